@@ -94,6 +94,12 @@ func (anim *Animation) SetStart(v float32) {
 	anim.start = v
 }
 
+// Start returns the initial time offset value.
+func (anim *Animation) Start() float32 {
+
+	return anim.start
+}
+
 // Update interpolates and updates the target values for each channel.
 // If the animation is paused, returns false. If the animation is not paused,
 // returns true if the input value is inside the key frames ranges or false otherwise.
@@ -146,3 +152,9 @@ func (anim *Animation) AddChannel(ch IChannel) {
 		anim.maxTime = lastTime
 	}
 }
+
+// Channels returns the channels of the animation, which must not be modified.
+func (anim *Animation) Channels() []IChannel {
+
+	return anim.channels
+}
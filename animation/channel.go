@@ -151,6 +151,12 @@ func NewPositionChannel(node core.INode) *PositionChannel {
 	return pc
 }
 
+// Target returns the node animated by this channel.
+func (pc *PositionChannel) Target() core.INode {
+
+	return pc.target
+}
+
 // RotationChannel is the animation channel for a node's rotation.
 type RotationChannel NodeChannel
 
@@ -195,6 +201,12 @@ func NewRotationChannel(node core.INode) *RotationChannel {
 	return rc
 }
 
+// Target returns the node animated by this channel.
+func (rc *RotationChannel) Target() core.INode {
+
+	return rc.target
+}
+
 // ScaleChannel is the animation channel for a node's scale.
 type ScaleChannel NodeChannel
 
@@ -235,6 +247,12 @@ func NewScaleChannel(node core.INode) *ScaleChannel {
 	return sc
 }
 
+// Target returns the node animated by this channel.
+func (sc *ScaleChannel) Target() core.INode {
+
+	return sc.target
+}
+
 // MorphChannel is the IChannel for morph geometries.
 type MorphChannel struct {
 	Channel
@@ -0,0 +1,177 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package animation
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// WrapMode specifies what a PathFollower does when it reaches an end of its path.
+type WrapMode int
+
+const (
+	// WrapStop stops at the ends of the path.
+	WrapStop = WrapMode(iota)
+	// WrapLoop jumps to the other end of the path.
+	WrapLoop
+	// WrapBounce reverses the direction of motion.
+	WrapBounce
+)
+
+// pathFrameDelta is the arc length distance used to estimate the curvature of the path.
+const pathFrameDelta = 0.01
+
+// PathFollower moves along a path curve at a controlled speed, keeping its arc length
+// position, and computes the position and Frenet frame used to orient an object following the path.
+type PathFollower struct {
+	curve     math32.PathCurve // followed curve
+	distance  float32          // current arc length position
+	direction float32          // direction of motion along the curve (1 or -1)
+	wrapMode  WrapMode         // behavior at the ends of the curve
+	up        math32.Vector3   // reference up direction for straight parts of the path
+}
+
+// NewPathFollower creates and returns a pointer to a new PathFollower
+// at the start of the specified curve.
+func NewPathFollower(curve math32.PathCurve, mode WrapMode) *PathFollower {
+
+	pf := new(PathFollower)
+	pf.curve = curve
+	pf.direction = 1
+	pf.wrapMode = mode
+	pf.up.Set(0, 1, 0)
+	return pf
+}
+
+// SetCurve sets the followed curve and moves to its start.
+func (pf *PathFollower) SetCurve(curve math32.PathCurve) {
+
+	pf.curve = curve
+	pf.distance = 0
+	pf.direction = 1
+}
+
+// Curve returns the followed curve.
+func (pf *PathFollower) Curve() math32.PathCurve {
+
+	return pf.curve
+}
+
+// SetWrapMode sets the behavior at the ends of the curve.
+func (pf *PathFollower) SetWrapMode(mode WrapMode) {
+
+	pf.wrapMode = mode
+}
+
+// WrapMode returns the behavior at the ends of the curve.
+func (pf *PathFollower) WrapMode() WrapMode {
+
+	return pf.wrapMode
+}
+
+// SetUp sets the reference up direction used to compute the normal where the path is straight.
+func (pf *PathFollower) SetUp(up *math32.Vector3) {
+
+	pf.up = *up
+}
+
+// SetDistance sets the current arc length position along the curve.
+func (pf *PathFollower) SetDistance(distance float32) {
+
+	pf.distance, pf.direction = pf.wrap(distance, pf.direction)
+}
+
+// Distance returns the current arc length position along the curve.
+func (pf *PathFollower) Distance() float32 {
+
+	return pf.distance
+}
+
+// Finished returns if the follower stopped at an end of the curve in WrapStop mode.
+func (pf *PathFollower) Finished() bool {
+
+	if pf.wrapMode != WrapStop {
+		return false
+	}
+	return (pf.direction > 0 && pf.distance >= pf.curve.Length()) || (pf.direction < 0 && pf.distance <= 0)
+}
+
+// wrap returns the arc length position and direction of motion resulting from
+// applying the wrap mode to the specified unbounded position.
+func (pf *PathFollower) wrap(distance, direction float32) (float32, float32) {
+
+	length := pf.curve.Length()
+	if length <= 0 {
+		return 0, direction
+	}
+	switch pf.wrapMode {
+	case WrapLoop:
+		distance = math32.Mod(distance, length)
+		if distance < 0 {
+			distance += length
+		}
+	case WrapBounce:
+		// Each crossing of an end reflects the position and reverses the direction
+		crossings := math32.Floor(distance / length)
+		distance -= crossings * length
+		if int(crossings)%2 != 0 {
+			distance = length - distance
+			direction = -direction
+		}
+	default:
+		distance = math32.Clamp(distance, 0, length)
+	}
+	return distance, direction
+}
+
+// Advance moves along the curve by speed*dt in the current direction of motion
+// applying the wrap mode at its ends and returns the new position,
+// the unit tangent in the direction of motion and the unit normal of the Frenet frame.
+func (pf *PathFollower) Advance(speed, dt float32) (position, tangent, normal math32.Vector3) {
+
+	pf.distance, pf.direction = pf.wrap(pf.distance+speed*dt*pf.direction, pf.direction)
+	return pf.Frame()
+}
+
+// Frame returns the position, the unit tangent in the direction of motion and the
+// unit normal of the Frenet frame at the current position along the curve.
+// The normal points towards the center of curvature or, where the path is straight,
+// is the direction perpendicular to the tangent closest to the reference up direction.
+func (pf *PathFollower) Frame() (position, tangent, normal math32.Vector3) {
+
+	position = pf.curve.PointAtLength(pf.distance)
+	tangent = pf.curve.TangentAtLength(pf.distance)
+	tangent.MultiplyScalar(pf.direction)
+
+	// The curvature vector is the derivative of the tangent relative to the arc length
+	length := pf.curve.Length()
+	s0 := math32.Max(pf.distance-pathFrameDelta, 0)
+	s1 := math32.Min(pf.distance+pathFrameDelta, length)
+	if s1 > s0 {
+		t0 := pf.curve.TangentAtLength(s0)
+		t1 := pf.curve.TangentAtLength(s1)
+		normal.SubVectors(&t1, &t0)
+		// Removes the tangential component
+		normal.Sub(tangent.Clone().MultiplyScalar(normal.Dot(&tangent)))
+	}
+	if normal.LengthSq() < 1e-8 {
+		normal = pf.up
+		normal.Sub(tangent.Clone().MultiplyScalar(normal.Dot(&tangent)))
+		if normal.LengthSq() < 1e-8 {
+			normal.Set(1, 0, 0)
+			normal.Sub(tangent.Clone().MultiplyScalar(normal.Dot(&tangent)))
+		}
+	}
+	normal.Normalize()
+	return position, tangent, normal
+}
+
+// LookAhead returns the position on the curve at the specified arc length distance
+// ahead of the current position in the direction of motion, applying the wrap mode.
+func (pf *PathFollower) LookAhead(distance float32) math32.Vector3 {
+
+	s, _ := pf.wrap(pf.distance+distance*pf.direction, pf.direction)
+	return pf.curve.PointAtLength(s)
+}
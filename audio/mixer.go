@@ -0,0 +1,89 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package audio
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// ReverbParams are the parameters of the reverberation of a room.
+type ReverbParams struct {
+	DecayTime        float32 // time in seconds for the reverberation to decay by 60 dB
+	EarlyReflections float32 // gain of the early reflections
+	WetDryMix        float32 // fraction of the reverberated signal in the output, 0 for the dry signal
+}
+
+// ReverbZone is a volume, such as a room, where the sounds reverberate with its parameters.
+type ReverbZone struct {
+	Volume math32.Box3
+	ReverbParams
+}
+
+// ReverbBackend is optionally implemented by the AudioBackend of an AudioMixer to receive
+// the reverberation parameters computed by UpdateReverb.
+type ReverbBackend interface {
+	SetReverb(params *ReverbParams)
+}
+
+// AudioMixer blends the reverberation of the zones around the listener.
+type AudioMixer struct {
+	BlendDistance float32 // depth inside the zones over which the reverberation fades in
+	backend       AudioBackend
+	reverb        ReverbParams
+}
+
+// NewAudioMixer creates and returns a pointer to a new AudioMixer with the specified backend,
+// which may be nil, with a dry signal and a blend distance of 1.
+func NewAudioMixer(backend AudioBackend) *AudioMixer {
+
+	m := new(AudioMixer)
+	m.backend = backend
+	m.BlendDistance = 1
+	return m
+}
+
+// UpdateReverb computes the reverberation at the specified listener position from the zones containing it,
+// weighted by the depth of the listener inside each zone: the distance to the closest point of its surface.
+// The wet fraction fades in over the blend distance from the surface of the zones, so that there is
+// no discontinuity when entering a zone, and the signal is dry outside all zones.
+// The parameters are set in the backend if it implements ReverbBackend.
+func (m *AudioMixer) UpdateReverb(listenerPos *math32.Vector3, zones []ReverbZone) {
+
+	var blend ReverbParams
+	var weights, maxDepth float32
+	var closest math32.Vector3
+	for i := range zones {
+		z := &zones[i]
+		if !z.Volume.ContainsPoint(listenerPos) {
+			continue
+		}
+		depth := z.Volume.ClosestPointToPoint(listenerPos, &closest).DistanceTo(listenerPos)
+		// A zone of zero depth contributes when the listener is only in zones of zero depth
+		w := depth + 1e-6
+		blend.DecayTime += z.DecayTime * w
+		blend.EarlyReflections += z.EarlyReflections * w
+		blend.WetDryMix += z.WetDryMix * w
+		weights += w
+		maxDepth = math32.Max(maxDepth, depth)
+	}
+	if weights > 0 {
+		blend.DecayTime /= weights
+		blend.EarlyReflections /= weights
+		blend.WetDryMix /= weights
+		if m.BlendDistance > 0 {
+			blend.WetDryMix *= math32.Min(maxDepth/m.BlendDistance, 1)
+		}
+	}
+	m.reverb = blend
+	if rb, ok := m.backend.(ReverbBackend); ok {
+		rb.SetReverb(&m.reverb)
+	}
+}
+
+// Reverb returns the reverberation parameters computed by the last UpdateReverb.
+func (m *AudioMixer) Reverb() ReverbParams {
+
+	return m.reverb
+}
@@ -0,0 +1,150 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package audio
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// DefaultSoundSpeed is the speed of sound in the air in m/s used by default for the Doppler shift.
+const DefaultSoundSpeed = 343
+
+// dopplerMaxSpeed is the fraction of the speed of sound to which the speeds along the
+// axis between the listener and the source are clamped, avoiding infinite or negative pitches.
+const dopplerMaxSpeed = 0.95
+
+// Transform is the position, rotation and velocity of an AudioListener in world coordinates.
+// The listener looks along -Z with +Y up, rotated by the rotation.
+type Transform struct {
+	Position math32.Vector3
+	Rotation math32.Quaternion
+	Velocity math32.Vector3
+}
+
+// AudioBuffer is audio data loaded by an AudioBackend, played by the AudioSource which references it.
+type AudioBuffer struct {
+	Handle     uint32 // handle of the data in the backend
+	SampleRate int    // samples per second of each channel
+	Channels   int    // number of channels
+}
+
+// AudioBackend is the interface of the platform audio API playing the sources, which receives
+// the parameters computed by the AudioSource from its position relative to the listener.
+type AudioBackend interface {
+	// SetSourceParams sets the gain, the left and right gains of the stereo panning
+	// and the pitch factor of the specified source.
+	SetSourceParams(src *AudioSource, gain, left, right, pitch float32)
+}
+
+// AudioListener is the listener of the spatial audio, at the position of its transform.
+type AudioListener struct {
+	SoundSpeed float32 // speed of sound in m/s, DefaultSoundSpeed by default
+	transform  Transform
+	spatial    SpatialAudio
+}
+
+// NewAudioListener creates and returns a pointer to a new AudioListener at the origin
+// looking along -Z, without velocity.
+func NewAudioListener() *AudioListener {
+
+	l := new(AudioListener)
+	l.SoundSpeed = DefaultSoundSpeed
+	l.SetTransform(&Transform{Rotation: math32.Quaternion{X: 0, Y: 0, Z: 0, W: 1}})
+	return l
+}
+
+// SetTransform sets the position, rotation and velocity of this listener.
+func (l *AudioListener) SetTransform(t *Transform) {
+
+	l.transform = *t
+	forward := math32.Vector3{X: 0, Y: 0, Z: -1}
+	forward.ApplyQuaternion(&t.Rotation)
+	up := math32.Vector3{X: 0, Y: 1, Z: 0}
+	up.ApplyQuaternion(&t.Rotation)
+	l.spatial.Update(&t.Position, &forward, &up)
+}
+
+// Transform returns the position, rotation and velocity of this listener.
+func (l *AudioListener) Transform() Transform {
+
+	return l.transform
+}
+
+// Doppler returns the factor of the frequency of a sound emitted by a source with the specified position
+// and velocity heard by this listener: (c - vl) / (c - vs), where c is the speed of sound and vl and vs
+// are the velocities of the listener and of the source along the axis from the source to the listener.
+// It is more than 1 when they come closer and less than 1 when they move apart.
+func (l *AudioListener) Doppler(sourcePos, sourceVel *math32.Vector3) float32 {
+
+	var axis math32.Vector3
+	axis.SubVectors(&l.transform.Position, sourcePos)
+	d := axis.Length()
+	c := l.SoundSpeed
+	if d == 0 || c <= 0 {
+		return 1
+	}
+	axis.MultiplyScalar(1 / d)
+	vl := math32.Min(l.transform.Velocity.Dot(&axis), c*dopplerMaxSpeed)
+	vs := math32.Min(sourceVel.Dot(&axis), c*dopplerMaxSpeed)
+	return (c - vl) / (c - vs)
+}
+
+// AudioSource is a sound source positioned in space, whose gain, stereo panning and pitch
+// are computed relative to a listener and set in its audio backend.
+type AudioSource struct {
+	Position math32.Vector3
+	Velocity math32.Vector3
+	Gain     float32 // gain before the attenuation and panning
+	MinDist  float32 // distance below which the sound is not attenuated
+	MaxDist  float32 // distance beyond which the sound is not attenuated further
+	Rolloff  float32 // rate of the attenuation with the distance
+	Loop     bool
+	Buffer   *AudioBuffer
+	backend  AudioBackend
+	gain     float32 // gain of the last UpdatePan
+	left     float32 // left gain of the last UpdatePan
+	right    float32 // right gain of the last UpdatePan
+	pitch    float32 // pitch of the last UpdatePan
+}
+
+// NewAudioSource creates and returns a pointer to a new AudioSource at the origin playing the specified
+// buffer with the specified backend, with a gain of 1, a minimum distance of 1, a maximum distance
+// of 1000 and a rolloff of 1.
+func NewAudioSource(backend AudioBackend, buffer *AudioBuffer) *AudioSource {
+
+	s := new(AudioSource)
+	s.backend = backend
+	s.Buffer = buffer
+	s.Gain = 1
+	s.MinDist = 1
+	s.MaxDist = 1000
+	s.Rolloff = 1
+	s.pitch = 1
+	return s
+}
+
+// UpdatePan computes the gain attenuated by the distance, the stereo panning and the pitch shifted
+// by the Doppler effect of this source heard by the specified listener, and sets them in the backend.
+func (s *AudioSource) UpdatePan(listener *AudioListener) {
+
+	s.gain = s.Gain * listener.spatial.ComputeAttenuation(&s.Position, s.MinDist, s.MaxDist, s.Rolloff)
+	s.left, s.right = listener.spatial.ComputePan(&s.Position)
+	s.pitch = listener.Doppler(&s.Position, &s.Velocity)
+	if s.backend != nil {
+		s.backend.SetSourceParams(s, s.gain, s.left, s.right, s.pitch)
+	}
+}
+
+// Gains returns the gain and the left and right panning gains computed by the last UpdatePan.
+func (s *AudioSource) Gains() (gain, left, right float32) {
+
+	return s.gain, s.left, s.right
+}
+
+// Pitch returns the pitch factor computed by the last UpdatePan.
+func (s *AudioSource) Pitch() float32 {
+
+	return s.pitch
+}
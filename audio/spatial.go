@@ -0,0 +1,91 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package audio
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// SpatialAudio computes the distance attenuation and stereo panning of sound sources
+// relative to a listener, for mixing positional audio without OpenAL.
+// The listener axes are cached so that the computations do not allocate.
+type SpatialAudio struct {
+	position math32.Vector3 // listener position
+	forward  math32.Vector3 // listener unit forward direction
+	up       math32.Vector3 // listener unit up direction orthogonal to forward
+	right    math32.Vector3 // listener unit right direction
+}
+
+// NewSpatialAudio creates and returns a pointer to a new SpatialAudio
+// for a listener with the specified position, forward and up directions.
+func NewSpatialAudio(listenerPos, listenerForward, listenerUp *math32.Vector3) *SpatialAudio {
+
+	sa := new(SpatialAudio)
+	sa.Update(listenerPos, listenerForward, listenerUp)
+	return sa
+}
+
+// Update sets the listener position, forward and up directions.
+// The up direction is made orthogonal to the forward direction.
+func (sa *SpatialAudio) Update(listenerPos, forward, up *math32.Vector3) {
+
+	sa.position = *listenerPos
+	sa.forward = *forward
+	sa.forward.Normalize()
+	sa.right.CrossVectors(&sa.forward, up)
+	if sa.right.LengthSq() == 0 {
+		// Up parallel to forward: any perpendicular right direction
+		sa.right.Set(1, 0, 0)
+		if math32.Abs(sa.forward.X) > 0.9 {
+			sa.right.Set(0, 0, 1)
+		}
+		f := sa.forward
+		sa.right.Sub(f.MultiplyScalar(sa.right.Dot(&sa.forward)))
+	}
+	sa.right.Normalize()
+	sa.up.CrossVectors(&sa.right, &sa.forward)
+}
+
+// Position returns the listener position.
+func (sa *SpatialAudio) Position() math32.Vector3 {
+
+	return sa.position
+}
+
+// Right returns the listener unit right direction.
+func (sa *SpatialAudio) Right() math32.Vector3 {
+
+	return sa.right
+}
+
+// ComputeAttenuation returns the gain in [0,1] of a source at the specified position using the
+// clamped inverse distance model: minDist / (minDist + rolloff*(d - minDist)) with the distance d
+// clamped to [minDist, maxDist]. Sources nearer than minDist are not attenuated.
+func (sa *SpatialAudio) ComputeAttenuation(sourcePos *math32.Vector3, minDist, maxDist, rolloff float32) float32 {
+
+	d := math32.Clamp(sa.position.DistanceTo(sourcePos), minDist, maxDist)
+	den := minDist + rolloff*(d-minDist)
+	if den <= 0 {
+		return 1
+	}
+	return math32.Clamp(minDist/den, 0, 1)
+}
+
+// ComputePan returns the left and right gains of a source at the specified position using equal power panning
+// of the projection of the direction to the source on the listener right axis.
+// Sources at the listener position, in front of or behind the listener have equal gains of sqrt(2)/2.
+func (sa *SpatialAudio) ComputePan(sourcePos *math32.Vector3) (left, right float32) {
+
+	dx := sourcePos.X - sa.position.X
+	dy := sourcePos.Y - sa.position.Y
+	dz := sourcePos.Z - sa.position.Z
+	var pan float32
+	if d := math32.Sqrt(dx*dx + dy*dy + dz*dz); d > 0 {
+		pan = (dx*sa.right.X + dy*sa.right.Y + dz*sa.right.Z) / d
+	}
+	// Pan in [-1,1] maps to the angle in [0,pi/2]
+	angle := (math32.Clamp(pan, -1, 1) + 1) * math32.Pi / 4
+	return math32.Cos(angle), math32.Sin(angle)
+}
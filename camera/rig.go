@@ -0,0 +1,199 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package camera
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+// RigMode specifies the behavior of a camera rig.
+type RigMode int
+
+const (
+	// RigNone does not move the camera.
+	RigNone = RigMode(iota)
+	// RigOrbit keeps the camera on a sphere around a target position.
+	RigOrbit
+	// RigFollow follows a target node at an offset with lag.
+	RigFollow
+)
+
+// rigMaxElevation is the maximum absolute orbit elevation in radians (89 degrees).
+const rigMaxElevation = 89 * math32.Pi / 180
+
+// rigMinDistance is the minimum distance between the camera and its target.
+const rigMinDistance = 1e-3
+
+// CameraRig moves a camera with orbit and follow behaviors updated at each frame.
+// The distance to the target may be changed with Dolly, optionally as a dolly zoom
+// which adjusts the field of view of a perspective camera to keep the target framing.
+type CameraRig struct {
+	cam       ICamera        // controlled camera
+	mode      RigMode        // current behavior
+	target    math32.Vector3 // orbit target position
+	azimuth   float32        // orbit azimuth angle in radians around the Y axis
+	elevation float32        // orbit elevation angle in radians
+	distance  float32        // orbit distance
+	follow    *core.Node     // followed node
+	offset    math32.Vector3 // offset from the followed node in world coordinates
+	lag       float32        // time to reach the follow position
+	velocity  math32.Vector3 // current follow velocity
+	dollyZoom bool           // Dolly adjusts the field of view
+}
+
+// NewCameraRig creates and returns a pointer to a new CameraRig controlling the specified camera.
+func NewCameraRig(cam ICamera) *CameraRig {
+
+	rig := new(CameraRig)
+	rig.cam = cam
+	rig.distance = 1
+	return rig
+}
+
+// Camera returns the controlled camera.
+func (rig *CameraRig) Camera() ICamera {
+
+	return rig.cam
+}
+
+// Mode returns the current behavior of the rig.
+func (rig *CameraRig) Mode() RigMode {
+
+	return rig.mode
+}
+
+// SetDollyZoom sets if Dolly changes the field of view of a perspective camera
+// so that objects at the target distance keep the same size on the screen.
+func (rig *CameraRig) SetDollyZoom(enabled bool) {
+
+	rig.dollyZoom = enabled
+}
+
+// OrbitAround sets the rig to orbit the specified target position at the specified azimuth
+// and elevation angles in radians and distance. The elevation is clamped to (-89, 89) degrees.
+func (rig *CameraRig) OrbitAround(target *math32.Vector3, azimuth, elevation, distance float32) {
+
+	rig.mode = RigOrbit
+	rig.target = *target
+	rig.azimuth = azimuth
+	rig.elevation = math32.Clamp(elevation, -rigMaxElevation, rigMaxElevation)
+	rig.distance = math32.Max(distance, rigMinDistance)
+}
+
+// Rotate changes the orbit azimuth and elevation by the specified angles in radians.
+func (rig *CameraRig) Rotate(deltaAzimuth, deltaElevation float32) {
+
+	rig.azimuth += deltaAzimuth
+	rig.elevation = math32.Clamp(rig.elevation+deltaElevation, -rigMaxElevation, rigMaxElevation)
+}
+
+// Follow sets the rig to follow the specified node at the specified offset in world coordinates,
+// reaching the follow position in approximately lag seconds.
+func (rig *CameraRig) Follow(target *core.Node, offset *math32.Vector3, lag float32) {
+
+	rig.mode = RigFollow
+	rig.follow = target
+	rig.offset = *offset
+	rig.lag = lag
+	rig.velocity.Set(0, 0, 0)
+}
+
+// Dolly moves the camera towards its target by the specified distance, or away from it if negative.
+// In follow mode the length of the offset is changed.
+func (rig *CameraRig) Dolly(delta float32) {
+
+	var before, after float32
+	switch rig.mode {
+	case RigOrbit:
+		before = rig.distance
+		rig.distance = math32.Max(rig.distance-delta, rigMinDistance)
+		after = rig.distance
+	case RigFollow:
+		before = rig.offset.Length()
+		if before == 0 {
+			return
+		}
+		after = math32.Max(before-delta, rigMinDistance)
+		rig.offset.MultiplyScalar(after / before)
+	default:
+		cam := rig.cam.GetCamera()
+		target := cam.Target()
+		pos := cam.Position()
+		var dir math32.Vector3
+		dir.SubVectors(&target, &pos)
+		before = dir.Length()
+		if before == 0 {
+			return
+		}
+		after = math32.Max(before-delta, rigMinDistance)
+		dir.MultiplyScalar((before - after) / before)
+		pos.Add(&dir)
+		cam.SetPositionVec(&pos)
+	}
+
+	// The visible height at the target distance is kept by scaling the tangent of the half field of view
+	if persp, ok := rig.cam.(*Perspective); ok && rig.dollyZoom && after > 0 {
+		half := math32.DegToRad(persp.Fov() / 2)
+		fov := 2 * math32.RadToDeg(math32.Atan(math32.Tan(half)*before/after))
+		persp.SetFov(math32.Clamp(fov, 1, 179))
+	}
+}
+
+// Update updates the camera position and orientation for the current behavior.
+// It should be called at each frame with the time elapsed since the previous frame.
+func (rig *CameraRig) Update(dt float32) {
+
+	cam := rig.cam.GetCamera()
+	switch rig.mode {
+	case RigOrbit:
+		cosEl := math32.Cos(rig.elevation)
+		pos := math32.Vector3{
+			X: rig.target.X + rig.distance*cosEl*math32.Sin(rig.azimuth),
+			Y: rig.target.Y + rig.distance*math32.Sin(rig.elevation),
+			Z: rig.target.Z + rig.distance*cosEl*math32.Cos(rig.azimuth),
+		}
+		cam.SetPositionVec(&pos)
+		cam.LookAt(&rig.target)
+	case RigFollow:
+		if rig.follow == nil {
+			return
+		}
+		var target math32.Vector3
+		rig.follow.WorldPosition(&target)
+		desired := target
+		desired.Add(&rig.offset)
+		pos := cam.Position()
+		if rig.lag > 0 {
+			math32.SmoothDampVector3(&pos, &desired, &rig.velocity, rig.lag, dt)
+		} else {
+			pos = desired
+		}
+		cam.SetPositionVec(&pos)
+		cam.LookAt(&target)
+	}
+}
+
+// ScreenToRay returns the ray in world coordinates from the camera through the specified
+// screen position in pixels inside the specified viewport, with Y increasing downwards.
+// It may be used for picking.
+func (rig *CameraRig) ScreenToRay(screenX, screenY float32, viewport *math32.Rect) *math32.Ray {
+
+	// Normalized device coordinates
+	x := (screenX-viewport.Min.X)/viewport.Width()*2 - 1
+	y := 1 - (screenY-viewport.Min.Y)/viewport.Height()*2
+
+	rig.cam.GetCamera().UpdateMatrixWorld()
+	near := math32.Vector3{X: x, Y: y, Z: -1}
+	far := math32.Vector3{X: x, Y: y, Z: 1}
+	if _, err := rig.cam.Unproject(&near); err != nil {
+		return nil
+	}
+	if _, err := rig.cam.Unproject(&far); err != nil {
+		return nil
+	}
+	far.Sub(&near).Normalize()
+	return math32.NewRay(&near, &far)
+}
@@ -0,0 +1,154 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"reflect"
+	"sync"
+)
+
+// SubscriptionID identifies a subscription of an EventBus.
+type SubscriptionID uint64
+
+// EventBus dispatches typed events to the handlers subscribed to their type, so that engine systems
+// communicate without knowing each other. Events are published with Publish and handlers
+// subscribed with Subscribe. It is safe to use from several goroutines.
+type EventBus struct {
+	mu       sync.Mutex
+	nextID   SubscriptionID
+	handlers map[reflect.Type][]busHandler // handlers by event type, in subscription order
+	types    map[SubscriptionID]reflect.Type
+	deferred bool     // events are queued until Flush
+	queue    []func() // dispatches of the queued events
+}
+
+// busHandler is a handler subscribed to an EventBus.
+type busHandler struct {
+	id SubscriptionID
+	fn interface{} // func(T) for the event type T
+}
+
+// NewEventBus creates and returns a pointer to a new EventBus which dispatches the events when published.
+func NewEventBus() *EventBus {
+
+	bus := new(EventBus)
+	bus.Initialize()
+	return bus
+}
+
+// Initialize initializes this event bus.
+// It is normally used by other types which embed an event bus.
+func (bus *EventBus) Initialize() {
+
+	bus.handlers = make(map[reflect.Type][]busHandler)
+	bus.types = make(map[SubscriptionID]reflect.Type)
+}
+
+// Subscribe subscribes the specified handler to the events of type T of the specified bus
+// and returns the ID used to unsubscribe it.
+func Subscribe[T any](bus *EventBus, handler func(T)) SubscriptionID {
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	bus.nextID++
+	id := bus.nextID
+	bus.handlers[t] = append(bus.handlers[t], busHandler{id: id, fn: handler})
+	bus.types[id] = t
+	return id
+}
+
+// Publish sends the specified event to the handlers subscribed to its type T in the specified bus,
+// in the order they subscribed. A deferred bus queues the event until its next Flush.
+// The handlers subscribed when the event is dispatched all receive it,
+// even if one of them unsubscribes another.
+func Publish[T any](bus *EventBus, event T) {
+
+	bus.mu.Lock()
+	if bus.deferred {
+		bus.queue = append(bus.queue, func() { dispatch(bus, event) })
+		bus.mu.Unlock()
+		return
+	}
+	bus.mu.Unlock()
+	dispatch(bus, event)
+}
+
+// dispatch calls the handlers of the type of the specified event outside of the lock,
+// so that they can subscribe, unsubscribe and publish.
+func dispatch[T any](bus *EventBus, event T) {
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	bus.mu.Lock()
+	// The handlers slice is never modified in place, so it is a snapshot of the subscriptions
+	handlers := bus.handlers[t]
+	bus.mu.Unlock()
+	for _, h := range handlers {
+		h.fn.(func(T))(event)
+	}
+}
+
+// Unsubscribe removes the subscription with the specified ID.
+// Returns true if it was found.
+func (bus *EventBus) Unsubscribe(id SubscriptionID) bool {
+
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	t, ok := bus.types[id]
+	if !ok {
+		return false
+	}
+	delete(bus.types, id)
+	old := bus.handlers[t]
+	handlers := make([]busHandler, 0, len(old)-1)
+	for _, h := range old {
+		if h.id != id {
+			handlers = append(handlers, h)
+		}
+	}
+	if len(handlers) == 0 {
+		delete(bus.handlers, t)
+	} else {
+		bus.handlers[t] = handlers
+	}
+	return true
+}
+
+// DeferredEventBus is an EventBus which accumulates the published events, for example
+// during a simulation step, and dispatches them all when Flush is called at the end of the frame.
+// Use Subscribe and Publish with its embedded EventBus.
+type DeferredEventBus struct {
+	EventBus
+}
+
+// NewDeferredEventBus creates and returns a pointer to a new DeferredEventBus without queued events.
+func NewDeferredEventBus() *DeferredEventBus {
+
+	bus := new(DeferredEventBus)
+	bus.Initialize()
+	bus.deferred = true
+	return bus
+}
+
+// Pending returns the number of events queued since the last flush.
+func (bus *DeferredEventBus) Pending() int {
+
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	return len(bus.queue)
+}
+
+// Flush dispatches the queued events in the order they were published.
+// Events published by the handlers during the flush are queued for the next one.
+func (bus *DeferredEventBus) Flush() {
+
+	bus.mu.Lock()
+	queue := bus.queue
+	bus.queue = nil
+	bus.mu.Unlock()
+	for _, d := range queue {
+		d()
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// busTestEvent is the event type of the event bus tests.
+type busTestEvent struct {
+	N int
+}
+
+// Test that unsubscribing from a handler does not drop the deliveries of the event being dispatched
+func TestEventBusUnsubscribeInHandler(t *testing.T) {
+
+	bus := NewEventBus()
+	var got []string
+	var idB, idD SubscriptionID
+	Subscribe(bus, func(e busTestEvent) {
+		got = append(got, "a")
+		// Unsubscribes a later handler, which still receives this event
+		bus.Unsubscribe(idB)
+	})
+	idB = Subscribe(bus, func(e busTestEvent) { got = append(got, "b") })
+	Subscribe(bus, func(e busTestEvent) { got = append(got, "c") })
+	idD = Subscribe(bus, func(e busTestEvent) {
+		got = append(got, "d")
+		if !bus.Unsubscribe(idD) {
+			t.Error("Unsubscribe of the running handler failed")
+		}
+	})
+	Subscribe(bus, func(s string) { got = append(got, s) })
+
+	Publish(bus, busTestEvent{1})
+	Publish(bus, busTestEvent{2})
+	Publish(bus, "s")
+	expected := []string{"a", "b", "c", "d", "a", "c", "s"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Error("deliveries", got, "instead of", expected)
+	}
+	if bus.Unsubscribe(idB) || bus.Unsubscribe(idD) {
+		t.Error("Unsubscribe of removed subscriptions succeeded")
+	}
+}
+
+// Test that the events published while flushing a deferred bus are dispatched by the next flush
+func TestDeferredEventBus(t *testing.T) {
+
+	bus := NewDeferredEventBus()
+	sum := 0
+	Subscribe(&bus.EventBus, func(e busTestEvent) {
+		sum += e.N
+		if e.N == 1 {
+			Publish(&bus.EventBus, busTestEvent{10})
+		}
+	})
+	Publish(&bus.EventBus, busTestEvent{1})
+	Publish(&bus.EventBus, busTestEvent{2})
+	if sum != 0 || bus.Pending() != 2 {
+		t.Fatal("events dispatched before the flush:", sum, bus.Pending())
+	}
+	bus.Flush()
+	if sum != 3 || bus.Pending() != 1 {
+		t.Fatal("first flush:", sum, bus.Pending())
+	}
+	bus.Flush()
+	if sum != 13 || bus.Pending() != 0 {
+		t.Fatal("second flush:", sum, bus.Pending())
+	}
+}
+
+// Test concurrent subscriptions, publications and unsubscriptions
+func TestEventBusConcurrent(t *testing.T) {
+
+	bus := NewEventBus()
+	var mu sync.Mutex
+	count := 0
+	Subscribe(bus, func(int) {
+		mu.Lock()
+		count++
+		mu.Unlock()
+	})
+	const goroutines = 8
+	const iterations = 1000
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				id := Subscribe(bus, func(int) {})
+				Publish(bus, i)
+				bus.Unsubscribe(id)
+			}
+		}()
+	}
+	wg.Wait()
+	if count != goroutines*iterations {
+		t.Error("events delivered", count, "times instead of", goroutines*iterations)
+	}
+}
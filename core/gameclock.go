@@ -0,0 +1,81 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package core
+
+// GameClock manages the simulation time of a loop which updates the simulation with a
+// fixed timestep and renders once per frame, interpolating between the last two steps.
+// All times are in seconds.
+type GameClock struct {
+	TotalTime       float32 // simulation time elapsed since the clock was created
+	DeltaTime       float32 // simulation time elapsed in the last tick
+	FixedDeltaTime  float32 // duration of a fixed step
+	AccumulatedTime float32 // simulation time not consumed by fixed steps yet
+	MaxDeltaTime    float32 // longest wall time of a tick, to limit the steps after a long frame; no limit if 0
+	timeScale       float32
+}
+
+// NewGameClock creates and returns a pointer to a new GameClock with the specified fixed step duration,
+// a time scale of 1 and a maximum tick duration of a quarter of a second.
+func NewGameClock(fixedDeltaTime float32) *GameClock {
+
+	if fixedDeltaTime <= 0 {
+		panic("NewGameClock: fixedDeltaTime must be positive")
+	}
+	gc := new(GameClock)
+	gc.FixedDeltaTime = fixedDeltaTime
+	gc.MaxDeltaTime = 0.25
+	gc.timeScale = 1
+	return gc
+}
+
+// SetTimeScale sets the factor applied to the wall time to get the simulation time:
+// 0.5 runs the simulation at half speed and 0 pauses it.
+func (gc *GameClock) SetTimeScale(scale float32) {
+
+	if scale < 0 {
+		scale = 0
+	}
+	gc.timeScale = scale
+}
+
+// TimeScale returns the factor applied to the wall time to get the simulation time.
+func (gc *GameClock) TimeScale() float32 {
+
+	return gc.timeScale
+}
+
+// Tick advances the clock by the specified wall time elapsed since the last tick, limited to MaxDeltaTime,
+// and returns the number of fixed steps to run in this frame, which are removed from the accumulated time.
+func (gc *GameClock) Tick(wallDt float32) int {
+
+	if wallDt < 0 {
+		wallDt = 0
+	}
+	if gc.MaxDeltaTime > 0 && wallDt > gc.MaxDeltaTime {
+		wallDt = gc.MaxDeltaTime
+	}
+	gc.DeltaTime = wallDt * gc.timeScale
+	gc.TotalTime += gc.DeltaTime
+	gc.AccumulatedTime += gc.DeltaTime
+	// Tolerates the rounding errors of the accumulated time so that ticks which add up
+	// to a whole number of steps run all of them
+	eps := gc.FixedDeltaTime * 1e-3
+	steps := 0
+	for gc.AccumulatedTime >= gc.FixedDeltaTime-eps {
+		gc.AccumulatedTime -= gc.FixedDeltaTime
+		steps++
+	}
+	if gc.AccumulatedTime < 0 {
+		gc.AccumulatedTime = 0
+	}
+	return steps
+}
+
+// Interpolation returns the fraction in [0,1) of the next fixed step covered by the accumulated time,
+// used to blend the states of the last two steps when rendering.
+func (gc *GameClock) Interpolation() float32 {
+
+	return gc.AccumulatedTime / gc.FixedDeltaTime
+}
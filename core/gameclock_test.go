@@ -0,0 +1,76 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"testing"
+)
+
+// Test the number of fixed steps run over 10 seconds of wall time for several frame rates
+func TestGameClockSteps(t *testing.T) {
+
+	cases := []struct {
+		fixed  float32 // fixed step duration
+		wall   float32 // wall time of the frames
+		frames int     // number of frames
+		steps  int     // expected number of steps
+	}{
+		{1.0 / 60, 1.0 / 60, 600, 600},
+		{1.0 / 60, 1.0 / 144, 1440, 600},
+		{1.0 / 60, 1.0 / 30, 300, 600},
+		{0.01, 1.0 / 30, 300, 1000},
+		{0.02, 0.02, 500, 500},
+		{0.02, 0.03, 333, 499},
+	}
+	for _, c := range cases {
+		gc := NewGameClock(c.fixed)
+		steps := 0
+		for i := 0; i < c.frames; i++ {
+			n := gc.Tick(c.wall)
+			if n < 0 || float32(n-1)*c.fixed > c.wall {
+				t.Fatal("Tick returned", n, "steps for a frame of", c.wall, "for a step of", c.fixed)
+			}
+			steps += n
+			if f := gc.Interpolation(); f < 0 || f >= 1 {
+				t.Fatal("Interpolation", f, "not in [0,1)")
+			}
+		}
+		if steps != c.steps {
+			t.Error(steps, "steps instead of", c.steps, "for a step of", c.fixed, "and frames of", c.wall)
+		}
+	}
+}
+
+// Test the limit of the tick duration and the time scale
+func TestGameClockScale(t *testing.T) {
+
+	gc := NewGameClock(0.01)
+	if n := gc.Tick(5); n != 25 {
+		t.Error("Tick of 5s returned", n, "steps instead of the 25 steps of MaxDeltaTime")
+	}
+	if gc.TotalTime != 0.25 {
+		t.Error("TotalTime", gc.TotalTime, "instead of 0.25")
+	}
+
+	gc.SetTimeScale(0.5)
+	if n := gc.Tick(0.1); n != 5 {
+		t.Error("Tick of 0.1s at half speed returned", n, "steps instead of 5")
+	}
+	gc.SetTimeScale(0)
+	if n := gc.Tick(0.1); n != 0 || gc.DeltaTime != 0 {
+		t.Error("paused clock returned", n, "steps and DeltaTime", gc.DeltaTime)
+	}
+	gc.SetTimeScale(-1)
+	if gc.TimeScale() != 0 {
+		t.Error("TimeScale", gc.TimeScale(), "instead of 0 for a negative scale")
+	}
+
+	// Interpolation halfway through a step
+	gc = NewGameClock(0.02)
+	gc.Tick(0.05)
+	if f := gc.Interpolation(); f < 0.49 || f > 0.51 {
+		t.Error("Interpolation", f, "instead of 0.5")
+	}
+}
@@ -0,0 +1,291 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// JobSystem runs jobs in a pool of worker goroutines, for the data-parallel tasks of the simulation.
+// Each worker keeps the jobs it creates in a lock-free work-stealing deque: the ranges of
+// ScheduleForEach are split in halves by the worker running them, which keeps one half
+// and pushes the other, stolen by the idle workers, down to the batch size.
+// A job must not wait for other jobs, as it would block its worker.
+type JobSystem struct {
+	workers  []*jobWorker
+	mu       sync.Mutex
+	injected []*jobTask     // jobs scheduled from outside of the workers
+	wake     chan struct{}  // tokens waking idle workers
+	quit     chan struct{}  // closed to stop the workers
+	wg       sync.WaitGroup // running workers
+}
+
+// Job is the handle of a job scheduled by a JobSystem.
+type Job struct {
+	wg sync.WaitGroup
+}
+
+// JobGroup is the handle of the batches of a range scheduled by a JobSystem.
+type JobGroup struct {
+	wg sync.WaitGroup
+}
+
+// jobTask is a job in a queue: a function or a range of items to process in batches.
+type jobTask struct {
+	fn         func()
+	rangeFn    func(start, end int)
+	start, end int
+	batch      int
+	job        *Job
+	group      *JobGroup
+}
+
+// jobWorker is a worker goroutine of a JobSystem with its deque.
+type jobWorker struct {
+	js    *JobSystem
+	id    int
+	deque jobDeque
+}
+
+// NewJobSystem creates and returns a pointer to a new JobSystem with the specified number of worker goroutines.
+func NewJobSystem(numWorkers int) *JobSystem {
+
+	if numWorkers < 1 {
+		panic("NewJobSystem: at least one worker is required")
+	}
+	js := new(JobSystem)
+	js.wake = make(chan struct{}, numWorkers)
+	js.quit = make(chan struct{})
+	js.workers = make([]*jobWorker, numWorkers)
+	for i := range js.workers {
+		w := &jobWorker{js: js, id: i}
+		w.deque.init()
+		js.workers[i] = w
+	}
+	js.wg.Add(numWorkers)
+	for _, w := range js.workers {
+		go w.run()
+	}
+	return js
+}
+
+// Schedule schedules the specified function to run in a worker and returns its handle.
+func (js *JobSystem) Schedule(fn func()) *Job {
+
+	job := new(Job)
+	job.wg.Add(1)
+	js.inject(&jobTask{fn: fn, job: job})
+	return job
+}
+
+// ScheduleForEach schedules the specified function to run in the workers for the batches of
+// up to batchSize items which divide the range from 0 to items, and returns the handle of the batches.
+// The function is called with the start and end (exclusive) of each batch.
+func (js *JobSystem) ScheduleForEach(items int, batchSize int, fn func(start, end int)) *JobGroup {
+
+	group := new(JobGroup)
+	if items <= 0 {
+		return group
+	}
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	group.wg.Add(items)
+	js.inject(&jobTask{rangeFn: fn, start: 0, end: items, batch: batchSize, group: group})
+	return group
+}
+
+// Dispose stops the workers after the jobs they are running. The jobs not started are not run.
+func (js *JobSystem) Dispose() {
+
+	close(js.quit)
+	js.wg.Wait()
+}
+
+// Wait blocks until the job finishes.
+func (j *Job) Wait() {
+
+	j.wg.Wait()
+}
+
+// Wait blocks until all the batches finish.
+func (g *JobGroup) Wait() {
+
+	g.wg.Wait()
+}
+
+// inject adds the specified task to the queue of the jobs scheduled from outside of the workers.
+func (js *JobSystem) inject(t *jobTask) {
+
+	js.mu.Lock()
+	js.injected = append(js.injected, t)
+	js.mu.Unlock()
+	js.signal()
+}
+
+// signal wakes an idle worker, if any. The buffered tokens wake the workers which are about to wait.
+func (js *JobSystem) signal() {
+
+	select {
+	case js.wake <- struct{}{}:
+	default:
+	}
+}
+
+// take returns the oldest injected task, or nil.
+func (js *JobSystem) take() *jobTask {
+
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	if len(js.injected) == 0 {
+		return nil
+	}
+	t := js.injected[0]
+	js.injected[0] = nil
+	js.injected = js.injected[1:]
+	return t
+}
+
+// run is the loop of a worker: it runs the tasks of its deque, then the tasks stolen from
+// the other workers, then the injected tasks, and waits when there are none.
+func (w *jobWorker) run() {
+
+	defer w.js.wg.Done()
+	for {
+		t := w.deque.pop()
+		if t == nil {
+			t = w.steal()
+		}
+		if t == nil {
+			t = w.js.take()
+		}
+		if t != nil {
+			w.execute(t)
+			continue
+		}
+		select {
+		case <-w.js.wake:
+		case <-w.js.quit:
+			return
+		}
+	}
+}
+
+// steal returns a task stolen from another worker, or nil.
+func (w *jobWorker) steal() *jobTask {
+
+	n := len(w.js.workers)
+	for i := 1; i < n; i++ {
+		if t := w.js.workers[(w.id+i)%n].deque.steal(); t != nil {
+			return t
+		}
+	}
+	return nil
+}
+
+// execute runs the specified task. The ranges larger than a batch are split in halves,
+// pushing the second halves to the deque of the worker.
+func (w *jobWorker) execute(t *jobTask) {
+
+	if t.fn != nil {
+		t.fn()
+		t.job.wg.Done()
+		return
+	}
+	start, end := t.start, t.end
+	for end-start > t.batch {
+		// Splits on a multiple of the batch size, so that the batches are full
+		mid := start + (end-start+2*t.batch-1)/(2*t.batch)*t.batch
+		w.deque.push(&jobTask{rangeFn: t.rangeFn, start: mid, end: end, batch: t.batch, group: t.group})
+		w.js.signal()
+		end = mid
+	}
+	t.rangeFn(start, end)
+	t.group.wg.Add(start - end)
+}
+
+// jobDeque is a Chase-Lev work-stealing deque: its owner pushes and pops at the bottom
+// and the other workers steal from the top, without locks.
+type jobDeque struct {
+	top    atomic.Int64
+	bottom atomic.Int64
+	array  atomic.Pointer[jobRing]
+}
+
+// jobRing is the circular array of a deque, replaced by a larger copy when full.
+type jobRing struct {
+	tasks []atomic.Pointer[jobTask]
+	mask  int64
+}
+
+// init allocates the initial array of the deque.
+func (d *jobDeque) init() {
+
+	d.array.Store(newJobRing(64))
+}
+
+// newJobRing creates a circular array with the specified size, a power of two.
+func newJobRing(size int64) *jobRing {
+
+	return &jobRing{tasks: make([]atomic.Pointer[jobTask], size), mask: size - 1}
+}
+
+// push adds the specified task at the bottom of the deque. Only called by the owner.
+func (d *jobDeque) push(t *jobTask) {
+
+	b := d.bottom.Load()
+	top := d.top.Load()
+	a := d.array.Load()
+	if b-top > a.mask {
+		// Grows the array, copying the tasks not taken
+		grown := newJobRing(2 * (a.mask + 1))
+		for i := top; i < b; i++ {
+			grown.tasks[i&grown.mask].Store(a.tasks[i&a.mask].Load())
+		}
+		a = grown
+		d.array.Store(a)
+	}
+	a.tasks[b&a.mask].Store(t)
+	d.bottom.Store(b + 1)
+}
+
+// pop removes and returns the task at the bottom of the deque, or nil if empty. Only called by the owner.
+func (d *jobDeque) pop() *jobTask {
+
+	b := d.bottom.Load() - 1
+	a := d.array.Load()
+	d.bottom.Store(b)
+	top := d.top.Load()
+	if top > b {
+		d.bottom.Store(b + 1)
+		return nil
+	}
+	t := a.tasks[b&a.mask].Load()
+	if top == b {
+		// Last task: races with the thieves
+		if !d.top.CompareAndSwap(top, top+1) {
+			t = nil
+		}
+		d.bottom.Store(b + 1)
+	}
+	return t
+}
+
+// steal removes and returns the task at the top of the deque, or nil if empty or taken concurrently.
+func (d *jobDeque) steal() *jobTask {
+
+	top := d.top.Load()
+	b := d.bottom.Load()
+	if top >= b {
+		return nil
+	}
+	a := d.array.Load()
+	t := a.tasks[top&a.mask].Load()
+	if !d.top.CompareAndSwap(top, top+1) {
+		return nil
+	}
+	return t
+}
@@ -0,0 +1,137 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package core
+
+import (
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+// Test that the jobs run once and that the batches of the ranges visit each index exactly once
+func TestJobSystem(t *testing.T) {
+
+	js := NewJobSystem(4)
+	defer js.Dispose()
+
+	var count atomic.Int64
+	jobs := make([]*Job, 100)
+	for i := range jobs {
+		jobs[i] = js.Schedule(func() { count.Add(1) })
+	}
+	for _, j := range jobs {
+		j.Wait()
+	}
+	if count.Load() != int64(len(jobs)) {
+		t.Fatal("jobs run", count.Load(), "times instead of", len(jobs))
+	}
+
+	for _, batch := range []int{1, 7, 64, 20000} {
+		visits := make([]int32, 10007)
+		var bad atomic.Bool
+		js.ScheduleForEach(len(visits), batch, func(start, end int) {
+			if start < 0 || end > len(visits) || end <= start || end-start > batch {
+				bad.Store(true)
+				return
+			}
+			for i := start; i < end; i++ {
+				atomic.AddInt32(&visits[i], 1)
+			}
+		}).Wait()
+		if bad.Load() {
+			t.Fatal("invalid batch for the batch size", batch)
+		}
+		for i, v := range visits {
+			if v != 1 {
+				t.Fatal("index", i, "visited", v, "times for the batch size", batch)
+			}
+		}
+	}
+
+	// Empty range
+	js.ScheduleForEach(0, 8, func(start, end int) { t.Error("batch of an empty range") }).Wait()
+}
+
+// Test the jobs and ranges scheduled by the jobs
+func TestJobSystemNested(t *testing.T) {
+
+	js := NewJobSystem(3)
+	defer js.Dispose()
+
+	const outer = 16
+	const inner = 1000
+	visits := make([]int32, outer*inner)
+	groups := make(chan *JobGroup, outer)
+	jobs := make([]*Job, outer)
+	for i := range jobs {
+		i := i
+		jobs[i] = js.Schedule(func() {
+			// A job must not wait for other jobs, the groups are waited by the test
+			groups <- js.ScheduleForEach(inner, 16, func(start, end int) {
+				for k := start; k < end; k++ {
+					atomic.AddInt32(&visits[i*inner+k], 1)
+				}
+			})
+		})
+	}
+	for _, j := range jobs {
+		j.Wait()
+	}
+	for i := 0; i < outer; i++ {
+		(<-groups).Wait()
+	}
+	for i, v := range visits {
+		if v != 1 {
+			t.Fatal("index", i, "visited", v, "times")
+		}
+	}
+}
+
+// transformVectors applies a rotation to the specified range of vectors.
+func transformVectors(vectors []math32.Vector3, m *math32.Matrix4, start, end int) {
+
+	for i := start; i < end; i++ {
+		vectors[i].ApplyMatrix4(m)
+	}
+}
+
+// Benchmark the transformation of 1M vectors in one goroutine and split across the workers,
+// for the speedup by the number of workers
+func BenchmarkScheduleForEach(b *testing.B) {
+
+	vectors := make([]math32.Vector3, 1<<20)
+	for i := range vectors {
+		vectors[i].Set(float32(i), 1, 2)
+	}
+	var m math32.Matrix4
+	m.MakeRotationY(0.5)
+
+	b.Run("serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			transformVectors(vectors, &m, 0, len(vectors))
+		}
+	})
+	for workers := 1; ; workers *= 2 {
+		if workers > runtime.NumCPU() {
+			workers = runtime.NumCPU()
+		}
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			js := NewJobSystem(workers)
+			defer js.Dispose()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				js.ScheduleForEach(len(vectors), 8192, func(start, end int) {
+					transformVectors(vectors, &m, start, end)
+				}).Wait()
+			}
+		})
+		if workers == runtime.NumCPU() {
+			break
+		}
+	}
+}
@@ -42,6 +42,20 @@ type Node struct {
 	quaternion  math32.Quaternion // Node rotation specified as a Quaternion (relative to parent)
 	matrix      math32.Matrix4    // Local transform matrix. Contains all position/rotation/scale information (relative to parent)
 	matrixWorld math32.Matrix4    // World transform matrix. Contains all absolute position/rotation/scale information (i.e. relative to very top parent, generally the scene)
+
+	// World transform cache of WorldTransform
+	localVersion uint64     // Incremented when the local matrix changes
+	world        worldCache // World transform computed from the parent chain
+}
+
+// worldCache is the world transform of a node with the versions it was computed from.
+type worldCache struct {
+	matrix        math32.Matrix4
+	valid         bool
+	version       uint64 // Incremented when the matrix changes
+	local         uint64 // Local matrix version of the node
+	parent        *Node  // Parent of the node
+	parentVersion uint64 // World matrix version of the parent
 }
 
 // NewNode returns a pointer to a new Node.
@@ -273,6 +287,44 @@ func (n *Node) FindLoaderID(id string) INode {
 	return finder(n, id)
 }
 
+// FindByName looks in the specified node and in all its children
+// for a node with the specified name and if found returns it.
+// Returns nil if not found.
+func (n *Node) FindByName(name string) INode {
+
+	var found INode
+	n.Traverse(func(node INode) bool {
+		if node.GetNode().name == name {
+			found = node
+			return true
+		}
+		return false
+	})
+	return found
+}
+
+// Traverse calls the specified function for this node and all its descendants in pre-order:
+// each node before its children. The function returns false to continue or true to stop the traversal.
+// Returns true if the traversal was stopped.
+func (n *Node) Traverse(cb func(node INode) bool) bool {
+
+	return traverse(n, cb)
+}
+
+// traverse visits the specified node and its descendants in pre-order.
+func traverse(inode INode, cb func(node INode) bool) bool {
+
+	if cb(inode) {
+		return true
+	}
+	for _, child := range inode.GetNode().children {
+		if traverse(child, cb) {
+			return true
+		}
+	}
+	return false
+}
+
 // Children returns the list of children.
 func (n *Node) Children() []INode {
 
@@ -670,6 +722,7 @@ func (n *Node) SetMatrix(m *math32.Matrix4) {
 	n.matrix = *m
 	n.matrix.Decompose(&n.position, &n.quaternion, &n.scale)
 	n.rotNeedsUpdate = true
+	n.localVersion++
 }
 
 // Matrix returns a copy of the local transformation matrix.
@@ -740,9 +793,48 @@ func (n *Node) UpdateMatrix() bool {
 	}
 	n.matrix.Compose(&n.position, &n.quaternion, &n.scale)
 	n.matNeedsUpdate = false
+	n.localVersion++
 	return true
 }
 
+// WorldTransform returns a copy of the world transform matrix computed from the local matrices
+// of this node and of its ancestors, without updating the world matrices of the tree as
+// UpdateMatrixWorld does. The result is cached and only recomputed when the local matrix
+// of the node or of one of its ancestors changes, or when an ancestor changes.
+func (n *Node) WorldTransform() math32.Matrix4 {
+
+	return *n.worldTransform()
+}
+
+// worldTransform returns a pointer to the cached world transform, updating it if necessary.
+func (n *Node) worldTransform() *math32.Matrix4 {
+
+	n.UpdateMatrix()
+	var parent *Node
+	var parentMatrix *math32.Matrix4
+	var parentVersion uint64
+	if n.parent != nil {
+		parent = n.parent.GetNode()
+		parentMatrix = parent.worldTransform()
+		parentVersion = parent.world.version
+	}
+	c := &n.world
+	if c.valid && c.local == n.localVersion && c.parent == parent && c.parentVersion == parentVersion {
+		return &c.matrix
+	}
+	if parent == nil {
+		c.matrix = n.matrix
+	} else {
+		c.matrix.MultiplyMatrices(parentMatrix, &n.matrix)
+	}
+	c.valid = true
+	c.version++
+	c.local = n.localVersion
+	c.parent = parent
+	c.parentVersion = parentVersion
+	return &c.matrix
+}
+
 // UpdateMatrixWorld updates this node world transform matrix and of all its children
 func (n *Node) UpdateMatrixWorld() {
 
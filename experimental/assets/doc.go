@@ -0,0 +1,7 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package assets implements the asynchronous loading of reference counted assets, with hot reloading.
+// WARNING: This package is experimental and incomplete!
+package assets
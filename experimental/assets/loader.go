@@ -0,0 +1,370 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package assets
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/g3n/engine/texture"
+)
+
+// Asset is the interface for the loaded assets.
+type Asset interface {
+	// Dispose releases the resources of the asset, when all its handles are released or it is reloaded.
+	Dispose()
+}
+
+// DecodeFunc is the type of the functions which decode the asset in the specified file.
+// They run in a background goroutine, so they must not call OpenGL.
+type DecodeFunc func(path string) (Asset, error)
+
+// Loader is the interface of the asset loaders used by Load: AssetLoader and MockAssetLoader.
+type Loader interface {
+	acquire(path string) *entry
+	release(e *entry)
+}
+
+// entry is the state of a loaded asset, shared by all its handles.
+type entry struct {
+	path    string
+	refs    int           // number of handles not released
+	done    chan struct{} // closed when the first load finishes
+	asset   Asset
+	err     error
+	modTime time.Time // modification time of the loaded file
+	reload  *reload   // finished reload not applied yet
+	loading bool      // a load or reload is running
+	dropped bool      // all the handles were released while loading
+}
+
+// reload is the result of the decoding of a modified asset file.
+type reload struct {
+	asset   Asset
+	err     error
+	modTime time.Time
+}
+
+// Future is the handle of an asset of type T which may not be loaded yet.
+type Future[T Asset] struct {
+	l        Loader
+	e        *entry
+	released bool
+}
+
+// Ready returns if the asset finished loading, successfully or not, without blocking.
+func (f *Future[T]) Ready() bool {
+
+	select {
+	case <-f.e.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Get waits for the asset to finish loading and returns it or the loading error.
+// After a hot reload it returns the reloaded asset.
+func (f *Future[T]) Get() (T, error) {
+
+	<-f.e.done
+	var zero T
+	entryMu.Lock()
+	asset, err := f.e.asset, f.e.err
+	entryMu.Unlock()
+	if err != nil {
+		return zero, err
+	}
+	t, ok := asset.(T)
+	if !ok {
+		return zero, fmt.Errorf("asset %s is %T, not %T", f.e.path, asset, zero)
+	}
+	return t, nil
+}
+
+// Path returns the path of the asset relative to the root of its loader.
+func (f *Future[T]) Path() string {
+
+	return f.e.path
+}
+
+// Release releases this handle, disposing the asset if it was its last handle.
+// Get must not be called after.
+func (f *Future[T]) Release() {
+
+	if !f.released {
+		f.released = true
+		f.l.release(f.e)
+	}
+}
+
+// entryMu protects the fields of the entries changed by the loading goroutines.
+var entryMu sync.Mutex
+
+// Load starts loading the asset of type T in the specified path with the specified loader, unless it is
+// already loaded or loading, and returns a new handle to it, which must be released when no longer needed.
+func Load[T Asset](l Loader, path string) *Future[T] {
+
+	return &Future[T]{l: l, e: l.acquire(path)}
+}
+
+// AssetLoader loads assets from the files of a root directory in background goroutines.
+// The assets are decoded by the function registered for their file extension. Each asset is
+// loaded once and shared by its handles: it is disposed when all of them are released.
+// The handles must be released, and Update and Dispose called, from the goroutine which owns the OpenGL context,
+// because they dispose assets.
+type AssetLoader struct {
+	root          string
+	WatchInterval time.Duration // interval between the checks of the modification of the files when watching
+	decoders      map[string]DecodeFunc
+	entries       map[string]*entry
+	disposals     []Asset       // assets to dispose at the next update
+	stopWatch     chan struct{} // closed to stop the watching goroutine
+}
+
+// NewAssetLoader creates and returns a pointer to a new AssetLoader of the files in the specified
+// root directory, which decodes the image files (.png, .jpg, .jpeg, .gif and .tga) as *texture.Texture2D
+// and the .json files as *ScriptableObject.
+func NewAssetLoader(root string) *AssetLoader {
+
+	al := new(AssetLoader)
+	al.root = root
+	al.WatchInterval = 500 * time.Millisecond
+	al.decoders = make(map[string]DecodeFunc)
+	al.entries = make(map[string]*entry)
+	for _, ext := range []string{".png", ".jpg", ".jpeg", ".gif", ".tga"} {
+		al.RegisterDecoder(ext, decodeTexture)
+	}
+	al.RegisterDecoder(".json", decodeScriptableObject)
+	return al
+}
+
+// decodeTexture decodes an image file as a texture, uploaded when first rendered.
+func decodeTexture(path string) (Asset, error) {
+
+	return texture.NewTexture2DFromFile(path)
+}
+
+// RegisterDecoder sets the function which decodes the files with the specified extension, including the dot.
+func (al *AssetLoader) RegisterDecoder(ext string, decode DecodeFunc) {
+
+	al.decoders[strings.ToLower(ext)] = decode
+}
+
+// Preload starts loading the assets in the specified paths without taking handles to them.
+// They are kept until loaded and released like the other assets.
+func (al *AssetLoader) Preload(paths []string) {
+
+	for _, path := range paths {
+		e := al.acquire(path)
+		entryMu.Lock()
+		e.refs--
+		entryMu.Unlock()
+	}
+}
+
+// acquire returns the entry of the specified path with a new reference, starting to load it if needed.
+func (al *AssetLoader) acquire(path string) *entry {
+
+	path = filepath.Clean(path)
+	entryMu.Lock()
+	defer entryMu.Unlock()
+	e, ok := al.entries[path]
+	if !ok {
+		e = &entry{path: path, done: make(chan struct{}), loading: true}
+		al.entries[path] = e
+		go al.load(e)
+	}
+	e.refs++
+	return e
+}
+
+// load decodes the asset of the specified entry for the first time.
+func (al *AssetLoader) load(e *entry) {
+
+	asset, modTime, err := al.decode(e.path)
+	entryMu.Lock()
+	e.asset, e.err, e.modTime = asset, err, modTime
+	e.loading = false
+	if e.dropped && asset != nil {
+		al.disposals = append(al.disposals, asset)
+		e.asset = nil
+	}
+	entryMu.Unlock()
+	close(e.done)
+}
+
+// decode decodes the asset in the specified path with the decoder of its extension
+// and returns it with the modification time of its file.
+func (al *AssetLoader) decode(path string) (Asset, time.Time, error) {
+
+	full := filepath.Join(al.root, path)
+	fi, err := os.Stat(full)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	decode, ok := al.decoders[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return nil, fi.ModTime(), fmt.Errorf("no decoder for asset %s", path)
+	}
+	asset, err := decode(full)
+	if err != nil {
+		return nil, fi.ModTime(), err
+	}
+	return asset, fi.ModTime(), nil
+}
+
+// release releases a reference to the specified entry, disposing its asset if it was the last one.
+func (al *AssetLoader) release(e *entry) {
+
+	entryMu.Lock()
+	e.refs--
+	if e.refs > 0 || al.entries[e.path] != e {
+		entryMu.Unlock()
+		return
+	}
+	delete(al.entries, e.path)
+	// A running load or reload disposes its asset when it finishes
+	e.dropped = true
+	var dispose []Asset
+	if e.asset != nil {
+		dispose = append(dispose, e.asset)
+		e.asset = nil
+	}
+	if e.reload != nil && e.reload.asset != nil {
+		dispose = append(dispose, e.reload.asset)
+	}
+	entryMu.Unlock()
+	for _, a := range dispose {
+		a.Dispose()
+	}
+}
+
+// Watch starts or stops watching the modification times of the files of the loaded assets,
+// which are reloaded in background goroutines when modified. The reloaded assets replace
+// the previous ones, which are disposed, at the next Update.
+// This is meant for development: modifying a file while it is read may fail to reload it.
+func (al *AssetLoader) Watch(enabled bool) {
+
+	if enabled == (al.stopWatch != nil) {
+		return
+	}
+	if !enabled {
+		close(al.stopWatch)
+		al.stopWatch = nil
+		return
+	}
+	al.stopWatch = make(chan struct{})
+	go al.watch(al.stopWatch, al.WatchInterval)
+}
+
+// watch checks the files of the loaded assets at the specified interval until stop is closed.
+func (al *AssetLoader) watch(stop chan struct{}, interval time.Duration) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		entryMu.Lock()
+		var loaded []*entry
+		for _, e := range al.entries {
+			if !e.loading && e.reload == nil {
+				loaded = append(loaded, e)
+			}
+		}
+		entryMu.Unlock()
+		for _, e := range loaded {
+			fi, err := os.Stat(filepath.Join(al.root, e.path))
+			if err != nil {
+				continue
+			}
+			entryMu.Lock()
+			if fi.ModTime().After(e.modTime) && !e.loading && !e.dropped {
+				e.loading = true
+				go al.reload(e)
+			}
+			entryMu.Unlock()
+		}
+	}
+}
+
+// reload decodes the modified file of the specified entry, to be applied at the next update.
+func (al *AssetLoader) reload(e *entry) {
+
+	asset, modTime, err := al.decode(e.path)
+	entryMu.Lock()
+	defer entryMu.Unlock()
+	e.loading = false
+	if e.dropped {
+		if asset != nil {
+			al.disposals = append(al.disposals, asset)
+		}
+		return
+	}
+	e.reload = &reload{asset: asset, err: err, modTime: modTime}
+}
+
+// Update applies the finished reloads and disposes the replaced assets and the assets
+// whose handles were all released while loading. Returns the paths of the reloaded assets.
+// It should be called once per frame.
+func (al *AssetLoader) Update() []string {
+
+	var reloaded []string
+	entryMu.Lock()
+	dispose := al.disposals
+	al.disposals = nil
+	for _, e := range al.entries {
+		r := e.reload
+		if r == nil {
+			continue
+		}
+		e.reload = nil
+		e.modTime = r.modTime
+		if r.err != nil {
+			log.Error("reloading %s: %v", e.path, r.err)
+			continue
+		}
+		if e.asset != nil {
+			dispose = append(dispose, e.asset)
+		}
+		e.asset, e.err = r.asset, nil
+		reloaded = append(reloaded, e.path)
+	}
+	entryMu.Unlock()
+	for _, a := range dispose {
+		a.Dispose()
+	}
+	return reloaded
+}
+
+// Dispose stops watching and disposes all the loaded assets, invalidating their handles.
+func (al *AssetLoader) Dispose() {
+
+	al.Watch(false)
+	entryMu.Lock()
+	entries := al.entries
+	al.entries = make(map[string]*entry)
+	entryMu.Unlock()
+	for _, e := range entries {
+		<-e.done
+		entryMu.Lock()
+		e.dropped = true
+		asset := e.asset
+		e.asset = nil
+		entryMu.Unlock()
+		if asset != nil {
+			asset.Dispose()
+		}
+	}
+	al.Update()
+}
@@ -0,0 +1,84 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package assets
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// MockAssetLoader is a Loader which returns assets set in memory, already loaded,
+// for the tests of the code which loads assets. It counts the handles of each path.
+type MockAssetLoader struct {
+	assets map[string]Asset
+	errors map[string]error
+	refs   map[string]int
+	loads  map[string]int
+}
+
+// NewMockAssetLoader creates and returns a pointer to a new MockAssetLoader without assets.
+func NewMockAssetLoader() *MockAssetLoader {
+
+	ml := new(MockAssetLoader)
+	ml.assets = make(map[string]Asset)
+	ml.errors = make(map[string]error)
+	ml.refs = make(map[string]int)
+	ml.loads = make(map[string]int)
+	return ml
+}
+
+// Set sets the asset returned for the specified path.
+func (ml *MockAssetLoader) Set(path string, asset Asset) {
+
+	path = filepath.Clean(path)
+	ml.assets[path] = asset
+	delete(ml.errors, path)
+}
+
+// SetError sets the error returned when loading the specified path.
+func (ml *MockAssetLoader) SetError(path string, err error) {
+
+	path = filepath.Clean(path)
+	ml.errors[path] = err
+	delete(ml.assets, path)
+}
+
+// Loads returns the number of times the specified path was loaded.
+func (ml *MockAssetLoader) Loads(path string) int {
+
+	return ml.loads[filepath.Clean(path)]
+}
+
+// Refs returns the number of handles of the specified path not released.
+func (ml *MockAssetLoader) Refs(path string) int {
+
+	return ml.refs[filepath.Clean(path)]
+}
+
+// acquire satisfies the Loader interface.
+// Returns a loaded entry with the asset or error set for the path.
+func (ml *MockAssetLoader) acquire(path string) *entry {
+
+	path = filepath.Clean(path)
+	e := &entry{path: path, done: make(chan struct{}), refs: 1}
+	if err, ok := ml.errors[path]; ok {
+		e.err = err
+	} else if asset, ok := ml.assets[path]; ok {
+		e.asset = asset
+	} else {
+		e.err = fmt.Errorf("asset %s not set", path)
+	}
+	close(e.done)
+	ml.refs[path]++
+	ml.loads[path]++
+	return e
+}
+
+// release satisfies the Loader interface.
+// The assets are not disposed, as they belong to the test.
+func (ml *MockAssetLoader) release(e *entry) {
+
+	ml.refs[e.path]--
+}
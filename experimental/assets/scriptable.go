@@ -0,0 +1,258 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package assets
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/g3n/engine/math32"
+)
+
+// ScriptableObject is the configuration of assets, such as the stats of enemies or the parameters
+// of weapons, as named values read from a JSON object, which can be tweaked without recompiling.
+// The values of nested objects are named by the path of their keys separated by dots, such as "enemy.speed".
+// It can be loaded by LoadScriptableObject or by an AssetLoader, which decodes the .json files as scriptable objects.
+type ScriptableObject struct {
+	WatchInterval time.Duration // interval between the checks of the modification of the file when watching
+	path          string
+	values        map[string]interface{}
+	modTime       time.Time                    // modification time of the loaded file
+	callbacks     []func(so *ScriptableObject) // called after the reloads
+	mu            sync.Mutex                   // protects the reload
+	reload        *scriptableReload            // finished reload not applied yet
+	stopWatch     chan struct{}                // closed to stop the watching goroutine
+}
+
+// scriptableReload is the result of the parsing of a modified scriptable object file.
+type scriptableReload struct {
+	values  map[string]interface{}
+	err     error
+	modTime time.Time
+}
+
+// LoadScriptableObject creates and returns a pointer to a new ScriptableObject
+// with the values of the JSON object in the file with the specified path.
+func LoadScriptableObject(path string) (*ScriptableObject, error) {
+
+	values, modTime, err := parseScriptableObject(path)
+	if err != nil {
+		return nil, err
+	}
+	so := new(ScriptableObject)
+	so.WatchInterval = 500 * time.Millisecond
+	so.path = path
+	so.values = values
+	so.modTime = modTime
+	return so, nil
+}
+
+// decodeScriptableObject decodes a JSON file as a scriptable object.
+func decodeScriptableObject(path string) (Asset, error) {
+
+	return LoadScriptableObject(path)
+}
+
+// parseScriptableObject parses the JSON object in the specified file
+// and returns its values with the modification time of the file.
+func parseScriptableObject(path string) (map[string]interface{}, time.Time, error) {
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fi.ModTime(), err
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fi.ModTime(), fmt.Errorf("parsing %s: %v", path, err)
+	}
+	return values, fi.ModTime(), nil
+}
+
+// Path returns the path of the file of this scriptable object.
+func (so *ScriptableObject) Path() string {
+
+	return so.path
+}
+
+// Get returns the value with the specified key, as decoded by encoding/json, and if it exists.
+func (so *ScriptableObject) Get(key string) (interface{}, bool) {
+
+	var value interface{} = so.values
+	for _, name := range strings.Split(key, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[name]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// GetFloat32 returns the number with the specified key, or the default value if it is not a number.
+func (so *ScriptableObject) GetFloat32(key string, defaultVal float32) float32 {
+
+	if v, ok := so.Get(key); ok {
+		if f, ok := v.(float64); ok {
+			return float32(f)
+		}
+	}
+	return defaultVal
+}
+
+// GetInt returns the number with the specified key truncated to an integer,
+// or the default value if it is not a number.
+func (so *ScriptableObject) GetInt(key string, defaultVal int) int {
+
+	if v, ok := so.Get(key); ok {
+		if f, ok := v.(float64); ok {
+			return int(f)
+		}
+	}
+	return defaultVal
+}
+
+// GetString returns the string with the specified key, or the default value if it is not a string.
+func (so *ScriptableObject) GetString(key string, defaultVal string) string {
+
+	if v, ok := so.Get(key); ok {
+		if s, ok := v.(string); ok {
+			return s
+		}
+	}
+	return defaultVal
+}
+
+// GetBool returns the boolean with the specified key, or the default value if it is not a boolean.
+func (so *ScriptableObject) GetBool(key string, defaultVal bool) bool {
+
+	if v, ok := so.Get(key); ok {
+		if b, ok := v.(bool); ok {
+			return b
+		}
+	}
+	return defaultVal
+}
+
+// GetVector3 returns a pointer to a new vector with the value with the specified key, an array of
+// three numbers or an object with the numbers "x", "y" and "z", or the default value if it is not a vector.
+func (so *ScriptableObject) GetVector3(key string, defaultVal *math32.Vector3) *math32.Vector3 {
+
+	v, ok := so.Get(key)
+	if !ok {
+		return defaultVal
+	}
+	var components [3]interface{}
+	switch val := v.(type) {
+	case []interface{}:
+		if len(val) != 3 {
+			return defaultVal
+		}
+		copy(components[:], val)
+	case map[string]interface{}:
+		components = [3]interface{}{val["x"], val["y"], val["z"]}
+	default:
+		return defaultVal
+	}
+	var xyz [3]float32
+	for i, c := range components {
+		f, ok := c.(float64)
+		if !ok {
+			return defaultVal
+		}
+		xyz[i] = float32(f)
+	}
+	return math32.NewVector3(xyz[0], xyz[1], xyz[2])
+}
+
+// OnChange registers a callback called by Update after the values of this scriptable object are reloaded.
+func (so *ScriptableObject) OnChange(cb func(so *ScriptableObject)) {
+
+	so.callbacks = append(so.callbacks, cb)
+}
+
+// Watch starts or stops watching the modification time of the file of this scriptable object, which is
+// parsed again in a background goroutine when modified. The values are replaced at the next Update.
+// The scriptable objects loaded by an AssetLoader are instead reloaded as new objects by its Watch.
+func (so *ScriptableObject) Watch(enabled bool) {
+
+	if enabled == (so.stopWatch != nil) {
+		return
+	}
+	if !enabled {
+		close(so.stopWatch)
+		so.stopWatch = nil
+		return
+	}
+	so.stopWatch = make(chan struct{})
+	go so.watch(so.stopWatch, so.WatchInterval)
+}
+
+// watch checks the file at the specified interval until stop is closed.
+func (so *ScriptableObject) watch(stop chan struct{}, interval time.Duration) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	so.mu.Lock()
+	modTime := so.modTime
+	so.mu.Unlock()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		fi, err := os.Stat(so.path)
+		if err != nil || !fi.ModTime().After(modTime) {
+			continue
+		}
+		values, mt, err := parseScriptableObject(so.path)
+		modTime = mt
+		so.mu.Lock()
+		so.reload = &scriptableReload{values: values, err: err, modTime: mt}
+		so.mu.Unlock()
+	}
+}
+
+// Update applies the finished reload of the values and calls the registered callbacks.
+// Returns if the values were reloaded. It should be called once per frame when watching.
+func (so *ScriptableObject) Update() bool {
+
+	so.mu.Lock()
+	r := so.reload
+	so.reload = nil
+	if r != nil {
+		so.modTime = r.modTime
+	}
+	so.mu.Unlock()
+	if r == nil {
+		return false
+	}
+	if r.err != nil {
+		log.Error("reloading %s: %v", so.path, r.err)
+		return false
+	}
+	so.values = r.values
+	for _, cb := range so.callbacks {
+		cb(so)
+	}
+	return true
+}
+
+// Dispose satisfies the Asset interface and stops watching.
+func (so *ScriptableObject) Dispose() {
+
+	so.Watch(false)
+}
@@ -0,0 +1,7 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package behavior implements behavior trees for the decisions of AI agents.
+// WARNING: This package is experimental and incomplete!
+package behavior
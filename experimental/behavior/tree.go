@@ -0,0 +1,408 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package behavior
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// BTStatus is the result of the evaluation of a node of a behavior tree.
+type BTStatus int
+
+// Node statuses
+const (
+	Running BTStatus = iota // the node has not finished and must be ticked again
+	Success
+	Failure
+)
+
+// String returns the name of the status.
+func (s BTStatus) String() string {
+
+	switch s {
+	case Running:
+		return "Running"
+	case Success:
+		return "Success"
+	case Failure:
+		return "Failure"
+	}
+	return fmt.Sprintf("BTStatus(%d)", int(s))
+}
+
+// BTContext is the state of the agent passed to the nodes of its behavior tree.
+type BTContext struct {
+	Entity     interface{}            // world state of the agent owning the tree
+	Blackboard map[string]interface{} // values shared by the nodes
+	DeltaTime  float32                // time since the previous tick in seconds
+}
+
+// NewBTContext creates and returns a pointer to a new BTContext of the specified entity with an empty blackboard.
+func NewBTContext(entity interface{}) *BTContext {
+
+	return &BTContext{Entity: entity, Blackboard: make(map[string]interface{})}
+}
+
+// Node is the interface for the nodes of a behavior tree.
+// The nodes returning Running continue from their state at the next tick.
+type Node interface {
+	Tick(ctx *BTContext) BTStatus
+	Children() []Node
+	String() string // description of the node, for PrintTree
+}
+
+// BehaviorTree is a tree of nodes evaluated at every frame from its root.
+type BehaviorTree struct {
+	Root Node
+}
+
+// NewBehaviorTree creates and returns a pointer to a new BehaviorTree with the specified root.
+func NewBehaviorTree(root Node) *BehaviorTree {
+
+	return &BehaviorTree{Root: root}
+}
+
+// Tick evaluates the tree with the specified context and returns the status of its root.
+func (bt *BehaviorTree) Tick(ctx *BTContext) BTStatus {
+
+	return bt.Root.Tick(ctx)
+}
+
+// PrintTree writes the description of the nodes of the tree to the specified writer,
+// one node per line indented by its depth.
+func (bt *BehaviorTree) PrintTree(w io.Writer) error {
+
+	return printNode(w, bt.Root, 0)
+}
+
+// printNode writes the description of the specified node and of its children.
+func printNode(w io.Writer, n Node, depth int) error {
+
+	_, err := fmt.Fprintf(w, "%s%s\n", strings.Repeat("  ", depth), n)
+	if err != nil {
+		return err
+	}
+	for _, c := range n.Children() {
+		err = printNode(w, c, depth+1)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sequence ticks its children in order while they succeed. It fails when a child fails
+// and succeeds when all the children succeed.
+type Sequence struct {
+	name     string
+	children []Node
+	current  int // child running
+}
+
+// NewSequence creates and returns a pointer to a new Sequence with the specified name and children.
+func NewSequence(name string, children ...Node) *Sequence {
+
+	return &Sequence{name: name, children: children}
+}
+
+// Tick satisfies the Node interface.
+func (s *Sequence) Tick(ctx *BTContext) BTStatus {
+
+	for s.current < len(s.children) {
+		status := s.children[s.current].Tick(ctx)
+		if status == Running {
+			return Running
+		}
+		if status == Failure {
+			s.current = 0
+			return Failure
+		}
+		s.current++
+	}
+	s.current = 0
+	return Success
+}
+
+// Children satisfies the Node interface.
+func (s *Sequence) Children() []Node {
+
+	return s.children
+}
+
+// String satisfies the Node interface.
+func (s *Sequence) String() string {
+
+	return "Sequence " + s.name
+}
+
+// Selector ticks its children in order while they fail. It succeeds when a child succeeds
+// and fails when all the children fail.
+type Selector struct {
+	name     string
+	children []Node
+	current  int // child running
+}
+
+// NewSelector creates and returns a pointer to a new Selector with the specified name and children.
+func NewSelector(name string, children ...Node) *Selector {
+
+	return &Selector{name: name, children: children}
+}
+
+// Tick satisfies the Node interface.
+func (s *Selector) Tick(ctx *BTContext) BTStatus {
+
+	for s.current < len(s.children) {
+		status := s.children[s.current].Tick(ctx)
+		if status == Running {
+			return Running
+		}
+		if status == Success {
+			s.current = 0
+			return Success
+		}
+		s.current++
+	}
+	s.current = 0
+	return Failure
+}
+
+// Children satisfies the Node interface.
+func (s *Selector) Children() []Node {
+
+	return s.children
+}
+
+// String satisfies the Node interface.
+func (s *Selector) String() string {
+
+	return "Selector " + s.name
+}
+
+// Parallel ticks all its children not finished at each tick. It succeeds when the specified number
+// of children succeed and fails when so many children fail that this number can no longer be reached.
+type Parallel struct {
+	name      string
+	children  []Node
+	threshold int
+	statuses  []BTStatus // statuses of the children in the current run
+}
+
+// NewParallel creates and returns a pointer to a new Parallel with the specified name, number of children
+// which must succeed and children. A threshold of zero or more than the children requires all of them.
+func NewParallel(name string, successThreshold int, children ...Node) *Parallel {
+
+	if successThreshold <= 0 || successThreshold > len(children) {
+		successThreshold = len(children)
+	}
+	return &Parallel{name: name, children: children, threshold: successThreshold, statuses: make([]BTStatus, len(children))}
+}
+
+// Tick satisfies the Node interface.
+func (p *Parallel) Tick(ctx *BTContext) BTStatus {
+
+	successes, failures := 0, 0
+	for i, c := range p.children {
+		if p.statuses[i] == Running {
+			p.statuses[i] = c.Tick(ctx)
+		}
+		switch p.statuses[i] {
+		case Success:
+			successes++
+		case Failure:
+			failures++
+		}
+	}
+	status := Running
+	if successes >= p.threshold {
+		status = Success
+	} else if failures > len(p.children)-p.threshold {
+		status = Failure
+	}
+	if status != Running {
+		for i := range p.statuses {
+			p.statuses[i] = Running
+		}
+	}
+	return status
+}
+
+// Children satisfies the Node interface.
+func (p *Parallel) Children() []Node {
+
+	return p.children
+}
+
+// String satisfies the Node interface.
+func (p *Parallel) String() string {
+
+	return fmt.Sprintf("Parallel %s (%d/%d)", p.name, p.threshold, len(p.children))
+}
+
+// Decorator modifies the status of its child with a function.
+type Decorator struct {
+	name   string
+	child  Node
+	modify func(status BTStatus) BTStatus
+}
+
+// NewDecorator creates and returns a pointer to a new Decorator with the specified name
+// and child, which returns the status of the child modified by the specified function.
+func NewDecorator(name string, child Node, modify func(status BTStatus) BTStatus) *Decorator {
+
+	return &Decorator{name: name, child: child, modify: modify}
+}
+
+// NewInverter creates and returns a pointer to a new Decorator which inverts the success and failure of its child.
+func NewInverter(child Node) *Decorator {
+
+	return NewDecorator("Inverter", child, func(status BTStatus) BTStatus {
+		switch status {
+		case Success:
+			return Failure
+		case Failure:
+			return Success
+		}
+		return status
+	})
+}
+
+// NewSucceeder creates and returns a pointer to a new Decorator which succeeds when its child finishes.
+func NewSucceeder(child Node) *Decorator {
+
+	return NewDecorator("Succeeder", child, func(status BTStatus) BTStatus {
+		if status == Running {
+			return Running
+		}
+		return Success
+	})
+}
+
+// Tick satisfies the Node interface.
+func (d *Decorator) Tick(ctx *BTContext) BTStatus {
+
+	return d.modify(d.child.Tick(ctx))
+}
+
+// Children satisfies the Node interface.
+func (d *Decorator) Children() []Node {
+
+	return []Node{d.child}
+}
+
+// String satisfies the Node interface.
+func (d *Decorator) String() string {
+
+	return d.name
+}
+
+// Repeater ticks its child again each time it finishes, until it finishes the specified number of times.
+// It returns Running while repeating and Success after the last repetition.
+type Repeater struct {
+	child Node
+	count int // number of repetitions or zero to repeat forever
+	done  int // repetitions finished in the current run
+}
+
+// NewRepeater creates and returns a pointer to a new Repeater of the specified child,
+// which repeats it the specified number of times, or forever if zero.
+func NewRepeater(child Node, count int) *Repeater {
+
+	return &Repeater{child: child, count: count}
+}
+
+// Tick satisfies the Node interface.
+// The child is ticked once per tick, so each repetition takes at least one frame.
+func (r *Repeater) Tick(ctx *BTContext) BTStatus {
+
+	if r.child.Tick(ctx) == Running {
+		return Running
+	}
+	r.done++
+	if r.count > 0 && r.done >= r.count {
+		r.done = 0
+		return Success
+	}
+	return Running
+}
+
+// Children satisfies the Node interface.
+func (r *Repeater) Children() []Node {
+
+	return []Node{r.child}
+}
+
+// String satisfies the Node interface.
+func (r *Repeater) String() string {
+
+	if r.count == 0 {
+		return "Repeater forever"
+	}
+	return fmt.Sprintf("Repeater %d", r.count)
+}
+
+// Leaf is a node which runs a function, for the conditions and actions of the agents.
+type Leaf struct {
+	name string
+	fn   func(ctx *BTContext) BTStatus
+}
+
+// NewLeaf creates and returns a pointer to a new Leaf with the specified name which returns
+// the status of the specified function.
+func NewLeaf(name string, fn func(ctx *BTContext) BTStatus) *Leaf {
+
+	return &Leaf{name: name, fn: fn}
+}
+
+// Tick satisfies the Node interface.
+func (l *Leaf) Tick(ctx *BTContext) BTStatus {
+
+	return l.fn(ctx)
+}
+
+// Children satisfies the Node interface.
+func (l *Leaf) Children() []Node {
+
+	return nil
+}
+
+// String satisfies the Node interface.
+func (l *Leaf) String() string {
+
+	return "Leaf " + l.name
+}
+
+// subTree is a node which evaluates another behavior tree.
+type subTree struct {
+	tree *BehaviorTree
+}
+
+// SubTree returns a node which evaluates the root of the specified tree, to reuse it in other trees.
+// The state of the running nodes is shared by all the uses of the tree, so a tree
+// should only be used once by each agent.
+func SubTree(tree *BehaviorTree) Node {
+
+	return &subTree{tree: tree}
+}
+
+// Tick satisfies the Node interface.
+func (st *subTree) Tick(ctx *BTContext) BTStatus {
+
+	return st.tree.Tick(ctx)
+}
+
+// Children satisfies the Node interface.
+func (st *subTree) Children() []Node {
+
+	return []Node{st.tree.Root}
+}
+
+// String satisfies the Node interface.
+func (st *subTree) String() string {
+
+	return "SubTree"
+}
@@ -0,0 +1,236 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package collision
+
+import "github.com/g3n/engine/math32"
+
+// Broadphase is the interface for algorithms which find the pairs of objects
+// whose bounding boxes overlap, before the more expensive narrowphase tests.
+type Broadphase interface {
+	Add(id int, bounds *math32.Box3)
+	Remove(id int)
+	Update(id int, bounds *math32.Box3)
+	OverlappingPairs() [][2]int
+}
+
+// sapEndpoint is the minimum or maximum extent of an object along an axis.
+type sapEndpoint struct {
+	value float32
+	min   bool
+	obj   *sapObject
+}
+
+// before returns if this endpoint is sorted before the other.
+// Minimums go first at equal values so touching boxes overlap.
+func (e *sapEndpoint) before(other *sapEndpoint) bool {
+
+	return e.value < other.value || (e.value == other.value && e.min && !other.min)
+}
+
+// side returns the index of this endpoint in sapObject.index.
+func (e *sapEndpoint) side() int {
+
+	if e.min {
+		return 0
+	}
+	return 1
+}
+
+// sapObject is an object of the sweep and prune broadphase.
+type sapObject struct {
+	id     int
+	bounds math32.Box3
+	index  [3][2]int // position of the minimum and maximum endpoints in each axis list
+	pairs  int       // number of overlapping pairs of this object
+}
+
+// SAPBroadphase is a sweep and prune broadphase, which keeps the extents of the bounding boxes
+// sorted along each axis. The updated extents are sorted again by insertion sort before the pairs
+// are needed; when the objects move slowly this takes few swaps of adjacent endpoints and the
+// overlapping pairs are maintained incrementally from those swaps.
+type SAPBroadphase struct {
+	axes    [3][]sapEndpoint
+	dirty   bool // some extents were updated since the lists were sorted
+	objects map[int]*sapObject
+	pairs   [][2]int
+	pairIdx map[[2]int]int // position of each pair in the pairs slice
+}
+
+// NewSAPBroadphase creates and returns a pointer to a new empty SAPBroadphase.
+func NewSAPBroadphase() *SAPBroadphase {
+
+	sap := new(SAPBroadphase)
+	sap.objects = make(map[int]*sapObject)
+	sap.pairIdx = make(map[[2]int]int)
+	return sap
+}
+
+// Add adds the object with the specified id and bounding box.
+// Adding an existing object updates its bounding box.
+func (sap *SAPBroadphase) Add(id int, bounds *math32.Box3) {
+
+	if _, ok := sap.objects[id]; ok {
+		sap.Update(id, bounds)
+		return
+	}
+	obj := &sapObject{id: id, bounds: *bounds}
+	sap.objects[id] = obj
+	// The endpoints are appended at the end of the lists and sorted into place when next needed
+	for axis := range sap.axes {
+		list := sap.axes[axis]
+		obj.index[axis] = [2]int{len(list), len(list) + 1}
+		sap.axes[axis] = append(list,
+			sapEndpoint{value: bounds.Min.Component(axis), min: true, obj: obj},
+			sapEndpoint{value: bounds.Max.Component(axis), obj: obj},
+		)
+	}
+	sap.dirty = true
+}
+
+// Remove removes the object with the specified id and all its pairs.
+func (sap *SAPBroadphase) Remove(id int) {
+
+	obj, ok := sap.objects[id]
+	if !ok {
+		return
+	}
+	for axis := range sap.axes {
+		list := sap.axes[axis]
+		lo, hi := obj.index[axis][0], obj.index[axis][1]
+		// Shifts the following endpoints over the removed ones
+		n := lo
+		for i := lo; i < len(list); i++ {
+			if i == lo || i == hi {
+				continue
+			}
+			list[n] = list[i]
+			list[n].obj.index[axis][list[n].side()] = n
+			n++
+		}
+		sap.axes[axis] = list[:n]
+	}
+	for i := 0; i < len(sap.pairs) && obj.pairs > 0; {
+		if p := sap.pairs[i]; p[0] == id || p[1] == id {
+			sap.removePair(sap.objects[p[0]], sap.objects[p[1]])
+		} else {
+			i++
+		}
+	}
+	delete(sap.objects, id)
+}
+
+// Update sets the bounding box of the object with the specified id, which must have been added.
+// The overlapping pairs are updated when next needed.
+func (sap *SAPBroadphase) Update(id int, bounds *math32.Box3) {
+
+	obj := sap.objects[id]
+	obj.bounds = *bounds
+	for axis := range sap.axes {
+		list := sap.axes[axis]
+		list[obj.index[axis][0]].value = bounds.Min.Component(axis)
+		list[obj.index[axis][1]].value = bounds.Max.Component(axis)
+	}
+	sap.dirty = true
+}
+
+// OverlappingPairs returns the ids of the pairs of objects whose bounding boxes overlap,
+// with the smaller id first.
+func (sap *SAPBroadphase) OverlappingPairs() [][2]int {
+
+	sap.sort()
+	pairs := make([][2]int, len(sap.pairs))
+	copy(pairs, sap.pairs)
+	return pairs
+}
+
+// cross updates the pairs when the moving endpoint moves before the other endpoint
+// of the same axis list. A minimum moving before a maximum of another object starts
+// their overlap along the axis and a maximum moving before a minimum ends it.
+func (sap *SAPBroadphase) cross(moving, other *sapEndpoint) {
+
+	if moving.min == other.min {
+		return
+	}
+	if moving.min {
+		if moving.obj.bounds.IsIntersectionBox(&other.obj.bounds) {
+			sap.addPair(moving.obj, other.obj)
+		}
+	} else if moving.obj.pairs > 0 && other.obj.pairs > 0 {
+		sap.removePair(moving.obj, other.obj)
+	}
+}
+
+// sort sorts the axis lists by insertion sort after some extents were updated,
+// which updates the pairs from the swaps of the endpoints.
+func (sap *SAPBroadphase) sort() {
+
+	if !sap.dirty {
+		return
+	}
+	for axis := range sap.axes {
+		list := sap.axes[axis]
+		for i := 1; i < len(list); i++ {
+			if list[i].before(&list[i-1]) {
+				sap.siftDown(axis, i)
+			}
+		}
+	}
+	sap.dirty = false
+}
+
+// siftDown moves the endpoint at the specified position of the specified axis list towards the start until sorted,
+// shifting the endpoints it moves before towards the end.
+func (sap *SAPBroadphase) siftDown(axis, i int) {
+
+	list := sap.axes[axis]
+	e := list[i]
+	for i > 0 && e.before(&list[i-1]) {
+		sap.cross(&e, &list[i-1])
+		list[i] = list[i-1]
+		list[i].obj.index[axis][list[i].side()] = i
+		i--
+	}
+	list[i] = e
+	e.obj.index[axis][e.side()] = i
+}
+
+// addPair adds the pair of the specified objects if not already added.
+func (sap *SAPBroadphase) addPair(a, b *sapObject) {
+
+	key := pairKey(a, b)
+	if _, ok := sap.pairIdx[key]; ok {
+		return
+	}
+	sap.pairIdx[key] = len(sap.pairs)
+	sap.pairs = append(sap.pairs, key)
+	a.pairs++
+	b.pairs++
+}
+
+// removePair removes the pair of the specified objects if present.
+func (sap *SAPBroadphase) removePair(a, b *sapObject) {
+
+	key := pairKey(a, b)
+	i, ok := sap.pairIdx[key]
+	if !ok {
+		return
+	}
+	last := len(sap.pairs) - 1
+	sap.pairs[i] = sap.pairs[last]
+	sap.pairIdx[sap.pairs[i]] = i
+	sap.pairs = sap.pairs[:last]
+	delete(sap.pairIdx, key)
+	a.pairs--
+	b.pairs--
+}
+
+// pairKey returns the ids of the specified objects with the smaller id first.
+func pairKey(a, b *sapObject) [2]int {
+
+	if a.id > b.id {
+		return [2]int{b.id, a.id}
+	}
+	return [2]int{a.id, b.id}
+}
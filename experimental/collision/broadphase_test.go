@@ -0,0 +1,168 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package collision
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+// pairsSink keeps the results of the benchmarks alive.
+var pairsSink [][2]int
+
+// randomBox returns a random box inside a cube of the specified size,
+// with integer coordinates so that some boxes touch.
+func randomBox(rng *rand.Rand, size int) math32.Box3 {
+
+	var box math32.Box3
+	for axis := 0; axis < 3; axis++ {
+		min := float32(rng.Intn(size))
+		box.Min.SetComponent(axis, min)
+		box.Max.SetComponent(axis, min+float32(rng.Intn(4)))
+	}
+	return box
+}
+
+// bruteForcePairs returns the sorted pairs of the overlapping boxes, testing every pair.
+func bruteForcePairs(boxes map[int]math32.Box3) [][2]int {
+
+	pairs := make([][2]int, 0)
+	for a, boxA := range boxes {
+		for b, boxB := range boxes {
+			if a < b && boxA.IsIntersectionBox(&boxB) {
+				pairs = append(pairs, [2]int{a, b})
+			}
+		}
+	}
+	sortPairs(pairs)
+	return pairs
+}
+
+// sortPairs sorts the specified pairs by their first and then their second id.
+func sortPairs(pairs [][2]int) {
+
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i][0] < pairs[j][0] || (pairs[i][0] == pairs[j][0] && pairs[i][1] < pairs[j][1])
+	})
+}
+
+// checkPairs checks that the overlapping pairs of the broadphase are the ones of the brute force test.
+func checkPairs(t *testing.T, sap *SAPBroadphase, boxes map[int]math32.Box3, step int) {
+
+	t.Helper()
+	got := sap.OverlappingPairs()
+	sortPairs(got)
+	want := bruteForcePairs(boxes)
+	if len(got) != len(want) {
+		t.Fatal("step", step, ":", len(got), "pairs instead of", len(want))
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatal("step", step, ": pair", got[i], "instead of", want[i])
+		}
+	}
+}
+
+// Test the overlapping pairs of the sweep and prune broadphase against the brute force test
+// along random additions, updates and removals
+func TestSAPBroadphaseRandom(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(1))
+	sap := NewSAPBroadphase()
+	boxes := make(map[int]math32.Box3)
+	ids := make([]int, 0)
+	for step := 0; step < 3000; step++ {
+		switch op := rng.Intn(10); {
+		case op < 4 || len(ids) == 0:
+			id := rng.Intn(1000)
+			box := randomBox(rng, 20)
+			if _, ok := boxes[id]; !ok {
+				ids = append(ids, id)
+			}
+			boxes[id] = box
+			sap.Add(id, &box)
+		case op < 8:
+			id := ids[rng.Intn(len(ids))]
+			box := randomBox(rng, 20)
+			boxes[id] = box
+			sap.Update(id, &box)
+		default:
+			i := rng.Intn(len(ids))
+			id := ids[i]
+			ids[i] = ids[len(ids)-1]
+			ids = ids[:len(ids)-1]
+			delete(boxes, id)
+			sap.Remove(id)
+		}
+		// Checks after batches of operations so that some are sorted together
+		if step%3 == 2 {
+			checkPairs(t, sap, boxes, step)
+		}
+	}
+}
+
+// Test the overlapping pairs of the sweep and prune broadphase against the brute force test
+// while the boxes move slowly
+func TestSAPBroadphaseMoving(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(2))
+	sap := NewSAPBroadphase()
+	boxes := make(map[int]math32.Box3)
+	velocities := make(map[int]math32.Vector3)
+	for id := 0; id < 200; id++ {
+		box := randomBox(rng, 30)
+		boxes[id] = box
+		velocities[id] = math32.Vector3{X: rng.Float32() - 0.5, Y: rng.Float32() - 0.5, Z: rng.Float32() - 0.5}
+		sap.Add(id, &box)
+	}
+	for step := 0; step < 200; step++ {
+		for id, box := range boxes {
+			v := velocities[id]
+			box.Min.Add(&v)
+			box.Max.Add(&v)
+			boxes[id] = box
+			sap.Update(id, &box)
+		}
+		checkPairs(t, sap, boxes, step)
+	}
+}
+
+// Benchmark a frame of the sweep and prune broadphase with 1000 slowly moving objects
+func BenchmarkSAPBroadphase(b *testing.B) {
+
+	const count = 1000
+	rng := rand.New(rand.NewSource(1))
+	sap := NewSAPBroadphase()
+	boxes := make([]math32.Box3, count)
+	velocities := make([]math32.Vector3, count)
+	for id := range boxes {
+		var center math32.Vector3
+		center.Set(rng.Float32()*100, rng.Float32()*100, rng.Float32()*100)
+		half := math32.Vector3{X: 1 + rng.Float32(), Y: 1 + rng.Float32(), Z: 1 + rng.Float32()}
+		boxes[id].Min.SubVectors(&center, &half)
+		boxes[id].Max.AddVectors(&center, &half)
+		velocities[id].Set(rng.Float32()-0.5, rng.Float32()-0.5, rng.Float32()-0.5).MultiplyScalar(0.05)
+		sap.Add(id, &boxes[id])
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Reverses the motion periodically to keep the objects inside the volume
+		if i%1000 == 0 {
+			for id := range velocities {
+				velocities[id].Negate()
+			}
+		}
+		for id := range boxes {
+			boxes[id].Min.Add(&velocities[id])
+			boxes[id].Max.Add(&velocities[id])
+			sap.Update(id, &boxes[id])
+		}
+		pairsSink = sap.OverlappingPairs()
+	}
+}
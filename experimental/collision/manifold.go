@@ -0,0 +1,124 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package collision
+
+import "github.com/g3n/engine/math32"
+
+// ContactManifoldMaxPoints is the maximum number of points of a ContactManifold.
+const ContactManifoldMaxPoints = 4
+
+// ContactPoint is a point of a contact manifold.
+type ContactPoint struct {
+	Position math32.Vector3
+	Normal   math32.Vector3
+	Depth    float32
+	ID       uint32  // identifies the features in contact so the point can be matched in the following frames
+	Impulse  float32 // normal impulse accumulated by the solver, used to warm start it in the following frame
+}
+
+// ContactManifold is the set of up to four contact points between two bodies.
+// Resolving several points at once avoids the jitter of resting contacts resolved one point at a time.
+type ContactManifold struct {
+	Points [ContactManifoldMaxPoints]ContactPoint
+	Count  int // number of valid points
+}
+
+// Reduce sets the points of this manifold to the deepest of the specified points and the three
+// points which maximize the area of the contact polygon together with it.
+// Returns pointer to this updated manifold.
+func (m *ContactManifold) Reduce(points []ContactPoint) *ContactManifold {
+
+	if len(points) <= ContactManifoldMaxPoints {
+		m.Count = copy(m.Points[:], points)
+		return m
+	}
+
+	// Deepest point
+	i0 := 0
+	for i := range points {
+		if points[i].Depth > points[i0].Depth {
+			i0 = i
+		}
+	}
+	p0 := &points[i0].Position
+
+	// Farthest point from the deepest
+	i1 := -1
+	var best float32
+	for i := range points {
+		if i == i0 {
+			continue
+		}
+		if d := points[i].Position.DistanceToSquared(p0); i1 < 0 || d > best {
+			i1, best = i, d
+		}
+	}
+	p1 := &points[i1].Position
+
+	// Point farthest from the segment of the first two, which maximizes the area of their triangle
+	var e1, e2, cross math32.Vector3
+	e1.SubVectors(p1, p0)
+	i2 := -1
+	for i := range points {
+		if i == i0 || i == i1 {
+			continue
+		}
+		e2.SubVectors(&points[i].Position, p0)
+		if a := cross.CrossVectors(&e1, &e2).LengthSq(); i2 < 0 || a > best {
+			i2, best = i, a
+		}
+	}
+	p2 := &points[i2].Position
+
+	// Point adding the largest area to the triangle: the area outside of its edges
+	var normal math32.Vector3
+	e2.SubVectors(p2, p0)
+	normal.CrossVectors(&e1, &e2)
+	edges := [3][2]*math32.Vector3{{p0, p1}, {p1, p2}, {p2, p0}}
+	i3 := -1
+	for i := range points {
+		if i == i0 || i == i1 || i == i2 {
+			continue
+		}
+		q := &points[i].Position
+		var added float32
+		for _, e := range edges {
+			e1.SubVectors(e[1], e[0])
+			e2.SubVectors(q, e[0])
+			added = math32.Max(added, -cross.CrossVectors(&e1, &e2).Dot(&normal))
+		}
+		if i3 < 0 || added > best {
+			i3, best = i, added
+		}
+	}
+
+	m.Points[0] = points[i0]
+	m.Points[1] = points[i1]
+	m.Points[2] = points[i2]
+	m.Points[3] = points[i3]
+	m.Count = 4
+	return m
+}
+
+// PersistContacts copies into the points of this manifold the accumulated impulses of the points
+// of the specified manifold of the previous frame with the same ID, to warm start the solver.
+// Points without a match keep their impulse.
+// Returns pointer to this updated manifold.
+func (m *ContactManifold) PersistContacts(prevManifold *ContactManifold) *ContactManifold {
+
+	if prevManifold == nil {
+		return m
+	}
+	for i := 0; i < m.Count; i++ {
+		p := &m.Points[i]
+		for j := 0; j < prevManifold.Count; j++ {
+			if prevManifold.Points[j].ID == p.ID {
+				p.Impulse = prevManifold.Points[j].Impulse
+				break
+			}
+		}
+	}
+	return m
+}
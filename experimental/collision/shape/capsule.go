@@ -4,3 +4,202 @@
 
 package shape
 
+import (
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// Capsule is an analytical collision capsule: a cylinder capped by two hemispheres.
+// It is aligned with the local Y axis and centered at the origin.
+type Capsule struct {
+	radius float32
+	height float32 // distance between the centers of the hemispheres
+}
+
+// NewCapsule creates and returns a pointer to a new analytical collision capsule
+// with the specified radius and distance between the centers of its hemispheres.
+func NewCapsule(radius, height float32) *Capsule {
+
+	c := new(Capsule)
+	c.radius = radius
+	c.height = height
+	return c
+}
+
+// SetRadius sets the radius of the analytical collision capsule.
+func (c *Capsule) SetRadius(radius float32) {
+
+	c.radius = radius
+}
+
+// Radius returns the radius of the analytical collision capsule.
+func (c *Capsule) Radius() float32 {
+
+	return c.radius
+}
+
+// SetHeight sets the distance between the centers of the hemispheres of the analytical collision capsule.
+func (c *Capsule) SetHeight(height float32) {
+
+	c.height = height
+}
+
+// Height returns the distance between the centers of the hemispheres of the analytical collision capsule.
+func (c *Capsule) Height() float32 {
+
+	return c.height
+}
+
+// IShape =============================================================
+
+// BoundingBox computes and returns the bounding box of the analytical collision capsule.
+func (c *Capsule) BoundingBox() math32.Box3 {
+
+	hy := c.height/2 + c.radius
+	return math32.Box3{
+		Min: math32.Vector3{X: -c.radius, Y: -hy, Z: -c.radius},
+		Max: math32.Vector3{X: c.radius, Y: hy, Z: c.radius},
+	}
+}
+
+// BoundingSphere computes and returns the bounding sphere of the analytical collision capsule.
+func (c *Capsule) BoundingSphere() math32.Sphere {
+
+	return *math32.NewSphere(math32.NewVec3(), c.height/2+c.radius)
+}
+
+// Area computes and returns the surface area of the analytical collision capsule.
+func (c *Capsule) Area() float32 {
+
+	return 4*math32.Pi*c.radius*c.radius + 2*math32.Pi*c.radius*c.height
+}
+
+// Volume computes and returns the volume of the analytical collision capsule.
+func (c *Capsule) Volume() float32 {
+
+	r2 := c.radius * c.radius
+	return math32.Pi*r2*c.height + (4.0/3.0)*math32.Pi*r2*c.radius
+}
+
+// RotationalInertia computes and returns the rotational inertia of the analytical collision capsule.
+func (c *Capsule) RotationalInertia(mass float32) math32.Matrix3 {
+
+	r := c.radius
+	h := c.height
+	volume := c.Volume()
+	if volume == 0 {
+		return *math32.NewMatrix3().Zero()
+	}
+	// Mass of the cylinder and of the two hemispheres together
+	mc := mass * math32.Pi * r * r * h / volume
+	ms := mass - mc
+	iy := mc*r*r/2 + ms*2*r*r/5
+	ix := mc*(h*h/12+r*r/4) + ms*(2*r*r/5+h*h/4+3*h*r/8)
+	return *math32.NewMatrix3().Set(
+		ix, 0, 0,
+		0, iy, 0,
+		0, 0, ix,
+	)
+}
+
+// ProjectOntoAxis computes and returns the minimum and maximum distances of the analytical collision capsule projected onto the specified local axis.
+func (c *Capsule) ProjectOntoAxis(localAxis *math32.Vector3) (float32, float32) {
+
+	half := math32.Abs(localAxis.Y)*c.height/2 + c.radius
+	return -half, half
+}
+
+// SupportPoint computes the point of the analytical collision capsule furthest in the specified local direction,
+// stores it in optionalTarget, if not nil, and returns it: the support point of the hemisphere on the side
+// of the direction, or of the top hemisphere if the direction is horizontal.
+func (c *Capsule) SupportPoint(localDir *math32.Vector3, optionalTarget *math32.Vector3) *math32.Vector3 {
+
+	center := math32.Vector3{X: 0, Y: c.height / 2, Z: 0}
+	if localDir.Y < 0 {
+		center.Y = -center.Y
+	}
+	return math32.NewSphere(&center, c.radius).SupportPoint(localDir, optionalTarget)
+}
+
+// ToMesh generates and returns a triangle geometry of the analytical collision capsule with two
+// hemispherical caps of the specified number of latitude rings and longitude segments
+// joined by a cylinder. The cylinder shares the equator vertices of the caps so the surface is closed.
+// Texture coordinates follow the convention of geometry.Sphere, with V increasing from the top
+// pole to the bottom pole proportionally to the distance along the surface.
+func (c *Capsule) ToMesh(rings, segments int) *geometry.Geometry {
+
+	if rings < 1 {
+		rings = 1
+	}
+	if segments < 3 {
+		segments = 3
+	}
+	r := c.radius
+	halfHeight := c.height / 2
+	quarter := math32.Pi * r / 2
+	length := 2*quarter + c.height
+
+	// Rows of vertices from the top pole to the bottom pole: the top cap rows end at
+	// the top equator, which is followed by the bottom equator and the bottom cap rows.
+	rows := 2*rings + 2
+	count := rows * (segments + 1)
+	positions := math32.NewArrayF32(0, count*3)
+	normals := math32.NewArrayF32(0, count*3)
+	uvs := math32.NewArrayF32(0, count*2)
+	indices := math32.NewArrayU32(0, (rows-1)*segments*6)
+
+	for row := 0; row < rows; row++ {
+		// Polar angle of the row on its hemisphere, vertical offset of its center and distance along the surface
+		var theta, offset, dist float32
+		if row <= rings {
+			theta = float32(row) / float32(rings) * math32.Pi / 2
+			offset = halfHeight
+			dist = theta * r
+		} else {
+			theta = math32.Pi/2 + float32(row-rings-1)/float32(rings)*math32.Pi/2
+			offset = -halfHeight
+			dist = theta*r + c.height
+		}
+		v := float32(0)
+		if length > 0 {
+			v = dist / length
+		}
+		sinTheta := math32.Sin(theta)
+		cosTheta := math32.Cos(theta)
+		for x := 0; x <= segments; x++ {
+			u := float32(x) / float32(segments)
+			phi := u * 2 * math32.Pi
+			nx := -math32.Cos(phi) * sinTheta
+			ny := cosTheta
+			nz := math32.Sin(phi) * sinTheta
+			positions.Append(nx*r, ny*r+offset, nz*r)
+			normals.Append(nx, ny, nz)
+			uvs.Append(u, v)
+		}
+	}
+
+	stride := uint32(segments + 1)
+	for row := 0; row < rows-1; row++ {
+		for x := 0; x < segments; x++ {
+			v1 := uint32(row)*stride + uint32(x+1)
+			v2 := uint32(row)*stride + uint32(x)
+			v3 := v2 + stride
+			v4 := v1 + stride
+			// Skips the degenerate triangles at the poles
+			if row != 0 {
+				indices.Append(v1, v2, v4)
+			}
+			if row != rows-2 {
+				indices.Append(v2, v3, v4)
+			}
+		}
+	}
+
+	geom := geometry.NewGeometry()
+	geom.SetIndices(indices)
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(normals).AddAttrib(gls.VertexNormal))
+	geom.AddVBO(gls.NewVBO(uvs).AddAttrib(gls.VertexTexcoord))
+	return geom
+}
@@ -246,6 +246,31 @@ func (ch *ConvexHull) ProjectOntoWorldAxis(worldAxis, pos *math32.Vector3, quat
 	return max, min
 }
 
+// SupportPoint computes the vertex of the convex hull furthest in the specified local direction,
+// stores it in optionalTarget, if not nil, and returns it. The origin is returned if the hull has no vertices.
+func (ch *ConvexHull) SupportPoint(localDir *math32.Vector3, optionalTarget *math32.Vector3) *math32.Vector3 {
+
+	var result *math32.Vector3
+	if optionalTarget != nil {
+		result = optionalTarget
+	} else {
+		result = math32.NewVec3()
+	}
+	result.Zero()
+	first := true
+	var max float32
+	ch.Geometry.ReadVertices(func(vertex math32.Vector3) bool {
+		val := vertex.Dot(localDir)
+		if first || val > max {
+			max = val
+			result.Copy(&vertex)
+			first = false
+		}
+		return false
+	})
+	return result
+}
+
 // =====================================================================
 
 //{array} result The an array of contact point objects, see clipFaceAgainstHull
@@ -0,0 +1,466 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shape
+
+import (
+	"errors"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/math32"
+	"sort"
+)
+
+// decompositionResolution is the number of voxels along the largest dimension
+// of the mesh used by ConvexDecomposition.
+const decompositionResolution = 32
+
+// decompositionPlanes is the maximum number of clipping planes evaluated
+// along each axis when splitting a part.
+const decompositionPlanes = 16
+
+// hacdPart is a set of voxels which is approximated by a single convex hull.
+type hacdPart struct {
+	voxels     []int32 // indices of the voxels of the part
+	concavity  float32 // volume between the part and its hull relative to the mesh volume
+	splittable bool    // part can be split by a clipping plane
+}
+
+// hacd contains the voxelization of a mesh used by the decomposition.
+type hacd struct {
+	origin math32.Vector3 // minimum corner of the voxel grid
+	size   float32        // size of the voxels
+	dims   [3]int         // number of voxels along each axis
+	volume float32        // total volume of the voxels of the mesh
+	rows   []int32        // scratch buffer of row extremes used to compute hull points
+}
+
+// ConvexDecomposition splits the closed triangle mesh of the specified geometry into at most maxHulls
+// convex hulls with at most maxVerticesPerHull vertices each, using a simplified Volumetric Hierarchical
+// Approximate Convex Decomposition (V-HACD).
+// The mesh is voxelized and the part with the largest concavity, the volume between the part and its
+// convex hull relative to the volume of the mesh, is recursively split by the axis aligned clipping plane
+// which minimizes the concavity of the resulting halves, until the concavity of all the parts is not
+// greater than concavityThreshold or there are maxHulls parts.
+// The voxels include all the voxels intersecting the surface of the mesh, so that the union of the
+// returned hulls covers the volume of the mesh. Hulls with too many vertices are simplified and
+// scaled up around their centroid to keep covering their part.
+func ConvexDecomposition(geom *geometry.Geometry, maxHulls, maxVerticesPerHull int, concavityThreshold float32) ([]*ConvexHull, error) {
+
+	if maxHulls < 1 {
+		return nil, errors.New("maximum number of hulls must be at least 1")
+	}
+	if maxVerticesPerHull < 4 {
+		return nil, errors.New("maximum number of vertices per hull must be at least 4")
+	}
+	var triangles [][3]math32.Vector3
+	geom.ReadFaces(func(vA, vB, vC math32.Vector3) bool {
+		triangles = append(triangles, [3]math32.Vector3{vA, vB, vC})
+		return false
+	})
+	if len(triangles) == 0 {
+		return nil, errors.New("geometry has no triangles")
+	}
+
+	h := new(hacd)
+	voxels := h.voxelize(triangles)
+	if len(voxels) == 0 {
+		return nil, errors.New("geometry has no volume")
+	}
+
+	root := &hacdPart{voxels: voxels, splittable: true}
+	root.concavity = h.concavity(root.voxels)
+	parts := []*hacdPart{root}
+	for len(parts) < maxHulls {
+		// Part with the largest concavity which can be split
+		worst := -1
+		for i, p := range parts {
+			if p.splittable && p.concavity > concavityThreshold && (worst < 0 || p.concavity > parts[worst].concavity) {
+				worst = i
+			}
+		}
+		if worst < 0 {
+			break
+		}
+		left, right := h.split(parts[worst])
+		if left == nil {
+			parts[worst].splittable = false
+			continue
+		}
+		parts[worst] = left
+		parts = append(parts, right)
+	}
+
+	hulls := make([]*ConvexHull, 0, len(parts))
+	for _, p := range parts {
+		qh, err := h.hull(p.voxels)
+		if err != nil {
+			return nil, err
+		}
+		if len(qh.vertices()) > maxVerticesPerHull {
+			if qh, err = simplifyHull(qh, maxVerticesPerHull); err != nil {
+				return nil, err
+			}
+		}
+		hulls = append(hulls, NewConvexHull(qh.geometry()))
+	}
+	return hulls, nil
+}
+
+// voxelize builds the voxel grid of the bounding box of the specified triangles and returns the
+// indices of the voxels intersecting the triangles or with their center inside the mesh.
+func (h *hacd) voxelize(triangles [][3]math32.Vector3) []int32 {
+
+	bounds := math32.NewBox3(&triangles[0][0], &triangles[0][0])
+	for i := range triangles {
+		for k := 0; k < 3; k++ {
+			bounds.ExpandByPoint(&triangles[i][k])
+		}
+	}
+	var extent math32.Vector3
+	extent.SubVectors(&bounds.Max, &bounds.Min)
+	h.size = math32.Max(extent.X, math32.Max(extent.Y, extent.Z)) / decompositionResolution
+	if h.size == 0 {
+		return nil
+	}
+	h.origin = bounds.Min
+	for a := 0; a < 3; a++ {
+		h.dims[a] = int(math32.Ceil(extent.Component(a)/h.size - 1e-4))
+		if h.dims[a] < 1 {
+			h.dims[a] = 1
+		}
+	}
+	filled := make([]bool, h.dims[0]*h.dims[1]*h.dims[2])
+
+	// Voxels intersecting the surface, with the boxes slightly shrunk to exclude the voxels only touching it
+	hs := h.size / 2 * (1 - 1e-3)
+	half := math32.Vector3{X: hs, Y: hs, Z: hs}
+	for i := range triangles {
+		tb := math32.NewBox3(&triangles[i][0], &triangles[i][0])
+		tb.ExpandByPoint(&triangles[i][1])
+		tb.ExpandByPoint(&triangles[i][2])
+		x0, y0, z0 := h.cell(&tb.Min)
+		x1, y1, z1 := h.cell(&tb.Max)
+		for z := z0; z <= z1; z++ {
+			for y := y0; y <= y1; y++ {
+				for x := x0; x <= x1; x++ {
+					center := h.center(x, y, z)
+					if triangleBoxOverlap(&center, &half, &triangles[i]) {
+						filled[h.index(x, y, z)] = true
+					}
+				}
+			}
+		}
+	}
+
+	// Voxels with the center inside the mesh by the parity of the crossings of a ray along each row.
+	// The rows are slightly offset from the voxel centers to avoid crossing the edges of the triangles.
+	var crossings []float32
+	for z := 0; z < h.dims[2]; z++ {
+		for y := 0; y < h.dims[1]; y++ {
+			ry := h.origin.Y + (float32(y)+0.5+1.234e-3)*h.size
+			rz := h.origin.Z + (float32(z)+0.5+2.345e-3)*h.size
+			crossings = crossings[:0]
+			for i := range triangles {
+				if x, ok := rowCrossing(&triangles[i], ry, rz); ok {
+					crossings = append(crossings, x)
+				}
+			}
+			if len(crossings) == 0 {
+				continue
+			}
+			sort.Slice(crossings, func(i, j int) bool { return crossings[i] < crossings[j] })
+			k := 0
+			for x := 0; x < h.dims[0]; x++ {
+				cx := h.origin.X + (float32(x)+0.5)*h.size
+				for k < len(crossings) && crossings[k] < cx {
+					k++
+				}
+				if k%2 == 1 {
+					filled[h.index(x, y, z)] = true
+				}
+			}
+		}
+	}
+
+	var voxels []int32
+	for i, f := range filled {
+		if f {
+			voxels = append(voxels, int32(i))
+		}
+	}
+	h.volume = float32(len(voxels)) * h.size * h.size * h.size
+	return voxels
+}
+
+// cell returns the coordinates of the voxel containing the specified point, clamped to the grid.
+func (h *hacd) cell(p *math32.Vector3) (int, int, int) {
+
+	x := math32.ClampInt(int((p.X-h.origin.X)/h.size), 0, h.dims[0]-1)
+	y := math32.ClampInt(int((p.Y-h.origin.Y)/h.size), 0, h.dims[1]-1)
+	z := math32.ClampInt(int((p.Z-h.origin.Z)/h.size), 0, h.dims[2]-1)
+	return x, y, z
+}
+
+// center returns the center of the specified voxel.
+func (h *hacd) center(x, y, z int) math32.Vector3 {
+
+	return math32.Vector3{
+		X: h.origin.X + (float32(x)+0.5)*h.size,
+		Y: h.origin.Y + (float32(y)+0.5)*h.size,
+		Z: h.origin.Z + (float32(z)+0.5)*h.size,
+	}
+}
+
+// index returns the index of the specified voxel.
+func (h *hacd) index(x, y, z int) int {
+
+	return x + h.dims[0]*(y+h.dims[1]*z)
+}
+
+// coords returns the coordinates of the voxel with the specified index.
+func (h *hacd) coords(i int32) [3]int {
+
+	idx := int(i)
+	return [3]int{idx % h.dims[0], (idx / h.dims[0]) % h.dims[1], idx / (h.dims[0] * h.dims[1])}
+}
+
+// hull returns the convex hull of the specified voxels.
+// As the voxels of each row along X are inside the box between the extreme voxels of the row,
+// only the outer corners of the extreme voxels of each row are used.
+func (h *hacd) hull(voxels []int32) (*quickhull, error) {
+
+	nrows := h.dims[1] * h.dims[2]
+	if len(h.rows) != 2*nrows {
+		h.rows = make([]int32, 2*nrows)
+	}
+	for i := range h.rows {
+		h.rows[i] = -1
+	}
+	for _, v := range voxels {
+		c := h.coords(v)
+		row := c[1] + h.dims[1]*c[2]
+		x := int32(c[0])
+		if h.rows[2*row] < 0 || x < h.rows[2*row] {
+			h.rows[2*row] = x
+		}
+		if x > h.rows[2*row+1] {
+			h.rows[2*row+1] = x
+		}
+	}
+	qh := new(quickhull)
+	for row := 0; row < nrows; row++ {
+		if h.rows[2*row] < 0 {
+			continue
+		}
+		y := row % h.dims[1]
+		z := row / h.dims[1]
+		x0 := h.origin.X + float32(h.rows[2*row])*h.size
+		x1 := h.origin.X + float32(h.rows[2*row+1]+1)*h.size
+		y0 := h.origin.Y + float32(y)*h.size
+		z0 := h.origin.Z + float32(z)*h.size
+		for _, x := range [2]float32{x0, x1} {
+			qh.points = append(qh.points,
+				math32.Vector3{X: x, Y: y0, Z: z0}, math32.Vector3{X: x, Y: y0 + h.size, Z: z0},
+				math32.Vector3{X: x, Y: y0, Z: z0 + h.size}, math32.Vector3{X: x, Y: y0 + h.size, Z: z0 + h.size})
+		}
+	}
+	if err := qh.build(); err != nil {
+		return nil, err
+	}
+	return qh, nil
+}
+
+// concavity returns the volume between the specified voxels and their convex hull relative to the mesh volume.
+func (h *hacd) concavity(voxels []int32) float32 {
+
+	qh, err := h.hull(voxels)
+	if err != nil {
+		return 0
+	}
+	partVolume := float32(len(voxels)) * h.size * h.size * h.size
+	return math32.Max(qh.volume()-partVolume, 0) / h.volume
+}
+
+// split returns the two parts resulting from clipping the specified part by the axis aligned
+// plane between voxel layers which minimizes the sum of their concavities,
+// or nil if the part has a single voxel layer along all axes.
+func (h *hacd) split(p *hacdPart) (*hacdPart, *hacdPart) {
+
+	var lo, hi [3]int
+	for a := 0; a < 3; a++ {
+		lo[a], hi[a] = h.dims[a], -1
+	}
+	for _, v := range p.voxels {
+		c := h.coords(v)
+		for a := 0; a < 3; a++ {
+			if c[a] < lo[a] {
+				lo[a] = c[a]
+			}
+			if c[a] > hi[a] {
+				hi[a] = c[a]
+			}
+		}
+	}
+
+	var best [2]*hacdPart
+	bestCost := float32(math32.Infinity)
+	var left, right []int32
+	for a := 0; a < 3; a++ {
+		step := (hi[a] - lo[a] + decompositionPlanes - 1) / decompositionPlanes
+		if step < 1 {
+			step = 1
+		}
+		// Voxels with coordinate lower than the plane go to the left part
+		for plane := lo[a] + step; plane <= hi[a]; plane += step {
+			left, right = left[:0], right[:0]
+			for _, v := range p.voxels {
+				if h.coords(v)[a] < plane {
+					left = append(left, v)
+				} else {
+					right = append(right, v)
+				}
+			}
+			cl := h.concavity(left)
+			cr := h.concavity(right)
+			// Balanced splits are preferred between planes of similar cost
+			balance := math32.Abs(float32(len(left)-len(right))) / float32(len(p.voxels))
+			cost := cl + cr + 1e-3*balance
+			if cost < bestCost {
+				bestCost = cost
+				best[0] = &hacdPart{voxels: append([]int32(nil), left...), concavity: cl, splittable: true}
+				best[1] = &hacdPart{voxels: append([]int32(nil), right...), concavity: cr, splittable: true}
+			}
+		}
+	}
+	return best[0], best[1]
+}
+
+// simplifyHull returns a hull with at most maxVertices vertices which contains the specified hull.
+// The vertices are chosen by farthest point sampling and the resulting hull is scaled up around
+// its centroid until it contains all the vertices of the original hull.
+func simplifyHull(qh *quickhull, maxVertices int) (*quickhull, error) {
+
+	verts := qh.vertices()
+	var centroid math32.Vector3
+	for _, v := range verts {
+		centroid.Add(&qh.points[v])
+	}
+	centroid.MultiplyScalar(1 / float32(len(verts)))
+
+	// Farthest point sampling starting with the vertex farthest from the centroid
+	minDist := make([]float32, len(verts))
+	for i := range minDist {
+		minDist[i] = math32.Infinity
+	}
+	next := 0
+	for i, v := range verts {
+		if qh.points[v].DistanceToSquared(&centroid) > qh.points[verts[next]].DistanceToSquared(&centroid) {
+			next = i
+		}
+	}
+	reduced := new(quickhull)
+	for len(reduced.points) < maxVertices {
+		p := qh.points[verts[next]]
+		reduced.points = append(reduced.points, p)
+		next = 0
+		for i, v := range verts {
+			minDist[i] = math32.Min(minDist[i], qh.points[v].DistanceToSquared(&p))
+			if minDist[i] > minDist[next] {
+				next = i
+			}
+		}
+	}
+	if err := reduced.build(); err != nil {
+		return nil, err
+	}
+
+	// Scale factor around the centroid of the reduced hull which makes it contain all the original vertices
+	faces := reduced.hullFaces()
+	centroid.Zero()
+	for i := range reduced.points {
+		centroid.Add(&reduced.points[i])
+	}
+	centroid.MultiplyScalar(1 / float32(len(reduced.points)))
+	scale := float32(1)
+	for _, f := range faces {
+		height := -f.distance(&centroid)
+		if height <= 0 {
+			continue
+		}
+		for _, v := range verts {
+			var d math32.Vector3
+			d.SubVectors(&qh.points[v], &centroid)
+			scale = math32.Max(scale, f.normal.Dot(&d)/height)
+		}
+	}
+	for i := range reduced.points {
+		p := &reduced.points[i]
+		p.Sub(&centroid).MultiplyScalar(scale).Add(&centroid)
+	}
+	return reduced, nil
+}
+
+// rowCrossing returns the X coordinate where the line parallel to the X axis at the
+// specified Y and Z coordinates crosses the specified triangle, if it crosses it.
+func rowCrossing(t *[3]math32.Vector3, y, z float32) (float32, bool) {
+
+	// Barycentric coordinates of the line in the projection of the triangle on the YZ plane
+	a, b, c := &t[0], &t[1], &t[2]
+	det := (b.Y-a.Y)*(c.Z-a.Z) - (c.Y-a.Y)*(b.Z-a.Z)
+	if det == 0 {
+		return 0, false
+	}
+	u := ((y-a.Y)*(c.Z-a.Z) - (c.Y-a.Y)*(z-a.Z)) / det
+	v := ((b.Y-a.Y)*(z-a.Z) - (y-a.Y)*(b.Z-a.Z)) / det
+	if u < 0 || v < 0 || u+v > 1 {
+		return 0, false
+	}
+	return a.X + u*(b.X-a.X) + v*(c.X-a.X), true
+}
+
+// triangleBoxOverlap returns if the specified triangle intersects the axis aligned box with the
+// specified center and half size, using the separating axis test of Akenine-Möller.
+func triangleBoxOverlap(center, half *math32.Vector3, t *[3]math32.Vector3) bool {
+
+	var v [3]math32.Vector3
+	for i := range v {
+		v[i].SubVectors(&t[i], center)
+	}
+	var edges [3]math32.Vector3
+	edges[0].SubVectors(&v[1], &v[0])
+	edges[1].SubVectors(&v[2], &v[1])
+	edges[2].SubVectors(&v[0], &v[2])
+
+	// Projects the triangle and the box on the specified axis
+	separated := func(axis *math32.Vector3) bool {
+		p0, p1, p2 := axis.Dot(&v[0]), axis.Dot(&v[1]), axis.Dot(&v[2])
+		r := half.X*math32.Abs(axis.X) + half.Y*math32.Abs(axis.Y) + half.Z*math32.Abs(axis.Z)
+		return math32.Min(p0, math32.Min(p1, p2)) > r || math32.Max(p0, math32.Max(p1, p2)) < -r
+	}
+
+	// Box axes
+	units := [3]math32.Vector3{{X: 1}, {Y: 1}, {Z: 1}}
+	for i := range units {
+		if separated(&units[i]) {
+			return false
+		}
+	}
+	// Triangle normal
+	var n math32.Vector3
+	n.CrossVectors(&edges[0], &edges[1])
+	if separated(&n) {
+		return false
+	}
+	// Cross products of the box axes and the triangle edges
+	for i := range units {
+		for j := range edges {
+			var axis math32.Vector3
+			axis.CrossVectors(&units[i], &edges[j])
+			if separated(&axis) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,135 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shape
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// boxesGeometry returns the non indexed geometry of the union of the specified boxes, by minimum and maximum corners.
+func boxesGeometry(boxes [][2]math32.Vector3) *geometry.Geometry {
+
+	positions := math32.NewArrayF32(0, 0)
+	for _, b := range boxes {
+		box := geometry.NewBox(b[1].X-b[0].X, b[1].Y-b[0].Y, b[1].Z-b[0].Z)
+		var center math32.Vector3
+		center.AddVectors(&b[0], &b[1]).MultiplyScalar(0.5)
+		box.ReadFaces(func(vA, vB, vC math32.Vector3) bool {
+			vA.Add(&center)
+			vB.Add(&center)
+			vC.Add(&center)
+			positions.AppendVector3(&vA, &vB, &vC)
+			return false
+		})
+	}
+	geom := geometry.NewGeometry()
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	return geom
+}
+
+// hullContains returns if the specified point is inside the hull or on its surface, within the tolerance.
+func hullContains(hull *ConvexHull, p *math32.Vector3, tolerance float32) bool {
+
+	normals := hull.FaceNormals()
+	for i, face := range hull.Faces() {
+		var d math32.Vector3
+		d.SubVectors(p, &face[0])
+		if d.Dot(&normals[i]) > tolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// checkCovered tests that each vertex of the geometry and each random point of the specified boxes is inside a hull.
+func checkCovered(t *testing.T, geom *geometry.Geometry, boxes [][2]math32.Vector3, hulls []*ConvexHull) {
+
+	t.Helper()
+	covered := func(p *math32.Vector3) bool {
+		for _, h := range hulls {
+			if hullContains(h, p, 1e-4) {
+				return true
+			}
+		}
+		return false
+	}
+	geom.ReadVertices(func(vertex math32.Vector3) bool {
+		if !covered(&vertex) {
+			t.Error("vertex", vertex, "not inside a hull")
+		}
+		return false
+	})
+	rng := rand.New(rand.NewSource(1))
+	for _, b := range boxes {
+		for i := 0; i < 200; i++ {
+			p := math32.Vector3{
+				X: b[0].X + rng.Float32()*(b[1].X-b[0].X),
+				Y: b[0].Y + rng.Float32()*(b[1].Y-b[0].Y),
+				Z: b[0].Z + rng.Float32()*(b[1].Z-b[0].Z),
+			}
+			if !covered(&p) {
+				t.Fatal("point", p, "of the mesh not inside a hull")
+			}
+		}
+	}
+}
+
+// Test the decompositions of a cube, an L shape and a ring
+func TestConvexDecomposition(t *testing.T) {
+
+	cases := []struct {
+		name  string
+		boxes [][2]math32.Vector3
+		hulls int // expected number of hulls, or 0 for any number
+	}{
+		{"cube", [][2]math32.Vector3{{{}, {X: 1, Y: 1, Z: 1}}}, 1},
+		{"L", [][2]math32.Vector3{{{}, {X: 2, Y: 1, Z: 1}}, {{Y: 1}, {X: 1, Y: 2, Z: 1}}}, 2},
+		{"ring", [][2]math32.Vector3{
+			{{}, {X: 3, Y: 1, Z: 1}}, {{Y: 2}, {X: 3, Y: 3, Z: 1}},
+			{{Y: 1}, {X: 1, Y: 2, Z: 1}}, {{X: 2, Y: 1}, {X: 3, Y: 2, Z: 1}},
+		}, 0},
+	}
+	for _, c := range cases {
+		geom := boxesGeometry(c.boxes)
+		hulls, err := ConvexDecomposition(geom, 8, 16, 0.02)
+		if err != nil {
+			t.Fatal(c.name, err)
+		}
+		if c.hulls != 0 && len(hulls) != c.hulls {
+			t.Error(c.name, "decomposed into", len(hulls), "hulls instead of", c.hulls)
+		}
+		if len(hulls) > 8 {
+			t.Error(c.name, "decomposed into", len(hulls), "hulls, more than the maximum")
+		}
+		for _, h := range hulls {
+			vertices := make(map[math32.Vector3]bool)
+			h.ReadVertices(func(vertex math32.Vector3) bool {
+				vertices[vertex] = true
+				return false
+			})
+			if len(vertices) > 16 {
+				t.Error(c.name, "hull with", len(vertices), "vertices, more than the maximum")
+			}
+		}
+		checkCovered(t, geom, c.boxes, hulls)
+	}
+
+	// Invalid arguments
+	cube := boxesGeometry([][2]math32.Vector3{{{}, {X: 1, Y: 1, Z: 1}}})
+	if _, err := ConvexDecomposition(cube, 0, 16, 0.02); err == nil {
+		t.Error("no error for no hulls")
+	}
+	if _, err := ConvexDecomposition(cube, 8, 3, 0.02); err == nil {
+		t.Error("no error for less than 4 vertices per hull")
+	}
+	if _, err := ConvexDecomposition(geometry.NewGeometry(), 8, 16, 0.02); err == nil {
+		t.Error("no error for an empty geometry")
+	}
+}
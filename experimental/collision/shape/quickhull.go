@@ -0,0 +1,358 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shape
+
+import (
+	"errors"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// qhFace is a triangular face of a convex hull being built by quickhull.
+// The vertices are counter-clockwise seen from outside the hull.
+type qhFace struct {
+	v       [3]int         // indices of the vertices
+	normal  math32.Vector3 // unit outward normal
+	offset  float32        // plane constant: dot(normal, x) = offset for points x on the face
+	outside []int          // indices of the points above the face which are not assigned to other faces
+	alive   bool           // face was not removed
+	visible int            // 0 unknown, 1 visible and 2 not visible from the current eye point
+}
+
+// distance returns the signed distance of the specified point to the plane of the face.
+func (f *qhFace) distance(p *math32.Vector3) float32 {
+
+	return f.normal.Dot(p) - f.offset
+}
+
+// quickhull builds the convex hull of a set of points with the quickhull algorithm.
+type quickhull struct {
+	points []math32.Vector3
+	faces  []*qhFace
+	edges  map[[2]int]*qhFace // face containing each directed edge
+	eps    float32            // distance tolerance of the points to the face planes
+}
+
+// newFace creates and returns a face with the specified vertices registering its edges.
+func (qh *quickhull) newFace(a, b, c int) *qhFace {
+
+	f := &qhFace{v: [3]int{a, b, c}, alive: true}
+	var ab, ac math32.Vector3
+	ab.SubVectors(&qh.points[b], &qh.points[a])
+	ac.SubVectors(&qh.points[c], &qh.points[a])
+	f.normal.CrossVectors(&ab, &ac)
+	if f.normal.LengthSq() > 0 {
+		f.normal.Normalize()
+	}
+	f.offset = f.normal.Dot(&qh.points[a])
+	qh.faces = append(qh.faces, f)
+	qh.edges[[2]int{a, b}] = f
+	qh.edges[[2]int{b, c}] = f
+	qh.edges[[2]int{c, a}] = f
+	return f
+}
+
+// removeFace removes the specified face unregistering its edges.
+func (qh *quickhull) removeFace(f *qhFace) {
+
+	f.alive = false
+	for i := 0; i < 3; i++ {
+		e := [2]int{f.v[i], f.v[(i+1)%3]}
+		if qh.edges[e] == f {
+			delete(qh.edges, e)
+		}
+	}
+}
+
+// assign adds the specified point to the outside set of the first specified face it is above.
+func (qh *quickhull) assign(p int, faces []*qhFace) {
+
+	for _, f := range faces {
+		if f.distance(&qh.points[p]) > qh.eps {
+			f.outside = append(f.outside, p)
+			return
+		}
+	}
+}
+
+// initialSimplex creates the faces of a tetrahedron of extreme points.
+func (qh *quickhull) initialSimplex() error {
+
+	pts := qh.points
+	// Extreme points along the coordinate axes
+	var extremes [6]int
+	for i := range pts {
+		if pts[i].X < pts[extremes[0]].X {
+			extremes[0] = i
+		}
+		if pts[i].X > pts[extremes[1]].X {
+			extremes[1] = i
+		}
+		if pts[i].Y < pts[extremes[2]].Y {
+			extremes[2] = i
+		}
+		if pts[i].Y > pts[extremes[3]].Y {
+			extremes[3] = i
+		}
+		if pts[i].Z < pts[extremes[4]].Z {
+			extremes[4] = i
+		}
+		if pts[i].Z > pts[extremes[5]].Z {
+			extremes[5] = i
+		}
+	}
+	var scale float32
+	for a := 0; a < 3; a++ {
+		scale = math32.Max(scale, pts[extremes[2*a+1]].Component(a)-pts[extremes[2*a]].Component(a))
+	}
+	qh.eps = 1e-5 * scale
+
+	// Most distant pair of extreme points
+	i0, i1 := extremes[0], extremes[1]
+	best := float32(-1)
+	for i := 0; i < 6; i++ {
+		for j := i + 1; j < 6; j++ {
+			if d := pts[extremes[i]].DistanceToSquared(&pts[extremes[j]]); d > best {
+				best = d
+				i0, i1 = extremes[i], extremes[j]
+			}
+		}
+	}
+	if math32.Sqrt(best) <= qh.eps {
+		return errors.New("convex hull points are coincident")
+	}
+
+	// Point farthest from the line
+	var dir math32.Vector3
+	dir.SubVectors(&pts[i1], &pts[i0]).Normalize()
+	i2 := -1
+	best = qh.eps
+	for i := range pts {
+		var d, c math32.Vector3
+		d.SubVectors(&pts[i], &pts[i0])
+		c.CrossVectors(&d, &dir)
+		if l := c.Length(); l > best {
+			best = l
+			i2 = i
+		}
+	}
+	if i2 < 0 {
+		return errors.New("convex hull points are collinear")
+	}
+
+	// Point farthest from the plane
+	var e1, e2, n math32.Vector3
+	e1.SubVectors(&pts[i1], &pts[i0])
+	e2.SubVectors(&pts[i2], &pts[i0])
+	n.CrossVectors(&e1, &e2).Normalize()
+	i3 := -1
+	best = qh.eps
+	for i := range pts {
+		var d math32.Vector3
+		d.SubVectors(&pts[i], &pts[i0])
+		if l := math32.Abs(d.Dot(&n)); l > best {
+			best = l
+			i3 = i
+		}
+	}
+	if i3 < 0 {
+		return errors.New("convex hull points are coplanar")
+	}
+
+	// Faces oriented with the centroid of the tetrahedron below them
+	var centroid math32.Vector3
+	centroid.Add(&pts[i0]).Add(&pts[i1]).Add(&pts[i2]).Add(&pts[i3]).MultiplyScalar(0.25)
+	tetra := [4][3]int{{i0, i1, i2}, {i0, i1, i3}, {i0, i2, i3}, {i1, i2, i3}}
+	for _, t := range tetra {
+		var ab, ac, fn, d math32.Vector3
+		ab.SubVectors(&pts[t[1]], &pts[t[0]])
+		ac.SubVectors(&pts[t[2]], &pts[t[0]])
+		fn.CrossVectors(&ab, &ac)
+		d.SubVectors(&centroid, &pts[t[0]])
+		if fn.Dot(&d) > 0 {
+			t[1], t[2] = t[2], t[1]
+		}
+		qh.newFace(t[0], t[1], t[2])
+	}
+	faces := qh.faces
+	for i := range pts {
+		if i != i0 && i != i1 && i != i2 && i != i3 {
+			qh.assign(i, faces)
+		}
+	}
+	return nil
+}
+
+// build computes the convex hull of the points.
+func (qh *quickhull) build() error {
+
+	if len(qh.points) < 4 {
+		return errors.New("convex hull requires at least 4 points")
+	}
+	qh.edges = make(map[[2]int]*qhFace)
+	if err := qh.initialSimplex(); err != nil {
+		return err
+	}
+
+	pending := append([]*qhFace(nil), qh.faces...)
+	var visible []*qhFace
+	var horizon [][2]int
+	for len(pending) > 0 {
+		f := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if !f.alive || len(f.outside) == 0 {
+			continue
+		}
+		// Farthest point above the face
+		eye := f.outside[0]
+		best := f.distance(&qh.points[eye])
+		for _, p := range f.outside[1:] {
+			if d := f.distance(&qh.points[p]); d > best {
+				best = d
+				eye = p
+			}
+		}
+		pe := &qh.points[eye]
+
+		// Faces visible from the eye point, which form a connected region around f
+		visible = visible[:0]
+		horizon = horizon[:0]
+		f.visible = 1
+		visible = append(visible, f)
+		for i := 0; i < len(visible); i++ {
+			vf := visible[i]
+			for k := 0; k < 3; k++ {
+				a, b := vf.v[k], vf.v[(k+1)%3]
+				nf := qh.edges[[2]int{b, a}]
+				if nf == nil {
+					continue
+				}
+				if nf.visible == 0 {
+					if nf.distance(pe) > qh.eps {
+						nf.visible = 1
+						visible = append(visible, nf)
+					} else {
+						nf.visible = 2
+					}
+				}
+			}
+		}
+		// Horizon edges are the edges of visible faces shared with faces which are not visible
+		for _, vf := range visible {
+			for k := 0; k < 3; k++ {
+				a, b := vf.v[k], vf.v[(k+1)%3]
+				if nf := qh.edges[[2]int{b, a}]; nf == nil || nf.visible != 1 {
+					horizon = append(horizon, [2]int{a, b})
+				}
+			}
+		}
+		var orphans []int
+		for _, vf := range visible {
+			for k := 0; k < 3; k++ {
+				if nf := qh.edges[[2]int{vf.v[(k+1)%3], vf.v[k]}]; nf != nil {
+					nf.visible = 0
+				}
+			}
+			orphans = append(orphans, vf.outside...)
+			vf.outside = nil
+			qh.removeFace(vf)
+		}
+
+		// New faces joining the horizon to the eye point
+		start := len(qh.faces)
+		for _, e := range horizon {
+			qh.newFace(e[0], e[1], eye)
+		}
+		newFaces := qh.faces[start:]
+		for _, p := range orphans {
+			if p != eye {
+				qh.assign(p, newFaces)
+			}
+		}
+		pending = append(pending, newFaces...)
+	}
+	return nil
+}
+
+// hullFaces returns the faces of the hull.
+func (qh *quickhull) hullFaces() []*qhFace {
+
+	faces := make([]*qhFace, 0, len(qh.faces))
+	for _, f := range qh.faces {
+		if f.alive {
+			faces = append(faces, f)
+		}
+	}
+	return faces
+}
+
+// volume returns the volume of the hull.
+func (qh *quickhull) volume() float32 {
+
+	var vol float32
+	var origin math32.Vector3
+	for _, f := range qh.faces {
+		if !f.alive {
+			continue
+		}
+		origin = qh.points[f.v[0]]
+		break
+	}
+	for _, f := range qh.faces {
+		if !f.alive {
+			continue
+		}
+		var a, b, c, bc math32.Vector3
+		a.SubVectors(&qh.points[f.v[0]], &origin)
+		b.SubVectors(&qh.points[f.v[1]], &origin)
+		c.SubVectors(&qh.points[f.v[2]], &origin)
+		bc.CrossVectors(&b, &c)
+		vol += a.Dot(&bc)
+	}
+	return vol / 6
+}
+
+// vertices returns the indices of the points which are vertices of the hull.
+func (qh *quickhull) vertices() []int {
+
+	used := make(map[int]bool)
+	var verts []int
+	for _, f := range qh.faces {
+		if !f.alive {
+			continue
+		}
+		for _, v := range f.v {
+			if !used[v] {
+				used[v] = true
+				verts = append(verts, v)
+			}
+		}
+	}
+	return verts
+}
+
+// geometry returns a new indexed triangle geometry with the vertices and faces of the hull.
+func (qh *quickhull) geometry() *geometry.Geometry {
+
+	remap := make(map[int]uint32)
+	positions := math32.NewArrayF32(0, 0)
+	indices := math32.NewArrayU32(0, 0)
+	for _, f := range qh.hullFaces() {
+		for _, v := range f.v {
+			idx, ok := remap[v]
+			if !ok {
+				idx = uint32(len(remap))
+				remap[v] = idx
+				positions.AppendVector3(&qh.points[v])
+			}
+			indices.Append(idx)
+		}
+	}
+	geom := geometry.NewGeometry()
+	geom.SetIndices(indices)
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	return geom
+}
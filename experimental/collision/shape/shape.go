@@ -17,6 +17,13 @@ type IShape interface {
 	ProjectOntoAxis(localAxis *math32.Vector3) (float32, float32)
 }
 
+// SupportMapper is the interface for the convex collision shapes which can be used by the GJK algorithm.
+// SupportPoint computes the point of the shape furthest in the specified local direction,
+// stores it in optionalTarget, if not nil, and returns it.
+type SupportMapper interface {
+	SupportPoint(localDir *math32.Vector3, optionalTarget *math32.Vector3) *math32.Vector3
+}
+
 // Shape is a collision shape.
 // It can be an analytical geometry such as a sphere, plane, etc.. or it can be defined by a polygonal Geometry.
 type Shape struct {
@@ -73,3 +73,12 @@ func (s *Sphere) ProjectOntoAxis(localAxis *math32.Vector3) (float32, float32) {
 
 	return -s.radius, s.radius
 }
+
+// SupportMapper ======================================================
+
+// SupportPoint computes the point of the analytical collision sphere furthest in the specified local direction,
+// stores it in optionalTarget, if not nil, and returns it. If the direction is zero the center is returned.
+func (s *Sphere) SupportPoint(localDir *math32.Vector3, optionalTarget *math32.Vector3) *math32.Vector3 {
+
+	return math32.NewSphere(math32.NewVec3(), s.radius).SupportPoint(localDir, optionalTarget)
+}
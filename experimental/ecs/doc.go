@@ -0,0 +1,7 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ecs implements an entity-component system whose components are stored in typed sparse sets.
+// WARNING: This package is experimental and incomplete!
+package ecs
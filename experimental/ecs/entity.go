@@ -0,0 +1,59 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ecs
+
+// EntityRegistry issues the IDs of the entities, recycling the IDs of the destroyed entities.
+type EntityRegistry struct {
+	nextID  EntityID
+	freeIDs []EntityID // IDs of the destroyed entities, the last one reused first
+	alive   map[EntityID]bool
+}
+
+// NewEntityRegistry creates and returns a pointer to a new EntityRegistry without entities.
+func NewEntityRegistry() *EntityRegistry {
+
+	er := new(EntityRegistry)
+	er.alive = make(map[EntityID]bool)
+	return er
+}
+
+// CreateEntity creates a new entity and returns its ID.
+func (er *EntityRegistry) CreateEntity() EntityID {
+
+	var e EntityID
+	if n := len(er.freeIDs); n > 0 {
+		e = er.freeIDs[n-1]
+		er.freeIDs = er.freeIDs[:n-1]
+	} else {
+		e = er.nextID
+		er.nextID++
+	}
+	er.alive[e] = true
+	return e
+}
+
+// DestroyEntity frees the ID of the specified entity.
+// Returns false if the entity was not alive.
+func (er *EntityRegistry) DestroyEntity(e EntityID) bool {
+
+	if !er.alive[e] {
+		return false
+	}
+	delete(er.alive, e)
+	er.freeIDs = append(er.freeIDs, e)
+	return true
+}
+
+// Alive returns if the specified entity was created and not destroyed.
+func (er *EntityRegistry) Alive(e EntityID) bool {
+
+	return er.alive[e]
+}
+
+// EntityCount returns the number of alive entities.
+func (er *EntityRegistry) EntityCount() int {
+
+	return len(er.alive)
+}
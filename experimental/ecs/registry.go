@@ -0,0 +1,267 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ecs
+
+import (
+	"encoding/gob"
+	"fmt"
+	"iter"
+	"reflect"
+)
+
+// EntityID identifies an entity, which is only the set of components associated with its ID.
+type EntityID uint32
+
+// ComponentID identifies a component type registered in a ComponentRegistry.
+type ComponentID int
+
+// absent is the value of the sparse array of a pool for the entities without the component.
+const absent = -1
+
+// pool is the interface of the typed component pools, used to access the pools of all the component types.
+type pool interface {
+	remove(e EntityID)
+	has(e EntityID) bool
+	len() int
+	encode(enc *gob.Encoder) error
+	decode(dec *gob.Decoder) error
+	clear()
+}
+
+// componentPool is a sparse set of the components of type T: the components are stored contiguously
+// in dense, with their entities at the same index in entities, and sparse maps each entity to its index.
+type componentPool[T any] struct {
+	sparse   []int32 // index in dense of the component of each entity, or absent
+	dense    []T
+	entities []EntityID
+}
+
+// index returns the index in dense of the component of the specified entity, or absent.
+func (p *componentPool[T]) index(e EntityID) int32 {
+
+	if int(e) >= len(p.sparse) {
+		return absent
+	}
+	return p.sparse[e]
+}
+
+// set sets the component of the specified entity, adding it if needed.
+func (p *componentPool[T]) set(e EntityID, value T) {
+
+	if i := p.index(e); i != absent {
+		p.dense[i] = value
+		return
+	}
+	for int(e) >= len(p.sparse) {
+		p.sparse = append(p.sparse, absent)
+	}
+	p.sparse[e] = int32(len(p.dense))
+	p.dense = append(p.dense, value)
+	p.entities = append(p.entities, e)
+}
+
+// remove removes the component of the specified entity, moving the last component to its place.
+func (p *componentPool[T]) remove(e EntityID) {
+
+	i := p.index(e)
+	if i == absent {
+		return
+	}
+	last := int32(len(p.dense) - 1)
+	if i != last {
+		p.dense[i] = p.dense[last]
+		p.entities[i] = p.entities[last]
+		p.sparse[p.entities[i]] = i
+	}
+	var zero T
+	p.dense[last] = zero
+	p.dense = p.dense[:last]
+	p.entities = p.entities[:last]
+	p.sparse[e] = absent
+}
+
+// has returns if the specified entity has a component in this pool.
+func (p *componentPool[T]) has(e EntityID) bool {
+
+	return p.index(e) != absent
+}
+
+// len returns the number of components in this pool.
+func (p *componentPool[T]) len() int {
+
+	return len(p.dense)
+}
+
+// encode writes the entities and components of this pool to the specified encoder.
+func (p *componentPool[T]) encode(enc *gob.Encoder) error {
+
+	err := enc.Encode(p.entities)
+	if err != nil {
+		return err
+	}
+	return enc.Encode(p.dense)
+}
+
+// decode replaces the entities and components of this pool with the ones read from the specified decoder.
+func (p *componentPool[T]) decode(dec *gob.Decoder) error {
+
+	var entities []EntityID
+	var dense []T
+	err := dec.Decode(&entities)
+	if err != nil {
+		return err
+	}
+	err = dec.Decode(&dense)
+	if err != nil {
+		return err
+	}
+	if len(dense) != len(entities) {
+		return fmt.Errorf("%d components for %d entities", len(dense), len(entities))
+	}
+	p.sparse = p.sparse[:0]
+	p.dense = dense
+	p.entities = entities
+	for i, e := range entities {
+		for int(e) >= len(p.sparse) {
+			p.sparse = append(p.sparse, absent)
+		}
+		p.sparse[e] = int32(i)
+	}
+	return nil
+}
+
+// clear removes all the components of this pool.
+func (p *componentPool[T]) clear() {
+
+	p.sparse = p.sparse[:0]
+	p.dense = nil
+	p.entities = nil
+}
+
+// ComponentRegistry stores the components of the entities in one pool per component type.
+// The pools are sparse sets, with constant time lookup and contiguous storage for the iteration.
+// The pointers to components returned by GetComponent and QueryComponents are only valid
+// until the next addition or removal of a component of the same type.
+type ComponentRegistry struct {
+	ids   map[reflect.Type]ComponentID
+	types []reflect.Type // component types by ID
+	pools []pool         // pools by component ID
+}
+
+// NewComponentRegistry creates and returns a pointer to a new empty ComponentRegistry.
+func NewComponentRegistry() *ComponentRegistry {
+
+	reg := new(ComponentRegistry)
+	reg.ids = make(map[reflect.Type]ComponentID)
+	return reg
+}
+
+// RemoveAll removes all the components of the specified entity.
+func (reg *ComponentRegistry) RemoveAll(e EntityID) {
+
+	for _, p := range reg.pools {
+		p.remove(e)
+	}
+}
+
+// HasComponent returns if the specified entity has a component with the specified ID.
+func (reg *ComponentRegistry) HasComponent(e EntityID, id ComponentID) bool {
+
+	if id < 0 || int(id) >= len(reg.pools) {
+		return false
+	}
+	return reg.pools[id].has(e)
+}
+
+// Count returns the number of components with the specified ID.
+func (reg *ComponentRegistry) Count(id ComponentID) int {
+
+	if id < 0 || int(id) >= len(reg.pools) {
+		return 0
+	}
+	return reg.pools[id].len()
+}
+
+// RegisterComponent creates the pool of the components of type T in the specified registry,
+// if not registered yet, and returns the ID of the component type.
+func RegisterComponent[T any](reg *ComponentRegistry) ComponentID {
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if id, ok := reg.ids[t]; ok {
+		return id
+	}
+	id := ComponentID(len(reg.pools))
+	reg.ids[t] = id
+	reg.types = append(reg.types, t)
+	reg.pools = append(reg.pools, new(componentPool[T]))
+	return id
+}
+
+// ComponentIDOf returns the ID of the component type T in the specified registry and if it is registered.
+func ComponentIDOf[T any](reg *ComponentRegistry) (ComponentID, bool) {
+
+	id, ok := reg.ids[reflect.TypeOf((*T)(nil)).Elem()]
+	return id, ok
+}
+
+// poolOf returns the pool of the components of type T in the specified registry,
+// registering the type if needed.
+func poolOf[T any](reg *ComponentRegistry) *componentPool[T] {
+
+	return reg.pools[RegisterComponent[T](reg)].(*componentPool[T])
+}
+
+// AddComponent sets the component of type T of the specified entity, replacing its previous value.
+// The component type is registered if needed.
+func AddComponent[T any](reg *ComponentRegistry, entity EntityID, value T) {
+
+	poolOf[T](reg).set(entity, value)
+}
+
+// GetComponent returns a pointer to the component of type T of the specified entity and true,
+// or nil and false if the entity has no such component.
+func GetComponent[T any](reg *ComponentRegistry, entity EntityID) (*T, bool) {
+
+	id, ok := ComponentIDOf[T](reg)
+	if !ok {
+		return nil, false
+	}
+	p := reg.pools[id].(*componentPool[T])
+	i := p.index(entity)
+	if i == absent {
+		return nil, false
+	}
+	return &p.dense[i], true
+}
+
+// RemoveComponent removes the component of type T of the specified entity, if any.
+func RemoveComponent[T any](reg *ComponentRegistry, entity EntityID) {
+
+	if id, ok := ComponentIDOf[T](reg); ok {
+		reg.pools[id].remove(entity)
+	}
+}
+
+// QueryComponents returns an iterator over the entities with a component of type T and pointers to their
+// components, from the last stored to the first. The current component can be removed during the iteration.
+func QueryComponents[T any](reg *ComponentRegistry) iter.Seq2[EntityID, *T] {
+
+	return func(yield func(EntityID, *T) bool) {
+		id, ok := ComponentIDOf[T](reg)
+		if !ok {
+			return
+		}
+		p := reg.pools[id].(*componentPool[T])
+		// Backwards, so that removing the current component moves an already visited one to its place
+		for i := len(p.dense) - 1; i >= 0; i-- {
+			if i >= len(p.dense) {
+				continue
+			}
+			if !yield(p.entities[i], &p.dense[i]) {
+				return
+			}
+		}
+	}
+}
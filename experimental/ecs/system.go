@@ -0,0 +1,168 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ecs
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// SystemID identifies a system registered in a SystemScheduler.
+type SystemID int
+
+// System is the interface for the systems which update the components of a World every frame.
+type System interface {
+	// Run updates the world by the specified time step in seconds.
+	Run(world *World, dt float32)
+	// Dependencies returns the IDs of the systems which must run before this system.
+	Dependencies() []SystemID
+}
+
+// SystemScheduler runs the registered systems every frame in an order which satisfies their dependencies.
+// The systems are run in stages, each stage with the systems whose dependencies ran in the previous stages.
+// The systems of a stage run in parallel in a pool of workers, so they must not modify the components
+// read or written by the other systems of the stage.
+type SystemScheduler struct {
+	systems []System
+	preds   [][]SystemID // systems which must run before each system
+	stages  [][]SystemID // systems of each stage
+	jobs    chan System
+	world   *World
+	dt      float32
+	wg      sync.WaitGroup
+}
+
+// NewSystemScheduler creates and returns a pointer to a new SystemScheduler without systems
+// which runs the systems of a stage in the specified number of worker goroutines.
+// With one worker or less, all the systems run in the goroutine of ExecuteFrame.
+func NewSystemScheduler(workers int) *SystemScheduler {
+
+	s := new(SystemScheduler)
+	if workers > 1 {
+		s.jobs = make(chan System)
+		for i := 0; i < workers; i++ {
+			go s.worker(s.jobs)
+		}
+	}
+	return s
+}
+
+// worker runs the systems received from the specified channel until it is closed.
+func (s *SystemScheduler) worker(jobs chan System) {
+
+	for sys := range jobs {
+		sys.Run(s.world, s.dt)
+		s.wg.Done()
+	}
+}
+
+// RegisterSystem adds the specified system, to be run after its dependencies, before the systems
+// in before and after the systems in after, and returns its ID.
+// Returns an error, without registering the system, if an ID is unknown or the constraints form a cycle.
+func (s *SystemScheduler) RegisterSystem(sys System, before, after []SystemID) (SystemID, error) {
+
+	id := SystemID(len(s.systems))
+	deps := append(append([]SystemID(nil), sys.Dependencies()...), after...)
+	for _, d := range append(deps, before...) {
+		if d < 0 || d >= id {
+			return 0, fmt.Errorf("unknown system ID %d", d)
+		}
+	}
+
+	// Adds the system and its constraints, removing them if a cycle is found
+	s.systems = append(s.systems, sys)
+	s.preds = append(s.preds, deps)
+	for _, b := range before {
+		s.preds[b] = append(s.preds[b], id)
+	}
+	stages, err := s.resolve()
+	if err != nil {
+		for _, b := range before {
+			s.preds[b] = s.preds[b][:len(s.preds[b])-1]
+		}
+		s.systems = s.systems[:id]
+		s.preds = s.preds[:id]
+		return 0, err
+	}
+	s.stages = stages
+	return id, nil
+}
+
+// resolve sorts the systems topologically into stages and returns an error if they have circular dependencies.
+func (s *SystemScheduler) resolve() ([][]SystemID, error) {
+
+	n := len(s.systems)
+	pending := make([]int, n)      // number of predecessors not placed yet
+	succs := make([][]SystemID, n) // systems which must run after each system
+	for i, preds := range s.preds {
+		for _, p := range preds {
+			pending[i]++
+			succs[p] = append(succs[p], SystemID(i))
+		}
+	}
+	var stage []SystemID
+	for i := 0; i < n; i++ {
+		if pending[i] == 0 {
+			stage = append(stage, SystemID(i))
+		}
+	}
+	var stages [][]SystemID
+	placed := 0
+	for len(stage) > 0 {
+		stages = append(stages, stage)
+		placed += len(stage)
+		var next []SystemID
+		for _, id := range stage {
+			for _, succ := range succs[id] {
+				pending[succ]--
+				if pending[succ] == 0 {
+					next = append(next, succ)
+				}
+			}
+		}
+		stage = next
+	}
+	if placed < n {
+		return nil, errors.New("circular system dependencies")
+	}
+	return stages, nil
+}
+
+// Stages returns the IDs of the systems of each stage, in execution order.
+func (s *SystemScheduler) Stages() [][]SystemID {
+
+	return s.stages
+}
+
+// ExecuteFrame runs all the systems with the specified world and time step in seconds,
+// waiting for each stage to finish before starting the next.
+func (s *SystemScheduler) ExecuteFrame(world *World, dt float32) {
+
+	s.world = world
+	s.dt = dt
+	for _, stage := range s.stages {
+		if s.jobs == nil || len(stage) == 1 {
+			for _, id := range stage {
+				s.systems[id].Run(world, dt)
+			}
+			continue
+		}
+		s.wg.Add(len(stage))
+		for _, id := range stage {
+			s.jobs <- s.systems[id]
+		}
+		s.wg.Wait()
+	}
+}
+
+// Dispose stops the workers of this scheduler, which must not be used after.
+func (s *SystemScheduler) Dispose() {
+
+	if s.jobs != nil {
+		close(s.jobs)
+		s.jobs = nil
+	}
+}
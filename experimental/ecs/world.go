@@ -0,0 +1,122 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ecs
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+)
+
+// World is the top level object of the entity-component system: it issues the entities,
+// stores their components and runs the systems which update them.
+// The generic component functions take its embedded ComponentRegistry.
+type World struct {
+	*EntityRegistry
+	*ComponentRegistry
+	*SystemScheduler
+}
+
+// worldState is the header of a serialized World, followed by the components of each type.
+type worldState struct {
+	NextID     EntityID
+	FreeIDs    []EntityID
+	Alive      []EntityID
+	Components []string // names of the serialized component types, in order
+}
+
+// NewWorld creates and returns a pointer to a new World without entities, components or systems,
+// whose scheduler has one worker per CPU.
+func NewWorld() *World {
+
+	w := new(World)
+	w.EntityRegistry = NewEntityRegistry()
+	w.ComponentRegistry = NewComponentRegistry()
+	w.SystemScheduler = NewSystemScheduler(runtime.NumCPU())
+	return w
+}
+
+// DestroyEntity removes all the components of the specified entity and frees its ID.
+func (w *World) DestroyEntity(e EntityID) {
+
+	if w.EntityRegistry.DestroyEntity(e) {
+		w.RemoveAll(e)
+	}
+}
+
+// Step runs all the systems for one frame with the specified time step in seconds.
+func (w *World) Step(dt float32) {
+
+	w.ExecuteFrame(w, dt)
+}
+
+// Serialize writes the entities and components of this world to the specified writer with gob encoding.
+// The component types must be encodable by the encoding/gob package.
+func (w *World) Serialize(wr io.Writer) error {
+
+	state := worldState{NextID: w.nextID, FreeIDs: w.freeIDs}
+	for e := range w.alive {
+		state.Alive = append(state.Alive, e)
+	}
+	sort.Slice(state.Alive, func(i, j int) bool { return state.Alive[i] < state.Alive[j] })
+	for _, t := range w.types {
+		state.Components = append(state.Components, t.String())
+	}
+	enc := gob.NewEncoder(wr)
+	err := enc.Encode(&state)
+	if err != nil {
+		return err
+	}
+	for i, p := range w.pools {
+		err = p.encode(enc)
+		if err != nil {
+			return fmt.Errorf("component %s: %v", state.Components[i], err)
+		}
+	}
+	return nil
+}
+
+// Deserialize replaces the entities and components of this world with the ones written by Serialize.
+// All the serialized component types must be registered in this world. The systems are not changed.
+func (w *World) Deserialize(r io.Reader) error {
+
+	var state worldState
+	dec := gob.NewDecoder(r)
+	err := dec.Decode(&state)
+	if err != nil {
+		return err
+	}
+	pools := make([]pool, len(state.Components))
+	for i, name := range state.Components {
+		for id, t := range w.types {
+			if t.String() == name {
+				pools[i] = w.pools[id]
+				break
+			}
+		}
+		if pools[i] == nil {
+			return fmt.Errorf("component %s not registered", name)
+		}
+	}
+
+	for _, p := range w.pools {
+		p.clear()
+	}
+	for i, p := range pools {
+		err = p.decode(dec)
+		if err != nil {
+			return fmt.Errorf("component %s: %v", state.Components[i], err)
+		}
+	}
+	w.nextID = state.NextID
+	w.freeIDs = state.FreeIDs
+	w.alive = make(map[EntityID]bool, len(state.Alive))
+	for _, e := range state.Alive {
+		w.alive[e] = true
+	}
+	return nil
+}
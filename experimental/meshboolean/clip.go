@@ -0,0 +1,65 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package meshboolean
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// ClipPolygonByPlane returns a new polygon with the part of the specified convex polygon on the side
+// of the line through the specified point that the specified normal points to, using the Sutherland-Hodgman
+// algorithm. The order of the vertices is kept. Returns nil if less than three vertices remain.
+func ClipPolygonByPlane(polygon []math32.Vector2, normal, point *math32.Vector2) []math32.Vector2 {
+
+	if len(polygon) < 3 {
+		return nil
+	}
+	offset := normal.Dot(point)
+	result := make([]math32.Vector2, 0, len(polygon)+1)
+	prev := &polygon[len(polygon)-1]
+	prevDist := normal.Dot(prev) - offset
+	for i := range polygon {
+		cur := &polygon[i]
+		dist := normal.Dot(cur) - offset
+		// The vertices on the line are kept without adding intersections,
+		// which would duplicate them
+		if (prevDist < 0 && dist > 0) || (prevDist > 0 && dist < 0) {
+			var p math32.Vector2
+			p.Copy(prev).Lerp(cur, prevDist/(prevDist-dist))
+			result = append(result, p)
+		}
+		if dist >= 0 {
+			result = append(result, *cur)
+		}
+		prev, prevDist = cur, dist
+	}
+	if len(result) < 3 {
+		return nil
+	}
+	return result
+}
+
+// ClipPolygonByRect returns a new polygon with the part of the specified convex polygon
+// inside the specified rectangle, clipped successively by its four sides.
+// Returns nil if less than three vertices remain.
+func ClipPolygonByRect(polygon []math32.Vector2, rect *math32.Rect) []math32.Vector2 {
+
+	sides := [4]struct {
+		normal math32.Vector2
+		point  *math32.Vector2
+	}{
+		{math32.Vector2{X: 1, Y: 0}, &rect.Min},
+		{math32.Vector2{X: -1, Y: 0}, &rect.Max},
+		{math32.Vector2{X: 0, Y: 1}, &rect.Min},
+		{math32.Vector2{X: 0, Y: -1}, &rect.Max},
+	}
+	for i := range sides {
+		polygon = ClipPolygonByPlane(polygon, &sides[i].normal, sides[i].point)
+		if polygon == nil {
+			return nil
+		}
+	}
+	return polygon
+}
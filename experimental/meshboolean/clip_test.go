@@ -0,0 +1,76 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package meshboolean
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+// polygonArea returns the signed area of the specified polygon, positive if counterclockwise.
+func polygonArea(polygon []math32.Vector2) float32 {
+
+	var area float32
+	for i := range polygon {
+		area += cross(&polygon[i], &polygon[(i+1)%len(polygon)])
+	}
+	return area / 2
+}
+
+// polygonBounds returns the bounding rectangle of the specified polygon.
+func polygonBounds(polygon []math32.Vector2) math32.Rect {
+
+	var r math32.Rect
+	r.MakeEmpty()
+	for i := range polygon {
+		r.ExpandByPoint(&polygon[i])
+	}
+	return r
+}
+
+// Test that clipping the unit square by the half plane x <= 0.5 gives the rectangle of 0.5 by 1
+func TestClipPolygonByPlane(t *testing.T) {
+
+	square := []math32.Vector2{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}}
+	clipped := ClipPolygonByPlane(square, &math32.Vector2{X: -1}, &math32.Vector2{X: 0.5})
+	bounds := polygonBounds(clipped)
+	if len(clipped) != 4 || polygonArea(clipped) != 0.5 || bounds != (math32.Rect{Max: math32.Vector2{X: 0.5, Y: 1}}) {
+		t.Error("clipped square", clipped, "instead of the rectangle from (0,0) to (0.5,1)")
+	}
+
+	// The polygon only touching the line is removed
+	if clipped := ClipPolygonByPlane(square, &math32.Vector2{X: 1}, &math32.Vector2{X: 1}); clipped != nil {
+		t.Error("square touching the line clipped to", clipped)
+	}
+	// The polygon entirely on the kept side is unchanged
+	if clipped := ClipPolygonByPlane(square, &math32.Vector2{Y: 1}, &math32.Vector2{Y: -1}); len(clipped) != 4 || polygonArea(clipped) != 1 {
+		t.Error("square on the kept side clipped to", clipped)
+	}
+}
+
+// Test the clipping of a square and a triangle by rectangles
+func TestClipPolygonByRect(t *testing.T) {
+
+	square := []math32.Vector2{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}}
+	rect := math32.Rect{Min: math32.Vector2{X: 0.25, Y: -1}, Max: math32.Vector2{X: 2, Y: 0.5}}
+	clipped := ClipPolygonByRect(square, &rect)
+	if bounds := polygonBounds(clipped); polygonArea(clipped) != 0.375 ||
+		bounds != (math32.Rect{Min: math32.Vector2{X: 0.25}, Max: math32.Vector2{X: 1, Y: 0.5}}) {
+		t.Error("square clipped to", clipped)
+	}
+
+	// The corner of the rectangle inside the triangle becomes a vertex
+	triangle := []math32.Vector2{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 0, Y: 4}}
+	rect = math32.Rect{Min: math32.Vector2{X: 1, Y: 1}, Max: math32.Vector2{X: 2, Y: 2}}
+	clipped = ClipPolygonByRect(triangle, &rect)
+	if math32.Abs(polygonArea(clipped)-1) > 1e-6 {
+		t.Error("triangle clipped to", clipped, "with the area", polygonArea(clipped), "instead of 1")
+	}
+	rect = math32.Rect{Min: math32.Vector2{X: 3, Y: 3}, Max: math32.Vector2{X: 4, Y: 4}}
+	if clipped := ClipPolygonByRect(triangle, &rect); clipped != nil {
+		t.Error("triangle outside of the rectangle clipped to", clipped)
+	}
+}
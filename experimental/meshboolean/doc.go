@@ -0,0 +1,9 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package meshboolean implements boolean operations of 2D polygons, such as the clipping of convex polygons
+// by half-planes and rectangles with the Sutherland-Hodgman algorithm and the union of simple polygons
+// with the Weiler-Atherton algorithm, for UI masking, 2D shadows and map intersections.
+// WARNING: This package is experimental and incomplete!
+package meshboolean
@@ -0,0 +1,193 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package meshboolean
+
+import (
+	"sort"
+
+	"github.com/g3n/engine/math32"
+)
+
+// unionNode is a vertex of a polygon, or an intersection with the other polygon,
+// in the lists traversed by the Weiler-Atherton algorithm.
+type unionNode struct {
+	pos   math32.Vector2
+	other int // index of the same intersection in the list of the other polygon, or -1 for a vertex
+}
+
+// unionCrossing is an intersection of an edge of each polygon.
+type unionCrossing struct {
+	pos    math32.Vector2
+	ea, eb int     // edges of the polygons a and b
+	ta, tb float32 // parameters of the intersection along the edges
+	ia, ib int     // indices of the nodes in the lists of the polygons
+}
+
+// PolygonUnion returns a new polygon with the outer boundary of the union of the specified simple polygons,
+// which may be non-convex, using the Weiler-Atherton algorithm, in counterclockwise order. The holes of the union
+// are not returned. If the boundaries do not cross, returns a copy of the polygon containing the other,
+// or nil if the polygons are disjoint. The polygons are assumed to be in general position: their vertices are
+// not on the edges of the other polygon.
+func PolygonUnion(a, b []math32.Vector2) []math32.Vector2 {
+
+	if len(a) < 3 || len(b) < 3 {
+		return nil
+	}
+	a = counterclockwise(a)
+	b = counterclockwise(b)
+
+	// Intersections of the edges
+	var crossings []unionCrossing
+	for i := range a {
+		a0, a1 := &a[i], &a[(i+1)%len(a)]
+		for j := range b {
+			b0, b1 := &b[j], &b[(j+1)%len(b)]
+			if ta, tb, ok := segmentIntersection(a0, a1, b0, b1); ok {
+				c := unionCrossing{ea: i, eb: j, ta: ta, tb: tb}
+				c.pos.Copy(a0).Lerp(a1, ta)
+				crossings = append(crossings, c)
+			}
+		}
+	}
+	if len(crossings) == 0 {
+		switch {
+		case containsPoint(b, &a[0]):
+			return append([]math32.Vector2(nil), b...)
+		case containsPoint(a, &b[0]):
+			return append([]math32.Vector2(nil), a...)
+		default:
+			return nil
+		}
+	}
+
+	listA := unionList(a, crossings, func(c *unionCrossing) (int, float32) { return c.ea, c.ta },
+		func(c *unionCrossing, idx int) { c.ia = idx })
+	listB := unionList(b, crossings, func(c *unionCrossing) (int, float32) { return c.eb, c.tb },
+		func(c *unionCrossing, idx int) { c.ib = idx })
+	for i := range crossings {
+		listA[crossings[i].ia].other = crossings[i].ib
+		listB[crossings[i].ib].other = crossings[i].ia
+	}
+
+	// The vertex with the smallest X is on the outer boundary of the union
+	lists := [2][]unionNode{listA, listB}
+	cur, idx := 0, 0
+	for l := range lists {
+		for i := range lists[l] {
+			n := &lists[l][i]
+			s := &lists[cur][idx]
+			if n.other < 0 && (n.pos.X < s.pos.X || (n.pos.X == s.pos.X && n.pos.Y < s.pos.Y)) {
+				cur, idx = l, i
+			}
+		}
+	}
+
+	// Follows the boundary crossing to the other polygon at each intersection,
+	// where the current polygon enters the other one
+	startList, startIdx := cur, idx
+	result := make([]math32.Vector2, 0, len(listA)+len(listB))
+	for steps := 0; steps <= len(listA)+len(listB); steps++ {
+		n := &lists[cur][idx]
+		result = append(result, n.pos)
+		if n.other >= 0 {
+			cur, idx = 1-cur, n.other
+		}
+		idx = (idx + 1) % len(lists[cur])
+		if cur == startList && idx == startIdx {
+			return result
+		}
+	}
+	// Not closed because of degenerate intersections
+	return nil
+}
+
+// unionList returns the vertices of the specified polygon with the intersections inserted in order along
+// its edges, getting the edge and parameter of each intersection and setting the index of its node.
+func unionList(polygon []math32.Vector2, crossings []unionCrossing,
+	edge func(c *unionCrossing) (int, float32), setIndex func(c *unionCrossing, idx int)) []unionNode {
+
+	order := make([]int, len(crossings))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		ei, ti := edge(&crossings[order[i]])
+		ej, tj := edge(&crossings[order[j]])
+		return ei < ej || (ei == ej && ti < tj)
+	})
+	list := make([]unionNode, 0, len(polygon)+len(crossings))
+	next := 0
+	for i := range polygon {
+		list = append(list, unionNode{pos: polygon[i], other: -1})
+		for ; next < len(order); next++ {
+			c := &crossings[order[next]]
+			if e, _ := edge(c); e != i {
+				break
+			}
+			setIndex(c, len(list))
+			list = append(list, unionNode{pos: c.pos, other: -1})
+		}
+	}
+	return list
+}
+
+// segmentIntersection returns the parameters along the segments p0-p1 and q0-q1 of their intersection,
+// in the range [0,1), and if they intersect. Parallel segments do not intersect.
+func segmentIntersection(p0, p1, q0, q1 *math32.Vector2) (float32, float32, bool) {
+
+	var r, s, qp math32.Vector2
+	r.SubVectors(p1, p0)
+	s.SubVectors(q1, q0)
+	qp.SubVectors(q0, p0)
+	denom := cross(&r, &s)
+	if denom == 0 {
+		return 0, 0, false
+	}
+	t := cross(&qp, &s) / denom
+	u := cross(&qp, &r) / denom
+	if t < 0 || t >= 1 || u < 0 || u >= 1 {
+		return 0, 0, false
+	}
+	return t, u, true
+}
+
+// cross returns the Z component of the cross product of the specified vectors.
+func cross(a, b *math32.Vector2) float32 {
+
+	return a.X*b.Y - a.Y*b.X
+}
+
+// counterclockwise returns the specified polygon, or a reversed copy if its vertices are in clockwise order.
+func counterclockwise(polygon []math32.Vector2) []math32.Vector2 {
+
+	var area float32
+	for i := range polygon {
+		area += cross(&polygon[i], &polygon[(i+1)%len(polygon)])
+	}
+	if area >= 0 {
+		return polygon
+	}
+	reversed := make([]math32.Vector2, len(polygon))
+	for i := range polygon {
+		reversed[len(polygon)-1-i] = polygon[i]
+	}
+	return reversed
+}
+
+// containsPoint returns if the specified point is inside the specified polygon,
+// by the parity of the crossings of a horizontal ray.
+func containsPoint(polygon []math32.Vector2, p *math32.Vector2) bool {
+
+	inside := false
+	j := len(polygon) - 1
+	for i := range polygon {
+		pi, pj := &polygon[i], &polygon[j]
+		if (pi.Y > p.Y) != (pj.Y > p.Y) && p.X < pj.X+(p.Y-pj.Y)*(pi.X-pj.X)/(pi.Y-pj.Y) {
+			inside = !inside
+		}
+		j = i
+	}
+	return inside
+}
@@ -0,0 +1,38 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package meshboolean
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+// Test the union of overlapping, non-convex, contained and disjoint polygons
+func TestPolygonUnion(t *testing.T) {
+
+	a := []math32.Vector2{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 2}, {X: 0, Y: 2}}
+	b := []math32.Vector2{{X: 1, Y: 1}, {X: 1, Y: 3}, {X: 3, Y: 3}, {X: 3, Y: 1}} // clockwise
+	for _, u := range [][]math32.Vector2{PolygonUnion(a, b), PolygonUnion(b, a)} {
+		if polygonArea(u) != 7 || len(u) != 8 {
+			t.Error("union of the squares", u, "with the area", polygonArea(u), "instead of 7")
+		}
+	}
+
+	// A bar crossing both arms of a U: the hole of the union is not returned
+	c := []math32.Vector2{{X: 0, Y: 0}, {X: 3, Y: 0}, {X: 3, Y: 3}, {X: 2, Y: 3}, {X: 2, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 3}, {X: 0, Y: 3}}
+	d := []math32.Vector2{{X: -1, Y: 2}, {X: 4, Y: 2}, {X: 4, Y: 2.5}, {X: -1, Y: 2.5}}
+	if u := PolygonUnion(c, d); math32.Abs(polygonArea(u)-9.5) > 1e-4 {
+		t.Error("union of the U and the bar", u, "with the area", polygonArea(u), "instead of 9.5")
+	}
+
+	inside := []math32.Vector2{{X: 0.5, Y: 0.5}, {X: 1, Y: 0.5}, {X: 1, Y: 1}}
+	if u := PolygonUnion(inside, a); polygonArea(u) != 4 {
+		t.Error("union with a contained triangle", u, "instead of the square")
+	}
+	if u := PolygonUnion(a, []math32.Vector2{{X: 5, Y: 5}, {X: 6, Y: 5}, {X: 6, Y: 6}}); u != nil {
+		t.Error("union of disjoint polygons", u)
+	}
+}
@@ -0,0 +1,8 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package navigation implements pathfinding for agents moving over the surface of triangle meshes
+// or along graphs of waypoints.
+// WARNING: This package is experimental and incomplete!
+package navigation
@@ -0,0 +1,165 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package navigation
+
+import (
+	"errors"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/math32"
+)
+
+// unreached is the integration value of the cells not walkable or not connected to the goal.
+const unreached = ^uint32(0)
+
+// FlowField contains the best direction to a common goal from each walkable voxel of a voxel grid,
+// for the navigation of many agents to the same destination. The walkable voxels are the voxels
+// with a positive value. The agents move between voxels sharing a face, an edge or a corner,
+// without cutting the corners of the voxels not walkable.
+type FlowField struct {
+	grid        *geometry.VoxelGrid
+	min         [3]int32 // coordinates of the first voxel of the field
+	size        [3]int32 // number of voxels of the field along each axis
+	integration []uint32 // number of steps to the goal from each voxel
+	directions  []math32.Vector3
+}
+
+// NewFlowField creates and returns a pointer to a new FlowField to the specified goal
+// over the walkable voxels of the specified grid.
+// Returns an error if the goal is not in a walkable voxel.
+func NewFlowField(grid *geometry.VoxelGrid, goalPos *math32.Vector3) (*FlowField, error) {
+
+	ff := new(FlowField)
+	ff.grid = grid
+	err := ff.Rebuild(goalPos)
+	if err != nil {
+		return nil, err
+	}
+	return ff, nil
+}
+
+// Rebuild recomputes the field for the specified goal and the current voxels of the grid.
+// Returns an error, leaving the field unchanged, if the goal is not in a walkable voxel.
+func (ff *FlowField) Rebuild(newGoal *math32.Vector3) error {
+
+	gx, gy, gz := ff.grid.Cell(newGoal)
+	if ff.grid.Get(gx, gy, gz) <= 0 {
+		return errors.New("goal not in a walkable voxel")
+	}
+	min, max, _ := ff.grid.Bounds()
+	size := [3]int32{max[0] - min[0] + 1, max[1] - min[1] + 1, max[2] - min[2] + 1}
+	n := int(size[0]) * int(size[1]) * int(size[2])
+
+	index := func(x, y, z int32) int {
+		return int(x) + int(size[0])*(int(y)+int(size[1])*int(z))
+	}
+
+	// Walkable voxels, read once as the grid lookups are slower
+	walkable := make([]bool, n)
+	ff.grid.ReadVoxels(func(x, y, z int32, value float32) bool {
+		if value > 0 {
+			walkable[index(x-min[0], y-min[1], z-min[2])] = true
+		}
+		return false
+	})
+
+	inside := func(x, y, z int32) bool {
+		return x >= 0 && y >= 0 && z >= 0 && x < size[0] && y < size[1] && z < size[2]
+	}
+	isWalkable := func(x, y, z int32) bool {
+		return inside(x, y, z) && walkable[index(x, y, z)]
+	}
+
+	// Integration field: breadth first search from the goal over the voxels sharing a face,
+	// linear in the number of walkable voxels
+	integration := make([]uint32, n)
+	for i := range integration {
+		integration[i] = unreached
+	}
+	goal := [3]int32{gx - min[0], gy - min[1], gz - min[2]}
+	queue := [][3]int32{goal}
+	integration[index(goal[0], goal[1], goal[2])] = 0
+	faces := [6][3]int32{{1, 0, 0}, {-1, 0, 0}, {0, 1, 0}, {0, -1, 0}, {0, 0, 1}, {0, 0, -1}}
+	for head := 0; head < len(queue); head++ {
+		c := queue[head]
+		steps := integration[index(c[0], c[1], c[2])] + 1
+		for _, d := range faces {
+			x, y, z := c[0]+d[0], c[1]+d[1], c[2]+d[2]
+			if !isWalkable(x, y, z) {
+				continue
+			}
+			if ni := index(x, y, z); integration[ni] == unreached {
+				integration[ni] = steps
+				queue = append(queue, [3]int32{x, y, z})
+			}
+		}
+	}
+
+	// Flow field: direction to the neighbor nearest to the goal, among the 26 neighbors
+	// reachable without crossing a voxel not walkable
+	directions := make([]math32.Vector3, n)
+	for _, c := range queue {
+		best := integration[index(c[0], c[1], c[2])]
+		var dir math32.Vector3
+		for dz := int32(-1); dz <= 1; dz++ {
+			for dy := int32(-1); dy <= 1; dy++ {
+				for dx := int32(-1); dx <= 1; dx++ {
+					x, y, z := c[0]+dx, c[1]+dy, c[2]+dz
+					if !isWalkable(x, y, z) || integration[index(x, y, z)] >= best {
+						continue
+					}
+					if (dx != 0 && !isWalkable(c[0]+dx, c[1], c[2])) ||
+						(dy != 0 && !isWalkable(c[0], c[1]+dy, c[2])) ||
+						(dz != 0 && !isWalkable(c[0], c[1], c[2]+dz)) {
+						continue
+					}
+					best = integration[index(x, y, z)]
+					dir = math32.Vector3{X: float32(dx), Y: float32(dy), Z: float32(dz)}
+				}
+			}
+		}
+		directions[index(c[0], c[1], c[2])] = *dir.Normalize()
+	}
+
+	ff.min = min
+	ff.size = size
+	ff.integration = integration
+	ff.directions = directions
+	return nil
+}
+
+// DirectionAt returns the unit direction to the goal of an agent at the specified position.
+// Returns the zero vector in the voxel of the goal and in the voxels not walkable or not
+// connected to the goal.
+func (ff *FlowField) DirectionAt(pos *math32.Vector3) math32.Vector3 {
+
+	i, ok := ff.index(pos)
+	if !ok {
+		return math32.Vector3{}
+	}
+	return ff.directions[i]
+}
+
+// Steps returns the number of steps between voxels sharing a face from the voxel at the specified
+// position to the goal, and false if the voxel is not walkable or not connected to the goal.
+func (ff *FlowField) Steps(pos *math32.Vector3) (int, bool) {
+
+	i, ok := ff.index(pos)
+	if !ok || ff.integration[i] == unreached {
+		return 0, false
+	}
+	return int(ff.integration[i]), true
+}
+
+// index returns the index in the field of the voxel at the specified position and if it is inside the field.
+func (ff *FlowField) index(pos *math32.Vector3) (int, bool) {
+
+	x, y, z := ff.grid.Cell(pos)
+	x, y, z = x-ff.min[0], y-ff.min[1], z-ff.min[2]
+	if x < 0 || y < 0 || z < 0 || x >= ff.size[0] || y >= ff.size[1] || z >= ff.size[2] {
+		return 0, false
+	}
+	return int(x) + int(ff.size[0])*(int(y)+int(ff.size[1])*int(z)), true
+}
@@ -0,0 +1,414 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package navigation
+
+import (
+	"container/heap"
+	"fmt"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/math32"
+)
+
+// navTriangle is a walkable triangle of a NavMesh.
+type navTriangle struct {
+	v       [3]int // indices of the vertices
+	portals []int  // indices of the portals to the neighbor triangles
+}
+
+// navPortal is an edge shared by two triangles of a NavMesh.
+type navPortal struct {
+	t [2]int // indices of the triangles
+	v [2]int // indices of the vertices
+}
+
+// navSegment is a portal narrowed for an agent radius.
+type navSegment struct {
+	a, b    math32.Vector3 // end points
+	blocked bool           // portal is narrower than the agent
+}
+
+// NavMesh is a navigation mesh formed by the walkable triangles of a surface facing up (+Y).
+// Paths are found with A* over the graph of adjacent triangles and smoothed with the funnel
+// algorithm in the XZ plane, so that they pass through the corners of the surface instead of
+// the triangle centers.
+type NavMesh struct {
+	vertices  []math32.Vector3
+	triangles []navTriangle
+	portals   []navPortal
+	boundary  []bool                   // vertex is on the boundary of the surface
+	eroded    map[float32][]navSegment // portals narrowed for each agent radius
+}
+
+// NewNavMesh creates and returns a pointer to a new NavMesh from the triangles of the specified geometry.
+// Vertices with the same position are merged. Returns an error if a triangle is degenerate or
+// does not face up, or if an edge is shared by more than two triangles.
+func NewNavMesh(geom *geometry.Geometry) (*NavMesh, error) {
+
+	nm := new(NavMesh)
+	nm.eroded = make(map[float32][]navSegment)
+	index := make(map[math32.Vector3]int)
+	vertex := func(p math32.Vector3) int {
+		if i, ok := index[p]; ok {
+			return i
+		}
+		index[p] = len(nm.vertices)
+		nm.vertices = append(nm.vertices, p)
+		return len(nm.vertices) - 1
+	}
+
+	edges := make(map[[2]int][]int)
+	var err error
+	geom.ReadFaces(func(vA, vB, vC math32.Vector3) bool {
+		ti := len(nm.triangles)
+		var ab, ac, n math32.Vector3
+		ab.SubVectors(&vB, &vA)
+		ac.SubVectors(&vC, &vA)
+		n.CrossVectors(&ab, &ac)
+		if n.LengthSq() == 0 {
+			err = fmt.Errorf("Navigation mesh triangle %d is degenerate", ti)
+			return true
+		}
+		if n.Y <= 0 {
+			err = fmt.Errorf("Navigation mesh triangle %d does not face up", ti)
+			return true
+		}
+		t := navTriangle{v: [3]int{vertex(vA), vertex(vB), vertex(vC)}}
+		nm.triangles = append(nm.triangles, t)
+		for k := 0; k < 3; k++ {
+			a, b := t.v[k], t.v[(k+1)%3]
+			if a > b {
+				a, b = b, a
+			}
+			edges[[2]int{a, b}] = append(edges[[2]int{a, b}], ti)
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(nm.triangles) == 0 {
+		return nil, fmt.Errorf("Navigation mesh has no triangles")
+	}
+
+	// Adjacency graph
+	nm.boundary = make([]bool, len(nm.vertices))
+	for e, tris := range edges {
+		switch len(tris) {
+		case 1:
+			nm.boundary[e[0]] = true
+			nm.boundary[e[1]] = true
+		case 2:
+			pi := len(nm.portals)
+			nm.portals = append(nm.portals, navPortal{t: [2]int{tris[0], tris[1]}, v: e})
+			nm.triangles[tris[0]].portals = append(nm.triangles[tris[0]].portals, pi)
+			nm.triangles[tris[1]].portals = append(nm.triangles[tris[1]].portals, pi)
+		default:
+			return nil, fmt.Errorf("Navigation mesh edge shared by %d triangles", len(tris))
+		}
+	}
+	return nm, nil
+}
+
+// TriangleCount returns the number of triangles of the navigation mesh.
+func (nm *NavMesh) TriangleCount() int {
+
+	return len(nm.triangles)
+}
+
+// FindPath returns the shortest path over the surface from the specified start to the specified end position
+// as a list of waypoints which starts with the start position and ends with the end position.
+// Returns an error if a position is not over the mesh or if there is no path.
+func (nm *NavMesh) FindPath(start, end *math32.Vector3) ([]math32.Vector3, error) {
+
+	return nm.FindPathWithRadius(start, end, 0)
+}
+
+// FindPathWithRadius returns the shortest path over the surface from the specified start to the specified
+// end position for an agent with the specified radius, which keeps the path at the radius distance from
+// the corners of the boundary of the surface and does not pass through portals narrower than the agent.
+// The navigation mesh eroded for each radius is computed once and cached.
+// The start and end positions are not moved away from the boundary.
+func (nm *NavMesh) FindPathWithRadius(start, end *math32.Vector3, agentRadius float32) ([]math32.Vector3, error) {
+
+	ts := nm.locate(start)
+	if ts < 0 {
+		return nil, fmt.Errorf("Start position is not over the navigation mesh")
+	}
+	te := nm.locate(end)
+	if te < 0 {
+		return nil, fmt.Errorf("End position is not over the navigation mesh")
+	}
+	segments := nm.erode(agentRadius)
+	corridor := nm.search(ts, te, start, end, segments)
+	if corridor == nil {
+		return nil, fmt.Errorf("No path between the start and end positions")
+	}
+	return nm.funnel(corridor, start, end, segments), nil
+}
+
+// locate returns the index of the triangle below or above the specified position
+// which is nearest to it vertically, or -1 if there is none.
+func (nm *NavMesh) locate(p *math32.Vector3) int {
+
+	best := -1
+	bestDist := float32(math32.Infinity)
+	for i := range nm.triangles {
+		t := &nm.triangles[i]
+		a, b, c := &nm.vertices[t.v[0]], &nm.vertices[t.v[1]], &nm.vertices[t.v[2]]
+		// Barycentric coordinates in the XZ plane
+		det := (b.X-a.X)*(c.Z-a.Z) - (c.X-a.X)*(b.Z-a.Z)
+		u := ((p.X-a.X)*(c.Z-a.Z) - (c.X-a.X)*(p.Z-a.Z)) / det
+		v := ((b.X-a.X)*(p.Z-a.Z) - (p.X-a.X)*(b.Z-a.Z)) / det
+		const eps = 1e-5
+		if u < -eps || v < -eps || u+v > 1+eps {
+			continue
+		}
+		y := a.Y + u*(b.Y-a.Y) + v*(c.Y-a.Y)
+		if d := math32.Abs(p.Y - y); d < bestDist {
+			bestDist = d
+			best = i
+		}
+	}
+	return best
+}
+
+// erode returns the portals narrowed by the specified agent radius at their end points on the boundary.
+func (nm *NavMesh) erode(radius float32) []navSegment {
+
+	if segments, ok := nm.eroded[radius]; ok {
+		return segments
+	}
+	segments := make([]navSegment, len(nm.portals))
+	for i := range nm.portals {
+		p := &nm.portals[i]
+		s := &segments[i]
+		s.a = nm.vertices[p.v[0]]
+		s.b = nm.vertices[p.v[1]]
+		if radius <= 0 {
+			continue
+		}
+		var dir math32.Vector3
+		dir.SubVectors(&s.b, &s.a)
+		width := math32.Sqrt(dir.X*dir.X + dir.Z*dir.Z)
+		shrink := float32(0)
+		if nm.boundary[p.v[0]] {
+			shrink += radius
+		}
+		if nm.boundary[p.v[1]] {
+			shrink += radius
+		}
+		if shrink >= width {
+			s.blocked = shrink > 0
+			continue
+		}
+		dir.MultiplyScalar(radius / width)
+		if nm.boundary[p.v[0]] {
+			s.a.Add(&dir)
+		}
+		if nm.boundary[p.v[1]] {
+			s.b.Sub(&dir)
+		}
+	}
+	nm.eroded[radius] = segments
+	return segments
+}
+
+// navNode is an entry of the A* open lists.
+type navNode struct {
+	tri  int     // index of the triangle or waypoint
+	cost float32 // cost from the start plus heuristic
+}
+
+// navQueue is a priority queue of A* nodes ordered by cost.
+type navQueue []navNode
+
+func (q navQueue) Len() int            { return len(q) }
+func (q navQueue) Less(i, j int) bool  { return q[i].cost < q[j].cost }
+func (q navQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *navQueue) Push(x interface{}) { *q = append(*q, x.(navNode)) }
+func (q *navQueue) Pop() interface{} {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
+
+// search runs A* over the triangle adjacency graph from the start triangle to the end triangle
+// and returns the indices of the portals crossed by the path, or nil if there is no path.
+func (nm *NavMesh) search(ts, te int, start, end *math32.Vector3, segments []navSegment) []int {
+
+	if ts == te {
+		return []int{}
+	}
+	n := len(nm.triangles)
+	g := make([]float32, n)
+	from := make([]int, n) // portal through which each triangle was reached
+	closed := make([]bool, n)
+	pos := make([]math32.Vector3, n) // position where each triangle was entered
+	for i := range g {
+		g[i] = math32.Infinity
+		from[i] = -1
+	}
+	g[ts] = 0
+	pos[ts] = *start
+	open := &navQueue{{tri: ts, cost: start.DistanceTo(end)}}
+	for open.Len() > 0 {
+		node := heap.Pop(open).(navNode)
+		t := node.tri
+		if closed[t] {
+			continue
+		}
+		if t == te {
+			break
+		}
+		closed[t] = true
+		for _, pi := range nm.triangles[t].portals {
+			s := &segments[pi]
+			if s.blocked {
+				continue
+			}
+			p := &nm.portals[pi]
+			next := p.t[0]
+			if next == t {
+				next = p.t[1]
+			}
+			if closed[next] {
+				continue
+			}
+			// Triangles are entered at the middle of the portals
+			var mid math32.Vector3
+			mid.AddVectors(&s.a, &s.b).MultiplyScalar(0.5)
+			cost := g[t] + pos[t].DistanceTo(&mid)
+			if next == te {
+				cost += mid.DistanceTo(end)
+			}
+			if cost < g[next] {
+				g[next] = cost
+				from[next] = pi
+				pos[next] = mid
+				heap.Push(open, navNode{tri: next, cost: cost + mid.DistanceTo(end)})
+			}
+		}
+	}
+	if from[te] < 0 {
+		return nil
+	}
+
+	// Portals from the end back to the start
+	var corridor []int
+	for t := te; t != ts; {
+		pi := from[t]
+		corridor = append(corridor, pi)
+		p := &nm.portals[pi]
+		if p.t[0] == t {
+			t = p.t[1]
+		} else {
+			t = p.t[0]
+		}
+	}
+	for i, j := 0, len(corridor)-1; i < j; i, j = i+1, j-1 {
+		corridor[i], corridor[j] = corridor[j], corridor[i]
+	}
+	return corridor
+}
+
+// cross2 returns the Y component of the cross product of the specified vectors projected on the XZ plane,
+// which is positive if b is to the left of a.
+func cross2(a, b *math32.Vector3) float32 {
+
+	return a.Z*b.X - a.X*b.Z
+}
+
+// funnel returns the waypoints of the shortest path from the specified start to the specified end
+// through the specified portals using the simple stupid funnel algorithm.
+func (nm *NavMesh) funnel(corridor []int, start, end *math32.Vector3, segments []navSegment) []math32.Vector3 {
+
+	// Portal end points ordered as seen traveling through the corridor
+	lefts := make([]math32.Vector3, 0, len(corridor)+2)
+	rights := make([]math32.Vector3, 0, len(corridor)+2)
+	lefts = append(lefts, *start)
+	rights = append(rights, *start)
+	prev := *start
+	for i, pi := range corridor {
+		s := &segments[pi]
+		// Direction of travel from the previous portal to the next portal or end
+		var next math32.Vector3
+		if i+1 < len(corridor) {
+			ns := &segments[corridor[i+1]]
+			next.AddVectors(&ns.a, &ns.b).MultiplyScalar(0.5)
+		} else {
+			next = *end
+		}
+		var dir, da, db math32.Vector3
+		dir.SubVectors(&next, &prev)
+		da.SubVectors(&s.a, &prev)
+		db.SubVectors(&s.b, &prev)
+		if cross2(&dir, &da) > cross2(&dir, &db) {
+			lefts = append(lefts, s.a)
+			rights = append(rights, s.b)
+		} else {
+			lefts = append(lefts, s.b)
+			rights = append(rights, s.a)
+		}
+		prev.AddVectors(&s.a, &s.b).MultiplyScalar(0.5)
+	}
+	lefts = append(lefts, *end)
+	rights = append(rights, *end)
+
+	path := []math32.Vector3{*start}
+	apex, left, right := *start, *start, *start
+	apexIndex, leftIndex, rightIndex := 0, 0, 0
+	for i := 1; i < len(lefts); i++ {
+		var toLeft, toRight, toNew math32.Vector3
+		toLeft.SubVectors(&left, &apex)
+		toRight.SubVectors(&right, &apex)
+
+		// Moves the right side of the funnel inwards
+		toNew.SubVectors(&rights[i], &apex)
+		if cross2(&toRight, &toNew) >= 0 {
+			if apex.Equals(&right) || cross2(&toLeft, &toNew) < 0 {
+				right = rights[i]
+				rightIndex = i
+			} else {
+				// The right side crosses the left side, which becomes the new apex
+				path = appendWaypoint(path, &left)
+				apex, right = left, left
+				apexIndex = leftIndex
+				rightIndex = apexIndex
+				i = apexIndex
+				continue
+			}
+		}
+
+		// Moves the left side of the funnel inwards
+		toRight.SubVectors(&right, &apex)
+		toNew.SubVectors(&lefts[i], &apex)
+		if cross2(&toLeft, &toNew) <= 0 {
+			if apex.Equals(&left) || cross2(&toRight, &toNew) > 0 {
+				left = lefts[i]
+				leftIndex = i
+			} else {
+				// The left side crosses the right side, which becomes the new apex
+				path = appendWaypoint(path, &right)
+				apex, left = right, right
+				apexIndex = rightIndex
+				leftIndex = apexIndex
+				i = apexIndex
+				continue
+			}
+		}
+	}
+	return appendWaypoint(path, end)
+}
+
+// appendWaypoint appends the specified waypoint to the path if it is not equal to the last waypoint,
+// which happens when a portal end point is shared by consecutive portals.
+func appendWaypoint(path []math32.Vector3, p *math32.Vector3) []math32.Vector3 {
+
+	if path[len(path)-1].Equals(p) {
+		return path
+	}
+	return append(path, *p)
+}
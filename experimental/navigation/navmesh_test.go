@@ -0,0 +1,124 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package navigation
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// cellsGeometry returns the geometry of the unit squares of the XZ plane with the specified minimum corners,
+// each formed by two triangles facing up.
+func cellsGeometry(cells [][2]float32) *geometry.Geometry {
+
+	positions := math32.NewArrayF32(0, 0)
+	for _, c := range cells {
+		p00 := math32.Vector3{X: c[0], Z: c[1]}
+		p10 := math32.Vector3{X: c[0] + 1, Z: c[1]}
+		p01 := math32.Vector3{X: c[0], Z: c[1] + 1}
+		p11 := math32.Vector3{X: c[0] + 1, Z: c[1] + 1}
+		// Counter clockwise seen from above
+		positions.AppendVector3(&p00, &p11, &p10)
+		positions.AppendVector3(&p00, &p01, &p11)
+	}
+	geom := geometry.NewGeometry()
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	return geom
+}
+
+// lCorridor returns the navigation mesh of a corridor of width 1 along X from x=0 to x=10
+// which turns along Z at its end up to z=10, with the inner corner at (9, 0, 1).
+func lCorridor(t *testing.T) *NavMesh {
+
+	var cells [][2]float32
+	for i := 0; i < 10; i++ {
+		cells = append(cells, [2]float32{float32(i), 0})
+	}
+	for j := 1; j < 10; j++ {
+		cells = append(cells, [2]float32{9, float32(j)})
+	}
+	nm, err := NewNavMesh(cellsGeometry(cells))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return nm
+}
+
+// Test that the path around the corner of an L corridor is pulled to the inner corner
+func TestNavMeshFindPath(t *testing.T) {
+
+	nm := lCorridor(t)
+	if nm.TriangleCount() != 38 {
+		t.Fatal("TriangleCount", nm.TriangleCount(), "instead of 38")
+	}
+	start := math32.Vector3{X: 0.5, Z: 0.5}
+	end := math32.Vector3{X: 9.5, Z: 9.5}
+	path, err := nm.FindPath(&start, &end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []math32.Vector3{start, {X: 9, Z: 1}, end}
+	if len(path) != len(expected) {
+		t.Fatal("path", path, "instead of", expected)
+	}
+	for i := range path {
+		if path[i].DistanceTo(&expected[i]) > 1e-5 {
+			t.Fatal("path", path, "instead of", expected)
+		}
+	}
+
+	// Straight path inside the first segment of the corridor
+	end = math32.Vector3{X: 8.5, Z: 0.5}
+	path, err = nm.FindPath(&start, &end)
+	if err != nil || len(path) != 2 {
+		t.Error("path", path, err, "instead of a straight path")
+	}
+
+	// Positions outside of the mesh
+	outside := math32.Vector3{X: 5, Z: 5}
+	if _, err := nm.FindPath(&outside, &end); err == nil {
+		t.Error("no error for a start position outside of the mesh")
+	}
+	if _, err := nm.FindPath(&start, &outside); err == nil {
+		t.Error("no error for an end position outside of the mesh")
+	}
+}
+
+// Test that the path of an agent with a radius keeps the radius distance from the inner corner
+// and that the corridor is blocked for agents wider than it
+func TestNavMeshFindPathWithRadius(t *testing.T) {
+
+	nm := lCorridor(t)
+	start := math32.Vector3{X: 0.5, Z: 0.5}
+	end := math32.Vector3{X: 9.5, Z: 9.5}
+	corner := math32.Vector3{X: 9, Z: 1}
+	const radius = 0.25
+	path, err := nm.FindPathWithRadius(&start, &end, radius)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(path) < 3 || path[0] != start || path[len(path)-1] != end {
+		t.Fatal("path", path, "does not go from the start to the end around the corner")
+	}
+	nearest := float32(math32.Infinity)
+	for _, p := range path[1 : len(path)-1] {
+		d := p.DistanceTo(&corner)
+		if d < radius-1e-5 {
+			t.Error("waypoint", p, "nearer than the radius to the corner")
+		}
+		nearest = math32.Min(nearest, d)
+	}
+	if math32.Abs(nearest-radius) > 1e-5 {
+		t.Error("path", path, "not at the radius distance from the corner")
+	}
+
+	// The radius of the agent is larger than half the width of the corridor
+	if _, err := nm.FindPathWithRadius(&start, &end, 0.6); err == nil {
+		t.Error("no error for an agent wider than the corridor")
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package navigation
+
+import (
+	"container/heap"
+
+	"github.com/g3n/engine/math32"
+)
+
+// Waypoint is a node of a WaypointGraph.
+type Waypoint struct {
+	Position  math32.Vector3
+	Neighbors []int // indices of the waypoints reachable from this one
+}
+
+// WaypointGraph is a graph of manually placed waypoints, for simple navigation without a navigation mesh.
+// The cost of an edge is the distance between its waypoints. The edges may be directed, for one-way
+// connections like the jump pads.
+type WaypointGraph struct {
+	Waypoints []Waypoint
+}
+
+// NewWaypointGraph creates and returns a pointer to a new WaypointGraph without waypoints.
+func NewWaypointGraph() *WaypointGraph {
+
+	return new(WaypointGraph)
+}
+
+// AddWaypoint adds a waypoint at the specified position and returns its index.
+func (wg *WaypointGraph) AddWaypoint(pos *math32.Vector3) int {
+
+	wg.Waypoints = append(wg.Waypoints, Waypoint{Position: *pos})
+	return len(wg.Waypoints) - 1
+}
+
+// AddEdge connects the specified waypoints in both directions.
+func (wg *WaypointGraph) AddEdge(a, b int) {
+
+	wg.AddDirectedEdge(a, b)
+	wg.AddDirectedEdge(b, a)
+}
+
+// AddDirectedEdge connects the waypoint a to the waypoint b, but not b to a.
+func (wg *WaypointGraph) AddDirectedEdge(a, b int) {
+
+	for _, n := range wg.Waypoints[a].Neighbors {
+		if n == b {
+			return
+		}
+	}
+	wg.Waypoints[a].Neighbors = append(wg.Waypoints[a].Neighbors, b)
+}
+
+// FindPath runs A* from the start waypoint to the goal waypoint and returns the indices of the
+// waypoints of the shortest path, including the start and the goal, its length and true.
+// Returns false if the goal is not reachable or an index is invalid.
+func (wg *WaypointGraph) FindPath(startIdx, goalIdx int) ([]int, float32, bool) {
+
+	n := len(wg.Waypoints)
+	if startIdx < 0 || startIdx >= n || goalIdx < 0 || goalIdx >= n {
+		return nil, 0, false
+	}
+	goal := &wg.Waypoints[goalIdx].Position
+	g := make([]float32, n)
+	from := make([]int, n)
+	closed := make([]bool, n)
+	for i := range g {
+		g[i] = math32.Infinity
+		from[i] = -1
+	}
+	g[startIdx] = 0
+	open := &navQueue{{tri: startIdx, cost: wg.Waypoints[startIdx].Position.DistanceTo(goal)}}
+	for open.Len() > 0 {
+		w := heap.Pop(open).(navNode).tri
+		if closed[w] {
+			continue
+		}
+		if w == goalIdx {
+			break
+		}
+		closed[w] = true
+		pos := &wg.Waypoints[w].Position
+		for _, next := range wg.Waypoints[w].Neighbors {
+			if closed[next] {
+				continue
+			}
+			npos := &wg.Waypoints[next].Position
+			cost := g[w] + pos.DistanceTo(npos)
+			if cost < g[next] {
+				g[next] = cost
+				from[next] = w
+				heap.Push(open, navNode{tri: next, cost: cost + npos.DistanceTo(goal)})
+			}
+		}
+	}
+	if startIdx != goalIdx && from[goalIdx] < 0 {
+		return nil, 0, false
+	}
+
+	// Waypoints from the goal back to the start
+	var path []int
+	for w := goalIdx; w != startIdx; w = from[w] {
+		path = append(path, w)
+	}
+	path = append(path, startIdx)
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, g[goalIdx], true
+}
+
+// Nearest returns the index of the waypoint nearest to the specified position, or -1 if there are no waypoints.
+func (wg *WaypointGraph) Nearest(pos *math32.Vector3) int {
+
+	nearest := -1
+	var minDist float32
+	for i := range wg.Waypoints {
+		d := wg.Waypoints[i].Position.DistanceToSquared(pos)
+		if nearest < 0 || d < minDist {
+			nearest = i
+			minDist = d
+		}
+	}
+	return nearest
+}
+
+// FindPathToPosition finds the shortest path between the waypoints nearest to the specified agent
+// position and goal and returns the positions to follow: the waypoints of the path followed by the goal.
+// Returns false if the goal is not reachable.
+func (wg *WaypointGraph) FindPathToPosition(agentPos, goal *math32.Vector3) ([]math32.Vector3, bool) {
+
+	path, _, ok := wg.FindPath(wg.Nearest(agentPos), wg.Nearest(goal))
+	if !ok {
+		return nil, false
+	}
+	positions := make([]math32.Vector3, 0, len(path)+1)
+	for _, w := range path {
+		positions = append(positions, wg.Waypoints[w].Position)
+	}
+	if positions[len(positions)-1] != *goal {
+		positions = append(positions, *goal)
+	}
+	return positions, true
+}
@@ -0,0 +1,7 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package network implements helpers to display the entities of multiplayer games updated from the network.
+// WARNING: This package is experimental and incomplete!
+package network
@@ -0,0 +1,96 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package network
+
+import (
+	"sort"
+
+	"github.com/g3n/engine/math32"
+)
+
+// Transform is the position and rotation of an entity.
+type Transform struct {
+	Position math32.Vector3
+	Rotation math32.Quaternion
+}
+
+// worldState is the transforms of the entities recorded at a time.
+type worldState struct {
+	time       float64
+	transforms []Transform
+}
+
+// LagCompensation keeps the recent history of the transforms of the entities of the server,
+// to detect the hits of a client against the entities where the client saw them:
+// in the past, by its latency and interpolation delay. The entities are identified by their
+// index in the recorded states.
+type LagCompensation struct {
+	MaxHistoryDuration float64      // duration of the kept history in seconds
+	history            []worldState // oldest first
+}
+
+// NewLagCompensation creates and returns a pointer to a new LagCompensation without history
+// which keeps the states recorded in the last 250 ms.
+func NewLagCompensation() *LagCompensation {
+
+	lc := new(LagCompensation)
+	lc.MaxHistoryDuration = 0.25
+	return lc
+}
+
+// Record adds a copy of the specified state, recorded at the specified time in seconds, and discards
+// the states older than MaxHistoryDuration before it. The states not more recent than the last one are ignored.
+func (lc *LagCompensation) Record(ts float64, state []Transform) {
+
+	if n := len(lc.history); n > 0 && ts <= lc.history[n-1].time {
+		return
+	}
+	// Reuses the transforms of a discarded state
+	var transforms []Transform
+	old := 0
+	for old < len(lc.history) && lc.history[old].time < ts-lc.MaxHistoryDuration {
+		old++
+	}
+	if old > 0 {
+		transforms = lc.history[0].transforms[:0]
+		lc.history = append(lc.history[:0], lc.history[old:]...)
+	}
+	transforms = append(transforms, state...)
+	lc.history = append(lc.history, worldState{time: ts, transforms: transforms})
+}
+
+// Rollback returns the transforms of the entities at the specified time, interpolated between the two
+// recorded states which bracket the time. Returns the oldest or last state for the times before or
+// after the history, and nil if there is no history. The entities which are only in the more recent
+// state are returned as in it.
+func (lc *LagCompensation) Rollback(ts float64) []Transform {
+
+	n := len(lc.history)
+	if n == 0 {
+		return nil
+	}
+	// Index of the first state not before the time
+	i := sort.Search(n, func(i int) bool { return lc.history[i].time >= ts })
+	if i == n {
+		return append([]Transform(nil), lc.history[n-1].transforms...)
+	}
+	s1 := &lc.history[i]
+	result := append([]Transform(nil), s1.transforms...)
+	if i == 0 || s1.time == ts {
+		return result
+	}
+	s0 := &lc.history[i-1]
+	t := float32((ts - s0.time) / (s1.time - s0.time))
+	for j := range result {
+		if j >= len(s0.transforms) {
+			break
+		}
+		tr := s0.transforms[j]
+		tr.Position.Lerp(&result[j].Position, t)
+		tr.Rotation.Slerp(&result[j].Rotation, t)
+		result[j] = tr
+	}
+	return result
+}
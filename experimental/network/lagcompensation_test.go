@@ -0,0 +1,102 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package network
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+// lagTransform returns the transform of the test entity at the specified time.
+func lagTransform(ts float64) Transform {
+
+	var tr Transform
+	tr.Position.Set(float32(ts), 2*float32(ts), 0)
+	tr.Rotation.SetFromAxisAngle(&math32.Vector3{Y: 1}, float32(ts))
+	return tr
+}
+
+// lagTime returns the time of the recorded state with the specified index.
+func lagTime(i int) float64 {
+
+	return float64(i) * 0.05
+}
+
+// recordedLagCompensation returns a LagCompensation with the states of two entities recorded every 50 ms for 5 s.
+func recordedLagCompensation() *LagCompensation {
+
+	lc := NewLagCompensation()
+	for i := 0; i < 100; i++ {
+		ts := lagTime(i)
+		lc.Record(ts, []Transform{lagTransform(ts), lagTransform(-ts)})
+	}
+	return lc
+}
+
+// Test that the recorded states are returned exactly and that the states in between are interpolated
+func TestLagCompensationRollback(t *testing.T) {
+
+	lc := recordedLagCompensation()
+	if len(lc.history) != 6 {
+		t.Fatal(len(lc.history), "states kept instead of the 6 of the last 250 ms")
+	}
+	if len(lc.Rollback(4.9)) != 2 {
+		t.Fatal("Rollback did not return the 2 entities")
+	}
+
+	// Exactly recorded timestamps
+	for i := 94; i < 100; i++ {
+		ts := lagTime(i)
+		state := lc.Rollback(ts)
+		if state[0] != lagTransform(ts) || state[1] != lagTransform(-ts) {
+			t.Error("Rollback of the recorded time", ts, "returned", state)
+		}
+	}
+
+	// Midpoints between recorded timestamps
+	for i := 94; i < 99; i++ {
+		ts := (float64(i) + 0.5) * 0.05
+		state := lc.Rollback(ts)
+		for j, sign := range []float64{1, -1} {
+			expected := lagTransform(sign * ts)
+			if state[j].Position.DistanceTo(&expected.Position) > 1e-5 {
+				t.Error("Rollback position", state[j].Position, "instead of", expected.Position, "at", ts)
+			}
+			if math32.Abs(state[j].Rotation.Dot(&expected.Rotation)) < 1-1e-6 {
+				t.Error("Rollback rotation", state[j].Rotation, "instead of", expected.Rotation, "at", ts)
+			}
+		}
+	}
+
+	// Times outside of the history
+	if state := lc.Rollback(0); state[0] != lc.history[0].transforms[0] {
+		t.Error("Rollback before the history returned", state[0], "instead of the oldest state")
+	}
+	if state := lc.Rollback(10); state[0] != lagTransform(lagTime(99)) {
+		t.Error("Rollback after the history returned", state[0], "instead of the last state")
+	}
+
+	// The returned states are copies
+	state := lc.Rollback(lagTime(99))
+	state[0].Position.X = 100
+	if lc.Rollback(lagTime(99))[0] != lagTransform(lagTime(99)) {
+		t.Error("Rollback returned the recorded state instead of a copy")
+	}
+	if NewLagCompensation().Rollback(1) != nil {
+		t.Error("Rollback without history did not return nil")
+	}
+}
+
+// Test that the states not more recent than the last one are ignored
+func TestLagCompensationRecord(t *testing.T) {
+
+	lc := recordedLagCompensation()
+	lc.Record(lagTime(98), []Transform{lagTransform(100)})
+	lc.Record(lagTime(99), []Transform{lagTransform(100)})
+	if len(lc.history) != 6 || lc.Rollback(lagTime(99))[0] != lagTransform(lagTime(99)) {
+		t.Error("state older than the last one recorded")
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package network
+
+import (
+	"sort"
+
+	"github.com/g3n/engine/math32"
+)
+
+// Snapshot is the state of a remote entity received at a time of the server clock, in seconds.
+type Snapshot struct {
+	Time     float64
+	Position math32.Vector3
+	Velocity math32.Vector3
+}
+
+// NetworkTransform smooths the position of a remote entity from the snapshots received from the network.
+// The entity is usually rendered in the past, at the current time minus a delay of about 100 ms,
+// so that the rendered time is bracketed by two received snapshots, even if some are late or lost.
+// With snapshots sent at 20 Hz, a delay of 100 ms tolerates the loss of one snapshot.
+type NetworkTransform struct {
+	snapshots []Snapshot // ring buffer
+	first     int        // index of the oldest snapshot
+	count     int        // number of snapshots
+}
+
+// NewNetworkTransform creates and returns a pointer to a new NetworkTransform without snapshots,
+// which keeps the specified number of most recent snapshots.
+func NewNetworkTransform(capacity int) *NetworkTransform {
+
+	if capacity < 2 {
+		panic("NewNetworkTransform: capacity must be at least 2")
+	}
+	nt := new(NetworkTransform)
+	nt.snapshots = make([]Snapshot, capacity)
+	return nt
+}
+
+// AddSnapshot adds a snapshot with the specified time, position and velocity, replacing the oldest
+// snapshot if the buffer is full. The snapshots not more recent than the last one, received out of order,
+// are ignored.
+func (nt *NetworkTransform) AddSnapshot(ts float64, pos, vel *math32.Vector3) {
+
+	if nt.count > 0 && ts <= nt.at(nt.count-1).Time {
+		return
+	}
+	s := Snapshot{Time: ts, Position: *pos, Velocity: *vel}
+	if nt.count < len(nt.snapshots) {
+		nt.snapshots[(nt.first+nt.count)%len(nt.snapshots)] = s
+		nt.count++
+		return
+	}
+	nt.snapshots[nt.first] = s
+	nt.first = (nt.first + 1) % len(nt.snapshots)
+}
+
+// Len returns the number of snapshots in the buffer.
+func (nt *NetworkTransform) Len() int {
+
+	return nt.count
+}
+
+// Snapshot returns the snapshot with the specified index, from 0 for the oldest to Len()-1 for the last.
+func (nt *NetworkTransform) Snapshot(i int) Snapshot {
+
+	return *nt.at(i)
+}
+
+// at returns a pointer to the snapshot with the specified index from the oldest.
+func (nt *NetworkTransform) at(i int) *Snapshot {
+
+	return &nt.snapshots[(nt.first+i)%len(nt.snapshots)]
+}
+
+// Interpolate returns the position at the specified time, interpolated linearly between the
+// two snapshots which bracket the time. Returns the position of the oldest or last snapshot
+// for the times before or after them, and the zero vector if there are no snapshots.
+func (nt *NetworkTransform) Interpolate(renderTime float64) math32.Vector3 {
+
+	if nt.count == 0 {
+		return math32.Vector3{}
+	}
+	// Index of the first snapshot after the time
+	i := sort.Search(nt.count, func(i int) bool { return nt.at(i).Time > renderTime })
+	if i == 0 {
+		return nt.at(0).Position
+	}
+	if i == nt.count {
+		return nt.at(nt.count - 1).Position
+	}
+	s0, s1 := nt.at(i-1), nt.at(i)
+	t := float32((renderTime - s0.Time) / (s1.Time - s0.Time))
+	pos := s0.Position
+	return *pos.Lerp(&s1.Position, t)
+}
+
+// Extrapolate returns the position at the specified time as Interpolate, except for the times
+// after the last snapshot, for which the last position is extended along the last velocity
+// for at most maxExtrapolation seconds.
+func (nt *NetworkTransform) Extrapolate(renderTime float64, maxExtrapolation float32) math32.Vector3 {
+
+	if nt.count == 0 || renderTime <= nt.at(nt.count-1).Time {
+		return nt.Interpolate(renderTime)
+	}
+	last := nt.at(nt.count - 1)
+	dt := math32.Min(float32(renderTime-last.Time), maxExtrapolation)
+	pos := last.Position
+	vel := last.Velocity
+	return *pos.Add(vel.MultiplyScalar(dt))
+}
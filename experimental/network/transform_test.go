@@ -0,0 +1,158 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package network
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+// transformSink keeps the results of the benchmarks alive.
+var transformSink math32.Vector3
+
+// snapshotTime returns the time of the snapshot with the specified index, sent at 20 Hz.
+func snapshotTime(i int) float64 {
+
+	return float64(i) * 0.05
+}
+
+// snapshotPosition returns the position of the snapshot with the specified index,
+// which does not move linearly so that the interpolated values depend on the bracketing snapshots.
+func snapshotPosition(i int) math32.Vector3 {
+
+	return math32.Vector3{X: float32(i * i), Y: float32(i), Z: 1}
+}
+
+// snapshotTransform returns a NetworkTransform with the specified capacity and count of snapshots.
+func snapshotTransform(capacity, count int) *NetworkTransform {
+
+	nt := NewNetworkTransform(capacity)
+	for i := 0; i < count; i++ {
+		pos := snapshotPosition(i)
+		nt.AddSnapshot(snapshotTime(i), &pos, &math32.Vector3{X: float32(2 * i), Y: 1})
+	}
+	return nt
+}
+
+// checkPosition checks that the position is the expected one.
+func checkPosition(t *testing.T, what string, ts float64, got, want math32.Vector3) {
+
+	t.Helper()
+	if math32.Abs(got.X-want.X) > 1e-4 || math32.Abs(got.Y-want.Y) > 1e-4 || math32.Abs(got.Z-want.Z) > 1e-4 {
+		t.Error(what, "at", ts, "returned", got, "instead of", want)
+	}
+}
+
+// Test the interpolation between the snapshots bracketing the time
+func TestNetworkTransformInterpolate(t *testing.T) {
+
+	nt := snapshotTransform(8, 5)
+	for i := 0; i < 5; i++ {
+		checkPosition(t, "Interpolate", snapshotTime(i), nt.Interpolate(snapshotTime(i)), snapshotPosition(i))
+	}
+	for i := 0; i < 4; i++ {
+		p0, p1 := snapshotPosition(i), snapshotPosition(i+1)
+		for _, f := range []float32{0.25, 0.5, 0.75} {
+			ts := snapshotTime(i) + float64(f)*0.05
+			want := p0
+			want.Lerp(&p1, f)
+			checkPosition(t, "Interpolate", ts, nt.Interpolate(ts), want)
+		}
+	}
+}
+
+// Test the clamping of the interpolation to the oldest and last snapshots
+func TestNetworkTransformClamp(t *testing.T) {
+
+	if pos := NewNetworkTransform(2).Interpolate(1); pos != (math32.Vector3{}) {
+		t.Error("Interpolate without snapshots returned", pos, "instead of the zero vector")
+	}
+	nt := snapshotTransform(8, 5)
+	for _, ts := range []float64{-1, -0.01} {
+		checkPosition(t, "Interpolate", ts, nt.Interpolate(ts), snapshotPosition(0))
+	}
+	for _, ts := range []float64{0.21, 10} {
+		checkPosition(t, "Interpolate", ts, nt.Interpolate(ts), snapshotPosition(4))
+	}
+}
+
+// Test the extrapolation along the last velocity, capped by the maximum extrapolation
+func TestNetworkTransformExtrapolate(t *testing.T) {
+
+	nt := snapshotTransform(8, 5)
+	last := snapshotPosition(4)
+	for _, ts := range []float64{-1, 0.075, 0.2} {
+		checkPosition(t, "Extrapolate", ts, nt.Extrapolate(ts, 0.1), nt.Interpolate(ts))
+	}
+	checkPosition(t, "Extrapolate", 0.25, nt.Extrapolate(0.25, 0.1), math32.Vector3{X: last.X + 8*0.05, Y: last.Y + 0.05, Z: 1})
+	for _, ts := range []float64{0.3, 0.5, 10} {
+		checkPosition(t, "Extrapolate", ts, nt.Extrapolate(ts, 0.1), math32.Vector3{X: last.X + 8*0.1, Y: last.Y + 0.1, Z: 1})
+	}
+	checkPosition(t, "Extrapolate", 10, nt.Extrapolate(10, 0), last)
+}
+
+// Test that the ring buffer keeps the most recent snapshots in order
+func TestNetworkTransformWraparound(t *testing.T) {
+
+	nt := snapshotTransform(4, 11)
+	if nt.Len() != 4 {
+		t.Fatal("Len returned", nt.Len(), "instead of 4")
+	}
+	for i := 0; i < 4; i++ {
+		if s := nt.Snapshot(i); s.Time != snapshotTime(7+i) || s.Position != snapshotPosition(7+i) {
+			t.Error("Snapshot", i, "returned", s, "instead of the snapshot", 7+i)
+		}
+	}
+	// The times before the oldest kept snapshot are clamped to it
+	checkPosition(t, "Interpolate", snapshotTime(3), nt.Interpolate(snapshotTime(3)), snapshotPosition(7))
+	ts := snapshotTime(8) + 0.025
+	want := snapshotPosition(8)
+	p1 := snapshotPosition(9)
+	checkPosition(t, "Interpolate", ts, nt.Interpolate(ts), *want.Lerp(&p1, 0.5))
+}
+
+// Test that the snapshots received out of order are dropped
+func TestNetworkTransformOutOfOrder(t *testing.T) {
+
+	nt := snapshotTransform(8, 5)
+	pos := math32.Vector3{X: 100}
+	for _, ts := range []float64{snapshotTime(4), snapshotTime(2), -1} {
+		nt.AddSnapshot(ts, &pos, &pos)
+		if nt.Len() != 5 {
+			t.Fatal("Len returned", nt.Len(), "after the snapshot at", ts, "received out of order")
+		}
+	}
+	for i := 0; i < 5; i++ {
+		if s := nt.Snapshot(i); s.Position != snapshotPosition(i) {
+			t.Error("Snapshot", i, "returned", s, "after the snapshots received out of order")
+		}
+	}
+	nt.AddSnapshot(snapshotTime(5), &pos, &pos)
+	if nt.Len() != 6 || nt.Snapshot(5).Position != pos {
+		t.Error("the snapshot in order after the ones out of order was not added")
+	}
+}
+
+// Benchmark the playback of 30 s at 60 frames per second, with snapshots received at 20 Hz
+// and rendered with an interpolation delay of 100 ms
+func BenchmarkNetworkTransformPlayback(b *testing.B) {
+
+	const delay = 0.1
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		nt := NewNetworkTransform(32)
+		next := 0
+		for frame := 0; frame < 30*60; frame++ {
+			now := float64(frame) / 60
+			for snapshotTime(next) <= now {
+				pos := snapshotPosition(next)
+				nt.AddSnapshot(snapshotTime(next), &pos, &math32.Vector3{X: 1})
+				next++
+			}
+			transformSink = nt.Interpolate(now - delay)
+		}
+	}
+}
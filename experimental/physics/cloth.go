@@ -0,0 +1,407 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics
+
+import (
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"runtime"
+	"sync"
+)
+
+// Default stiffness of each type of cloth constraint.
+const (
+	clothStructuralStiffness = 1.0
+	clothShearStiffness      = 0.5
+	clothBendStiffness       = 0.2
+)
+
+// clothMinParallel is the minimum number of loop iterations split across workers.
+const clothMinParallel = 256
+
+//
+// Cloth is a position based dynamics cloth simulation of a grid of particles connected by
+// structural springs (to the adjacent particles), shear springs (to the diagonal particles)
+// and bend springs (to the particles two cells away).
+// The particle (i,j) is stored at index i+j*width and is initially located at
+// (i*cellSize, 0, j*cellSize).
+//
+type Cloth struct {
+	width         int
+	height        int
+	cellSize      float32
+	positions     []math32.Vector3   // current particle positions
+	previous      []math32.Vector3   // particle positions in the previous step
+	pinned        []bool             // particles with fixed positions
+	constraints   []SpringConstraint // all spring constraints
+	batches       [][]int            // groups of constraints without shared particles
+	iterations    int                // number of constraint projection iterations per update
+	damping       float32            // fraction of the velocity kept at each update
+	mass          float32            // mass of each particle
+	time          float32            // simulation time passed to the wind
+	selfCollision bool               // self collision is enabled
+	thickness     float32            // minimum distance between non-neighbour particles
+	workers       int                // number of goroutines of parallel loops
+}
+
+// NewCloth creates and returns a pointer to a new Cloth with the specified number
+// of particles in each direction separated by the specified cell size.
+func NewCloth(width, height int, cellSize float32) *Cloth {
+
+	if width < 2 || height < 2 {
+		panic("NewCloth: cloth must have at least 2x2 particles")
+	}
+	c := new(Cloth)
+	c.width = width
+	c.height = height
+	c.cellSize = cellSize
+	c.iterations = 8
+	c.damping = 0.99
+	c.mass = 1
+	c.thickness = cellSize * 0.5
+	c.workers = runtime.NumCPU()
+
+	count := width * height
+	c.positions = make([]math32.Vector3, count)
+	c.previous = make([]math32.Vector3, count)
+	c.pinned = make([]bool, count)
+	for j := 0; j < height; j++ {
+		for i := 0; i < width; i++ {
+			c.positions[c.index(i, j)].Set(float32(i)*cellSize, 0, float32(j)*cellSize)
+		}
+	}
+	copy(c.previous, c.positions)
+
+	connect := func(i0, j0, i1, j1 int, stiffness float32) {
+		if i1 < 0 || i1 >= width || j1 >= height {
+			return
+		}
+		c.constraints = append(c.constraints, *NewSpringConstraint(c.positions, c.index(i0, j0), c.index(i1, j1), stiffness))
+	}
+	for j := 0; j < height; j++ {
+		for i := 0; i < width; i++ {
+			connect(i, j, i+1, j, clothStructuralStiffness)
+			connect(i, j, i, j+1, clothStructuralStiffness)
+			connect(i, j, i+1, j+1, clothShearStiffness)
+			connect(i, j, i-1, j+1, clothShearStiffness)
+			connect(i, j, i+2, j, clothBendStiffness)
+			connect(i, j, i, j+2, clothBendStiffness)
+		}
+	}
+	c.buildBatches()
+	return c
+}
+
+// index returns the index of the specified particle.
+func (c *Cloth) index(i, j int) int {
+
+	return i + j*c.width
+}
+
+// buildBatches groups the constraints so that constraints of the same group do not share
+// particles and may be projected in parallel, by greedy coloring of the constraints graph.
+func (c *Cloth) buildBatches() {
+
+	// Colors already used by the constraints of each particle, as bit masks
+	used := make([]uint64, len(c.positions))
+	c.batches = nil
+	for k := range c.constraints {
+		a := c.constraints[k].A
+		b := c.constraints[k].B
+		color := 0
+		for color < 64 && (used[a]|used[b])&(1<<uint(color)) != 0 {
+			color++
+		}
+		if color < 64 {
+			used[a] |= 1 << uint(color)
+			used[b] |= 1 << uint(color)
+		}
+		for len(c.batches) <= color {
+			c.batches = append(c.batches, nil)
+		}
+		c.batches[color] = append(c.batches[color], k)
+	}
+	// Constraints which exceeded the colors limit must be projected serially
+	if len(c.batches) > 64 {
+		for _, k := range c.batches[64] {
+			c.batches = append(c.batches, []int{k})
+		}
+		c.batches = append(c.batches[:64], c.batches[65:]...)
+	}
+}
+
+// Dimensions returns the number of particles of the cloth in each direction.
+func (c *Cloth) Dimensions() (width, height int) {
+
+	return c.width, c.height
+}
+
+// Positions returns the current positions of the cloth particles.
+func (c *Cloth) Positions() []math32.Vector3 {
+
+	return c.positions
+}
+
+// Constraints returns the spring constraints of the cloth.
+func (c *Cloth) Constraints() []SpringConstraint {
+
+	return c.constraints
+}
+
+// Pin fixes the position of the specified particle.
+func (c *Cloth) Pin(i, j int) {
+
+	c.setPinned(c.index(i, j), true)
+}
+
+// Unpin releases the position of the specified particle.
+func (c *Cloth) Unpin(i, j int) {
+
+	c.setPinned(c.index(i, j), false)
+}
+
+// setPinned sets if the specified particle is fixed and updates its constraints.
+func (c *Cloth) setPinned(idx int, pinned bool) {
+
+	c.pinned[idx] = pinned
+	c.previous[idx] = c.positions[idx]
+	for k := range c.constraints {
+		sc := &c.constraints[k]
+		if sc.A == idx {
+			sc.PinnedA = pinned
+		}
+		if sc.B == idx {
+			sc.PinnedB = pinned
+		}
+	}
+}
+
+// SetIterations sets the number of constraint projection iterations per update.
+func (c *Cloth) SetIterations(iterations int) {
+
+	c.iterations = iterations
+}
+
+// SetDamping sets the fraction of the particle velocities kept at each update.
+func (c *Cloth) SetDamping(damping float32) {
+
+	c.damping = damping
+}
+
+// SetParticleMass sets the mass of each particle, which scales the effect of the wind.
+func (c *Cloth) SetParticleMass(mass float32) {
+
+	c.mass = mass
+}
+
+// SetSelfCollision sets if the cloth particles collide with each other and
+// the minimum distance kept between particles which are not neighbours.
+func (c *Cloth) SetSelfCollision(enabled bool, thickness float32) {
+
+	c.selfCollision = enabled
+	c.thickness = thickness
+}
+
+// SetWorkers sets the number of goroutines used by the parallel loops of the simulation.
+func (c *Cloth) SetWorkers(workers int) {
+
+	if workers < 1 {
+		workers = 1
+	}
+	c.workers = workers
+}
+
+// parallelFor calls fn for contiguous sub ranges of [0,n) split across the worker goroutines
+// and waits for all of them to finish. Small ranges are processed by the calling goroutine.
+func (c *Cloth) parallelFor(n int, fn func(start, end int)) {
+
+	if c.workers <= 1 || n < clothMinParallel {
+		fn(0, n)
+		return
+	}
+	chunk := (n + c.workers - 1) / c.workers
+	var wg sync.WaitGroup
+	for start := 0; start < n; start += chunk {
+		end := start + chunk
+		if end > n {
+			end = n
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			fn(start, end)
+		}(start, end)
+	}
+	wg.Wait()
+}
+
+// Update advances the simulation by the specified time step: integrates the particle velocities
+// with the specified gravity acceleration and optional wind, projects the constraints and,
+// if enabled, resolves self collisions.
+func (c *Cloth) Update(dt float32, gravity *math32.Vector3, wind *Wind) {
+
+	if dt <= 0 {
+		return
+	}
+	c.time += dt
+	dt2 := dt * dt
+
+	// Verlet integration
+	c.parallelFor(len(c.positions), func(start, end int) {
+		for i := start; i < end; i++ {
+			if c.pinned[i] {
+				continue
+			}
+			p := &c.positions[i]
+			accel := *gravity
+			if wind != nil && c.mass > 0 {
+				force := wind.ForceAt(p, c.time)
+				accel.Add(force.MultiplyScalar(1 / c.mass))
+			}
+			vel := *p
+			vel.Sub(&c.previous[i]).MultiplyScalar(c.damping)
+			c.previous[i] = *p
+			p.Add(&vel).Add(accel.MultiplyScalar(dt2))
+		}
+	})
+
+	// Constraints of the same batch do not share particles
+	for it := 0; it < c.iterations; it++ {
+		for _, batch := range c.batches {
+			c.parallelFor(len(batch), func(start, end int) {
+				for _, k := range batch[start:end] {
+					c.constraints[k].Satisfy(c.positions)
+				}
+			})
+		}
+	}
+
+	if c.selfCollision {
+		c.collideSelf()
+	}
+}
+
+// collideSelf pushes apart the particles closer than the cloth thickness
+// which are not within two cells of each other in the grid, using a spatial hash.
+func (c *Cloth) collideSelf() {
+
+	if c.thickness <= 0 {
+		return
+	}
+	type cell struct{ x, y, z int32 }
+	cellOf := func(p *math32.Vector3) cell {
+		return cell{
+			int32(math32.Floor(p.X / c.thickness)),
+			int32(math32.Floor(p.Y / c.thickness)),
+			int32(math32.Floor(p.Z / c.thickness)),
+		}
+	}
+	grid := make(map[cell][]int)
+	for i := range c.positions {
+		key := cellOf(&c.positions[i])
+		grid[key] = append(grid[key], i)
+	}
+
+	minDist2 := c.thickness * c.thickness
+	for i := range c.positions {
+		ci := cellOf(&c.positions[i])
+		ii, ji := i%c.width, i/c.width
+		for dz := int32(-1); dz <= 1; dz++ {
+			for dy := int32(-1); dy <= 1; dy++ {
+				for dx := int32(-1); dx <= 1; dx++ {
+					for _, j := range grid[cell{ci.x + dx, ci.y + dy, ci.z + dz}] {
+						if j <= i || (c.pinned[i] && c.pinned[j]) {
+							continue
+						}
+						// Skips the particles connected by springs
+						di, dj := j%c.width-ii, j/c.width-ji
+						if di >= -2 && di <= 2 && dj >= -2 && dj <= 2 {
+							continue
+						}
+						var d math32.Vector3
+						d.SubVectors(&c.positions[j], &c.positions[i])
+						dist2 := d.LengthSq()
+						if dist2 >= minDist2 || dist2 == 0 {
+							continue
+						}
+						dist := math32.Sqrt(dist2)
+						d.MultiplyScalar((c.thickness - dist) / dist)
+						wi := float32(0.5)
+						wj := float32(0.5)
+						if c.pinned[i] {
+							wi, wj = 0, 1
+						} else if c.pinned[j] {
+							wi, wj = 1, 0
+						}
+						c.positions[i].X -= d.X * wi
+						c.positions[i].Y -= d.Y * wi
+						c.positions[i].Z -= d.Z * wi
+						c.positions[j].X += d.X * wj
+						c.positions[j].Y += d.Y * wj
+						c.positions[j].Z += d.Z * wj
+					}
+				}
+			}
+		}
+	}
+}
+
+// ToMesh generates and returns a geometry of the current deformed cloth with one vertex
+// per particle and vertex positions, normals and texture coordinates.
+func (c *Cloth) ToMesh() *geometry.Geometry {
+
+	count := len(c.positions)
+	positions := math32.NewArrayF32(0, count*3)
+	normals := math32.NewArrayF32(count*3, count*3)
+	uvs := math32.NewArrayF32(0, count*2)
+	indices := math32.NewArrayU32(0, (c.width-1)*(c.height-1)*6)
+
+	for j := 0; j < c.height; j++ {
+		for i := 0; i < c.width; i++ {
+			positions.AppendVector3(&c.positions[c.index(i, j)])
+			uvs.Append(float32(i)/float32(c.width-1), float32(j)/float32(c.height-1))
+		}
+	}
+
+	// Vertex normals are the sums of the normals of the adjacent faces weighted by their areas
+	var e1, e2, n math32.Vector3
+	addFace := func(a, b, d uint32) {
+		indices.Append(a, b, d)
+		e1.SubVectors(&c.positions[b], &c.positions[a])
+		e2.SubVectors(&c.positions[d], &c.positions[a])
+		n.CrossVectors(&e1, &e2)
+		for _, v := range [3]uint32{a, b, d} {
+			normals[v*3] += n.X
+			normals[v*3+1] += n.Y
+			normals[v*3+2] += n.Z
+		}
+	}
+	for j := 0; j < c.height-1; j++ {
+		for i := 0; i < c.width-1; i++ {
+			a := uint32(c.index(i, j))
+			b := a + uint32(c.width)
+			cc := b + 1
+			d := a + 1
+			addFace(a, b, d)
+			addFace(b, cc, d)
+		}
+	}
+	for v := 0; v < count; v++ {
+		var vn math32.Vector3
+		normals.GetVector3(v*3, &vn)
+		if vn.LengthSq() > 0 {
+			vn.Normalize()
+		}
+		normals.SetVector3(v*3, &vn)
+	}
+
+	geom := geometry.NewGeometry()
+	geom.SetIndices(indices)
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(normals).AddAttrib(gls.VertexNormal))
+	geom.AddVBO(gls.NewVBO(uvs).AddAttrib(gls.VertexTexcoord))
+	return geom
+}
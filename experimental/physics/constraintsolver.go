@@ -0,0 +1,234 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// ConstraintJacobian is the jacobian row of a constraint between two 2D bodies:
+// the derivative of the constraint relative to the linear and angular velocities of each body.
+type ConstraintJacobian struct {
+	LinearA  math32.Vector2
+	AngularA float32
+	LinearB  math32.Vector2
+	AngularB float32
+}
+
+// Constraint is the interface for the velocity constraints between two 2D bodies solved by ConstraintSolver.
+type Constraint interface {
+	// Bodies returns the constrained bodies. Either body may be nil for a constraint to the static world.
+	Bodies() (a, b *RigidBody2D)
+	// Jacobian returns the jacobian of the constraint at the current positions.
+	Jacobian() ConstraintJacobian
+	// PositionError returns the current value of the position constraint, negative when violated.
+	PositionError() float32
+	// Limits returns the minimum and maximum accumulated impulse.
+	Limits() (min, max float32)
+	// Impulse returns the accumulated impulse, which warm starts the solver.
+	Impulse() float32
+	// SetImpulse sets the accumulated impulse.
+	SetImpulse(impulse float32)
+}
+
+// ConstraintSolver solves the constraints between 2D bodies with sequential impulses, using projected
+// Gauss-Seidel iterations and Baumgarte stabilization of the position errors.
+type ConstraintSolver struct {
+	PositionCorrection float32 // fraction of the position error corrected per step (Baumgarte factor)
+	SloppedPenetration float32 // penetration of the one sided constraints allowed without correction
+	WarmStarting       bool    // applies the accumulated impulses of the constraints before iterating
+}
+
+// NewConstraintSolver creates and returns a pointer to a new ConstraintSolver with default parameters.
+func NewConstraintSolver() *ConstraintSolver {
+
+	cs := new(ConstraintSolver)
+	cs.PositionCorrection = 0.2
+	cs.SloppedPenetration = 0.01
+	cs.WarmStarting = true
+	return cs
+}
+
+// solverRow is the state of a constraint during the iterations.
+type solverRow struct {
+	c           Constraint
+	a, b        *RigidBody2D
+	jac         ConstraintJacobian
+	effMass     float32 // inverse of the effective mass of the constraint
+	bias        float32
+	min, max    float32
+	impulse     float32
+	invInertiaA float32
+	invInertiaB float32
+}
+
+// Solve advances the specified bodies by the specified time step: it integrates their velocities,
+// applies the impulses satisfying the specified constraints in the specified number of iterations
+// and then integrates their positions.
+func (cs *ConstraintSolver) Solve(bodies []*RigidBody2D, constraints []Constraint, dt float32, iterations int) {
+
+	for _, b := range bodies {
+		b.integrateVelocity(dt)
+	}
+
+	rows := make([]solverRow, 0, len(constraints))
+	for _, c := range constraints {
+		r := solverRow{c: c, jac: c.Jacobian()}
+		r.a, r.b = c.Bodies()
+		r.min, r.max = c.Limits()
+		rowInvMass(&r)
+		if r.effMass == 0 {
+			continue
+		}
+		// Baumgarte stabilization: the velocity target removes a fraction of the position error
+		perr := c.PositionError()
+		if r.min >= 0 {
+			perr = math32.Min(perr+cs.SloppedPenetration, 0)
+		}
+		if dt > 0 {
+			r.bias = -cs.PositionCorrection / dt * perr
+		}
+		if cs.WarmStarting {
+			r.impulse = math32.Clamp(c.Impulse(), r.min, r.max)
+			applyRowImpulse(&r, r.impulse)
+		}
+		rows = append(rows, r)
+	}
+
+	for iter := 0; iter < iterations; iter++ {
+		for i := range rows {
+			r := &rows[i]
+			// Impulse change driving the constraint velocity to the bias, clamped to the limits of the accumulated impulse
+			delta := (r.bias - rowVelocity(r)) * r.effMass
+			accum := math32.Clamp(r.impulse+delta, r.min, r.max)
+			delta = accum - r.impulse
+			r.impulse = accum
+			applyRowImpulse(r, delta)
+		}
+	}
+
+	for i := range rows {
+		rows[i].c.SetImpulse(rows[i].impulse)
+	}
+	for _, b := range bodies {
+		b.integratePosition(dt)
+	}
+}
+
+// rowInvMass sets the inverse of the effective mass of the specified row.
+func rowInvMass(r *solverRow) {
+
+	var k float32
+	if r.a != nil {
+		r.invInertiaA = r.a.inverseInertia()
+		k += r.a.InverseMass*r.jac.LinearA.LengthSq() + r.invInertiaA*r.jac.AngularA*r.jac.AngularA
+	}
+	if r.b != nil {
+		r.invInertiaB = r.b.inverseInertia()
+		k += r.b.InverseMass*r.jac.LinearB.LengthSq() + r.invInertiaB*r.jac.AngularB*r.jac.AngularB
+	}
+	if k > 0 {
+		r.effMass = 1 / k
+	}
+}
+
+// rowVelocity returns the velocity of the constraint of the specified row.
+func rowVelocity(r *solverRow) float32 {
+
+	var v float32
+	if r.a != nil {
+		v += r.jac.LinearA.Dot(&r.a.Velocity) + r.jac.AngularA*r.a.AngularVelocity
+	}
+	if r.b != nil {
+		v += r.jac.LinearB.Dot(&r.b.Velocity) + r.jac.AngularB*r.b.AngularVelocity
+	}
+	return v
+}
+
+// applyRowImpulse applies the specified impulse along the jacobian of the specified row to its bodies.
+func applyRowImpulse(r *solverRow, impulse float32) {
+
+	if r.a != nil {
+		r.a.Velocity.X += r.jac.LinearA.X * impulse * r.a.InverseMass
+		r.a.Velocity.Y += r.jac.LinearA.Y * impulse * r.a.InverseMass
+		r.a.AngularVelocity += r.jac.AngularA * impulse * r.invInertiaA
+	}
+	if r.b != nil {
+		r.b.Velocity.X += r.jac.LinearB.X * impulse * r.b.InverseMass
+		r.b.Velocity.Y += r.jac.LinearB.Y * impulse * r.b.InverseMass
+		r.b.AngularVelocity += r.jac.AngularB * impulse * r.invInertiaB
+	}
+}
+
+// ContactConstraint2D is a non penetration constraint at a contact point between two 2D bodies.
+type ContactConstraint2D struct {
+	a, b    *RigidBody2D
+	normal  math32.Vector2 // unit normal pointing from the first body to the second
+	rA, rB  math32.Vector2 // contact point relative to each body
+	depth   float32
+	impulse float32
+}
+
+// NewContactConstraint2D creates and returns a pointer to a new ContactConstraint2D between the specified
+// bodies at the specified world contact point, normal pointing from the first body to the second and
+// penetration depth. The first body may be nil for a contact with the static world.
+func NewContactConstraint2D(a, b *RigidBody2D, point, normal *math32.Vector2, depth float32) *ContactConstraint2D {
+
+	cc := new(ContactConstraint2D)
+	cc.a = a
+	cc.b = b
+	cc.normal = *normal
+	if a != nil {
+		cc.rA.SubVectors(point, &a.Position)
+	}
+	if b != nil {
+		cc.rB.SubVectors(point, &b.Position)
+	}
+	cc.depth = depth
+	return cc
+}
+
+// Bodies satisfies the Constraint interface.
+func (cc *ContactConstraint2D) Bodies() (a, b *RigidBody2D) {
+
+	return cc.a, cc.b
+}
+
+// Jacobian satisfies the Constraint interface.
+func (cc *ContactConstraint2D) Jacobian() ConstraintJacobian {
+
+	n := cc.normal
+	return ConstraintJacobian{
+		LinearA:  math32.Vector2{X: -n.X, Y: -n.Y},
+		AngularA: -cross2(&cc.rA, &n),
+		LinearB:  n,
+		AngularB: cross2(&cc.rB, &n),
+	}
+}
+
+// PositionError satisfies the Constraint interface.
+func (cc *ContactConstraint2D) PositionError() float32 {
+
+	return -cc.depth
+}
+
+// Limits satisfies the Constraint interface.
+// Contacts can only push the bodies apart.
+func (cc *ContactConstraint2D) Limits() (min, max float32) {
+
+	return 0, math32.Infinity
+}
+
+// Impulse satisfies the Constraint interface.
+func (cc *ContactConstraint2D) Impulse() float32 {
+
+	return cc.impulse
+}
+
+// SetImpulse satisfies the Constraint interface.
+func (cc *ContactConstraint2D) SetImpulse(impulse float32) {
+
+	cc.impulse = impulse
+}
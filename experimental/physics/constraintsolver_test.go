@@ -0,0 +1,88 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+// Test that a stack of unit boxes dropped slightly apart and offset settles and stays at rest without jitter
+// for 10 seconds of simulation
+func TestConstraintSolverStack(t *testing.T) {
+
+	const count = 6
+	var boxes []*RigidBody2D
+	for i := 0; i < count; i++ {
+		b := NewRigidBody2D(1, 1.0/6)
+		b.Position.Set(float32(i)*0.02, 0.5+float32(i)*1.01)
+		b.Acceleration.Y = -9.8
+		boxes = append(boxes, b)
+	}
+	cs := NewConstraintSolver()
+	const dt = 1.0 / 60
+
+	// Contacts at the corners of the overlap of each box with the box below or the ground,
+	// with the impulses of the previous step kept for warm starting
+	impulses := make(map[[2]int]float32)
+	var settled [count]math32.Vector2
+	var maxSpeed, maxAngularSpeed float32
+	for step := 0; step < 600; step++ {
+		var constraints []Constraint
+		var keys [][2]int
+		for i, b := range boxes {
+			var below *RigidBody2D
+			left, right, top := b.Position.X-0.5, b.Position.X+0.5, float32(0)
+			if i > 0 {
+				below = boxes[i-1]
+				left = math32.Max(left, below.Position.X-0.5)
+				right = math32.Min(right, below.Position.X+0.5)
+				top = below.Position.Y + 0.5
+			}
+			depth := top - (b.Position.Y - 0.5)
+			if depth < -0.02 {
+				continue
+			}
+			for k, x := range []float32{left, right} {
+				c := NewContactConstraint2D(below, b, &math32.Vector2{X: x, Y: b.Position.Y - 0.5}, &math32.Vector2{Y: 1}, depth)
+				key := [2]int{i, k}
+				c.SetImpulse(impulses[key])
+				constraints = append(constraints, c)
+				keys = append(keys, key)
+			}
+		}
+		cs.Solve(boxes, constraints, dt, 10)
+		for i, c := range constraints {
+			impulses[keys[i]] = c.Impulse()
+		}
+
+		// Positions after 5 seconds and the largest speeds of the last 5 seconds
+		if step == 299 {
+			for i, b := range boxes {
+				settled[i] = b.Position
+			}
+		} else if step >= 300 {
+			for _, b := range boxes {
+				maxSpeed = math32.Max(maxSpeed, b.Velocity.Length())
+				maxAngularSpeed = math32.Max(maxAngularSpeed, math32.Abs(b.AngularVelocity))
+			}
+		}
+	}
+
+	if maxSpeed > 1e-3 || maxAngularSpeed > 1e-3 {
+		t.Error("the stack jitters with the speed", maxSpeed, "and the angular speed", maxAngularSpeed)
+	}
+	for i, b := range boxes {
+		if b.Position.DistanceTo(&settled[i]) > 1e-3 {
+			t.Error("box", i, "moved from", settled[i], "to", b.Position, "in the last 5 seconds")
+		}
+		// Each contact sinks at most by the allowed penetration
+		expected := 0.5 + float32(i)
+		if math32.Abs(b.Position.Y-expected) > 2*cs.SloppedPenetration*float32(i+1) || math32.Abs(b.Angle) > 0.01 {
+			t.Error("box", i, "at", b.Position, "with the angle", b.Angle, "instead of the height", expected)
+		}
+	}
+}
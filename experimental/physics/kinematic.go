@@ -0,0 +1,168 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics
+
+import (
+	"github.com/g3n/engine/experimental/collision/shape"
+	"github.com/g3n/engine/math32"
+)
+
+// Collider is the interface for the static geometry a KinematicBody collides with.
+// The resolution of the contacts assumes convex colliders.
+type Collider interface {
+	// ClosestPoint returns the point of the collider which is closest to the specified point.
+	ClosestPoint(point *math32.Vector3) math32.Vector3
+	// IntersectsCapsule returns if the collider intersects the capsule whose hemispheres are
+	// centered at the specified points and have the specified radius.
+	IntersectsCapsule(a, b *math32.Vector3, radius float32) bool
+}
+
+// kinematicIterations is the maximum number of times the contacts are resolved per step.
+const kinematicIterations = 4
+
+// kinematicSkin is the distance kept between the capsule and the colliders after a contact is resolved.
+const kinematicSkin = 1e-4
+
+// KinematicBody is a character controller body moved programmatically instead of by forces.
+// Its shape is a vertical capsule centered at its position which slides along the colliders it hits.
+type KinematicBody struct {
+	Capsule    *shape.Capsule
+	Position   math32.Vector3
+	Velocity   math32.Vector3
+	SlopeLimit float32 // maximum angle in radians between the up direction and a walkable surface normal
+	grounded   bool
+}
+
+// NewKinematicBody creates and returns a pointer to a new KinematicBody with the specified capsule
+// at the specified position. The default slope limit is 45 degrees.
+func NewKinematicBody(capsule *shape.Capsule, position *math32.Vector3) *KinematicBody {
+
+	kb := new(KinematicBody)
+	kb.Capsule = capsule
+	kb.Position = *position
+	kb.SlopeLimit = math32.Pi / 4
+	return kb
+}
+
+// Grounded returns if any of the contacts resolved by the last Move was on a walkable surface.
+func (kb *KinematicBody) Grounded() bool {
+
+	return kb.grounded
+}
+
+// segment returns the centers of the hemispheres of the capsule at the current position.
+func (kb *KinematicBody) segment() (a, b math32.Vector3) {
+
+	half := kb.Capsule.Height() / 2
+	a = math32.Vector3{X: kb.Position.X, Y: kb.Position.Y - half, Z: kb.Position.Z}
+	b = math32.Vector3{X: kb.Position.X, Y: kb.Position.Y + half, Z: kb.Position.Z}
+	return a, b
+}
+
+// Move sweeps the capsule along the specified displacement in steps no longer than half its radius,
+// pushing it out of the colliders it intersects and clipping the remaining displacement and the velocity
+// against the contact normals so it slides along them.
+// Returns pointer to a new vector with the actual displacement and if the body is grounded.
+func (kb *KinematicBody) Move(delta *math32.Vector3, staticColliders []Collider) (moved *math32.Vector3, grounded bool) {
+
+	start := kb.Position
+	kb.grounded = false
+	radius := kb.Capsule.Radius()
+	cosSlope := math32.Cos(kb.SlopeLimit)
+
+	steps := 1
+	if radius > 0 {
+		steps = int(math32.Ceil(delta.Length() / (radius / 2)))
+		if steps < 1 {
+			steps = 1
+		}
+	}
+	remaining := *delta
+	for s := steps; s > 0; s-- {
+		step := remaining
+		step.DivideScalar(float32(s))
+		remaining.Sub(&step)
+		kb.Position.Add(&step)
+		for iter := 0; iter < kinematicIterations; iter++ {
+			resolved := false
+			for _, c := range staticColliders {
+				a, b := kb.segment()
+				if !c.IntersectsCapsule(&a, &b, radius) {
+					continue
+				}
+				normal, depth, ok := kb.contact(c, &a, &b, &step)
+				if !ok {
+					continue
+				}
+				resolved = true
+				kb.Position.Add(normal.Clone().MultiplyScalar(depth + kinematicSkin))
+				if normal.Y > cosSlope {
+					kb.grounded = true
+				}
+				// Removes the components moving into the collider
+				slideClip(&remaining, &normal)
+				slideClip(&kb.Velocity, &normal)
+			}
+			if !resolved {
+				break
+			}
+		}
+	}
+
+	moved = math32.NewVec3().SubVectors(&kb.Position, &start)
+	return moved, kb.grounded
+}
+
+// contact returns the normal pointing out of the specified collider and the penetration depth of the capsule
+// with the specified hemisphere centers, or false if they are not penetrating.
+// The direction of the last step is used as normal when the capsule axis is inside the collider.
+func (kb *KinematicBody) contact(c Collider, a, b, step *math32.Vector3) (math32.Vector3, float32, bool) {
+
+	// Alternating projections between the capsule axis and the collider converge to their closest points
+	var axis, q math32.Vector3
+	axis.Copy(a).Lerp(b, 0.5)
+	for i := 0; i < 4; i++ {
+		q = c.ClosestPoint(&axis)
+		axis = closestOnSegment(a, b, &q)
+	}
+	radius := kb.Capsule.Radius()
+	var normal math32.Vector3
+	normal.SubVectors(&axis, &q)
+	dist := normal.Length()
+	if dist > 0 {
+		if dist >= radius {
+			return normal, 0, false
+		}
+		normal.DivideScalar(dist)
+		return normal, radius - dist, true
+	}
+	// The axis is inside the collider: pushes the capsule back along the step
+	if step.LengthSq() == 0 {
+		return normal, 0, false
+	}
+	normal.Copy(step).Negate().Normalize()
+	return normal, radius, true
+}
+
+// closestOnSegment returns the point of the segment from a to b which is closest to the point p.
+func closestOnSegment(a, b, p *math32.Vector3) math32.Vector3 {
+
+	var ab, ap math32.Vector3
+	ab.SubVectors(b, a)
+	ap.SubVectors(p, a)
+	t := float32(0)
+	if l := ab.LengthSq(); l > 0 {
+		t = math32.Clamp(ap.Dot(&ab)/l, 0, 1)
+	}
+	return *ab.MultiplyScalar(t).Add(a)
+}
+
+// slideClip removes from the specified vector its component opposite to the specified unit normal.
+func slideClip(v, normal *math32.Vector3) {
+
+	if d := v.Dot(normal); d < 0 {
+		v.Sub(normal.Clone().MultiplyScalar(d))
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// Positional correction applied by RigidBody2D.CollideWith to avoid sinking.
+const (
+	rigidBody2DCorrection = 0.8  // fraction of the penetration resolved per collision
+	rigidBody2DSlop       = 0.01 // penetration allowed without correction
+)
+
+// ContactManifold2D describes the contact between two 2D bodies.
+type ContactManifold2D struct {
+	Normal math32.Vector2   // unit normal pointing from the first body to the second
+	Depth  float32          // penetration depth along the normal
+	Points []math32.Vector2 // contact points in world coordinates
+}
+
+// RigidBody2D is a simple 2D rigid body for arcade style physics.
+// A body with zero inverse mass is static.
+type RigidBody2D struct {
+	Position        math32.Vector2
+	Velocity        math32.Vector2
+	Acceleration    math32.Vector2 // constant acceleration such as gravity
+	Angle           float32
+	AngularVelocity float32
+	Mass            float32
+	InverseMass     float32
+	Inertia         float32
+	Restitution     float32 // bounciness in [0,1] of the collisions
+	force           math32.Vector2
+	torque          float32
+}
+
+// NewRigidBody2D creates and returns a pointer to a new RigidBody2D with the specified mass and
+// rotational inertia. A zero mass creates a static body.
+func NewRigidBody2D(mass, inertia float32) *RigidBody2D {
+
+	rb := new(RigidBody2D)
+	rb.Mass = mass
+	if mass > 0 {
+		rb.InverseMass = 1 / mass
+	}
+	rb.Inertia = inertia
+	return rb
+}
+
+// inverseInertia returns the inverse of the rotational inertia of this body, zero if it cannot rotate.
+func (rb *RigidBody2D) inverseInertia() float32 {
+
+	if rb.InverseMass == 0 || rb.Inertia <= 0 {
+		return 0
+	}
+	return 1 / rb.Inertia
+}
+
+// cross2 returns the z component of the cross product of the specified 2D vectors.
+func cross2(a, b *math32.Vector2) float32 {
+
+	return a.X*b.Y - a.Y*b.X
+}
+
+// Integrate advances this body by the specified time step with semi-implicit Euler integration,
+// updating the velocities from the accumulated forces first and then the position from the new velocities.
+// The accumulated forces and torques are cleared.
+func (rb *RigidBody2D) Integrate(dt float32) {
+
+	rb.integrateVelocity(dt)
+	rb.integratePosition(dt)
+}
+
+// integrateVelocity updates the velocities of this body from its acceleration and accumulated forces
+// and clears the accumulated forces and torques.
+func (rb *RigidBody2D) integrateVelocity(dt float32) {
+
+	if rb.InverseMass != 0 {
+		rb.Velocity.X += (rb.Acceleration.X + rb.force.X*rb.InverseMass) * dt
+		rb.Velocity.Y += (rb.Acceleration.Y + rb.force.Y*rb.InverseMass) * dt
+		rb.AngularVelocity += rb.torque * rb.inverseInertia() * dt
+	}
+	rb.force.Zero()
+	rb.torque = 0
+}
+
+// integratePosition updates the position and angle of this body from its velocities.
+func (rb *RigidBody2D) integratePosition(dt float32) {
+
+	if rb.InverseMass == 0 {
+		return
+	}
+	rb.Position.X += rb.Velocity.X * dt
+	rb.Position.Y += rb.Velocity.Y * dt
+	rb.Angle += rb.AngularVelocity * dt
+}
+
+// ApplyForce accumulates the specified force applied at the specified world point until the next Integrate.
+// A nil contact point applies the force at the center of mass, without torque.
+func (rb *RigidBody2D) ApplyForce(force *math32.Vector2, contactPoint *math32.Vector2) {
+
+	rb.force.Add(force)
+	if contactPoint != nil {
+		var r math32.Vector2
+		r.SubVectors(contactPoint, &rb.Position)
+		rb.torque += cross2(&r, force)
+	}
+}
+
+// ApplyImpulse changes the velocities of this body by the specified impulse applied at the specified world point.
+// A nil contact point applies the impulse at the center of mass, without changing the angular velocity.
+func (rb *RigidBody2D) ApplyImpulse(impulse *math32.Vector2, contactPoint *math32.Vector2) {
+
+	rb.Velocity.X += impulse.X * rb.InverseMass
+	rb.Velocity.Y += impulse.Y * rb.InverseMass
+	if contactPoint != nil {
+		var r math32.Vector2
+		r.SubVectors(contactPoint, &rb.Position)
+		rb.AngularVelocity += cross2(&r, impulse) * rb.inverseInertia()
+	}
+}
+
+// pointVelocity returns the velocity of the specified world point moving with this body.
+func (rb *RigidBody2D) pointVelocity(p *math32.Vector2) math32.Vector2 {
+
+	return math32.Vector2{
+		X: rb.Velocity.X - rb.AngularVelocity*(p.Y-rb.Position.Y),
+		Y: rb.Velocity.Y + rb.AngularVelocity*(p.X-rb.Position.X),
+	}
+}
+
+// CollideWith resolves the collision of this body with the other body described by the specified manifold,
+// whose normal points from this body to the other. An impulse is applied at each contact point where the
+// bodies approach, using the smaller restitution of the two bodies, and the bodies are moved apart along
+// the normal to correct the penetration.
+// Returns true if the bodies were approaching or penetrating.
+func (rb *RigidBody2D) CollideWith(other *RigidBody2D, manifold *ContactManifold2D) bool {
+
+	invMass := rb.InverseMass + other.InverseMass
+	if invMass == 0 {
+		return false
+	}
+	n := &manifold.Normal
+	invInertiaA := rb.inverseInertia()
+	invInertiaB := other.inverseInertia()
+	restitution := math32.Min(rb.Restitution, other.Restitution)
+	resolved := false
+
+	points := manifold.Points
+	if len(points) == 0 {
+		// Without contact points the bodies collide at their centers of mass
+		mid := math32.Vector2{X: (rb.Position.X + other.Position.X) / 2, Y: (rb.Position.Y + other.Position.Y) / 2}
+		points = []math32.Vector2{mid}
+	}
+	for i := range points {
+		p := &points[i]
+		va := rb.pointVelocity(p)
+		vb := other.pointVelocity(p)
+		vn := (vb.X-va.X)*n.X + (vb.Y-va.Y)*n.Y
+		if vn >= 0 {
+			continue
+		}
+		var rA, rB math32.Vector2
+		rA.SubVectors(p, &rb.Position)
+		rB.SubVectors(p, &other.Position)
+		rna := cross2(&rA, n)
+		rnb := cross2(&rB, n)
+		j := -(1 + restitution) * vn / (invMass + rna*rna*invInertiaA + rnb*rnb*invInertiaB)
+		j /= float32(len(points))
+		impulse := math32.Vector2{X: n.X * j, Y: n.Y * j}
+		other.ApplyImpulse(&impulse, p)
+		impulse.Negate()
+		rb.ApplyImpulse(&impulse, p)
+		resolved = true
+	}
+
+	if depth := manifold.Depth - rigidBody2DSlop; depth > 0 {
+		c := depth / invMass * rigidBody2DCorrection
+		rb.Position.X -= n.X * c * rb.InverseMass
+		rb.Position.Y -= n.Y * c * rb.InverseMass
+		other.Position.X += n.X * c * other.InverseMass
+		other.Position.Y += n.Y * c * other.InverseMass
+		resolved = true
+	}
+	return resolved
+}
@@ -0,0 +1,78 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics
+
+import "github.com/g3n/engine/math32"
+
+//
+// SpringConstraint is a position based dynamics distance constraint between two particles
+// which keeps them at a rest length from each other.
+// The particles are identified by their indices into a position buffer.
+//
+type SpringConstraint struct {
+	A          int     // index of the first particle
+	B          int     // index of the second particle
+	RestLength float32 // distance at which the constraint is satisfied
+	Stiffness  float32 // fraction in [0,1] of the correction applied by each projection
+	PinnedA    bool    // the first particle is fixed
+	PinnedB    bool    // the second particle is fixed
+}
+
+// NewSpringConstraint creates and returns a pointer to a new SpringConstraint between the specified
+// particles with the specified stiffness and a rest length equal to their current distance.
+func NewSpringConstraint(positions []math32.Vector3, a, b int, stiffness float32) *SpringConstraint {
+
+	sc := new(SpringConstraint)
+	sc.A = a
+	sc.B = b
+	sc.RestLength = positions[a].DistanceTo(&positions[b])
+	sc.Stiffness = stiffness
+	return sc
+}
+
+// Satisfy projects the positions of the particles of this constraint so that their distance
+// moves towards the rest length. The correction along the segment is split equally between
+// the particles, or fully applied to the free particle if the other one is pinned.
+func (sc *SpringConstraint) Satisfy(positions []math32.Vector3) {
+
+	if sc.PinnedA && sc.PinnedB {
+		return
+	}
+	pa := &positions[sc.A]
+	pb := &positions[sc.B]
+	dx := pb.X - pa.X
+	dy := pb.Y - pa.Y
+	dz := pb.Z - pa.Z
+	length := math32.Sqrt(dx*dx + dy*dy + dz*dz)
+	if length == 0 {
+		return
+	}
+	// Correction of the distance along the unit direction from A to B
+	c := sc.Stiffness * (length - sc.RestLength) / length
+	wa := float32(0.5)
+	wb := float32(0.5)
+	if sc.PinnedA {
+		wa, wb = 0, 1
+	} else if sc.PinnedB {
+		wa, wb = 1, 0
+	}
+	pa.X += dx * c * wa
+	pa.Y += dy * c * wa
+	pa.Z += dz * c * wa
+	pb.X -= dx * c * wb
+	pb.Y -= dy * c * wb
+	pb.Z -= dz * c * wb
+}
+
+// ProjectConstraints satisfies the specified constraints in sequence the specified number of times,
+// which makes the positions converge towards a configuration satisfying all the constraints.
+func ProjectConstraints(constraints []SpringConstraint, positions []math32.Vector3, iterations int) {
+
+	for it := 0; it < iterations; it++ {
+		for i := range constraints {
+			constraints[i].Satisfy(positions)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+// Test the corrections of a constraint with free and pinned particles
+func TestSpringConstraintSatisfy(t *testing.T) {
+
+	positions := []math32.Vector3{{}, {X: 1}}
+	sc := NewSpringConstraint(positions, 0, 1, 1)
+	if sc.RestLength != 1 {
+		t.Fatal("RestLength", sc.RestLength, "instead of 1")
+	}
+
+	// Stretched to 3: both particles move by 1
+	positions[1].X = 3
+	sc.Satisfy(positions)
+	if positions[0] != (math32.Vector3{X: 1}) || positions[1] != (math32.Vector3{X: 2}) {
+		t.Error("free particles at", positions)
+	}
+
+	// Only the free particle moves
+	positions = []math32.Vector3{{}, {Y: 3}}
+	sc.PinnedA = true
+	sc.Satisfy(positions)
+	if positions[0] != (math32.Vector3{}) || positions[1] != (math32.Vector3{Y: 1}) {
+		t.Error("pinned particle A at", positions)
+	}
+
+	// Half the correction with half the stiffness
+	positions = []math32.Vector3{{}, {Z: 3}}
+	sc.PinnedA, sc.PinnedB = false, true
+	sc.Stiffness = 0.5
+	sc.Satisfy(positions)
+	if positions[0] != (math32.Vector3{Z: 1}) || positions[1] != (math32.Vector3{Z: 3}) {
+		t.Error("pinned particle B at", positions)
+	}
+}
+
+// Test that a hanging chain pinned at both ends converges to a catenary
+func TestSpringConstraintCatenary(t *testing.T) {
+
+	// Chain of length 10 between (-4, 0) and (4, 0)
+	const n = 41
+	const length = 10
+	const halfSpan = 4
+	positions := make([]math32.Vector3, n)
+	for i := range positions {
+		positions[i].X = -halfSpan + 2*halfSpan*float32(i)/(n-1)
+	}
+	var constraints []SpringConstraint
+	for i := 0; i < n-1; i++ {
+		constraints = append(constraints, SpringConstraint{A: i, B: i + 1, RestLength: float32(length) / (n - 1),
+			Stiffness: 1, PinnedA: i == 0, PinnedB: i+1 == n-1})
+	}
+
+	// Damped Verlet integration under gravity
+	previous := append([]math32.Vector3(nil), positions...)
+	const dt = 0.01
+	for step := 0; step < 4000; step++ {
+		for i := 1; i < n-1; i++ {
+			var velocity math32.Vector3
+			velocity.SubVectors(&positions[i], &previous[i]).MultiplyScalar(0.98)
+			previous[i] = positions[i]
+			positions[i].Add(&velocity)
+			positions[i].Y -= 9.8 * dt * dt
+		}
+		ProjectConstraints(constraints, positions, 50)
+	}
+
+	// Catenary y = a*cosh(x/a) - a*cosh(halfSpan/a) with the length 2*a*sinh(halfSpan/a)
+	lo, hi := 0.5, 20.0
+	for k := 0; k < 100; k++ {
+		if a := (lo + hi) / 2; 2*a*math.Sinh(halfSpan/a) > length {
+			lo = a
+		} else {
+			hi = a
+		}
+	}
+	a := (lo + hi) / 2
+	for i, p := range positions {
+		y := a*math.Cosh(float64(p.X)/a) - a*math.Cosh(halfSpan/a)
+		if math.Abs(y-float64(p.Y)) > 0.03 {
+			t.Error("particle", i, "at", p, "instead of the catenary height", y)
+		}
+	}
+	for i := range constraints {
+		c := &constraints[i]
+		if d := positions[c.A].DistanceTo(&positions[c.B]); math32.Abs(d-c.RestLength) > 1e-3 {
+			t.Error("constraint", i, "length", d, "instead of", c.RestLength)
+		}
+	}
+}
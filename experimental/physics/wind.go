@@ -0,0 +1,138 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics
+
+import (
+	"github.com/g3n/engine/math32"
+	"math"
+	"sync/atomic"
+)
+
+//
+// Wind is a spatially and temporally varying force field for vegetation and cloth.
+// The force is the base direction times the strength plus a turbulence offset
+// obtained from Perlin noise advected with the wind, and temporary gusts increase the strength.
+// After construction and configuration ForceAt and Gust may be called concurrently.
+//
+type Wind struct {
+	direction  math32.Vector3           // unit direction of the wind
+	strength   float32                  // base strength
+	noise      *math32.Perlin           // turbulence noise generator or nil
+	turbulence float32                  // turbulence amplitude relative to the strength
+	frequency  float32                  // spatial frequency of the turbulence
+	lastTime   atomic.Uint32            // bits of the latest time passed to ForceAt
+	gust       atomic.Pointer[windGust] // current gust or nil
+}
+
+// windGust is an immutable gust of a Wind, replaced as a whole when a new gust is scheduled.
+type windGust struct {
+	strength float32 // strength increase at the middle of the gust
+	start    float32 // start time
+	end      float32 // end time
+}
+
+// windField is the force field of a wind at a fixed time.
+type windField struct {
+	wind *Wind
+	time float32
+}
+
+// NewWind creates and returns a pointer to a new Wind with the specified direction and strength.
+// If noise is not nil it is used to compute the turbulence.
+func NewWind(direction *math32.Vector3, strength float32, noise *math32.Perlin) *Wind {
+
+	w := new(Wind)
+	w.direction = *direction
+	if w.direction.LengthSq() > 0 {
+		w.direction.Normalize()
+	}
+	w.strength = strength
+	w.noise = noise
+	w.turbulence = 0.5
+	w.frequency = 0.1
+	return w
+}
+
+// Direction returns the unit direction of the wind.
+func (w *Wind) Direction() math32.Vector3 {
+
+	return w.direction
+}
+
+// Strength returns the base strength of the wind.
+func (w *Wind) Strength() float32 {
+
+	return w.strength
+}
+
+// SetTurbulence sets the amplitude of the turbulence relative to the strength
+// and its spatial frequency. It must not be called concurrently with ForceAt.
+func (w *Wind) SetTurbulence(amplitude, frequency float32) {
+
+	w.turbulence = amplitude
+	w.frequency = frequency
+}
+
+// Gust schedules a gust which increases the strength of the wind by the specified amount
+// during the specified duration, starting at the latest time passed to ForceAt.
+// The increase rises and falls smoothly and replaces any gust in progress.
+func (w *Wind) Gust(strength float32, duration float32) {
+
+	start := math.Float32frombits(w.lastTime.Load())
+	w.gust.Store(&windGust{strength: strength, start: start, end: start + duration})
+}
+
+// gustAt returns the strength increase of the current gust at the specified time.
+func (w *Wind) gustAt(time float32) float32 {
+
+	g := w.gust.Load()
+	if g == nil || time < g.start || time >= g.end {
+		return 0
+	}
+	return g.strength * math32.Sin(math32.Pi*(time-g.start)/(g.end-g.start))
+}
+
+// AtTime returns the force field of this wind at the specified time, which can be added to a Simulation.
+// It must be replaced at each step of the simulation for the wind to vary with time.
+func (w *Wind) AtTime(time float32) ForceField {
+
+	return &windField{wind: w, time: time}
+}
+
+// ForceAt satisfies the ForceField interface and returns the wind force at the specified position.
+func (f *windField) ForceAt(pos *math32.Vector3) math32.Vector3 {
+
+	return f.wind.ForceAt(pos, f.time)
+}
+
+// ForceAt returns the wind force at the specified position and time.
+func (w *Wind) ForceAt(position *math32.Vector3, time float32) math32.Vector3 {
+
+	// Records the latest time to start the next gust
+	bits := math.Float32bits(time)
+	for {
+		old := w.lastTime.Load()
+		if math.Float32frombits(old) >= time || w.lastTime.CompareAndSwap(old, bits) {
+			break
+		}
+	}
+
+	strength := w.strength + w.gustAt(time)
+	force := w.direction
+	force.MultiplyScalar(strength)
+	if w.noise == nil || w.turbulence == 0 {
+		return force
+	}
+	// The turbulence pattern moves with the wind
+	amp := w.turbulence * strength
+	shift := strength * time
+	x := (position.X - w.direction.X*shift) * w.frequency
+	y := (position.Y - w.direction.Y*shift) * w.frequency
+	z := (position.Z - w.direction.Z*shift) * w.frequency
+	force.X += amp * w.noise.Noise3D(x, y, z)
+	force.Y += amp * w.noise.Noise3D(x+31.4, y+47.2, z+12.9)
+	force.Z += amp * w.noise.Noise3D(x+73.1, y+19.7, z+58.3)
+	return force
+}
@@ -0,0 +1,55 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package physics
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+// Test the strength of a gust starting at the latest evaluated time
+func TestWindGust(t *testing.T) {
+
+	w := NewWind(math32.NewVector3(2, 0, 0), 2, nil)
+	pos := math32.Vector3{X: 3, Y: 1, Z: 2}
+	if f := w.ForceAt(&pos, 1); f != (math32.Vector3{X: 2}) {
+		t.Error("force", f, "without gust instead of (2,0,0)")
+	}
+	w.Gust(3, 2)
+	cases := [][2]float32{{1, 2}, {2, 5}, {2.5, 2 + 3*math32.Sin(math32.Pi*0.75)}, {3, 2}, {3.5, 2}}
+	for _, c := range cases {
+		if f := w.ForceAt(&pos, c[0]); math32.Abs(f.X-c[1]) > 1e-5 || f.Y != 0 || f.Z != 0 {
+			t.Error("force", f, "at the time", c[0], "instead of", c[1])
+		}
+	}
+}
+
+// Test the evaluation of the wind concurrently with the replacement of the gusts
+func TestWindConcurrentGust(t *testing.T) {
+
+	w := NewWind(math32.NewVector3(1, 0, 0), 2, math32.NewPerlin(7))
+	w.SetTurbulence(0, 0.1)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			pos := math32.Vector3{X: float32(g)}
+			for i := 0; i < 2000; i++ {
+				f := w.ForceAt(&pos, float32(i)*0.01)
+				if f.X < 2 || f.X > 6 {
+					t.Error("force", f, "outside of the gust strengths")
+					return
+				}
+				if i%100 == g {
+					w.Gust(float32(1+g%4), 0.5)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,9 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package procgen implements procedural generators of terrains, dungeons, rivers and foliage
+// combining noise, curves and geometry. The generators are deterministic: they produce the same
+// results from the same seeds and random number generators.
+// WARNING: This package is experimental and incomplete!
+package procgen
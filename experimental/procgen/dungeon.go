@@ -0,0 +1,177 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package procgen
+
+import (
+	"math/rand"
+
+	"github.com/g3n/engine/experimental/navigation"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// DungeonConfig is the configuration of the dungeons generated by GenerateDungeon.
+type DungeonConfig struct {
+	Width         int        // number of cells along X
+	Depth         int        // number of cells along Z
+	CellSize      float32    // size of the square cells
+	MinRoomSize   int        // minimum size of the rooms in cells, at least 1
+	MaxDepth      int        // maximum depth of the BSP tree, which has at most 2^MaxDepth rooms
+	CorridorWidth int        // width of the corridors in cells, at least 1
+	Rng           *rand.Rand // random number generator, with a fixed seed if nil
+}
+
+// dungeonRect is a rectangle of cells.
+type dungeonRect struct {
+	x, z int // first cell
+	w, d int // number of cells along X and Z
+}
+
+// center returns the cell at the center of the rectangle.
+func (r *dungeonRect) center() (int, int) {
+
+	return r.x + r.w/2, r.z + r.d/2
+}
+
+// dungeonGrid is the floor of a dungeon being generated.
+type dungeonGrid struct {
+	config *DungeonConfig
+	rng    *rand.Rand
+	floor  []bool // floor cells, indexed by x+z*width
+}
+
+// GenerateDungeon generates a dungeon of rooms connected by corridors and returns the navigation mesh of its floor,
+// in the XZ plane from the origin. The area is split recursively in two by binary space partitioning, a room of
+// random size is placed in each leaf, and the two halves of each split are connected by an L-shaped corridor
+// between two of their rooms, so that all the rooms are reachable.
+// Panics if the configuration is invalid.
+func GenerateDungeon(config DungeonConfig) *navigation.NavMesh {
+
+	if config.MinRoomSize < 1 || config.CorridorWidth < 1 || config.CellSize <= 0 ||
+		config.Width < config.MinRoomSize+2 || config.Depth < config.MinRoomSize+2 {
+		panic("GenerateDungeon: invalid dungeon configuration")
+	}
+	g := &dungeonGrid{config: &config, rng: config.Rng}
+	if g.rng == nil {
+		g.rng = rand.New(rand.NewSource(1))
+	}
+	g.floor = make([]bool, config.Width*config.Depth)
+	g.split(dungeonRect{x: 0, z: 0, w: config.Width, d: config.Depth}, 0)
+
+	nm, err := navigation.NewNavMesh(g.geometry())
+	if err != nil {
+		// The cells form a valid surface facing up
+		panic("GenerateDungeon: " + err.Error())
+	}
+	return nm
+}
+
+// split places the rooms of the specified BSP leaf, splitting it if it is large enough and not too deep,
+// and returns the room connected to the other half of the parent split.
+func (g *dungeonGrid) split(leaf dungeonRect, depth int) dungeonRect {
+
+	// A leaf keeps a margin of one cell around its room
+	minLeaf := g.config.MinRoomSize + 2
+	canSplitX := leaf.w >= 2*minLeaf
+	canSplitZ := leaf.d >= 2*minLeaf
+	if depth >= g.config.MaxDepth || (!canSplitX && !canSplitZ) {
+		room := dungeonRect{}
+		room.w = g.config.MinRoomSize + g.rng.Intn(leaf.w-minLeaf+1)
+		room.d = g.config.MinRoomSize + g.rng.Intn(leaf.d-minLeaf+1)
+		room.x = leaf.x + 1 + g.rng.Intn(leaf.w-2-room.w+1)
+		room.z = leaf.z + 1 + g.rng.Intn(leaf.d-2-room.d+1)
+		g.carve(room)
+		return room
+	}
+
+	// Splits along the longer axis which can be split
+	a, b := leaf, leaf
+	if canSplitX && (!canSplitZ || leaf.w >= leaf.d) {
+		a.w = minLeaf + g.rng.Intn(leaf.w-2*minLeaf+1)
+		b.x += a.w
+		b.w -= a.w
+	} else {
+		a.d = minLeaf + g.rng.Intn(leaf.d-2*minLeaf+1)
+		b.z += a.d
+		b.d -= a.d
+	}
+	roomA := g.split(a, depth+1)
+	roomB := g.split(b, depth+1)
+	g.corridor(&roomA, &roomB)
+	if g.rng.Intn(2) == 0 {
+		return roomA
+	}
+	return roomB
+}
+
+// carve marks the cells of the specified rectangle, clipped to the grid, as floor.
+func (g *dungeonGrid) carve(r dungeonRect) {
+
+	for z := math32.ClampInt(r.z, 0, g.config.Depth); z < math32.ClampInt(r.z+r.d, 0, g.config.Depth); z++ {
+		for x := math32.ClampInt(r.x, 0, g.config.Width); x < math32.ClampInt(r.x+r.w, 0, g.config.Width); x++ {
+			g.floor[x+z*g.config.Width] = true
+		}
+	}
+}
+
+// corridor carves an L-shaped corridor between the centers of the specified rooms,
+// turning at the corner chosen randomly between the two possible ones.
+func (g *dungeonGrid) corridor(a, b *dungeonRect) {
+
+	ax, az := a.center()
+	bx, bz := b.center()
+	cw := g.config.CorridorWidth
+	// The corner is shared by the two segments
+	cx, cz := bx, az
+	if g.rng.Intn(2) == 0 {
+		cx, cz = ax, bz
+	}
+	for _, seg := range [2][4]int{{ax, az, cx, cz}, {cx, cz, bx, bz}} {
+		x0, x1 := seg[0], seg[2]
+		if x0 > x1 {
+			x0, x1 = x1, x0
+		}
+		z0, z1 := seg[1], seg[3]
+		if z0 > z1 {
+			z0, z1 = z1, z0
+		}
+		g.carve(dungeonRect{x: x0 - cw/2, z: z0 - cw/2, w: x1 - x0 + cw, d: z1 - z0 + cw})
+	}
+}
+
+// geometry returns the geometry of two triangles facing up for each floor cell.
+func (g *dungeonGrid) geometry() *geometry.Geometry {
+
+	width := g.config.Width
+	cs := g.config.CellSize
+	positions := math32.NewArrayF32(0, 0)
+	indices := math32.NewArrayU32(0, 0)
+	vertices := make(map[int]uint32) // index of the vertex at each grid corner x+z*(width+1)
+	vertex := func(x, z int) uint32 {
+		key := x + z*(width+1)
+		if idx, ok := vertices[key]; ok {
+			return idx
+		}
+		idx := uint32(len(positions) / 3)
+		positions.Append(float32(x)*cs, 0, float32(z)*cs)
+		vertices[key] = idx
+		return idx
+	}
+	for z := 0; z < g.config.Depth; z++ {
+		for x := 0; x < width; x++ {
+			if !g.floor[x+z*width] {
+				continue
+			}
+			v00, v10 := vertex(x, z), vertex(x+1, z)
+			v01, v11 := vertex(x, z+1), vertex(x+1, z+1)
+			indices.Append(v00, v01, v10, v10, v01, v11)
+		}
+	}
+	geom := geometry.NewGeometry()
+	geom.SetIndices(indices)
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	return geom
+}
@@ -0,0 +1,95 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package procgen
+
+import (
+	"math/rand"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/math32"
+)
+
+// foliageCandidates is the number of candidates generated around each active point
+// before it is removed from the active list (k in Bridson's algorithm).
+const foliageCandidates = 30
+
+// foliagePacking is the number of points per square of the minimum distance generated by Bridson's sampling.
+const foliagePacking = 0.68
+
+// ScatterFoliage returns the positions of foliage scattered on the surface of the specified terrain with
+// approximately the specified density, in points per unit of area, using Bridson's Poisson disk sampling
+// in the XZ plane: the points are placed randomly but no two points are closer than a minimum distance,
+// which avoids both the clumps and the regular patterns. If rng is nil a generator with a fixed seed is used.
+func ScatterFoliage(terrain *geometry.HeightMap, density float32, rng *rand.Rand) []math32.Vector3 {
+
+	if density <= 0 {
+		return nil
+	}
+	if rng == nil {
+		rng = rand.New(rand.NewSource(1))
+	}
+	sizeX, sizeZ := terrain.Size()
+	minDist := math32.Sqrt(foliagePacking / density)
+	// A cell of diagonal minDist contains at most one point
+	cellSize := minDist / math32.Sqrt(2)
+	dimX := int(math32.Ceil(sizeX/cellSize)) + 1
+	dimZ := int(math32.Ceil(sizeZ/cellSize)) + 1
+	grid := make([]int32, dimX*dimZ)
+	for i := range grid {
+		grid[i] = -1
+	}
+
+	var points []math32.Vector3
+	var active []int32
+	add := func(x, z float32) {
+		grid[int(x/cellSize)+int(z/cellSize)*dimX] = int32(len(points))
+		active = append(active, int32(len(points)))
+		points = append(points, math32.Vector3{X: x, Y: terrain.HeightAt(x, z), Z: z})
+	}
+	valid := func(x, z float32) bool {
+		if x < 0 || x > sizeX || z < 0 || z > sizeZ {
+			return false
+		}
+		cx, cz := int(x/cellSize), int(z/cellSize)
+		// Points closer than minDist can only be within two cells
+		for j := math32.ClampInt(cz-2, 0, dimZ-1); j <= math32.ClampInt(cz+2, 0, dimZ-1); j++ {
+			for i := math32.ClampInt(cx-2, 0, dimX-1); i <= math32.ClampInt(cx+2, 0, dimX-1); i++ {
+				idx := grid[i+j*dimX]
+				if idx < 0 {
+					continue
+				}
+				dx, dz := points[idx].X-x, points[idx].Z-z
+				if dx*dx+dz*dz < minDist*minDist {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	add(rng.Float32()*sizeX, rng.Float32()*sizeZ)
+	for len(active) > 0 {
+		ai := rng.Intn(len(active))
+		center := points[active[ai]]
+		found := false
+		for k := 0; k < foliageCandidates; k++ {
+			// Uniform by area in the annulus of radii minDist and 2*minDist
+			r := minDist * math32.Sqrt(1+3*rng.Float32())
+			angle := 2 * math32.Pi * rng.Float32()
+			x, z := center.X+r*math32.Cos(angle), center.Z+r*math32.Sin(angle)
+			if valid(x, z) {
+				add(x, z)
+				found = true
+				break
+			}
+		}
+		if !found {
+			last := len(active) - 1
+			active[ai] = active[last]
+			active = active[:last]
+		}
+	}
+	return points
+}
@@ -0,0 +1,122 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package procgen
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// riverSegments is the number of segments of the rivers generated by GenerateRiver.
+const riverSegments = 64
+
+// riverAmplitude is the amplitude of the initial noise of the rivers relative to their length.
+const riverAmplitude = 0.08
+
+// riverFrequency is the number of cycles of the initial noise over the length of the rivers.
+const riverFrequency = 3
+
+// riverErosionSteps is the number of steps of the migration of the banks of the rivers.
+const riverErosionSteps = 48
+
+// riverErosionRate is the fraction of its curvature offset by which a point of a river moves
+// outwards at each step, eroding the outer bank of the bend.
+const riverErosionRate = 1
+
+// riverErosionWindow is the number of points upstream over which the curvature driving the erosion is averaged.
+const riverErosionWindow = 5
+
+// riverDiffusion is the fraction of its curvature offset by which a point of a river moves back inwards
+// at each step after the erosion, damping the bends shorter than the erosion window.
+const riverDiffusion = 0.5
+
+// GenerateRiver generates and returns a pointer to a new polyline of a river flowing from the start point to
+// the end point. The straight course is perturbed in the XZ plane by the specified noise, then the bends are
+// grown as by the erosion of the outer banks, where the flow is faster, and the deposition on the inner banks:
+// each point migrates outwards in proportion to the curvature of the river averaged slightly upstream, which
+// makes the long meanders grow and drift downstream while the short bends are smoothed.
+// The height varies linearly along the river from the start to the end.
+func GenerateRiver(start, end *math32.Vector3, noise *math32.Perlin) *math32.PolyLine3 {
+
+	var dir math32.Vector3
+	dir.SubVectors(end, start)
+	dir.Y = 0
+	length := dir.Length()
+	points := make([]math32.Vector3, riverSegments+1)
+	if length == 0 {
+		for i := range points {
+			points[i].Copy(start).Lerp(end, float32(i)/riverSegments)
+		}
+		return math32.NewPolyLine3(points)
+	}
+	dir.MultiplyScalar(1 / length)
+	side := math32.Vector3{X: -dir.Z, Y: 0, Z: dir.X}
+
+	// Initial course perturbed by the noise, vanishing at the ends
+	for i := range points {
+		t := float32(i) / riverSegments
+		offset := noise.Noise3D(t*riverFrequency+0.5, 0.5, 0.5) * riverAmplitude * length * math32.Sin(math32.Pi*t)
+		points[i].Copy(start).Lerp(end, t)
+		points[i].X += side.X * offset
+		points[i].Z += side.Z * offset
+	}
+
+	// Migration of the banks, limited to a fraction of the segment length per step for stability
+	maxStep := 0.25 * length / riverSegments
+	curvature := make([]math32.Vector3, len(points))
+	for step := 0; step < riverErosionSteps; step++ {
+		riverCurvature(points, curvature)
+		for i := 1; i < len(points)-1; i++ {
+			// The curvature averaged upstream drives the erosion, so that the bends drift downstream
+			// and the bends shorter than the window are damped by the diffusion
+			var move math32.Vector3
+			first := math32.ClampInt(i-riverErosionWindow+1, 1, i)
+			for j := first; j <= i; j++ {
+				move.Add(&curvature[j])
+			}
+			move.MultiplyScalar(riverErosionRate / float32(i-first+1))
+			if l := move.Length(); l > maxStep {
+				move.MultiplyScalar(maxStep / l)
+			}
+			points[i].Add(&move)
+		}
+		riverCurvature(points, curvature)
+		for i := 1; i < len(points)-1; i++ {
+			var move math32.Vector3
+			move.Copy(&curvature[i]).MultiplyScalar(-riverDiffusion)
+			points[i].Add(&move)
+		}
+	}
+
+	// Height decreasing along the course
+	var total float32
+	for i := 1; i < len(points); i++ {
+		total += riverDistanceXZ(&points[i], &points[i-1])
+	}
+	var dist float32
+	for i := range points {
+		if i > 0 {
+			dist += riverDistanceXZ(&points[i], &points[i-1])
+		}
+		points[i].Y = start.Y + (end.Y-start.Y)*dist/total
+	}
+	return math32.NewPolyLine3(points)
+}
+
+// riverCurvature computes the offset in the XZ plane of each interior point of the river from the midpoint of its
+// neighbors, which points outwards from the bend with a length proportional to its curvature.
+func riverCurvature(points, curvature []math32.Vector3) {
+
+	for i := 1; i < len(points)-1; i++ {
+		curvature[i].X = points[i].X - (points[i-1].X+points[i+1].X)/2
+		curvature[i].Y = 0
+		curvature[i].Z = points[i].Z - (points[i-1].Z+points[i+1].Z)/2
+	}
+}
+
+// riverDistanceXZ returns the distance between the specified points in the XZ plane.
+func riverDistanceXZ(a, b *math32.Vector3) float32 {
+
+	return math32.Sqrt((a.X-b.X)*(a.X-b.X) + (a.Z-b.Z)*(a.Z-b.Z))
+}
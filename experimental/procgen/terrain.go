@@ -0,0 +1,80 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package procgen
+
+import (
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/math32"
+)
+
+// TerrainConfig is the configuration of the terrains generated by GenerateTerrain.
+type TerrainConfig struct {
+	Width       int     // number of height samples along X, at least 2
+	Depth       int     // number of height samples along Z, at least 2
+	CellSize    float32 // distance between the samples
+	HeightScale float32 // height of the highest possible terrain
+	Seed        int64   // seed of the Perlin noise
+	Frequency   float32 // frequency of the first octave, in cycles per unit of distance
+	Octaves     int     // number of octaves of noise, at least 1
+	Lacunarity  float32 // factor of the frequency of each octave, usually 2
+	Persistence float32 // factor of the amplitude of each octave, usually 0.5
+}
+
+// NewTerrainConfig creates and returns a pointer to a new TerrainConfig with the specified number of
+// samples along X and Z and cell size, a height scale of 1 and 6 octaves of noise whose first octave
+// has a wavelength of the quarter of the terrain width.
+func NewTerrainConfig(width, depth int, cellSize float32) *TerrainConfig {
+
+	return &TerrainConfig{
+		Width:       width,
+		Depth:       depth,
+		CellSize:    cellSize,
+		HeightScale: 1,
+		Seed:        1,
+		Frequency:   4 / (float32(width-1) * cellSize),
+		Octaves:     6,
+		Lacunarity:  2,
+		Persistence: 0.5,
+	}
+}
+
+// fbm returns the fractional Brownian motion of the specified Perlin noise at the specified point:
+// the sum of the octaves of noise of increasing frequency and decreasing amplitude,
+// divided by the sum of their amplitudes so that it is approximately in the range [-1,1].
+func fbm(noise *math32.Perlin, x, y, z float32, octaves int, lacunarity, persistence float32) float32 {
+
+	var amplitudes float32
+	amplitude := float32(1)
+	for o := 0; o < octaves; o++ {
+		amplitudes += amplitude
+		amplitude *= persistence
+	}
+	if amplitudes == 0 {
+		return 0
+	}
+	return math32.PerlinOctaveNoise(noise, x, y, z, 1, 1, octaves, lacunarity, persistence) / amplitudes
+}
+
+// GenerateTerrain generates and returns a pointer to a new height map with the fractional Brownian motion
+// of Perlin noise seeded by the configuration. The heights are in the range [0,1], scaled by the height scale.
+func GenerateTerrain(config TerrainConfig) *geometry.HeightMap {
+
+	noise := math32.NewPerlin(config.Seed)
+	octaves := config.Octaves
+	if octaves < 1 {
+		octaves = 1
+	}
+	data := make([]float32, config.Width*config.Depth)
+	for j := 0; j < config.Depth; j++ {
+		for i := 0; i < config.Width; i++ {
+			// The noise is zero at the lattice points: samples the plane between them
+			x := float32(i) * config.CellSize * config.Frequency
+			z := float32(j) * config.CellSize * config.Frequency
+			h := fbm(noise, x, 0.5, z, octaves, config.Lacunarity, config.Persistence)
+			data[i+j*config.Width] = math32.Clamp((h+1)/2, 0, 1)
+		}
+	}
+	return geometry.NewHeightMap(data, config.Width, config.Depth, config.CellSize, config.HeightScale)
+}
@@ -0,0 +1,262 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package save
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/g3n/engine/math32"
+)
+
+// BinaryWriter writes little-endian binary values to a writer.
+// The first error stops the writing and is returned by Err, so that
+// the errors do not need to be checked after each value.
+type BinaryWriter struct {
+	w   io.Writer
+	err error
+}
+
+// NewBinaryWriter creates and returns a pointer to a new BinaryWriter writing to the specified writer.
+func NewBinaryWriter(w io.Writer) *BinaryWriter {
+
+	return &BinaryWriter{w: w}
+}
+
+// Err returns the first error which occurred while writing, or nil.
+func (bw *BinaryWriter) Err() error {
+
+	return bw.err
+}
+
+// write writes the specified fixed size value if no error occurred.
+func (bw *BinaryWriter) write(v interface{}) {
+
+	if bw.err == nil {
+		bw.err = binary.Write(bw.w, binary.LittleEndian, v)
+	}
+}
+
+// WriteUint32 writes the specified uint32 value.
+func (bw *BinaryWriter) WriteUint32(v uint32) {
+
+	bw.write(v)
+}
+
+// WriteInt32 writes the specified int32 value.
+func (bw *BinaryWriter) WriteInt32(v int32) {
+
+	bw.write(v)
+}
+
+// WriteFloat32 writes the specified float32 value.
+func (bw *BinaryWriter) WriteFloat32(v float32) {
+
+	bw.write(v)
+}
+
+// WriteBool writes the specified bool value as a byte.
+func (bw *BinaryWriter) WriteBool(v bool) {
+
+	bw.write(v)
+}
+
+// WriteBytes writes the length of the specified data as an uint32 followed by the data.
+func (bw *BinaryWriter) WriteBytes(data []byte) {
+
+	bw.WriteUint32(uint32(len(data)))
+	if bw.err == nil {
+		_, bw.err = bw.w.Write(data)
+	}
+}
+
+// WriteString writes the length of the specified string as an uint32 followed by its bytes.
+func (bw *BinaryWriter) WriteString(s string) {
+
+	bw.WriteBytes([]byte(s))
+}
+
+// WriteFloat32s writes the length of the specified slice as an uint32 followed by its values.
+func (bw *BinaryWriter) WriteFloat32s(values []float32) {
+
+	bw.WriteUint32(uint32(len(values)))
+	bw.write(values)
+}
+
+// WriteUint32s writes the length of the specified slice as an uint32 followed by its values.
+func (bw *BinaryWriter) WriteUint32s(values []uint32) {
+
+	bw.WriteUint32(uint32(len(values)))
+	bw.write(values)
+}
+
+// WriteVector3 writes the specified vector encoded by its MarshalBinary.
+func (bw *BinaryWriter) WriteVector3(v *math32.Vector3) {
+
+	data, _ := v.MarshalBinary()
+	bw.write(data)
+}
+
+// WriteMatrix4 writes the specified matrix encoded by its MarshalBinary.
+func (bw *BinaryWriter) WriteMatrix4(m *math32.Matrix4) {
+
+	data, _ := m.MarshalBinary()
+	bw.write(data)
+}
+
+// WriteQuaternion writes the specified quaternion encoded by its MarshalBinary.
+func (bw *BinaryWriter) WriteQuaternion(q *math32.Quaternion) {
+
+	data, _ := q.MarshalBinary()
+	bw.write(data)
+}
+
+// BinaryReader reads the little-endian binary values written by a BinaryWriter from a reader.
+// The first error stops the reading and is returned by Err; the values read after it are zero.
+type BinaryReader struct {
+	r   io.Reader
+	err error
+}
+
+// NewBinaryReader creates and returns a pointer to a new BinaryReader reading from the specified reader.
+func NewBinaryReader(r io.Reader) *BinaryReader {
+
+	return &BinaryReader{r: r}
+}
+
+// Err returns the first error which occurred while reading, or nil.
+func (br *BinaryReader) Err() error {
+
+	return br.err
+}
+
+// read reads the specified fixed size value if no error occurred.
+func (br *BinaryReader) read(v interface{}) {
+
+	if br.err == nil {
+		br.err = binary.Read(br.r, binary.LittleEndian, v)
+	}
+}
+
+// ReadUint32 reads and returns an uint32 value.
+func (br *BinaryReader) ReadUint32() uint32 {
+
+	var v uint32
+	br.read(&v)
+	return v
+}
+
+// ReadInt32 reads and returns an int32 value.
+func (br *BinaryReader) ReadInt32() int32 {
+
+	var v int32
+	br.read(&v)
+	return v
+}
+
+// ReadFloat32 reads and returns a float32 value.
+func (br *BinaryReader) ReadFloat32() float32 {
+
+	var v float32
+	br.read(&v)
+	return v
+}
+
+// ReadBool reads and returns a bool value.
+func (br *BinaryReader) ReadBool() bool {
+
+	var v bool
+	br.read(&v)
+	return v
+}
+
+// ReadBytes reads and returns the data written by WriteBytes.
+func (br *BinaryReader) ReadBytes() []byte {
+
+	return br.readSlice(1)
+}
+
+// readSlice reads an uint32 length followed by this number of values of the specified size,
+// and returns their bytes.
+func (br *BinaryReader) readSlice(size int) []byte {
+
+	n := int64(br.ReadUint32()) * int64(size)
+	if br.err != nil {
+		return nil
+	}
+	// Copies instead of allocating the length read, which may be corrupted
+	var buf bytes.Buffer
+	copied, err := io.CopyN(&buf, br.r, n)
+	if err != nil {
+		br.err = fmt.Errorf("invalid data length:%d read:%d", n, copied)
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// ReadFloat32s reads and returns the values written by WriteFloat32s.
+func (br *BinaryReader) ReadFloat32s() []float32 {
+
+	data := br.readSlice(4)
+	if data == nil {
+		return nil
+	}
+	values := make([]float32, len(data)/4)
+	for i := range values {
+		values[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return values
+}
+
+// ReadUint32s reads and returns the values written by WriteUint32s.
+func (br *BinaryReader) ReadUint32s() []uint32 {
+
+	data := br.readSlice(4)
+	if data == nil {
+		return nil
+	}
+	values := make([]uint32, len(data)/4)
+	for i := range values {
+		values[i] = binary.LittleEndian.Uint32(data[4*i:])
+	}
+	return values
+}
+
+// ReadString reads and returns the string written by WriteString.
+func (br *BinaryReader) ReadString() string {
+
+	return string(br.ReadBytes())
+}
+
+// readMarshaled reads size bytes decoded by the specified UnmarshalBinary.
+func (br *BinaryReader) readMarshaled(size int, unmarshal func([]byte) error) {
+
+	data := make([]byte, size)
+	br.read(data)
+	if br.err == nil {
+		br.err = unmarshal(data)
+	}
+}
+
+// ReadVector3 reads a vector written by WriteVector3 into the specified vector.
+func (br *BinaryReader) ReadVector3(v *math32.Vector3) {
+
+	br.readMarshaled(12, v.UnmarshalBinary)
+}
+
+// ReadMatrix4 reads a matrix written by WriteMatrix4 into the specified matrix.
+func (br *BinaryReader) ReadMatrix4(m *math32.Matrix4) {
+
+	br.readMarshaled(64, m.UnmarshalBinary)
+}
+
+// ReadQuaternion reads a quaternion written by WriteQuaternion into the specified quaternion.
+func (br *BinaryReader) ReadQuaternion(q *math32.Quaternion) {
+
+	br.readMarshaled(16, q.UnmarshalBinary)
+}
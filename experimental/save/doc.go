@@ -0,0 +1,8 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package save implements the versioned binary serialization of the state of games and of scene graphs,
+// with migrations to load the data saved by previous versions.
+// WARNING: This package is experimental and incomplete!
+package save
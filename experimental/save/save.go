@@ -0,0 +1,121 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package save
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// migration converts the data of a version, read from the reader, to the data
+// of a later version, written to the writer.
+type migration struct {
+	toVersion uint32
+	fn        func(*BinaryReader, *BinaryWriter) error
+}
+
+// SaveSystem saves the state of a game as binary data preceded by an uint32 version header.
+// The data saved by previous versions is converted to the current version by the chain
+// of registered migrations before being loaded.
+type SaveSystem struct {
+	version    uint32
+	save       func(w *BinaryWriter) error
+	load       func(r *BinaryReader) error
+	migrations map[uint32]migration // indexed by the version they convert from
+}
+
+// NewSaveSystem creates and returns a pointer to a new SaveSystem saving with the specified
+// function the data of the specified current version, and loading it with the specified function.
+func NewSaveSystem(version uint32, save func(w *BinaryWriter) error, load func(r *BinaryReader) error) *SaveSystem {
+
+	s := new(SaveSystem)
+	s.version = version
+	s.save = save
+	s.load = load
+	s.migrations = make(map[uint32]migration)
+	return s
+}
+
+// Version returns the current version of the data saved by this system.
+func (s *SaveSystem) Version() uint32 {
+
+	return s.version
+}
+
+// RegisterMigration registers the function which converts the data of fromVersion to toVersion,
+// replacing any migration previously registered from fromVersion.
+// The toVersion must be later than fromVersion and not later than the current version.
+func (s *SaveSystem) RegisterMigration(fromVersion, toVersion uint32, fn func(*BinaryReader, *BinaryWriter) error) {
+
+	if toVersion <= fromVersion || toVersion > s.version {
+		panic(fmt.Sprintf("RegisterMigration: invalid migration from %d to %d", fromVersion, toVersion))
+	}
+	s.migrations[fromVersion] = migration{toVersion: toVersion, fn: fn}
+}
+
+// Save writes the version header and the data of the current version to the specified writer.
+func (s *SaveSystem) Save(w io.Writer) error {
+
+	bw := NewBinaryWriter(w)
+	bw.WriteUint32(s.version)
+	if err := s.save(bw); err != nil {
+		return err
+	}
+	return bw.Err()
+}
+
+// Load reads the version header and the data from the specified reader, converts the data of
+// a previous version to the current version by applying the registered migrations in order,
+// and loads it. Returns an error if the version is later than the current version
+// or if no migration is registered from one of the versions.
+func (s *SaveSystem) Load(r io.Reader) error {
+
+	br := NewBinaryReader(r)
+	version := br.ReadUint32()
+	if err := br.Err(); err != nil {
+		return err
+	}
+	if version > s.version {
+		return fmt.Errorf("invalid version:%d", version)
+	}
+	if version == s.version {
+		if err := s.load(br); err != nil {
+			return err
+		}
+		return br.Err()
+	}
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	for version < s.version {
+		m, ok := s.migrations[version]
+		if !ok {
+			return fmt.Errorf("no migration from version:%d", version)
+		}
+		mr := NewBinaryReader(bytes.NewReader(data))
+		var buf bytes.Buffer
+		mw := NewBinaryWriter(&buf)
+		if err := m.fn(mr, mw); err != nil {
+			return err
+		}
+		if err := mr.Err(); err != nil {
+			return err
+		}
+		if err := mw.Err(); err != nil {
+			return err
+		}
+		data = buf.Bytes()
+		version = m.toVersion
+	}
+	br = NewBinaryReader(bytes.NewReader(data))
+	if err := s.load(br); err != nil {
+		return err
+	}
+	return br.Err()
+}
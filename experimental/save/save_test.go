@@ -0,0 +1,149 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package save
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+// saveTestState is the game state of the save tests. The version 1 has no rotation and no score.
+type saveTestState struct {
+	pos   math32.Vector3
+	rot   math32.Quaternion
+	name  string
+	m     math32.Matrix4
+	score int32
+}
+
+// saveVersion1 returns the data of the specified state saved at the version 1: position, name and matrix.
+func saveVersion1(t *testing.T, state *saveTestState) []byte {
+
+	t.Helper()
+	s := NewSaveSystem(1, func(w *BinaryWriter) error {
+		w.WriteVector3(&state.pos)
+		w.WriteString(state.name)
+		w.WriteMatrix4(&state.m)
+		return nil
+	}, nil)
+	var buf bytes.Buffer
+	if err := s.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// migrate1to2 converts the data of the version 1 to the version 2, which adds the rotation after the position.
+func migrate1to2(r *BinaryReader, w *BinaryWriter) error {
+
+	var pos math32.Vector3
+	var m math32.Matrix4
+	r.ReadVector3(&pos)
+	w.WriteVector3(&pos)
+	w.WriteQuaternion(&math32.Quaternion{W: 1})
+	w.WriteString(r.ReadString())
+	r.ReadMatrix4(&m)
+	w.WriteMatrix4(&m)
+	return nil
+}
+
+// Test that the data saved at the version 1 is loaded by the version 2 after the migration
+func TestSaveSystemMigration(t *testing.T) {
+
+	saved := saveTestState{pos: math32.Vector3{X: 1, Y: 2, Z: 3}, name: "hero"}
+	saved.m.MakeTranslation(4, 5, 6)
+	data := saveVersion1(t, &saved)
+
+	var loaded saveTestState
+	load2 := func(r *BinaryReader) error {
+		r.ReadVector3(&loaded.pos)
+		r.ReadQuaternion(&loaded.rot)
+		loaded.name = r.ReadString()
+		r.ReadMatrix4(&loaded.m)
+		return nil
+	}
+	s2 := NewSaveSystem(2, nil, load2)
+	if err := s2.Load(bytes.NewReader(data)); err == nil {
+		t.Error("no error without migration from the version 1")
+	}
+	s2.RegisterMigration(1, 2, migrate1to2)
+	if err := s2.Load(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	expected := saved
+	expected.rot.W = 1
+	if loaded != expected {
+		t.Error("loaded", loaded, "instead of", expected)
+	}
+
+	// Chain of migrations to the version 3, which adds the score at the end
+	loaded = saveTestState{}
+	s3 := NewSaveSystem(3, nil, func(r *BinaryReader) error {
+		load2(r)
+		loaded.score = r.ReadInt32()
+		return nil
+	})
+	s3.RegisterMigration(1, 2, migrate1to2)
+	s3.RegisterMigration(2, 3, func(r *BinaryReader, w *BinaryWriter) error {
+		w.WriteBytes(nil)
+		return nil
+	})
+	if err := s3.Load(bytes.NewReader(data)); err == nil {
+		t.Error("no error for a migration writing invalid data")
+	}
+	s3.RegisterMigration(2, 3, func(r *BinaryReader, w *BinaryWriter) error {
+		var pos math32.Vector3
+		var rot math32.Quaternion
+		var m math32.Matrix4
+		r.ReadVector3(&pos)
+		r.ReadQuaternion(&rot)
+		name := r.ReadString()
+		r.ReadMatrix4(&m)
+		w.WriteVector3(&pos)
+		w.WriteQuaternion(&rot)
+		w.WriteString(name)
+		w.WriteMatrix4(&m)
+		w.WriteInt32(100)
+		return nil
+	})
+	if err := s3.Load(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	expected.score = 100
+	if loaded != expected {
+		t.Error("loaded", loaded, "instead of", expected)
+	}
+}
+
+// Test the errors of the invalid versions and the truncated data
+func TestSaveSystemErrors(t *testing.T) {
+
+	s := NewSaveSystem(1, nil, func(r *BinaryReader) error {
+		r.ReadString()
+		return nil
+	})
+	if err := s.Load(bytes.NewReader([]byte{5, 0, 0, 0})); err == nil {
+		t.Error("no error for a later version")
+	}
+	if err := s.Load(bytes.NewReader([]byte{1, 0})); err == nil {
+		t.Error("no error for a truncated header")
+	}
+	if err := s.Load(bytes.NewReader([]byte{1, 0, 0, 0, 10, 0, 0, 0, 'a'})); err == nil {
+		t.Error("no error for truncated data")
+	}
+	br := NewBinaryReader(bytes.NewReader([]byte{255, 255, 255, 255, 1}))
+	if br.ReadBytes() != nil || br.Err() == nil {
+		t.Error("no error for an invalid length")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic for a migration to a previous version")
+		}
+	}()
+	s.RegisterMigration(1, 0, migrate1to2)
+}
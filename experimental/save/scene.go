@@ -0,0 +1,803 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package save
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+
+	"github.com/g3n/engine/animation"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// sceneMagic identifies the data written by WriteScene.
+const sceneMagic = "G3NS"
+
+// sceneVersion is the version of the scene format written by WriteScene.
+const sceneVersion = 1
+
+// Kinds of the serialized nodes.
+const (
+	nodePlain = iota
+	nodeMesh
+	nodeLines
+	nodeLineStrip
+	nodePoints
+)
+
+// Kinds of the serialized materials.
+const (
+	materialBasic = iota
+	materialStandard
+	materialPhong
+	materialPoint
+	materialPhysical
+)
+
+// Kinds of the serialized texture data.
+const (
+	textureBytes = iota
+	textureFloats
+)
+
+// Kinds of the serialized animation channels.
+const (
+	channelPosition = iota
+	channelRotation
+	channelScale
+)
+
+// noRef is the reference written for a missing optional object.
+const noRef = -1
+
+// SceneSerializer writes and reads entire scene graphs with their meshes, materials, textures and animations.
+// The geometries, materials and textures shared by several nodes are written once, when first referenced,
+// and referenced by an integer ID afterwards. The data is preceded by its SHA-256 hash, which is checked
+// when it is read to detect corruption.
+// The graphics other than meshes, lines, line strips and points, such as cameras, are written as plain
+// nodes with their transform and children. The skeletons of rigged meshes and the morph targets of geometries
+// are not written.
+type SceneSerializer struct {
+	Animations []*animation.Animation // animations of the nodes of the scene, written and read with it
+}
+
+// NewSceneSerializer creates and returns a pointer to a new SceneSerializer without animations.
+func NewSceneSerializer() *SceneSerializer {
+
+	return new(SceneSerializer)
+}
+
+// sceneWriter keeps the IDs of the objects already written by WriteScene.
+type sceneWriter struct {
+	w          *BinaryWriter
+	nodes      map[core.INode]int
+	geometries map[*geometry.Geometry]int
+	materials  map[material.IMaterial]int
+	textures   map[*texture.Texture2D]int
+}
+
+// sceneReader keeps the objects already read by ReadScene, indexed by their IDs.
+type sceneReader struct {
+	r          *BinaryReader
+	nodes      []core.INode
+	geometries []*geometry.Geometry
+	materials  []material.IMaterial
+	textures   []*texture.Texture2D
+}
+
+// WriteScene writes the scene graph with the specified root node, depth-first, followed by the animations
+// of this serializer, to the specified writer.
+// Returns an error if a material, a texture or an animation channel is of an unsupported type,
+// if a texture has no data in memory or if an animation channel targets a node outside the scene.
+func (s *SceneSerializer) WriteScene(w io.Writer, root core.INode) error {
+
+	var payload bytes.Buffer
+	sw := &sceneWriter{
+		w:          NewBinaryWriter(&payload),
+		nodes:      make(map[core.INode]int),
+		geometries: make(map[*geometry.Geometry]int),
+		materials:  make(map[material.IMaterial]int),
+		textures:   make(map[*texture.Texture2D]int),
+	}
+	if err := sw.writeNode(root); err != nil {
+		return err
+	}
+	sw.w.WriteUint32(uint32(len(s.Animations)))
+	for _, anim := range s.Animations {
+		if err := sw.writeAnimation(anim); err != nil {
+			return err
+		}
+	}
+	if err := sw.w.Err(); err != nil {
+		return err
+	}
+
+	hash := sha256.Sum256(payload.Bytes())
+	bw := NewBinaryWriter(w)
+	bw.write([]byte(sceneMagic))
+	bw.WriteUint32(sceneVersion)
+	bw.write(hash[:])
+	bw.WriteBytes(payload.Bytes())
+	return bw.Err()
+}
+
+// ReadScene reads a scene graph written by WriteScene from the specified reader and returns its root node.
+// The animations read with it replace the animations of this serializer.
+// Returns an error if the data is not a scene, if its version is not supported, or if it is corrupted.
+func (s *SceneSerializer) ReadScene(r io.Reader) (core.INode, error) {
+
+	br := NewBinaryReader(r)
+	var magic [len(sceneMagic)]byte
+	br.read(magic[:])
+	version := br.ReadUint32()
+	var hash [sha256.Size]byte
+	br.read(hash[:])
+	payload := br.ReadBytes()
+	if err := br.Err(); err != nil {
+		return nil, err
+	}
+	if string(magic[:]) != sceneMagic {
+		return nil, fmt.Errorf("invalid scene header")
+	}
+	if version != sceneVersion {
+		return nil, fmt.Errorf("invalid scene version:%d", version)
+	}
+	if sha256.Sum256(payload) != hash {
+		return nil, fmt.Errorf("invalid scene hash: the data is corrupted")
+	}
+
+	sr := &sceneReader{r: NewBinaryReader(bytes.NewReader(payload))}
+	root, err := sr.readNode()
+	if err != nil {
+		return nil, err
+	}
+	count := sr.r.ReadUint32()
+	var anims []*animation.Animation
+	for i := uint32(0); i < count && sr.r.Err() == nil; i++ {
+		anim, err := sr.readAnimation()
+		if err != nil {
+			return nil, err
+		}
+		anims = append(anims, anim)
+	}
+	if err := sr.r.Err(); err != nil {
+		return nil, err
+	}
+	s.Animations = anims
+	return root, nil
+}
+
+// writeNode writes the specified node followed by its children.
+func (sw *sceneWriter) writeNode(inode core.INode) error {
+
+	sw.nodes[inode] = len(sw.nodes)
+	kind := nodePlain
+	switch inode.(type) {
+	case *graphic.Mesh:
+		kind = nodeMesh
+	case *graphic.Lines:
+		kind = nodeLines
+	case *graphic.LineStrip:
+		kind = nodeLineStrip
+	case *graphic.Points:
+		kind = nodePoints
+	}
+	w := sw.w
+	w.WriteUint32(uint32(kind))
+
+	node := inode.GetNode()
+	w.WriteString(node.Name())
+	w.WriteString(node.LoaderID())
+	w.WriteBool(node.Visible())
+	pos := node.Position()
+	w.WriteVector3(&pos)
+	quat := node.Quaternion()
+	w.WriteQuaternion(&quat)
+	scale := node.Scale()
+	w.WriteVector3(&scale)
+
+	if kind != nodePlain {
+		gr := inode.(graphic.IGraphic).GetGraphic()
+		sw.writeGeometry(gr.GetGeometry())
+		w.WriteBool(gr.Renderable())
+		w.WriteBool(gr.Cullable())
+		w.WriteInt32(int32(gr.RenderOrder()))
+		materials := gr.Materials()
+		w.WriteUint32(uint32(len(materials)))
+		for i := range materials {
+			if err := sw.writeMaterial(materials[i].IMaterial()); err != nil {
+				return err
+			}
+			start, count := materials[i].Range()
+			w.WriteInt32(int32(start))
+			w.WriteInt32(int32(count))
+		}
+	}
+
+	children := node.Children()
+	w.WriteUint32(uint32(len(children)))
+	for _, child := range children {
+		if err := sw.writeNode(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readNode reads a node written by writeNode with its children.
+func (sr *sceneReader) readNode() (core.INode, error) {
+
+	r := sr.r
+	kind := r.ReadUint32()
+	name := r.ReadString()
+	loaderID := r.ReadString()
+	visible := r.ReadBool()
+	var pos, scale math32.Vector3
+	var quat math32.Quaternion
+	r.ReadVector3(&pos)
+	r.ReadQuaternion(&quat)
+	r.ReadVector3(&scale)
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+
+	var inode core.INode
+	if kind == nodePlain {
+		inode = core.NewNode()
+	} else {
+		geom, err := sr.readGeometry()
+		if err != nil {
+			return nil, err
+		}
+		var igr graphic.IGraphic
+		switch kind {
+		case nodeMesh:
+			igr = graphic.NewMesh(geom, nil)
+		case nodeLines:
+			igr = graphic.NewLines(geom, nil)
+		case nodeLineStrip:
+			igr = graphic.NewLineStrip(geom, nil)
+		case nodePoints:
+			igr = graphic.NewPoints(geom, nil)
+		default:
+			return nil, fmt.Errorf("invalid node kind:%d", kind)
+		}
+		gr := igr.GetGraphic()
+		gr.SetRenderable(r.ReadBool())
+		gr.SetCullable(r.ReadBool())
+		gr.SetRenderOrder(int(r.ReadInt32()))
+		gr.ClearMaterials()
+		count := r.ReadUint32()
+		for i := uint32(0); i < count && r.Err() == nil; i++ {
+			imat, err := sr.readMaterial()
+			if err != nil {
+				return nil, err
+			}
+			start := r.ReadInt32()
+			count := r.ReadInt32()
+			gr.AddMaterial(igr, imat, int(start), int(count))
+		}
+		inode = igr
+	}
+	sr.nodes = append(sr.nodes, inode)
+
+	node := inode.GetNode()
+	node.SetName(name)
+	node.SetLoaderID(loaderID)
+	node.SetVisible(visible)
+	node.SetPositionVec(&pos)
+	node.SetQuaternionQuat(&quat)
+	node.SetScaleVec(&scale)
+
+	count := r.ReadUint32()
+	for i := uint32(0); i < count && r.Err() == nil; i++ {
+		child, err := sr.readNode()
+		if err != nil {
+			return nil, err
+		}
+		node.Add(child)
+	}
+	return inode, r.Err()
+}
+
+// readRef reads the ID of an object, given the number of objects already read.
+// Returns whether the object is new and must be read after its ID.
+func (sr *sceneReader) readRef(count int) (int, bool, error) {
+
+	id := int(sr.r.ReadInt32())
+	if err := sr.r.Err(); err != nil {
+		return 0, false, err
+	}
+	if id < noRef || id > count {
+		return 0, false, fmt.Errorf("invalid reference:%d", id)
+	}
+	return id, id == count, nil
+}
+
+// writeGeometry writes the reference to the specified geometry, followed by its vertex buffers,
+// indices and groups if it was not written before.
+func (sw *sceneWriter) writeGeometry(geom *geometry.Geometry) {
+
+	w := sw.w
+	if id, ok := sw.geometries[geom]; ok {
+		w.WriteInt32(int32(id))
+		return
+	}
+	sw.geometries[geom] = len(sw.geometries)
+	w.WriteInt32(int32(sw.geometries[geom]))
+	vbos := geom.VBOs()
+	w.WriteUint32(uint32(len(vbos)))
+	for _, vbo := range vbos {
+		w.WriteFloat32s(*vbo.Buffer())
+		attribs := vbo.Attributes()
+		w.WriteUint32(uint32(len(attribs)))
+		for _, attrib := range attribs {
+			w.WriteInt32(int32(attrib.Type))
+			w.WriteString(attrib.Name)
+			w.WriteUint32(attrib.ByteOffset)
+			w.WriteInt32(attrib.NumElements)
+			w.WriteUint32(attrib.ElementType)
+		}
+	}
+	w.WriteUint32s(geom.Indices())
+	w.WriteUint32(uint32(geom.GroupCount()))
+	for i := 0; i < geom.GroupCount(); i++ {
+		group := geom.GroupAt(i)
+		w.WriteInt32(int32(group.Start))
+		w.WriteInt32(int32(group.Count))
+		w.WriteInt32(int32(group.Matindex))
+		w.WriteString(group.Matid)
+	}
+}
+
+// readGeometry reads a geometry written by writeGeometry, or returns the geometry already read
+// with its reference count incremented.
+func (sr *sceneReader) readGeometry() (*geometry.Geometry, error) {
+
+	id, isNew, err := sr.readRef(len(sr.geometries))
+	if err != nil {
+		return nil, err
+	}
+	if !isNew {
+		if id == noRef {
+			return nil, fmt.Errorf("invalid geometry reference:%d", id)
+		}
+		return sr.geometries[id].Incref(), nil
+	}
+	r := sr.r
+	geom := geometry.NewGeometry()
+	count := r.ReadUint32()
+	for i := uint32(0); i < count && r.Err() == nil; i++ {
+		vbo := gls.NewVBO(r.ReadFloat32s())
+		attribs := r.ReadUint32()
+		for j := uint32(0); j < attribs && r.Err() == nil; j++ {
+			attrib := gls.VBOattrib{
+				Type:        gls.AttribType(r.ReadInt32()),
+				Name:        r.ReadString(),
+				ByteOffset:  r.ReadUint32(),
+				NumElements: r.ReadInt32(),
+				ElementType: r.ReadUint32(),
+			}
+			vbo.AddCustomAttribOffset(attrib.Name, attrib.NumElements, attrib.ByteOffset)
+			*vbo.AttribAt(int(j)) = attrib
+		}
+		geom.AddVBO(vbo)
+	}
+	if indices := r.ReadUint32s(); len(indices) > 0 {
+		geom.SetIndices(indices)
+	}
+	count = r.ReadUint32()
+	for i := uint32(0); i < count && r.Err() == nil; i++ {
+		start := r.ReadInt32()
+		n := r.ReadInt32()
+		matIndex := r.ReadInt32()
+		geom.AddGroup(int(start), int(n), int(matIndex)).Matid = r.ReadString()
+	}
+	sr.geometries = append(sr.geometries, geom)
+	return geom, r.Err()
+}
+
+// writeMaterial writes the reference to the specified material, followed by its parameters and
+// textures if it was not written before.
+func (sw *sceneWriter) writeMaterial(imat material.IMaterial) error {
+
+	w := sw.w
+	if id, ok := sw.materials[imat]; ok {
+		w.WriteInt32(int32(id))
+		return nil
+	}
+	var kind int
+	switch imat.(type) {
+	case *material.Basic:
+		kind = materialBasic
+	case *material.Standard:
+		kind = materialStandard
+	case *material.Phong:
+		kind = materialPhong
+	case *material.Point:
+		kind = materialPoint
+	case *material.Physical:
+		kind = materialPhysical
+	default:
+		return fmt.Errorf("unsupported material:%T", imat)
+	}
+	sw.materials[imat] = len(sw.materials)
+	w.WriteInt32(int32(sw.materials[imat]))
+	w.WriteUint32(uint32(kind))
+
+	mat := imat.GetMaterial()
+	w.WriteInt32(int32(mat.Side()))
+	w.WriteInt32(int32(mat.Blending()))
+	w.WriteInt32(int32(mat.UseLights()))
+	w.WriteBool(mat.Transparent())
+	w.WriteBool(mat.Wireframe())
+	w.WriteBool(mat.DepthMask())
+	w.WriteBool(mat.DepthTest())
+	w.WriteFloat32(mat.LineWidth())
+	factor, units := mat.PolygonOffset()
+	w.WriteFloat32(factor)
+	w.WriteFloat32(units)
+
+	switch m := imat.(type) {
+	case *material.Standard:
+		sw.writeStandard(m)
+	case *material.Phong:
+		sw.writeStandard(&m.Standard)
+	case *material.Point:
+		sw.writeStandard(&m.Standard)
+		w.WriteFloat32(m.Size())
+		w.WriteFloat32(m.RotationZ())
+	case *material.Physical:
+		base := m.BaseColorFactor()
+		w.WriteFloat32s([]float32{base.R, base.G, base.B, base.A})
+		emissive := m.EmissiveFactor()
+		w.WriteFloat32s([]float32{emissive.R, emissive.G, emissive.B})
+		w.WriteFloat32(m.MetallicFactor())
+		w.WriteFloat32(m.RoughnessFactor())
+		w.WriteFloat32(m.OcclusionStrength())
+		w.WriteFloat32(m.AlphaCutoff())
+		// The textures are set in the material by the setters of the maps
+		for _, tex := range []*texture.Texture2D{m.BaseColorMap(), m.MetallicRoughnessMap(), m.NormalMap(), m.OcclusionMap(), m.EmissiveMap()} {
+			if err := sw.writeTexture(tex); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	textures := mat.Textures()
+	w.WriteUint32(uint32(len(textures)))
+	for _, tex := range textures {
+		if err := sw.writeTexture(tex); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeStandard writes the colors and factors of the specified standard material.
+func (sw *sceneWriter) writeStandard(m *material.Standard) {
+
+	for _, c := range []math32.Color{m.Color(), m.AmbientColor(), m.SpecularColor(), m.EmissiveColor()} {
+		sw.w.WriteFloat32s([]float32{c.R, c.G, c.B})
+	}
+	sw.w.WriteFloat32(m.Shininess())
+	sw.w.WriteFloat32(m.Opacity())
+}
+
+// readMaterial reads a material written by writeMaterial, or returns the material already read
+// with its reference count incremented.
+func (sr *sceneReader) readMaterial() (material.IMaterial, error) {
+
+	id, isNew, err := sr.readRef(len(sr.materials))
+	if err != nil {
+		return nil, err
+	}
+	if !isNew {
+		if id == noRef {
+			return nil, fmt.Errorf("invalid material reference:%d", id)
+		}
+		sr.materials[id].GetMaterial().Incref()
+		return sr.materials[id], nil
+	}
+	r := sr.r
+	var imat material.IMaterial
+	kind := r.ReadUint32()
+	switch kind {
+	case materialBasic:
+		imat = material.NewBasic()
+	case materialStandard:
+		imat = material.NewStandard(&math32.Color{})
+	case materialPhong:
+		imat = material.NewPhong(&math32.Color{})
+	case materialPoint:
+		imat = material.NewPoint(&math32.Color{})
+	case materialPhysical:
+		imat = material.NewPhysical()
+	default:
+		return nil, fmt.Errorf("invalid material kind:%d", kind)
+	}
+	sr.materials = append(sr.materials, imat)
+
+	mat := imat.GetMaterial()
+	mat.SetSide(material.Side(r.ReadInt32()))
+	mat.SetBlending(material.Blending(r.ReadInt32()))
+	mat.SetUseLights(material.UseLights(r.ReadInt32()))
+	mat.SetTransparent(r.ReadBool())
+	mat.SetWireframe(r.ReadBool())
+	mat.SetDepthMask(r.ReadBool())
+	mat.SetDepthTest(r.ReadBool())
+	mat.SetLineWidth(r.ReadFloat32())
+	factor := r.ReadFloat32()
+	mat.SetPolygonOffset(factor, r.ReadFloat32())
+
+	switch m := imat.(type) {
+	case *material.Standard:
+		sr.readStandard(m)
+	case *material.Phong:
+		sr.readStandard(&m.Standard)
+	case *material.Point:
+		sr.readStandard(&m.Standard)
+		m.SetSize(r.ReadFloat32())
+		m.SetRotationZ(r.ReadFloat32())
+	case *material.Physical:
+		var base, emissive [4]float32
+		copy(base[:], r.ReadFloat32s())
+		copy(emissive[:], r.ReadFloat32s())
+		m.SetBaseColorFactor(&math32.Color4{R: base[0], G: base[1], B: base[2], A: base[3]})
+		m.SetEmissiveFactor(&math32.Color{R: emissive[0], G: emissive[1], B: emissive[2]})
+		m.SetMetallicFactor(r.ReadFloat32())
+		m.SetRoughnessFactor(r.ReadFloat32())
+		m.SetOcclusionStrength(r.ReadFloat32())
+		m.SetAlphaCutoff(r.ReadFloat32())
+		setters := []func(*texture.Texture2D) *material.Physical{m.SetBaseColorMap, m.SetMetallicRoughnessMap, m.SetNormalMap, m.SetOcclusionMap, m.SetEmissiveMap}
+		for _, set := range setters {
+			tex, err := sr.readTexture()
+			if err != nil {
+				return nil, err
+			}
+			if tex != nil {
+				set(tex)
+			}
+		}
+		return imat, r.Err()
+	}
+	count := r.ReadUint32()
+	for i := uint32(0); i < count && r.Err() == nil; i++ {
+		tex, err := sr.readTexture()
+		if err != nil {
+			return nil, err
+		}
+		if tex == nil {
+			return nil, fmt.Errorf("invalid texture reference:%d", noRef)
+		}
+		mat.AddTexture(tex)
+	}
+	return imat, r.Err()
+}
+
+// readStandard reads the colors and factors of the specified standard material.
+func (sr *sceneReader) readStandard(m *material.Standard) {
+
+	var colors [4]math32.Color
+	for i := range colors {
+		var c [3]float32
+		copy(c[:], sr.r.ReadFloat32s())
+		colors[i] = math32.Color{R: c[0], G: c[1], B: c[2]}
+	}
+	m.SetColor(&colors[0])
+	m.SetAmbientColor(&colors[1])
+	m.SetSpecularColor(&colors[2])
+	m.SetEmissiveColor(&colors[3])
+	m.SetShininess(sr.r.ReadFloat32())
+	m.SetOpacity(sr.r.ReadFloat32())
+}
+
+// writeTexture writes the reference to the specified texture, which may be nil, followed by its data and
+// parameters if it was not written before.
+func (sw *sceneWriter) writeTexture(tex *texture.Texture2D) error {
+
+	w := sw.w
+	if tex == nil {
+		w.WriteInt32(noRef)
+		return nil
+	}
+	if id, ok := sw.textures[tex]; ok {
+		w.WriteInt32(int32(id))
+		return nil
+	}
+	format, formatType, iformat, data := tex.Data()
+	var kind int
+	switch data.(type) {
+	case []byte:
+		kind = textureBytes
+	case []float32:
+		kind = textureFloats
+	case nil:
+		return fmt.Errorf("texture without data")
+	default:
+		return fmt.Errorf("unsupported texture data:%T", data)
+	}
+	sw.textures[tex] = len(sw.textures)
+	w.WriteInt32(int32(sw.textures[tex]))
+
+	width, height := tex.Dimensions()
+	w.WriteInt32(int32(width))
+	w.WriteInt32(int32(height))
+	w.WriteInt32(int32(format))
+	w.WriteInt32(int32(formatType))
+	w.WriteInt32(int32(iformat))
+	w.WriteUint32(uint32(kind))
+	if kind == textureBytes {
+		w.WriteBytes(data.([]byte))
+	} else {
+		w.WriteFloat32s(data.([]float32))
+	}
+
+	minFilter, magFilter := tex.Filter()
+	w.WriteUint32(uint32(minFilter))
+	w.WriteUint32(uint32(magFilter))
+	wrapS, wrapT := tex.Wrap()
+	w.WriteUint32(uint32(wrapS))
+	w.WriteUint32(uint32(wrapT))
+	w.WriteBool(tex.GenerateMipmap())
+	w.WriteBool(tex.FlipY())
+	w.WriteBool(tex.Visible())
+	repeatX, repeatY := tex.Repeat()
+	offsetX, offsetY := tex.Offset()
+	w.WriteFloat32s([]float32{repeatX, repeatY, offsetX, offsetY})
+	sampler, info := tex.GetUniformNames()
+	w.WriteString(sampler)
+	w.WriteString(info)
+	return nil
+}
+
+// readTexture reads a texture written by writeTexture, or returns the texture already read
+// with its reference count incremented. Returns nil for a missing texture.
+func (sr *sceneReader) readTexture() (*texture.Texture2D, error) {
+
+	id, isNew, err := sr.readRef(len(sr.textures))
+	if err != nil || id == noRef {
+		return nil, err
+	}
+	if !isNew {
+		return sr.textures[id].Incref(), nil
+	}
+	r := sr.r
+	width := r.ReadInt32()
+	height := r.ReadInt32()
+	format := r.ReadInt32()
+	formatType := r.ReadInt32()
+	iformat := r.ReadInt32()
+	var data interface{}
+	switch kind := r.ReadUint32(); kind {
+	case textureBytes:
+		data = r.ReadBytes()
+	case textureFloats:
+		data = r.ReadFloat32s()
+	default:
+		return nil, fmt.Errorf("invalid texture data kind:%d", kind)
+	}
+	tex := texture.NewTexture2DFromData(int(width), int(height), int(format), int(formatType), int(iformat), data)
+	minFilter := r.ReadUint32()
+	tex.SetFilter(texture.FilterMode(minFilter), texture.FilterMode(r.ReadUint32()))
+	wrapS := r.ReadUint32()
+	tex.SetWrap(texture.WrapMode(wrapS), texture.WrapMode(r.ReadUint32()))
+	tex.SetGenerateMipmap(r.ReadBool())
+	tex.SetFlipY(r.ReadBool())
+	tex.SetVisible(r.ReadBool())
+	var params [4]float32
+	copy(params[:], r.ReadFloat32s())
+	tex.SetRepeat(params[0], params[1])
+	tex.SetOffset(params[2], params[3])
+	sampler := r.ReadString()
+	tex.SetUniformNames(sampler, r.ReadString())
+	sr.textures = append(sr.textures, tex)
+	return tex, r.Err()
+}
+
+// writeAnimation writes the specified animation with its channels, which must target nodes of the scene.
+func (sw *sceneWriter) writeAnimation(anim *animation.Animation) error {
+
+	w := sw.w
+	w.WriteString(anim.Name())
+	w.WriteBool(anim.Loop())
+	w.WriteBool(anim.Paused())
+	w.WriteFloat32(anim.Speed())
+	w.WriteFloat32(anim.Start())
+	channels := anim.Channels()
+	w.WriteUint32(uint32(len(channels)))
+	for _, ich := range channels {
+		var kind int
+		var target core.INode
+		var ch *animation.Channel
+		switch c := ich.(type) {
+		case *animation.PositionChannel:
+			kind, target, ch = channelPosition, c.Target(), &c.Channel
+		case *animation.RotationChannel:
+			kind, target, ch = channelRotation, c.Target(), &c.Channel
+		case *animation.ScaleChannel:
+			kind, target, ch = channelScale, c.Target(), &c.Channel
+		default:
+			return fmt.Errorf("unsupported animation channel:%T", ich)
+		}
+		id, ok := sw.nodes[target]
+		if !ok {
+			return fmt.Errorf("animation channel target not in the scene:%s", target.GetNode().Name())
+		}
+		w.WriteUint32(uint32(kind))
+		w.WriteInt32(int32(id))
+		w.WriteString(string(ch.InterpolationType()))
+		w.WriteFloat32s(ch.Keyframes())
+		w.WriteFloat32s(ch.Values())
+		inTangent, outTangent := ch.InterpolationTangents()
+		w.WriteFloat32s(inTangent)
+		w.WriteFloat32s(outTangent)
+	}
+	return nil
+}
+
+// readAnimation reads an animation written by writeAnimation, targeting the nodes already read.
+func (sr *sceneReader) readAnimation() (*animation.Animation, error) {
+
+	r := sr.r
+	anim := animation.NewAnimation()
+	anim.SetName(r.ReadString())
+	anim.SetLoop(r.ReadBool())
+	anim.SetPaused(r.ReadBool())
+	anim.SetSpeed(r.ReadFloat32())
+	anim.SetStart(r.ReadFloat32())
+	count := r.ReadUint32()
+	for i := uint32(0); i < count && r.Err() == nil; i++ {
+		kind := r.ReadUint32()
+		id := r.ReadInt32()
+		interp := animation.InterpolationType(r.ReadString())
+		keyframes := r.ReadFloat32s()
+		values := r.ReadFloat32s()
+		inTangent := r.ReadFloat32s()
+		outTangent := r.ReadFloat32s()
+		if err := r.Err(); err != nil {
+			return nil, err
+		}
+		if id < 0 || int(id) >= len(sr.nodes) {
+			return nil, fmt.Errorf("invalid animation channel target:%d", id)
+		}
+		if len(keyframes) == 0 {
+			return nil, fmt.Errorf("invalid animation channel without keyframes")
+		}
+		// The empty interpolation set by loaders keeps the default linear interpolation
+		if interp != "" && interp != animation.STEP && interp != animation.LINEAR && interp != animation.CUBICSPLINE {
+			return nil, fmt.Errorf("invalid animation interpolation:%s", interp)
+		}
+		target := sr.nodes[id]
+		var ich animation.IChannel
+		var ch *animation.Channel
+		switch kind {
+		case channelPosition:
+			c := animation.NewPositionChannel(target)
+			ich, ch = c, &c.Channel
+		case channelRotation:
+			c := animation.NewRotationChannel(target)
+			ich, ch = c, &c.Channel
+		case channelScale:
+			c := animation.NewScaleChannel(target)
+			ich, ch = c, &c.Channel
+		default:
+			return nil, fmt.Errorf("invalid animation channel kind:%d", kind)
+		}
+		ch.SetBuffers(keyframes, values)
+		ch.SetInterpolationType(interp)
+		ch.SetInterpolationTangents(inTangent, outTangent)
+		anim.AddChannel(ich)
+	}
+	return anim, r.Err()
+}
@@ -0,0 +1,201 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package save
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/g3n/engine/animation"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/loader/gltf"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// dumpScene writes a description of the nodes, geometries and materials of the specified scene graph,
+// which is equal for equal scenes.
+func dumpScene(inode core.INode, depth int, out *bytes.Buffer) {
+
+	node := inode.GetNode()
+	fmt.Fprintf(out, "%*s%T %q %v %v %v\n", depth, "", inode, node.Name(), node.Position(), node.Quaternion(), node.Scale())
+	if igr, ok := inode.(graphic.IGraphic); ok {
+		geom := igr.GetGeometry()
+		fmt.Fprintf(out, "%*s geometry items=%d indices=%v\n", depth, "", geom.Items(), geom.Indices())
+		for _, vbo := range geom.VBOs() {
+			fmt.Fprintf(out, "%*s vbo %v %v\n", depth, "", *vbo.Buffer(), vbo.Attributes())
+		}
+		for _, grmat := range igr.GetGraphic().Materials() {
+			start, count := grmat.Range()
+			imat := grmat.IMaterial()
+			fmt.Fprintf(out, "%*s material %T %d %d side=%v textures=%d\n", depth, "", imat, start, count,
+				imat.GetMaterial().Side(), len(imat.GetMaterial().Textures()))
+			switch m := imat.(type) {
+			case *material.Physical:
+				fmt.Fprintf(out, "%*s  %v %v %v %v\n", depth, "", m.BaseColorFactor(), m.MetallicFactor(), m.RoughnessFactor(), m.BaseColorMap() != nil)
+			case *material.Standard:
+				fmt.Fprintf(out, "%*s  %v %v %v\n", depth, "", m.Color(), m.Shininess(), m.Opacity())
+			}
+		}
+	}
+	for _, child := range node.Children() {
+		dumpScene(child, depth+1, out)
+	}
+}
+
+// roundTrip writes the specified scene with the specified animations, checks that it is read back equal
+// and returns the serialized data and the serializer which read it.
+func roundTrip(t *testing.T, root core.INode, anims []*animation.Animation) ([]byte, *SceneSerializer, core.INode) {
+
+	t.Helper()
+	s := NewSceneSerializer()
+	s.Animations = anims
+	var buf bytes.Buffer
+	if err := s.WriteScene(&buf, root); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	r := NewSceneSerializer()
+	read, err := r.ReadScene(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want, got bytes.Buffer
+	dumpScene(root, 0, &want)
+	dumpScene(read, 0, &got)
+	if want.String() != got.String() {
+		t.Fatalf("scene read back as\n%s\ninstead of\n%s", got.String(), want.String())
+	}
+	if len(r.Animations) != len(anims) {
+		t.Fatal(len(r.Animations), "animations read back instead of", len(anims))
+	}
+	return data, r, read
+}
+
+// sharedObjects returns the numbers of distinct geometries and materials of the graphics of the specified scene.
+func sharedObjects(root core.INode) (int, int) {
+
+	geoms := make(map[*geometry.Geometry]bool)
+	mats := make(map[material.IMaterial]bool)
+	var visit func(inode core.INode)
+	visit = func(inode core.INode) {
+		if igr, ok := inode.(graphic.IGraphic); ok {
+			geoms[igr.GetGeometry()] = true
+			for _, grmat := range igr.GetGraphic().Materials() {
+				mats[grmat.IMaterial()] = true
+			}
+		}
+		for _, child := range inode.GetNode().Children() {
+			visit(child)
+		}
+	}
+	visit(root)
+	return len(geoms), len(mats)
+}
+
+// Test the round trip of a scene with shared geometries, materials and textures and an animation
+func TestSceneSerializerRoundTrip(t *testing.T) {
+
+	geom := geometry.NewBox(1, 2, 3)
+	tex := texture.NewTexture2DFromColor(&math32.Color{R: 1}, 2, 2)
+	std := material.NewStandard(&math32.Color{R: 1, G: 0.5, B: 0.25})
+	std.SetShininess(12)
+	std.AddTexture(tex)
+	phys := material.NewPhysical()
+	phys.SetBaseColorMap(tex)
+	phys.SetMetallicFactor(0.3)
+
+	root := core.NewNode()
+	root.SetName("root")
+	m1 := graphic.NewMesh(geom, std)
+	m1.SetPosition(1, 2, 3)
+	m2 := graphic.NewMesh(geom, phys)
+	m2.SetName("second")
+	child := core.NewNode()
+	child.SetScale(2, 2, 2)
+	child.Add(m2)
+	root.Add(m1)
+	root.Add(child)
+	root.Add(graphic.NewLines(geometry.NewGeometry(), std))
+
+	anim := animation.NewAnimation()
+	anim.SetName("move")
+	pc := animation.NewPositionChannel(m2)
+	pc.SetBuffers(math32.ArrayF32{0, 1}, math32.ArrayF32{0, 0, 0, 1, 1, 1})
+	anim.AddChannel(pc)
+
+	_, r, read := roundTrip(t, root, []*animation.Animation{anim})
+
+	// The shared objects are read once
+	rm1 := read.GetNode().ChildAt(0).(*graphic.Mesh)
+	rm2 := read.GetNode().ChildAt(1).GetNode().ChildAt(0).(*graphic.Mesh)
+	if rm1.GetGeometry() != rm2.GetGeometry() {
+		t.Error("geometry not shared")
+	}
+	if rm1.Materials()[0].IMaterial() != read.GetNode().ChildAt(2).(*graphic.Lines).Materials()[0].IMaterial() {
+		t.Error("material not shared")
+	}
+	if rm1.Materials()[0].IMaterial().GetMaterial().Textures()[0] != rm2.Materials()[0].IMaterial().(*material.Physical).BaseColorMap() {
+		t.Error("texture not shared")
+	}
+
+	// The animation targets the read node
+	channel, ok := r.Animations[0].Channels()[0].(*animation.PositionChannel)
+	if !ok || channel.Target() != rm2 || r.Animations[0].Name() != "move" {
+		t.Fatal("animation not read back")
+	}
+	r.Animations[0].Update(0.5)
+	if p := rm2.Position(); p != (math32.Vector3{X: 0.5, Y: 0.5, Z: 0.5}) {
+		t.Error("animated position", p, "instead of 0.5, 0.5, 0.5")
+	}
+}
+
+// Test that the corrupted and truncated data is rejected
+func TestSceneSerializerCorruption(t *testing.T) {
+
+	root := core.NewNode()
+	root.Add(graphic.NewMesh(geometry.NewBox(1, 1, 1), material.NewStandard(&math32.Color{G: 1})))
+	data, _, _ := roundTrip(t, root, nil)
+	s := NewSceneSerializer()
+	for _, i := range []int{len(data) / 2, len(data) - 10, len(data) - 1} {
+		corrupted := append([]byte(nil), data...)
+		corrupted[i] ^= 1
+		if _, err := s.ReadScene(bytes.NewReader(corrupted)); err == nil {
+			t.Error("corruption of the byte", i, "not detected")
+		}
+	}
+	if _, err := s.ReadScene(bytes.NewReader(data[:20])); err == nil {
+		t.Error("truncation not detected")
+	}
+	if _, err := s.ReadScene(bytes.NewReader([]byte("not a scene at all, not a scene at all"))); err == nil {
+		t.Error("invalid data not detected")
+	}
+}
+
+// Test the round trip of a glTF model whose meshes and material are used by several nodes
+func TestSceneSerializerGLTF(t *testing.T) {
+
+	loaded, err := gltf.LoadGLTF("testdata/shared.gltf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, anims := loaded.Root, loaded.Animations
+	// The loader creates a geometry for each node of a mesh and loads each material once
+	geoms, mats := sharedObjects(root)
+	if geoms != 3 || mats != 1 {
+		t.Fatal("glTF model loaded with", geoms, "geometries and", mats, "materials instead of 3 and 1")
+	}
+	_, r, read := roundTrip(t, root, anims)
+	if rg, rm := sharedObjects(read); rg != geoms || rm != mats {
+		t.Error("scene read back with", rg, "geometries and", rm, "materials instead of", geoms, "and", mats)
+	}
+	if len(r.Animations) != 1 || r.Animations[0].Name() != "move" {
+		t.Error("glTF animation not read back")
+	}
+}
@@ -0,0 +1,8 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package steering implements steering behaviors for the movement of autonomous agents,
+// which are combined by priority.
+// WARNING: This package is experimental and incomplete!
+package steering
@@ -0,0 +1,125 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package steering
+
+import (
+	"math/rand"
+
+	"github.com/g3n/engine/math32"
+)
+
+// Seek returns the velocity with the specified maximum speed which moves the agent towards the target.
+// The steering force is this desired velocity minus the current velocity of the agent.
+func Seek(agent, target *math32.Vector3, maxSpeed float32) math32.Vector3 {
+
+	var desired math32.Vector3
+	desired.SubVectors(target, agent).Normalize().MultiplyScalar(maxSpeed)
+	return desired
+}
+
+// Flee returns the velocity with the specified maximum speed which moves the agent away from the target.
+func Flee(agent, target *math32.Vector3, maxSpeed float32) math32.Vector3 {
+
+	var desired math32.Vector3
+	desired.SubVectors(agent, target).Normalize().MultiplyScalar(maxSpeed)
+	return desired
+}
+
+// Arrive returns the velocity which moves the agent towards the target as Seek, but slowing down linearly
+// inside the slowing radius around the target, to stop at it.
+func Arrive(agent, target *math32.Vector3, slowingRadius, maxSpeed float32) math32.Vector3 {
+
+	var desired math32.Vector3
+	desired.SubVectors(target, agent)
+	dist := desired.Length()
+	speed := maxSpeed
+	if dist < slowingRadius {
+		speed *= dist / slowingRadius
+	}
+	desired.Normalize().MultiplyScalar(speed)
+	return desired
+}
+
+// Wander returns the offset from the agent to a random target wandering on the XZ plane: the target is on
+// a circle with the wander radius whose center is at the wander distance ahead of the agent orientation,
+// the angle in radians of its heading from the X axis towards the Z axis. The target moves on the circle
+// by a random angle of up to wanderJitter radians at each call, and the orientation is updated to point
+// to it, so the agent turns smoothly. If rng is nil the default source of the math/rand package is used.
+func Wander(agent *math32.Vector3, orientation *float32, wanderRadius, wanderDist, wanderJitter float32, rng *rand.Rand) math32.Vector3 {
+
+	random := rand.Float32
+	if rng != nil {
+		random = rng.Float32
+	}
+	heading := *orientation
+	angle := heading + (2*random()-1)*wanderJitter
+	target := math32.Vector3{
+		X: math32.Cos(heading)*wanderDist + math32.Cos(angle)*wanderRadius,
+		Z: math32.Sin(heading)*wanderDist + math32.Sin(angle)*wanderRadius,
+	}
+	*orientation = math32.Atan2(target.Z, target.X)
+	return target
+}
+
+// Separation returns the force which moves the agent away from the neighbors closer than the radius,
+// the sum of the directions away from each neighbor weighted from 1 at the agent to 0 at the radius.
+func Separation(agent *math32.Vector3, neighbors []math32.Vector3, radius float32) math32.Vector3 {
+
+	var force math32.Vector3
+	for i := range neighbors {
+		var away math32.Vector3
+		away.SubVectors(agent, &neighbors[i])
+		dist := away.Length()
+		if dist == 0 || dist >= radius {
+			continue
+		}
+		force.Add(away.MultiplyScalar((1 - dist/radius) / dist))
+	}
+	return force
+}
+
+// SteeringAccumulator combines the forces of several behaviors, added by decreasing priority,
+// without exceeding a maximum force: the forces which exceed the remaining budget are truncated,
+// so the urgent behaviors, like the avoidance of obstacles, prevail over the others, like wandering.
+type SteeringAccumulator struct {
+	MaxForce float32
+	force    math32.Vector3
+}
+
+// NewSteeringAccumulator creates and returns a pointer to a new SteeringAccumulator with the specified maximum force.
+func NewSteeringAccumulator(maxForce float32) *SteeringAccumulator {
+
+	return &SteeringAccumulator{MaxForce: maxForce}
+}
+
+// Add adds the specified force, truncated to the remaining budget, and returns
+// if there is budget left for the behaviors of lower priority.
+func (sa *SteeringAccumulator) Add(force *math32.Vector3) bool {
+
+	remaining := sa.MaxForce - sa.force.Length()
+	if remaining <= 0 {
+		return false
+	}
+	length := force.Length()
+	if length < remaining {
+		sa.force.Add(force)
+		return true
+	}
+	f := *force
+	sa.force.Add(f.MultiplyScalar(remaining / length))
+	return false
+}
+
+// Force returns the accumulated force.
+func (sa *SteeringAccumulator) Force() math32.Vector3 {
+
+	return sa.force
+}
+
+// Reset resets the accumulated force to zero, for the next update.
+func (sa *SteeringAccumulator) Reset() {
+
+	sa.force = math32.Vector3{}
+}
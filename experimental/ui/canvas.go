@@ -0,0 +1,332 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ui
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// UIElement is the interface for all the elements of a UICanvas.
+// Elements embed UIBase, which keeps their children and layout state.
+type UIElement interface {
+	// Bounds returns the screen rectangle of the element computed by the last layout.
+	Bounds() math32.Rect
+	// Draw draws the element, but not its children, into the specified batch.
+	Draw(sb *SpriteBatch)
+	// HandleEvent handles the specified event and returns true if it was consumed.
+	HandleEvent(ev *UIEvent) bool
+	// GetUIBase returns the embedded UIBase of the element.
+	GetUIBase() *UIBase
+}
+
+// LayoutKind specifies how the size of an element is computed from its parent.
+type LayoutKind int
+
+// Layout kinds
+const (
+	LayoutFixed = LayoutKind(iota) // fixed size along the direction of the parent
+	LayoutFlex                     // share of the space left by the fixed siblings, proportional to Flex
+	LayoutFill                     // whole content rectangle of the parent, over the other children
+)
+
+// LayoutConstraint describes the size of an element inside its parent.
+type LayoutConstraint struct {
+	Kind   LayoutKind
+	Width  float32 // width of fixed elements, or 0 to fill the width of a vertical parent
+	Height float32 // height of fixed elements, or 0 to fill the height of an horizontal parent
+	Flex   float32 // weight of flex elements, 1 if 0
+}
+
+// Direction specifies the direction along which the children of an element are placed.
+type Direction int
+
+// Directions
+const (
+	Vertical = Direction(iota)
+	Horizontal
+)
+
+// UIBase is the common state of all UI elements: their bounds, layout constraint and children.
+// The children are placed one after the other along the direction of the element, inside its
+// bounds reduced by the padding and separated by the spacing.
+type UIBase struct {
+	Constraint LayoutConstraint
+	Direction  Direction
+	Padding    float32
+	Spacing    float32
+	bounds     math32.Rect
+	clip       bool // clips the children to the bounds
+	parent     UIElement
+	children   []UIElement
+}
+
+// Bounds returns the screen rectangle of this element computed by the last layout.
+func (b *UIBase) Bounds() math32.Rect {
+
+	return b.bounds
+}
+
+// GetUIBase satisfies the UIElement interface.
+func (b *UIBase) GetUIBase() *UIBase {
+
+	return b
+}
+
+// Parent returns the parent of this element or nil.
+func (b *UIBase) Parent() UIElement {
+
+	return b.parent
+}
+
+// Children returns the children of this element.
+func (b *UIBase) Children() []UIElement {
+
+	return b.children
+}
+
+// Add appends the specified child to the specified parent element,
+// removing it from its previous parent.
+func Add(parent, child UIElement) {
+
+	cb := child.GetUIBase()
+	if cb.parent != nil {
+		Remove(cb.parent, child)
+	}
+	pb := parent.GetUIBase()
+	pb.children = append(pb.children, child)
+	cb.parent = parent
+}
+
+// Remove removes the specified child from the specified parent element.
+// Returns true if found.
+func Remove(parent, child UIElement) bool {
+
+	pb := parent.GetUIBase()
+	for i, c := range pb.children {
+		if c == child {
+			copy(pb.children[i:], pb.children[i+1:])
+			pb.children[len(pb.children)-1] = nil
+			pb.children = pb.children[:len(pb.children)-1]
+			child.GetUIBase().parent = nil
+			return true
+		}
+	}
+	return false
+}
+
+// contentLayouter is implemented by the elements which lay out their children differently.
+type contentLayouter interface {
+	layoutContent()
+}
+
+// layoutElement sets the bounds of the specified element and lays out its children.
+func layoutElement(e UIElement, bounds *math32.Rect) {
+
+	b := e.GetUIBase()
+	b.bounds = *bounds
+	if cl, ok := e.(contentLayouter); ok {
+		cl.layoutContent()
+		return
+	}
+	content := b.bounds
+	content.Expand(-b.Padding)
+	layoutChildren(b.children, &content, b.Direction, b.Spacing)
+}
+
+// layoutChildren places the specified elements one after the other along the specified
+// direction inside the specified rectangle, and lays out their children.
+func layoutChildren(children []UIElement, content *math32.Rect, dir Direction, spacing float32) {
+
+	axis := 1
+	if dir == Horizontal {
+		axis = 0
+	}
+	mainSize := content.Height()
+	if axis == 0 {
+		mainSize = content.Width()
+	}
+
+	// Space left for the flex elements
+	free := mainSize
+	var flexSum float32
+	placed := 0
+	for _, c := range children {
+		lc := &c.GetUIBase().Constraint
+		switch lc.Kind {
+		case LayoutFill:
+			continue
+		case LayoutFlex:
+			flexSum += flexWeight(lc)
+		default:
+			free -= fixedSize(lc, axis)
+		}
+		placed++
+	}
+	if placed > 1 {
+		free -= spacing * float32(placed-1)
+	}
+	free = math32.Max(free, 0)
+
+	pos := content.Min.Component(axis)
+	for _, c := range children {
+		lc := &c.GetUIBase().Constraint
+		if lc.Kind == LayoutFill {
+			layoutElement(c, content)
+			continue
+		}
+		var size float32
+		if lc.Kind == LayoutFlex {
+			if flexSum > 0 {
+				size = free * flexWeight(lc) / flexSum
+			}
+		} else {
+			size = fixedSize(lc, axis)
+		}
+		r := *content
+		if axis == 0 {
+			r.Min.X, r.Max.X = pos, pos+size
+			if lc.Kind == LayoutFixed && lc.Height > 0 {
+				r.Max.Y = r.Min.Y + lc.Height
+			}
+		} else {
+			r.Min.Y, r.Max.Y = pos, pos+size
+			if lc.Kind == LayoutFixed && lc.Width > 0 {
+				r.Max.X = r.Min.X + lc.Width
+			}
+		}
+		layoutElement(c, &r)
+		pos += size + spacing
+	}
+}
+
+// fixedSize returns the size of a fixed element along the specified axis.
+func fixedSize(lc *LayoutConstraint, axis int) float32 {
+
+	if axis == 0 {
+		return lc.Width
+	}
+	return lc.Height
+}
+
+// flexWeight returns the weight of a flex element.
+func flexWeight(lc *LayoutConstraint) float32 {
+
+	if lc.Flex <= 0 {
+		return 1
+	}
+	return lc.Flex
+}
+
+// UICanvas is the root of a tree of UI elements, which lays them out in a viewport,
+// draws them and dispatches the input events to them.
+type UICanvas struct {
+	UIBase
+	focus UIElement // element which consumed the last mouse button press, receives the key events
+}
+
+// NewUICanvas creates and returns a pointer to a new empty UICanvas
+// whose elements are placed vertically.
+func NewUICanvas() *UICanvas {
+
+	return new(UICanvas)
+}
+
+// Draw satisfies the UIElement interface. The canvas itself draws nothing.
+func (c *UICanvas) Draw(sb *SpriteBatch) {
+}
+
+// HandleEvent satisfies the UIElement interface. The canvas itself consumes no event.
+func (c *UICanvas) HandleEvent(ev *UIEvent) bool {
+
+	return false
+}
+
+// Add appends the specified element to the canvas.
+func (c *UICanvas) Add(e UIElement) {
+
+	Add(c, e)
+}
+
+// Layout computes the bounds of all the elements of the canvas inside the specified viewport rectangle.
+func (c *UICanvas) Layout(viewport *math32.Rect) {
+
+	layoutElement(c, viewport)
+}
+
+// DrawAll draws all the elements of the canvas into the specified batch, parents before their children.
+func (c *UICanvas) DrawAll(sb *SpriteBatch) {
+
+	drawElement(c, sb)
+}
+
+// drawElement draws the specified element and its children.
+func drawElement(e UIElement, sb *SpriteBatch) {
+
+	e.Draw(sb)
+	b := e.GetUIBase()
+	if b.clip {
+		sb.PushClip(&b.bounds)
+	}
+	for _, child := range b.children {
+		drawElement(child, sb)
+	}
+	if b.clip {
+		sb.PopClip()
+	}
+}
+
+// Focus returns the element which receives the events without position or nil.
+func (c *UICanvas) Focus() UIElement {
+
+	return c.focus
+}
+
+// Dispatch sends the specified event to the elements of the canvas, from the deepest element
+// under the event position up to the root, until an element consumes it.
+// Events without position and mouse button releases are sent to the focused element and its ancestors.
+// Returns the element which consumed the event or nil.
+func (c *UICanvas) Dispatch(ev *UIEvent) UIElement {
+
+	// The mouse button releases go to the element which consumed the press, even outside of it
+	var target UIElement
+	if ev.positional() && !(ev.Type == UIMouseUp && c.focus != nil) {
+		target = hitTest(c, &ev.Pos)
+	} else {
+		target = c.focus
+	}
+	for e := target; e != nil; e = e.GetUIBase().parent {
+		ev.Target = e
+		if e.HandleEvent(ev) {
+			if ev.Type == UIMouseDown {
+				c.focus = e
+			}
+			return e
+		}
+	}
+	if ev.Type == UIMouseDown {
+		c.focus = nil
+	}
+	return nil
+}
+
+// hitTest returns the deepest element under the specified point, the last drawn among siblings,
+// or nil if the point is outside of the specified element.
+func hitTest(e UIElement, p *math32.Vector2) UIElement {
+
+	b := e.GetUIBase()
+	inside := b.bounds.ContainsPoint(p)
+	if b.clip && !inside {
+		return nil
+	}
+	for i := len(b.children) - 1; i >= 0; i-- {
+		if hit := hitTest(b.children[i], p); hit != nil {
+			return hit
+		}
+	}
+	if inside {
+		return e
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ui implements a lightweight widget system whose elements are laid out with
+// constraints and drawn as textured quads collected in a SpriteBatch.
+// WARNING: This package is experimental and incomplete!
+package ui
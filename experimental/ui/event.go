@@ -0,0 +1,44 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ui
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// UIEventType is the type of a UIEvent.
+type UIEventType int
+
+// Event types
+const (
+	UIMouseDown = UIEventType(iota)
+	UIMouseUp
+	UIMouseMove
+	UIScroll
+	UIKeyDown
+	UIKeyUp
+	UIChar
+)
+
+// UIEvent is an input event dispatched to the elements of a UICanvas.
+type UIEvent struct {
+	Type   UIEventType
+	Pos    math32.Vector2 // screen position in pixels of the mouse and scroll events
+	Delta  math32.Vector2 // scroll offset of the scroll events
+	Button int            // mouse button of the mouse button events
+	Key    int            // key code of the key events
+	Char   rune           // character of the char events
+	Target UIElement      // element handling the event, set by the dispatch
+}
+
+// positional returns if this event is sent to the elements under its position.
+func (ev *UIEvent) positional() bool {
+
+	switch ev.Type {
+	case UIMouseDown, UIMouseUp, UIMouseMove, UIScroll:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ui
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/g3n/engine/experimental/ecs"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// minimapDiscSize is the size in pixels of the texture of the icons of a Minimap.
+const minimapDiscSize = 64
+
+// MinimapIcon is the appearance on a Minimap of an entity.
+type MinimapIcon struct {
+	Entity ecs.EntityID
+	Color  math32.Color4
+	Radius float32 // radius of the circle in world units
+}
+
+// MinimapEntity is the position of an entity drawn by Minimap.Update.
+type MinimapEntity struct {
+	Entity   ecs.EntityID
+	Position math32.Vector3
+}
+
+// Minimap is a top-down map of an area of the world drawn into a render target, whose color texture
+// can be shown by an UIImage. The X axis of the world is mapped to the right of the texture and the Z axis down,
+// as seen from above, keeping the aspect ratio of the area: the texture is letterboxed when their shapes differ.
+// The entities are drawn as circles with the color and radius of their icons.
+type Minimap struct {
+	Area       math32.Box3   // area of the world shown, whose Y extent is ignored
+	Icons      []MinimapIcon // icons of the drawn entities, the other entities are not drawn
+	Background math32.Color4
+	gs         *gls.GLS
+	target     *texture.RenderTarget
+	batch      *SpriteBatch
+	shader     *gls.Shader
+	disc       *texture.Texture2D     // white disc with antialiased edges
+	icons      map[ecs.EntityID]int32 // index of the icon of each entity, rebuilt by Update
+}
+
+// NewMinimap creates and returns a pointer to a new Minimap of the specified area of the world drawn into
+// a render target of the specified size in pixels, by a SpriteBatch with the specified shader.
+func NewMinimap(gs *gls.GLS, area *math32.Box3, width, height int, shader *gls.Shader) (*Minimap, error) {
+
+	target, err := texture.NewRenderTarget(gs, width, height, texture.RenderTargetOpts{})
+	if err != nil {
+		return nil, err
+	}
+	mm := new(Minimap)
+	mm.Area = *area
+	mm.Background = math32.Color4{R: 0, G: 0, B: 0, A: 0.5}
+	mm.gs = gs
+	mm.target = target
+	mm.batch = NewSpriteBatch(256)
+	mm.shader = shader
+	mm.icons = make(map[ecs.EntityID]int32)
+
+	// The alpha falls off over the last pixel of the radius
+	img := image.NewRGBA(image.Rect(0, 0, minimapDiscSize, minimapDiscSize))
+	const r = minimapDiscSize / 2
+	for y := 0; y < minimapDiscSize; y++ {
+		for x := 0; x < minimapDiscSize; x++ {
+			dx, dy := float32(x)+0.5-r, float32(y)+0.5-r
+			d := math32.Sqrt(dx*dx + dy*dy)
+			img.SetRGBA(x, y, color.RGBA{R: 255, G: 255, B: 255, A: packUnorm8(r - d)})
+		}
+	}
+	mm.disc = texture.NewTexture2DFromRGBA(img)
+	mm.disc.SetGenerateMipmap(false)
+	return mm, nil
+}
+
+// RenderTarget returns the render target into which this minimap is drawn.
+func (mm *Minimap) RenderTarget() *texture.RenderTarget {
+
+	return mm.target
+}
+
+// Texture returns the texture of this minimap.
+func (mm *Minimap) Texture() *texture.Texture2D {
+
+	return mm.target.ColorTexture()
+}
+
+// transform returns the scale from world units to pixels and the pixel position
+// of the corner of the area, centering the area in the texture.
+func (mm *Minimap) transform() (scale, offsetX, offsetY float32) {
+
+	width, height := float32(mm.target.Width()), float32(mm.target.Height())
+	sizeX := mm.Area.Max.X - mm.Area.Min.X
+	sizeZ := mm.Area.Max.Z - mm.Area.Min.Z
+	switch {
+	case sizeX <= 0 && sizeZ <= 0:
+		return 0, width / 2, height / 2
+	case sizeX <= 0:
+		scale = height / sizeZ
+	case sizeZ <= 0:
+		scale = width / sizeX
+	default:
+		scale = math32.Min(width/sizeX, height/sizeZ)
+	}
+	return scale, (width - math32.Max(sizeX, 0)*scale) / 2, (height - math32.Max(sizeZ, 0)*scale) / 2
+}
+
+// WorldToMinimap returns a pointer to a new vector with the position in pixels from the top left corner
+// of the texture of this minimap of the specified world position.
+func (mm *Minimap) WorldToMinimap(worldPos *math32.Vector3) *math32.Vector2 {
+
+	scale, offsetX, offsetY := mm.transform()
+	return math32.NewVector2(
+		offsetX+(worldPos.X-mm.Area.Min.X)*scale,
+		offsetY+(worldPos.Z-mm.Area.Min.Z)*scale,
+	)
+}
+
+// Update clears the render target of this minimap with the background color
+// and draws the specified entities which have an icon, in their order.
+func (mm *Minimap) Update(entities []MinimapEntity) {
+
+	for k := range mm.icons {
+		delete(mm.icons, k)
+	}
+	for i := range mm.Icons {
+		mm.icons[mm.Icons[i].Entity] = int32(i)
+	}
+
+	gs := mm.gs
+	mm.target.Bind()
+	bg := &mm.Background
+	gs.ClearColor(bg.R, bg.G, bg.B, bg.A)
+	gs.Clear(gls.COLOR_BUFFER_BIT)
+	gs.Disable(gls.DEPTH_TEST)
+	gs.Disable(gls.CULL_FACE)
+	gs.Enable(gls.BLEND)
+	gs.BlendEquation(gls.FUNC_ADD)
+	gs.BlendFunc(gls.SRC_ALPHA, gls.ONE_MINUS_SRC_ALPHA)
+
+	scale, _, _ := mm.transform()
+	uv := math32.Rect{Max: math32.Vector2{X: 1, Y: 1}}
+	mm.batch.Begin(mm.shader)
+	mm.batch.SetTexture(mm.disc)
+	for i := range entities {
+		idx, ok := mm.icons[entities[i].Entity]
+		if !ok {
+			continue
+		}
+		icon := &mm.Icons[idx]
+		center := mm.WorldToMinimap(&entities[i].Position)
+		// At least one pixel wide to remain visible on large areas
+		r := math32.Max(icon.Radius*scale, 0.5)
+		rect := math32.Rect{
+			Min: math32.Vector2{X: center.X - r, Y: center.Y - r},
+			Max: math32.Vector2{X: center.X + r, Y: center.Y + r},
+		}
+		mm.batch.DrawQuad(&uv, &rect, &icon.Color, 0)
+	}
+	mm.batch.End()
+	mm.target.Unbind()
+}
+
+// Dispose releases the OpenGL resources of this minimap.
+func (mm *Minimap) Dispose() {
+
+	mm.batch.Dispose()
+	mm.disc.Dispose()
+	mm.target.Dispose()
+}
@@ -0,0 +1,140 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ui
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// UIScrollView is an element whose children are stacked vertically with their own heights,
+// which may be taller than the view. The children are clipped to the view and scrolled
+// by the scroll events. A scroll bar is drawn when they do not fit.
+type UIScrollView struct {
+	UIBase
+	ScrollSpeed    float32 // pixels scrolled per unit of scroll event offset
+	BarWidth       float32
+	BarColor       math32.Color4
+	Background     math32.Color4
+	offset         float32 // scrolled distance from the top of the content
+	contentHeight  float32
+	viewportHeight float32
+}
+
+// NewUIScrollView creates and returns a pointer to a new empty UIScrollView which fills the space left by its siblings.
+func NewUIScrollView() *UIScrollView {
+
+	sv := new(UIScrollView)
+	sv.clip = true
+	sv.ScrollSpeed = 20
+	sv.BarWidth = 6
+	sv.BarColor = math32.Color4{R: 0.6, G: 0.6, B: 0.6, A: 1}
+	sv.Constraint = LayoutConstraint{Kind: LayoutFlex}
+	return sv
+}
+
+// Offset returns the scrolled distance in pixels from the top of the content.
+func (sv *UIScrollView) Offset() float32 {
+
+	return sv.offset
+}
+
+// ContentHeight returns the height in pixels of the content computed by the last layout.
+func (sv *UIScrollView) ContentHeight() float32 {
+
+	return sv.contentHeight
+}
+
+// ScrollTo sets the scrolled distance from the top of the content, limited to the scrollable range.
+func (sv *UIScrollView) ScrollTo(offset float32) {
+
+	sv.offset = offset
+	sv.layoutContent()
+}
+
+// maxOffset returns the largest scrolled distance, which shows the bottom of the content.
+func (sv *UIScrollView) maxOffset() float32 {
+
+	return math32.Max(sv.contentHeight-sv.viewportHeight, 0)
+}
+
+// layoutContent lays out the children of this view as a column as tall as needed, with the flex children getting
+// the height of the view, and moves them up by the scroll offset.
+func (sv *UIScrollView) layoutContent() {
+
+	view := sv.bounds
+	view.Expand(-sv.Padding)
+	sv.viewportHeight = view.Height()
+	width := view.Width()
+
+	// Content height
+	var height float32
+	for i, c := range sv.children {
+		if i > 0 {
+			height += sv.Spacing
+		}
+		height += sv.childHeight(c)
+	}
+	sv.contentHeight = height
+	sv.offset = math32.Clamp(sv.offset, 0, sv.maxOffset())
+	if sv.contentHeight > sv.viewportHeight {
+		width -= sv.BarWidth
+	}
+
+	y := view.Min.Y - sv.offset
+	for _, c := range sv.children {
+		h := sv.childHeight(c)
+		var r math32.Rect
+		r.Min.Set(view.Min.X, y)
+		r.Max.Set(view.Min.X+width, y+h)
+		if lc := &c.GetUIBase().Constraint; lc.Kind == LayoutFixed && lc.Width > 0 {
+			r.Max.X = r.Min.X + lc.Width
+		}
+		layoutElement(c, &r)
+		y += h + sv.Spacing
+	}
+}
+
+// childHeight returns the height of the specified child in the content of this view.
+func (sv *UIScrollView) childHeight(c UIElement) float32 {
+
+	lc := &c.GetUIBase().Constraint
+	if lc.Kind == LayoutFixed {
+		return lc.Height
+	}
+	return sv.viewportHeight
+}
+
+// Draw satisfies the UIElement interface.
+// Draws the scroll bar, with a thumb proportional to the visible part of the content.
+func (sv *UIScrollView) Draw(sb *SpriteBatch) {
+
+	if sv.Background.A > 0 {
+		sb.DrawRect(&sv.bounds, &sv.Background)
+	}
+	if sv.contentHeight <= sv.viewportHeight {
+		return
+	}
+	view := sv.bounds
+	view.Expand(-sv.Padding)
+	thumb := view.Height() * sv.viewportHeight / sv.contentHeight
+	top := view.Min.Y + (view.Height()-thumb)*sv.offset/sv.maxOffset()
+	var bar math32.Rect
+	bar.Min.Set(view.Max.X-sv.BarWidth, top)
+	bar.Max.Set(view.Max.X, top+thumb)
+	sb.DrawRect(&bar, &sv.BarColor)
+}
+
+// HandleEvent satisfies the UIElement interface.
+// Consumes the scroll events while the content can move in their direction.
+func (sv *UIScrollView) HandleEvent(ev *UIEvent) bool {
+
+	if ev.Type != UIScroll {
+		return false
+	}
+	// Positive offsets scroll up, towards the top of the content
+	prev := sv.offset
+	sv.ScrollTo(sv.offset - ev.Delta.Y*sv.ScrollSpeed)
+	return sv.offset != prev
+}
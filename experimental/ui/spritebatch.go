@@ -0,0 +1,277 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ui
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// Names of the attributes and uniforms of the shaders used with SpriteBatch.
+const (
+	SpritePositionAttrib    = "VertexPosition"   // vec2 screen position in pixels
+	SpriteTexcoordAttrib    = "VertexTexcoord"   // vec2 texture coordinates from the top left corner
+	SpriteColorAttrib       = "VertexColor"      // vec4 tint
+	SpriteProjectionUniform = "SpriteProjection" // mat4 from screen pixels to clip space
+	SpriteTextureUniform    = "SpriteTexture"    // sampler2D
+)
+
+// spriteVertex is a vertex of a SpriteBatch packed in 16 bytes: the position as two floats,
+// the texture coordinates as two normalized unsigned shorts and the color as four normalized bytes.
+type spriteVertex struct {
+	x, y  float32
+	u, v  uint16
+	color [4]uint8
+}
+
+// spriteVertexSize is the size in bytes of a spriteVertex.
+const spriteVertexSize = 16
+
+// SpriteBatch draws many textured and tinted quads in screen pixels with few draw calls.
+// The quads are written to a vertex buffer sent to OpenGL and drawn together when the texture changes,
+// when the buffer is full and at the end of the batch.
+// The quads can be clipped to a stack of clip rectangles.
+type SpriteBatch struct {
+	Projection math32.Matrix4 // from screen pixels to clip space, set from the viewport by Begin
+	maxQuads   int
+	verts      []spriteVertex // vertices of the quads not yet drawn
+	indices    math32.ArrayU32
+	clips      []math32.Rect
+	tex        *texture.Texture2D // texture of the quads not yet drawn
+	white      *texture.Texture2D // texture of the solid color quads
+	shader     *gls.Shader
+	gs         *gls.GLS
+	vao        uint32
+	vbo        uint32
+	ibo        uint32
+	drawCalls  int
+}
+
+// NewSpriteBatch creates and returns a pointer to a new SpriteBatch whose buffers hold the specified number of quads.
+func NewSpriteBatch(maxQuads int) *SpriteBatch {
+
+	if maxQuads <= 0 {
+		panic("NewSpriteBatch: maxQuads must be positive")
+	}
+	sb := new(SpriteBatch)
+	sb.maxQuads = maxQuads
+	sb.verts = make([]spriteVertex, 0, 4*maxQuads)
+	sb.indices = math32.NewArrayU32(0, 6*maxQuads)
+	for i := 0; i < maxQuads; i++ {
+		base := uint32(4 * i)
+		sb.indices.Append(base, base+1, base+2, base, base+2, base+3)
+	}
+	sb.white = texture.NewTexture2DFromColor(&math32.Color{R: 1, G: 1, B: 1}, 1, 1)
+	sb.white.SetGenerateMipmap(false)
+	sb.white.SetFilter(texture.FilterNearest, texture.FilterNearest)
+	return sb
+}
+
+// Begin starts a batch of quads drawn with the specified shader.
+// A nil shader discards the quads, which is useful to measure them.
+func (sb *SpriteBatch) Begin(shader *gls.Shader) {
+
+	sb.shader = shader
+	sb.verts = sb.verts[:0]
+	sb.clips = sb.clips[:0]
+	sb.tex = nil
+	sb.drawCalls = 0
+	if shader == nil {
+		return
+	}
+	gs := shader.GLS()
+	if sb.gs == nil {
+		sb.gs = gs
+		sb.vao = gs.GenVertexArray()
+		gs.BindVertexArray(sb.vao)
+		sb.vbo = gs.GenBuffer()
+		gs.BindBuffer(gls.ARRAY_BUFFER, sb.vbo)
+		gs.BufferData(gls.ARRAY_BUFFER, 4*sb.maxQuads*spriteVertexSize, nil, gls.DYNAMIC_DRAW)
+		sb.ibo = gs.GenBuffer()
+		gs.BindBuffer(gls.ELEMENT_ARRAY_BUFFER, sb.ibo)
+		gs.BufferData(gls.ELEMENT_ARRAY_BUFFER, sb.indices.Bytes(), sb.indices, gls.STATIC_DRAW)
+	}
+	// Screen pixels from the top left corner of the viewport
+	_, _, width, height := gs.GetViewport()
+	sb.Projection.MakeOrthographic(0, float32(width), 0, float32(height), -1, 1)
+}
+
+// End draws the quads not drawn yet and ends the batch.
+func (sb *SpriteBatch) End() {
+
+	sb.flush()
+	sb.shader = nil
+}
+
+// DrawCalls returns the number of draw calls of the current or last batch.
+func (sb *SpriteBatch) DrawCalls() int {
+
+	return sb.drawCalls
+}
+
+// SetTexture sets the texture of the following quads, drawing the previous quads if it changes.
+// A nil texture draws solid color quads.
+func (sb *SpriteBatch) SetTexture(tex *texture.Texture2D) {
+
+	if tex == nil {
+		tex = sb.white
+	}
+	if tex != sb.tex {
+		sb.flush()
+		sb.tex = tex
+	}
+}
+
+// PushClip restricts the following quads to the intersection of the
+// specified rectangle with the current clip rectangle.
+func (sb *SpriteBatch) PushClip(rect *math32.Rect) {
+
+	clip := *rect
+	if n := len(sb.clips); n > 0 {
+		sb.clips[n-1].IntersectionRect(rect, &clip)
+	}
+	sb.clips = append(sb.clips, clip)
+}
+
+// PopClip restores the clip rectangle before the last PushClip.
+func (sb *SpriteBatch) PopClip() {
+
+	if len(sb.clips) > 0 {
+		sb.clips = sb.clips[:len(sb.clips)-1]
+	}
+}
+
+// DrawRect draws a solid color rectangle.
+func (sb *SpriteBatch) DrawRect(rect *math32.Rect, color *math32.Color4) {
+
+	sb.Draw(rect, &math32.Rect{Max: math32.Vector2{X: 1, Y: 1}}, nil, color)
+}
+
+// Draw draws the specified texture rectangle in the specified screen rectangle with the specified tint.
+// The part outside of the current clip rectangle is cut, adjusting the texture rectangle.
+func (sb *SpriteBatch) Draw(rect, uv *math32.Rect, tex *texture.Texture2D, color *math32.Color4) {
+
+	sb.SetTexture(tex)
+	sb.DrawQuad(uv, rect, color, 0)
+}
+
+// DrawQuad draws the specified rectangle of the current texture in the specified screen rectangle rotated
+// by the specified angle in radians around its center, with the specified tint.
+// Quads without rotation are cut to the current clip rectangle, adjusting the texture rectangle.
+// Rotated quads are not clipped.
+func (sb *SpriteBatch) DrawQuad(uvRect, destRect *math32.Rect, color *math32.Color4, rotation float32) {
+
+	if destRect.Width() <= 0 || destRect.Height() <= 0 {
+		return
+	}
+	dest, uv := *destRect, *uvRect
+	if n := len(sb.clips); n > 0 && rotation == 0 {
+		destRect.IntersectionRect(&sb.clips[n-1], &dest)
+		if dest.Width() <= 0 || dest.Height() <= 0 {
+			return
+		}
+		// Maps the cut part of the screen rectangle to the texture rectangle
+		sx := uvRect.Width() / destRect.Width()
+		sy := uvRect.Height() / destRect.Height()
+		uv.Min.X = uvRect.Min.X + (dest.Min.X-destRect.Min.X)*sx
+		uv.Max.X = uvRect.Max.X - (destRect.Max.X-dest.Max.X)*sx
+		uv.Min.Y = uvRect.Min.Y + (dest.Min.Y-destRect.Min.Y)*sy
+		uv.Max.Y = uvRect.Max.Y - (destRect.Max.Y-dest.Max.Y)*sy
+	}
+	if sb.tex == nil {
+		sb.tex = sb.white
+	}
+	if len(sb.verts) == cap(sb.verts) {
+		sb.flush()
+	}
+
+	// Corners in the order top left, top right, bottom right, bottom left
+	corners := [4]math32.Vector2{
+		{X: dest.Min.X, Y: dest.Min.Y}, {X: dest.Max.X, Y: dest.Min.Y},
+		{X: dest.Max.X, Y: dest.Max.Y}, {X: dest.Min.X, Y: dest.Max.Y},
+	}
+	if rotation != 0 {
+		var center math32.Vector2
+		dest.Center(&center)
+		sin, cos := math32.Sin(rotation), math32.Cos(rotation)
+		for i := range corners {
+			dx, dy := corners[i].X-center.X, corners[i].Y-center.Y
+			corners[i].X = center.X + dx*cos - dy*sin
+			corners[i].Y = center.Y + dx*sin + dy*cos
+		}
+	}
+	u0, v0 := packUnorm16(uv.Min.X), packUnorm16(uv.Min.Y)
+	u1, v1 := packUnorm16(uv.Max.X), packUnorm16(uv.Max.Y)
+	c := [4]uint8{packUnorm8(color.R), packUnorm8(color.G), packUnorm8(color.B), packUnorm8(color.A)}
+	sb.verts = append(sb.verts,
+		spriteVertex{corners[0].X, corners[0].Y, u0, v0, c},
+		spriteVertex{corners[1].X, corners[1].Y, u1, v0, c},
+		spriteVertex{corners[2].X, corners[2].Y, u1, v1, c},
+		spriteVertex{corners[3].X, corners[3].Y, u0, v1, c},
+	)
+}
+
+// flush draws the quads not drawn yet in a single draw call.
+func (sb *SpriteBatch) flush() {
+
+	if len(sb.verts) == 0 {
+		return
+	}
+	if sb.shader == nil {
+		sb.verts = sb.verts[:0]
+		return
+	}
+	gs := sb.gs
+	sb.shader.Use()
+	gs.BindVertexArray(sb.vao)
+	gs.BindBuffer(gls.ARRAY_BUFFER, sb.vbo)
+	gs.BufferSubData(gls.ARRAY_BUFFER, 0, len(sb.verts)*spriteVertexSize, sb.verts)
+
+	// The attribute locations depend on the shader
+	prog := sb.shader.Program()
+	if loc := prog.GetAttribLocation(SpritePositionAttrib); loc >= 0 {
+		gs.EnableVertexAttribArray(uint32(loc))
+		gs.VertexAttribPointer(uint32(loc), 2, gls.FLOAT, false, spriteVertexSize, 0)
+	}
+	if loc := prog.GetAttribLocation(SpriteTexcoordAttrib); loc >= 0 {
+		gs.EnableVertexAttribArray(uint32(loc))
+		gs.VertexAttribPointer(uint32(loc), 2, gls.UNSIGNED_SHORT, true, spriteVertexSize, 8)
+	}
+	if loc := prog.GetAttribLocation(SpriteColorAttrib); loc >= 0 {
+		gs.EnableVertexAttribArray(uint32(loc))
+		gs.VertexAttribPointer(uint32(loc), 4, gls.UNSIGNED_BYTE, true, spriteVertexSize, 12)
+	}
+
+	sb.shader.SetUniformMat4(SpriteProjectionUniform, &sb.Projection)
+	sb.tex.Bind(gs, 0)
+	sb.shader.SetUniformInt(SpriteTextureUniform, 0)
+	gs.DrawElements(gls.TRIANGLES, int32(len(sb.verts)/4*6), gls.UNSIGNED_INT, 0)
+	sb.drawCalls++
+	sb.verts = sb.verts[:0]
+}
+
+// Dispose releases the OpenGL resources of this batch.
+func (sb *SpriteBatch) Dispose() {
+
+	if sb.gs != nil {
+		sb.gs.DeleteVertexArrays(sb.vao)
+		sb.gs.DeleteBuffers(sb.vbo, sb.ibo)
+		sb.gs = nil
+	}
+	sb.white.Dispose()
+}
+
+// packUnorm16 returns the specified value in [0,1] as a normalized unsigned short.
+func packUnorm16(v float32) uint16 {
+
+	return uint16(math32.Clamp(v, 0, 1)*0xFFFF + 0.5)
+}
+
+// packUnorm8 returns the specified value in [0,1] as a normalized unsigned byte.
+func packUnorm8(v float32) uint8 {
+
+	return uint8(math32.Clamp(v, 0, 1)*0xFF + 0.5)
+}
@@ -0,0 +1,215 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ui
+
+import (
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// Font is the interface for the fonts of the UI text, satisfied by text.SDFFont.
+type Font interface {
+	// GlyphQuad returns the quad of the glyph of the character relative to its origin on the baseline with Y up,
+	// its UV rectangle in the texture from its top left corner, its advance and if the font has the glyph.
+	GlyphQuad(r rune) (quad, uvRect math32.Rect, advance float32, ok bool)
+	// Kern returns the adjustment of the advance between two consecutive characters.
+	Kern(r0, r1 rune) float32
+	// LineHeight returns the vertical distance between two lines.
+	LineHeight() float32
+	// Ascent returns the distance from the top of a line to its baseline.
+	Ascent() float32
+	// Texture returns the texture with the glyphs.
+	Texture() *texture.Texture2D
+}
+
+// TextAlign specifies the horizontal alignment of the text of a UILabel.
+type TextAlign int
+
+// Text alignments
+const (
+	TextLeft = TextAlign(iota)
+	TextCenter
+	TextRight
+)
+
+// textWidth returns the width in pixels of the specified text with the specified font and scale.
+func textWidth(font Font, text string, scale float32) float32 {
+
+	var w float32
+	prev := rune(-1)
+	for _, r := range text {
+		_, _, advance, ok := font.GlyphQuad(r)
+		if !ok {
+			continue
+		}
+		if prev >= 0 {
+			w += font.Kern(prev, r)
+		}
+		w += advance
+		prev = r
+	}
+	return w * scale
+}
+
+// drawText draws the specified single line text vertically centered in the specified rectangle.
+func drawText(sb *SpriteBatch, font Font, text string, scale float32, rect *math32.Rect, align TextAlign, color *math32.Color4) {
+
+	x := rect.Min.X
+	switch align {
+	case TextCenter:
+		x += (rect.Width() - textWidth(font, text, scale)) / 2
+	case TextRight:
+		x += rect.Width() - textWidth(font, text, scale)
+	}
+	baseline := rect.Min.Y + (rect.Height()-font.LineHeight()*scale)/2 + font.Ascent()*scale
+	tex := font.Texture()
+	prev := rune(-1)
+	for _, r := range text {
+		quad, uv, advance, ok := font.GlyphQuad(r)
+		if !ok {
+			continue
+		}
+		if prev >= 0 {
+			x += font.Kern(prev, r) * scale
+		}
+		// The glyph quads have the Y axis up and the screen has it down
+		var gr math32.Rect
+		gr.Min.Set(x+quad.Min.X*scale, baseline-quad.Max.Y*scale)
+		gr.Max.Set(x+quad.Max.X*scale, baseline-quad.Min.Y*scale)
+		sb.Draw(&gr, &uv, tex, color)
+		x += advance * scale
+		prev = r
+	}
+}
+
+// UILabel is an element which draws a single line of text.
+type UILabel struct {
+	UIBase
+	Text  string
+	Font  Font
+	Scale float32 // size of one pixel of the font size
+	Align TextAlign
+	Color math32.Color4
+}
+
+// NewUILabel creates and returns a pointer to a new UILabel with the specified
+// text and font and a fixed height of one line.
+func NewUILabel(text string, font Font) *UILabel {
+
+	l := new(UILabel)
+	l.Text = text
+	l.Font = font
+	l.Scale = 1
+	l.Color = math32.Color4{R: 1, G: 1, B: 1, A: 1}
+	l.Constraint = LayoutConstraint{Kind: LayoutFixed, Height: font.LineHeight()}
+	return l
+}
+
+// Draw satisfies the UIElement interface.
+func (l *UILabel) Draw(sb *SpriteBatch) {
+
+	drawText(sb, l.Font, l.Text, l.Scale, &l.bounds, l.Align, &l.Color)
+}
+
+// HandleEvent satisfies the UIElement interface. Labels consume no event.
+func (l *UILabel) HandleEvent(ev *UIEvent) bool {
+
+	return false
+}
+
+// UIButton is an element with a rectangular background and a centered label,
+// which calls its OnClick callback when clicked.
+type UIButton struct {
+	UIBase
+	Label        UILabel
+	Color        math32.Color4 // background color
+	PressedColor math32.Color4 // background color while pressed
+	OnClick      func(b *UIButton)
+	pressed      bool
+}
+
+// NewUIButton creates and returns a pointer to a new UIButton with the specified text and font.
+func NewUIButton(text string, font Font) *UIButton {
+
+	b := new(UIButton)
+	b.Label = *NewUILabel(text, font)
+	b.Label.Align = TextCenter
+	b.Color = math32.Color4{R: 0.3, G: 0.3, B: 0.3, A: 1}
+	b.PressedColor = math32.Color4{R: 0.2, G: 0.2, B: 0.2, A: 1}
+	b.Constraint = LayoutConstraint{Kind: LayoutFixed, Height: 1.5 * font.LineHeight()}
+	return b
+}
+
+// Pressed returns if the mouse button was pressed over this button and not released yet.
+func (b *UIButton) Pressed() bool {
+
+	return b.pressed
+}
+
+// Draw satisfies the UIElement interface.
+func (b *UIButton) Draw(sb *SpriteBatch) {
+
+	color := &b.Color
+	if b.pressed {
+		color = &b.PressedColor
+	}
+	sb.DrawRect(&b.bounds, color)
+	b.Label.bounds = b.bounds
+	b.Label.Draw(sb)
+}
+
+// HandleEvent satisfies the UIElement interface.
+// The button is clicked when the mouse button is pressed and released over it.
+func (b *UIButton) HandleEvent(ev *UIEvent) bool {
+
+	switch ev.Type {
+	case UIMouseDown:
+		b.pressed = true
+		return true
+	case UIMouseUp:
+		clicked := b.pressed && b.bounds.ContainsPoint(&ev.Pos)
+		b.pressed = false
+		if clicked && b.OnClick != nil {
+			b.OnClick(b)
+		}
+		return clicked
+	case UIMouseMove:
+		if b.pressed && !b.bounds.ContainsPoint(&ev.Pos) {
+			b.pressed = false
+		}
+	}
+	return false
+}
+
+// UIImage is an element which draws a texture stretched over its bounds.
+type UIImage struct {
+	UIBase
+	Texture *texture.Texture2D
+	UV      math32.Rect // texture rectangle from the top left corner
+	Color   math32.Color4
+}
+
+// NewUIImage creates and returns a pointer to a new UIImage with the whole specified texture and the specified fixed size.
+func NewUIImage(tex *texture.Texture2D, width, height float32) *UIImage {
+
+	img := new(UIImage)
+	img.Texture = tex
+	img.UV.Max.Set(1, 1)
+	img.Color = math32.Color4{R: 1, G: 1, B: 1, A: 1}
+	img.Constraint = LayoutConstraint{Kind: LayoutFixed, Width: width, Height: height}
+	return img
+}
+
+// Draw satisfies the UIElement interface.
+func (img *UIImage) Draw(sb *SpriteBatch) {
+
+	sb.Draw(&img.bounds, &img.UV, img.Texture, &img.Color)
+}
+
+// HandleEvent satisfies the UIElement interface. Images consume no event.
+func (img *UIImage) HandleEvent(ev *UIEvent) bool {
+
+	return false
+}
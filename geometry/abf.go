@@ -0,0 +1,464 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/g3n/engine/math32"
+)
+
+// abfMaxIterations is the maximum number of Newton iterations of the angle optimization of UnwrapABF.
+const abfMaxIterations = 50
+
+// abfTolerance is the largest residual of the angle constraints and of the gradient at convergence.
+const abfTolerance = 1e-9
+
+// abfMinAngle is the smallest angle in radians kept by the Newton iterations.
+const abfMinAngle = 1e-4
+
+// abfEntry is a non-zero element of a row of the Jacobian of the angle constraints.
+type abfEntry struct {
+	angle int // index of the angle, 3 per triangle
+	value float64
+}
+
+// abfMesh is a triangle mesh being flattened by UnwrapABF.
+type abfMesh struct {
+	positions []math32.Vector3
+	indices   []uint32
+	used      []bool    // if each vertex is referenced by a triangle
+	interior  []bool    // if each vertex is referenced and not on the boundary
+	corners   [][]int   // angle indices of the corners of the triangles around each interior vertex
+	beta      []float64 // optimal angles
+	weight    []float64 // weights of the angle deviations in the energy
+	alpha     []float64 // current angles
+}
+
+// UnwrapABF returns the texture coordinates of the vertices of the specified geometry flattened with little
+// distortion by the Angle Based Flattening of Sheffer and de Sturler, scaled into [0,1] keeping their aspect ratio,
+// and sets them as its texture coordinates. The angles of the flattened triangles are the closest to their angles
+// in 3D which form a valid planar triangulation, found by Newton iterations solving for the Lagrange multipliers
+// of the constraints as in ABF++, and the positions are reconstructed from the angles by least squares.
+// Returns an error if the mesh is not a topological disk: a connected manifold surface with one boundary
+// and without handles, whose triangles are consistently oriented and not degenerate. Closed meshes must be
+// cut first, which vertices duplicated at the seams of the attributes usually do.
+func UnwrapABF(geom *Geometry) ([]math32.Vector2, error) {
+
+	positions, indices := meshTriangles(geom)
+	m := &abfMesh{positions: positions, indices: indices}
+	if err := m.init(); err != nil {
+		return nil, err
+	}
+	m.solveAngles()
+	uvs := m.reconstruct()
+	normalizeUVs(uvs)
+	setTexcoords(geom, uvs)
+	return uvs, nil
+}
+
+// init checks the topology of the mesh and computes its optimal angles.
+func (m *abfMesh) init() error {
+
+	nv := len(m.positions)
+	nf := len(m.indices) / 3
+	if nf == 0 {
+		return errors.New("geometry has no triangles")
+	}
+
+	// Edges, whose directed versions must be unique for consistently oriented manifold triangles
+	type edge struct{ a, b uint32 }
+	directed := make(map[edge]bool)
+	undirected := make(map[edge]int)
+	used := make([]bool, nv)
+	parent := make([]int, nv)
+	for i := range parent {
+		parent[i] = i
+	}
+	find := func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+	for t := 0; t < nf; t++ {
+		for k := 0; k < 3; k++ {
+			a, b := m.indices[3*t+k], m.indices[3*t+(k+1)%3]
+			if int(a) >= nv {
+				return fmt.Errorf("invalid vertex index:%d", a)
+			}
+			if directed[edge{a, b}] {
+				return fmt.Errorf("non-manifold or inconsistently oriented edge at triangle:%d", t)
+			}
+			directed[edge{a, b}] = true
+			used[a] = true
+			if a > b {
+				a, b = b, a
+			}
+			undirected[edge{a, b}]++
+			parent[find(int(a))] = find(int(b))
+		}
+	}
+	m.used = used
+	m.interior = make([]bool, nv)
+	var usedCount, components int
+	for i := range used {
+		if used[i] {
+			usedCount++
+			m.interior[i] = true
+			if find(i) == i {
+				components++
+			}
+		}
+	}
+	boundary := 0
+	for e, count := range undirected {
+		if count == 1 {
+			m.interior[e.a] = false
+			m.interior[e.b] = false
+			boundary++
+		}
+	}
+	if components != 1 {
+		return fmt.Errorf("invalid number of connected components:%d", components)
+	}
+	if boundary == 0 || usedCount-len(undirected)+nf != 1 {
+		return errors.New("geometry is not a topological disk")
+	}
+
+	// Angles of the triangles in 3D
+	m.beta = make([]float64, 3*nf)
+	m.weight = make([]float64, 3*nf)
+	m.corners = make([][]int, nv)
+	sums := make([]float64, nv)
+	for t := 0; t < nf; t++ {
+		for k := 0; k < 3; k++ {
+			p := &m.positions[m.indices[3*t+k]]
+			var e1, e2 math32.Vector3
+			e1.SubVectors(&m.positions[m.indices[3*t+(k+1)%3]], p)
+			e2.SubVectors(&m.positions[m.indices[3*t+(k+2)%3]], p)
+			l1, l2 := float64(e1.Length()), float64(e2.Length())
+			if l1 == 0 || l2 == 0 {
+				return fmt.Errorf("invalid degenerate triangle:%d", t)
+			}
+			angle := math.Acos(math.Max(-1, math.Min(1, float64(e1.Dot(&e2))/(l1*l2))))
+			if angle < abfMinAngle || angle > math.Pi-2*abfMinAngle {
+				return fmt.Errorf("invalid degenerate triangle:%d", t)
+			}
+			m.beta[3*t+k] = angle
+			v := m.indices[3*t+k]
+			sums[v] += angle
+			if m.interior[v] {
+				m.corners[v] = append(m.corners[v], 3*t+k)
+			}
+		}
+	}
+	// The angles around the interior vertices are scaled to sum to a full turn
+	for t := 0; t < nf; t++ {
+		for k := 0; k < 3; k++ {
+			if v := m.indices[3*t+k]; m.interior[v] {
+				m.beta[3*t+k] *= 2 * math.Pi / sums[v]
+			}
+			m.weight[3*t+k] = 1 / (m.beta[3*t+k] * m.beta[3*t+k])
+		}
+	}
+	m.alpha = append([]float64(nil), m.beta...)
+	return nil
+}
+
+// constraints returns the rows of the Jacobian and the residuals of the constraints at the current angles:
+// the sum of the angles of each triangle is pi, the sum of the angles around each interior vertex is 2*pi
+// and, for the lengths of the edges around each interior vertex to be consistent by the law of sines,
+// the product of the sines of the angles following the vertex equals the product of the sines preceding it.
+func (m *abfMesh) constraints() ([][]abfEntry, []float64) {
+
+	var rows [][]abfEntry
+	var res []float64
+	for t := 0; t < len(m.alpha)/3; t++ {
+		rows = append(rows, []abfEntry{{3 * t, 1}, {3*t + 1, 1}, {3*t + 2, 1}})
+		res = append(res, m.alpha[3*t]+m.alpha[3*t+1]+m.alpha[3*t+2]-math.Pi)
+	}
+	for v := range m.corners {
+		if !m.interior[v] {
+			continue
+		}
+		planar := make([]abfEntry, 0, len(m.corners[v]))
+		wheel := make([]abfEntry, 0, 2*len(m.corners[v]))
+		var sum, logSines float64
+		for _, c := range m.corners[v] {
+			t, k := c/3, c%3
+			next, prev := 3*t+(k+1)%3, 3*t+(k+2)%3
+			planar = append(planar, abfEntry{c, 1})
+			sum += m.alpha[c]
+			wheel = append(wheel, abfEntry{next, 1 / math.Tan(m.alpha[next])}, abfEntry{prev, -1 / math.Tan(m.alpha[prev])})
+			logSines += math.Log(math.Sin(m.alpha[next])) - math.Log(math.Sin(m.alpha[prev]))
+		}
+		rows = append(rows, planar, wheel)
+		res = append(res, sum-2*math.Pi, logSines)
+	}
+	return rows, res
+}
+
+// solveAngles minimizes the weighted squared deviations of the angles from their optimal values subject to the
+// constraints. Each Newton iteration approximates the Hessian of the Lagrangian by the diagonal Hessian of the
+// energy and solves the Schur complement system of the multipliers by conjugate gradients.
+func (m *abfMesh) solveAngles() {
+
+	n := len(m.alpha)
+	var lambda []float64
+	grad := make([]float64, n)
+	hinv := make([]float64, n)
+	tmp := make([]float64, n)
+	for i := range hinv {
+		hinv[i] = 1 / (2 * m.weight[i])
+	}
+	for iter := 0; iter < abfMaxIterations; iter++ {
+		rows, res := m.constraints()
+		if lambda == nil {
+			lambda = make([]float64, len(rows))
+		}
+
+		// Gradient of the Lagrangian relative to the angles
+		for i := range grad {
+			grad[i] = 2 * m.weight[i] * (m.alpha[i] - m.beta[i])
+		}
+		for r, row := range rows {
+			for _, e := range row {
+				grad[e.angle] += lambda[r] * e.value
+			}
+		}
+		var residual float64
+		for i := range grad {
+			residual = math.Max(residual, math.Abs(grad[i]))
+		}
+		for r := range res {
+			residual = math.Max(residual, math.Abs(res[r]))
+		}
+		if residual < abfTolerance {
+			break
+		}
+
+		// J H^-1 J^T dlambda = c - J H^-1 g
+		rhs := make([]float64, len(rows))
+		for r, row := range rows {
+			rhs[r] = res[r]
+			for _, e := range row {
+				rhs[r] -= e.value * hinv[e.angle] * grad[e.angle]
+			}
+		}
+		dlambda := make([]float64, len(rows))
+		conjugateGradient(func(x, y []float64) {
+			for i := range tmp {
+				tmp[i] = 0
+			}
+			for r, row := range rows {
+				for _, e := range row {
+					tmp[e.angle] += e.value * x[r]
+				}
+			}
+			for r, row := range rows {
+				y[r] = 0
+				for _, e := range row {
+					y[r] += e.value * hinv[e.angle] * tmp[e.angle]
+				}
+			}
+		}, rhs, dlambda, 10*len(rows))
+
+		// dalpha = -H^-1 (g + J^T dlambda)
+		copy(tmp, grad)
+		for r, row := range rows {
+			lambda[r] += dlambda[r]
+			for _, e := range row {
+				tmp[e.angle] += e.value * dlambda[r]
+			}
+		}
+		for i := range m.alpha {
+			m.alpha[i] = math.Max(abfMinAngle, math.Min(math.Pi-abfMinAngle, m.alpha[i]-hinv[i]*tmp[i]))
+		}
+	}
+}
+
+// reconstruct returns the planar positions of the vertices best matching the angles, minimizing for each
+// triangle the squared difference between an edge and the edge rotated and scaled from the previous one
+// by its angles. The largest angle of each triangle is opposite to the predicted edge, which avoids
+// dividing by small sines. Two boundary vertices far apart are fixed to remove the similarity transforms,
+// then the positions are rotated to align their principal axis with U.
+func (m *abfMesh) reconstruct() []math32.Vector2 {
+
+	nv := len(m.positions)
+	nf := len(m.indices) / 3
+
+	// Fixed vertices
+	pin0 := -1
+	for v := range m.interior {
+		if m.used[v] && !m.interior[v] {
+			pin0 = v
+			break
+		}
+	}
+	pin1, dist := -1, float32(-1)
+	for t := 0; t < nf; t++ {
+		for k := 0; k < 3; k++ {
+			v := int(m.indices[3*t+k])
+			if d := m.positions[v].DistanceTo(&m.positions[pin0]); !m.interior[v] && d > dist {
+				pin1, dist = v, d
+			}
+		}
+	}
+	fixed := map[int][2]float64{pin0: {0, 0}, pin1: {float64(dist), 0}}
+	vars := make([]int, nv) // index of the unknowns of each vertex or -1
+	nvars := 0
+	for v := range vars {
+		if _, ok := fixed[v]; ok {
+			vars[v] = -1
+			continue
+		}
+		vars[v] = nvars
+		nvars++
+	}
+
+	// Residual of each triangle as the sum of 2x2 matrices times the positions of its vertices
+	type term struct {
+		vertex int
+		m      [4]float64 // row major
+	}
+	terms := make([][3]term, nf)
+	for t := 0; t < nf; t++ {
+		c := 0
+		for k := 1; k < 3; k++ {
+			if m.alpha[3*t+k] > m.alpha[3*t+c] {
+				c = k
+			}
+		}
+		a, b := (c+1)%3, (c+2)%3
+		s := math.Sin(m.alpha[3*t+b]) / math.Sin(m.alpha[3*t+c])
+		sin, cos := math.Sincos(m.alpha[3*t+a])
+		sr := [4]float64{s * cos, -s * sin, s * sin, s * cos}
+		terms[t] = [3]term{
+			{int(m.indices[3*t+c]), [4]float64{1, 0, 0, 1}},
+			{int(m.indices[3*t+a]), [4]float64{sr[0] - 1, sr[1], sr[2], sr[3] - 1}},
+			{int(m.indices[3*t+b]), [4]float64{-sr[0], -sr[1], -sr[2], -sr[3]}},
+		}
+	}
+
+	// Normal equations A^T A x = -A^T A_fixed x_fixed
+	rhs := make([]float64, 2*nvars)
+	for t := range terms {
+		var r [2]float64
+		for _, tm := range terms[t] {
+			if p, ok := fixed[tm.vertex]; ok {
+				r[0] += tm.m[0]*p[0] + tm.m[1]*p[1]
+				r[1] += tm.m[2]*p[0] + tm.m[3]*p[1]
+			}
+		}
+		for _, tm := range terms[t] {
+			if j := vars[tm.vertex]; j >= 0 {
+				rhs[2*j] -= tm.m[0]*r[0] + tm.m[2]*r[1]
+				rhs[2*j+1] -= tm.m[1]*r[0] + tm.m[3]*r[1]
+			}
+		}
+	}
+	x := make([]float64, 2*nvars)
+	conjugateGradient(func(x, y []float64) {
+		for i := range y {
+			y[i] = 0
+		}
+		for t := range terms {
+			var r [2]float64
+			for _, tm := range terms[t] {
+				if j := vars[tm.vertex]; j >= 0 {
+					r[0] += tm.m[0]*x[2*j] + tm.m[1]*x[2*j+1]
+					r[1] += tm.m[2]*x[2*j] + tm.m[3]*x[2*j+1]
+				}
+			}
+			for _, tm := range terms[t] {
+				if j := vars[tm.vertex]; j >= 0 {
+					y[2*j] += tm.m[0]*r[0] + tm.m[2]*r[1]
+					y[2*j+1] += tm.m[1]*r[0] + tm.m[3]*r[1]
+				}
+			}
+		}
+	}, rhs, x, 10*len(x))
+
+	pts := make([][2]float64, nv)
+	var mean [2]float64
+	var count float64
+	for v := range pts {
+		if p, ok := fixed[v]; ok {
+			pts[v] = p
+		} else if j := vars[v]; m.used[v] {
+			pts[v] = [2]float64{x[2*j], x[2*j+1]}
+		} else {
+			continue
+		}
+		mean[0] += pts[v][0]
+		mean[1] += pts[v][1]
+		count++
+	}
+	mean[0] /= count
+	mean[1] /= count
+
+	// Rotates the principal axis of the positions along U, instead of the fixed vertices
+	var cxx, cyy, cxy float64
+	for v := range pts {
+		if m.used[v] {
+			dx, dy := pts[v][0]-mean[0], pts[v][1]-mean[1]
+			cxx += dx * dx
+			cyy += dy * dy
+			cxy += dx * dy
+		}
+	}
+	sin, cos := math.Sincos(-math.Atan2(2*cxy, cxx-cyy) / 2)
+	uvs := make([]math32.Vector2, nv)
+	for v := range uvs {
+		if m.used[v] {
+			uvs[v].Set(float32(pts[v][0]*cos-pts[v][1]*sin), float32(pts[v][0]*sin+pts[v][1]*cos))
+		}
+	}
+	return uvs
+}
+
+// conjugateGradient solves the symmetric positive semi-definite system whose product by a vector is computed
+// by apply, starting from and updating x, for at most the specified number of iterations.
+func conjugateGradient(apply func(x, y []float64), b, x []float64, maxIter int) {
+
+	n := len(b)
+	r := make([]float64, n)
+	p := make([]float64, n)
+	ap := make([]float64, n)
+	apply(x, ap)
+	var rr, bb float64
+	for i := range r {
+		r[i] = b[i] - ap[i]
+		p[i] = r[i]
+		rr += r[i] * r[i]
+		bb += b[i] * b[i]
+	}
+	tol := 1e-24 * math.Max(bb, 1e-30)
+	for iter := 0; iter < maxIter && rr > tol; iter++ {
+		apply(p, ap)
+		var pap float64
+		for i := range p {
+			pap += p[i] * ap[i]
+		}
+		if pap <= 0 {
+			return
+		}
+		alpha := rr / pap
+		var rrNew float64
+		for i := range x {
+			x[i] += alpha * p[i]
+			r[i] -= alpha * ap[i]
+			rrNew += r[i] * r[i]
+		}
+		for i := range p {
+			p[i] = r[i] + rrNew/rr*p[i]
+		}
+		rr = rrNew
+	}
+}
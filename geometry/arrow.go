@@ -0,0 +1,209 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// arrowSegments is the number of radial segments of the shaft and head of an Arrow3D.
+const arrowSegments = 16
+
+// arrowHeadScale is the ratio between the radius of the head and the radius of the shaft of an Arrow3D.
+const arrowHeadScale = 2.5
+
+// Arrow3D is a geometry formed by a cylinder shaft capped with a cone head,
+// used to visualize directions such as velocities, forces and normals.
+// The arrow starts at its origin and its tip is at origin + normalize(direction)*length.
+type Arrow3D struct {
+	Geometry
+	Length      float32          // total length including the head
+	HeadRatio   float32          // fraction of the length occupied by the head
+	ShaftRadius float32          // radius of the shaft
+	origin      math32.Vector3   // start of the arrow
+	direction   math32.Vector3   // unit direction of the arrow
+	local       []math32.Vector3 // vertex positions of the arrow pointing along +Y from the origin
+	localNorm   []math32.Vector3 // vertex normals of the arrow pointing along +Y
+	positions   *gls.VBO
+	normals     *gls.VBO
+}
+
+// NewArrow3D creates and returns a pointer to a new Arrow3D geometry starting at the specified origin
+// and pointing in the specified direction, with the specified total length, head length ratio and shaft radius.
+// The head is a cone of length headRatio*length.
+func NewArrow3D(origin, direction *math32.Vector3, length, headRatio, shaftRadius float32) *Arrow3D {
+
+	a := new(Arrow3D)
+	a.Geometry.Init()
+	a.Length = length
+	a.HeadRatio = math32.Clamp(headRatio, 0, 1)
+	a.ShaftRadius = shaftRadius
+	a.origin = *origin
+
+	headLen := a.HeadRatio * length
+	shaftLen := length - headLen
+	headRadius := shaftRadius * arrowHeadScale
+
+	uvs := math32.NewArrayF32(0, 0)
+	indices := math32.NewArrayU32(0, 0)
+	add := func(x, y, z, nx, ny, nz, u, v float32) uint32 {
+		a.local = append(a.local, math32.Vector3{X: x, Y: y, Z: z})
+		a.localNorm = append(a.localNorm, math32.Vector3{X: nx, Y: ny, Z: nz})
+		uvs.Append(u, v)
+		return uint32(len(a.local) - 1)
+	}
+
+	// Disk facing -Y or +Y
+	disk := func(y, radius, ny float32) {
+		center := add(0, y, 0, 0, ny, 0, 0.5, 0.5)
+		for i := 0; i <= arrowSegments; i++ {
+			angle := 2 * math32.Pi * float32(i) / arrowSegments
+			s, c := math32.Sin(angle), math32.Cos(angle)
+			add(radius*s, y, radius*c, 0, ny, 0, 0.5+s/2, 0.5+c/2)
+			if i > 0 {
+				idx := uint32(len(a.local) - 1)
+				if ny < 0 {
+					indices.Append(center, idx, idx-1)
+				} else {
+					indices.Append(center, idx-1, idx)
+				}
+			}
+		}
+	}
+
+	// Base of the head around the top of the shaft facing -Y
+	annulus := func(y, inner, outer float32) {
+		for i := 0; i <= arrowSegments; i++ {
+			angle := 2 * math32.Pi * float32(i) / arrowSegments
+			s, c := math32.Sin(angle), math32.Cos(angle)
+			k := inner / outer
+			add(inner*s, y, inner*c, 0, -1, 0, 0.5+k*s/2, 0.5+k*c/2)
+			add(outer*s, y, outer*c, 0, -1, 0, 0.5+s/2, 0.5+c/2)
+			if i > 0 {
+				i0 := uint32(len(a.local) - 4)
+				indices.Append(i0, i0+2, i0+3, i0, i0+3, i0+1)
+			}
+		}
+	}
+
+	// The caps close the mesh: the bottom of the shaft, the base of the head
+	// joined to the top of the shaft and the top of the shaft without head
+	if shaftLen > 0 {
+		disk(0, shaftRadius, -1)
+	}
+	if headLen > 0 && shaftLen > 0 {
+		annulus(shaftLen, shaftRadius, headRadius)
+	} else if headLen > 0 {
+		disk(0, headRadius, -1)
+	} else {
+		disk(shaftLen, shaftRadius, 1)
+	}
+
+	// Shaft side
+	if shaftLen > 0 {
+		for i := 0; i <= arrowSegments; i++ {
+			u := float32(i) / arrowSegments
+			angle := 2 * math32.Pi * u
+			s, c := math32.Sin(angle), math32.Cos(angle)
+			add(shaftRadius*s, 0, shaftRadius*c, s, 0, c, u, 0)
+			add(shaftRadius*s, shaftLen, shaftRadius*c, s, 0, c, u, shaftLen/length)
+			if i > 0 {
+				i0 := uint32(len(a.local) - 4)
+				indices.Append(i0, i0+2, i0+3, i0, i0+3, i0+1)
+			}
+		}
+	}
+
+	// Head side with a tip vertex per segment to keep smooth normals
+	if headLen > 0 {
+		ny := headRadius / math32.Sqrt(headRadius*headRadius+headLen*headLen)
+		nr := headLen / math32.Sqrt(headRadius*headRadius+headLen*headLen)
+		var prev uint32
+		for i := 0; i <= arrowSegments; i++ {
+			u := float32(i) / arrowSegments
+			angle := 2 * math32.Pi * u
+			s, c := math32.Sin(angle), math32.Cos(angle)
+			base := add(headRadius*s, shaftLen, headRadius*c, nr*s, ny, nr*c, u, shaftLen/length)
+			if i > 0 {
+				// Tip normal at the middle of the segment
+				mid := 2 * math32.Pi * (float32(i) - 0.5) / arrowSegments
+				ms, mc := math32.Sin(mid), math32.Cos(mid)
+				tip := add(0, length, 0, nr*ms, ny, nr*mc, u, 1)
+				indices.Append(prev, base, tip)
+			}
+			prev = base
+		}
+	}
+
+	positions := math32.NewArrayF32(len(a.local)*3, len(a.local)*3)
+	normals := math32.NewArrayF32(len(a.local)*3, len(a.local)*3)
+	a.SetIndices(indices)
+	a.positions = gls.NewVBO(positions).AddAttrib(gls.VertexPosition)
+	a.normals = gls.NewVBO(normals).AddAttrib(gls.VertexNormal)
+	a.AddVBO(a.positions)
+	a.AddVBO(a.normals)
+	a.AddVBO(gls.NewVBO(uvs).AddAttrib(gls.VertexTexcoord))
+	a.UpdateDirection(direction)
+	return a
+}
+
+// Origin returns the start of the arrow.
+func (a *Arrow3D) Origin() math32.Vector3 {
+
+	return a.origin
+}
+
+// Direction returns the unit direction of the arrow.
+func (a *Arrow3D) Direction() math32.Vector3 {
+
+	return a.direction
+}
+
+// SetOrigin moves the start of the arrow to the specified position updating the vertex positions in place.
+func (a *Arrow3D) SetOrigin(origin *math32.Vector3) {
+
+	a.origin = *origin
+	a.UpdateDirection(&a.direction)
+}
+
+// UpdateDirection orients the arrow in the specified direction updating the
+// vertex positions and normals in place without reallocating the vertex buffers.
+// A zero direction keeps the previous direction, or +Y for a new arrow.
+func (a *Arrow3D) UpdateDirection(direction *math32.Vector3) {
+
+	if direction.LengthSq() > 0 {
+		a.direction = *direction
+		a.direction.Normalize()
+	} else if a.direction.LengthSq() == 0 {
+		a.direction.Set(0, 1, 0)
+	}
+
+	var q math32.Quaternion
+	q.SetFromUnitVectors(&math32.Vector3{X: 0, Y: 1, Z: 0}, &a.direction)
+	positions := a.positions.Buffer()
+	normals := a.normals.Buffer()
+	for i := range a.local {
+		p := a.local[i]
+		p.ApplyQuaternion(&q).Add(&a.origin)
+		positions.SetVector3(i*3, &p)
+		n := a.localNorm[i]
+		n.ApplyQuaternion(&q)
+		normals.SetVector3(i*3, &n)
+	}
+	a.positions.Update()
+	a.normals.Update()
+	a.boundingBoxValid = false
+	a.boundingSphereValid = false
+	a.rotInertiaValid = false
+}
+
+// Tip returns the position of the tip of the arrow.
+func (a *Arrow3D) Tip() math32.Vector3 {
+
+	tip := a.direction
+	tip.MultiplyScalar(a.Length).Add(&a.origin)
+	return tip
+}
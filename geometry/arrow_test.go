@@ -0,0 +1,95 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+// Test that the tip of the arrow is at origin + normalize(direction)*length after each update of the direction
+func TestArrow3DTip(t *testing.T) {
+
+	origin := math32.Vector3{X: 1, Y: 2, Z: 3}
+	a := NewArrow3D(&origin, &math32.Vector3{X: 1, Y: 1}, 2, 0.25, 0.05)
+	buffer := &(*a.positions.Buffer())[0]
+	for _, dir := range []math32.Vector3{{X: 1, Y: 1}, {Y: -1}, {Y: 1}, {Z: 2}, {X: -1, Y: 0.3, Z: 0.2}} {
+		a.UpdateDirection(&dir)
+		expected := dir
+		expected.Normalize().MultiplyScalar(2).Add(&origin)
+		if tip := a.Tip(); tip.DistanceTo(&expected) > 1e-5 {
+			t.Error("Tip", tip, "instead of", expected, "for the direction", dir)
+		}
+		// The farthest vertex from the origin is the tip
+		farthest := origin
+		a.ReadVertices(func(v math32.Vector3) bool {
+			if v.DistanceTo(&origin) > farthest.DistanceTo(&origin) {
+				farthest = v
+			}
+			return false
+		})
+		if farthest.DistanceTo(&expected) > 1e-5 {
+			t.Error("tip vertex", farthest, "instead of", expected, "for the direction", dir)
+		}
+	}
+	if &(*a.positions.Buffer())[0] != buffer {
+		t.Error("UpdateDirection reallocated the positions")
+	}
+}
+
+// Test that the mesh is closed and oriented outwards, with and without shaft and head
+func TestArrow3DClosed(t *testing.T) {
+
+	const length, radius = 2, 0.1
+	for _, headRatio := range []float32{0.25, 1, 0} {
+		origin := math32.Vector3{X: -1, Y: 0.5}
+		a := NewArrow3D(&origin, &math32.Vector3{X: 1, Y: -2, Z: 0.5}, length, headRatio, radius)
+
+		// Each edge between welded positions must be used once in each direction
+		type key [3]int32
+		weld := func(v math32.Vector3) key {
+			return key{int32(math32.Round(v.X * 1e4)), int32(math32.Round(v.Y * 1e4)), int32(math32.Round(v.Z * 1e4))}
+		}
+		edges := make(map[[2]key]int)
+		var volume float32
+		a.ReadFaces(func(va, vb, vc math32.Vector3) bool {
+			ka, kb, kc := weld(va), weld(vb), weld(vc)
+			if ka == kb || kb == kc || kc == ka {
+				return false
+			}
+			for _, e := range [][2]key{{ka, kb}, {kb, kc}, {kc, ka}} {
+				edges[e]++
+				edges[[2]key{e[1], e[0]}]--
+			}
+			// Signed volume of the tetrahedron with the origin
+			va.Sub(&origin)
+			vb.Sub(&origin)
+			vc.Sub(&origin)
+			var n math32.Vector3
+			n.CrossVectors(&vb, &vc)
+			volume += va.Dot(&n) / 6
+			return false
+		})
+		open := 0
+		for _, count := range edges {
+			if count != 0 {
+				open++
+			}
+		}
+		if open > 0 {
+			t.Error(open/2, "open edges with the head ratio", headRatio)
+		}
+
+		// Volume of the prism shaft and of the pyramid head with arrowSegments sides
+		base := arrowSegments / 2 * math32.Sin(2*math32.Pi/arrowSegments)
+		headLen := headRatio * length
+		headRadius := float32(radius * arrowHeadScale)
+		expected := base*radius*radius*(length-headLen) + base*headRadius*headRadius*headLen/3
+		if math32.Abs(volume-expected) > 1e-4*expected {
+			t.Error("volume", volume, "instead of", expected, "with the head ratio", headRatio)
+		}
+	}
+}
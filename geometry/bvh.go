@@ -0,0 +1,160 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"sort"
+
+	"github.com/g3n/engine/math32"
+)
+
+// bvhLeafTriangles is the maximum number of triangles of the leaves of the BVH built by NewBVH.
+const bvhLeafTriangles = 4
+
+// BVHNode is a node of a bounding volume hierarchy of triangles.
+type BVHNode struct {
+	Box       math32.Box3 // bounding box of the triangles of the subtree
+	Left      *BVHNode    // nil for the leaves
+	Right     *BVHNode    // nil for the leaves
+	Triangles []int       // indices of the triangles of the leaves
+}
+
+// BVH is a bounding volume hierarchy of the triangles of a geometry, for visibility and overlap queries.
+// The triangles are identified by their index in the geometry, as in the indices of their vertices
+// 3*i, 3*i+1 and 3*i+2 in the index buffer, or in the position buffer if the geometry is not indexed.
+type BVH struct {
+	Root      *BVHNode         // nil if the geometry has no triangles
+	boxes     []math32.Box3    // bounding box of each triangle
+	positions []math32.Vector3 // vertex positions of the geometry
+	indices   []uint32         // vertex indices of the triangles
+}
+
+// NewBVH creates and returns a pointer to a new bounding volume hierarchy of the triangles of the specified geometry.
+// The triangles are split recursively in two halves by the median of their centroids along the longest axis
+// of the centroids bounds, until the leaves have at most a few triangles.
+// The hierarchy is not updated if the geometry is later modified.
+func NewBVH(geom *Geometry) *BVH {
+
+	positions, indices := meshTriangles(geom)
+	b := new(BVH)
+	b.positions = positions
+	b.indices = indices
+	count := len(indices) / 3
+	if count == 0 {
+		return b
+	}
+	b.boxes = make([]math32.Box3, count)
+	centroids := make([]math32.Vector3, count)
+	triangles := make([]int, count)
+	for i := range triangles {
+		triangles[i] = i
+		box := &b.boxes[i]
+		box.MakeEmpty()
+		for j := 0; j < 3; j++ {
+			box.ExpandByPoint(&positions[indices[3*i+j]])
+		}
+		box.Center(&centroids[i])
+	}
+	b.Root = b.build(triangles, centroids)
+	return b
+}
+
+// build returns the root of the subtree of the specified triangles, which are reordered.
+func (b *BVH) build(triangles []int, centroids []math32.Vector3) *BVHNode {
+
+	node := new(BVHNode)
+	node.Box.MakeEmpty()
+	var bounds math32.Box3
+	bounds.MakeEmpty()
+	for _, t := range triangles {
+		node.Box.Union(&b.boxes[t])
+		bounds.ExpandByPoint(&centroids[t])
+	}
+	var size math32.Vector3
+	size.SubVectors(&bounds.Max, &bounds.Min)
+	axis := 0
+	if size.Y > size.X {
+		axis = 1
+	}
+	if size.Z > size.Component(axis) {
+		axis = 2
+	}
+	// Triangles with the same centroid cannot be split
+	if len(triangles) <= bvhLeafTriangles || size.Component(axis) == 0 {
+		node.Triangles = triangles
+		return node
+	}
+	sort.Slice(triangles, func(i, j int) bool {
+		return centroids[triangles[i]].Component(axis) < centroids[triangles[j]].Component(axis)
+	})
+	mid := len(triangles) / 2
+	node.Left = b.build(triangles[:mid], centroids)
+	node.Right = b.build(triangles[mid:], centroids)
+	return node
+}
+
+// Traverse visits the subtree of this node, skipping the subtrees whose bounding box fails the specified test,
+// and calls the callback with the index of each triangle of the visited leaves.
+func (n *BVHNode) Traverse(test func(box *math32.Box3) bool, callback func(triangleIndex int)) {
+
+	if !test(&n.Box) {
+		return
+	}
+	if n.Left == nil {
+		for _, t := range n.Triangles {
+			callback(t)
+		}
+		return
+	}
+	n.Left.Traverse(test, callback)
+	n.Right.Traverse(test, callback)
+}
+
+// Traverse calls the callback with the index of each triangle whose bounding box passes the specified test,
+// skipping the subtrees whose bounding box fails it.
+func (b *BVH) Traverse(test func(box *math32.Box3) bool, callback func(triangleIndex int)) {
+
+	if b.Root == nil {
+		return
+	}
+	b.Root.Traverse(test, func(t int) {
+		if test(&b.boxes[t]) {
+			callback(t)
+		}
+	})
+}
+
+// Triangle returns the triangle with the specified index.
+func (b *BVH) Triangle(triangleIndex int) math32.Triangle {
+
+	i := 3 * triangleIndex
+	return *math32.NewTriangle(&b.positions[b.indices[i]], &b.positions[b.indices[i+1]], &b.positions[b.indices[i+2]])
+}
+
+// TraverseFrustum calls the callback with the index of each triangle intersecting the specified frustum,
+// skipping the subtrees whose bounding box is outside it and testing exactly the triangles of the other leaves.
+func (b *BVH) TraverseFrustum(frustum *math32.Frustum, callback func(triangleIndex int)) {
+
+	b.Traverse(frustum.IntersectsBox, func(t int) {
+		tri := b.Triangle(t)
+		if frustum.IntersectsTriangle(&tri) {
+			callback(t)
+		}
+	})
+}
+
+// TraverseSphere calls the callback with the index of each triangle whose bounding box intersects the specified sphere.
+func (b *BVH) TraverseSphere(sphere *math32.Sphere, callback func(triangleIndex int)) {
+
+	b.Traverse(func(box *math32.Box3) bool {
+		return box.DistanceToPoint(&sphere.Center) <= sphere.Radius
+	}, callback)
+}
+
+// TraverseBox calls the callback with the index of each triangle whose bounding box intersects the specified box.
+func (b *BVH) TraverseBox(box *math32.Box3, callback func(triangleIndex int)) {
+
+	b.Traverse(box.IsIntersectionBox, callback)
+}
@@ -0,0 +1,95 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+// Test that the frustum traversal visits exactly the triangles inside the frustum
+// and fewer nodes than a full traversal
+func TestBVHTraverseFrustum(t *testing.T) {
+
+	plane := NewPlane(20, 20, 32, 32)
+	bvh := NewBVH(&plane.Geometry)
+
+	// Camera above the plane looking down at an angle
+	var view, proj, viewProj math32.Matrix4
+	view.Identity()
+	view.LookAt(math32.NewVector3(0, -6, 4), math32.NewVector3(2, 0, 0), math32.NewVector3(0, 0, 1))
+	view.SetPosition(math32.NewVector3(0, -6, 4))
+	var viewInv math32.Matrix4
+	if err := viewInv.GetInverse(&view); err != nil {
+		t.Fatal(err)
+	}
+	proj.MakePerspective(40, 1.5, 0.1, 100)
+	viewProj.MultiplyMatrices(&proj, &viewInv)
+	frustum := math32.NewFrustumFromMatrix(&viewProj)
+
+	visited := make(map[int]bool)
+	bvh.TraverseFrustum(frustum, func(i int) {
+		if visited[i] {
+			t.Error("triangle visited twice:", i)
+		}
+		visited[i] = true
+	})
+	count := 0
+	for i := 0; i < len(plane.Indices())/3; i++ {
+		tri := bvh.Triangle(i)
+		inside := frustum.IntersectsTriangle(&tri)
+		if inside {
+			count++
+		}
+		if inside != visited[i] {
+			t.Error("triangle", i, "inside:", inside, "visited:", visited[i])
+		}
+	}
+	if count == 0 || count == len(plane.Indices())/3 {
+		t.Fatal("the frustum should contain part of the plane, contains", count)
+	}
+
+	culled, full := 0, 0
+	bvh.Root.Traverse(func(box *math32.Box3) bool {
+		culled++
+		return frustum.IntersectsBox(box)
+	}, func(int) {})
+	bvh.Root.Traverse(func(*math32.Box3) bool {
+		full++
+		return true
+	}, func(int) {})
+	if culled >= full {
+		t.Error("culling visited", culled, "nodes of", full)
+	}
+}
+
+// Test that the box and sphere traversals visit the triangles whose bounding box intersects the query
+func TestBVHTraverseBoxSphere(t *testing.T) {
+
+	plane := NewPlane(10, 10, 32, 32)
+	bvh := NewBVH(&plane.Geometry)
+	box := math32.NewBox3(math32.NewVector3(-1, -1, -1), math32.NewVector3(2, 1, 1))
+	sphere := math32.NewSphere(math32.NewVector3(1, 2, 0), 1.5)
+	inBox := make(map[int]bool)
+	bvh.TraverseBox(box, func(i int) { inBox[i] = true })
+	inSphere := make(map[int]bool)
+	bvh.TraverseSphere(sphere, func(i int) { inSphere[i] = true })
+
+	for i := 0; i < len(plane.Indices())/3; i++ {
+		tri := bvh.Triangle(i)
+		var bounds math32.Box3
+		bounds.SetFromPoints([]math32.Vector3{*tri.A(), *tri.B(), *tri.C()})
+		if box.IsIntersectionBox(&bounds) != inBox[i] {
+			t.Error("TraverseBox failed for triangle", i)
+		}
+		if (bounds.DistanceToPoint(&sphere.Center) <= sphere.Radius) != inSphere[i] {
+			t.Error("TraverseSphere failed for triangle", i)
+		}
+	}
+	if len(inBox) == 0 || len(inSphere) == 0 {
+		t.Error("the queries should intersect the plane")
+	}
+}
@@ -338,6 +338,29 @@ func (g *Geometry) ReadFaces(cb func(vA, vB, vC math32.Vector3) bool) {
 	}
 }
 
+// meshTriangles returns the vertex positions of the specified geometry and
+// the vertex indices of its triangles, which are sequential if it is not indexed.
+func meshTriangles(geom *Geometry) ([]math32.Vector3, []uint32) {
+
+	vbo := geom.VBO(gls.VertexPosition)
+	if vbo == nil {
+		return nil, nil
+	}
+	var positions []math32.Vector3
+	vbo.ReadVectors3(gls.VertexPosition, func(v math32.Vector3) bool {
+		positions = append(positions, v)
+		return false
+	})
+	if geom.Indexed() {
+		return positions, geom.Indices()
+	}
+	indices := make([]uint32, len(positions))
+	for i := range indices {
+		indices[i] = uint32(i)
+	}
+	return positions, indices
+}
+
 // TODO Read and Operate on Texcoords, Faces, Edges, FaceNormals, etc...
 
 // Indexed returns whether the geometry is indexed or not.
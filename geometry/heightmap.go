@@ -0,0 +1,333 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// HeightMap represents a terrain defined by a regular grid of height samples in the XZ plane.
+// The sample (i,j) is stored at data[i+j*width] and is located at
+// (i*cellSize, data[i+j*width]*heightScale, j*cellSize).
+type HeightMap struct {
+	data        []float32
+	width       int
+	height      int
+	cellSize    float32
+	heightScale float32
+}
+
+// heightMapRefineSteps is the number of bisection steps used to refine a ray hit.
+const heightMapRefineSteps = 16
+
+// NewHeightMap creates and returns a pointer to a new HeightMap wrapping the specified
+// height samples, which must have at least width*height elements and are not copied.
+func NewHeightMap(data []float32, width, height int, cellSize, heightScale float32) *HeightMap {
+
+	if width < 2 || height < 2 || len(data) < width*height {
+		panic("NewHeightMap: invalid height map dimensions")
+	}
+	hm := new(HeightMap)
+	hm.data = data
+	hm.width = width
+	hm.height = height
+	hm.cellSize = cellSize
+	hm.heightScale = heightScale
+	return hm
+}
+
+// Data returns the height samples of this height map.
+func (hm *HeightMap) Data() []float32 {
+
+	return hm.data
+}
+
+// Dimensions returns the number of samples of this height map along X and Z.
+func (hm *HeightMap) Dimensions() (width, height int) {
+
+	return hm.width, hm.height
+}
+
+// CellSize returns the distance between samples of this height map.
+func (hm *HeightMap) CellSize() float32 {
+
+	return hm.cellSize
+}
+
+// HeightScale returns the scale applied to the height samples.
+func (hm *HeightMap) HeightScale() float32 {
+
+	return hm.heightScale
+}
+
+// Size returns the extent of this height map in the X and Z directions.
+func (hm *HeightMap) Size() (sizeX, sizeZ float32) {
+
+	return float32(hm.width-1) * hm.cellSize, float32(hm.height-1) * hm.cellSize
+}
+
+// Contains returns if the specified XZ position is inside the extent of this height map.
+func (hm *HeightMap) Contains(x, z float32) bool {
+
+	sx, sz := hm.Size()
+	return x >= 0 && z >= 0 && x <= sx && z <= sz
+}
+
+// HeightAt returns the terrain height at the specified XZ position by bilinear interpolation
+// of the four surrounding samples. Positions outside the height map are clamped to its border.
+func (hm *HeightMap) HeightAt(x, z float32) float32 {
+
+	fx := math32.Clamp(x/hm.cellSize, 0, float32(hm.width-1))
+	fz := math32.Clamp(z/hm.cellSize, 0, float32(hm.height-1))
+	i := int(fx)
+	j := int(fz)
+	if i > hm.width-2 {
+		i = hm.width - 2
+	}
+	if j > hm.height-2 {
+		j = hm.height - 2
+	}
+	tx := fx - float32(i)
+	tz := fz - float32(j)
+
+	idx := i + j*hm.width
+	h00 := hm.data[idx]
+	h10 := hm.data[idx+1]
+	h01 := hm.data[idx+hm.width]
+	h11 := hm.data[idx+hm.width+1]
+	h0 := h00 + (h10-h00)*tx
+	h1 := h01 + (h11-h01)*tx
+	return (h0 + (h1-h0)*tz) * hm.heightScale
+}
+
+// NormalAt returns the terrain surface normal at the specified XZ position
+// computed by forward differences of the interpolated height, one cell apart.
+// Backward differences are used at the far borders of the height map.
+func (hm *HeightMap) NormalAt(x, z float32) math32.Vector3 {
+
+	sx, sz := hm.Size()
+	dx := hm.cellSize
+	dz := hm.cellSize
+	if x+dx > sx {
+		dx = -dx
+	}
+	if z+dz > sz {
+		dz = -dz
+	}
+	h := hm.HeightAt(x, z)
+	slopeX := (hm.HeightAt(x+dx, z) - h) / dx
+	slopeZ := (hm.HeightAt(x, z+dz) - h) / dz
+	n := math32.Vector3{X: -slopeX, Y: 1, Z: -slopeZ}
+	n.Normalize()
+	return n
+}
+
+// RayCast returns the first intersection of the specified ray with the terrain
+// inside the height map extent at a distance up to maxDist from the ray origin, which may be infinite.
+// The part of the ray inside the XZ extent of the height map is marched in horizontal steps of half
+// the cell size and the step where the ray goes below the terrain is then refined by bisection.
+// Returns false if there is no intersection.
+func (hm *HeightMap) RayCast(ray *math32.Ray, maxDist float32) (hit *math32.Vector3, ok bool) {
+
+	origin := ray.Origin()
+	dir := ray.Direction()
+	if dir.LengthSq() == 0 || !(maxDist >= 0) {
+		return nil, false
+	}
+	dir.Normalize()
+
+	// Clips the ray to the XZ extent of the height map
+	sx, sz := hm.Size()
+	tmin, tmax := float32(0), maxDist
+	for _, axis := range [2][3]float32{{origin.X, dir.X, sx}, {origin.Z, dir.Z, sz}} {
+		o, d, size := axis[0], axis[1], axis[2]
+		if d == 0 {
+			if o < 0 || o > size {
+				return nil, false
+			}
+			continue
+		}
+		t0, t1 := -o/d, (size-o)/d
+		if t0 > t1 {
+			t0, t1 = t1, t0
+		}
+		tmin = math32.Max(tmin, t0)
+		tmax = math32.Min(tmax, t1)
+	}
+	if tmin > tmax {
+		return nil, false
+	}
+	point := func(t float32) *math32.Vector3 {
+		return math32.NewVector3(origin.X+dir.X*t, origin.Y+dir.Y*t, origin.Z+dir.Z*t)
+	}
+	// above returns the height of the ray above the terrain at the specified distance,
+	// clamping the ray point to the height map extent against rounding
+	above := func(t float32) float32 {
+		return origin.Y + dir.Y*t - hm.HeightAt(math32.Clamp(origin.X+dir.X*t, 0, sx), math32.Clamp(origin.Z+dir.Z*t, 0, sz))
+	}
+
+	// The ray starts inside the extent below the terrain or enters the extent below it
+	prevT := tmin
+	if above(tmin) <= 0 {
+		return point(tmin), true
+	}
+	// A vertical ray meets the terrain at the height below its origin
+	horizontal := math32.Sqrt(dir.X*dir.X + dir.Z*dir.Z)
+	if horizontal == 0 {
+		if dir.Y >= 0 {
+			return nil, false
+		}
+		t := tmin + above(tmin)/-dir.Y
+		if t > tmax {
+			return nil, false
+		}
+		return point(t), true
+	}
+	// The number of steps is bounded by the extent, so that the march ends even for distant
+	// origins where adding a step to the distance would not change it
+	steps := int(math32.Ceil((tmax - tmin) * horizontal / (hm.cellSize * 0.5)))
+	if steps < 1 {
+		steps = 1
+	}
+	for k := 1; k <= steps; k++ {
+		t := tmin + (tmax-tmin)*float32(k)/float32(steps)
+		if above(t) > 0 {
+			prevT = t
+			continue
+		}
+		// Bisects between the last point above the terrain and this one
+		t0, t1 := prevT, t
+		for i := 0; i < heightMapRefineSteps; i++ {
+			tm := (t0 + t1) * 0.5
+			if above(tm) > 0 {
+				t0 = tm
+			} else {
+				t1 = tm
+			}
+		}
+		return point(t1), true
+	}
+	return nil, false
+}
+
+// ToMesh generates and returns a grid geometry of this height map with
+// one vertex per sample and vertex positions, normals and texture coordinates.
+func (hm *HeightMap) ToMesh() *Geometry {
+
+	count := hm.width * hm.height
+	positions := math32.NewArrayF32(0, count*3)
+	normals := math32.NewArrayF32(0, count*3)
+	uvs := math32.NewArrayF32(0, count*2)
+	indices := math32.NewArrayU32(0, (hm.width-1)*(hm.height-1)*6)
+
+	for j := 0; j < hm.height; j++ {
+		z := float32(j) * hm.cellSize
+		for i := 0; i < hm.width; i++ {
+			x := float32(i) * hm.cellSize
+			n := hm.NormalAt(x, z)
+			positions.Append(x, hm.data[i+j*hm.width]*hm.heightScale, z)
+			normals.AppendVector3(&n)
+			uvs.Append(float32(i)/float32(hm.width-1), float32(j)/float32(hm.height-1))
+		}
+	}
+	for j := 0; j < hm.height-1; j++ {
+		for i := 0; i < hm.width-1; i++ {
+			a := uint32(i + j*hm.width)
+			b := a + uint32(hm.width)
+			c := b + 1
+			d := a + 1
+			indices.Append(a, b, d, b, c, d)
+		}
+	}
+
+	geom := NewGeometry()
+	geom.SetIndices(indices)
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(normals).AddAttrib(gls.VertexNormal))
+	geom.AddVBO(gls.NewVBO(uvs).AddAttrib(gls.VertexTexcoord))
+	return geom
+}
+
+// RegionMesh generates and returns a grid geometry of the region of this height map with the specified
+// numbers of cells along X and Z from the sample (i0,j0), clipped to the height map, with vertex
+// positions and normals. Only one sample every step samples is used, and the last samples of the
+// region. A skirt of the specified depth hangs down along the border of the region, to hide the gaps
+// between adjacent regions with different steps.
+func (hm *HeightMap) RegionMesh(i0, j0, cellsX, cellsZ, step int, skirtDepth float32) *Geometry {
+
+	if step < 1 {
+		step = 1
+	}
+	samples := func(first, cells, size int) []int {
+		last := first + cells
+		if last > size-1 {
+			last = size - 1
+		}
+		var list []int
+		for i := first; i < last; i += step {
+			list = append(list, i)
+		}
+		return append(list, last)
+	}
+	xs := samples(i0, cellsX, hm.width)
+	zs := samples(j0, cellsZ, hm.height)
+	nx, nz := len(xs), len(zs)
+
+	perimeter := 2*(nx+nz) - 4
+	positions := math32.NewArrayF32(0, (nx*nz+perimeter)*3)
+	normals := math32.NewArrayF32(0, (nx*nz+perimeter)*3)
+	indices := math32.NewArrayU32(0, ((nx-1)*(nz-1)+perimeter)*6)
+	for _, j := range zs {
+		z := float32(j) * hm.cellSize
+		for _, i := range xs {
+			x := float32(i) * hm.cellSize
+			n := hm.NormalAt(x, z)
+			positions.Append(x, hm.data[i+j*hm.width]*hm.heightScale, z)
+			normals.AppendVector3(&n)
+		}
+	}
+	for j := 0; j < nz-1; j++ {
+		for i := 0; i < nx-1; i++ {
+			a := uint32(i + j*nx)
+			b := a + uint32(nx)
+			c := b + 1
+			d := a + 1
+			indices.Append(a, b, d, b, c, d)
+		}
+	}
+
+	// Border vertices around the region, each with a copy lowered by the skirt depth
+	border := make([]uint32, 0, perimeter)
+	for i := 0; i < nx-1; i++ {
+		border = append(border, uint32(i))
+	}
+	for j := 0; j < nz-1; j++ {
+		border = append(border, uint32(nx-1+j*nx))
+	}
+	for i := nx - 1; i > 0; i-- {
+		border = append(border, uint32(i+(nz-1)*nx))
+	}
+	for j := nz - 1; j > 0; j-- {
+		border = append(border, uint32(j*nx))
+	}
+	first := uint32(nx * nz)
+	for _, v := range border {
+		positions.Append(positions[v*3], positions[v*3+1]-skirtDepth, positions[v*3+2])
+		normals.Append(normals[v*3], normals[v*3+1], normals[v*3+2])
+	}
+	for k := range border {
+		next := (k + 1) % len(border)
+		a, b := border[k], border[next]
+		sa, sb := first+uint32(k), first+uint32(next)
+		indices.Append(a, sa, b, b, sa, sb)
+	}
+
+	geom := NewGeometry()
+	geom.SetIndices(indices)
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(normals).AddAttrib(gls.VertexNormal))
+	return geom
+}
@@ -0,0 +1,72 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+// newTestHeightMap returns a flat height map of 9x9 samples of height 1 with cells of size 1.
+func newTestHeightMap() *HeightMap {
+
+	data := make([]float32, 81)
+	for i := range data {
+		data[i] = 1
+	}
+	return NewHeightMap(data, 9, 9, 1, 1)
+}
+
+// Test that a ray going down onto the terrain hits it
+func TestHeightMapRayCastHit(t *testing.T) {
+
+	hm := newTestHeightMap()
+	ray := math32.NewRay(math32.NewVector3(-2, 5, 4), math32.NewVector3(1, -1, 0))
+	hit, ok := hm.RayCast(ray, math32.Infinity)
+	if !ok {
+		t.Fatal("RayCast missed the terrain")
+	}
+	if !hit.AlmostEquals(math32.NewVector3(2, 1, 4), 1e-3) {
+		t.Error("RayCast hit at", *hit)
+	}
+
+	// Vertical ray with an infinite distance
+	ray = math32.NewRay(math32.NewVector3(3, 10, 3), math32.NewVector3(0, -1, 0))
+	hit, ok = hm.RayCast(ray, math32.Infinity)
+	if !ok || !hit.AlmostEquals(math32.NewVector3(3, 1, 3), 1e-5) {
+		t.Error("RayCast vertical hit failed", hit, ok)
+	}
+}
+
+// Test that rays missing the terrain return with an infinite or large maximum distance
+func TestHeightMapRayCastMiss(t *testing.T) {
+
+	hm := newTestHeightMap()
+	rays := []*math32.Ray{
+		// Going up over the terrain
+		math32.NewRay(math32.NewVector3(4, 2, 4), math32.NewVector3(1, 1, 0)),
+		// Passing beside the extent
+		math32.NewRay(math32.NewVector3(-1, 0, -1), math32.NewVector3(0, 0, 1)),
+		// Vertical going up
+		math32.NewRay(math32.NewVector3(4, 2, 4), math32.NewVector3(0, 1, 0)),
+		// Horizontal above the terrain from far away
+		math32.NewRay(math32.NewVector3(-1e9, 2, 4), math32.NewVector3(1, 0, 0)),
+	}
+	for i, ray := range rays {
+		if _, ok := hm.RayCast(ray, math32.Infinity); ok {
+			t.Error("RayCast with infinite distance hit with ray", i)
+		}
+		if _, ok := hm.RayCast(ray, 1e30); ok {
+			t.Error("RayCast with large distance hit with ray", i)
+		}
+	}
+
+	// Hit beyond the maximum distance
+	ray := math32.NewRay(math32.NewVector3(-2, 5, 4), math32.NewVector3(1, -1, 0))
+	if _, ok := hm.RayCast(ray, 5); ok {
+		t.Error("RayCast hit beyond the maximum distance")
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// IcoSphere represents a unit sphere geometry built by recursively subdividing an icosahedron.
+// Unlike the latitude-longitude Sphere its triangles have nearly uniform size and shape.
+// It has 20*4^n faces for n subdivisions and vertices are shared between adjacent faces.
+type IcoSphere struct {
+	Geometry
+	Subdivisions int
+}
+
+// NewIcoSphere returns a pointer to a new IcoSphere geometry with the specified number of subdivisions.
+func NewIcoSphere(subdivisions int) *IcoSphere {
+
+	s := new(IcoSphere)
+	s.Geometry.Init()
+	if subdivisions < 0 {
+		subdivisions = 0
+	}
+	s.Subdivisions = subdivisions
+
+	// Icosahedron vertices and faces wound counter-clockwise seen from outside
+	t := (1 + math32.Sqrt(5)) / 2
+	coords := []float32{
+		-1, t, 0, 1, t, 0, -1, -t, 0, 1, -t, 0,
+		0, -1, t, 0, 1, t, 0, -1, -t, 0, 1, -t,
+		t, 0, -1, t, 0, 1, -t, 0, -1, -t, 0, 1,
+	}
+	vertices := make([]math32.Vector3, len(coords)/3)
+	for i := range vertices {
+		vertices[i].Set(coords[i*3], coords[i*3+1], coords[i*3+2]).Normalize()
+	}
+	faces := []uint32{
+		0, 11, 5, 0, 5, 1, 0, 1, 7, 0, 7, 10, 0, 10, 11,
+		1, 5, 9, 5, 11, 4, 11, 10, 2, 10, 7, 6, 7, 1, 8,
+		3, 9, 4, 3, 4, 2, 3, 2, 6, 3, 6, 8, 3, 8, 9,
+		4, 9, 5, 2, 4, 11, 6, 2, 10, 8, 6, 7, 9, 8, 1,
+	}
+
+	for level := 0; level < subdivisions; level++ {
+		// Midpoint vertices of this level keyed by the indices of their edge vertices
+		midpoints := make(map[uint64]uint32)
+		midpoint := func(a, b uint32) uint32 {
+			if a > b {
+				a, b = b, a
+			}
+			key := uint64(a)<<32 | uint64(b)
+			if idx, ok := midpoints[key]; ok {
+				return idx
+			}
+			var m math32.Vector3
+			m.AddVectors(&vertices[a], &vertices[b]).Normalize()
+			idx := uint32(len(vertices))
+			vertices = append(vertices, m)
+			midpoints[key] = idx
+			return idx
+		}
+		next := make([]uint32, 0, len(faces)*4)
+		for i := 0; i < len(faces); i += 3 {
+			a, b, c := faces[i], faces[i+1], faces[i+2]
+			ab := midpoint(a, b)
+			bc := midpoint(b, c)
+			ca := midpoint(c, a)
+			next = append(next, a, ab, ca, b, bc, ab, c, ca, bc, ab, bc, ca)
+		}
+		faces = next
+	}
+
+	// The vertices of the unit sphere are also its normals
+	positions := math32.NewArrayF32(0, len(vertices)*3)
+	for i := range vertices {
+		positions.AppendVector3(&vertices[i])
+	}
+	normals := math32.NewArrayF32(len(positions), len(positions))
+	copy(normals, positions)
+	indices := math32.NewArrayU32(0, len(faces))
+	indices.Append(faces...)
+
+	s.SetIndices(indices)
+	s.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	s.AddVBO(gls.NewVBO(normals).AddAttrib(gls.VertexNormal))
+
+	// Update bounding sphere
+	s.boundingSphere.Radius = 1
+	s.boundingSphereValid = true
+
+	// Update bounding box
+	s.boundingBox = math32.Box3{Min: math32.Vector3{X: -1, Y: -1, Z: -1}, Max: math32.Vector3{X: 1, Y: 1, Z: 1}}
+	s.boundingBoxValid = true
+
+	return s
+}
@@ -0,0 +1,360 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// LineCap specifies the shape of the ends of a LineMesh3.
+type LineCap int
+
+const (
+	// LineCapButt ends the line exactly at its end points.
+	LineCapButt = LineCap(iota)
+	// LineCapRound ends the line with half discs centered at its end points.
+	LineCapRound
+	// LineCapSquare extends the line by half its thickness beyond its end points.
+	LineCapSquare
+)
+
+// LineJoin specifies the shape of the joins between the segments of a LineMesh3.
+type LineJoin int
+
+const (
+	// LineJoinMiter extends the outer edges of the segments until they meet.
+	LineJoinMiter = LineJoin(iota)
+	// LineJoinRound fills the outer side of the joins with circular arcs.
+	LineJoinRound
+	// LineJoinBevel fills the outer side of the joins with a triangle.
+	LineJoinBevel
+)
+
+// LineStyle contains the parameters of the shape of a LineMesh3.
+type LineStyle struct {
+	Thickness     float32  // line width
+	Cap           LineCap  // shape of the line ends
+	Join          LineJoin // shape of the joins between segments
+	MiterLimit    float32  // maximum ratio of the miter length to half the thickness before using a bevel join
+	RoundSegments int      // number of segments of a half circle for round caps and joins
+}
+
+// NewLineStyle returns a LineStyle with the specified thickness, butt caps,
+// miter joins with a miter limit of 4 and round segments of 8.
+func NewLineStyle(thickness float32) LineStyle {
+
+	return LineStyle{Thickness: thickness, Cap: LineCapButt, Join: LineJoinMiter, MiterLimit: 4, RoundSegments: 8}
+}
+
+// LineMesh3 is a geometry which renders a polyline as a ribbon of quads
+// facing a camera position, with configurable thickness, caps and joins.
+// The geometry is generated by Update and regenerated when the camera moves significantly.
+// All vertex normals point towards the camera and the texture coordinates
+// are the relative arc length along the line (U) and the position across the line (V).
+type LineMesh3 struct {
+	Geometry
+	line      *math32.PolyLine3 // rendered polyline
+	style     LineStyle         // shape parameters
+	threshold float32           // camera distance which triggers a regeneration
+	camera    math32.Vector3    // camera position of the last generation
+	built     bool              // geometry has been generated
+	positions *gls.VBO
+	normals   *gls.VBO
+	uvs       *gls.VBO
+}
+
+// NewLineMesh3 creates and returns a pointer to a new LineMesh3 for the specified polyline and style.
+// The geometry is empty until Update is called with the camera position.
+func NewLineMesh3(line *math32.PolyLine3, style *LineStyle) *LineMesh3 {
+
+	lm := new(LineMesh3)
+	lm.Geometry.Init()
+	lm.line = line
+	lm.style = *style
+	lm.threshold = style.Thickness
+	lm.positions = gls.NewVBO(math32.NewArrayF32(0, 0)).AddAttrib(gls.VertexPosition)
+	lm.normals = gls.NewVBO(math32.NewArrayF32(0, 0)).AddAttrib(gls.VertexNormal)
+	lm.uvs = gls.NewVBO(math32.NewArrayF32(0, 0)).AddAttrib(gls.VertexTexcoord)
+	lm.AddVBO(lm.positions)
+	lm.AddVBO(lm.normals)
+	lm.AddVBO(lm.uvs)
+	return lm
+}
+
+// SetLine sets the rendered polyline. The geometry is regenerated at the next Update.
+func (lm *LineMesh3) SetLine(line *math32.PolyLine3) {
+
+	lm.line = line
+	lm.built = false
+}
+
+// SetStyle sets the line style. The geometry is regenerated at the next Update.
+func (lm *LineMesh3) SetStyle(style *LineStyle) {
+
+	lm.style = *style
+	lm.built = false
+}
+
+// Style returns the line style.
+func (lm *LineMesh3) Style() LineStyle {
+
+	return lm.style
+}
+
+// SetUpdateThreshold sets the distance the camera must move to regenerate the geometry.
+// The default is the line thickness.
+func (lm *LineMesh3) SetUpdateThreshold(distance float32) {
+
+	lm.threshold = distance
+}
+
+// Update regenerates the geometry facing the specified camera position in the
+// coordinates of the line if it was not generated yet, if the line or style changed
+// or if the camera moved more than the update threshold since the last generation.
+// Returns if the geometry was regenerated.
+func (lm *LineMesh3) Update(cameraPos *math32.Vector3) bool {
+
+	if lm.built && lm.camera.DistanceTo(cameraPos) <= lm.threshold {
+		return false
+	}
+	lm.camera = *cameraPos
+	lm.built = true
+	lm.build()
+	return true
+}
+
+// lineBuilder accumulates the vertices and triangles of a LineMesh3.
+type lineBuilder struct {
+	camera    math32.Vector3
+	length    float32
+	positions math32.ArrayF32
+	normals   math32.ArrayF32
+	uvs       math32.ArrayF32
+	indices   math32.ArrayU32
+}
+
+// vertex adds a vertex at the specified position and arc length and returns its index.
+func (b *lineBuilder) vertex(p *math32.Vector3, s, v float32) uint32 {
+
+	var n math32.Vector3
+	n.SubVectors(&b.camera, p)
+	if n.LengthSq() > 0 {
+		n.Normalize()
+	}
+	idx := uint32(b.positions.Len() / 3)
+	b.positions.AppendVector3(p)
+	b.normals.AppendVector3(&n)
+	u := float32(0)
+	if b.length > 0 {
+		u = s / b.length
+	}
+	b.uvs.Append(u, v)
+	return idx
+}
+
+// triangle adds a triangle with the specified vertices wound counter-clockwise seen from the camera.
+func (b *lineBuilder) triangle(i0, i1, i2 uint32) {
+
+	var p0, p1, p2, e1, e2, n math32.Vector3
+	b.positions.GetVector3(int(i0)*3, &p0)
+	b.positions.GetVector3(int(i1)*3, &p1)
+	b.positions.GetVector3(int(i2)*3, &p2)
+	e1.SubVectors(&p1, &p0)
+	e2.SubVectors(&p2, &p0)
+	n.CrossVectors(&e1, &e2)
+	e1.SubVectors(&b.camera, &p0)
+	if n.Dot(&e1) < 0 {
+		i1, i2 = i2, i1
+	}
+	b.indices.Append(i0, i1, i2)
+}
+
+// fan adds a circular arc of triangles around the center vertex starting at the specified unit offset
+// and rotating around the specified axis by the specified angle, with the specified number of steps.
+func (b *lineBuilder) fan(center *math32.Vector3, s float32, start, axis *math32.Vector3, angle, radius float32, steps int) {
+
+	ic := b.vertex(center, s, 0.5)
+	var q math32.Quaternion
+	prevOffset := *start
+	prev := *center
+	prev.Add(prevOffset.Clone().MultiplyScalar(radius))
+	iprev := b.vertex(&prev, s, 0)
+	for i := 1; i <= steps; i++ {
+		q.SetFromAxisAngle(axis, angle*float32(i)/float32(steps))
+		offset := *start
+		offset.ApplyQuaternion(&q)
+		p := *center
+		p.Add(offset.MultiplyScalar(radius))
+		ip := b.vertex(&p, s, 0)
+		b.triangle(ic, iprev, ip)
+		iprev = ip
+	}
+}
+
+// build generates the geometry of the line facing the current camera position.
+func (lm *LineMesh3) build() {
+
+	b := lineBuilder{camera: lm.camera}
+	b.positions = math32.NewArrayF32(0, 0)
+	b.normals = math32.NewArrayF32(0, 0)
+	b.uvs = math32.NewArrayF32(0, 0)
+	b.indices = math32.NewArrayU32(0, 0)
+
+	// Removes consecutive coincident points
+	points := make([]math32.Vector3, 0)
+	if lm.line != nil {
+		for _, p := range lm.line.Points() {
+			if len(points) == 0 || points[len(points)-1].DistanceToSquared(&p) > 0 {
+				points = append(points, p)
+			}
+		}
+	}
+	half := lm.style.Thickness / 2
+	if len(points) >= 2 && half > 0 {
+		lm.buildRibbon(&b, points, half)
+	}
+
+	lm.positions.SetBuffer(b.positions)
+	lm.normals.SetBuffer(b.normals)
+	lm.uvs.SetBuffer(b.uvs)
+	lm.SetIndices(b.indices)
+	lm.areaValid = false
+	lm.volumeValid = false
+	lm.rotInertiaValid = false
+}
+
+// buildRibbon adds to the builder the triangles of the line through the specified distinct points.
+func (lm *LineMesh3) buildRibbon(b *lineBuilder, points []math32.Vector3, half float32) {
+
+	n := len(points) - 1
+	roundSteps := lm.style.RoundSegments
+	if roundSteps < 1 {
+		roundSteps = 1
+	}
+
+	// Unit direction and side vector of each segment, perpendicular to the direction and the view vector
+	dirs := make([]math32.Vector3, n)
+	sides := make([]math32.Vector3, n)
+	arc := make([]float32, n+1)
+	for i := 0; i < n; i++ {
+		dirs[i].SubVectors(&points[i+1], &points[i])
+		arc[i+1] = arc[i] + dirs[i].Length()
+		dirs[i].Normalize()
+		var mid, view math32.Vector3
+		mid.AddVectors(&points[i], &points[i+1]).MultiplyScalar(0.5)
+		view.SubVectors(&b.camera, &mid)
+		sides[i].CrossVectors(&dirs[i], &view)
+		if sides[i].LengthSq() < 1e-12 {
+			// Segment aligned with the view direction
+			sides[i].Set(1, 0, 0).Cross(&dirs[i])
+			if sides[i].LengthSq() < 1e-12 {
+				sides[i].Set(0, 1, 0).Cross(&dirs[i])
+			}
+		}
+		sides[i].Normalize()
+	}
+	b.length = arc[n]
+
+	// Left and right vertices of the start and end of each segment
+	starts := make([][2]uint32, n)
+	ends := make([][2]uint32, n)
+	offsetVertex := func(p *math32.Vector3, side *math32.Vector3, scale, s, v float32) uint32 {
+		q := *p
+		q.Add(side.Clone().MultiplyScalar(scale))
+		return b.vertex(&q, s, v)
+	}
+
+	// First end point with the cap
+	start := points[0]
+	if lm.style.Cap == LineCapSquare {
+		start.Sub(dirs[0].Clone().MultiplyScalar(half))
+	}
+	starts[0] = [2]uint32{offsetVertex(&start, &sides[0], half, 0, 0), offsetVertex(&start, &sides[0], -half, 0, 1)}
+	if lm.style.Cap == LineCapRound {
+		back := dirs[0]
+		back.Negate()
+		var axis math32.Vector3
+		axis.CrossVectors(&sides[0], &back).Normalize()
+		b.fan(&points[0], 0, &sides[0], &axis, math32.Pi, half, roundSteps)
+	}
+
+	// Joins between segments
+	for i := 1; i < n; i++ {
+		p := &points[i]
+		sa := &sides[i-1]
+		sb := &sides[i]
+		// The outer side of the join is the side where the line turns away from
+		var turn math32.Vector3
+		turn.CrossVectors(&dirs[i-1], &dirs[i])
+		var view math32.Vector3
+		view.SubVectors(&b.camera, p)
+		outer := float32(1)
+		if turn.Dot(&view) > 0 {
+			outer = -1
+		}
+
+		var miter math32.Vector3
+		miter.AddVectors(sa, sb)
+		miterLen := float32(0)
+		if miter.LengthSq() > 1e-12 {
+			miter.Normalize()
+			if c := miter.Dot(sa); c > 1e-6 {
+				miterLen = half / c
+			}
+		}
+		if lm.style.Join == LineJoinMiter && miterLen > 0 && miterLen <= lm.style.MiterLimit*half {
+			left := offsetVertex(p, &miter, miterLen, arc[i], 0)
+			right := offsetVertex(p, &miter, -miterLen, arc[i], 1)
+			ends[i-1] = [2]uint32{left, right}
+			starts[i] = [2]uint32{left, right}
+			continue
+		}
+
+		ends[i-1] = [2]uint32{offsetVertex(p, sa, half, arc[i], 0), offsetVertex(p, sa, -half, arc[i], 1)}
+		starts[i] = [2]uint32{offsetVertex(p, sb, half, arc[i], 0), offsetVertex(p, sb, -half, arc[i], 1)}
+		oa := *sa
+		oa.MultiplyScalar(outer)
+		ob := *sb
+		ob.MultiplyScalar(outer)
+		if lm.style.Join == LineJoinRound {
+			angle := math32.Acos(math32.Clamp(oa.Dot(&ob), -1, 1))
+			var axis math32.Vector3
+			axis.CrossVectors(&oa, &ob)
+			if axis.LengthSq() > 1e-12 && angle > 0 {
+				axis.Normalize()
+				steps := int(math32.Ceil(angle / math32.Pi * float32(roundSteps)))
+				if steps < 1 {
+					steps = 1
+				}
+				b.fan(p, arc[i], &oa, &axis, angle, half, steps)
+			}
+			continue
+		}
+		// Bevel join and miter join exceeding the limit
+		ic := b.vertex(p, arc[i], 0.5)
+		ia := offsetVertex(p, &oa, half, arc[i], 0)
+		ib := offsetVertex(p, &ob, half, arc[i], 0)
+		b.triangle(ic, ia, ib)
+	}
+
+	// Last end point with the cap
+	end := points[n]
+	if lm.style.Cap == LineCapSquare {
+		end.Add(dirs[n-1].Clone().MultiplyScalar(half))
+	}
+	ends[n-1] = [2]uint32{offsetVertex(&end, &sides[n-1], half, arc[n], 0), offsetVertex(&end, &sides[n-1], -half, arc[n], 1)}
+	if lm.style.Cap == LineCapRound {
+		var axis math32.Vector3
+		axis.CrossVectors(&sides[n-1], &dirs[n-1]).Normalize()
+		b.fan(&points[n], arc[n], &sides[n-1], &axis, math32.Pi, half, roundSteps)
+	}
+
+	// Segment quads
+	for i := 0; i < n; i++ {
+		b.triangle(starts[i][0], starts[i][1], ends[i][1])
+		b.triangle(starts[i][0], ends[i][1], ends[i][0])
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"errors"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// Marching cubes cell topology.
+// Corner i of a cell is at offset mcCorners[i] from the cell origin and
+// edge j of a cell connects corners mcEdges[j][0] and mcEdges[j][1].
+var mcCorners = [8][3]int{
+	{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0},
+	{0, 0, 1}, {1, 0, 1}, {1, 1, 1}, {0, 1, 1},
+}
+
+var mcEdges = [12][2]int{
+	{0, 1}, {1, 2}, {3, 2}, {0, 3},
+	{4, 5}, {5, 6}, {7, 6}, {4, 7},
+	{0, 4}, {1, 5}, {2, 6}, {3, 7},
+}
+
+// mcEdgeTable contains for each of the 256 cell configurations a bit mask
+// of the edges which are intersected by the isosurface.
+var mcEdgeTable [256]uint16
+
+// mcTriTable contains for each of the 256 cell configurations the list of
+// edge indices forming the triangles of the isosurface inside the cell,
+// three edges per triangle, wound counter-clockwise when seen from outside.
+var mcTriTable [256][]uint8
+
+func init() {
+
+	mcBuildTables()
+}
+
+// mcBuildTables precomputes the edge and triangle tables.
+// For each configuration the isosurface contour is traced on each cell face, oriented so that
+// the inside corners are on the right side when the face is seen from outside the cell.
+// Ambiguous faces always separate the inside corners, which only depends on the face
+// corners and so guarantees that the contours of neighbour cells match and the surface is closed.
+// The contour segments are then chained into closed loops which are triangulated as fans.
+func mcBuildTables() {
+
+	// Faces as corner cycles, counter-clockwise when seen from outside the cell
+	faces := [6][4]int{
+		{0, 3, 2, 1}, {4, 5, 6, 7},
+		{0, 1, 5, 4}, {3, 7, 6, 2},
+		{0, 4, 7, 3}, {1, 2, 6, 5},
+	}
+	edgeOf := func(a, b int) int {
+		for i, e := range mcEdges {
+			if (e[0] == a && e[1] == b) || (e[0] == b && e[1] == a) {
+				return i
+			}
+		}
+		panic("invalid cell edge")
+	}
+
+	edgesShareFace := func(e1, e2 int) bool {
+		for _, f := range faces {
+			n := 0
+			for k := 0; k < 4; k++ {
+				e := edgeOf(f[k], f[(k+1)%4])
+				if e == e1 || e == e2 {
+					n++
+				}
+			}
+			if n == 2 {
+				return true
+			}
+		}
+		return false
+	}
+
+	for config := 0; config < 256; config++ {
+		inside := func(c int) bool { return config&(1<<uint(c)) != 0 }
+
+		var mask uint16
+		for i, e := range mcEdges {
+			if inside(e[0]) != inside(e[1]) {
+				mask |= 1 << uint(i)
+			}
+		}
+		mcEdgeTable[config] = mask
+
+		// Contour segments: next[a] = b means a segment goes from the crossing on edge a to the crossing on edge b
+		next := [12]int{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1}
+		for _, f := range faces {
+			for k := 0; k < 4; k++ {
+				c0 := f[k]
+				c1 := f[(k+1)%4]
+				// Entry crossing: from an outside corner to an inside corner.
+				if inside(c0) || !inside(c1) {
+					continue
+				}
+				// Connect to the first exit crossing after the inside corner
+				for m := 1; m < 4; m++ {
+					d0 := f[(k+m)%4]
+					d1 := f[(k+m+1)%4]
+					if inside(d0) && !inside(d1) {
+						next[edgeOf(c0, c1)] = edgeOf(d0, d1)
+						break
+					}
+				}
+			}
+		}
+
+		// Chain the segments into loops and triangulate each loop as a fan
+		tris := make([]uint8, 0)
+		var visited [12]bool
+		for start := 0; start < 12; start++ {
+			if next[start] < 0 || visited[start] {
+				continue
+			}
+			loop := make([]int, 0, 12)
+			for e := start; !visited[e]; e = next[e] {
+				visited[e] = true
+				loop = append(loop, e)
+			}
+			// Choose the fan apex so that no diagonal lies on a cell face, where
+			// it could coincide with an edge of the contour of the neighbour cell.
+			apex := 0
+			for a := 0; a < len(loop); a++ {
+				ok := true
+				for i := 2; i+1 < len(loop) && ok; i++ {
+					ok = !edgesShareFace(loop[a], loop[(a+i)%len(loop)])
+				}
+				if ok {
+					apex = a
+					break
+				}
+			}
+			for i := 1; i+1 < len(loop); i++ {
+				tris = append(tris, uint8(loop[apex]), uint8(loop[(apex+i)%len(loop)]), uint8(loop[(apex+i+1)%len(loop)]))
+			}
+		}
+		mcTriTable[config] = tris
+	}
+}
+
+// MarchingCubes extracts the isosurface of the specified scalar field using the classic
+// Lorensen-Cline marching cubes algorithm and returns it as an indexed triangle geometry
+// with vertex positions and normals.
+// The field is a dense array of width*height*depth samples in X major order
+// (index = x + y*width + z*width*height) and cellSize is the distance between samples.
+// Values greater than isoValue are considered to be inside the surface and
+// the triangles are wound so that their normals point outwards.
+// Vertices on cell edges are shared by all the cells containing the edge.
+func MarchingCubes(field []float32, width, height, depth int, isoValue float32, cellSize float32) (*Geometry, error) {
+
+	if width < 2 || height < 2 || depth < 2 {
+		return nil, errors.New("field dimensions must be at least 2x2x2")
+	}
+	if len(field) != width*height*depth {
+		return nil, errors.New("field length does not match its dimensions")
+	}
+	if cellSize <= 0 {
+		return nil, errors.New("cell size must be positive")
+	}
+
+	sample := func(x, y, z int) float32 {
+		return field[x+y*width+z*width*height]
+	}
+	// gradient returns the central differences gradient of the field at the specified sample
+	gradient := func(x, y, z int) math32.Vector3 {
+		var g math32.Vector3
+		x0, x1 := math32.ClampInt(x-1, 0, width-1), math32.ClampInt(x+1, 0, width-1)
+		y0, y1 := math32.ClampInt(y-1, 0, height-1), math32.ClampInt(y+1, 0, height-1)
+		z0, z1 := math32.ClampInt(z-1, 0, depth-1), math32.ClampInt(z+1, 0, depth-1)
+		g.X = (sample(x1, y, z) - sample(x0, y, z)) / float32(x1-x0)
+		g.Y = (sample(x, y1, z) - sample(x, y0, z)) / float32(y1-y0)
+		g.Z = (sample(x, y, z1) - sample(x, y, z0)) / float32(z1-z0)
+		return g
+	}
+
+	positions := math32.NewArrayF32(0, 0)
+	normals := math32.NewArrayF32(0, 0)
+	indices := math32.NewArrayU32(0, 0)
+
+	// Indices of the vertices already created, keyed by lattice edge
+	vertices := make(map[int]uint32)
+	var nvertices uint32
+
+	// vertexAt returns the index of the vertex on the specified cell edge, creating it if necessary
+	vertexAt := func(cx, cy, cz, edge int) uint32 {
+		c0 := mcCorners[mcEdges[edge][0]]
+		c1 := mcCorners[mcEdges[edge][1]]
+		x0, y0, z0 := cx+c0[0], cy+c0[1], cz+c0[2]
+		x1, y1, z1 := cx+c1[0], cy+c1[1], cz+c1[2]
+		// Edges always go from the lower to the higher corner along a single axis
+		axis := 0
+		if y1 != y0 {
+			axis = 1
+		} else if z1 != z0 {
+			axis = 2
+		}
+		key := ((z0*height+y0)*width+x0)*3 + axis
+		if idx, ok := vertices[key]; ok {
+			return idx
+		}
+
+		v0 := sample(x0, y0, z0)
+		v1 := sample(x1, y1, z1)
+		t := float32(0.5)
+		if v1 != v0 {
+			t = (isoValue - v0) / (v1 - v0)
+		}
+		p0 := math32.Vector3{X: float32(x0), Y: float32(y0), Z: float32(z0)}
+		p1 := math32.Vector3{X: float32(x1), Y: float32(y1), Z: float32(z1)}
+		p0.Lerp(&p1, t).MultiplyScalar(cellSize)
+		g0 := gradient(x0, y0, z0)
+		g1 := gradient(x1, y1, z1)
+		// Inside is where the field is greater so the outward normal is the negated gradient
+		normal := g0.Lerp(&g1, t).Negate()
+		if normal.LengthSq() > 0 {
+			normal.Normalize()
+		}
+		positions.AppendVector3(&p0)
+		normals.AppendVector3(normal)
+
+		idx := nvertices
+		nvertices++
+		vertices[key] = idx
+		return idx
+	}
+
+	for z := 0; z < depth-1; z++ {
+		for y := 0; y < height-1; y++ {
+			for x := 0; x < width-1; x++ {
+				config := 0
+				for i, c := range mcCorners {
+					if sample(x+c[0], y+c[1], z+c[2]) > isoValue {
+						config |= 1 << uint(i)
+					}
+				}
+				if mcEdgeTable[config] == 0 {
+					continue
+				}
+				for _, edge := range mcTriTable[config] {
+					indices.Append(vertexAt(x, y, z, int(edge)))
+				}
+			}
+		}
+	}
+
+	geom := NewGeometry()
+	geom.SetIndices(indices)
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(normals).AddAttrib(gls.VertexNormal))
+	return geom, nil
+}
@@ -0,0 +1,165 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"math/rand"
+	"sort"
+)
+
+// MeshSampler generates random points uniformly distributed by area on the surface of a triangle mesh.
+// The triangles are copied at creation, so later changes of the geometry are not sampled.
+type MeshSampler struct {
+	positions []math32.Vector3 // triangle vertex positions, three per triangle
+	normals   []math32.Vector3 // triangle vertex normals, three per triangle
+	cdf       []float32        // cumulative area of the triangles
+}
+
+// NewMeshSampler creates and returns a pointer to a new MeshSampler for the triangles of the specified geometry.
+// If the geometry has no vertex normals the face normals are used.
+func NewMeshSampler(geom *Geometry) *MeshSampler {
+
+	ms := new(MeshSampler)
+	vbo := geom.VBO(gls.VertexPosition)
+	if vbo == nil {
+		return ms
+	}
+	var vertices, vertexNormals []math32.Vector3
+	vbo.ReadVectors3(gls.VertexPosition, func(v math32.Vector3) bool {
+		vertices = append(vertices, v)
+		return false
+	})
+	if nvbo := geom.VBO(gls.VertexNormal); nvbo != nil {
+		nvbo.ReadVectors3(gls.VertexNormal, func(n math32.Vector3) bool {
+			vertexNormals = append(vertexNormals, n)
+			return false
+		})
+		if len(vertexNormals) != len(vertices) {
+			vertexNormals = nil
+		}
+	}
+
+	// Vertex indices of the triangles
+	var indices []uint32
+	if geom.Indexed() {
+		indices = geom.Indices()
+	} else {
+		indices = make([]uint32, len(vertices))
+		for i := range indices {
+			indices[i] = uint32(i)
+		}
+	}
+
+	var total float32
+	for i := 0; i+2 < len(indices); i += 3 {
+		a := &vertices[indices[i]]
+		b := &vertices[indices[i+1]]
+		c := &vertices[indices[i+2]]
+		var ab, ac, n math32.Vector3
+		ab.SubVectors(b, a)
+		ac.SubVectors(c, a)
+		n.CrossVectors(&ab, &ac)
+		area := n.Length() / 2
+		if area == 0 {
+			continue
+		}
+		total += area
+		ms.cdf = append(ms.cdf, total)
+		ms.positions = append(ms.positions, *a, *b, *c)
+		if vertexNormals != nil {
+			ms.normals = append(ms.normals, vertexNormals[indices[i]], vertexNormals[indices[i+1]], vertexNormals[indices[i+2]])
+		} else {
+			n.Normalize()
+			ms.normals = append(ms.normals, n, n, n)
+		}
+	}
+	return ms
+}
+
+// Area returns the total area of the sampled triangles.
+func (ms *MeshSampler) Area() float32 {
+
+	if len(ms.cdf) == 0 {
+		return 0
+	}
+	return ms.cdf[len(ms.cdf)-1]
+}
+
+// TriangleCount returns the number of sampled triangles. Triangles with zero area are not sampled.
+func (ms *MeshSampler) TriangleCount() int {
+
+	return len(ms.cdf)
+}
+
+// sample returns the index of a random triangle chosen with probability proportional
+// to its area and the barycentric coordinates of a uniformly distributed point inside it.
+func (ms *MeshSampler) sample(rng *rand.Rand) (int, float32, float32, float32) {
+
+	// Binary search of the cumulative area
+	r := rng.Float32() * ms.Area()
+	tri := sort.Search(len(ms.cdf), func(i int) bool { return ms.cdf[i] > r })
+	if tri >= len(ms.cdf) {
+		tri = len(ms.cdf) - 1
+	}
+	// Uniform barycentric coordinates
+	s := math32.Sqrt(rng.Float32())
+	t := rng.Float32()
+	return tri, 1 - s, s * (1 - t), s * t
+}
+
+// Sample returns n random points uniformly distributed by area on the surface of the mesh.
+// If rng is nil the default source of the math/rand package is used.
+func (ms *MeshSampler) Sample(n int, rng *rand.Rand) []math32.Vector3 {
+
+	positions := make([]math32.Vector3, 0, n)
+	if len(ms.cdf) == 0 {
+		return positions
+	}
+	if rng == nil {
+		rng = rand.New(rand.NewSource(rand.Int63()))
+	}
+	for i := 0; i < n; i++ {
+		tri, u, v, w := ms.sample(rng)
+		positions = append(positions, barycentric(ms.positions[tri*3:], u, v, w))
+	}
+	return positions
+}
+
+// SampleWithNormals returns n random points uniformly distributed by area on the surface
+// of the mesh and the unit surface normals at the points, interpolated from the vertex normals.
+// If rng is nil the default source of the math/rand package is used.
+func (ms *MeshSampler) SampleWithNormals(n int, rng *rand.Rand) (positions, normals []math32.Vector3) {
+
+	positions = make([]math32.Vector3, 0, n)
+	normals = make([]math32.Vector3, 0, n)
+	if len(ms.cdf) == 0 {
+		return positions, normals
+	}
+	if rng == nil {
+		rng = rand.New(rand.NewSource(rand.Int63()))
+	}
+	for i := 0; i < n; i++ {
+		tri, u, v, w := ms.sample(rng)
+		positions = append(positions, barycentric(ms.positions[tri*3:], u, v, w))
+		normal := barycentric(ms.normals[tri*3:], u, v, w)
+		if normal.LengthSq() > 0 {
+			normal.Normalize()
+		}
+		normals = append(normals, normal)
+	}
+	return positions, normals
+}
+
+// barycentric returns the combination of the first three specified vectors with the specified weights.
+func barycentric(v []math32.Vector3, u, w1, w2 float32) math32.Vector3 {
+
+	return math32.Vector3{
+		X: u*v[0].X + w1*v[1].X + w2*v[2].X,
+		Y: u*v[0].Y + w1*v[1].Y + w2*v[2].Y,
+		Z: u*v[0].Z + w1*v[1].Z + w2*v[2].Z,
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// Test that the sample density in each triangle is proportional to its area
+func TestMeshSamplerUniformity(t *testing.T) {
+
+	// Right triangles with legs of lengths area and 2 at x = 10*i, and a degenerate triangle
+	areas := []float32{1, 2, 3, 6}
+	positions := math32.NewArrayF32(0, 0)
+	for i, area := range areas {
+		x := 10 * float32(i)
+		positions.Append(x, 0, 0, x+area, 0, 0, x, 2, 0)
+	}
+	positions.Append(0, 0, 0, 1, 1, 1, 2, 2, 2)
+	geom := NewGeometry()
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+
+	ms := NewMeshSampler(geom)
+	if ms.TriangleCount() != 4 || ms.Area() != 12 {
+		t.Fatal("sampler of", ms.TriangleCount(), "triangles with area", ms.Area(), "instead of 4 and 12")
+	}
+	const n = 60000
+	points, normals := ms.SampleWithNormals(n, rand.New(rand.NewSource(1)))
+	if len(points) != n || len(normals) != n {
+		t.Fatal(len(points), "points and", len(normals), "normals instead of", n)
+	}
+	counts := make([]float64, len(areas))
+	for i, p := range points {
+		tri := int(math32.Floor(p.X / 10))
+		if tri < 0 || tri >= len(areas) || p.Y < 0 || p.Z != 0 || (p.X-10*float32(tri))/areas[tri]+p.Y/2 > 1+1e-5 {
+			t.Fatal("point", p, "outside the triangles")
+		}
+		if normals[i] != (math32.Vector3{Z: 1}) {
+			t.Fatal("normal", normals[i], "instead of the face normal")
+		}
+		counts[tri]++
+	}
+
+	// Chi-squared with 3 degrees of freedom below the 0.999 quantile
+	var chi2 float64
+	for i, area := range areas {
+		expected := float64(area / ms.Area() * n)
+		chi2 += (counts[i] - expected) * (counts[i] - expected) / expected
+	}
+	if chi2 > 16.27 {
+		t.Error("chi-squared", chi2, "of the triangle counts", counts)
+	}
+}
+
+// Test the interpolated normals of the faces of a box and the sampling of an empty geometry
+func TestMeshSamplerNormals(t *testing.T) {
+
+	box := NewBox(1, 2, 3)
+	ms := NewMeshSampler(&box.Geometry)
+	if math32.Abs(ms.Area()-22) > 1e-5 {
+		t.Fatal("box area", ms.Area(), "instead of 22")
+	}
+	points, normals := ms.SampleWithNormals(1000, rand.New(rand.NewSource(1)))
+	half := math32.Vector3{X: 0.5, Y: 1, Z: 1.5}
+	for i, p := range points {
+		// The normal points along the axis where the point is on a face
+		n := normals[i]
+		onFace := math32.Abs(math32.Abs(p.X)-half.X) < 1e-5 && n.X*p.X > 0.999*half.X ||
+			math32.Abs(math32.Abs(p.Y)-half.Y) < 1e-5 && n.Y*p.Y > 0.999*half.Y ||
+			math32.Abs(math32.Abs(p.Z)-half.Z) < 1e-5 && n.Z*p.Z > 0.999*half.Z
+		if !onFace || math32.Abs(n.Length()-1) > 1e-5 {
+			t.Fatal("point", p, "with the normal", n, "not on a face of the box")
+		}
+	}
+	if s := NewMeshSampler(NewGeometry()).Sample(10, nil); len(s) != 0 {
+		t.Error(len(s), "samples of an empty geometry")
+	}
+}
@@ -0,0 +1,20 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !xatlas
+
+package geometry
+
+import (
+	"errors"
+
+	"github.com/g3n/engine/math32"
+)
+
+// UnwrapXAtlas segments the specified geometry into charts packed in an atlas by the xatlas library.
+// Returns an error, since the engine was built without the xatlas tag.
+func UnwrapXAtlas(geom *Geometry) (uvs []math32.Vector2, atlasWidth, atlasHeight int, err error) {
+
+	return nil, 0, 0, errors.New("xatlas support requires building with the xatlas tag")
+}
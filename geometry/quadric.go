@@ -0,0 +1,101 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// QuadricErrorMatrix returns the quadric error matrix of the vertex with the specified index in the position
+// buffer of the specified triangle geometry, as used by the decimation by edge collapse of Garland and Heckbert:
+// the sum of the outer products of the planes (a,b,c,d) of the faces referencing the vertex, with unit normals.
+// The product of the matrix with a point (x,y,z,1) on both sides is the sum of the squared distances
+// of the point to these planes. Vertices duplicated at seams of the attributes do not share their faces.
+func QuadricErrorMatrix(geom *Geometry, vertexIndex int) math32.Matrix4 {
+
+	var q math32.Matrix4
+	positions, indices := meshTriangles(geom)
+	for i := 0; i+2 < len(indices); i += 3 {
+		ia, ib, ic := int(indices[i]), int(indices[i+1]), int(indices[i+2])
+		if ia != vertexIndex && ib != vertexIndex && ic != vertexIndex {
+			continue
+		}
+		var ab, ac, n math32.Vector3
+		ab.SubVectors(&positions[ib], &positions[ia])
+		ac.SubVectors(&positions[ic], &positions[ia])
+		n.CrossVectors(&ab, &ac)
+		// Degenerate faces have no plane
+		if n.LengthSq() == 0 {
+			continue
+		}
+		n.Normalize()
+		p := [4]float32{n.X, n.Y, n.Z, -n.Dot(&positions[ia])}
+		for col := 0; col < 4; col++ {
+			for row := 0; row < 4; row++ {
+				q[col*4+row] += p[row] * p[col]
+			}
+		}
+	}
+	return q
+}
+
+// CollapseError returns the smallest quadric error of the collapse of the edge between the vertices with
+// the specified indices in the position buffer of the specified triangle geometry into a single vertex:
+// the error of the position minimizing the sum of the quadric error matrices of the two vertices,
+// found by inverting the system of its derivatives, or of the best of the two vertices and their midpoint
+// if the system is singular, as for the edges of flat regions.
+func CollapseError(geom *Geometry, edgeStart, edgeEnd int) float32 {
+
+	qa := QuadricErrorMatrix(geom, edgeStart)
+	qb := QuadricErrorMatrix(geom, edgeEnd)
+	positions, _ := meshTriangles(geom)
+	var q math32.Matrix4
+	for i := range q {
+		q[i] = qa[i] + qb[i]
+	}
+	_, err := quadricCollapse(&q, &positions[edgeStart], &positions[edgeEnd])
+	return err
+}
+
+// quadricCollapse returns the position of a vertex replacing the vertices a and b with the smallest error
+// for the specified quadric error matrix, and its error.
+func quadricCollapse(q *math32.Matrix4, a, b *math32.Vector3) (math32.Vector3, float32) {
+
+	var mid math32.Vector3
+	mid.Copy(a).Lerp(b, 0.5)
+	candidates := []math32.Vector3{*a, *b, mid}
+
+	// The gradient of the error is zero where the first three rows of the matrix
+	// times the position are zero: the solution is the last column of the inverse
+	// of the matrix with its last row replaced by (0,0,0,1)
+	sys := *q
+	sys[3], sys[7], sys[11], sys[15] = 0, 0, 0, 1
+	var inv math32.Matrix4
+	if inv.GetInverse(&sys) == nil {
+		candidates = append(candidates, math32.Vector3{X: inv[12], Y: inv[13], Z: inv[14]})
+	}
+	best, bestErr := candidates[0], quadricError(q, &candidates[0])
+	for i := 1; i < len(candidates); i++ {
+		// A nearly singular system may have a far worse solution than the fallbacks
+		if e := quadricError(q, &candidates[i]); e < bestErr {
+			best, bestErr = candidates[i], e
+		}
+	}
+	return best, bestErr
+}
+
+// quadricError returns the error of the specified position for the specified quadric error matrix.
+func quadricError(q *math32.Matrix4, p *math32.Vector3) float32 {
+
+	v := [4]float32{p.X, p.Y, p.Z, 1}
+	var e float32
+	for col := 0; col < 4; col++ {
+		for row := 0; row < 4; row++ {
+			e += v[row] * q[col*4+row] * v[col]
+		}
+	}
+	// Rounding may make the error slightly negative
+	return math32.Max(e, 0)
+}
@@ -99,3 +99,11 @@ func NewSphere(radius float64, widthSegments, heightSegments int, phiStart, phiL
 
 	return s
 }
+
+// NewUVSphere returns a pointer to a new unit Sphere geometry parameterized by latitude and longitude,
+// with the specified number of latitude rings and longitude segments.
+// Its texture coordinates map the longitude to U and the latitude to V.
+func NewUVSphere(rings, segments int) *Sphere {
+
+	return NewSphere(1, segments, rings, 0, 2*math.Pi, 0, math.Pi)
+}
@@ -0,0 +1,86 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"errors"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// TessellateBezierSurface generates and returns a triangle geometry sampling the specified
+// bicubic Bezier surface at a uniform grid of (divisionsU+1)*(divisionsV+1) parameter values.
+func TessellateBezierSurface(surface *math32.CubicBezierSurface, divisionsU, divisionsV int) (*Geometry, error) {
+
+	return tessellateSurface(
+		func(u, v float32) math32.Vector3 {
+			var p math32.Vector3
+			surface.PointAt(u, v, &p)
+			return p
+		},
+		surface.NormalAt,
+		divisionsU, divisionsV)
+}
+
+// TessellateBSplineSurface generates and returns a triangle geometry sampling the specified
+// B-spline surface at a uniform grid of (divisionsU+1)*(divisionsV+1) parameter values.
+func TessellateBSplineSurface(surface *math32.BSplineSurface, divisionsU, divisionsV int) (*Geometry, error) {
+
+	return tessellateSurface(surface.PointAt, surface.NormalAt, divisionsU, divisionsV)
+}
+
+// tessellateSurface generates a triangle geometry sampling the parametric surface defined
+// by the specified position and normal functions over the unit square at a uniform grid of
+// (divisionsU+1)*(divisionsV+1) parameter values, with vertex positions, normals and
+// texture coordinates equal to the surface parameters.
+// Triangles are wound counter-clockwise around the cross product of the u and v directions.
+func tessellateSurface(pointAt, normalAt func(u, v float32) math32.Vector3, divisionsU, divisionsV int) (*Geometry, error) {
+
+	if divisionsU < 1 || divisionsV < 1 {
+		return nil, errors.New("surface divisions must be at least 1")
+	}
+
+	count := (divisionsU + 1) * (divisionsV + 1)
+	positions := math32.NewArrayF32(0, count*3)
+	normals := math32.NewArrayF32(0, count*3)
+	uvs := math32.NewArrayF32(0, count*2)
+	indices := math32.NewArrayU32(0, divisionsU*divisionsV*6)
+
+	for j := 0; j <= divisionsV; j++ {
+		v := float32(j) / float32(divisionsV)
+		for i := 0; i <= divisionsU; i++ {
+			u := float32(i) / float32(divisionsU)
+			p := pointAt(u, v)
+			n := normalAt(u, v)
+			positions.AppendVector3(&p)
+			normals.AppendVector3(&n)
+			uvs.Append(u, v)
+		}
+	}
+	stride := uint32(divisionsU + 1)
+	for j := 0; j < divisionsV; j++ {
+		for i := 0; i < divisionsU; i++ {
+			a := uint32(j)*stride + uint32(i)
+			b := a + 1
+			c := b + stride
+			d := a + stride
+			indices.Append(a, b, c, a, c, d)
+		}
+	}
+
+	geom := NewGeometry()
+	geom.SetIndices(indices)
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(normals).AddAttrib(gls.VertexNormal))
+	geom.AddVBO(gls.NewVBO(uvs).AddAttrib(gls.VertexTexcoord))
+	return geom, nil
+}
+
+// TessellateCoonsPatch generates and returns a triangle geometry sampling the specified
+// Coons patch at a uniform grid of (divisionsU+1)*(divisionsV+1) parameter values.
+func TessellateCoonsPatch(patch *math32.CoonsPatch, divisionsU, divisionsV int) (*Geometry, error) {
+
+	return tessellateSurface(patch.PointAt, patch.NormalAt, divisionsU, divisionsV)
+}
@@ -0,0 +1,89 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// UnwrapPlanar returns the texture coordinates of the vertices of the specified geometry projected on the plane
+// perpendicular to the specified normal, scaled into [0,1] keeping their aspect ratio, and sets them as its
+// texture coordinates. It is fast but stretches the texture on the faces which are not facing the normal.
+func UnwrapPlanar(geom *Geometry, normal *math32.Vector3) []math32.Vector2 {
+
+	positions, _ := meshTriangles(geom)
+
+	// Axes of the plane, from the axis least aligned with the normal
+	var n, axisU, axisV math32.Vector3
+	n.Copy(normal).Normalize()
+	switch {
+	case math32.Abs(n.X) <= math32.Abs(n.Y) && math32.Abs(n.X) <= math32.Abs(n.Z):
+		axisU.Set(1, 0, 0)
+	case math32.Abs(n.Y) <= math32.Abs(n.Z):
+		axisU.Set(0, 1, 0)
+	default:
+		axisU.Set(0, 0, 1)
+	}
+	axisV.CrossVectors(&n, &axisU).Normalize()
+	axisU.CrossVectors(&axisV, &n)
+
+	uvs := make([]math32.Vector2, len(positions))
+	for i := range positions {
+		uvs[i].Set(positions[i].Dot(&axisU), positions[i].Dot(&axisV))
+	}
+	normalizeUVs(uvs)
+	setTexcoords(geom, uvs)
+	return uvs
+}
+
+// normalizeUVs translates and scales the specified texture coordinates into [0,1] keeping their aspect ratio.
+func normalizeUVs(uvs []math32.Vector2) {
+
+	if len(uvs) == 0 {
+		return
+	}
+	min, max := uvs[0], uvs[0]
+	for i := range uvs {
+		min.Min(&uvs[i])
+		max.Max(&uvs[i])
+	}
+	size := math32.Max(max.X-min.X, max.Y-min.Y)
+	if size == 0 {
+		size = 1
+	}
+	for i := range uvs {
+		uvs[i].Sub(&min).MultiplyScalar(1 / size)
+	}
+}
+
+// setTexcoords sets the specified texture coordinates of the vertices of the specified geometry,
+// replacing its texture coordinates or adding a VBO with them.
+func setTexcoords(geom *Geometry, uvs []math32.Vector2) {
+
+	vbo := geom.VBO(gls.VertexTexcoord)
+	if vbo == nil || vbo.AttribCount() == 1 {
+		buffer := math32.NewArrayF32(0, 2*len(uvs))
+		for i := range uvs {
+			buffer.Append(uvs[i].X, uvs[i].Y)
+		}
+		if vbo == nil {
+			geom.AddVBO(gls.NewVBO(buffer).AddAttrib(gls.VertexTexcoord))
+		} else {
+			vbo.SetBuffer(buffer)
+		}
+		return
+	}
+	// Interleaved with other attributes of the same vertices
+	buffer := *vbo.Buffer()
+	stride := vbo.Stride()
+	offset := vbo.AttribOffset(gls.VertexTexcoord)
+	for i := range uvs {
+		if pos := i*stride + offset; pos+2 <= len(buffer) {
+			buffer.SetVector2(pos, &uvs[i])
+		}
+	}
+	vbo.Update()
+}
@@ -0,0 +1,402 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"errors"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"sort"
+)
+
+// Voxel grid chunk dimensions
+const (
+	voxelChunkShift = 4
+	voxelChunkSize  = 1 << voxelChunkShift
+	voxelChunkMask  = voxelChunkSize - 1
+	voxelChunkCells = voxelChunkSize * voxelChunkSize * voxelChunkSize
+)
+
+// VoxelGrid is a sparse unbounded 3D grid of float32 voxel values.
+// Voxels are stored in chunks of 16x16x16 voxels which are only allocated
+// when a non zero value is stored in them. Chunks can be run-length compressed
+// by Compact() and are transparently decompressed when modified.
+// The voxel with integer coordinates (x,y,z) occupies the cube from
+// (x,y,z)*cellSize to (x+1,y+1,z+1)*cellSize.
+type VoxelGrid struct {
+	cellSize float32
+	chunks   map[voxelChunkKey]*voxelChunk
+}
+
+// voxelChunkKey identifies a chunk by the coordinates of its first voxel divided by the chunk size.
+type voxelChunkKey struct {
+	x, y, z int32
+}
+
+// voxelChunk stores the voxels of a chunk either as a dense array or as a list of runs.
+type voxelChunk struct {
+	dense []float32  // dense voxel values or nil if compressed
+	runs  []voxelRun // run-length compressed voxel values
+	nz    int        // number of non zero voxels when dense
+}
+
+// voxelRun is a sequence of voxels with the same value,
+// starting at the specified linear index inside the chunk.
+type voxelRun struct {
+	start uint16
+	value float32
+}
+
+// NewVoxelGrid creates and returns a pointer to a new empty VoxelGrid
+// with the specified voxel size.
+func NewVoxelGrid(cellSize float32) *VoxelGrid {
+
+	vg := new(VoxelGrid)
+	vg.cellSize = cellSize
+	vg.chunks = make(map[voxelChunkKey]*voxelChunk)
+	return vg
+}
+
+// CellSize returns the size of the voxels of this grid.
+func (vg *VoxelGrid) CellSize() float32 {
+
+	return vg.cellSize
+}
+
+// Get returns the value of the voxel at the specified integer coordinates.
+// Voxels which were never set have value zero.
+func (vg *VoxelGrid) Get(x, y, z int32) float32 {
+
+	c := vg.chunks[voxelChunkKey{x >> voxelChunkShift, y >> voxelChunkShift, z >> voxelChunkShift}]
+	if c == nil {
+		return 0
+	}
+	return c.get(voxelIndex(x, y, z))
+}
+
+// Set sets the value of the voxel at the specified integer coordinates.
+// Chunks whose voxels are all set to zero are released.
+func (vg *VoxelGrid) Set(x, y, z int32, value float32) {
+
+	key := voxelChunkKey{x >> voxelChunkShift, y >> voxelChunkShift, z >> voxelChunkShift}
+	c := vg.chunks[key]
+	if c == nil {
+		if value == 0 {
+			return
+		}
+		c = &voxelChunk{dense: make([]float32, voxelChunkCells)}
+		vg.chunks[key] = c
+	}
+	c.set(voxelIndex(x, y, z), value)
+	if c.nz == 0 {
+		delete(vg.chunks, key)
+	}
+}
+
+// Fill sets to the specified value all the voxels whose centers are inside the specified box.
+func (vg *VoxelGrid) Fill(bounds *math32.Box3, value float32) {
+
+	if bounds.Empty() {
+		return
+	}
+	x0, y0, z0 := vg.cellRange(&bounds.Min, true)
+	x1, y1, z1 := vg.cellRange(&bounds.Max, false)
+	for z := z0; z <= z1; z++ {
+		for y := y0; y <= y1; y++ {
+			for x := x0; x <= x1; x++ {
+				vg.Set(x, y, z, value)
+			}
+		}
+	}
+}
+
+// Cell returns the integer coordinates of the voxel containing the specified point.
+func (vg *VoxelGrid) Cell(p *math32.Vector3) (x, y, z int32) {
+
+	return int32(math32.Floor(p.X / vg.cellSize)),
+		int32(math32.Floor(p.Y / vg.cellSize)),
+		int32(math32.Floor(p.Z / vg.cellSize))
+}
+
+// CellCenter returns the position of the center of the voxel with the specified integer coordinates.
+func (vg *VoxelGrid) CellCenter(x, y, z int32) math32.Vector3 {
+
+	return math32.Vector3{
+		X: (float32(x) + 0.5) * vg.cellSize,
+		Y: (float32(y) + 0.5) * vg.cellSize,
+		Z: (float32(z) + 0.5) * vg.cellSize,
+	}
+}
+
+// Bounds returns the integer coordinates range of the allocated chunks.
+// Returns false if the grid is empty.
+func (vg *VoxelGrid) Bounds() (min, max [3]int32, ok bool) {
+
+	for key := range vg.chunks {
+		k := [3]int32{key.x << voxelChunkShift, key.y << voxelChunkShift, key.z << voxelChunkShift}
+		for i := 0; i < 3; i++ {
+			if !ok || k[i] < min[i] {
+				min[i] = k[i]
+			}
+			if !ok || k[i]+voxelChunkMask > max[i] {
+				max[i] = k[i] + voxelChunkMask
+			}
+		}
+		ok = true
+	}
+	return min, max, ok
+}
+
+// ReadVoxels iterates over the voxels with non zero values, in no particular order, and calls
+// the specified callback function with the coordinates and value of each voxel.
+// The callback function returns false to continue or true to break.
+func (vg *VoxelGrid) ReadVoxels(cb func(x, y, z int32, value float32) bool) {
+
+	for key, c := range vg.chunks {
+		x0, y0, z0 := key.x<<voxelChunkShift, key.y<<voxelChunkShift, key.z<<voxelChunkShift
+		visit := func(idx int, value float32) bool {
+			return cb(x0+int32(idx&voxelChunkMask), y0+int32(idx>>voxelChunkShift&voxelChunkMask), z0+int32(idx>>(2*voxelChunkShift)), value)
+		}
+		if c.dense != nil {
+			for idx, v := range c.dense {
+				if v != 0 && visit(idx, v) {
+					return
+				}
+			}
+			continue
+		}
+		for i, r := range c.runs {
+			if r.value == 0 {
+				continue
+			}
+			end := voxelChunkCells
+			if i+1 < len(c.runs) {
+				end = int(c.runs[i+1].start)
+			}
+			for idx := int(r.start); idx < end; idx++ {
+				if visit(idx, r.value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Compact run-length compresses all the chunks of this grid.
+// Compressed chunks are decompressed when any of their voxels is set.
+func (vg *VoxelGrid) Compact() {
+
+	for _, c := range vg.chunks {
+		c.compress()
+	}
+}
+
+// RayCast traverses the voxels along the specified ray using the DDA
+// (Digital Differential Analyzer) algorithm and returns the integer coordinates
+// of the first voxel with a non zero value.
+// Returns false if no such voxel is found.
+func (vg *VoxelGrid) RayCast(ray *math32.Ray) (x, y, z int32, hit bool) {
+
+	min, max, ok := vg.Bounds()
+	if !ok {
+		return 0, 0, 0, false
+	}
+	box := math32.Box3{
+		Min: math32.Vector3{X: float32(min[0]) * vg.cellSize, Y: float32(min[1]) * vg.cellSize, Z: float32(min[2]) * vg.cellSize},
+		Max: math32.Vector3{X: float32(max[0]+1) * vg.cellSize, Y: float32(max[1]+1) * vg.cellSize, Z: float32(max[2]+1) * vg.cellSize},
+	}
+
+	// Starts the traversal where the ray enters the allocated region
+	origin := ray.Origin()
+	dir := ray.Direction()
+	if !box.ContainsPoint(&origin) {
+		var entry math32.Vector3
+		if ray.IntersectBox(&box, &entry) == nil {
+			return 0, 0, 0, false
+		}
+		origin = entry
+	}
+
+	pos := [3]int32{}
+	pos[0], pos[1], pos[2] = vg.Cell(&origin)
+	o := [3]float32{origin.X, origin.Y, origin.Z}
+	d := [3]float32{dir.X, dir.Y, dir.Z}
+	var step [3]int32
+	var tMax, tDelta [3]float32
+	for i := 0; i < 3; i++ {
+		// The entry point may be exactly on the far boundary of the region
+		pos[i] = clampInt32(pos[i], min[i], max[i])
+		switch {
+		case d[i] > 0:
+			step[i] = 1
+			tMax[i] = ((float32(pos[i]+1))*vg.cellSize - o[i]) / d[i]
+			tDelta[i] = vg.cellSize / d[i]
+		case d[i] < 0:
+			step[i] = -1
+			tMax[i] = (float32(pos[i])*vg.cellSize - o[i]) / d[i]
+			tDelta[i] = -vg.cellSize / d[i]
+		default:
+			tMax[i] = math32.Infinity
+			tDelta[i] = math32.Infinity
+		}
+	}
+
+	for {
+		if vg.Get(pos[0], pos[1], pos[2]) != 0 {
+			return pos[0], pos[1], pos[2], true
+		}
+		// Advances along the axis with the nearest voxel boundary
+		axis := 0
+		if tMax[1] < tMax[axis] {
+			axis = 1
+		}
+		if tMax[2] < tMax[axis] {
+			axis = 2
+		}
+		if step[axis] == 0 {
+			return 0, 0, 0, false
+		}
+		pos[axis] += step[axis]
+		if pos[axis] < min[axis] || pos[axis] > max[axis] {
+			return 0, 0, 0, false
+		}
+		tMax[axis] += tDelta[axis]
+	}
+}
+
+// ToMesh extracts the isosurface of this grid at the specified iso value
+// using MarchingCubes and returns it as a geometry in world coordinates.
+// Voxel values are sampled at the voxel centers and voxels outside
+// the allocated chunks are considered to have value zero.
+func (vg *VoxelGrid) ToMesh(isoValue float32) (*Geometry, error) {
+
+	min, max, ok := vg.Bounds()
+	if !ok {
+		return nil, errors.New("voxel grid is empty")
+	}
+	// Pads the field with one voxel on each side so the surface is closed
+	width := int(max[0]-min[0]) + 3
+	height := int(max[1]-min[1]) + 3
+	depth := int(max[2]-min[2]) + 3
+	field := make([]float32, width*height*depth)
+	for key, c := range vg.chunks {
+		for idx := 0; idx < voxelChunkCells; idx++ {
+			v := c.get(idx)
+			if v == 0 {
+				continue
+			}
+			x := int(key.x<<voxelChunkShift-min[0]) + idx&voxelChunkMask + 1
+			y := int(key.y<<voxelChunkShift-min[1]) + (idx>>voxelChunkShift)&voxelChunkMask + 1
+			z := int(key.z<<voxelChunkShift-min[2]) + idx>>(2*voxelChunkShift) + 1
+			field[x+y*width+z*width*height] = v
+		}
+	}
+
+	geom, err := MarchingCubes(field, width, height, depth, isoValue, vg.cellSize)
+	if err != nil {
+		return nil, err
+	}
+	// Translates the vertices from field to world coordinates
+	offset := vg.CellCenter(min[0]-1, min[1]-1, min[2]-1)
+	geom.VBO(gls.VertexPosition).OperateOnVectors3(gls.VertexPosition, func(v *math32.Vector3) bool {
+		v.Add(&offset)
+		return false
+	})
+	return geom, nil
+}
+
+// cellRange returns the integer coordinates of the first (lower is true) or last voxel
+// whose center is at or after (or at or before) the specified point along each axis.
+func (vg *VoxelGrid) cellRange(p *math32.Vector3, lower bool) (x, y, z int32) {
+
+	f := math32.Floor
+	if lower {
+		f = math32.Ceil
+	}
+	return int32(f(p.X/vg.cellSize - 0.5)), int32(f(p.Y/vg.cellSize - 0.5)), int32(f(p.Z/vg.cellSize - 0.5))
+}
+
+// voxelIndex returns the linear index inside its chunk of the voxel with the specified coordinates.
+func voxelIndex(x, y, z int32) int {
+
+	return int(x&voxelChunkMask) | int(y&voxelChunkMask)<<voxelChunkShift | int(z&voxelChunkMask)<<(2*voxelChunkShift)
+}
+
+// clampInt32 clamps v to the closed interval [a, b].
+func clampInt32(v, a, b int32) int32 {
+
+	if v < a {
+		return a
+	}
+	if v > b {
+		return b
+	}
+	return v
+}
+
+// get returns the value of the voxel at the specified linear index.
+func (c *voxelChunk) get(idx int) float32 {
+
+	if c.dense != nil {
+		return c.dense[idx]
+	}
+	// Finds the last run starting at or before the index
+	i := sort.Search(len(c.runs), func(i int) bool { return int(c.runs[i].start) > idx })
+	return c.runs[i-1].value
+}
+
+// set sets the value of the voxel at the specified linear index, decompressing the chunk if necessary.
+func (c *voxelChunk) set(idx int, value float32) {
+
+	if c.dense == nil {
+		c.decompress()
+	}
+	old := c.dense[idx]
+	if old == 0 && value != 0 {
+		c.nz++
+	} else if old != 0 && value == 0 {
+		c.nz--
+	}
+	c.dense[idx] = value
+}
+
+// compress converts the dense voxel array of this chunk to a list of runs.
+func (c *voxelChunk) compress() {
+
+	if c.dense == nil {
+		return
+	}
+	runs := make([]voxelRun, 0, 4)
+	for i, v := range c.dense {
+		if i == 0 || v != runs[len(runs)-1].value {
+			runs = append(runs, voxelRun{uint16(i), v})
+		}
+	}
+	// Only keeps the compressed representation if it is smaller
+	if len(runs)*2 < voxelChunkCells {
+		c.runs = runs
+		c.dense = nil
+	}
+}
+
+// decompress converts the list of runs of this chunk to a dense voxel array.
+func (c *voxelChunk) decompress() {
+
+	c.dense = make([]float32, voxelChunkCells)
+	c.nz = 0
+	for i, r := range c.runs {
+		end := voxelChunkCells
+		if i+1 < len(c.runs) {
+			end = int(c.runs[i+1].start)
+		}
+		for idx := int(r.start); idx < end; idx++ {
+			c.dense[idx] = r.value
+		}
+		if r.value != 0 {
+			c.nz += end - int(r.start)
+		}
+	}
+	c.runs = nil
+}
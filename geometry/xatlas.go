@@ -0,0 +1,100 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build xatlas
+
+package geometry
+
+// #cgo LDFLAGS: -lxatlas -lstdc++ -lm
+// #include <stdlib.h>
+// #include <xatlas_c.h>
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/g3n/engine/math32"
+)
+
+// UnwrapXAtlas returns the texture coordinates of the vertices of the specified geometry segmented into charts
+// packed in an atlas by the xatlas library, scaled into [0,1] by the size of the atlas, with the size of the atlas
+// in pixels, and sets them as its texture coordinates. The vertices are duplicated along the seams of the charts,
+// so the attributes and the indices of the geometry are replaced by the ones of the vertices of the atlas.
+// Requires building with the xatlas tag and the xatlas library with its C API.
+func UnwrapXAtlas(geom *Geometry) (uvs []math32.Vector2, atlasWidth, atlasHeight int, err error) {
+
+	positions, indices := meshTriangles(geom)
+	if len(indices) < 3 {
+		return nil, 0, 0, errors.New("geometry has no triangles")
+	}
+
+	// The declaration passed to C must not point to Go memory
+	cpos := (*C.float)(C.malloc(C.size_t(len(positions) * 3 * 4)))
+	defer C.free(unsafe.Pointer(cpos))
+	pos := unsafe.Slice(cpos, 3*len(positions))
+	for i := range positions {
+		pos[3*i], pos[3*i+1], pos[3*i+2] = C.float(positions[i].X), C.float(positions[i].Y), C.float(positions[i].Z)
+	}
+	cidx := (*C.uint32_t)(C.malloc(C.size_t(len(indices) * 4)))
+	defer C.free(unsafe.Pointer(cidx))
+	idx := unsafe.Slice(cidx, len(indices))
+	for i := range indices {
+		idx[i] = C.uint32_t(indices[i])
+	}
+
+	atlas := C.xatlasCreate()
+	defer C.xatlasDestroy(atlas)
+	var decl C.xatlasMeshDecl
+	C.xatlasMeshDeclInit(&decl)
+	decl.vertexPositionData = unsafe.Pointer(cpos)
+	decl.vertexCount = C.uint32_t(len(positions))
+	decl.vertexPositionStride = 12
+	decl.indexData = unsafe.Pointer(cidx)
+	decl.indexCount = C.uint32_t(len(indices))
+	decl.indexFormat = C.XATLAS_INDEX_FORMAT_UINT32
+	if e := C.xatlasAddMesh(atlas, &decl, 1); e != C.XATLAS_ADD_MESH_ERROR_SUCCESS {
+		return nil, 0, 0, fmt.Errorf("xatlas: %s", C.GoString(C.xatlasAddMeshErrorString(e)))
+	}
+	var chartOptions C.xatlasChartOptions
+	var packOptions C.xatlasPackOptions
+	C.xatlasChartOptionsInit(&chartOptions)
+	C.xatlasPackOptionsInit(&packOptions)
+	C.xatlasGenerate(atlas, &chartOptions, &packOptions)
+	if atlas.meshCount != 1 || atlas.width == 0 || atlas.height == 0 {
+		return nil, 0, 0, errors.New("xatlas: no atlas generated")
+	}
+	atlasWidth, atlasHeight = int(atlas.width), int(atlas.height)
+
+	// Vertices of the atlas, each referencing a vertex of the geometry
+	mesh := &unsafe.Slice(atlas.meshes, atlas.meshCount)[0]
+	verts := unsafe.Slice(mesh.vertexArray, mesh.vertexCount)
+	xref := make([]uint32, len(verts))
+	uvs = make([]math32.Vector2, len(verts))
+	for i := range verts {
+		xref[i] = uint32(verts[i].xref)
+		uvs[i].Set(float32(verts[i].uv[0])/float32(atlasWidth), float32(verts[i].uv[1])/float32(atlasHeight))
+	}
+	newIndices := math32.NewArrayU32(0, int(mesh.indexCount))
+	for _, i := range unsafe.Slice(mesh.indexArray, mesh.indexCount) {
+		newIndices.Append(uint32(i))
+	}
+
+	// The attributes of the geometry are copied for the vertices of the atlas
+	for _, vbo := range geom.VBOs() {
+		stride := vbo.Stride()
+		old := *vbo.Buffer()
+		buffer := math32.NewArrayF32(len(xref)*stride, len(xref)*stride)
+		for i, v := range xref {
+			if src := int(v) * stride; src+stride <= len(old) {
+				copy(buffer[i*stride:(i+1)*stride], old[src:src+stride])
+			}
+		}
+		vbo.SetBuffer(buffer)
+	}
+	geom.SetIndices(newIndices)
+	setTexcoords(geom, uvs)
+	return uvs, atlasWidth, atlasHeight, nil
+}
@@ -212,6 +212,19 @@ func (gs *GLS) BindBuffer(target int, vbo uint32) {
 	C.glBindBuffer(C.GLenum(target), C.GLuint(vbo))
 }
 
+// BindFramebuffer binds a framebuffer object to the specified target.
+// The zero framebuffer is the default framebuffer of the window.
+func (gs *GLS) BindFramebuffer(target uint32, fb uint32) {
+
+	C.glBindFramebuffer(C.GLenum(target), C.GLuint(fb))
+}
+
+// BindRenderbuffer binds a renderbuffer object to the specified target.
+func (gs *GLS) BindRenderbuffer(target uint32, rb uint32) {
+
+	C.glBindRenderbuffer(C.GLenum(target), C.GLuint(rb))
+}
+
 // BindTexture lets you create or use a named texture.
 func (gs *GLS) BindTexture(target int, tex uint32) {
 
@@ -273,6 +286,13 @@ func (gs *GLS) BlendFuncSeparate(srcRGB uint32, dstRGB uint32, srcAlpha uint32,
 	gs.blendDstAlpha = dstAlpha
 }
 
+// BlitFramebuffer copies a block of pixels from the read framebuffer to the draw framebuffer.
+func (gs *GLS) BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 int32, mask uint32, filter uint32) {
+
+	C.glBlitFramebuffer(C.GLint(srcX0), C.GLint(srcY0), C.GLint(srcX1), C.GLint(srcY1),
+		C.GLint(dstX0), C.GLint(dstY0), C.GLint(dstX1), C.GLint(dstY1), C.GLbitfield(mask), C.GLenum(filter))
+}
+
 // BufferData creates a new data store for the buffer object currently
 // bound to target, deleting any pre-existing data store.
 func (gs *GLS) BufferData(target uint32, size int, data interface{}, usage uint32) {
@@ -280,6 +300,19 @@ func (gs *GLS) BufferData(target uint32, size int, data interface{}, usage uint3
 	C.glBufferData(C.GLenum(target), C.GLsizeiptr(size), ptr(data), C.GLenum(usage))
 }
 
+// BufferSubData updates a subset of the data store of the buffer object currently
+// bound to target starting at the specified byte offset.
+func (gs *GLS) BufferSubData(target uint32, offset int, size int, data interface{}) {
+
+	C.glBufferSubData(C.GLenum(target), C.GLintptr(offset), C.GLsizeiptr(size), ptr(data))
+}
+
+// CheckFramebufferStatus returns the completeness status of the framebuffer bound to the specified target.
+func (gs *GLS) CheckFramebufferStatus(target uint32) uint32 {
+
+	return uint32(C.glCheckFramebufferStatus(C.GLenum(target)))
+}
+
 // ClearColor specifies the red, green, blue, and alpha values
 // used by glClear to clear the color buffers.
 func (gs *GLS) ClearColor(r, g, b, a float32) {
@@ -325,6 +358,18 @@ func (gs *GLS) DeleteBuffers(bufs ...uint32) {
 	gs.stats.Buffers -= len(bufs)
 }
 
+// DeleteFramebuffers deletes the specified framebuffer objects.
+func (gs *GLS) DeleteFramebuffers(fbs ...uint32) {
+
+	C.glDeleteFramebuffers(C.GLsizei(len(fbs)), (*C.GLuint)(&fbs[0]))
+}
+
+// DeleteRenderbuffers deletes the specified renderbuffer objects.
+func (gs *GLS) DeleteRenderbuffers(rbs ...uint32) {
+
+	C.glDeleteRenderbuffers(C.GLsizei(len(rbs)), (*C.GLuint)(&rbs[0]))
+}
+
 // DeleteShader frees the memory and invalidates the name
 // associated with the specified shader object.
 func (gs *GLS) DeleteShader(shader uint32) {
@@ -390,6 +435,13 @@ func (gs *GLS) DrawArrays(mode uint32, first int32, count int32) {
 	gs.stats.Drawcalls++
 }
 
+// DrawArraysInstanced renders the specified number of instances of primitives from array data.
+func (gs *GLS) DrawArraysInstanced(mode uint32, first int32, count int32, instances int32) {
+
+	C.glDrawArraysInstanced(C.GLenum(mode), C.GLint(first), C.GLsizei(count), C.GLsizei(instances))
+	gs.stats.Drawcalls++
+}
+
 // DrawBuffer specifies which color buffers are to be drawn into.
 func (gs *GLS) DrawBuffer(mode uint32) {
 
@@ -403,6 +455,13 @@ func (gs *GLS) DrawElements(mode uint32, count int32, itype uint32, start uint32
 	gs.stats.Drawcalls++
 }
 
+// DrawElementsInstanced renders the specified number of instances of primitives from indexed array data.
+func (gs *GLS) DrawElementsInstanced(mode uint32, count int32, itype uint32, start uint32, instances int32) {
+
+	C.glDrawElementsInstanced(C.GLenum(mode), C.GLsizei(count), C.GLenum(itype), unsafe.Pointer(uintptr(start)), C.GLsizei(instances))
+	gs.stats.Drawcalls++
+}
+
 // Enable enables the specified capability.
 func (gs *GLS) Enable(cap int) {
 
@@ -437,6 +496,18 @@ func (gs *GLS) CullFace(mode uint32) {
 	C.glCullFace(C.GLenum(mode))
 }
 
+// FramebufferRenderbuffer attaches a renderbuffer object to the framebuffer bound to the specified target.
+func (gs *GLS) FramebufferRenderbuffer(target, attachment, rbtarget uint32, rb uint32) {
+
+	C.glFramebufferRenderbuffer(C.GLenum(target), C.GLenum(attachment), C.GLenum(rbtarget), C.GLuint(rb))
+}
+
+// FramebufferTexture2D attaches a level of a texture object to the framebuffer bound to the specified target.
+func (gs *GLS) FramebufferTexture2D(target, attachment, textarget uint32, tex uint32, level int32) {
+
+	C.glFramebufferTexture2D(C.GLenum(target), C.GLenum(attachment), C.GLenum(textarget), C.GLuint(tex), C.GLint(level))
+}
+
 // FrontFace defines front- and back-facing polygons.
 func (gs *GLS) FrontFace(mode uint32) {
 
@@ -447,6 +518,22 @@ func (gs *GLS) FrontFace(mode uint32) {
 	gs.frontFace = mode
 }
 
+// GenFramebuffer generates a framebuffer object name.
+func (gs *GLS) GenFramebuffer() uint32 {
+
+	var fb uint32
+	C.glGenFramebuffers(1, (*C.GLuint)(&fb))
+	return fb
+}
+
+// GenRenderbuffer generates a renderbuffer object name.
+func (gs *GLS) GenRenderbuffer() uint32 {
+
+	var rb uint32
+	C.glGenRenderbuffers(1, (*C.GLuint)(&rb))
+	return rb
+}
+
 // GenBuffer generates a​buffer object name.
 func (gs *GLS) GenBuffer() uint32 {
 
@@ -480,6 +567,19 @@ func (gs *GLS) GenVertexArray() uint32 {
 	return vao
 }
 
+// GetActiveUniform returns the name, array size and type of the active uniform
+// with the specified index of the specified program object.
+func (gs *GLS) GetActiveUniform(program, index uint32) (name string, size int32, utype uint32) {
+
+	var maxLength int32
+	gs.GetProgramiv(program, ACTIVE_UNIFORM_MAX_LENGTH, &maxLength)
+	var length C.GLsizei
+	var csize C.GLint
+	var ctype C.GLenum
+	C.glGetActiveUniform(C.GLuint(program), C.GLuint(index), C.GLsizei(maxLength), &length, &csize, &ctype, gs.gobufSize(uint32(maxLength)))
+	return string(gs.gobuf[:length]), int32(csize), uint32(ctype)
+}
+
 // GetAttribLocation returns the location of the specified attribute variable.
 func (gs *GLS) GetAttribLocation(program uint32, name string) int32 {
 
@@ -565,6 +665,13 @@ func (gs *GLS) Scissor(x, y int32, width, height uint32) {
 	C.glScissor(C.GLint(x), C.GLint(y), C.GLsizei(width), C.GLsizei(height))
 }
 
+// RenderbufferStorageMultisample creates the data store of the renderbuffer bound to the specified
+// target with the specified number of samples, internal format and size.
+func (gs *GLS) RenderbufferStorageMultisample(target uint32, samples int32, iformat uint32, width, height int32) {
+
+	C.glRenderbufferStorageMultisample(C.GLenum(target), C.GLsizei(samples), C.GLenum(iformat), C.GLsizei(width), C.GLsizei(height))
+}
+
 // ShaderSource sets the source code for the specified shader object.
 func (gs *GLS) ShaderSource(shader uint32, src string) {
 
@@ -586,6 +693,37 @@ func (gs *GLS) TexImage2D(target uint32, level int32, iformat int32, width int32
 		ptr(data))
 }
 
+// TexImage3D specifies a three-dimensional texture image or an array of two-dimensional texture images.
+func (gs *GLS) TexImage3D(target uint32, level int32, iformat int32, width int32, height int32, depth int32, border int32, format uint32, itype uint32, data interface{}) {
+
+	C.glTexImage3D(C.GLenum(target),
+		C.GLint(level),
+		C.GLint(iformat),
+		C.GLsizei(width),
+		C.GLsizei(height),
+		C.GLsizei(depth),
+		C.GLint(border),
+		C.GLenum(format),
+		C.GLenum(itype),
+		ptr(data))
+}
+
+// TexSubImage3D specifies a region of a three-dimensional texture image, such as one layer of a texture array.
+func (gs *GLS) TexSubImage3D(target uint32, level int32, xoffset, yoffset, zoffset int32, width, height, depth int32, format uint32, itype uint32, data interface{}) {
+
+	C.glTexSubImage3D(C.GLenum(target),
+		C.GLint(level),
+		C.GLint(xoffset),
+		C.GLint(yoffset),
+		C.GLint(zoffset),
+		C.GLsizei(width),
+		C.GLsizei(height),
+		C.GLsizei(depth),
+		C.GLenum(format),
+		C.GLenum(itype),
+		ptr(data))
+}
+
 // TexParameteri sets the specified texture parameter on the specified texture.
 func (gs *GLS) TexParameteri(target uint32, pname uint32, param int32) {
 
@@ -709,6 +847,20 @@ func (gs *GLS) Uniform4fvUP(location int32, count int32, v unsafe.Pointer) {
 	gs.stats.Unisets++
 }
 
+// ValidateProgram checks whether the specified program can execute given the current OpenGL state.
+// The result is returned by GetProgramiv with VALIDATE_STATUS.
+func (gs *GLS) ValidateProgram(program uint32) {
+
+	C.glValidateProgram(C.GLuint(program))
+}
+
+// VertexAttribDivisor sets the number of instances drawn with each value of the specified
+// generic vertex attribute. A zero divisor advances the attribute per vertex.
+func (gs *GLS) VertexAttribDivisor(index uint32, divisor uint32) {
+
+	C.glVertexAttribDivisor(C.GLuint(index), C.GLuint(divisor))
+}
+
 // VertexAttribPointer defines an array of generic vertex attribute data.
 func (gs *GLS) VertexAttribPointer(index uint32, size int32, xtype uint32, normalized bool, stride int32, offset uint32) {
 
@@ -0,0 +1,179 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gls
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/g3n/engine/math32"
+)
+
+// TextureID is the OpenGL handle of a texture object.
+type TextureID uint32
+
+// UniformInfo describes an active uniform of a shader program.
+type UniformInfo struct {
+	Name     string
+	Type     uint32 // OpenGL type such as FLOAT_MAT4 or SAMPLER_2D
+	Size     int32  // number of elements of arrays, 1 otherwise
+	Location int32
+}
+
+// Shader is a program built from vertex and fragment shader sources whose active uniforms
+// are found by reflection after linking, so they can be set by name with typed methods.
+type Shader struct {
+	gs       *GLS
+	prog     *Program
+	uniforms map[string]*UniformInfo
+}
+
+// NewShader compiles and links the specified vertex and fragment shader sources and
+// returns a pointer to a new Shader with the active uniforms of the program.
+func (gs *GLS) NewShader(vertSrc, fragSrc string) (*Shader, error) {
+
+	prog := gs.NewProgram()
+	prog.AddShader(VERTEX_SHADER, vertSrc)
+	prog.AddShader(FRAGMENT_SHADER, fragSrc)
+	if err := prog.Build(); err != nil {
+		return nil, err
+	}
+
+	s := new(Shader)
+	s.gs = gs
+	s.prog = prog
+	s.uniforms = make(map[string]*UniformInfo)
+	var count int32
+	gs.GetProgramiv(prog.handle, ACTIVE_UNIFORMS, &count)
+	for i := 0; i < int(count); i++ {
+		name, size, utype := gs.GetActiveUniform(prog.handle, uint32(i))
+		info := &UniformInfo{Name: name, Type: utype, Size: size}
+		info.Location = gs.GetUniformLocation(prog.handle, name)
+		s.uniforms[name] = info
+		// Arrays are reported with the name of their first element
+		if strings.HasSuffix(name, "[0]") {
+			s.uniforms[strings.TrimSuffix(name, "[0]")] = info
+		}
+	}
+	return s, nil
+}
+
+// Program returns the program of this shader.
+func (s *Shader) Program() *Program {
+
+	return s.prog
+}
+
+// GLS returns the OpenGL state of this shader.
+func (s *Shader) GLS() *GLS {
+
+	return s.gs
+}
+
+// Uniforms returns the descriptions of the active uniforms of this shader sorted by name.
+func (s *Shader) Uniforms() []UniformInfo {
+
+	list := make([]UniformInfo, 0, len(s.uniforms))
+	for name, info := range s.uniforms {
+		if name == info.Name {
+			list = append(list, *info)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Uniform returns the description of the active uniform with the specified name,
+// or nil if not found.
+func (s *Shader) Uniform(name string) *UniformInfo {
+
+	return s.uniforms[name]
+}
+
+// Use sets this shader as the current program.
+func (s *Shader) Use() {
+
+	if s.gs.prog != s.prog {
+		s.gs.UseProgram(s.prog)
+	}
+}
+
+// location returns the location of the uniform with the specified name activating this shader,
+// or -1 if the uniform is not active, in which case setting it has no effect.
+func (s *Shader) location(name string) int32 {
+
+	info, ok := s.uniforms[name]
+	if !ok {
+		log.Warn("Shader: uniform %s NOT FOUND", name)
+		// Caches the missing uniform so the warning is not repeated
+		s.uniforms[name] = &UniformInfo{Location: -1}
+		return -1
+	}
+	if info.Location >= 0 {
+		s.Use()
+	}
+	return info.Location
+}
+
+// SetUniformMat4 sets the value of the mat4 uniform with the specified name.
+func (s *Shader) SetUniformMat4(name string, m *math32.Matrix4) {
+
+	if loc := s.location(name); loc >= 0 {
+		s.gs.UniformMatrix4fv(loc, 1, false, &m[0])
+	}
+}
+
+// SetUniformVec3 sets the value of the vec3 uniform with the specified name.
+func (s *Shader) SetUniformVec3(name string, v *math32.Vector3) {
+
+	if loc := s.location(name); loc >= 0 {
+		s.gs.Uniform3f(loc, v.X, v.Y, v.Z)
+	}
+}
+
+// SetUniformFloat sets the value of the float uniform with the specified name.
+func (s *Shader) SetUniformFloat(name string, v float32) {
+
+	if loc := s.location(name); loc >= 0 {
+		s.gs.Uniform1f(loc, v)
+	}
+}
+
+// SetUniformInt sets the value of the int uniform with the specified name.
+func (s *Shader) SetUniformInt(name string, v int32) {
+
+	if loc := s.location(name); loc >= 0 {
+		s.gs.Uniform1i(loc, v)
+	}
+}
+
+// BindTexture binds the specified 2D texture to the specified texture unit
+// and sets the sampler uniform with the specified name to that unit.
+func (s *Shader) BindTexture(name string, tex TextureID, unit int) {
+
+	s.gs.ActiveTexture(TEXTURE0 + uint32(unit))
+	s.gs.BindTexture(TEXTURE_2D, uint32(tex))
+	if loc := s.location(name); loc >= 0 {
+		s.gs.Uniform1i(loc, int32(unit))
+	}
+}
+
+// Validate checks whether this shader can execute given the current OpenGL state.
+// Returns an error with the validation log if it cannot. Warnings of a valid program are logged.
+func (s *Shader) Validate() error {
+
+	s.gs.ValidateProgram(s.prog.handle)
+	var status int32
+	s.gs.GetProgramiv(s.prog.handle, VALIDATE_STATUS, &status)
+	info := strings.TrimSpace(strings.TrimRight(s.gs.GetProgramInfoLog(s.prog.handle), "\x00"))
+	if status == FALSE {
+		return fmt.Errorf("error validating program: %v", info)
+	}
+	if info != "" {
+		log.Warn("%s", info)
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphic
+
+import (
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// DebugRenderer is the interface for objects which draw debug
+// visualizations of spatial data such as lines, boxes and spheres.
+// The draw calls of a frame are submitted by Flush.
+type DebugRenderer interface {
+	DrawLine3(l *math32.Line3, color *math32.Color)
+	DrawBox3(b *math32.Box3, color *math32.Color)
+	DrawSphere(s *math32.Sphere, color *math32.Color, segments int)
+	DrawVector3(origin, dir *math32.Vector3, color *math32.Color)
+	DrawText(pos *math32.Vector3, text string, color *math32.Color)
+	Flush()
+}
+
+// debugRenderer is the renderer which receives the engine debug output.
+var debugRenderer DebugRenderer = NopDebugRenderer{}
+
+// SetDebugRenderer sets the renderer which receives the engine debug output.
+// A nil renderer discards the debug output.
+func SetDebugRenderer(r DebugRenderer) {
+
+	if r == nil {
+		r = NopDebugRenderer{}
+	}
+	debugRenderer = r
+}
+
+// GetDebugRenderer returns the renderer which receives the engine debug output.
+func GetDebugRenderer() DebugRenderer {
+
+	return debugRenderer
+}
+
+// NopDebugRenderer is a DebugRenderer which discards all draw calls.
+type NopDebugRenderer struct{}
+
+// DrawLine3 satisfies the DebugRenderer interface and does nothing.
+func (NopDebugRenderer) DrawLine3(l *math32.Line3, color *math32.Color) {}
+
+// DrawBox3 satisfies the DebugRenderer interface and does nothing.
+func (NopDebugRenderer) DrawBox3(b *math32.Box3, color *math32.Color) {}
+
+// DrawSphere satisfies the DebugRenderer interface and does nothing.
+func (NopDebugRenderer) DrawSphere(s *math32.Sphere, color *math32.Color, segments int) {}
+
+// DrawVector3 satisfies the DebugRenderer interface and does nothing.
+func (NopDebugRenderer) DrawVector3(origin, dir *math32.Vector3, color *math32.Color) {}
+
+// DrawText satisfies the DebugRenderer interface and does nothing.
+func (NopDebugRenderer) DrawText(pos *math32.Vector3, text string, color *math32.Color) {}
+
+// Flush satisfies the DebugRenderer interface and does nothing.
+func (NopDebugRenderer) Flush() {}
+
+// DebugText is a text label submitted to a BatchDebugRenderer.
+type DebugText struct {
+	Position math32.Vector3 // position of the label in world coordinates
+	Text     string         // label text
+	Color    math32.Color   // label color
+}
+
+// BatchDebugRenderer is a DebugRenderer which accumulates its draw calls into the
+// vertex buffers of a Lines graphic, which should be added to the scene.
+// Flush transfers the primitives drawn since the previous flush to the graphic.
+// The text labels are not rendered and are made available by Labels, so the
+// application can draw them with its own GUI.
+type BatchDebugRenderer struct {
+	Lines
+	positions math32.ArrayF32 // positions accumulated since the last flush
+	colors    math32.ArrayF32 // colors accumulated since the last flush
+	texts     []DebugText     // labels accumulated since the last flush
+	labels    []DebugText     // labels of the last flush
+	posVBO    *gls.VBO
+	colorVBO  *gls.VBO
+}
+
+// NewBatchDebugRenderer creates and returns a pointer to a new BatchDebugRenderer.
+func NewBatchDebugRenderer() *BatchDebugRenderer {
+
+	dr := new(BatchDebugRenderer)
+	dr.positions = math32.NewArrayF32(0, 0)
+	dr.colors = math32.NewArrayF32(0, 0)
+	geom := geometry.NewGeometry()
+	dr.posVBO = gls.NewVBO(math32.NewArrayF32(0, 0)).AddAttrib(gls.VertexPosition)
+	dr.colorVBO = gls.NewVBO(math32.NewArrayF32(0, 0)).AddAttrib(gls.VertexColor)
+	geom.AddVBO(dr.posVBO)
+	geom.AddVBO(dr.colorVBO)
+	dr.Lines.Init(geom, material.NewBasic())
+	// The lines change at each frame and may be anywhere
+	dr.SetCullable(false)
+	return dr
+}
+
+// line adds a line with the specified end points and color.
+func (dr *BatchDebugRenderer) line(a, b *math32.Vector3, color *math32.Color) {
+
+	dr.positions.AppendVector3(a, b)
+	dr.colors.AppendColor(color, color)
+}
+
+// DrawLine3 draws the specified line segment.
+func (dr *BatchDebugRenderer) DrawLine3(l *math32.Line3, color *math32.Color) {
+
+	dr.line(l.Start(), l.End(), color)
+}
+
+// DrawBox3 draws the edges of the specified box.
+func (dr *BatchDebugRenderer) DrawBox3(b *math32.Box3, color *math32.Color) {
+
+	var corners [8]math32.Vector3
+	for i := range corners {
+		corners[i] = b.Min
+		if i&1 != 0 {
+			corners[i].X = b.Max.X
+		}
+		if i&2 != 0 {
+			corners[i].Y = b.Max.Y
+		}
+		if i&4 != 0 {
+			corners[i].Z = b.Max.Z
+		}
+	}
+	// Each edge joins corners which differ in a single coordinate
+	for i := range corners {
+		for bit := 1; bit < 8; bit <<= 1 {
+			if i&bit == 0 {
+				dr.line(&corners[i], &corners[i|bit], color)
+			}
+		}
+	}
+}
+
+// DrawSphere draws three great circles of the specified sphere on
+// the coordinate planes, each with the specified number of segments.
+func (dr *BatchDebugRenderer) DrawSphere(s *math32.Sphere, color *math32.Color, segments int) {
+
+	if segments < 3 {
+		segments = 3
+	}
+	point := func(axis, i int) math32.Vector3 {
+		angle := 2 * math32.Pi * float32(i) / float32(segments)
+		c := s.Radius * math32.Cos(angle)
+		sn := s.Radius * math32.Sin(angle)
+		p := s.Center
+		switch axis {
+		case 0:
+			p.Y += c
+			p.Z += sn
+		case 1:
+			p.Z += c
+			p.X += sn
+		default:
+			p.X += c
+			p.Y += sn
+		}
+		return p
+	}
+	for axis := 0; axis < 3; axis++ {
+		prev := point(axis, 0)
+		for i := 1; i <= segments; i++ {
+			p := point(axis, i)
+			dr.line(&prev, &p, color)
+			prev = p
+		}
+	}
+}
+
+// DrawVector3 draws an arrow from the specified origin to origin+dir.
+func (dr *BatchDebugRenderer) DrawVector3(origin, dir *math32.Vector3, color *math32.Color) {
+
+	var tip math32.Vector3
+	tip.AddVectors(origin, dir)
+	dr.line(origin, &tip, color)
+
+	length := dir.Length()
+	if length == 0 {
+		return
+	}
+	// Head formed by four lines back from the tip around the direction
+	var side, up math32.Vector3
+	side.Set(1, 0, 0)
+	if math32.Abs(dir.X) > 0.9*length {
+		side.Set(0, 1, 0)
+	}
+	side.Cross(dir).Normalize().MultiplyScalar(0.1 * length)
+	up.CrossVectors(dir, &side).Normalize().MultiplyScalar(0.1 * length)
+	back := *dir
+	back.MultiplyScalar(-0.2).Add(&tip)
+	for _, offset := range []*math32.Vector3{&side, &up, side.Clone().Negate(), up.Clone().Negate()} {
+		p := back
+		p.Add(offset)
+		dr.line(&tip, &p, color)
+	}
+}
+
+// DrawText adds a text label at the specified position.
+func (dr *BatchDebugRenderer) DrawText(pos *math32.Vector3, text string, color *math32.Color) {
+
+	dr.texts = append(dr.texts, DebugText{Position: *pos, Text: text, Color: *color})
+}
+
+// Flush transfers the lines drawn since the previous flush to the graphic
+// and starts accumulating the primitives of the next frame.
+func (dr *BatchDebugRenderer) Flush() {
+
+	// Swaps the accumulation buffers with the buffers of the graphic to reuse their memory
+	prevPos := *dr.posVBO.Buffer()
+	prevColors := *dr.colorVBO.Buffer()
+	dr.posVBO.SetBuffer(dr.positions)
+	dr.colorVBO.SetBuffer(dr.colors)
+	dr.positions = prevPos[:0]
+	dr.colors = prevColors[:0]
+	dr.labels, dr.texts = dr.texts, dr.labels[:0]
+
+	// The lines are not indexed and setting the empty indices invalidates the cached bounding volumes
+	dr.GetGeometry().SetIndices(math32.NewArrayU32(0, 0))
+}
+
+// Labels returns the text labels drawn before the last flush.
+func (dr *BatchDebugRenderer) Labels() []DebugText {
+
+	return dr.labels
+}
+
+// LineCount returns the number of lines drawn before the last flush.
+func (dr *BatchDebugRenderer) LineCount() int {
+
+	return dr.posVBO.Buffer().Size() / 6
+}
@@ -275,6 +275,13 @@ func (gr *Graphic) ModelViewProjectionMatrix() *math32.Matrix4 {
 	return &gr.mvpm
 }
 
+// Range returns the index of the first element and the number of elements of the geometry
+// rendered with this material, which are 0 for all the elements.
+func (grmat *GraphicMaterial) Range() (start, count int) {
+
+	return grmat.start, grmat.count
+}
+
 // IMaterial returns the material associated with the GraphicMaterial.
 func (grmat *GraphicMaterial) IMaterial() material.IMaterial {
 
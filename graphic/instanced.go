@@ -0,0 +1,141 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphic
+
+import (
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// Names of the per instance attributes in the shaders used with InstancedRenderer.
+const (
+	InstanceMatrixAttrib = "InstanceMatrix" // mat4 model matrix
+	InstanceColorAttrib  = "InstanceColor"  // vec3 tint
+)
+
+// instanceFloats is the number of floats of each instance: a Matrix4 followed by a Color.
+const instanceFloats = 16 + 3
+
+// InstancedRenderer draws many instances of the same geometry in a single draw call with
+// hardware instancing. Each instance has its own transform and tint color, stored in an
+// instance buffer whose attributes advance once per instance.
+type InstancedRenderer struct {
+	geom      *geometry.Geometry
+	gs        *gls.GLS
+	vbo       uint32          // instance buffer handle
+	buffer    math32.ArrayF32 // instance data
+	count     int             // number of instances
+	capacity  int             // minimum number of instances to allocate in the instance buffer
+	allocated int             // number of instances allocated in the instance buffer
+	update    bool            // instance data needs to be sent
+}
+
+// NewInstancedRenderer creates and returns a pointer to a new InstancedRenderer of the specified
+// geometry whose instance buffer is initially allocated for the specified number of instances.
+func NewInstancedRenderer(mesh *geometry.Geometry, maxInstances int) *InstancedRenderer {
+
+	ir := new(InstancedRenderer)
+	ir.geom = mesh
+	ir.capacity = maxInstances
+	ir.buffer = math32.NewArrayF32(0, maxInstances*instanceFloats)
+	return ir
+}
+
+// Count returns the current number of instances.
+func (ir *InstancedRenderer) Count() int {
+
+	return ir.count
+}
+
+// SetInstances sets the transforms and tint colors of the instances, one per transform.
+// Instances without a corresponding color are white.
+func (ir *InstancedRenderer) SetInstances(transforms []math32.Matrix4, colors []math32.Color) {
+
+	ir.count = len(transforms)
+	ir.buffer = ir.buffer[:0]
+	for i := range transforms {
+		ir.buffer = append(ir.buffer, transforms[i][:]...)
+		if i < len(colors) {
+			ir.buffer.AppendColor(&colors[i])
+		} else {
+			ir.buffer.Append(1, 1, 1)
+		}
+	}
+	ir.update = true
+}
+
+// Draw draws all the instances with the specified shader, which must declare the
+// InstanceMatrixAttrib and InstanceColorAttrib attributes.
+func (ir *InstancedRenderer) Draw(shader *gls.Shader) {
+
+	if ir.count == 0 {
+		return
+	}
+	gs := shader.GLS()
+	shader.Use()
+	ir.geom.RenderSetup(gs)
+
+	// Transfers the instance data, reallocating the buffer only when it grows
+	if ir.gs == nil {
+		ir.vbo = gs.GenBuffer()
+		ir.gs = gs
+		ir.allocated = 0
+	}
+	const stride = instanceFloats * 4
+	gs.BindBuffer(gls.ARRAY_BUFFER, ir.vbo)
+	if ir.update {
+		if ir.grow() {
+			gs.BufferData(gls.ARRAY_BUFFER, ir.allocated*stride, nil, gls.DYNAMIC_DRAW)
+		}
+		gs.BufferSubData(gls.ARRAY_BUFFER, 0, ir.buffer.Bytes(), &ir.buffer[0])
+		ir.update = false
+	}
+
+	// Sets the instance attributes, a mat4 taking four consecutive locations
+	prog := shader.Program()
+	if loc := prog.GetAttribLocation(InstanceMatrixAttrib); loc >= 0 {
+		for col := uint32(0); col < 4; col++ {
+			gs.EnableVertexAttribArray(uint32(loc) + col)
+			gs.VertexAttribPointer(uint32(loc)+col, 4, gls.FLOAT, false, stride, col*16)
+			gs.VertexAttribDivisor(uint32(loc)+col, 1)
+		}
+	}
+	if loc := prog.GetAttribLocation(InstanceColorAttrib); loc >= 0 {
+		gs.EnableVertexAttribArray(uint32(loc))
+		gs.VertexAttribPointer(uint32(loc), 3, gls.FLOAT, false, stride, 16*4)
+		gs.VertexAttribDivisor(uint32(loc), 1)
+	}
+
+	if ir.geom.Indexed() {
+		gs.DrawElementsInstanced(gls.TRIANGLES, int32(len(ir.geom.Indices())), gls.UNSIGNED_INT, 0, int32(ir.count))
+	} else {
+		gs.DrawArraysInstanced(gls.TRIANGLES, 0, int32(ir.geom.Items()), int32(ir.count))
+	}
+}
+
+// grow sets the number of instances allocated in the instance buffer for the current instances,
+// at least the initial capacity. Returns true if the buffer must be reallocated, or false
+// if the instance data can be updated in place.
+func (ir *InstancedRenderer) grow() bool {
+
+	if ir.count <= ir.allocated {
+		return false
+	}
+	ir.allocated = ir.count
+	if ir.capacity > ir.allocated {
+		ir.allocated = ir.capacity
+	}
+	return true
+}
+
+// Dispose releases the instance buffer of this renderer. The geometry is not disposed.
+func (ir *InstancedRenderer) Dispose() {
+
+	if ir.gs != nil {
+		ir.gs.DeleteBuffers(ir.vbo)
+		ir.gs = nil
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphic
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+)
+
+// Test that the instance buffer is reallocated only when the instances exceed its allocation,
+// and updated in place otherwise
+func TestInstancedRendererGrow(t *testing.T) {
+
+	ir := NewInstancedRenderer(nil, 10)
+	cases := []struct {
+		count     int
+		realloc   bool
+		allocated int
+	}{
+		{5, true, 10},   // first allocation of the initial capacity
+		{8, false, 10},  // within the capacity
+		{10, false, 10}, // full capacity
+		{25, true, 25},  // grows to the number of instances
+		{20, false, 25}, // shrinking keeps the allocation
+		{25, false, 25},
+		{26, true, 26},
+	}
+	for _, c := range cases {
+		ir.SetInstances(make([]math32.Matrix4, c.count), nil)
+		realloc := ir.grow()
+		if realloc != c.realloc || ir.allocated != c.allocated {
+			t.Error("instances", c.count, "reallocated", realloc, "with", ir.allocated, "instead of", c.realloc, "with", c.allocated)
+		}
+	}
+}
+
+// Test the instance data of the transforms and colors, with white instances without colors
+func TestInstancedRendererSetInstances(t *testing.T) {
+
+	ir := NewInstancedRenderer(nil, 2)
+	transforms := []math32.Matrix4{*math32.NewMatrix4().MakeTranslation(1, 2, 3), *math32.NewMatrix4()}
+	ir.SetInstances(transforms, []math32.Color{{R: 0.5, G: 0.25}})
+	if ir.Count() != 2 || len(ir.buffer) != 2*instanceFloats {
+		t.Fatal("count", ir.Count(), "with", len(ir.buffer), "floats instead of 2 with", 2*instanceFloats)
+	}
+	for i := range transforms {
+		for j := 0; j < 16; j++ {
+			if ir.buffer[i*instanceFloats+j] != transforms[i][j] {
+				t.Error("instance", i, "matrix element", j, "is", ir.buffer[i*instanceFloats+j], "instead of", transforms[i][j])
+			}
+		}
+	}
+	colors := [][3]float32{{0.5, 0.25, 0}, {1, 1, 1}}
+	for i := range colors {
+		for j := 0; j < 3; j++ {
+			if ir.buffer[i*instanceFloats+16+j] != colors[i][j] {
+				t.Error("instance", i, "color component", j, "is", ir.buffer[i*instanceFloats+16+j], "instead of", colors[i][j])
+			}
+		}
+	}
+}
+
+const instancedVertexSource = `#version 330 core
+in vec3 VertexPosition;
+in mat4 InstanceMatrix;
+in vec3 InstanceColor;
+out vec3 Color;
+void main() {
+	Color = InstanceColor;
+	gl_Position = InstanceMatrix * vec4(VertexPosition, 1.0);
+}
+`
+
+const instancedFragmentSource = `#version 330 core
+in vec3 Color;
+out vec4 FragColor;
+void main() {
+	FragColor = vec4(Color, 1.0);
+}
+`
+
+// Benchmark updating and drawing 50000 moving cubes per frame, which requires an OpenGL context
+func BenchmarkInstancedRenderer50k(b *testing.B) {
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	wmgr, err := window.Manager("glfw")
+	if err != nil {
+		b.Skip("no OpenGL context:", err)
+	}
+	win, err := wmgr.CreateWindow(64, 64, "BenchmarkInstancedRenderer50k", false)
+	if err != nil {
+		b.Skip("no OpenGL context:", err)
+	}
+	defer win.Destroy()
+	gs, err := gls.New()
+	if err != nil {
+		b.Skip("no OpenGL context:", err)
+	}
+	shader, err := gs.NewShader(instancedVertexSource, instancedFragmentSource)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	const instances = 50000
+	ir := NewInstancedRenderer(&geometry.NewCube(0.01).Geometry, instances)
+	defer ir.Dispose()
+	transforms := make([]math32.Matrix4, instances)
+	colors := make([]math32.Color, instances)
+	for i := range colors {
+		colors[i] = math32.Color{R: float32(i%256) / 255, G: 0.5, B: 1}
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range transforms {
+			x := float32(j%250)/125 - 1
+			y := float32(j/250)/100 - 1
+			transforms[j].MakeTranslation(x, y+float32(i%100)*0.001, 0)
+		}
+		ir.SetInstances(transforms, colors)
+		ir.Draw(shader)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphic
+
+import (
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/math32"
+)
+
+// LODLevel is a level of detail of a LODGroup.
+type LODLevel struct {
+	Mesh           *Mesh
+	ScreenCoverage float32 // minimum fraction of the screen height covered by the bounding sphere
+	MinDistance    float32 // minimum distance from the camera to the center
+}
+
+// LODGroup selects between meshes of an object with different levels of detail the one which
+// corresponds to the size of the object on the screen: the size of the projection of its
+// bounding sphere. The levels are ordered from the most detailed, with the largest coverage.
+type LODGroup struct {
+	Center           math32.Vector3 // center of the bounding sphere in world coordinates
+	Radius           float32        // radius of the bounding sphere
+	Levels           []LODLevel
+	HysteresisMargin float32 // relative margin of the coverage thresholds required to change level
+	current          int     // index of the selected level, len(Levels) if none, or -1 before the first selection
+}
+
+// NewLODGroup creates and returns a pointer to a new LODGroup without levels
+// with the specified center of its bounding sphere, of radius 1, and a hysteresis margin of 10%.
+func NewLODGroup(center *math32.Vector3) *LODGroup {
+
+	lg := new(LODGroup)
+	lg.Center = *center
+	lg.Radius = 1
+	lg.HysteresisMargin = 0.1
+	lg.current = -1
+	return lg
+}
+
+// AddLevel appends a level less detailed than the previous ones with the specified mesh,
+// minimum screen coverage and minimum distance.
+func (lg *LODGroup) AddLevel(mesh *Mesh, screenCoverage, minDistance float32) {
+
+	lg.Levels = append(lg.Levels, LODLevel{Mesh: mesh, ScreenCoverage: screenCoverage, MinDistance: minDistance})
+}
+
+// Coverage returns the fraction of the height of the screen covered by the
+// projection of the bounding sphere with the specified camera.
+func (lg *LODGroup) Coverage(cam camera.ICamera) float32 {
+
+	var camPos math32.Vector3
+	cam.GetCamera().WorldPosition(&camPos)
+	var proj math32.Matrix4
+	cam.ProjMatrix(&proj)
+	// The perspective projections divide by the distance
+	w := float32(1)
+	if proj[15] == 0 {
+		w = camPos.DistanceTo(&lg.Center)
+		if w <= lg.Radius {
+			return math32.Infinity
+		}
+	}
+	return lg.Radius * proj[5] / w
+}
+
+// Select returns the mesh of the level for the specified camera: the first level whose coverage
+// threshold and minimum distance are reached. Returns nil if no level is reached, when the object
+// is too small to be rendered. When the coverage is close to the threshold of the selected level,
+// the level only changes when the difference exceeds the hysteresis margin, to avoid flickering.
+func (lg *LODGroup) Select(cam camera.ICamera) *Mesh {
+
+	coverage := lg.Coverage(cam)
+	var camPos math32.Vector3
+	cam.GetCamera().WorldPosition(&camPos)
+	dist := camPos.DistanceTo(&lg.Center)
+
+	level := len(lg.Levels)
+	for i := range lg.Levels {
+		if coverage >= lg.Levels[i].ScreenCoverage && dist >= lg.Levels[i].MinDistance {
+			level = i
+			break
+		}
+	}
+	if lg.current >= 0 && lg.current <= len(lg.Levels) && level != lg.current {
+		margin := 1 + lg.HysteresisMargin
+		if level > lg.current && lg.current < len(lg.Levels) {
+			// Less detailed: the coverage must be clearly below the threshold of the current level
+			cur := &lg.Levels[lg.current]
+			if coverage*margin >= cur.ScreenCoverage && dist >= cur.MinDistance {
+				level = lg.current
+			}
+		} else if level < lg.current && coverage < lg.Levels[level].ScreenCoverage*margin {
+			// More detailed: the coverage must be clearly above the threshold of the new level
+			level = lg.current
+		}
+	}
+	lg.current = level
+	if level == len(lg.Levels) {
+		return nil
+	}
+	return lg.Levels[level].Mesh
+}
+
+// Level returns the index of the level selected by the last call to Select, -1 before the first call
+// and len(Levels) if no level was selected.
+func (lg *LODGroup) Level() int {
+
+	return lg.current
+}
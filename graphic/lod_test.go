@@ -0,0 +1,91 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphic
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/math32"
+)
+
+// newTestLODGroup returns a group of radius 2 at (1,0,-3) with levels of coverage 0.5 and 0.1
+// and a level of coverage 0.02 used from the distance 50.
+func newTestLODGroup() (*LODGroup, []*Mesh) {
+
+	lg := NewLODGroup(&math32.Vector3{X: 1, Z: -3})
+	lg.Radius = 2
+	meshes := []*Mesh{new(Mesh), new(Mesh), new(Mesh)}
+	lg.AddLevel(meshes[0], 0.5, 0)
+	lg.AddLevel(meshes[1], 0.1, 0)
+	lg.AddLevel(meshes[2], 0.02, 50)
+	return lg, meshes
+}
+
+// Test the levels selected at known distances with a field of view of 60 degrees,
+// where the coverage of the radius 2 is 2*sqrt(3)/distance
+func TestLODGroupSelect(t *testing.T) {
+
+	cam := camera.NewPerspective(60, 1, 0.1, 1000)
+	cases := []struct {
+		distance float32
+		level    int
+	}{
+		{1.5, 0}, // inside the bounding sphere
+		{6.5, 0}, // coverage 0.533
+		{7.5, 1}, // coverage 0.462
+		{34, 1},  // coverage 0.102
+		{40, 3},  // coverage 0.087, before the minimum distance of the last level
+		{60, 2},  // coverage 0.058
+		{200, 3}, // coverage 0.017
+	}
+	for _, c := range cases {
+		lg, meshes := newTestLODGroup()
+		cam.SetPosition(1, 0, -3+c.distance)
+		cam.UpdateMatrixWorld()
+		if c.distance > 2 {
+			if coverage := lg.Coverage(cam); math32.Abs(coverage-2*math32.Sqrt(3)/c.distance) > 1e-5 {
+				t.Error("coverage", coverage, "at the distance", c.distance, "instead of", 2*math32.Sqrt(3)/c.distance)
+			}
+		}
+		var expected *Mesh
+		if c.level < len(meshes) {
+			expected = meshes[c.level]
+		}
+		if mesh := lg.Select(cam); mesh != expected || lg.Level() != c.level {
+			t.Error("level", lg.Level(), "selected at the distance", c.distance, "instead of", c.level)
+		}
+	}
+}
+
+// Test that the level only changes when the coverage crosses the threshold by the hysteresis margin
+func TestLODGroupHysteresis(t *testing.T) {
+
+	cam := camera.NewPerspective(90, 1, 0.1, 1000)
+	lg, _ := newTestLODGroup()
+	lg.Radius = 1
+	if lg.Level() != -1 {
+		t.Fatal("level", lg.Level(), "before the first selection")
+	}
+	// With a field of view of 90 degrees the coverage is 1/distance
+	steps := []struct {
+		distance float32
+		level    int
+	}{
+		{1.5, 0},
+		{2.1, 0},  // 0.476 within 10% below the threshold 0.5
+		{2.5, 1},  // 0.4
+		{1.95, 1}, // 0.513 within 10% above the threshold 0.5
+		{1.7, 0},  // 0.588
+	}
+	for _, s := range steps {
+		cam.SetPosition(1, 0, -3+s.distance)
+		cam.UpdateMatrixWorld()
+		lg.Select(cam)
+		if lg.Level() != s.level {
+			t.Error("level", lg.Level(), "at the distance", s.distance, "instead of", s.level)
+		}
+	}
+}
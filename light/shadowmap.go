@@ -0,0 +1,213 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package light
+
+import (
+	"fmt"
+
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// shadowBias converts the normalized device coordinates of the light projections
+// from [-1,1] to the texture coordinates and depths in [0,1] of the shadow maps.
+var shadowBias = math32.Matrix4{
+	0.5, 0, 0, 0,
+	0, 0.5, 0, 0,
+	0, 0, 0.5, 0,
+	0.5, 0.5, 0.5, 1,
+}
+
+// ShadowMap contains the depth maps of a directional light for cascaded shadow mapping:
+// the view frustum of the camera is split along its depth into cascades covering larger
+// distances further from the camera, each with its own depth map of the same resolution.
+// The scene is rendered from the light into each cascade, and the shaders receiving the shadows
+// select the cascade from the view depth of the fragments.
+type ShadowMap struct {
+	Lambda         float32          // blend of the logarithmic (1) and the uniform (0) splits of the cascades
+	CasterDistance float32          // distance before the cascades along the light direction including the shadow casters
+	ViewProj       []math32.Matrix4 // view projection matrix of the light of each cascade
+	ShadowMatrix   []math32.Matrix4 // matrix from world coordinates to shadow map texture coordinates of each cascade
+	Splits         []float32        // view depth of the far end of each cascade
+	gs             *gls.GLS
+	resolution     int
+	targets        []*texture.RenderTarget // depth render target of each cascade
+	uniMatrix      gls.Uniform             // shadow matrices uniform location cache
+	uniSplits      gls.Uniform             // splits uniform location cache
+}
+
+// ShadowSampler is the name of the shader sampler array of the depth maps of the cascades.
+const ShadowSampler = "uShadowMap"
+
+// NewShadowMap creates and returns a pointer to a new ShadowMap with the specified number of cascades
+// and the specified width and height in texels of their depth maps.
+// Returns an error if the number of cascades is not positive or a render target cannot be created.
+func NewShadowMap(gs *gls.GLS, numCascades int, resolution int) (*ShadowMap, error) {
+
+	if numCascades < 1 {
+		return nil, fmt.Errorf("invalid number of cascades:%d", numCascades)
+	}
+	sm := new(ShadowMap)
+	sm.gs = gs
+	sm.resolution = resolution
+	sm.Lambda = 0.75
+	sm.CasterDistance = 100
+	sm.ViewProj = make([]math32.Matrix4, numCascades)
+	sm.ShadowMatrix = make([]math32.Matrix4, numCascades)
+	sm.Splits = make([]float32, numCascades)
+	for i := 0; i < numCascades; i++ {
+		rt, err := texture.NewRenderTarget(gs, resolution, resolution, texture.RenderTargetOpts{
+			ColorFormat: gls.RGB8,
+			DepthFormat: gls.DEPTH_COMPONENT24,
+		})
+		if err != nil {
+			sm.Dispose()
+			return nil, err
+		}
+		rt.DepthTexture().SetUniformNames(ShadowSampler, "uShadowMapTexParams")
+		sm.targets = append(sm.targets, rt)
+	}
+	sm.uniMatrix.Init("ShadowMatrix")
+	sm.uniSplits.Init("ShadowSplits")
+	return sm, nil
+}
+
+// Cascades returns the number of cascades.
+func (sm *ShadowMap) Cascades() int {
+
+	return len(sm.ViewProj)
+}
+
+// UpdateCascades splits the view frustum of the specified camera and computes the view projection
+// of the light with the specified direction which covers each cascade. Each cascade is covered by
+// the bounding sphere of its part of the frustum, with its center snapped to the texels of the
+// depth map, so that the shadows do not change when the camera rotates or moves less than a texel.
+func (sm *ShadowMap) UpdateCascades(cam *camera.Perspective, lightDir *math32.Vector3) {
+
+	var view, camWorld math32.Matrix4
+	cam.ViewMatrix(&view)
+	camWorld.GetInverse(&view)
+	near, far := cam.Near(), cam.Far()
+	tanY := math32.Tan(math32.DegToRad(cam.Fov() / 2))
+	tanX := tanY * cam.Aspect()
+
+	// Rotation of the light looking along its direction, and its inverse
+	var rot, rotInv math32.Matrix4
+	dir := *lightDir
+	dir.Normalize()
+	up := math32.Vector3{X: 0, Y: 1, Z: 0}
+	if math32.Abs(dir.Y) > 0.99 {
+		up = math32.Vector3{X: 0, Y: 0, Z: 1}
+	}
+	var zero math32.Vector3
+	rot.Identity().LookAt(&zero, &dir, &up)
+	rotInv.Copy(&rot).Transpose()
+
+	n := len(sm.ViewProj)
+	prev := near
+	for i := 0; i < n; i++ {
+		// Practical split scheme: blend of the logarithmic and uniform splits
+		t := float32(i+1) / float32(n)
+		logSplit := near * math32.Pow(far/near, t)
+		uniSplit := near + (far-near)*t
+		split := sm.Lambda*logSplit + (1-sm.Lambda)*uniSplit
+		sm.Splits[i] = split
+
+		// Bounding sphere of the corners of the part of the frustum, in world coordinates
+		var corners [8]math32.Vector3
+		for c := range corners {
+			d := prev
+			if c >= 4 {
+				d = split
+			}
+			sx, sy := float32(1), float32(1)
+			if c&1 != 0 {
+				sx = -1
+			}
+			if c&2 != 0 {
+				sy = -1
+			}
+			corners[c] = math32.Vector3{X: sx * tanX * d, Y: sy * tanY * d, Z: -d}
+			corners[c].ApplyMatrix4(&camWorld)
+		}
+		var center math32.Vector3
+		for c := range corners {
+			center.Add(&corners[c])
+		}
+		center.MultiplyScalar(1.0 / 8)
+		var radius float32
+		for c := range corners {
+			radius = math32.Max(radius, center.DistanceTo(&corners[c]))
+		}
+		// Rounded up to limit the changes of scale of the texels
+		radius = math32.Ceil(radius*16) / 16
+
+		// Center in light coordinates snapped to the texels
+		texel := 2 * radius / float32(sm.resolution)
+		center.ApplyMatrix4(&rotInv)
+		center.X = math32.Floor(center.X/texel) * texel
+		center.Y = math32.Floor(center.Y/texel) * texel
+
+		var lightView, proj math32.Matrix4
+		lightView.Copy(&rotInv)
+		lightView[12] = -center.X
+		lightView[13] = -center.Y
+		lightView[14] = -center.Z
+		proj.MakeOrthographic(-radius, radius, radius, -radius, -radius-sm.CasterDistance, radius)
+		sm.ViewProj[i].MultiplyMatrices(&proj, &lightView)
+		sm.ShadowMatrix[i].MultiplyMatrices(&shadowBias, &sm.ViewProj[i])
+		prev = split
+	}
+}
+
+// Bind sets the render target of the specified cascade as the current framebuffer
+// and clears its depth, to render the shadow casters with its view projection.
+func (sm *ShadowMap) Bind(cascade int) {
+
+	sm.targets[cascade].Bind()
+	sm.gs.Clear(gls.DEPTH_BUFFER_BIT)
+}
+
+// Unbind restores the default framebuffer after rendering the specified cascade.
+func (sm *ShadowMap) Unbind(cascade int) {
+
+	sm.targets[cascade].Unbind()
+}
+
+// DepthTexture returns the depth map of the specified cascade.
+func (sm *ShadowMap) DepthTexture(cascade int) *texture.Texture2D {
+
+	return sm.targets[cascade].DepthTexture()
+}
+
+// Textures returns the depth maps of the cascades, elements of the shader sampler array
+// ShadowSampler, to add to the materials receiving the shadows.
+func (sm *ShadowMap) Textures() []*texture.Texture2D {
+
+	textures := make([]*texture.Texture2D, len(sm.targets))
+	for i, rt := range sm.targets {
+		textures[i] = rt.DepthTexture()
+	}
+	return textures
+}
+
+// RenderSetup transfers the shadow matrices and the splits of the cascades
+// to the uniforms ShadowMatrix and ShadowSplits of the current shader program.
+func (sm *ShadowMap) RenderSetup(gs *gls.GLS) {
+
+	gs.UniformMatrix4fv(sm.uniMatrix.Location(gs), int32(len(sm.ShadowMatrix)), false, &sm.ShadowMatrix[0][0])
+	gs.Uniform1fv(sm.uniSplits.Location(gs), int32(len(sm.Splits)), sm.Splits)
+}
+
+// Dispose releases the render targets of the cascades.
+func (sm *ShadowMap) Dispose() {
+
+	for _, rt := range sm.targets {
+		rt.Dispose()
+	}
+	sm.targets = nil
+}
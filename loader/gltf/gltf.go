@@ -22,6 +22,7 @@ const (
 	KhrMaterialsUnlit                 = "KHR_materials_unlit"
 	KhrMaterialsCommon                = "KHR_materials_common" // TODO this is officially part of glTF 1.0 (remove?)
 	KhrMaterialsPbrSpecularGlossiness = "KHR_materials_pbrSpecularGlossiness"
+	KhrMeshQuantization               = "KHR_mesh_quantization"
 )
 
 // GLTF is the root object for a glTF asset.
@@ -46,8 +47,9 @@ type GLTF struct {
 	Extensions         map[string]interface{} // Dictionary object with extension-specific objects. Not required.
 	Extras             interface{}            // Application-specific data. Not required.
 
-	path string // File path for resources.
-	data []byte // Binary file Chunk 1 data.
+	path      string // File path for resources.
+	data      []byte // Binary file Chunk 1 data.
+	quantized bool   // KHR_mesh_quantization is used, allowing integer vertex attributes.
 }
 
 // Accessor is a typed view into a BufferView.
@@ -423,6 +425,16 @@ var TypeSizes = map[string]int{
 	MAT4:   16,
 }
 
+// ComponentSizes maps a component type to the size in bytes of a component.
+var ComponentSizes = map[int]int{
+	BYTE:           1,
+	UNSIGNED_BYTE:  1,
+	SHORT:          2,
+	UNSIGNED_SHORT: 2,
+	UNSIGNED_INT:   4,
+	FLOAT:          4,
+}
+
 // AttributeName maps the glTF attribute name to the internal g3n attribute type.
 var AttributeName = map[string]gls.AttribType{
 	"POSITION":   gls.VertexPosition,
@@ -30,6 +30,52 @@ import (
 	"github.com/g3n/engine/animation"
 )
 
+// LoadedScene is a glTF scene loaded by LoadGLTF.
+type LoadedScene struct {
+	Root       core.INode             // node with the nodes of the scene, including their meshes, skins and cameras
+	Animations []*animation.Animation // all the animations of the asset
+	GLTF       *GLTF                  // parsed asset, to load other objects
+}
+
+// LoadGLTF parses the glTF 2.0 file with the specified path, in binary format if its extension is .glb
+// and JSON format otherwise, and loads its default scene, or its first scene if it has no default,
+// and all its animations.
+func LoadGLTF(path string) (*LoadedScene, error) {
+
+	var g *GLTF
+	var err error
+	if strings.ToLower(filepath.Ext(path)) == ".glb" {
+		g, err = ParseBin(path)
+	} else {
+		g, err = ParseJSON(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sceneIdx := 0
+	if g.Scene != nil {
+		sceneIdx = *g.Scene
+	}
+	ls := &LoadedScene{GLTF: g}
+	if len(g.Scenes) > 0 {
+		ls.Root, err = g.LoadScene(sceneIdx)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		ls.Root = core.NewNode()
+	}
+	for i := range g.Animations {
+		anim, err := g.LoadAnimation(i)
+		if err != nil {
+			return nil, err
+		}
+		ls.Animations = append(ls.Animations, anim)
+	}
+	return ls, nil
+}
+
 // ParseJSON parses the glTF data from the specified JSON file
 // and returns a pointer to the parsed structure.
 func ParseJSON(filename string) (*GLTF, error) {
@@ -58,11 +104,40 @@ func ParseJSONReader(r io.Reader, path string) (*GLTF, error) {
 		return nil, err
 	}
 
-	// TODO Check for extensions used and extensions required
-
+	g.checkExtensions()
 	return g, nil
 }
 
+// supportedExtensions are the glTF extensions handled by the loader.
+var supportedExtensions = map[string]bool{
+	KhrMaterialsCommon:  true,
+	KhrMaterialsUnlit:   true,
+	KhrMeshQuantization: true,
+}
+
+// checkExtensions warns about the extensions used which are not supported,
+// which are ignored, and enables the supported ones.
+func (g *GLTF) checkExtensions() {
+
+	required := make(map[string]bool)
+	for _, ext := range g.ExtensionsRequired {
+		required[ext] = true
+	}
+	for _, ext := range g.ExtensionsUsed {
+		if ext == KhrMeshQuantization {
+			g.quantized = true
+		}
+		if supportedExtensions[ext] {
+			continue
+		}
+		if required[ext] {
+			log.Warn("required extension %s is not supported: the asset may not load correctly", ext)
+		} else {
+			log.Warn("extension %s is not supported and will be ignored", ext)
+		}
+	}
+}
+
 // ParseBin parses the glTF data from the specified binary file
 // and returns a pointer to the parsed structure.
 func ParseBin(filename string) (*GLTF, error) {
@@ -550,24 +625,29 @@ func (g *GLTF) loadAttributes(geom *geometry.Geometry, attributes map[string]int
 		// Load data and add it to geometry's VBO
 		if g.isInterleaved(accessor) {
 			bvIdx := *accessor.BufferView
+			byteOffset := 0
+			if accessor.ByteOffset != nil {
+				byteOffset = *accessor.ByteOffset
+			}
 			// Check if we already loaded this buffer view
 			vbo, ok := interleavedVBOs[bvIdx]
 			if ok {
 				// Already created VBO for this buffer view
 				// Add attribute with correct byteOffset
-				g.addAttributeToVBO(vbo, name, uint32(*accessor.ByteOffset))
+				g.addAttributeToVBO(vbo, name, uint32(byteOffset))
 			} else {
 				// Load data and create vbo
 				buf, err := g.loadBufferView(bvIdx)
 				if err != nil {
 					return err
 				}
-				data, err := g.bytesToArrayF32(buf, accessor.ComponentType, accessor.Count*TypeSizes[accessor.Type])
+				// The VBO holds all the floats of the interleaved attributes
+				data, err := g.bytesToArrayF32(buf, accessor.ComponentType, len(buf)/int(gls.FloatSize), accessor.Normalized)
 				if err != nil {
 					return err
 				}
 				vbo := gls.NewVBO(data)
-				g.addAttributeToVBO(vbo, name, 0)
+				g.addAttributeToVBO(vbo, name, uint32(byteOffset))
 				// Save reference to VBO keyed by index of the buffer view
 				interleavedVBOs[bvIdx] = vbo
 				// Add VBO to geometry
@@ -578,7 +658,7 @@ func (g *GLTF) loadAttributes(geom *geometry.Geometry, attributes map[string]int
 			if err != nil {
 				return err
 			}
-			data, err := g.bytesToArrayF32(buf, accessor.ComponentType, accessor.Count*TypeSizes[accessor.Type])
+			data, err := g.bytesToArrayF32(buf, accessor.ComponentType, accessor.Count*TypeSizes[accessor.Type], accessor.Normalized)
 			if err != nil {
 				return err
 			}
@@ -622,6 +702,20 @@ func (g *GLTF) validateAccessorAttribute(ac Accessor, attribName string) error {
 
 	usage := "attribute " + attribName
 
+	// KHR_mesh_quantization allows integer positions, normals, tangents and texture coordinates
+	if g.quantized {
+		switch semantic {
+		case "POSITION", "NORMAL", "TANGENT", "TEXCOORD":
+			validTypes := []string{VEC3}
+			if semantic == "TANGENT" {
+				validTypes = []string{VEC3, VEC4}
+			} else if semantic == "TEXCOORD" {
+				validTypes = []string{VEC2}
+			}
+			return g.validateAccessor(ac, usage, validTypes, []int{FLOAT, BYTE, UNSIGNED_BYTE, SHORT, UNSIGNED_SHORT})
+		}
+	}
+
 	if attribName == "POSITION" {
 		return g.validateAccessor(ac, usage, []string{VEC3}, []int{FLOAT})
 	} else if attribName == "NORMAL" {
@@ -702,8 +796,7 @@ func (g *GLTF) LoadMaterial(matIdx int) (material.IMaterial, error) {
 			if ext == KhrMaterialsCommon {
 				imat, err = g.loadMaterialCommon(extData)
 			} else if ext == KhrMaterialsUnlit {
-				//imat, err = g.loadMaterialUnlit(matData, extData)
-			//} else if ext == KhrMaterialsPbrSpecularGlossiness {
+				imat, err = g.loadMaterialUnlit(&matData)
 			} else {
 				return nil, fmt.Errorf("unsupported extension:%s", ext)
 			}
@@ -876,7 +969,8 @@ func (g *GLTF) bytesToArrayU32(data []byte, componentType, count int) (math32.Ar
 }
 
 // bytesToArrayF32 converts a byte array to ArrayF32.
-func (g *GLTF) bytesToArrayF32(data []byte, componentType, count int) (math32.ArrayF32, error) {
+// Normalized integer components are mapped to [0,1] if unsigned or [-1,1] if signed.
+func (g *GLTF) bytesToArrayF32(data []byte, componentType, count int, normalized bool) (math32.ArrayF32, error) {
 
 	// If component is UNSIGNED_INT nothing to do
 	if componentType == UNSIGNED_INT {
@@ -889,6 +983,9 @@ func (g *GLTF) bytesToArrayF32(data []byte, componentType, count int) (math32.Ar
 		out := math32.NewArrayF32(count, count)
 		for i := 0; i < count; i++ {
 			out[i] = float32(data[i*2]) + float32(data[i*2+1])*256
+			if normalized {
+				out[i] /= 65535
+			}
 		}
 		return out, nil
 	}
@@ -898,6 +995,33 @@ func (g *GLTF) bytesToArrayF32(data []byte, componentType, count int) (math32.Ar
 		out := math32.NewArrayF32(count, count)
 		for i := 0; i < count; i++ {
 			out[i] = float32(data[i])
+			if normalized {
+				out[i] /= 255
+			}
+		}
+		return out, nil
+	}
+
+	// Converts SHORT, used by KHR_mesh_quantization
+	if componentType == SHORT {
+		out := math32.NewArrayF32(count, count)
+		for i := 0; i < count; i++ {
+			out[i] = float32(int16(uint16(data[i*2]) | uint16(data[i*2+1])<<8))
+			if normalized {
+				out[i] = math32.Max(out[i]/32767, -1)
+			}
+		}
+		return out, nil
+	}
+
+	// Converts BYTE, used by KHR_mesh_quantization
+	if componentType == BYTE {
+		out := math32.NewArrayF32(count, count)
+		for i := 0; i < count; i++ {
+			out[i] = float32(int8(data[i]))
+			if normalized {
+				out[i] = math32.Max(out[i]/127, -1)
+			}
 		}
 		return out, nil
 	}
@@ -950,7 +1074,7 @@ func (g *GLTF) loadAccessorF32(ai int, usage string, validTypes []string, validC
 		return nil, err
 	}
 
-	return g.bytesToArrayF32(data, ac.ComponentType, ac.Count*TypeSizes[ac.Type])
+	return g.bytesToArrayF32(data, ac.ComponentType, ac.Count*TypeSizes[ac.Type], ac.Normalized)
 }
 
 // loadAccessorBytes returns the base byte array used by an accessor.
@@ -975,29 +1099,43 @@ func (g *GLTF) loadAccessorBytes(ac Accessor) ([]byte, error) {
 	}
 	data = data[offset:]
 
-	// TODO check if interleaved and de-interleave if necessary?
-
 	// Calculate the size in bytes of a complete attribute
-	itemSize := TypeSizes[ac.Type]
-	itemBytes := int(gls.FloatSize) * itemSize
+	itemBytes := ComponentSizes[ac.ComponentType] * TypeSizes[ac.Type]
+	if ac.Count == 0 {
+		return data, nil
+	}
 
 	// If the BufferView stride is equal to the item size, the buffer is not interleaved
-	if (bv.ByteStride != nil) && (*bv.ByteStride != itemBytes) {
-		// BufferView data is interleaved, de-interleave
-		// TODO
-		return nil, fmt.Errorf("data is interleaved - not supported for animation yet")
+	stride := itemBytes
+	if bv.ByteStride != nil {
+		stride = *bv.ByteStride
+	}
+	if len(data) < (ac.Count-1)*stride+itemBytes {
+		return nil, fmt.Errorf("accessor data out of BufferView bounds")
+	}
+	if stride == itemBytes {
+		return data, nil
+	}
+
+	// BufferView data is interleaved or its elements are padded, de-interleave
+	packed := make([]byte, ac.Count*itemBytes)
+	for i := 0; i < ac.Count; i++ {
+		copy(packed[i*itemBytes:(i+1)*itemBytes], data[i*stride:])
 	}
 
 	// TODO Sparse accessor
 
-	return data, nil
+	return packed, nil
 }
 
-// isInterleaves returns whether the BufferView used by the provided accessor is interleaved.
+// isInterleaved returns whether the BufferView used by the provided accessor is interleaved
+// and can be used directly as the VBO with the other attributes stored in it.
+// Integer attributes, as allowed by KHR_mesh_quantization, are converted to
+// float and so are de-interleaved instead.
 func (g *GLTF) isInterleaved(accessor Accessor) bool {
 
 	// Get the Accessor's BufferView
-	if accessor.BufferView == nil {
+	if accessor.BufferView == nil || accessor.ComponentType != FLOAT {
 		return false
 	}
 	bv := g.BufferViews[*accessor.BufferView]
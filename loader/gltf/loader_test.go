@@ -0,0 +1,54 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gltf
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/math32"
+)
+
+// Test loading KHR_mesh_quantization attributes with strides padded to 4 bytes
+func TestLoadQuantizedMesh(t *testing.T) {
+
+	g, err := ParseJSON("testdata/quantized.gltf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	node, err := g.LoadMesh(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	children := node.GetNode().Children()
+	if len(children) != 1 {
+		t.Fatal("LoadMesh returned", len(children), "primitives")
+	}
+	geom := children[0].(*graphic.Mesh).GetGeometry()
+
+	var positions []math32.Vector3
+	geom.VBO(gls.VertexPosition).ReadVectors3(gls.VertexPosition, func(v math32.Vector3) bool {
+		positions = append(positions, v)
+		return false
+	})
+	expected := []math32.Vector3{{X: 0, Y: 0, Z: 0}, {X: 100, Y: 0, Z: 0}, {X: 0, Y: 200, Z: 0}}
+	if len(positions) != len(expected) {
+		t.Fatal("loaded", len(positions), "positions")
+	}
+	for i := range expected {
+		if positions[i] != expected[i] {
+			t.Error("position", i, "loaded as", positions[i])
+		}
+	}
+
+	// Normalized BYTE normals
+	geom.VBO(gls.VertexNormal).ReadVectors3(gls.VertexNormal, func(v math32.Vector3) bool {
+		if v != (math32.Vector3{X: 0, Y: 0, Z: 1}) {
+			t.Error("normal loaded as", v)
+		}
+		return false
+	})
+}
@@ -0,0 +1,40 @@
+package gltf
+
+import (
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// loadMaterialUnlit approximates a KHR_materials_unlit material with a physical material
+// which emits its base color and reflects no light.
+// The specification of this extension is at:
+// https://github.com/KhronosGroup/glTF/tree/master/extensions/2.0/Khronos/KHR_materials_unlit
+func (g *GLTF) loadMaterialUnlit(m *Material) (material.IMaterial, error) {
+
+	pm := material.NewPhysical()
+	if m.DoubleSided {
+		pm.SetSide(material.SideDouble)
+	} else {
+		pm.SetSide(material.SideFront)
+	}
+	pm.SetTransparent(m.AlphaMode == "BLEND")
+
+	color := math32.Color4{R: 1, G: 1, B: 1, A: 1}
+	pbr := m.PbrMetallicRoughness
+	if pbr != nil && pbr.BaseColorFactor != nil {
+		color = math32.Color4{R: pbr.BaseColorFactor[0], G: pbr.BaseColorFactor[1], B: pbr.BaseColorFactor[2], A: pbr.BaseColorFactor[3]}
+	}
+	pm.SetEmissiveFactor(&math32.Color{R: color.R, G: color.G, B: color.B})
+	pm.SetBaseColorFactor(&math32.Color4{A: color.A})
+	pm.SetMetallicFactor(0)
+	pm.SetRoughnessFactor(1)
+
+	if pbr != nil && pbr.BaseColorTexture != nil {
+		tex, err := g.LoadTexture(pbr.BaseColorTexture.Index)
+		if err != nil {
+			return nil, err
+		}
+		pm.SetEmissiveMap(tex)
+	}
+	return pm, nil
+}
@@ -71,6 +71,7 @@ type Material struct {
 	Specular   math32.Color // Specular color reflectivity
 	Emissive   math32.Color // Emissive color
 	MapKd      string       // Texture file linked to diffuse color
+	MapBump    string       // Bump (normal) map texture file
 }
 
 // Light gray default material used as when other materials cannot be loaded.
@@ -257,13 +258,7 @@ func (dec *Decoder) NewMesh(obj *Object) (*graphic.Mesh, error) {
 		}
 
 		// Creates material for mesh
-		mat := material.NewPhong(&matDesc.Diffuse)
-		ambientColor := mat.AmbientColor()
-		mat.SetAmbientColor(ambientColor.Multiply(&matDesc.Ambient))
-		mat.SetSpecularColor(&matDesc.Specular)
-		mat.SetShininess(matDesc.Shininess)
-		// Loads material textures if specified
-		err = dec.loadTex(&mat.Material, matDesc)
+		mat, err := dec.newMaterial(matDesc)
 		if err != nil {
 			return nil, err
 		}
@@ -292,13 +287,7 @@ func (dec *Decoder) NewMesh(obj *Object) (*graphic.Mesh, error) {
 		}
 
 		// Creates material for mesh
-		matGroup := material.NewPhong(&matDesc.Diffuse)
-		ambientColor := matGroup.AmbientColor()
-		matGroup.SetAmbientColor(ambientColor.Multiply(&matDesc.Ambient))
-		matGroup.SetSpecularColor(&matDesc.Specular)
-		matGroup.SetShininess(matDesc.Shininess)
-		// Loads material textures if specified
-		err = dec.loadTex(&matGroup.Material, matDesc)
+		matGroup, err := dec.newMaterial(matDesc)
 		if err != nil {
 			return nil, err
 		}
@@ -368,6 +357,97 @@ func (dec *Decoder) NewGeometry(obj *Object) (*geometry.Geometry, error) {
 	return geom, nil
 }
 
+// LoadOBJ decodes the specified obj file and the material library it references and returns
+// one mesh for each material used by each decoded object, with the material of each mesh
+// at the same index. Materials used by several meshes are shared.
+func LoadOBJ(objPath string) ([]*graphic.Mesh, []material.IMaterial, error) {
+
+	dec, err := Decode(objPath, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var meshes []*graphic.Mesh
+	var mats []material.IMaterial
+	created := make(map[string]material.IMaterial)
+	for i := range dec.Objects {
+		obj := &dec.Objects[i]
+		// Groups the faces of the object by material, in the order of first use
+		var names []string
+		faces := make(map[string][]Face)
+		for _, face := range obj.Faces {
+			if _, ok := faces[face.Material]; !ok {
+				names = append(names, face.Material)
+			}
+			faces[face.Material] = append(faces[face.Material], face)
+		}
+		for _, name := range names {
+			geom, err := dec.NewGeometry(&Object{Name: obj.Name, Faces: faces[name]})
+			if err != nil {
+				return nil, nil, err
+			}
+			mat := created[name]
+			if mat == nil {
+				matDesc := dec.Materials[name]
+				if matDesc == nil {
+					matDesc = defaultMat
+					msg := fmt.Sprintf("could not find material %s for %s. using default material.", name, obj.Name)
+					dec.appendWarn(objType, msg)
+				}
+				mat, err = dec.newMaterial(matDesc)
+				if err != nil {
+					return nil, nil, err
+				}
+				created[name] = mat
+			}
+			mesh := graphic.NewMesh(geom, mat)
+			mesh.SetName(obj.Name)
+			meshes = append(meshes, mesh)
+			mats = append(mats, mat)
+		}
+	}
+	return meshes, mats, nil
+}
+
+// newMaterial creates and returns a material from the specified material descriptor.
+// The Phong material has no normal map, so a physical material
+// is created for the descriptors with a bump map.
+func (dec *Decoder) newMaterial(desc *Material) (material.IMaterial, error) {
+
+	if desc.MapBump == "" {
+		mat := material.NewPhong(&desc.Diffuse)
+		ambientColor := mat.AmbientColor()
+		mat.SetAmbientColor(ambientColor.Multiply(&desc.Ambient))
+		mat.SetSpecularColor(&desc.Specular)
+		mat.SetShininess(desc.Shininess)
+		// Loads material textures if specified
+		err := dec.loadTex(&mat.Material, desc)
+		if err != nil {
+			return nil, err
+		}
+		return mat, nil
+	}
+
+	mat := material.NewPhysical()
+	mat.SetBaseColorFactor(&math32.Color4{R: desc.Diffuse.R, G: desc.Diffuse.G, B: desc.Diffuse.B, A: 1})
+	mat.SetMetallicFactor(0)
+	// Converts the specular exponent (0 to 1000) to roughness
+	mat.SetRoughnessFactor(math32.Clamp(1-math32.Sqrt(desc.Shininess/1000), 0, 1))
+	if desc.MapKd != "" {
+		tex, err := dec.loadTexFile(desc.MapKd)
+		if err != nil {
+			return nil, err
+		}
+		mat.SetBaseColorMap(tex)
+	}
+	tex, err := dec.loadTexFile(desc.MapBump)
+	if err != nil {
+		return nil, err
+	}
+	mat.SetNormalMap(tex)
+	return mat, nil
+}
+
 // loadTex loads textures described in the material descriptor into the
 // specified material
 func (dec *Decoder) loadTex(mat *material.Material, desc *Material) error {
@@ -377,23 +457,29 @@ func (dec *Decoder) loadTex(mat *material.Material, desc *Material) error {
 		return nil
 	}
 
+	tex, err := dec.loadTexFile(desc.MapKd)
+	if err != nil {
+		return err
+	}
+	mat.AddTexture(tex)
+	return nil
+}
+
+// loadTexFile loads the texture in the specified image file
+func (dec *Decoder) loadTexFile(name string) (*texture.Texture2D, error) {
+
 	// Get texture file path
 	// If texture file path is not absolute assumes it is relative
 	// to the directory of the material file
 	var texPath string
-	if filepath.IsAbs(desc.MapKd) {
-		texPath = desc.MapKd
+	if filepath.IsAbs(name) {
+		texPath = name
 	} else {
-		texPath = filepath.Join(dec.mtlDir, desc.MapKd)
+		texPath = filepath.Join(dec.mtlDir, name)
 	}
 
 	// Try to load texture from image file
-	tex, err := texture.NewTexture2DFromImage(texPath)
-	if err != nil {
-		return err
-	}
-	mat.AddTexture(tex)
-	return nil
+	return texture.NewTexture2DFromImage(texPath)
 }
 
 // parse reads the lines from the specified reader and dispatch them
@@ -749,6 +835,8 @@ func (dec *Decoder) parseMtlLine(line string) error {
 		return dec.parseIllum(fields[1:])
 	case "map_Kd":
 		return dec.parseMapKd(fields[1:])
+	case "map_bump", "map_Bump", "bump":
+		return dec.parseMapBump(fields[1:])
 	default:
 		dec.appendWarn(mtlType, "field not supported: "+ltype)
 	}
@@ -918,7 +1006,19 @@ func (dec *Decoder) parseMapKd(fields []string) error {
 	if len(fields) < 1 {
 		return dec.formatError("No fields")
 	}
-	dec.matCurrent.MapKd = fields[0]
+	dec.matCurrent.MapKd = fields[len(fields)-1]
+	return nil
+}
+
+// Parses bump texture of the material
+// map_bump [-options] <filename>
+func (dec *Decoder) parseMapBump(fields []string) error {
+
+	if len(fields) < 1 {
+		return dec.formatError("No fields")
+	}
+	// The options, like the bump multiplier -bm, are not supported
+	dec.matCurrent.MapBump = fields[len(fields)-1]
 	return nil
 }
 
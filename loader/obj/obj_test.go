@@ -0,0 +1,121 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package obj
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// objVertex is a vertex of a loaded mesh.
+type objVertex struct {
+	pos    math32.Vector3
+	normal math32.Vector3
+	uv     math32.Vector2
+}
+
+// meshVertices returns the vertices of the triangles of the specified mesh, in index order.
+func meshVertices(t *testing.T, mesh *graphic.Mesh) []objVertex {
+
+	t.Helper()
+	geom := mesh.GetGeometry()
+	positions := geom.VBO(gls.VertexPosition).Buffer()
+	normals := geom.VBO(gls.VertexNormal).Buffer()
+	uvs := geom.VBO(gls.VertexTexcoord).Buffer()
+	if normals.Len() != positions.Len() || uvs.Len() != positions.Len()/3*2 {
+		t.Fatal("mesh", mesh.Name(), "has", positions.Len(), "position,", normals.Len(), "normal and", uvs.Len(), "uv components")
+	}
+	var vertices []objVertex
+	for _, i := range geom.Indices() {
+		var v objVertex
+		positions.GetVector3(3*int(i), &v.pos)
+		normals.GetVector3(3*int(i), &v.normal)
+		uvs.GetVector2(2*int(i), &v.uv)
+		vertices = append(vertices, v)
+	}
+	return vertices
+}
+
+// Test the meshes and materials of an obj file with several objects and materials
+func TestLoadOBJ(t *testing.T) {
+
+	meshes, mats, err := LoadOBJ("testdata/multimaterial.obj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(meshes) != 3 || len(mats) != 3 {
+		t.Fatal(len(meshes), "meshes and", len(mats), "materials instead of 3")
+	}
+
+	// One mesh for each material of each object, in the order of first use
+	names := []string{"panel", "panel", "stand"}
+	for i, mesh := range meshes {
+		if mesh.Name() != names[i] {
+			t.Error("mesh", i, "named", mesh.Name(), "instead of", names[i])
+		}
+	}
+	if mats[1] != mats[2] || mats[0] == mats[1] {
+		t.Error("materials not shared by material name")
+	}
+
+	// Material properties of the library
+	colors := []math32.Color{{R: 1}, {B: 1}}
+	speculars := []math32.Color{{R: 0.5, G: 0.5, B: 0.5}, {R: 1, G: 1, B: 1}}
+	shininess := []float32{100, 20}
+	for i, mat := range mats[:2] {
+		phong, ok := mat.(*material.Phong)
+		if !ok {
+			t.Fatalf("material %d is a %T instead of a Phong material", i, mat)
+		}
+		if phong.Color() != colors[i] || phong.SpecularColor() != speculars[i] || phong.Shininess() != shininess[i] {
+			t.Error("material", i, phong.Color(), phong.SpecularColor(), phong.Shininess())
+		}
+	}
+
+	// Triangles of the faces: the quad and the pentagon are fan triangulated
+	// and the negative indices are relative to the last vertices
+	p := func(x, y, z float32) math32.Vector3 { return math32.Vector3{X: x, Y: y, Z: z} }
+	front := p(0, 0, 1)
+	back := p(0, 0, -1)
+	uv := []math32.Vector2{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}}
+	expected := [][]objVertex{
+		{
+			{p(0, 0, 0), front, uv[0]}, {p(1, 0, 0), front, uv[1]}, {p(1, 1, 0), front, uv[2]},
+			{p(0, 0, 0), front, uv[0]}, {p(1, 1, 0), front, uv[2]}, {p(0, 1, 0), front, uv[3]},
+			{p(0, 0, 0), front, uv[0]}, {p(1, 1, 0), front, uv[2]}, {p(0, 1, 0), front, uv[3]},
+		},
+		{
+			{p(2, 0, 0), front, uv[0]}, {p(3, 0, 0), front, uv[1]}, {p(3.5, 1, 0), front, uv[2]},
+			{p(2, 0, 0), front, uv[0]}, {p(3.5, 1, 0), front, uv[2]}, {p(2.5, 2, 0), front, uv[3]},
+			{p(2, 0, 0), front, uv[0]}, {p(2.5, 2, 0), front, uv[3]}, {p(1.5, 1, 0), front, uv[0]},
+		},
+		{
+			{p(0, 0, 1), back, uv[0]}, {p(0, 1, 1), back, uv[2]}, {p(1, 0, 1), back, uv[1]},
+		},
+	}
+	for i, mesh := range meshes {
+		vertices := meshVertices(t, mesh)
+		if len(vertices) != len(expected[i]) {
+			t.Fatal("mesh", i, "has", len(vertices)/3, "triangles instead of", len(expected[i])/3)
+		}
+		for k := range vertices {
+			if vertices[k] != expected[i][k] {
+				t.Error("mesh", i, "vertex", k, vertices[k], "instead of", expected[i][k])
+			}
+		}
+	}
+}
+
+// Test the error of a missing obj file
+func TestLoadOBJMissing(t *testing.T) {
+
+	if _, _, err := LoadOBJ("testdata/missing.obj"); err == nil {
+		t.Error("no error for a missing file")
+	}
+}
@@ -0,0 +1,410 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ply is used to read and write the Stanford Polygon File Format (*.ply),
+// in its ASCII and binary variants, used for point clouds and triangle meshes.
+// Only the positions, normals and colors of the vertices and the vertex indices
+// of the faces are supported. Basic format info: http://paulbourke.net/dataformats/ply/
+package ply
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// Formats of the PLY body
+const (
+	formatASCII        = "ascii"
+	formatLittleEndian = "binary_little_endian"
+	formatBigEndian    = "binary_big_endian"
+)
+
+// propType is the type of a scalar property value.
+type propType struct {
+	size  int     // size in bytes in the binary formats
+	float bool    // floating point type
+	max   float64 // maximum value of the integer types, used to normalize colors
+}
+
+// propTypes maps the names of the property types, including the aliases, to the types.
+var propTypes = map[string]propType{
+	"char":    {size: 1, max: math.MaxInt8},
+	"int8":    {size: 1, max: math.MaxInt8},
+	"uchar":   {size: 1, max: math.MaxUint8},
+	"uint8":   {size: 1, max: math.MaxUint8},
+	"short":   {size: 2, max: math.MaxInt16},
+	"int16":   {size: 2, max: math.MaxInt16},
+	"ushort":  {size: 2, max: math.MaxUint16},
+	"uint16":  {size: 2, max: math.MaxUint16},
+	"int":     {size: 4, max: math.MaxInt32},
+	"int32":   {size: 4, max: math.MaxInt32},
+	"uint":    {size: 4, max: math.MaxUint32},
+	"uint32":  {size: 4, max: math.MaxUint32},
+	"float":   {size: 4, float: true},
+	"float32": {size: 4, float: true},
+	"double":  {size: 8, float: true},
+	"float64": {size: 8, float: true},
+}
+
+// property is a property of an element declared in the header.
+type property struct {
+	name     string
+	typ      string
+	list     bool   // list property, with the count of items before the items
+	countTyp string // type of the count of a list property
+}
+
+// element is an element declared in the header, with its properties.
+type element struct {
+	name  string
+	count int
+	props []property
+}
+
+// decoder reads the values of the body of a PLY file.
+type decoder struct {
+	format  string
+	order   binary.ByteOrder
+	reader  *bufio.Reader
+	scanner *bufio.Scanner // tokens of the ASCII body
+	buf     [8]byte
+}
+
+// LoadPLY decodes the PLY file from the specified reader and returns a geometry
+// with the positions and, if present, the normals and colors of its vertices.
+// The faces with more than three vertices are triangulated as fans.
+// The geometry of a point cloud, without faces, has no indices.
+func LoadPLY(r io.Reader) (*geometry.Geometry, error) {
+
+	dec := &decoder{reader: bufio.NewReader(r)}
+	elements, err := dec.parseHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	positions := math32.NewArrayF32(0, 0)
+	normals := math32.NewArrayF32(0, 0)
+	colors := math32.NewArrayF32(0, 0)
+	indices := math32.NewArrayU32(0, 0)
+	var hasNormals, hasColors, hasFaces bool
+	vertices := 0
+	for _, el := range elements {
+		switch el.name {
+		case "vertex":
+			vertices = el.count
+			hasNormals, hasColors, err = dec.readVertices(&el, &positions, &normals, &colors)
+		case "face":
+			hasFaces = true
+			err = dec.readFaces(&el, vertices, &indices)
+		default:
+			err = dec.skipElement(&el)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	geom := geometry.NewGeometry()
+	if hasFaces {
+		geom.SetIndices(indices)
+	}
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	if hasNormals {
+		geom.AddVBO(gls.NewVBO(normals).AddAttrib(gls.VertexNormal))
+	}
+	if hasColors {
+		geom.AddVBO(gls.NewVBO(colors).AddAttrib(gls.VertexColor))
+	}
+	return geom, nil
+}
+
+// parseHeader reads the header of the file and returns the declared elements.
+func (dec *decoder) parseHeader() ([]element, error) {
+
+	line, err := dec.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if line != "ply" {
+		return nil, errors.New("not a PLY file")
+	}
+	var elements []element
+	for {
+		line, err := dec.readLine()
+		if err != nil {
+			return nil, err
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "format":
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("invalid PLY format line: %q", line)
+			}
+			dec.format = fields[1]
+			switch dec.format {
+			case formatASCII:
+			case formatLittleEndian:
+				dec.order = binary.LittleEndian
+			case formatBigEndian:
+				dec.order = binary.BigEndian
+			default:
+				return nil, fmt.Errorf("unsupported PLY format: %s", dec.format)
+			}
+		case "comment", "obj_info":
+		case "element":
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("invalid PLY element line: %q", line)
+			}
+			count, err := strconv.Atoi(fields[2])
+			if err != nil || count < 0 {
+				return nil, fmt.Errorf("invalid PLY element count: %q", line)
+			}
+			elements = append(elements, element{name: fields[1], count: count})
+		case "property":
+			if len(elements) == 0 {
+				return nil, fmt.Errorf("PLY property without element: %q", line)
+			}
+			prop, err := parseProperty(fields)
+			if err != nil {
+				return nil, err
+			}
+			el := &elements[len(elements)-1]
+			el.props = append(el.props, prop)
+		case "end_header":
+			if dec.format == "" {
+				return nil, errors.New("PLY header without format")
+			}
+			if dec.format == formatASCII {
+				dec.scanner = bufio.NewScanner(dec.reader)
+				dec.scanner.Split(bufio.ScanWords)
+			}
+			return elements, nil
+		default:
+			return nil, fmt.Errorf("invalid PLY header line: %q", line)
+		}
+	}
+}
+
+// parseProperty parses the fields of a property line of the header.
+// property <type> <name>
+// property list <count type> <item type> <name>
+func parseProperty(fields []string) (property, error) {
+
+	var prop property
+	if len(fields) == 5 && fields[1] == "list" {
+		prop = property{name: fields[4], typ: fields[3], list: true, countTyp: fields[2]}
+		if _, ok := propTypes[prop.countTyp]; !ok {
+			return prop, fmt.Errorf("invalid PLY property type: %s", prop.countTyp)
+		}
+	} else if len(fields) == 3 {
+		prop = property{name: fields[2], typ: fields[1]}
+	} else {
+		return prop, fmt.Errorf("invalid PLY property line: %q", strings.Join(fields, " "))
+	}
+	if _, ok := propTypes[prop.typ]; !ok {
+		return prop, fmt.Errorf("invalid PLY property type: %s", prop.typ)
+	}
+	return prop, nil
+}
+
+// readLine reads a line of the header without the line terminator.
+func (dec *decoder) readLine() (string, error) {
+
+	line, err := dec.reader.ReadString('\n')
+	if err != nil {
+		if err == io.EOF {
+			return "", errors.New("unexpected end of PLY header")
+		}
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readValue reads a scalar value of the specified type from the body.
+func (dec *decoder) readValue(typ string) (float64, error) {
+
+	if dec.scanner != nil {
+		if !dec.scanner.Scan() {
+			if err := dec.scanner.Err(); err != nil {
+				return 0, err
+			}
+			return 0, io.ErrUnexpectedEOF
+		}
+		v, err := strconv.ParseFloat(dec.scanner.Text(), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid PLY value: %q", dec.scanner.Text())
+		}
+		return v, nil
+	}
+
+	b := dec.buf[:propTypes[typ].size]
+	_, err := io.ReadFull(dec.reader, b)
+	if err != nil {
+		if err == io.EOF {
+			return 0, io.ErrUnexpectedEOF
+		}
+		return 0, err
+	}
+	switch typ {
+	case "char", "int8":
+		return float64(int8(b[0])), nil
+	case "uchar", "uint8":
+		return float64(b[0]), nil
+	case "short", "int16":
+		return float64(int16(dec.order.Uint16(b))), nil
+	case "ushort", "uint16":
+		return float64(dec.order.Uint16(b)), nil
+	case "int", "int32":
+		return float64(int32(dec.order.Uint32(b))), nil
+	case "uint", "uint32":
+		return float64(dec.order.Uint32(b)), nil
+	case "float", "float32":
+		return float64(math.Float32frombits(dec.order.Uint32(b))), nil
+	default:
+		return math.Float64frombits(dec.order.Uint64(b)), nil
+	}
+}
+
+// readList reads the items of a list property.
+func (dec *decoder) readList(prop *property, items []float64) ([]float64, error) {
+
+	count, err := dec.readValue(prop.countTyp)
+	if err != nil {
+		return nil, err
+	}
+	items = items[:0]
+	for i := 0; i < int(count); i++ {
+		v, err := dec.readValue(prop.typ)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+	}
+	return items, nil
+}
+
+// readVertices reads the vertex element, appending the positions, normals and colors
+// to the specified arrays, and returns if the vertices have normals and colors.
+func (dec *decoder) readVertices(el *element, positions, normals, colors *math32.ArrayF32) (bool, bool, error) {
+
+	// Index of each property in the vertex values, or -1 if absent
+	idx := map[string]int{"x": -1, "y": -1, "z": -1, "nx": -1, "ny": -1, "nz": -1, "red": -1, "green": -1, "blue": -1}
+	for i, prop := range el.props {
+		if _, ok := idx[prop.name]; ok && !prop.list {
+			idx[prop.name] = i
+		}
+	}
+	if idx["x"] < 0 || idx["y"] < 0 || idx["z"] < 0 {
+		return false, false, errors.New("PLY vertex without position")
+	}
+	hasNormals := idx["nx"] >= 0 && idx["ny"] >= 0 && idx["nz"] >= 0
+	hasColors := idx["red"] >= 0 && idx["green"] >= 0 && idx["blue"] >= 0
+
+	// Converts a color value to the range 0 to 1
+	color := func(values []float64, name string) float32 {
+		v := values[idx[name]]
+		if t := propTypes[el.props[idx[name]].typ]; !t.float {
+			v /= t.max
+		}
+		return float32(v)
+	}
+
+	values := make([]float64, len(el.props))
+	var items []float64
+	for n := 0; n < el.count; n++ {
+		for i := range el.props {
+			prop := &el.props[i]
+			var err error
+			if prop.list {
+				items, err = dec.readList(prop, items)
+			} else {
+				values[i], err = dec.readValue(prop.typ)
+			}
+			if err != nil {
+				return false, false, err
+			}
+		}
+		positions.Append(float32(values[idx["x"]]), float32(values[idx["y"]]), float32(values[idx["z"]]))
+		if hasNormals {
+			normals.Append(float32(values[idx["nx"]]), float32(values[idx["ny"]]), float32(values[idx["nz"]]))
+		}
+		if hasColors {
+			colors.Append(color(values, "red"), color(values, "green"), color(values, "blue"))
+		}
+	}
+	return hasNormals, hasColors, nil
+}
+
+// readFaces reads the face element, appending the triangulated
+// vertex indices of the faces to the specified array.
+func (dec *decoder) readFaces(el *element, vertices int, indices *math32.ArrayU32) error {
+
+	var items []float64
+	var face []uint32
+	for n := 0; n < el.count; n++ {
+		face = face[:0]
+		for i := range el.props {
+			prop := &el.props[i]
+			if !prop.list {
+				_, err := dec.readValue(prop.typ)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			var err error
+			items, err = dec.readList(prop, items)
+			if err != nil {
+				return err
+			}
+			if prop.name != "vertex_indices" && prop.name != "vertex_index" {
+				continue
+			}
+			for _, v := range items {
+				if v < 0 || int(v) >= vertices {
+					return fmt.Errorf("invalid PLY vertex index: %v", v)
+				}
+				face = append(face, uint32(v))
+			}
+		}
+		for i := 1; i < len(face)-1; i++ {
+			indices.Append(face[0], face[i], face[i+1])
+		}
+	}
+	return nil
+}
+
+// skipElement reads and discards the values of an element not supported.
+func (dec *decoder) skipElement(el *element) error {
+
+	var items []float64
+	for n := 0; n < el.count; n++ {
+		for i := range el.props {
+			prop := &el.props[i]
+			var err error
+			if prop.list {
+				items, err = dec.readList(prop, items)
+			} else {
+				_, err = dec.readValue(prop.typ)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ply
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// loadFile loads the specified PLY file of the testdata directory.
+func loadFile(t *testing.T, name string) *geometry.Geometry {
+
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	geom, err := LoadPLY(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(name, err)
+	}
+	return geom
+}
+
+// checkEqual tests that the specified geometries have the same positions, normals, colors and indices.
+func checkEqual(t *testing.T, want, got *geometry.Geometry) {
+
+	t.Helper()
+	for _, atype := range []gls.AttribType{gls.VertexPosition, gls.VertexNormal, gls.VertexColor} {
+		w, g := readVectors(want, atype), readVectors(got, atype)
+		if len(g) != len(w) {
+			t.Fatal(len(g), atype, "vectors instead of", len(w))
+		}
+		for i := range w {
+			if !g[i].Equals(&w[i]) {
+				t.Fatal(atype, i, "is", g[i], "instead of", w[i])
+			}
+		}
+	}
+	w, g := want.Indices(), got.Indices()
+	if len(g) != len(w) {
+		t.Fatal(len(g), "indices instead of", len(w))
+	}
+	for i := range w {
+		if g[i] != w[i] {
+			t.Fatal("index", i, "is", g[i], "instead of", w[i])
+		}
+	}
+}
+
+// Test the ASCII file of a quad with vertex colors
+func TestLoadPLYASCII(t *testing.T) {
+
+	geom := loadFile(t, "quad.ply")
+	positions := readVectors(geom, gls.VertexPosition)
+	colors := readVectors(geom, gls.VertexColor)
+	if len(positions) != 4 || len(colors) != 4 || readVectors(geom, gls.VertexNormal) != nil {
+		t.Fatal(len(positions), "positions,", len(colors), "colors and normals", readVectors(geom, gls.VertexNormal))
+	}
+	if positions[2] != (math32.Vector3{X: 1, Y: 1}) || colors[1] != (math32.Vector3{Y: 1}) {
+		t.Error("vertex 2 at", positions[2], "and vertex 1 of color", colors[1])
+	}
+	// The quad is triangulated as a fan
+	if indices := geom.Indices(); len(indices) != 6 {
+		t.Error("quad triangulated with the indices", indices)
+	}
+
+	data, _ := os.ReadFile("testdata/quad.ply")
+	if _, err := LoadPLY(bytes.NewReader(data[:len(data)-5])); err == nil {
+		t.Error("truncated file loaded")
+	}
+}
+
+// Test a big endian binary file with double positions, line terminators CR LF and an unknown element
+func TestLoadPLYBigEndian(t *testing.T) {
+
+	var buf bytes.Buffer
+	buf.WriteString("ply\r\nformat binary_big_endian 1.0\r\nelement vertex 1\r\n" +
+		"property double x\r\nproperty double y\r\nproperty double z\r\n" +
+		"element extra 1\r\nproperty list uchar short k\r\nend_header\r\n")
+	binary.Write(&buf, binary.BigEndian, []float64{1.5, -2, 3})
+	buf.Write([]byte{2, 0, 1, 0, 2})
+	geom, err := LoadPLY(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := readVectors(geom, gls.VertexPosition); len(p) != 1 || p[0] != (math32.Vector3{X: 1.5, Y: -2, Z: 3}) || geom.Indexed() {
+		t.Error("point cloud loaded with the positions", p)
+	}
+}
+
+// Test that the geometries written in the ASCII and binary formats are read back equal
+func TestWritePLYRoundTrip(t *testing.T) {
+
+	for _, geom := range []*geometry.Geometry{loadFile(t, "quad.ply"), &geometry.NewBox(1, 2, 3).Geometry} {
+		for _, binaryFormat := range []bool{false, true} {
+			var buf bytes.Buffer
+			if err := WritePLY(&buf, geom, binaryFormat); err != nil {
+				t.Fatal(err)
+			}
+			loaded, err := LoadPLY(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkEqual(t, geom, loaded)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ply
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// WritePLY writes the vertex positions, normals and colors and the triangles of the specified geometry
+// to the specified writer in the PLY format, binary little endian or ASCII. The colors are written as
+// 8 bit values. The triangles are only written for indexed geometries: the vertices of the other
+// geometries are written as a point cloud.
+func WritePLY(w io.Writer, igeom geometry.IGeometry, binaryFormat bool) error {
+
+	geom := igeom.GetGeometry()
+	positions := readVectors(geom, gls.VertexPosition)
+	normals := readVectors(geom, gls.VertexNormal)
+	colors := readVectors(geom, gls.VertexColor)
+	if len(normals) != len(positions) {
+		normals = nil
+	}
+	if len(colors) != len(positions) {
+		colors = nil
+	}
+	indices := geom.Indices()
+	faces := indices.Size() / 3
+
+	// Writes the header
+	bw := bufio.NewWriter(w)
+	format := formatASCII
+	if binaryFormat {
+		format = formatLittleEndian
+	}
+	fmt.Fprintf(bw, "ply\nformat %s 1.0\ncomment g3n\n", format)
+	fmt.Fprintf(bw, "element vertex %d\n", len(positions))
+	bw.WriteString("property float x\nproperty float y\nproperty float z\n")
+	if normals != nil {
+		bw.WriteString("property float nx\nproperty float ny\nproperty float nz\n")
+	}
+	if colors != nil {
+		bw.WriteString("property uchar red\nproperty uchar green\nproperty uchar blue\n")
+	}
+	if faces > 0 {
+		fmt.Fprintf(bw, "element face %d\n", faces)
+		bw.WriteString("property list uchar uint vertex_indices\n")
+	}
+	bw.WriteString("end_header\n")
+
+	// Writes the vertices
+	var buf [4]byte
+	writeFloat := func(v float32, last bool) {
+		if binaryFormat {
+			binary.LittleEndian.PutUint32(buf[:], math.Float32bits(v))
+			bw.Write(buf[:])
+			return
+		}
+		// Shortest representation which reads back exactly
+		bw.WriteString(strconv.FormatFloat(float64(v), 'g', -1, 32))
+		writeSep(bw, last)
+	}
+	writeByte := func(v float32, last bool) {
+		b := uint8(math32.Clamp(v, 0, 1)*255 + 0.5)
+		if binaryFormat {
+			bw.WriteByte(b)
+			return
+		}
+		fmt.Fprintf(bw, "%d", b)
+		writeSep(bw, last)
+	}
+	for i, p := range positions {
+		writeFloat(p.X, false)
+		writeFloat(p.Y, false)
+		writeFloat(p.Z, normals == nil && colors == nil)
+		if normals != nil {
+			n := normals[i]
+			writeFloat(n.X, false)
+			writeFloat(n.Y, false)
+			writeFloat(n.Z, colors == nil)
+		}
+		if colors != nil {
+			c := colors[i]
+			writeByte(c.X, false)
+			writeByte(c.Y, false)
+			writeByte(c.Z, true)
+		}
+	}
+
+	// Writes the triangles
+	for i := 0; i < faces*3; i += 3 {
+		if binaryFormat {
+			bw.WriteByte(3)
+			for _, idx := range indices[i : i+3] {
+				binary.LittleEndian.PutUint32(buf[:], idx)
+				bw.Write(buf[:])
+			}
+			continue
+		}
+		fmt.Fprintf(bw, "3 %d %d %d\n", indices[i], indices[i+1], indices[i+2])
+	}
+	return bw.Flush()
+}
+
+// readVectors returns the 3 component values of the specified attribute of the geometry, or nil if absent.
+func readVectors(geom *geometry.Geometry, atype gls.AttribType) []math32.Vector3 {
+
+	vbo := geom.VBO(atype)
+	if vbo == nil {
+		return nil
+	}
+	var vecs []math32.Vector3
+	vbo.ReadVectors3(atype, func(vec math32.Vector3) bool {
+		vecs = append(vecs, vec)
+		return false
+	})
+	return vecs
+}
+
+// writeSep writes the separator after a value of an ASCII vertex.
+func writeSep(bw *bufio.Writer, last bool) {
+
+	if last {
+		bw.WriteByte('\n')
+	} else {
+		bw.WriteByte(' ')
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package stl is used to read and write the STL file format (*.stl) of 3D printing and CAD tools,
+// in its ASCII and binary variants. Basic format info: https://en.wikipedia.org/wiki/STL_(file_format)
+package stl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// Sizes of the binary format
+const (
+	headerSize   = 80
+	triangleSize = 50 // normal, 3 vertices and attribute byte count
+)
+
+// LoadSTL decodes the ASCII or binary STL file from the specified reader and returns an indexed
+// geometry with its triangles. The format is detected from the size and first bytes of the file.
+// The vertices of the triangles closer than the specified epsilon are welded into a single
+// vertex, exactly equal vertices with an epsilon of zero, and none with a negative epsilon.
+// The vertex normals are the averages of the normals of the triangles sharing the vertex,
+// weighted by their areas.
+func LoadSTL(r io.Reader, weldEpsilon float32) (*geometry.Geometry, error) {
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Binary files may also begin with "solid", so the size is checked first
+	var triangles []math32.Vector3
+	if len(data) >= headerSize+4 && len(data) == headerSize+4+triangleSize*int(binary.LittleEndian.Uint32(data[headerSize:])) {
+		triangles = decodeBinary(data)
+	} else if bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("solid")) {
+		triangles, err = decodeASCII(data)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, errors.New("invalid STL file")
+	}
+	return newGeometry(triangles, weldEpsilon), nil
+}
+
+// decodeBinary returns the vertices of the triangles of the binary STL data, whose size is already checked.
+func decodeBinary(data []byte) []math32.Vector3 {
+
+	count := int(binary.LittleEndian.Uint32(data[headerSize:]))
+	vertices := make([]math32.Vector3, 0, 3*count)
+	float := func(b []byte) float32 {
+		return math.Float32frombits(binary.LittleEndian.Uint32(b))
+	}
+	for i := 0; i < count; i++ {
+		// Skips the normal, recomputed from the vertices, and the attribute byte count
+		t := data[headerSize+4+i*triangleSize+12:]
+		for v := 0; v < 3; v++ {
+			vertices = append(vertices, math32.Vector3{X: float(t[12*v:]), Y: float(t[12*v+4:]), Z: float(t[12*v+8:])})
+		}
+	}
+	return vertices
+}
+
+// decodeASCII returns the vertices of the triangles of the ASCII STL data:
+// solid <name>
+// facet normal <nx> <ny> <nz>
+// outer loop
+// vertex <x> <y> <z>
+// ...
+// endloop
+// endfacet
+// ...
+// endsolid <name>
+func decodeASCII(data []byte) ([]math32.Vector3, error) {
+
+	var vertices []math32.Vector3
+	inFacet := 0 // vertices of the current facet
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "vertex":
+			if len(fields) != 4 {
+				return nil, fmt.Errorf("invalid STL vertex in line:%d", line)
+			}
+			var v [3]float32
+			for i := range v {
+				f, err := strconv.ParseFloat(fields[i+1], 32)
+				if err != nil {
+					return nil, fmt.Errorf("invalid STL vertex in line:%d", line)
+				}
+				v[i] = float32(f)
+			}
+			vertices = append(vertices, math32.Vector3{X: v[0], Y: v[1], Z: v[2]})
+			inFacet++
+		case "facet":
+			inFacet = 0
+		case "endfacet":
+			if inFacet != 3 {
+				return nil, fmt.Errorf("STL facet with %d vertices in line:%d", inFacet, line)
+			}
+		case "solid", "outer", "endloop", "endsolid":
+		default:
+			return nil, fmt.Errorf("invalid STL keyword %q in line:%d", fields[0], line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(vertices)%3 != 0 {
+		return nil, errors.New("unexpected end of STL file")
+	}
+	return vertices, nil
+}
+
+// newGeometry creates the geometry of the specified triangle vertices, welding them with the specified epsilon.
+func newGeometry(triangles []math32.Vector3, epsilon float32) *geometry.Geometry {
+
+	positions := math32.NewArrayF32(0, 0)
+	indices := math32.NewArrayU32(0, len(triangles))
+	var welded []math32.Vector3
+
+	// Vertices by the cell of the grid with the size of epsilon which contains them
+	type cell [3]int64
+	cells := make(map[cell][]uint32)
+	cellOf := func(v *math32.Vector3) cell {
+		return cell{int64(math.Floor(float64(v.X / epsilon))), int64(math.Floor(float64(v.Y / epsilon))), int64(math.Floor(float64(v.Z / epsilon)))}
+	}
+	exact := make(map[math32.Vector3]uint32)
+	find := func(v *math32.Vector3) (uint32, bool) {
+		if epsilon == 0 {
+			idx, ok := exact[*v]
+			return idx, ok
+		}
+		// The vertices closer than epsilon are in the same or adjacent cells
+		c := cellOf(v)
+		for dx := int64(-1); dx <= 1; dx++ {
+			for dy := int64(-1); dy <= 1; dy++ {
+				for dz := int64(-1); dz <= 1; dz++ {
+					for _, idx := range cells[cell{c[0] + dx, c[1] + dy, c[2] + dz}] {
+						if welded[idx].DistanceTo(v) <= epsilon {
+							return idx, true
+						}
+					}
+				}
+			}
+		}
+		return 0, false
+	}
+
+	for i := range triangles {
+		v := &triangles[i]
+		if epsilon >= 0 {
+			if idx, ok := find(v); ok {
+				indices.Append(idx)
+				continue
+			}
+		}
+		idx := uint32(len(welded))
+		welded = append(welded, *v)
+		if epsilon == 0 {
+			exact[*v] = idx
+		} else if epsilon > 0 {
+			c := cellOf(v)
+			cells[c] = append(cells[c], idx)
+		}
+		positions.AppendVector3(v)
+		indices.Append(idx)
+	}
+
+	// Accumulates the area weighted normals of the triangles, as the cross products of their edges
+	normals := make([]math32.Vector3, len(welded))
+	for i := 0; i+2 < len(indices); i += 3 {
+		a, b, c := welded[indices[i]], welded[indices[i+1]], welded[indices[i+2]]
+		var n math32.Vector3
+		n.CrossVectors(b.Sub(&a), c.Sub(&a))
+		for _, idx := range indices[i : i+3] {
+			normals[idx].Add(&n)
+		}
+	}
+	normalsArray := math32.NewArrayF32(0, 3*len(normals))
+	for i := range normals {
+		normalsArray.AppendVector3(normals[i].Normalize())
+	}
+
+	geom := geometry.NewGeometry()
+	geom.SetIndices(indices)
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(normalsArray).AddAttrib(gls.VertexNormal))
+	return geom
+}
@@ -0,0 +1,157 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stl
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// loadFile loads the specified STL file of the testdata directory.
+func loadFile(t *testing.T, name string, weldEpsilon float32) *geometry.Geometry {
+
+	t.Helper()
+	f, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	geom, err := LoadSTL(f, weldEpsilon)
+	if err != nil {
+		t.Fatal(name, err)
+	}
+	return geom
+}
+
+// vertexCount returns the number of vertices of the specified geometry.
+func vertexCount(geom *geometry.Geometry) int {
+
+	n := 0
+	geom.ReadVertices(func(vertex math32.Vector3) bool {
+		n++
+		return false
+	})
+	return n
+}
+
+// faces returns the vertices of the triangles of the specified geometry.
+func faces(geom *geometry.Geometry) []math32.Vector3 {
+
+	var vertices []math32.Vector3
+	geom.ReadFaces(func(vA, vB, vC math32.Vector3) bool {
+		vertices = append(vertices, vA, vB, vC)
+		return false
+	})
+	return vertices
+}
+
+// checkRoundTrip tests that the specified geometry written in the binary format is loaded with the same triangles.
+func checkRoundTrip(t *testing.T, geom *geometry.Geometry) {
+
+	t.Helper()
+	var buf bytes.Buffer
+	if err := WriteSTLBinary(&buf, geom); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadSTL(&buf, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, got := faces(geom), faces(loaded)
+	if len(got) != len(want) {
+		t.Fatal("round trip with", len(got)/3, "triangles instead of", len(want)/3)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatal("round trip vertex", i, got[i], "instead of", want[i])
+		}
+	}
+}
+
+// Test the ASCII STL file of a tetrahedron
+func TestLoadSTLASCII(t *testing.T) {
+
+	geom := loadFile(t, "tetrahedron.stl", 1e-6)
+	if n := vertexCount(geom); n != 4 || len(geom.Indices()) != 12 {
+		t.Fatal(n, "vertices and", len(geom.Indices()), "indices instead of 4 and 12")
+	}
+	if v := geom.Volume(); math32.Abs(v-1.0/6) > 1e-6 {
+		t.Error("volume", v, "instead of 1/6")
+	}
+	checkRoundTrip(t, geom)
+}
+
+// Test the binary STL file of a cube, whose header starts with "solid" as the ASCII files
+func TestLoadSTLBinary(t *testing.T) {
+
+	geom := loadFile(t, "cube_binary.stl", 1e-6)
+	if n := vertexCount(geom); n != 8 || len(geom.Indices()) != 36 {
+		t.Fatal(n, "vertices and", len(geom.Indices()), "indices instead of 8 and 36")
+	}
+	if v := geom.Volume(); math32.Abs(v-1) > 1e-6 {
+		t.Error("volume", v, "instead of 1")
+	}
+	box := geom.BoundingBox()
+	if box.Min != (math32.Vector3{}) || box.Max != (math32.Vector3{X: 1, Y: 1, Z: 1}) {
+		t.Error("bounding box", box, "instead of the unit cube")
+	}
+
+	// The normals of the corners are the averages of the normals of their triangles, pointing outwards
+	positions := geom.VBO(gls.VertexPosition).Buffer()
+	normals := geom.VBO(gls.VertexNormal).Buffer()
+	for i := 0; i < positions.Len()/3; i++ {
+		var pos, normal math32.Vector3
+		positions.GetVector3(3*i, &pos)
+		normals.GetVector3(3*i, &normal)
+		pos.SubScalar(0.5)
+		if math32.Abs(normal.Length()-1) > 1e-5 || normal.Dot(&pos) <= 0 {
+			t.Error("normal", normal, "of the corner", i)
+		}
+	}
+	checkRoundTrip(t, geom)
+}
+
+// Test the welding of the vertices with positive, zero and negative epsilons
+func TestLoadSTLWeld(t *testing.T) {
+
+	const ascii = `solid weld
+facet normal 0 0 1
+ outer loop
+  vertex 0 0 0
+  vertex 1 0 0
+  vertex 1 1 0
+ endloop
+endfacet
+facet normal 0 0 1
+ outer loop
+  vertex 0 0 0
+  vertex 1 1.0000001 0
+  vertex 0 1 0
+ endloop
+endfacet
+endsolid weld
+`
+	for _, c := range []struct {
+		epsilon  float32
+		vertices int
+	}{{1e-5, 4}, {0, 5}, {-1, 6}} {
+		geom, err := LoadSTL(strings.NewReader(ascii), c.epsilon)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := vertexCount(geom); n != c.vertices {
+			t.Error(n, "vertices instead of", c.vertices, "for the epsilon", c.epsilon)
+		}
+	}
+	if _, err := LoadSTL(strings.NewReader(ascii[:60]), 0); err == nil {
+		t.Error("no error for a truncated file")
+	}
+}
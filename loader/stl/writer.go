@@ -0,0 +1,53 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stl
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/math32"
+)
+
+// WriteSTLBinary writes the triangles of the specified geometry, indexed or not,
+// to the specified writer in the binary STL format, with their normals computed
+// from their counterclockwise vertices.
+func WriteSTLBinary(w io.Writer, igeom geometry.IGeometry) error {
+
+	geom := igeom.GetGeometry()
+	var triangles []math32.Vector3
+	geom.ReadFaces(func(vA, vB, vC math32.Vector3) bool {
+		triangles = append(triangles, vA, vB, vC)
+		return false
+	})
+
+	bw := bufio.NewWriter(w)
+	var header [headerSize + 4]byte
+	copy(header[:], "g3n binary STL")
+	binary.LittleEndian.PutUint32(header[headerSize:], uint32(len(triangles)/3))
+	bw.Write(header[:])
+
+	var buf [triangleSize]byte
+	put := func(offset int, v *math32.Vector3) {
+		binary.LittleEndian.PutUint32(buf[offset:], math.Float32bits(v.X))
+		binary.LittleEndian.PutUint32(buf[offset+4:], math.Float32bits(v.Y))
+		binary.LittleEndian.PutUint32(buf[offset+8:], math.Float32bits(v.Z))
+	}
+	for i := 0; i < len(triangles); i += 3 {
+		a, b, c := triangles[i], triangles[i+1], triangles[i+2]
+		var n math32.Vector3
+		n.CrossVectors(b.Sub(&a), c.Sub(&a)).Normalize()
+		put(0, &n)
+		for v := 0; v < 3; v++ {
+			put(12+12*v, &triangles[i+v])
+		}
+		// The attribute byte count is left zero
+		bw.Write(buf[:])
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,594 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package vtk is used to read the datasets of the legacy ASCII VTK file format (*.vtk)
+// of scientific visualization tools like ParaView. Only the structured and unstructured
+// grids are supported. Basic format info: https://vtk.org/wp-content/uploads/2015/04/file-formats.pdf
+package vtk
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// Dataset types
+const (
+	StructuredGrid   = "STRUCTURED_GRID"
+	UnstructuredGrid = "UNSTRUCTURED_GRID"
+)
+
+// Cell types
+const (
+	CellVertex        = 1
+	CellPolyVertex    = 2
+	CellLine          = 3
+	CellPolyLine      = 4
+	CellTriangle      = 5
+	CellTriangleStrip = 6
+	CellPolygon       = 7
+	CellPixel         = 8
+	CellQuad          = 9
+	CellTetra         = 10
+	CellVoxel         = 11
+	CellHexahedron    = 12
+)
+
+// VTKDataset contains the decoded points, cells and attributes of a VTK dataset.
+// The attributes with several components per point or cell, like vectors, have their components
+// stored consecutively. The cells of the structured grids are generated from their dimensions.
+type VTKDataset struct {
+	Title      string               // Title in the header
+	Type       string               // Dataset type: StructuredGrid or UnstructuredGrid
+	Dimensions [3]int               // Number of points in each direction of a structured grid
+	Points     []math32.Vector3     // Points positions
+	Cells      [][]int              // Indices of the points of each cell
+	CellTypes  []int                // Type of each cell
+	PointData  map[string][]float32 // Attributes of the points, by name
+	CellData   map[string][]float32 // Attributes of the cells, by name
+}
+
+// decoder reads the tokens of a VTK file.
+type decoder struct {
+	scanner *bufio.Scanner
+	token   string // token read back by unread
+	unread  bool
+}
+
+// keywords are the section keywords, used to skip the sections which are not supported.
+var keywords = map[string]bool{
+	"POINTS": true, "CELLS": true, "CELL_TYPES": true, "DIMENSIONS": true, "POINT_DATA": true, "CELL_DATA": true,
+	"SCALARS": true, "COLOR_SCALARS": true, "VECTORS": true, "NORMALS": true, "TENSORS": true,
+	"TEXTURE_COORDINATES": true, "FIELD": true, "LOOKUP_TABLE": true,
+}
+
+// LoadVTK decodes the legacy ASCII VTK file from the specified reader and returns its dataset.
+func LoadVTK(r io.Reader) (*VTKDataset, error) {
+
+	br := bufio.NewReader(r)
+	lines := make([]string, 3)
+	for i := range lines {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, errors.New("unexpected end of VTK header")
+		}
+		lines[i] = strings.TrimSpace(line)
+	}
+	if !strings.HasPrefix(lines[0], "# vtk DataFile") {
+		return nil, errors.New("not a VTK file")
+	}
+	if !strings.EqualFold(lines[2], "ASCII") {
+		return nil, fmt.Errorf("unsupported VTK format: %s", lines[2])
+	}
+
+	ds := &VTKDataset{Title: lines[1], PointData: make(map[string][]float32), CellData: make(map[string][]float32)}
+	dec := &decoder{scanner: bufio.NewScanner(br)}
+	dec.scanner.Split(bufio.ScanWords)
+	if tok, _ := dec.next(); tok != "DATASET" {
+		return nil, errors.New("VTK file without DATASET")
+	}
+	ds.Type, _ = dec.next()
+	if ds.Type != StructuredGrid && ds.Type != UnstructuredGrid {
+		return nil, fmt.Errorf("unsupported VTK dataset type: %s", ds.Type)
+	}
+
+	// Attributes section currently read, with its data map and number of items
+	var data map[string][]float32
+	items := 0
+	for {
+		tok, err := dec.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch strings.ToUpper(tok) {
+		case "DIMENSIONS":
+			for i := range ds.Dimensions {
+				ds.Dimensions[i], err = dec.int()
+				if err != nil {
+					return nil, err
+				}
+			}
+		case "POINTS":
+			err = dec.parsePoints(ds)
+		case "CELLS":
+			err = dec.parseCells(ds)
+		case "CELL_TYPES":
+			err = dec.parseCellTypes(ds)
+		case "POINT_DATA":
+			data = ds.PointData
+			items, err = dec.int()
+		case "CELL_DATA":
+			data = ds.CellData
+			items, err = dec.int()
+		case "SCALARS", "COLOR_SCALARS", "VECTORS", "NORMALS", "TENSORS", "TEXTURE_COORDINATES", "FIELD":
+			if data == nil {
+				return nil, fmt.Errorf("VTK %s outside of POINT_DATA or CELL_DATA", tok)
+			}
+			err = dec.parseAttribute(strings.ToUpper(tok), data, items)
+		case "LOOKUP_TABLE":
+			// Lookup table definition: name, size and RGBA values
+			_, err = dec.next()
+			if err == nil {
+				var size int
+				size, err = dec.int()
+				if err == nil {
+					_, err = dec.floats(4 * size)
+				}
+			}
+		case "METADATA":
+			err = dec.skipSection()
+		default:
+			return nil, fmt.Errorf("invalid VTK keyword: %s", tok)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if ds.Type == StructuredGrid {
+		return ds, ds.structuredCells()
+	}
+	if len(ds.CellTypes) != len(ds.Cells) {
+		return nil, fmt.Errorf("%d VTK cell types for %d cells", len(ds.CellTypes), len(ds.Cells))
+	}
+	return ds, nil
+}
+
+// next reads the next token, returning io.EOF at the end of the file.
+func (dec *decoder) next() (string, error) {
+
+	if dec.unread {
+		dec.unread = false
+		return dec.token, nil
+	}
+	if !dec.scanner.Scan() {
+		if err := dec.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	dec.token = dec.scanner.Text()
+	return dec.token, nil
+}
+
+// back makes the last token read the next one.
+func (dec *decoder) back() {
+
+	dec.unread = true
+}
+
+// int reads an integer token.
+func (dec *decoder) int() (int, error) {
+
+	tok, err := dec.next()
+	if err != nil {
+		return 0, io.ErrUnexpectedEOF
+	}
+	v, err := strconv.Atoi(tok)
+	if err != nil {
+		return 0, fmt.Errorf("invalid VTK integer: %q", tok)
+	}
+	return v, nil
+}
+
+// floats reads the specified number of float tokens.
+func (dec *decoder) floats(count int) ([]float32, error) {
+
+	values := make([]float32, count)
+	for i := range values {
+		tok, err := dec.next()
+		if err != nil {
+			return nil, io.ErrUnexpectedEOF
+		}
+		v, err := strconv.ParseFloat(tok, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid VTK value: %q", tok)
+		}
+		values[i] = float32(v)
+	}
+	return values, nil
+}
+
+// parsePoints parses the points of the dataset:
+// POINTS <count> <type> <x y z>...
+func (dec *decoder) parsePoints(ds *VTKDataset) error {
+
+	count, err := dec.int()
+	if err != nil {
+		return err
+	}
+	if _, err = dec.next(); err != nil {
+		return io.ErrUnexpectedEOF
+	}
+	values, err := dec.floats(3 * count)
+	if err != nil {
+		return err
+	}
+	ds.Points = make([]math32.Vector3, count)
+	for i := range ds.Points {
+		ds.Points[i] = math32.Vector3{X: values[3*i], Y: values[3*i+1], Z: values[3*i+2]}
+	}
+	return nil
+}
+
+// parseCells parses the cells of an unstructured grid, in the format of the version 4:
+// CELLS <count> <size> <points count> <point index>...
+// or in the format of the version 5:
+// CELLS <offsets count> <connectivity count> OFFSETS <type> <offset>... CONNECTIVITY <type> <point index>...
+func (dec *decoder) parseCells(ds *VTKDataset) error {
+
+	count, err := dec.int()
+	if err != nil {
+		return err
+	}
+	size, err := dec.int()
+	if err != nil {
+		return err
+	}
+	tok, err := dec.next()
+	if err != nil {
+		return io.ErrUnexpectedEOF
+	}
+	dec.back()
+
+	var cells [][]int
+	if tok == "OFFSETS" {
+		var offsets, conn []int
+		offsets, err = dec.indexArray("OFFSETS", count)
+		if err == nil {
+			conn, err = dec.indexArray("CONNECTIVITY", size)
+		}
+		if err != nil {
+			return err
+		}
+		for i := 0; i+1 < len(offsets); i++ {
+			if offsets[i] < 0 || offsets[i] > offsets[i+1] || offsets[i+1] > len(conn) {
+				return errors.New("invalid VTK cell offsets")
+			}
+			cells = append(cells, conn[offsets[i]:offsets[i+1]])
+		}
+	} else {
+		cells = make([][]int, count)
+		read := 0
+		for i := range cells {
+			n, err := dec.int()
+			if err != nil {
+				return err
+			}
+			read += n + 1
+			if n < 0 || read > size {
+				return errors.New("invalid VTK cells size")
+			}
+			cells[i] = make([]int, n)
+			for j := range cells[i] {
+				cells[i][j], err = dec.int()
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for _, cell := range cells {
+		for _, p := range cell {
+			if p < 0 || p >= len(ds.Points) {
+				return fmt.Errorf("invalid VTK point index: %d", p)
+			}
+		}
+	}
+	ds.Cells = cells
+	return nil
+}
+
+// indexArray parses an array of indices of the version 5 cells:
+// <name> <type> <index>...
+func (dec *decoder) indexArray(name string, count int) ([]int, error) {
+
+	if tok, _ := dec.next(); tok != name {
+		return nil, fmt.Errorf("VTK %s expected", name)
+	}
+	if _, err := dec.next(); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	values := make([]int, count)
+	for i := range values {
+		v, err := dec.int()
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// parseCellTypes parses the types of the cells of an unstructured grid:
+// CELL_TYPES <count> <type>...
+func (dec *decoder) parseCellTypes(ds *VTKDataset) error {
+
+	count, err := dec.int()
+	if err != nil {
+		return err
+	}
+	ds.CellTypes = make([]int, count)
+	for i := range ds.CellTypes {
+		ds.CellTypes[i], err = dec.int()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseAttribute parses an attribute of the points or cells with the specified keyword
+// and stores its values in the specified map.
+func (dec *decoder) parseAttribute(keyword string, data map[string][]float32, items int) error {
+
+	name, err := dec.next()
+	if err != nil {
+		return io.ErrUnexpectedEOF
+	}
+	comps := 0
+	switch keyword {
+	case "SCALARS":
+		// SCALARS <name> <type> [<components>]
+		// LOOKUP_TABLE <table name>
+		if _, err = dec.next(); err != nil {
+			return io.ErrUnexpectedEOF
+		}
+		comps = 1
+		var tok string
+		tok, err = dec.next()
+		if n, aerr := strconv.Atoi(tok); err == nil && aerr == nil {
+			comps = n
+			tok, err = dec.next()
+		}
+		if err != nil {
+			return io.ErrUnexpectedEOF
+		}
+		if tok == "LOOKUP_TABLE" {
+			_, err = dec.next()
+		} else {
+			dec.back()
+		}
+	case "COLOR_SCALARS":
+		// COLOR_SCALARS <name> <components>
+		comps, err = dec.int()
+	case "VECTORS", "NORMALS":
+		// VECTORS <name> <type>
+		comps = 3
+		_, err = dec.next()
+	case "TENSORS":
+		// TENSORS <name> <type>
+		comps = 9
+		_, err = dec.next()
+	case "TEXTURE_COORDINATES":
+		// TEXTURE_COORDINATES <name> <dimension> <type>
+		comps, err = dec.int()
+		if err == nil {
+			_, err = dec.next()
+		}
+	case "FIELD":
+		// FIELD <name> <arrays count>
+		// <array name> <components> <tuples> <type> <values>...
+		var arrays int
+		arrays, err = dec.int()
+		for i := 0; i < arrays && err == nil; i++ {
+			var array string
+			var arrComps, tuples int
+			array, err = dec.next()
+			if err != nil {
+				return io.ErrUnexpectedEOF
+			}
+			if arrComps, err = dec.int(); err != nil {
+				return err
+			}
+			if tuples, err = dec.int(); err != nil {
+				return err
+			}
+			if _, err = dec.next(); err != nil {
+				return io.ErrUnexpectedEOF
+			}
+			data[array], err = dec.floats(arrComps * tuples)
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	data[name], err = dec.floats(comps * items)
+	return err
+}
+
+// skipSection skips the tokens until the next section keyword.
+func (dec *decoder) skipSection() error {
+
+	for {
+		tok, err := dec.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if keywords[strings.ToUpper(tok)] {
+			dec.back()
+			return nil
+		}
+	}
+}
+
+// structuredCells generates the cells of a structured grid from its dimensions:
+// hexahedra for the 3D grids, quads for the 2D grids and lines for the 1D grids.
+func (ds *VTKDataset) structuredCells() error {
+
+	nx, ny, nz := ds.Dimensions[0], ds.Dimensions[1], ds.Dimensions[2]
+	if nx < 1 || ny < 1 || nz < 1 || nx*ny*nz != len(ds.Points) {
+		return fmt.Errorf("VTK dimensions %v for %d points", ds.Dimensions, len(ds.Points))
+	}
+
+	// Dimensions with more than one point and their strides in the points
+	var dims, strides []int
+	stride := 1
+	for _, n := range ds.Dimensions {
+		if n > 1 {
+			dims = append(dims, n)
+			strides = append(strides, stride)
+		}
+		stride *= n
+	}
+	ds.Cells = nil
+	ds.CellTypes = nil
+	switch len(dims) {
+	case 1:
+		for i := 0; i < dims[0]-1; i++ {
+			p := i * strides[0]
+			ds.addCell(CellLine, p, p+strides[0])
+		}
+	case 2:
+		for j := 0; j < dims[1]-1; j++ {
+			for i := 0; i < dims[0]-1; i++ {
+				p := i*strides[0] + j*strides[1]
+				ds.addCell(CellQuad, p, p+strides[0], p+strides[0]+strides[1], p+strides[1])
+			}
+		}
+	case 3:
+		sx, sy, sz := strides[0], strides[1], strides[2]
+		for k := 0; k < nz-1; k++ {
+			for j := 0; j < ny-1; j++ {
+				for i := 0; i < nx-1; i++ {
+					p := i*sx + j*sy + k*sz
+					ds.addCell(CellHexahedron, p, p+sx, p+sx+sy, p+sy, p+sz, p+sx+sz, p+sx+sy+sz, p+sy+sz)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// addCell appends a cell of the specified type and points.
+func (ds *VTKDataset) addCell(ctype int, points ...int) {
+
+	ds.Cells = append(ds.Cells, points)
+	ds.CellTypes = append(ds.CellTypes, ctype)
+}
+
+// hexFaces are the point indices of the faces of a hexahedron, counterclockwise seen from outside.
+var hexFaces = [6][4]int{{0, 3, 2, 1}, {4, 5, 6, 7}, {0, 1, 5, 4}, {1, 2, 6, 5}, {2, 3, 7, 6}, {3, 0, 4, 7}}
+
+// tetraFaces are the point indices of the faces of a tetrahedron, counterclockwise seen from outside.
+var tetraFaces = [4][3]int{{0, 2, 1}, {0, 1, 3}, {1, 2, 3}, {2, 0, 3}}
+
+// NewGeometry creates and returns an indexed geometry with the points of the dataset as vertex positions
+// and the triangles of its surface cells: triangles, triangle strips, polygons, pixels and quads.
+// The faces of the volume cells (tetrahedra, voxels and hexahedra) which are not shared by two cells are
+// added as the boundary surface. Returns an error if the dataset has no surface or volume cells.
+func (ds *VTKDataset) NewGeometry() (*geometry.Geometry, error) {
+
+	indices := math32.NewArrayU32(0, 0)
+	fan := func(points ...int) {
+		for i := 1; i < len(points)-1; i++ {
+			indices.Append(uint32(points[0]), uint32(points[i]), uint32(points[i+1]))
+		}
+	}
+
+	// Counts the faces of the volume cells, by sorted points, to find the boundary faces
+	var volumeFaces [][]int
+	faceCount := make(map[[4]int]int)
+	faceKey := func(face []int) [4]int {
+		key := [4]int{-1, -1, -1, -1}
+		copy(key[:], face)
+		for i := 1; i < len(face); i++ {
+			for j := i; j > 0 && key[j] < key[j-1]; j-- {
+				key[j], key[j-1] = key[j-1], key[j]
+			}
+		}
+		return key
+	}
+	addVolumeFace := func(face ...int) {
+		volumeFaces = append(volumeFaces, face)
+		faceCount[faceKey(face)]++
+	}
+
+	for i, cell := range ds.Cells {
+		switch ds.CellTypes[i] {
+		case CellTriangle, CellPolygon, CellQuad:
+			fan(cell...)
+		case CellPixel:
+			if len(cell) == 4 {
+				fan(cell[0], cell[1], cell[3], cell[2])
+			}
+		case CellTriangleStrip:
+			for j := 0; j+2 < len(cell); j++ {
+				if j%2 == 0 {
+					fan(cell[j], cell[j+1], cell[j+2])
+				} else {
+					fan(cell[j+1], cell[j], cell[j+2])
+				}
+			}
+		case CellTetra:
+			if len(cell) == 4 {
+				for _, f := range tetraFaces {
+					addVolumeFace(cell[f[0]], cell[f[1]], cell[f[2]])
+				}
+			}
+		case CellHexahedron, CellVoxel:
+			if len(cell) == 8 {
+				c := cell
+				if ds.CellTypes[i] == CellVoxel {
+					// Voxel points are ordered along x, y and z
+					c = []int{cell[0], cell[1], cell[3], cell[2], cell[4], cell[5], cell[7], cell[6]}
+				}
+				for _, f := range hexFaces {
+					addVolumeFace(c[f[0]], c[f[1]], c[f[2]], c[f[3]])
+				}
+			}
+		}
+	}
+	for _, face := range volumeFaces {
+		if faceCount[faceKey(face)] == 1 {
+			fan(face...)
+		}
+	}
+	if indices.Size() == 0 {
+		return nil, errors.New("VTK dataset without surface or volume cells")
+	}
+
+	positions := math32.NewArrayF32(0, 3*len(ds.Points))
+	for i := range ds.Points {
+		positions.AppendVector3(&ds.Points[i])
+	}
+	geom := geometry.NewGeometry()
+	geom.SetIndices(indices)
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	return geom, nil
+}
@@ -229,27 +229,57 @@ func (mat *Material) SetDepthMask(state bool) {
 	mat.depthMask = state
 }
 
+// DepthMask returns whether writing into the depth buffer is enabled.
+func (mat *Material) DepthMask() bool {
+
+	return mat.depthMask
+}
+
 func (mat *Material) SetDepthTest(state bool) {
 
 	mat.depthTest = state
 }
 
+// DepthTest returns whether the depth buffer test is enabled.
+func (mat *Material) DepthTest() bool {
+
+	return mat.depthTest
+}
+
 func (mat *Material) SetBlending(blending Blending) {
 
 	mat.blending = blending
 }
 
+// Blending returns the current blending mode.
+func (mat *Material) Blending() Blending {
+
+	return mat.blending
+}
+
 func (mat *Material) SetLineWidth(width float32) {
 
 	mat.lineWidth = width
 }
 
+// LineWidth returns the current line width.
+func (mat *Material) LineWidth() float32 {
+
+	return mat.lineWidth
+}
+
 func (mat *Material) SetPolygonOffset(factor, units float32) {
 
 	mat.polyOffsetFactor = factor
 	mat.polyOffsetUnits = units
 }
 
+// PolygonOffset returns the current polygon offset factor and units.
+func (mat *Material) PolygonOffset() (factor, units float32) {
+
+	return mat.polyOffsetFactor, mat.polyOffsetUnits
+}
+
 // RenderSetup is called by the renderer before drawing objects with this material.
 func (mat *Material) RenderSetup(gs *gls.GLS) {
 
@@ -333,6 +363,21 @@ func (mat *Material) AddTexture(tex *texture.Texture2D) {
 	mat.textures = append(mat.textures, tex)
 }
 
+// SetTexture sets the specified Texture2d as the texture of this material for the
+// shader sampler with the specified name, replacing the texture previously set for it.
+func (mat *Material) SetTexture(sampler string, tex *texture.Texture2D) {
+
+	_, info := tex.GetUniformNames()
+	tex.SetUniformNames(sampler, info)
+	for pos, curr := range mat.textures {
+		if currSampler, _ := curr.GetUniformNames(); currSampler == sampler {
+			mat.textures[pos] = tex
+			return
+		}
+	}
+	mat.AddTexture(tex)
+}
+
 // RemoveTexture removes the specified Texture2d from the material
 func (mat *Material) RemoveTexture(tex *texture.Texture2D) {
 
@@ -363,3 +408,9 @@ func (mat *Material) TextureCount() int {
 
 	return len(mat.textures)
 }
+
+// Textures returns the textures of the material, which must not be modified.
+func (mat *Material) Textures() []*texture.Texture2D {
+
+	return mat.textures
+}
@@ -22,10 +22,12 @@ type Physical struct {
 	emissiveTex          *texture.Texture2D // Optional emissive texture
 	uni                  gls.Uniform        // Uniform location cache
 	udata                struct {           // Combined uniform data
-		baseColorFactor math32.Color4
-		emissiveFactor  math32.Color4
-		metallicFactor  float32
-		roughnessFactor float32
+		baseColorFactor   math32.Color4
+		emissiveFactor    math32.Color4
+		metallicFactor    float32
+		roughnessFactor   float32
+		occlusionStrength float32
+		alphaCutoff       float32
 	}
 }
 
@@ -45,9 +47,35 @@ func NewPhysical() *Physical {
 	m.udata.emissiveFactor = math32.Color4{0, 0, 0, 1}
 	m.udata.metallicFactor = 1
 	m.udata.roughnessFactor = 1
+	m.udata.occlusionStrength = 1
 	return m
 }
 
+// Clone creates and returns a pointer to a copy of this material with the same parameters,
+// render states and textures. The textures are shared, not copied: their reference counts
+// are incremented.
+func (m *Physical) Clone() *Physical {
+
+	c := new(Physical)
+	*c = *m
+	c.refcount = 1
+	c.ShaderDefines = *gls.NewShaderDefines()
+	c.ShaderDefines.Add(&m.ShaderDefines)
+	c.textures = make([]*texture.Texture2D, len(m.textures))
+	for i, tex := range m.textures {
+		c.textures[i] = tex.Incref()
+	}
+	c.uni.Init("Material")
+	return c
+}
+
+// IsTransparent returns if the material needs to be rendered as transparent:
+// when its base color is not opaque or when it has an alpha cutoff.
+func (m *Physical) IsTransparent() bool {
+
+	return m.udata.baseColorFactor.A < 1 || m.udata.alphaCutoff > 0
+}
+
 // SetBaseColorFactor sets this material base color.
 // Its default value is {1,1,1,1}.
 // Returns pointer to this updated material.
@@ -57,6 +85,12 @@ func (m *Physical) SetBaseColorFactor(c *math32.Color4) *Physical {
 	return m
 }
 
+// BaseColorFactor returns this material base color.
+func (m *Physical) BaseColorFactor() math32.Color4 {
+
+	return m.udata.baseColorFactor
+}
+
 // SetMetallicFactor sets this material metallic factor.
 // Its default value is 1.
 // Returns pointer to this updated material.
@@ -66,6 +100,12 @@ func (m *Physical) SetMetallicFactor(v float32) *Physical {
 	return m
 }
 
+// MetallicFactor returns this material metallic factor.
+func (m *Physical) MetallicFactor() float32 {
+
+	return m.udata.metallicFactor
+}
+
 // SetRoughnessFactor sets this material roughness factor.
 // Its default value is 1.
 // Returns pointer to this updated material.
@@ -75,6 +115,12 @@ func (m *Physical) SetRoughnessFactor(v float32) *Physical {
 	return m
 }
 
+// RoughnessFactor returns this material roughness factor.
+func (m *Physical) RoughnessFactor() float32 {
+
+	return m.udata.roughnessFactor
+}
+
 // SetEmissiveFactor sets the emissive color of the material.
 // Its default is {1, 1, 1}.
 // Returns pointer to this updated material.
@@ -86,6 +132,43 @@ func (m *Physical) SetEmissiveFactor(c *math32.Color) *Physical {
 	return m
 }
 
+// EmissiveFactor returns the emissive color of the material.
+func (m *Physical) EmissiveFactor() math32.Color {
+
+	return math32.Color{R: m.udata.emissiveFactor.R, G: m.udata.emissiveFactor.G, B: m.udata.emissiveFactor.B}
+}
+
+// SetOcclusionStrength sets the strength of the occlusion map, from 0 for no occlusion to 1 for full occlusion.
+// Its default value is 1.
+// Returns pointer to this updated material.
+func (m *Physical) SetOcclusionStrength(v float32) *Physical {
+
+	m.udata.occlusionStrength = v
+	return m
+}
+
+// OcclusionStrength returns the strength of the occlusion map.
+func (m *Physical) OcclusionStrength() float32 {
+
+	return m.udata.occlusionStrength
+}
+
+// SetAlphaCutoff sets the alpha value below which the fragments are discarded,
+// for the alpha masks of foliage or fences. Zero disables the test.
+// Its default value is 0.
+// Returns pointer to this updated material.
+func (m *Physical) SetAlphaCutoff(v float32) *Physical {
+
+	m.udata.alphaCutoff = v
+	return m
+}
+
+// AlphaCutoff returns the alpha value below which the fragments are discarded.
+func (m *Physical) AlphaCutoff() float32 {
+
+	return m.udata.alphaCutoff
+}
+
 // SetBaseColorMap sets this material optional texture base color.
 // Returns pointer to this updated material.
 func (m *Physical) SetBaseColorMap(tex *texture.Texture2D) *Physical {
@@ -102,6 +185,12 @@ func (m *Physical) SetBaseColorMap(tex *texture.Texture2D) *Physical {
 	return m
 }
 
+// BaseColorMap returns this material optional base color texture, or nil.
+func (m *Physical) BaseColorMap() *texture.Texture2D {
+
+	return m.baseColorTex
+}
+
 // SetMetallicRoughnessMap sets this material optional metallic-roughness texture.
 // Returns pointer to this updated material.
 func (m *Physical) SetMetallicRoughnessMap(tex *texture.Texture2D) *Physical {
@@ -118,6 +207,12 @@ func (m *Physical) SetMetallicRoughnessMap(tex *texture.Texture2D) *Physical {
 	return m
 }
 
+// MetallicRoughnessMap returns this material optional metallic-roughness texture, or nil.
+func (m *Physical) MetallicRoughnessMap() *texture.Texture2D {
+
+	return m.metallicRoughnessTex
+}
+
 // SetNormalMap sets this material optional normal texture.
 // Returns pointer to this updated material.
 // TODO add SetNormalMap (and SetSpecularMap) to StandardMaterial.
@@ -135,6 +230,12 @@ func (m *Physical) SetNormalMap(tex *texture.Texture2D) *Physical {
 	return m
 }
 
+// NormalMap returns this material optional normal texture, or nil.
+func (m *Physical) NormalMap() *texture.Texture2D {
+
+	return m.normalTex
+}
+
 // SetOcclusionMap sets this material optional occlusion texture.
 // Returns pointer to this updated material.
 func (m *Physical) SetOcclusionMap(tex *texture.Texture2D) *Physical {
@@ -151,6 +252,12 @@ func (m *Physical) SetOcclusionMap(tex *texture.Texture2D) *Physical {
 	return m
 }
 
+// OcclusionMap returns this material optional occlusion texture, or nil.
+func (m *Physical) OcclusionMap() *texture.Texture2D {
+
+	return m.occlusionTex
+}
+
 // SetEmissiveMap sets this material optional emissive texture.
 // Returns pointer to this updated material.
 func (m *Physical) SetEmissiveMap(tex *texture.Texture2D) *Physical {
@@ -167,6 +274,12 @@ func (m *Physical) SetEmissiveMap(tex *texture.Texture2D) *Physical {
 	return m
 }
 
+// EmissiveMap returns this material optional emissive texture, or nil.
+func (m *Physical) EmissiveMap() *texture.Texture2D {
+
+	return m.emissiveTex
+}
+
 // RenderSetup transfer this material uniforms and textures to the shader
 func (m *Physical) RenderSetup(gl *gls.GLS) {
 
@@ -39,8 +39,20 @@ func (pm *Point) SetSize(size float32) {
 	pm.udata.psize = size
 }
 
+// Size returns the point size.
+func (pm *Point) Size() float32 {
+
+	return pm.udata.psize
+}
+
 // SetRotationZ sets the point rotation around the Z axis.
 func (pm *Point) SetRotationZ(rot float32) {
 
 	pm.udata.protationZ = rot
 }
+
+// RotationZ returns the point rotation around the Z axis.
+func (pm *Point) RotationZ() float32 {
+
+	return pm.udata.protationZ
+}
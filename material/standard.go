@@ -76,6 +76,12 @@ func (ms *Standard) SetColor(color *math32.Color) {
 	ms.udata.ambient = *color
 }
 
+// Color returns the material diffuse color reflectivity.
+func (ms *Standard) Color() math32.Color {
+
+	return ms.udata.diffuse
+}
+
 // SetEmissiveColor sets the material emissive color
 // The default is {0,0,0}
 func (ms *Standard) SetEmissiveColor(color *math32.Color) {
@@ -96,18 +102,36 @@ func (ms *Standard) SetSpecularColor(color *math32.Color) {
 	ms.udata.specular = *color
 }
 
+// SpecularColor returns the material specular color reflectivity.
+func (ms *Standard) SpecularColor() math32.Color {
+
+	return ms.udata.specular
+}
+
 // SetShininess sets the specular highlight factor. Default is 30.
 func (ms *Standard) SetShininess(shininess float32) {
 
 	ms.udata.shininess = shininess
 }
 
+// Shininess returns the specular highlight factor.
+func (ms *Standard) Shininess() float32 {
+
+	return ms.udata.shininess
+}
+
 // SetOpacity sets the material opacity (alpha). Default is 1.0.
 func (ms *Standard) SetOpacity(opacity float32) {
 
 	ms.udata.opacity = opacity
 }
 
+// Opacity returns the material opacity (alpha).
+func (ms *Standard) Opacity() float32 {
+
+	return ms.udata.opacity
+}
+
 // RenderSetup is called by the engine before drawing the object
 // which uses this material
 func (ms *Standard) RenderSetup(gs *gls.GLS) {
@@ -0,0 +1,76 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"sync/atomic"
+)
+
+// AtomicVector3 is a Vector3 which can be read and written by several goroutines concurrently.
+// The three components do not fit in a single atomic word, so each stored value is an immutable
+// copy swapped atomically: Store, CompareAndSwap and Add allocate. The zero value is the zero vector.
+type AtomicVector3 struct {
+	p atomic.Pointer[Vector3]
+}
+
+// NewAtomicVector3 creates and returns a pointer to a new AtomicVector3 with the specified value.
+func NewAtomicVector3(v *Vector3) *AtomicVector3 {
+
+	av := new(AtomicVector3)
+	av.Store(v)
+	return av
+}
+
+// Load returns the value of this vector.
+func (av *AtomicVector3) Load() Vector3 {
+
+	if p := av.p.Load(); p != nil {
+		return *p
+	}
+	return Vector3{}
+}
+
+// Store sets the value of this vector.
+func (av *AtomicVector3) Store(v *Vector3) {
+
+	c := *v
+	av.p.Store(&c)
+}
+
+// CompareAndSwap sets the value of this vector to desired if it is equal to expected.
+// Returns if the value was set.
+func (av *AtomicVector3) CompareAndSwap(expected, desired *Vector3) bool {
+
+	c := *desired
+	for {
+		p := av.p.Load()
+		if p == nil {
+			if *expected != (Vector3{}) {
+				return false
+			}
+		} else if *p != *expected {
+			return false
+		}
+		if av.p.CompareAndSwap(p, &c) {
+			return true
+		}
+	}
+}
+
+// Add adds the specified delta to this vector and returns the new value.
+func (av *AtomicVector3) Add(delta *Vector3) Vector3 {
+
+	for {
+		p := av.p.Load()
+		var sum Vector3
+		if p != nil {
+			sum = *p
+		}
+		sum.Add(delta)
+		if av.p.CompareAndSwap(p, &sum) {
+			return sum
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"sync"
+	"testing"
+)
+
+// Test concurrent loads, stores, compare and swaps and additions, which must not tear the vectors
+func TestAtomicVector3Race(t *testing.T) {
+
+	var av AtomicVector3
+	if !av.CompareAndSwap(&Vector3{}, &Vector3{1, 1, 1}) || av.CompareAndSwap(&Vector3{}, &Vector3{2, 2, 2}) {
+		t.Fatal("CompareAndSwap of the zero value failed")
+	}
+
+	// Additions from several goroutines are not lost
+	const goroutines = 8
+	const iterations = 1000
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				av.Add(&Vector3{1, 2, 3})
+				if v := av.Load(); v.Y != 2*v.X-1 || v.Z != 3*v.X-2 {
+					t.Error("torn vector", v)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if v := av.Load(); v != (Vector3{1 + goroutines*iterations, 1 + 2*goroutines*iterations, 1 + 3*goroutines*iterations}) {
+		t.Fatal("lost additions:", v)
+	}
+
+	// Stores of vectors with equal components are read whole
+	av.Store(&Vector3{})
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				if g%2 == 0 {
+					f := float32(g*iterations + i)
+					av.Store(&Vector3{f, f, f})
+				} else if v := av.Load(); v.X != v.Y || v.Y != v.Z {
+					t.Error("torn vector", v)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+// atomicVector3Sink keeps the results of the benchmarks.
+var atomicVector3Sink Vector3
+
+// Benchmark the loads of an AtomicVector3, to compare with BenchmarkVector3Load
+func BenchmarkAtomicVector3Load(b *testing.B) {
+
+	av := NewAtomicVector3(&Vector3{1, 2, 3})
+	var sum Vector3
+	for i := 0; i < b.N; i++ {
+		v := av.Load()
+		sum.Add(&v)
+	}
+	atomicVector3Sink = sum
+}
+
+// Benchmark the non atomic reads of a Vector3
+func BenchmarkVector3Load(b *testing.B) {
+
+	p := &Vector3{1, 2, 3}
+	var sum Vector3
+	for i := 0; i < b.N; i++ {
+		v := *p
+		sum.Add(&v)
+	}
+	atomicVector3Sink = sum
+}
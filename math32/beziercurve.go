@@ -0,0 +1,58 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+// BezierCurve3 is a 3D Bezier curve of any degree defined by its control points.
+// If weights are specified the curve is a rational Bezier curve, which can represent conic sections exactly.
+type BezierCurve3 struct {
+	ControlPoints []Vector3
+	Weights       []float32 // weight of each control point or nil for a polynomial curve
+}
+
+// NewBezierCurve3 creates and returns a pointer to a new polynomial BezierCurve3
+// with a copy of the specified control points.
+func NewBezierCurve3(controlPoints []Vector3) *BezierCurve3 {
+
+	if len(controlPoints) < 2 {
+		panic("NewBezierCurve3: at least 2 control points are required")
+	}
+	c := new(BezierCurve3)
+	c.ControlPoints = make([]Vector3, len(controlPoints))
+	copy(c.ControlPoints, controlPoints)
+	return c
+}
+
+// Degree returns the degree of this curve, which is the number of control points minus one.
+func (c *BezierCurve3) Degree() int {
+
+	return len(c.ControlPoints) - 1
+}
+
+// PointAt returns the point of this curve at the specified parameter in [0,1]
+// using the de Casteljau algorithm in homogeneous coordinates.
+func (c *BezierCurve3) PointAt(t float32) Vector3 {
+
+	t = Clamp(t, 0, 1)
+	pts := make([]Vector4, len(c.ControlPoints))
+	for i := range c.ControlPoints {
+		w := float32(1)
+		if c.Weights != nil {
+			w = c.Weights[i]
+		}
+		cp := &c.ControlPoints[i]
+		pts[i] = Vector4{cp.X * w, cp.Y * w, cp.Z * w, w}
+	}
+	for n := len(pts) - 1; n > 0; n-- {
+		for i := 0; i < n; i++ {
+			a, b := &pts[i], &pts[i+1]
+			a.X += (b.X - a.X) * t
+			a.Y += (b.Y - a.Y) * t
+			a.Z += (b.Z - a.Z) * t
+			a.W += (b.W - a.W) * t
+		}
+	}
+	p := &pts[0]
+	return Vector3{p.X / p.W, p.Y / p.W, p.Z / p.W}
+}
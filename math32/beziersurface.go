@@ -0,0 +1,100 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+// CubicBezierSurface represents a bicubic Bezier patch defined by a 4x4 grid of control points.
+// The control point [i][j] is associated with the i-th Bernstein polynomial in the
+// u parameter and the j-th Bernstein polynomial in the v parameter.
+type CubicBezierSurface struct {
+	ControlPoints [4][4]Vector3
+}
+
+// NewCubicBezierSurface creates and returns a pointer to a new CubicBezierSurface
+// with the specified control points.
+func NewCubicBezierSurface(controlPoints [4][4]Vector3) *CubicBezierSurface {
+
+	s := new(CubicBezierSurface)
+	s.ControlPoints = controlPoints
+	return s
+}
+
+// bernstein3 stores in b the four cubic Bernstein polynomials evaluated at t.
+func bernstein3(t float32, b *[4]float32) {
+
+	it := 1 - t
+	b[0] = it * it * it
+	b[1] = 3 * t * it * it
+	b[2] = 3 * t * t * it
+	b[3] = t * t * t
+}
+
+// bernstein3Deriv stores in b the derivatives of the four cubic Bernstein polynomials evaluated at t.
+func bernstein3Deriv(t float32, b *[4]float32) {
+
+	it := 1 - t
+	b[0] = -3 * it * it
+	b[1] = 3*it*it - 6*t*it
+	b[2] = 6*t*it - 3*t*t
+	b[3] = 3 * t * t
+}
+
+// combine returns the sum of the control points weighted by the tensor product of bu and bv.
+func (s *CubicBezierSurface) combine(bu, bv *[4]float32) Vector3 {
+
+	var p Vector3
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			w := bu[i] * bv[j]
+			cp := &s.ControlPoints[i][j]
+			p.X += cp.X * w
+			p.Y += cp.Y * w
+			p.Z += cp.Z * w
+		}
+	}
+	return p
+}
+
+// PointAt calculates the point of this surface at the specified parameters in [0,1]
+// and stores its pointer to optionalTarget, if not nil, and also returns it.
+func (s *CubicBezierSurface) PointAt(u, v float32, optionalTarget *Vector3) *Vector3 {
+
+	var result *Vector3
+	if optionalTarget == nil {
+		result = NewVector3(0, 0, 0)
+	} else {
+		result = optionalTarget
+	}
+	var bu, bv [4]float32
+	bernstein3(u, &bu)
+	bernstein3(v, &bv)
+	*result = s.combine(&bu, &bv)
+	return result
+}
+
+// Derivatives returns the partial derivatives of this surface relative to u and v
+// at the specified parameters.
+func (s *CubicBezierSurface) Derivatives(u, v float32) (du, dv Vector3) {
+
+	var bu, bv, dbu, dbv [4]float32
+	bernstein3(u, &bu)
+	bernstein3(v, &bv)
+	bernstein3Deriv(u, &dbu)
+	bernstein3Deriv(v, &dbv)
+	return s.combine(&dbu, &bv), s.combine(&bu, &dbv)
+}
+
+// NormalAt returns the unit normal of this surface at the specified parameters,
+// the normalized cross product of the partial derivatives relative to u and v.
+// Returns the zero vector if the surface is degenerate at this point.
+func (s *CubicBezierSurface) NormalAt(u, v float32) Vector3 {
+
+	du, dv := s.Derivatives(u, v)
+	var n Vector3
+	n.CrossVectors(&du, &dv)
+	if n.LengthSq() > 0 {
+		n.Normalize()
+	}
+	return n
+}
@@ -0,0 +1,85 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"testing"
+)
+
+// newTestBezierSurface returns a surface with irregular control points.
+func newTestBezierSurface() *CubicBezierSurface {
+
+	var cp [4][4]Vector3
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			cp[i][j] = Vector3{X: float32(i) * 0.37, Y: float32(j) * 1.3, Z: float32(i*j%3) * 0.7}
+		}
+	}
+	return NewCubicBezierSurface(cp)
+}
+
+// Test that the corners of the surface are exactly its corner control points
+func TestCubicBezierSurfaceCorners(t *testing.T) {
+
+	s := newTestBezierSurface()
+	for _, c := range [][2]int{{0, 0}, {0, 1}, {1, 0}, {1, 1}} {
+		p := s.PointAt(float32(c[0]), float32(c[1]), nil)
+		if *p != s.ControlPoints[3*c[0]][3*c[1]] {
+			t.Error("PointAt", c, "is", *p, "instead of", s.ControlPoints[3*c[0]][3*c[1]])
+		}
+	}
+}
+
+// Test the points, derivatives and normals of a planar patch with uniformly spaced control points
+func TestCubicBezierSurfacePlane(t *testing.T) {
+
+	// The patch is the bilinear map (u, v) -> (2u, 3v, 1)
+	var cp [4][4]Vector3
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			cp[i][j] = Vector3{X: 2 * float32(i) / 3, Y: float32(j), Z: 1}
+		}
+	}
+	s := NewCubicBezierSurface(cp)
+	var p Vector3
+	for _, uv := range [][2]float32{{0.25, 0.5}, {0.5, 0.5}, {0.9, 0.1}} {
+		s.PointAt(uv[0], uv[1], &p)
+		expected := Vector3{X: 2 * uv[0], Y: 3 * uv[1], Z: 1}
+		if p.DistanceTo(&expected) > 1e-5 {
+			t.Error("PointAt", uv, "is", p, "instead of", expected)
+		}
+		du, dv := s.Derivatives(uv[0], uv[1])
+		if du.DistanceTo(&Vector3{X: 2}) > 1e-5 || dv.DistanceTo(&Vector3{Y: 3}) > 1e-5 {
+			t.Error("Derivatives", uv, "are", du, dv, "instead of (2,0,0) and (0,3,0)")
+		}
+		if n := s.NormalAt(uv[0], uv[1]); n.DistanceTo(&Vector3{Z: 1}) > 1e-5 {
+			t.Error("NormalAt", uv, "is", n, "instead of (0,0,1)")
+		}
+	}
+}
+
+// Test that the derivatives are the limits of the differences of the points
+func TestCubicBezierSurfaceDerivatives(t *testing.T) {
+
+	s := newTestBezierSurface()
+	const h = 1e-3
+	for _, uv := range [][2]float32{{0.2, 0.3}, {0.5, 0.5}, {0.7, 0.9}} {
+		u, v := uv[0], uv[1]
+		du, dv := s.Derivatives(u, v)
+		var p0, p1, fd Vector3
+		s.PointAt(u-h, v, &p0)
+		s.PointAt(u+h, v, &p1)
+		fd.SubVectors(&p1, &p0).DivideScalar(2 * h)
+		if fd.DistanceTo(&du) > 1e-2 {
+			t.Error("derivative relative to u", du, "instead of", fd, "at", uv)
+		}
+		s.PointAt(u, v-h, &p0)
+		s.PointAt(u, v+h, &p1)
+		fd.SubVectors(&p1, &p0).DivideScalar(2 * h)
+		if fd.DistanceTo(&dv) > 1e-2 {
+			t.Error("derivative relative to v", dv, "instead of", fd, "at", uv)
+		}
+	}
+}
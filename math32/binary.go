@@ -0,0 +1,79 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// putFloats encodes the specified components as little-endian float32 values.
+func putFloats(values ...float32) []byte {
+
+	data := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(v))
+	}
+	return data
+}
+
+// getFloats decodes the specified components from little-endian float32 values.
+func getFloats(data []byte, values ...*float32) error {
+
+	if len(data) != 4*len(values) {
+		return fmt.Errorf("invalid data length:%d", len(data))
+	}
+	for i, v := range values {
+		*v = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+// Returns the X, Y and Z components of this vector as 12 bytes of little-endian float32 values.
+func (v *Vector3) MarshalBinary() ([]byte, error) {
+
+	return putFloats(v.X, v.Y, v.Z), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+// Sets this vector from the data encoded by MarshalBinary.
+func (v *Vector3) UnmarshalBinary(data []byte) error {
+
+	return getFloats(data, &v.X, &v.Y, &v.Z)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+// Returns the X, Y, Z and W components of this quaternion as 16 bytes of little-endian float32 values.
+func (q *Quaternion) MarshalBinary() ([]byte, error) {
+
+	return putFloats(q.X, q.Y, q.Z, q.W), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+// Sets this quaternion from the data encoded by MarshalBinary.
+func (q *Quaternion) UnmarshalBinary(data []byte) error {
+
+	return getFloats(data, &q.X, &q.Y, &q.Z, &q.W)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+// Returns the elements of this matrix in column order as 64 bytes of little-endian float32 values.
+func (m *Matrix4) MarshalBinary() ([]byte, error) {
+
+	return putFloats(m[:]...), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+// Sets this matrix from the data encoded by MarshalBinary.
+func (m *Matrix4) UnmarshalBinary(data []byte) error {
+
+	values := make([]*float32, len(m))
+	for i := range m {
+		values[i] = &m[i]
+	}
+	return getFloats(data, values...)
+}
@@ -0,0 +1,161 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"math"
+)
+
+// blurFFTSigma is the sigma above which GaussianBlur3D convolves using FFT.
+const blurFFTSigma = 5
+
+// GaussianBlur3D applies a 3D Gaussian filter with the specified standard deviation
+// to a scalar field stored as a dense array of width*height*depth values in
+// X major order (index = x + y*width + z*width*height) and returns a new array with the result.
+// The filter is separable and is applied as three 1D passes along X, Y and Z with
+// a kernel half-width of ceil(3*sigma). Values outside the field are clamped to the nearest border value.
+// For sigma greater than 5 each 1D convolution is computed using FFT.
+func GaussianBlur3D(data []float32, width, height, depth int, sigma float32) []float32 {
+
+	size := width * height * depth
+	if len(data) < size {
+		panic("GaussianBlur3D: data length smaller than width*height*depth")
+	}
+	out := make([]float32, size)
+	copy(out, data)
+	if sigma <= 0 || size == 0 {
+		return out
+	}
+
+	kernel := gaussianKernel(sigma)
+	useFFT := sigma > blurFFTSigma
+
+	dims := [3]int{width, height, depth}
+	strides := [3]int{1, width, width * height}
+	for axis := 0; axis < 3; axis++ {
+		n := dims[axis]
+		if n < 2 {
+			continue
+		}
+		stride := strides[axis]
+		// Dimensions of the plane of lines perpendicular to the current axis
+		var o1, o2, s1, s2 int
+		switch axis {
+		case 0:
+			o1, o2, s1, s2 = height, depth, width, width*height
+		case 1:
+			o1, o2, s1, s2 = width, depth, 1, width*height
+		case 2:
+			o1, o2, s1, s2 = width, height, 1, width
+		}
+		var conv *fftConvolver
+		if useFFT {
+			conv = newFFTConvolver(kernel, n)
+		}
+		line := make([]float32, n)
+		res := make([]float32, n)
+		for j := 0; j < o2; j++ {
+			for i := 0; i < o1; i++ {
+				start := i*s1 + j*s2
+				for k := 0; k < n; k++ {
+					line[k] = out[start+k*stride]
+				}
+				if useFFT {
+					conv.convolve(line, res)
+				} else {
+					convolveLine(kernel, line, res)
+				}
+				for k := 0; k < n; k++ {
+					out[start+k*stride] = res[k]
+				}
+			}
+		}
+	}
+	return out
+}
+
+// gaussianKernel returns a normalized 1D Gaussian kernel with
+// half-width ceil(3*sigma) and length 2*half-width+1.
+func gaussianKernel(sigma float32) []float64 {
+
+	radius := int(math.Ceil(3 * float64(sigma)))
+	kernel := make([]float64, 2*radius+1)
+	s2 := 2 * float64(sigma) * float64(sigma)
+	sum := 0.0
+	for i := -radius; i <= radius; i++ {
+		w := math.Exp(-float64(i*i) / s2)
+		kernel[i+radius] = w
+		sum += w
+	}
+	for i := range kernel {
+		kernel[i] /= sum
+	}
+	return kernel
+}
+
+// convolveLine convolves src with the specified symmetric kernel, clamping
+// samples outside the line to the border values, and stores the result in dst.
+func convolveLine(kernel []float64, src, dst []float32) {
+
+	radius := len(kernel) / 2
+	last := len(src) - 1
+	for i := range src {
+		sum := 0.0
+		for k := -radius; k <= radius; k++ {
+			sum += kernel[k+radius] * float64(src[ClampInt(i+k, 0, last)])
+		}
+		dst[i] = float32(sum)
+	}
+}
+
+// fftConvolver convolves lines of a fixed length with a fixed kernel using FFT.
+type fftConvolver struct {
+	radius  int          // kernel half-width
+	kfft    []complex128 // transformed kernel
+	buf     []complex128 // work buffer
+	lineLen int          // length of the lines to convolve
+}
+
+// newFFTConvolver creates and returns a pointer to a new fftConvolver
+// for the specified kernel and line length.
+func newFFTConvolver(kernel []float64, lineLen int) *fftConvolver {
+
+	c := new(fftConvolver)
+	c.radius = len(kernel) / 2
+	c.lineLen = lineLen
+	// The padded line has lineLen+2*radius samples and the linear convolution
+	// with the kernel has lineLen+4*radius samples.
+	size := 1
+	for size < lineLen+4*c.radius {
+		size <<= 1
+	}
+	c.kfft = make([]complex128, size)
+	for i, w := range kernel {
+		c.kfft[i] = complex(w, 0)
+	}
+	FFT(c.kfft, false)
+	c.buf = make([]complex128, size)
+	return c
+}
+
+// convolve convolves src with the kernel and stores the result in dst.
+func (c *fftConvolver) convolve(src, dst []float32) {
+
+	last := c.lineLen - 1
+	for i := range c.buf {
+		c.buf[i] = 0
+	}
+	for i := 0; i < c.lineLen+2*c.radius; i++ {
+		c.buf[i] = complex(float64(src[ClampInt(i-c.radius, 0, last)]), 0)
+	}
+	FFT(c.buf, false)
+	for i := range c.buf {
+		c.buf[i] *= c.kfft[i]
+	}
+	FFT(c.buf, true)
+	for i := 0; i < c.lineLen; i++ {
+		dst[i] = float32(real(c.buf[i+2*c.radius]))
+	}
+}
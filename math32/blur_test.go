@@ -0,0 +1,63 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"testing"
+)
+
+// Test that the blur of an impulse is a Gaussian with the specified standard deviation along each axis,
+// with the direct and the FFT convolutions
+func TestGaussianBlur3DImpulse(t *testing.T) {
+
+	const n = 61
+	const c = n / 2
+	for _, sigma := range []float32{1, 2, 6} {
+		data := make([]float32, n*n*n)
+		data[c+c*n+c*n*n] = 1
+		out := GaussianBlur3D(data, n, n, n, sigma)
+		var sum, vx, vy, vz float64
+		for z := 0; z < n; z++ {
+			for y := 0; y < n; y++ {
+				for x := 0; x < n; x++ {
+					w := float64(out[x+y*n+z*n*n])
+					if w < -1e-6 {
+						t.Fatal("negative value", w, "for sigma", sigma)
+					}
+					sum += w
+					vx += w * float64((x-c)*(x-c))
+					vy += w * float64((y-c)*(y-c))
+					vz += w * float64((z-c)*(z-c))
+				}
+			}
+		}
+		if Abs(float32(sum)-1) > 1e-5 {
+			t.Error("sum", sum, "instead of 1 for sigma", sigma)
+		}
+		// The kernel truncated at 3 sigma has a slightly smaller deviation
+		for _, v := range []float64{vx, vy, vz} {
+			if sd := Sqrt(float32(v / sum)); Abs(sd-sigma) > 0.02*sigma {
+				t.Error("standard deviation", sd, "instead of", sigma)
+			}
+		}
+	}
+}
+
+// Test that a constant field is not changed, also at its borders
+func TestGaussianBlur3DConstant(t *testing.T) {
+
+	const w, h, d = 7, 5, 3
+	data := make([]float32, w*h*d)
+	for i := range data {
+		data[i] = 2.5
+	}
+	for _, sigma := range []float32{0, 0.5, 3, 8} {
+		for i, v := range GaussianBlur3D(data, w, h, d, sigma) {
+			if Abs(v-2.5) > 1e-5 {
+				t.Fatal("value", v, "at", i, "instead of 2.5 for sigma", sigma)
+			}
+		}
+	}
+}
@@ -191,6 +191,65 @@ func (b *Box3) ClampPoint(point *Vector3, optionalTarget *Vector3) *Vector3 {
 	return result.Copy(point).Clamp(&b.Min, &b.Max)
 }
 
+// SupportPoint calculates the point of this box furthest in the specified direction, which is the corner
+// with the maximum or the minimum coordinate on each axis according to the sign of the direction on that axis.
+// The maximum coordinate is used on the axes where the direction is zero.
+// Stores the pointer to this new point into optionalTarget, if not nil, and also returns it.
+func (b *Box3) SupportPoint(dir *Vector3, optionalTarget *Vector3) *Vector3 {
+
+	var result *Vector3
+	if optionalTarget == nil {
+		result = NewVector3(0, 0, 0)
+	} else {
+		result = optionalTarget
+	}
+	result.Copy(&b.Max)
+	if dir.X < 0 {
+		result.X = b.Min.X
+	}
+	if dir.Y < 0 {
+		result.Y = b.Min.Y
+	}
+	if dir.Z < 0 {
+		result.Z = b.Min.Z
+	}
+	return result
+}
+
+// ClosestPointToPoint calculates the point of the surface of this box closest to the specified point:
+// the point clamped to the box if outside, or its projection on the nearest face if inside.
+// Stores the pointer to this new point into optionalTarget, if not nil, and also returns it.
+func (b *Box3) ClosestPointToPoint(point *Vector3, optionalTarget *Vector3) *Vector3 {
+
+	result := b.ClampPoint(point, optionalTarget)
+	if !result.Equals(point) {
+		return result
+	}
+	// Inside: moves the point to the nearest face
+	dists := [6]float32{point.X - b.Min.X, b.Max.X - point.X, point.Y - b.Min.Y, b.Max.Y - point.Y, point.Z - b.Min.Z, b.Max.Z - point.Z}
+	nearest := 0
+	for i := 1; i < len(dists); i++ {
+		if dists[i] < dists[nearest] {
+			nearest = i
+		}
+	}
+	switch nearest {
+	case 0:
+		result.X = b.Min.X
+	case 1:
+		result.X = b.Max.X
+	case 2:
+		result.Y = b.Min.Y
+	case 3:
+		result.Y = b.Max.Y
+	case 4:
+		result.Z = b.Min.Z
+	case 5:
+		result.Z = b.Max.Z
+	}
+	return result
+}
+
 // DistanceToPoint returns the distance from this box to the specified point.
 func (b *Box3) DistanceToPoint(point *Vector3) float32 {
 
@@ -0,0 +1,280 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+// BSplineSurface represents a non-rational B-spline surface defined by a grid of control
+// points and a knot vector with its degree in each parametric direction.
+// The control point [i][j] is associated with the i-th basis function in the
+// u direction and the j-th basis function in the v direction.
+// Unlike Bezier surfaces, moving a control point only affects the surface locally.
+type BSplineSurface struct {
+	controlPoints [][]Vector3
+	degree        [2]int
+	knots         [2][]float32
+}
+
+// NewBSplineSurface creates and returns a pointer to a new BSplineSurface with a copy of the
+// specified grid of control points and the specified degrees, which are clamped to the number
+// of control points minus one in each direction. Uniform clamped knot vectors over [0,1] are
+// generated, so the surface interpolates the corner control points.
+func NewBSplineSurface(controlPoints [][]Vector3, degreeU, degreeV int) *BSplineSurface {
+
+	nu := len(controlPoints)
+	if nu < 2 {
+		panic("NewBSplineSurface: at least 2x2 control points are required")
+	}
+	nv := len(controlPoints[0])
+	if nv < 2 {
+		panic("NewBSplineSurface: at least 2x2 control points are required")
+	}
+	s := new(BSplineSurface)
+	s.controlPoints = make([][]Vector3, nu)
+	for i := range controlPoints {
+		if len(controlPoints[i]) != nv {
+			panic("NewBSplineSurface: control points grid is not rectangular")
+		}
+		s.controlPoints[i] = make([]Vector3, nv)
+		copy(s.controlPoints[i], controlPoints[i])
+	}
+	s.degree[0] = ClampInt(degreeU, 1, nu-1)
+	s.degree[1] = ClampInt(degreeV, 1, nv-1)
+	s.knots[0] = bsplineClampedKnots(nu, s.degree[0])
+	s.knots[1] = bsplineClampedKnots(nv, s.degree[1])
+	return s
+}
+
+// bsplineClampedKnots returns the uniform clamped knot vector over [0,1]
+// for the specified number of control points and degree.
+func bsplineClampedKnots(n, degree int) []float32 {
+
+	knots := make([]float32, n+degree+1)
+	segments := n - degree
+	for i := range knots {
+		switch {
+		case i <= degree:
+			knots[i] = 0
+		case i >= n:
+			knots[i] = 1
+		default:
+			knots[i] = float32(i-degree) / float32(segments)
+		}
+	}
+	return knots
+}
+
+// ControlPoints returns the grid of control points of this surface.
+func (s *BSplineSurface) ControlPoints() [][]Vector3 {
+
+	return s.controlPoints
+}
+
+// Degree returns the degree of this surface in the specified direction (0 for u, 1 for v).
+func (s *BSplineSurface) Degree(direction int) int {
+
+	return s.degree[direction]
+}
+
+// Knots returns the knot vector of this surface in the specified direction (0 for u, 1 for v).
+func (s *BSplineSurface) Knots(direction int) []float32 {
+
+	return s.knots[direction]
+}
+
+// bsplineSpan returns the index of the knot span containing t (The NURBS Book, A2.1).
+func bsplineSpan(knots []float32, n, degree int, t float32) int {
+
+	if t >= knots[n] {
+		return n - 1
+	}
+	if t <= knots[degree] {
+		return degree
+	}
+	low := degree
+	high := n
+	mid := (low + high) / 2
+	for t < knots[mid] || t >= knots[mid+1] {
+		if t < knots[mid] {
+			high = mid
+		} else {
+			low = mid
+		}
+		mid = (low + high) / 2
+	}
+	return mid
+}
+
+// bsplineBasis computes using the Cox-de Boor recurrence the degree+1 non-zero basis
+// functions at t of the specified knot span and their first derivatives (The NURBS Book, A2.3).
+func bsplineBasis(knots []float32, span, degree int, t float32, basis, derivs []float32) {
+
+	// ndu stores the basis functions in its upper triangle and the knot differences in its lower triangle
+	ndu := make([][]float32, degree+1)
+	for i := range ndu {
+		ndu[i] = make([]float32, degree+1)
+	}
+	left := make([]float32, degree+1)
+	right := make([]float32, degree+1)
+	ndu[0][0] = 1
+	for j := 1; j <= degree; j++ {
+		left[j] = t - knots[span+1-j]
+		right[j] = knots[span+j] - t
+		saved := float32(0)
+		for r := 0; r < j; r++ {
+			ndu[j][r] = right[r+1] + left[j-r]
+			temp := ndu[r][j-1] / ndu[j][r]
+			ndu[r][j] = saved + right[r+1]*temp
+			saved = left[j-r] * temp
+		}
+		ndu[j][j] = saved
+	}
+	for r := 0; r <= degree; r++ {
+		basis[r] = ndu[r][degree]
+		d := float32(0)
+		if r >= 1 {
+			d += ndu[r-1][degree-1] / ndu[degree][r-1]
+		}
+		if r <= degree-1 {
+			d -= ndu[r][degree-1] / ndu[degree][r]
+		}
+		derivs[r] = d * float32(degree)
+	}
+}
+
+// evaluate returns the point of this surface and its partial derivatives at the specified parameters.
+func (s *BSplineSurface) evaluate(u, v float32) (p, du, dv Vector3) {
+
+	u = Clamp(u, 0, 1)
+	v = Clamp(v, 0, 1)
+	nu := len(s.controlPoints)
+	nv := len(s.controlPoints[0])
+	pu := s.degree[0]
+	pv := s.degree[1]
+	spanU := bsplineSpan(s.knots[0], nu, pu, u)
+	spanV := bsplineSpan(s.knots[1], nv, pv, v)
+	bu := make([]float32, pu+1)
+	dbu := make([]float32, pu+1)
+	bv := make([]float32, pv+1)
+	dbv := make([]float32, pv+1)
+	bsplineBasis(s.knots[0], spanU, pu, u, bu, dbu)
+	bsplineBasis(s.knots[1], spanV, pv, v, bv, dbv)
+
+	for i := 0; i <= pu; i++ {
+		row := s.controlPoints[spanU-pu+i]
+		for j := 0; j <= pv; j++ {
+			cp := &row[spanV-pv+j]
+			w := bu[i] * bv[j]
+			wu := dbu[i] * bv[j]
+			wv := bu[i] * dbv[j]
+			p.X += cp.X * w
+			p.Y += cp.Y * w
+			p.Z += cp.Z * w
+			du.X += cp.X * wu
+			du.Y += cp.Y * wu
+			du.Z += cp.Z * wu
+			dv.X += cp.X * wv
+			dv.Y += cp.Y * wv
+			dv.Z += cp.Z * wv
+		}
+	}
+	return p, du, dv
+}
+
+// PointAt returns the point of this surface at the specified parameters in [0,1].
+func (s *BSplineSurface) PointAt(u, v float32) Vector3 {
+
+	p, _, _ := s.evaluate(u, v)
+	return p
+}
+
+// Derivatives returns the partial derivatives of this surface relative to u and v
+// at the specified parameters.
+func (s *BSplineSurface) Derivatives(u, v float32) (du, dv Vector3) {
+
+	_, du, dv = s.evaluate(u, v)
+	return du, dv
+}
+
+// NormalAt returns the unit normal of this surface at the specified parameters,
+// the normalized cross product of the partial derivatives relative to u and v.
+// Returns the zero vector if the surface is degenerate at this point.
+func (s *BSplineSurface) NormalAt(u, v float32) Vector3 {
+
+	_, du, dv := s.evaluate(u, v)
+	var n Vector3
+	n.CrossVectors(&du, &dv)
+	if n.LengthSq() > 0 {
+		n.Normalize()
+	}
+	return n
+}
+
+// InsertKnot inserts the knot t in the specified direction (0 for u, 1 for v) using Boehm's
+// algorithm, adding a row (u) or column (v) of control points without changing the shape of the surface.
+// Knots outside the open interval (0,1) are ignored.
+func (s *BSplineSurface) InsertKnot(t float32, direction int) {
+
+	if direction != 0 && direction != 1 {
+		panic("index is out of range")
+	}
+	if t <= 0 || t >= 1 {
+		return
+	}
+	knots := s.knots[direction]
+	degree := s.degree[direction]
+	nu := len(s.controlPoints)
+	nv := len(s.controlPoints[0])
+	n := nu
+	if direction == 1 {
+		n = nv
+	}
+	k := bsplineSpan(knots, n, degree, t)
+
+	// insert returns the new control points of the curve with the specified control points
+	insert := func(points []Vector3) []Vector3 {
+		res := make([]Vector3, len(points)+1)
+		for i := range res {
+			switch {
+			case i <= k-degree:
+				res[i] = points[i]
+			case i > k:
+				res[i] = points[i-1]
+			default:
+				alpha := (t - knots[i]) / (knots[i+degree] - knots[i])
+				res[i] = points[i-1]
+				res[i].Lerp(&points[i], alpha)
+			}
+		}
+		return res
+	}
+
+	if direction == 0 {
+		// Inserts along each column of constant v
+		cps := make([][]Vector3, nu+1)
+		for i := range cps {
+			cps[i] = make([]Vector3, nv)
+		}
+		column := make([]Vector3, nu)
+		for j := 0; j < nv; j++ {
+			for i := 0; i < nu; i++ {
+				column[i] = s.controlPoints[i][j]
+			}
+			res := insert(column)
+			for i := range res {
+				cps[i][j] = res[i]
+			}
+		}
+		s.controlPoints = cps
+	} else {
+		for i := range s.controlPoints {
+			s.controlPoints[i] = insert(s.controlPoints[i])
+		}
+	}
+
+	newKnots := make([]float32, 0, len(knots)+1)
+	newKnots = append(newKnots, knots[:k+1]...)
+	newKnots = append(newKnots, t)
+	newKnots = append(newKnots, knots[k+1:]...)
+	s.knots[direction] = newKnots
+}
@@ -0,0 +1,94 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+// coonsDerivativeStep is the parameter step of the finite differences of the boundary curves of a CoonsPatch.
+const coonsDerivativeStep = 1e-3
+
+// CoonsPatch is a bilinearly blended Coons patch: the surface interpolating four boundary curves.
+// The curves C0 and C1 are the boundaries at v=0 and v=1 parameterized by u, and the curves D0 and D1
+// the boundaries at u=0 and u=1 parameterized by v. The curves must meet at the four corners:
+// C0(0)=D0(0), C0(1)=D1(0), C1(0)=D0(1) and C1(1)=D1(1).
+type CoonsPatch struct {
+	C0, C1 *BezierCurve3
+	D0, D1 *BezierCurve3
+}
+
+// NewCoonsPatch creates and returns a pointer to a new CoonsPatch with the specified boundary curves.
+func NewCoonsPatch(c0, c1, d0, d1 *BezierCurve3) *CoonsPatch {
+
+	return &CoonsPatch{C0: c0, C1: c1, D0: d0, D1: d1}
+}
+
+// PointAt returns the point of this patch at the specified parameters in [0,1]: the sum of the linear
+// blends of the opposite boundary curves minus the bilinear blend of the corners, which both blends contain.
+func (p *CoonsPatch) PointAt(u, v float32) Vector3 {
+
+	c0, c1 := p.C0.PointAt(u), p.C1.PointAt(u)
+	d0, d1 := p.D0.PointAt(v), p.D1.PointAt(v)
+	p00, p10 := p.C0.PointAt(0), p.C0.PointAt(1)
+	p01, p11 := p.C1.PointAt(0), p.C1.PointAt(1)
+	var r Vector3
+	for _, term := range [...]struct {
+		p *Vector3
+		w float32
+	}{
+		{&c0, 1 - v}, {&c1, v}, {&d0, 1 - u}, {&d1, u},
+		{&p00, -(1 - u) * (1 - v)}, {&p10, -u * (1 - v)}, {&p01, -(1 - u) * v}, {&p11, -u * v},
+	} {
+		r.X += term.p.X * term.w
+		r.Y += term.p.Y * term.w
+		r.Z += term.p.Z * term.w
+	}
+	return r
+}
+
+// Derivatives returns the partial derivatives of this patch relative to u and v at the specified parameters,
+// with the derivatives of the boundary curves approximated by finite differences.
+func (p *CoonsPatch) Derivatives(u, v float32) (du, dv Vector3) {
+
+	c0, c1 := curveDerivative(p.C0, u), curveDerivative(p.C1, u)
+	d0, d1 := curveDerivative(p.D0, v), curveDerivative(p.D1, v)
+	pd0, pd1 := p.D0.PointAt(v), p.D1.PointAt(v)
+	pc0, pc1 := p.C0.PointAt(u), p.C1.PointAt(u)
+	p00, p10 := p.C0.PointAt(0), p.C0.PointAt(1)
+	p01, p11 := p.C1.PointAt(0), p.C1.PointAt(1)
+
+	// The blends of the corners are linear in each parameter
+	var e0, e1 Vector3
+	e0.SubVectors(&p10, &p00).MultiplyScalar(1 - v)
+	e1.SubVectors(&p11, &p01).MultiplyScalar(v)
+	du.Copy(c0.MultiplyScalar(1 - v)).Add(c1.MultiplyScalar(v)).Add(&pd1).Sub(&pd0).Sub(&e0).Sub(&e1)
+	e0.SubVectors(&p01, &p00).MultiplyScalar(1 - u)
+	e1.SubVectors(&p11, &p10).MultiplyScalar(u)
+	dv.Copy(d0.MultiplyScalar(1 - u)).Add(d1.MultiplyScalar(u)).Add(&pc1).Sub(&pc0).Sub(&e0).Sub(&e1)
+	return du, dv
+}
+
+// NormalAt returns the unit normal of this patch at the specified parameters,
+// the normalized cross product of the partial derivatives relative to u and v.
+// Returns the zero vector if the patch is degenerate at this point.
+func (p *CoonsPatch) NormalAt(u, v float32) Vector3 {
+
+	du, dv := p.Derivatives(u, v)
+	var n Vector3
+	n.CrossVectors(&du, &dv)
+	if n.LengthSq() > 0 {
+		n.Normalize()
+	}
+	return n
+}
+
+// curveDerivative returns the derivative of the specified curve at the specified parameter in [0,1]
+// approximated by central differences, or one-sided differences at the ends.
+func curveDerivative(c *BezierCurve3, t float32) Vector3 {
+
+	t0 := Max(t-coonsDerivativeStep, 0)
+	t1 := Min(t+coonsDerivativeStep, 1)
+	a, b := c.PointAt(t0), c.PointAt(t1)
+	var d Vector3
+	d.SubVectors(&b, &a).MultiplyScalar(1 / (t1 - t0))
+	return d
+}
@@ -0,0 +1,73 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"testing"
+)
+
+// newTestCoonsPatch returns a patch with curved boundaries of degrees 2 and 3 and corners at
+// (0,0,0), (1,0,0), (0,0,1) and (1,0,1).
+func newTestCoonsPatch() *CoonsPatch {
+
+	c0 := NewBezierCurve3([]Vector3{{X: 0, Y: 0, Z: 0}, {X: 0.5, Y: 0.5, Z: 0}, {X: 1, Y: 0, Z: 0}})
+	c1 := NewBezierCurve3([]Vector3{{X: 0, Y: 0, Z: 1}, {X: 0.3, Y: -0.2, Z: 1}, {X: 0.7, Y: 0.4, Z: 1.2}, {X: 1, Y: 0, Z: 1}})
+	d0 := NewBezierCurve3([]Vector3{{X: 0, Y: 0, Z: 0}, {X: -0.3, Y: 0.3, Z: 0.5}, {X: 0, Y: 0, Z: 1}})
+	d1 := NewBezierCurve3([]Vector3{{X: 1, Y: 0, Z: 0}, {X: 1.2, Y: 0.6, Z: 0.5}, {X: 1, Y: 0, Z: 1}})
+	return NewCoonsPatch(c0, c1, d0, d1)
+}
+
+// Test that the patch reproduces its four boundary curves at u or v equal to 0 or 1
+func TestCoonsPatchBoundaries(t *testing.T) {
+
+	p := newTestCoonsPatch()
+	for i := 0; i <= 20; i++ {
+		s := float32(i) / 20
+		boundaries := []struct {
+			name            string
+			patch, boundary Vector3
+		}{
+			{"c0", p.PointAt(s, 0), p.C0.PointAt(s)},
+			{"c1", p.PointAt(s, 1), p.C1.PointAt(s)},
+			{"d0", p.PointAt(0, s), p.D0.PointAt(s)},
+			{"d1", p.PointAt(1, s), p.D1.PointAt(s)},
+		}
+		for _, b := range boundaries {
+			if b.patch.DistanceTo(&b.boundary) > 1e-6 {
+				t.Error("patch point", b.patch, "instead of the point", b.boundary, "of the boundary", b.name, "at", s)
+			}
+		}
+	}
+}
+
+// Test that the patch of the straight sides of a square is the bilinear map of the square
+// and that the derivatives are the limits of the differences of the points
+func TestCoonsPatchPointAt(t *testing.T) {
+
+	line := func(a, b Vector3) *BezierCurve3 { return NewBezierCurve3([]Vector3{a, b}) }
+	square := NewCoonsPatch(line(Vector3{}, Vector3{X: 2}), line(Vector3{Y: 3}, Vector3{X: 2, Y: 3}),
+		line(Vector3{}, Vector3{Y: 3}), line(Vector3{X: 2}, Vector3{X: 2, Y: 3}))
+	for _, uv := range [][2]float32{{0.25, 0.5}, {0.5, 0.5}, {0.9, 0.1}} {
+		p := square.PointAt(uv[0], uv[1])
+		if expected := (Vector3{X: 2 * uv[0], Y: 3 * uv[1]}); p.DistanceTo(&expected) > 1e-6 {
+			t.Error("PointAt", uv, "is", p, "instead of", expected)
+		}
+	}
+
+	p := newTestCoonsPatch()
+	const h = 1e-3
+	for _, uv := range [][2]float32{{0.4, 0.6}, {0.1, 0.8}} {
+		u, v := uv[0], uv[1]
+		du, dv := p.Derivatives(u, v)
+		var fdu, fdv Vector3
+		p0, p1 := p.PointAt(u-h, v), p.PointAt(u+h, v)
+		fdu.SubVectors(&p1, &p0).DivideScalar(2 * h)
+		p0, p1 = p.PointAt(u, v-h), p.PointAt(u, v+h)
+		fdv.SubVectors(&p1, &p0).DivideScalar(2 * h)
+		if du.DistanceTo(&fdu) > 1e-2 || dv.DistanceTo(&fdv) > 1e-2 {
+			t.Error("derivatives", du, dv, "instead of", fdu, fdv, "at", uv)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// delaunayTriangle is a counter clockwise triangle of the Bowyer-Watson triangulation
+// with the triangles adjacent to its edges.
+type delaunayTriangle struct {
+	v    [3]int // vertex indices
+	n    [3]int // index of the triangle adjacent to the edge opposite to each vertex or -1
+	dead bool
+}
+
+// delaunay is the state of the Bowyer-Watson incremental triangulation.
+// The three vertices after the points are the vertices of a super triangle at infinity,
+// which are handled symbolically so that the convex hull of the points is always triangulated.
+type delaunay struct {
+	pts  [][2]float64
+	tris []delaunayTriangle
+	last int // last triangle created, from which point location starts
+}
+
+// delaunaySuper are the directions of the super triangle vertices at infinity, in counter clockwise order.
+var delaunaySuper = [3][2]float64{{0, 1}, {-math.Sqrt(3) / 2, -0.5}, {math.Sqrt(3) / 2, -0.5}}
+
+// Delaunay2D returns the indices of the triangles of the Delaunay triangulation of the specified
+// points computed by Bowyer-Watson incremental insertion, with three indices per triangle in
+// counter clockwise order. No point is inside the circumcircle of any triangle.
+// Returns an error if there are less than three points, duplicate points or all points are collinear.
+func Delaunay2D(points []Vector2) (indices []int, err error) {
+
+	n := len(points)
+	if n < 3 {
+		return nil, errors.New("less than three points")
+	}
+	seen := make(map[Vector2]bool, n)
+	for _, p := range points {
+		if seen[p] {
+			return nil, errors.New("duplicate points")
+		}
+		seen[p] = true
+	}
+	d := &delaunay{pts: make([][2]float64, n)}
+	for i, p := range points {
+		d.pts[i] = [2]float64{float64(p.X), float64(p.Y)}
+	}
+	collinear := true
+	for i := 2; i < n && collinear; i++ {
+		collinear = orient2(d.pts[0], d.pts[1], d.pts[i]) == 0
+	}
+	if collinear {
+		return nil, errors.New("all points are collinear")
+	}
+
+	// Points are inserted along a Morton curve so that each point is located near the last triangle
+	order := make([]Vector3, n)
+	var bounds Box3
+	bounds.MakeEmpty()
+	for i, p := range points {
+		order[i] = Vector3{X: p.X, Y: p.Y}
+		bounds.ExpandByPoint(&order[i])
+	}
+	d.tris = append(d.tris, delaunayTriangle{v: [3]int{n, n + 1, n + 2}, n: [3]int{-1, -1, -1}})
+	for _, i := range MortonSort(order, &bounds) {
+		d.insert(i)
+	}
+
+	for i := range d.tris {
+		t := &d.tris[i]
+		if !t.dead && t.v[0] < n && t.v[1] < n && t.v[2] < n {
+			indices = append(indices, t.v[0], t.v[1], t.v[2])
+		}
+	}
+	return indices, nil
+}
+
+// insert inserts the point with the specified index into the triangulation.
+func (d *delaunay) insert(pi int) {
+
+	p := d.pts[pi]
+	t0 := d.locate(p)
+
+	// Triangles whose circumcircle contains the point form a cavity around it
+	type edge struct {
+		a, b, out int
+	}
+	var boundary []edge
+	bad := []int{t0}
+	d.tris[t0].dead = true
+	for k := 0; k < len(bad); k++ {
+		t := d.tris[bad[k]]
+		for i := 0; i < 3; i++ {
+			nb := t.n[i]
+			if nb >= 0 && d.tris[nb].dead {
+				continue
+			}
+			if nb >= 0 && d.inCircle(nb, p) {
+				d.tris[nb].dead = true
+				bad = append(bad, nb)
+				continue
+			}
+			boundary = append(boundary, edge{t.v[(i+1)%3], t.v[(i+2)%3], nb})
+		}
+	}
+
+	// The cavity is triangulated by connecting its boundary edges to the point
+	first := len(d.tris)
+	startAt := make(map[int]int, len(boundary))
+	endAt := make(map[int]int, len(boundary))
+	for k, e := range boundary {
+		ti := first + k
+		d.tris = append(d.tris, delaunayTriangle{v: [3]int{e.a, e.b, pi}, n: [3]int{-1, -1, e.out}})
+		startAt[e.a] = ti
+		endAt[e.b] = ti
+		if e.out >= 0 {
+			o := &d.tris[e.out]
+			for i := 0; i < 3; i++ {
+				if o.v[i] != e.a && o.v[i] != e.b {
+					o.n[i] = ti
+				}
+			}
+		}
+	}
+	for k, e := range boundary {
+		t := &d.tris[first+k]
+		t.n[0] = startAt[e.b]
+		t.n[1] = endAt[e.a]
+	}
+	d.last = len(d.tris) - 1
+}
+
+// locate returns the index of a triangle containing the specified point, walking from the last triangle.
+func (d *delaunay) locate(p [2]float64) int {
+
+	t := d.last
+	for steps := 0; steps < len(d.tris); steps++ {
+		tri := &d.tris[t]
+		moved := false
+		for i := 0; i < 3; i++ {
+			if d.orient(tri.v[(i+1)%3], tri.v[(i+2)%3], p) < 0 && tri.n[i] >= 0 {
+				t = tri.n[i]
+				moved = true
+				break
+			}
+		}
+		if !moved {
+			return t
+		}
+	}
+	// The walk cycles only because of rounding errors: falls back to testing all triangles
+	for i := range d.tris {
+		tri := &d.tris[i]
+		if !tri.dead && d.orient(tri.v[1], tri.v[2], p) >= 0 &&
+			d.orient(tri.v[2], tri.v[0], p) >= 0 && d.orient(tri.v[0], tri.v[1], p) >= 0 {
+			return i
+		}
+	}
+	return t
+}
+
+// orient returns a positive value if the specified point is on the left of the edge between
+// the specified vertices, a negative value if on the right and zero if collinear.
+// Edges with super triangle vertices are evaluated in the limit of infinite distance.
+func (d *delaunay) orient(i, j int, p [2]float64) float64 {
+
+	n := len(d.pts)
+	switch {
+	case i < n && j < n:
+		return orient2(d.pts[i], d.pts[j], p)
+	case i < n:
+		a, dir := d.pts[i], delaunaySuper[j-n]
+		return dir[0]*(p[1]-a[1]) - dir[1]*(p[0]-a[0])
+	case j < n:
+		b, dir := d.pts[j], delaunaySuper[i-n]
+		return dir[0]*(b[1]-p[1]) - dir[1]*(b[0]-p[0])
+	}
+	d1, d2 := delaunaySuper[i-n], delaunaySuper[j-n]
+	return d1[0]*d2[1] - d1[1]*d2[0]
+}
+
+// inCircle returns if the specified point is strictly inside the circumcircle of the specified triangle.
+// Circumcircles through super triangle vertices are evaluated in the limit of infinite distance:
+// a triangle with one of them becomes the half plane on the left of its real edge.
+func (d *delaunay) inCircle(ti int, p [2]float64) bool {
+
+	n := len(d.pts)
+	v := d.tris[ti].v
+	supers := 0
+	for _, vi := range v {
+		if vi >= n {
+			supers++
+		}
+	}
+	switch supers {
+	case 0:
+		return inCircle2(d.pts[v[0]], d.pts[v[1]], d.pts[v[2]], p)
+	case 1:
+		// Real edge in counter clockwise order
+		var a, b [2]float64
+		switch {
+		case v[0] >= n:
+			a, b = d.pts[v[1]], d.pts[v[2]]
+		case v[1] >= n:
+			a, b = d.pts[v[2]], d.pts[v[0]]
+		default:
+			a, b = d.pts[v[0]], d.pts[v[1]]
+		}
+		o := orient2(a, b, p)
+		if o != 0 {
+			return o > 0
+		}
+		dot := (p[0]-a[0])*(b[0]-a[0]) + (p[1]-a[1])*(b[1]-a[1])
+		return dot > 0 && dot < (b[0]-a[0])*(b[0]-a[0])+(b[1]-a[1])*(b[1]-a[1])
+	case 2:
+		// The circle becomes the half plane bounded by the line through the real vertex
+		// orthogonal to the direction of the missing super vertex
+		var a [2]float64
+		missing := 3
+		for _, vi := range v {
+			if vi < n {
+				a = d.pts[vi]
+			} else {
+				missing -= vi - n
+			}
+		}
+		dir := delaunaySuper[missing]
+		return (p[0]-a[0])*dir[0]+(p[1]-a[1])*dir[1] < 0
+	}
+	return true
+}
+
+// orient2 returns twice the signed area of the triangle a, b, c, positive if counter clockwise.
+func orient2(a, b, c [2]float64) float64 {
+
+	return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+}
+
+// inCircle2 returns if the point d is strictly inside the circumcircle of the counter clockwise triangle a, b, c.
+func inCircle2(a, b, c, d [2]float64) bool {
+
+	adx, ady := a[0]-d[0], a[1]-d[1]
+	bdx, bdy := b[0]-d[0], b[1]-d[1]
+	cdx, cdy := c[0]-d[0], c[1]-d[1]
+	ad := adx*adx + ady*ady
+	bd := bdx*bdx + bdy*bdy
+	cd := cdx*cdx + cdy*cdy
+	det := adx*(bdy*cd-bd*cdy) - ady*(bdx*cd-bd*cdx) + ad*(bdx*cdy-bdy*cdx)
+	return det > 0
+}
+
+// VoronoiFromDelaunay returns the Voronoi cell of each of the specified points from their Delaunay
+// triangulation, as the circumcenters of the triangles around the point in counter clockwise order.
+// The cells of the points on the convex hull are unbounded and contain only the finite circumcenters.
+func VoronoiFromDelaunay(points []Vector2, triangles []int) [][]Vector2 {
+
+	type corner struct {
+		angle  float32
+		center Vector2
+	}
+	corners := make([][]corner, len(points))
+	for i := 0; i+2 < len(triangles); i += 3 {
+		a, b, c := &points[triangles[i]], &points[triangles[i+1]], &points[triangles[i+2]]
+		center, ok := circumcenter(a, b, c)
+		if !ok {
+			continue
+		}
+		cx := (a.X + b.X + c.X) / 3
+		cy := (a.Y + b.Y + c.Y) / 3
+		for _, vi := range triangles[i : i+3] {
+			p := &points[vi]
+			// Triangles are ordered around the point by the angle of their centroid
+			corners[vi] = append(corners[vi], corner{Atan2(cy-p.Y, cx-p.X), center})
+		}
+	}
+	cells := make([][]Vector2, len(points))
+	for i, cs := range corners {
+		sort.Slice(cs, func(a, b int) bool { return cs[a].angle < cs[b].angle })
+		// Triangles of cocircular points have the same circumcenter
+		cell := make([]Vector2, 0, len(cs))
+		for j := range cs {
+			if len(cell) == 0 || !cell[len(cell)-1].Equals(&cs[j].center) {
+				cell = append(cell, cs[j].center)
+			}
+		}
+		if len(cell) > 1 && cell[0].Equals(&cell[len(cell)-1]) {
+			cell = cell[:len(cell)-1]
+		}
+		cells[i] = cell
+	}
+	return cells
+}
+
+// circumcenter returns the center of the circle through the specified points,
+// or false if they are collinear.
+func circumcenter(a, b, c *Vector2) (Vector2, bool) {
+
+	bx, by := float64(b.X-a.X), float64(b.Y-a.Y)
+	cx, cy := float64(c.X-a.X), float64(c.Y-a.Y)
+	den := 2 * (bx*cy - by*cx)
+	if den == 0 {
+		return Vector2{}, false
+	}
+	b2 := bx*bx + by*by
+	c2 := cx*cx + cy*cy
+	ux := (cy*b2 - by*c2) / den
+	uy := (bx*c2 - cx*b2) / den
+	return Vector2{a.X + float32(ux), a.Y + float32(uy)}, true
+}
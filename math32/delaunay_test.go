@@ -0,0 +1,131 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// checkDelaunay checks that the triangles are counter clockwise, cover the convex hull of the points
+// with the specified area and that no point is inside the circumcircle of any triangle.
+func checkDelaunay(t *testing.T, name string, points []Vector2, hullArea float64) {
+
+	indices, err := Delaunay2D(points)
+	if err != nil {
+		t.Fatal(name, err)
+	}
+	if len(indices) == 0 || len(indices)%3 != 0 {
+		t.Fatal(name, "invalid number of indices:", len(indices))
+	}
+	var area float64
+	for i := 0; i < len(indices); i += 3 {
+		a, b, c := points[indices[i]], points[indices[i+1]], points[indices[i+2]]
+		ax, ay := float64(a.X), float64(a.Y)
+		bx, by := float64(b.X)-ax, float64(b.Y)-ay
+		cx, cy := float64(c.X)-ax, float64(c.Y)-ay
+		cross := bx*cy - by*cx
+		if cross <= 0 {
+			t.Fatal(name, "triangle not counter clockwise:", i/3)
+		}
+		area += cross / 2
+		// In circle determinant relative to a, scaled by the size of the triangle
+		b2, c2 := bx*bx+by*by, cx*cx+cy*cy
+		scale := math.Max(b2, c2)
+		for j := range points {
+			if j == indices[i] || j == indices[i+1] || j == indices[i+2] {
+				continue
+			}
+			dx, dy := float64(points[j].X)-ax, float64(points[j].Y)-ay
+			d2 := dx*dx + dy*dy
+			det := (bx*(cy*d2-c2*dy) - by*(cx*d2-c2*dx) + b2*(cx*dy-cy*dx))
+			// Negative for points inside the circumcircle of the counter clockwise triangle
+			if det < -1e-9*scale*math.Max(scale, d2) {
+				t.Fatal(name, "point", j, "inside the circumcircle of triangle", i/3)
+			}
+		}
+	}
+	if math.Abs(area-hullArea) > 1e-6*hullArea {
+		t.Error(name, "triangles area", area, "convex hull area", hullArea)
+	}
+}
+
+// Test the Delaunay property for random and adversarial point sets
+func TestDelaunay2D(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(1))
+	// Random points in the unit square with its corners
+	random := []Vector2{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+	for i := 0; i < 500; i++ {
+		random = append(random, Vector2{rng.Float32(), rng.Float32()})
+	}
+	checkDelaunay(t, "random", random, 1)
+
+	// Grid of cocircular points
+	var grid []Vector2
+	for j := 0; j < 12; j++ {
+		for i := 0; i < 12; i++ {
+			grid = append(grid, Vector2{float32(i), float32(j)})
+		}
+	}
+	checkDelaunay(t, "grid", grid, 121)
+
+	// Points nearly on a line with one point off it
+	nearCollinear := []Vector2{{0, -1}}
+	for i := 0; i < 50; i++ {
+		x := float32(i)
+		nearCollinear = append(nearCollinear, Vector2{x, 1e-3 * x * x / 50})
+	}
+	// The convex hull is the triangle of the point off the line and the ends of the parabola,
+	// with the other points inside it: 2n-5 triangles for n points with 3 on the hull
+	indices, err := Delaunay2D(nearCollinear)
+	if err != nil {
+		t.Fatal("near collinear", err)
+	}
+	if len(indices) != 3*(2*len(nearCollinear)-5) {
+		t.Error("near collinear triangles:", len(indices)/3)
+	}
+	checkDelaunay(t, "near collinear", nearCollinear, 24.5)
+}
+
+// Test the errors for degenerate inputs
+func TestDelaunay2DDegenerate(t *testing.T) {
+
+	if _, err := Delaunay2D([]Vector2{{0, 0}, {1, 0}}); err == nil {
+		t.Error("Delaunay2D should fail with two points")
+	}
+	if _, err := Delaunay2D([]Vector2{{0, 0}, {1, 0}, {0, 1}, {1, 0}}); err == nil {
+		t.Error("Delaunay2D should fail with duplicate points")
+	}
+	if _, err := Delaunay2D([]Vector2{{0, 0}, {1, 1}, {2, 2}, {-3, -3}}); err == nil {
+		t.Error("Delaunay2D should fail with collinear points")
+	}
+}
+
+// Test that the Voronoi cell of an interior grid point is the unit square around it
+func TestVoronoiFromDelaunay(t *testing.T) {
+
+	var grid []Vector2
+	for j := 0; j < 5; j++ {
+		for i := 0; i < 5; i++ {
+			grid = append(grid, Vector2{float32(i), float32(j)})
+		}
+	}
+	indices, err := Delaunay2D(grid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cells := VoronoiFromDelaunay(grid, indices)
+	cell := cells[2+2*5]
+	if len(cell) != 4 {
+		t.Fatal("interior cell has", len(cell), "vertices")
+	}
+	for _, c := range cell {
+		if Abs(Abs(c.X-2)-0.5) > 1e-6 || Abs(Abs(c.Y-2)-0.5) > 1e-6 {
+			t.Error("interior cell vertex", c)
+		}
+	}
+}
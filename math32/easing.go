@@ -0,0 +1,172 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+// EasingFunc is an animation easing function which maps the normalized time t in [0,1] to the progress
+// of the animation, 0 at t=0 and 1 at t=1. The progress of some functions is outside of [0,1] in between.
+type EasingFunc func(t float32) float32
+
+// Constants of the back easing functions, which overshoot by about 10%.
+const (
+	easeBackC1 = 1.70158
+	easeBackC2 = easeBackC1 * 1.525
+	easeBackC3 = easeBackC1 + 1
+)
+
+// EaseLinear returns t.
+func EaseLinear(t float32) float32 {
+
+	return t
+}
+
+// EaseInQuad returns the quadratic easing of t, accelerating from zero velocity.
+func EaseInQuad(t float32) float32 {
+
+	return t * t
+}
+
+// EaseOutQuad returns the quadratic easing of t, decelerating to zero velocity.
+func EaseOutQuad(t float32) float32 {
+
+	return 1 - (1-t)*(1-t)
+}
+
+// EaseInOutQuad returns the quadratic easing of t, accelerating until halfway then decelerating.
+func EaseInOutQuad(t float32) float32 {
+
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	u := -2*t + 2
+	return 1 - u*u/2
+}
+
+// EaseInCubic returns the cubic easing of t, accelerating from zero velocity.
+func EaseInCubic(t float32) float32 {
+
+	return t * t * t
+}
+
+// EaseOutCubic returns the cubic easing of t, decelerating to zero velocity.
+func EaseOutCubic(t float32) float32 {
+
+	u := 1 - t
+	return 1 - u*u*u
+}
+
+// EaseInOutCubic returns the cubic easing of t, accelerating until halfway then decelerating.
+func EaseInOutCubic(t float32) float32 {
+
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	u := -2*t + 2
+	return 1 - u*u*u/2
+}
+
+// EaseInExpo returns the exponential easing of t, accelerating from almost zero velocity.
+func EaseInExpo(t float32) float32 {
+
+	if t <= 0 {
+		return 0
+	}
+	return Pow(2, 10*t-10)
+}
+
+// EaseOutExpo returns the exponential easing of t, decelerating to almost zero velocity.
+func EaseOutExpo(t float32) float32 {
+
+	if t >= 1 {
+		return 1
+	}
+	return 1 - Pow(2, -10*t)
+}
+
+// EaseInOutExpo returns the exponential easing of t, accelerating until halfway then decelerating.
+func EaseInOutExpo(t float32) float32 {
+
+	switch {
+	case t <= 0:
+		return 0
+	case t >= 1:
+		return 1
+	case t < 0.5:
+		return Pow(2, 20*t-10) / 2
+	default:
+		return (2 - Pow(2, -20*t+10)) / 2
+	}
+}
+
+// EaseInBack returns the back easing of t, which moves slightly backwards below 0 before accelerating.
+func EaseInBack(t float32) float32 {
+
+	return easeBackC3*t*t*t - easeBackC1*t*t
+}
+
+// EaseOutBack returns the back easing of t, which overshoots slightly above 1 before settling.
+func EaseOutBack(t float32) float32 {
+
+	u := t - 1
+	return 1 + easeBackC3*u*u*u + easeBackC1*u*u
+}
+
+// EaseInOutBack returns the back easing of t, which moves slightly below 0 at the start
+// and above 1 at the end.
+func EaseInOutBack(t float32) float32 {
+
+	if t < 0.5 {
+		u := 2 * t
+		return u * u * ((easeBackC2+1)*u - easeBackC2) / 2
+	}
+	u := 2*t - 2
+	return (u*u*((easeBackC2+1)*u+easeBackC2) + 2) / 2
+}
+
+// EaseOutBounce returns the bounce easing of t, which reaches 1 and bounces back three times with decreasing heights.
+func EaseOutBounce(t float32) float32 {
+
+	const n1 = 7.5625
+	const d1 = 2.75
+	switch {
+	case t < 1/d1:
+		return n1 * t * t
+	case t < 2/d1:
+		t -= 1.5 / d1
+		return n1*t*t + 0.75
+	case t < 2.5/d1:
+		t -= 2.25 / d1
+		return n1*t*t + 0.9375
+	default:
+		t -= 2.625 / d1
+		return n1*t*t + 0.984375
+	}
+}
+
+// EaseInBounce returns the bounce easing of t, which bounces three times with increasing heights before reaching 1.
+func EaseInBounce(t float32) float32 {
+
+	return 1 - EaseOutBounce(1-t)
+}
+
+// EaseInOutBounce returns the bounce easing of t, bouncing in until halfway then bouncing out.
+func EaseInOutBounce(t float32) float32 {
+
+	if t < 0.5 {
+		return (1 - EaseOutBounce(1-2*t)) / 2
+	}
+	return (1 + EaseOutBounce(2*t-1)) / 2
+}
+
+// EaseVector3 returns the interpolation between the specified vectors at the progress of the specified
+// easing function at t. A nil easing function interpolates linearly.
+func EaseVector3(from, to *Vector3, t float32, easing EasingFunc) Vector3 {
+
+	if easing != nil {
+		t = easing(t)
+	}
+	var v Vector3
+	v.Copy(from).Lerp(to, t)
+	return v
+}
@@ -0,0 +1,76 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// FFT computes in place the discrete Fourier transform of x,
+// or its inverse if inverse is true, using the iterative radix-2
+// Cooley-Tukey algorithm. The length of x must be a power of two.
+func FFT(x []complex128, inverse bool) {
+
+	n := len(x)
+	if n < 2 {
+		return
+	}
+	// Bit reversal permutation
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	sign := -1.0
+	if inverse {
+		sign = 1.0
+	}
+	for length := 2; length <= n; length <<= 1 {
+		w := cmplx.Rect(1, sign*2*math.Pi/float64(length))
+		half := length >> 1
+		for i := 0; i < n; i += length {
+			wk := complex(1, 0)
+			for k := 0; k < half; k++ {
+				u := x[i+k]
+				v := x[i+k+half] * wk
+				x[i+k] = u + v
+				x[i+k+half] = u - v
+				wk *= w
+			}
+		}
+	}
+	if inverse {
+		scale := complex(1/float64(n), 0)
+		for i := range x {
+			x[i] *= scale
+		}
+	}
+}
+
+// FFT2D computes in place the two-dimensional discrete Fourier transform of x, or its inverse
+// if inverse is true, where x has width*height elements in row major order (index = x + y*width).
+// The width and height must be powers of two.
+func FFT2D(x []complex128, width, height int, inverse bool) {
+
+	for y := 0; y < height; y++ {
+		FFT(x[y*width:(y+1)*width], inverse)
+	}
+	col := make([]complex128, height)
+	for c := 0; c < width; c++ {
+		for y := range col {
+			col[y] = x[c+y*width]
+		}
+		FFT(col, inverse)
+		for y := range col {
+			x[c+y*width] = col[y]
+		}
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"math"
+)
+
+// Float16 is an IEEE 754-2008 binary16 half precision floating point value,
+// as used by GPU vertex buffers and textures.
+type Float16 uint16
+
+// Float32ToFloat16 returns the half precision value nearest to the specified value, rounding ties to even.
+// Values too large for half precision become infinities, values too small become zeros or
+// subnormals, and NaNs stay NaNs keeping the high bits of their payload.
+func Float32ToFloat16(f float32) Float16 {
+
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int(bits>>23) & 0xff
+	mant := bits & 0x7fffff
+
+	// Infinities and NaNs
+	if exp == 0xff {
+		if mant == 0 {
+			return Float16(sign | 0x7c00)
+		}
+		// The quiet bit is set if the payload does not fit
+		payload := uint16(mant >> 13)
+		if payload == 0 {
+			payload = 0x200
+		}
+		return Float16(sign | 0x7c00 | payload)
+	}
+
+	e := exp - 127 + 15
+	if e >= 31 {
+		return Float16(sign | 0x7c00)
+	}
+	if e <= 0 {
+		// Subnormal or zero
+		if e < -10 {
+			return Float16(sign)
+		}
+		full := mant | 0x800000
+		shift := uint(14 - e)
+		h := full >> shift
+		rem := full & (1<<shift - 1)
+		halfway := uint32(1) << (shift - 1)
+		if rem > halfway || (rem == halfway && h&1 == 1) {
+			h++
+		}
+		return Float16(sign | uint16(h))
+	}
+
+	// Normal value. A carry of the rounding into the exponent gives the correct result, including infinity.
+	h := uint32(e)<<10 | mant>>13
+	rem := mant & 0x1fff
+	if rem > 0x1000 || (rem == 0x1000 && h&1 == 1) {
+		h++
+	}
+	return Float16(sign | uint16(h))
+}
+
+// Float16ToFloat32 returns the single precision value equal to the specified half precision value.
+func Float16ToFloat32(h Float16) float32 {
+
+	sign := uint32(h&0x8000) << 16
+	exp := int(h>>10) & 0x1f
+	mant := uint32(h & 0x3ff)
+	switch exp {
+	case 0:
+		if mant == 0 {
+			return math.Float32frombits(sign)
+		}
+		// Normalizes the subnormal value
+		e := -14
+		for mant&0x400 == 0 {
+			mant <<= 1
+			e--
+		}
+		mant &= 0x3ff
+		return math.Float32frombits(sign | uint32(e+127)<<23 | mant<<13)
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	}
+	return math.Float32frombits(sign | uint32(exp-15+127)<<23 | mant<<13)
+}
+
+// Float32 returns the single precision value equal to this half precision value.
+func (h Float16) Float32() float32 {
+
+	return Float16ToFloat32(h)
+}
+
+// IsNaN returns if this half precision value is not a number.
+func (h Float16) IsNaN() bool {
+
+	return h&0x7c00 == 0x7c00 && h&0x3ff != 0
+}
+
+// IsInf returns if this half precision value is an infinity.
+func (h Float16) IsInf() bool {
+
+	return h&0x7fff == 0x7c00
+}
+
+// Float16Slice is a slice of half precision values with batch conversions
+// from and to single precision values.
+type Float16Slice []Float16
+
+// SetFromFloat32Slice sets this slice to the half precision values nearest to the specified values,
+// reusing its memory if its capacity is enough.
+// The conversion gives the same results as Float32ToFloat16 with fewer branches per value.
+func (s *Float16Slice) SetFromFloat32Slice(data []float32) {
+
+	if cap(*s) < len(data) {
+		*s = make(Float16Slice, len(data))
+	}
+	*s = (*s)[:len(data)]
+	out := *s
+
+	const f16max = (127 + 16) << 23                        // smallest single precision value which overflows to infinity
+	const denormMagic = ((127 - 15) + (23 - 10) + 1) << 23 // aligns subnormal mantissas by a float addition
+	denorm := math.Float32frombits(denormMagic)
+	// Same length, so that the bounds checks are eliminated
+	data = data[:len(out)]
+	for i, f := range data {
+		bits := math.Float32bits(f)
+		sign := bits & 0x80000000
+		bits ^= sign
+		var h uint32
+		if bits-113<<23 < f16max-113<<23 {
+			// Rebias the exponent and round the mantissa to nearest even
+			odd := (bits >> 13) & 1
+			h = (bits - (127-15)<<23 + 0xfff + odd) >> 13
+		} else if bits < 113<<23 {
+			// The float addition rounds the subnormal mantissa to nearest even
+			h = math.Float32bits(math.Float32frombits(bits)+denorm) - denormMagic
+		} else {
+			// Overflow, infinity or NaN
+			h = 0x7c00
+			if bits > 0x7f800000 {
+				h = 0x7c00 | (bits>>13)&0x3ff
+				if h == 0x7c00 {
+					h = 0x7e00
+				}
+			}
+		}
+		out[i] = Float16(h | sign>>16)
+	}
+}
+
+// float16Tables contains the lookup tables of the half to single precision conversion
+// by mantissa, exponent and offset which avoids branches (J. van der Zijp, Fast Half Float Conversions).
+type float16Tables struct {
+	mantissa [2048]uint32
+	exponent [64]uint32
+	offset   [64]uint32
+}
+
+// f16Tables are the lookup tables used by Float16Slice.ToFloat32Slice.
+var f16Tables = newFloat16Tables()
+
+// newFloat16Tables creates and returns the half to single precision conversion lookup tables.
+func newFloat16Tables() *float16Tables {
+
+	t := new(float16Tables)
+	// Subnormal mantissas are normalized
+	for i := uint32(1); i < 1024; i++ {
+		m := i << 13
+		e := uint32(0)
+		for m&0x800000 == 0 {
+			e -= 0x800000
+			m <<= 1
+		}
+		t.mantissa[i] = (m &^ 0x800000) | (e + 0x38800000)
+	}
+	for i := uint32(1024); i < 2048; i++ {
+		t.mantissa[i] = 0x38000000 + (i-1024)<<13
+	}
+	for i := uint32(1); i < 31; i++ {
+		t.exponent[i] = i << 23
+		t.exponent[i+32] = 0x80000000 + i<<23
+	}
+	t.exponent[31] = 0x47800000
+	t.exponent[32] = 0x80000000
+	t.exponent[63] = 0xc7800000
+	for i := range t.offset {
+		t.offset[i] = 1024
+	}
+	t.offset[0] = 0
+	t.offset[32] = 0
+	return t
+}
+
+// ToFloat32Slice returns a new slice with the single precision values equal to the values of this slice.
+// The conversion gives the same results as Float16ToFloat32 without branches using lookup tables.
+func (s Float16Slice) ToFloat32Slice() []float32 {
+
+	out := make([]float32, len(s))
+	s.ToFloat32SliceInto(out)
+	return out
+}
+
+// ToFloat32SliceInto sets the specified slice to the single precision values equal to the values
+// of this slice, without allocating. Converts as many values as the shortest of the slices.
+func (s Float16Slice) ToFloat32SliceInto(dst []float32) {
+
+	if len(dst) > len(s) {
+		dst = dst[:len(s)]
+	}
+	// Same length, so that the bounds checks are eliminated
+	src := s[:len(dst)]
+	t := f16Tables
+	for i, h := range src {
+		e := h >> 10
+		dst[i] = math.Float32frombits(t.mantissa[t.offset[e]+uint32(h&0x3ff)] + t.exponent[e])
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// Test the round trip of every half precision value and the batch conversions
+func TestFloat16RoundTrip(t *testing.T) {
+
+	all := make(Float16Slice, 1<<16)
+	for i := range all {
+		all[i] = Float16(i)
+	}
+	singles := all.ToFloat32Slice()
+	var back Float16Slice
+	back.SetFromFloat32Slice(singles)
+	for i, h := range all {
+		f := Float16ToFloat32(h)
+		if math.Float32bits(f) != math.Float32bits(singles[i]) {
+			t.Fatalf("ToFloat32Slice of %#04x is %v instead of %v", i, singles[i], f)
+		}
+		if Float32ToFloat16(f) != h {
+			t.Fatalf("Float32ToFloat16 of %v is %#04x instead of %#04x", f, Float32ToFloat16(f), i)
+		}
+		if back[i] != h {
+			t.Fatalf("SetFromFloat32Slice of %v is %#04x instead of %#04x", f, back[i], i)
+		}
+		if h.IsNaN() != IsNaN(f) || h.IsInf() != math.IsInf(float64(f), 0) {
+			t.Fatalf("IsNaN or IsInf of %#04x failed", i)
+		}
+	}
+}
+
+// Test the conversion into existing slices shorter and longer than the half precision slice
+func TestFloat16SliceToFloat32SliceInto(t *testing.T) {
+
+	s := Float16Slice{0x3c00, 0x4000, 0xc200} // 1, 2, -3
+	short := make([]float32, 2)
+	s.ToFloat32SliceInto(short)
+	if short[0] != 1 || short[1] != 2 {
+		t.Error("short slice", short, "instead of [1 2]")
+	}
+	long := []float32{9, 9, 9, 9}
+	s.ToFloat32SliceInto(long)
+	if long[0] != 1 || long[1] != 2 || long[2] != -3 || long[3] != 9 {
+		t.Error("long slice", long, "instead of [1 2 -3 9]")
+	}
+}
+
+// Test the rounding of single precision values to the nearest half precision value, ties to even
+func TestFloat16Rounding(t *testing.T) {
+
+	cases := []struct {
+		f float32
+		h Float16
+	}{
+		{1, 0x3c00},
+		{-2, 0xc000},
+		{65504, 0x7bff},                         // largest normal
+		{65519.99, 0x7bff},                      // rounds down to the largest normal
+		{65520, 0x7c00},                         // rounds up to infinity
+		{float32(math.Inf(-1)), 0xfc00},         // infinity
+		{5.960464477539063e-08, 0x0001},         // smallest subnormal
+		{2.980232238769531e-08, 0x0000},         // half of the smallest subnormal ties to zero
+		{4.470348358154297e-08, 0x0001},         // above half of the smallest subnormal
+		{6.103515625e-05, 0x0400},               // smallest normal
+		{1 + 1.0/2048, 0x3c00},                  // tie rounds to even
+		{1 + 3.0/2048, 0x3c02},                  // tie rounds to even
+		{float32(math.Copysign(0, -1)), 0x8000}, // negative zero
+	}
+	for _, c := range cases {
+		if h := Float32ToFloat16(c.f); h != c.h {
+			t.Errorf("Float32ToFloat16(%v) is %#04x instead of %#04x", c.f, h, c.h)
+		}
+	}
+	if !Float32ToFloat16(float32(math.NaN())).IsNaN() {
+		t.Error("Float32ToFloat16 of NaN is not NaN")
+	}
+
+	// Random values, including ties, are converted to the nearest value by the scalar and batch conversions
+	rng := rand.New(rand.NewSource(1))
+	data := make([]float32, 1<<20)
+	for i := range data {
+		data[i] = math.Float32frombits(rng.Uint32())
+	}
+	for i := 0; i < 1000; i++ {
+		data[i] = math.Float32frombits(uint32(rng.Intn(1<<19))<<13 | 0x1000)
+	}
+	var out Float16Slice
+	out.SetFromFloat32Slice(data)
+	for i, f := range data {
+		h := Float32ToFloat16(f)
+		if h != out[i] {
+			t.Fatalf("SetFromFloat32Slice of %v is %#04x instead of %#04x", f, out[i], h)
+		}
+		if IsNaN(f) {
+			continue
+		}
+		// No neighbor is nearer than the converted value
+		d := math.Abs(float64(Float16ToFloat32(h)) - float64(f))
+		for _, n := range []Float16{h + 1, h - 1} {
+			v := float64(Float16ToFloat32(n))
+			if !math.IsNaN(v) && !math.IsInf(v, 0) && !math.IsInf(float64(h.Float32()), 0) && math.Abs(v-float64(f)) < d {
+				t.Fatalf("Float32ToFloat16 of %v is %#04x which is not the nearest", f, h)
+			}
+		}
+	}
+}
+
+// float16BenchData returns the values converted by the benchmarks.
+func float16BenchData() []float32 {
+
+	data := make([]float32, 4096)
+	for i := range data {
+		data[i] = float32(i)*0.37 - 700
+	}
+	return data
+}
+
+// Benchmark the scalar conversion to half precision, the baseline of the batch conversion
+func BenchmarkFloat32ToFloat16(b *testing.B) {
+
+	data := float16BenchData()
+	out := make([]Float16, len(data))
+	for n := 0; n < b.N; n++ {
+		for i, f := range data {
+			out[i] = Float32ToFloat16(f)
+		}
+	}
+}
+
+// Benchmark the batch conversion to half precision, at least twice as fast as the scalar conversion
+func BenchmarkFloat16SliceSetFromFloat32Slice(b *testing.B) {
+
+	data := float16BenchData()
+	var out Float16Slice
+	for n := 0; n < b.N; n++ {
+		out.SetFromFloat32Slice(data)
+	}
+}
+
+// Benchmark the scalar conversion to single precision, the baseline of the batch conversions
+func BenchmarkFloat16ToFloat32(b *testing.B) {
+
+	var s Float16Slice
+	s.SetFromFloat32Slice(float16BenchData())
+	out := make([]float32, len(s))
+	for n := 0; n < b.N; n++ {
+		for i, h := range s {
+			out[i] = Float16ToFloat32(h)
+		}
+	}
+}
+
+// benchFloat32s keeps the results of the benchmarks.
+var benchFloat32s []float32
+
+// Benchmark the batch conversion to single precision into a new slice, including its allocation
+func BenchmarkFloat16SliceToFloat32Slice(b *testing.B) {
+
+	var s Float16Slice
+	s.SetFromFloat32Slice(float16BenchData())
+	for n := 0; n < b.N; n++ {
+		benchFloat32s = s.ToFloat32Slice()
+	}
+}
+
+// Benchmark the batch conversion to single precision into an existing slice,
+// at least twice as fast as the scalar conversion
+func BenchmarkFloat16SliceToFloat32SliceInto(b *testing.B) {
+
+	var s Float16Slice
+	s.SetFromFloat32Slice(float16BenchData())
+	out := make([]float32, len(s))
+	for n := 0; n < b.N; n++ {
+		s.ToFloat32SliceInto(out)
+	}
+}
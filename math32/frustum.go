@@ -167,6 +167,117 @@ func (f *Frustum) IntersectsBox(box *Box3) bool {
 	return true
 }
 
+// IntersectsTriangle determines whether the specified triangle is intersecting the frustum, exactly,
+// by the separating axis test on the normals of the planes of the frustum and of the triangle
+// and on the cross products of the edges of the triangle with the edges of the frustum.
+// If the planes do not meet at eight corners only the planes of the frustum are tested.
+func (f *Frustum) IntersectsTriangle(tri *Triangle) bool {
+
+	vertices := [3]*Vector3{tri.A(), tri.B(), tri.C()}
+	for i := 0; i < 6; i++ {
+		plane := &f.planes[i]
+		if plane.DistanceToPoint(vertices[0]) < 0 && plane.DistanceToPoint(vertices[1]) < 0 &&
+			plane.DistanceToPoint(vertices[2]) < 0 {
+			return false
+		}
+	}
+	corners, ok := f.corners()
+	if !ok {
+		return true
+	}
+
+	// separated returns whether the projections of the triangle and of the frustum on the axis are disjoint
+	separated := func(axis *Vector3) bool {
+		triMin, triMax := Infinity, -Infinity
+		for _, v := range vertices {
+			d := axis.Dot(v)
+			triMin, triMax = Min(triMin, d), Max(triMax, d)
+		}
+		frMin, frMax := Infinity, -Infinity
+		for i := range corners {
+			d := axis.Dot(&corners[i])
+			frMin, frMax = Min(frMin, d), Max(frMax, d)
+		}
+		return triMax < frMin || frMax < triMin
+	}
+
+	var e0, e1, axis Vector3
+	e0.SubVectors(vertices[1], vertices[0])
+	e1.SubVectors(vertices[2], vertices[0])
+	if axis.CrossVectors(&e0, &e1); axis.LengthSq() > 0 && separated(&axis) {
+		return false
+	}
+	// The corners differing by one bit of their index are the ends of the 12 edges of the frustum
+	for i := 0; i < 3; i++ {
+		var edge Vector3
+		edge.SubVectors(vertices[(i+1)%3], vertices[i])
+		for c := 0; c < 8; c++ {
+			for bit := 1; bit < 8; bit <<= 1 {
+				if c&bit != 0 {
+					continue
+				}
+				var fe Vector3
+				fe.SubVectors(&corners[c|bit], &corners[c])
+				if axis.CrossVectors(&edge, &fe); axis.LengthSq() > 0 && separated(&axis) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// corners returns the eight corners of the frustum, intersections of one of each pair of opposite planes,
+// indexed by the sum of 1 for the left plane, 2 for the top plane and 4 for the near plane.
+// Returns false if three of the planes do not meet at a single point.
+func (f *Frustum) corners() ([8]Vector3, bool) {
+
+	var corners [8]Vector3
+	for i := range corners {
+		p1 := &f.planes[i&1]
+		p2 := &f.planes[2+(i>>1)&1]
+		p3 := &f.planes[4+(i>>2)&1]
+		var c23, c31, c12 Vector3
+		c23.CrossVectors(&p2.normal, &p3.normal)
+		c31.CrossVectors(&p3.normal, &p1.normal)
+		c12.CrossVectors(&p1.normal, &p2.normal)
+		det := p1.normal.Dot(&c23)
+		if Abs(det) < 1e-12 {
+			return corners, false
+		}
+		c23.MultiplyScalar(-p1.constant)
+		c31.MultiplyScalar(-p2.constant)
+		c12.MultiplyScalar(-p3.constant)
+		corners[i].AddVectors(&c23, &c31).Add(&c12).MultiplyScalar(1 / det)
+	}
+	return corners, true
+}
+
+// ProjectSphere determines whether the specified sphere is intersecting the frustum and
+// estimates the radius of its projection on the screen as a fraction of the screen height,
+// to be used for selecting levels of detail. The sphere must be in the camera (view) coordinates
+// of the specified projection matrix, from which the frustum is normally built.
+// The radius is computed from the half angle subtended by the sphere seen from the camera
+// and is infinite if the camera is inside the sphere.
+func (f *Frustum) ProjectSphere(sphere *Sphere, projectionMatrix *Matrix4) (screenRadius float32, visible bool) {
+
+	visible = f.IntersectsSphere(sphere)
+
+	// Orthographic projections do not depend on the distance
+	if projectionMatrix[11] == 0 {
+		return sphere.Radius * projectionMatrix[5] / 2, visible
+	}
+	dist2 := sphere.Center.LengthSq()
+	r2 := sphere.Radius * sphere.Radius
+	if dist2 <= r2 {
+		return Infinity, visible
+	}
+	// Tangent of the half angle: sin/cos with sin = r/d
+	tanHalf := sphere.Radius / Sqrt(dist2-r2)
+	// The projection scales the tangent of the view angle so that the screen half height is 1
+	return tanHalf * projectionMatrix[5] / 2, visible
+}
+
 // ContainsPoint determines whether the frustum contains the specified point
 func (f *Frustum) ContainsPoint(point *Vector3) bool {
 
@@ -0,0 +1,122 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import "testing"
+
+// Test the exact intersection of triangles with an orthographic and a perspective frustum
+func TestFrustumIntersectsTriangle(t *testing.T) {
+
+	ortho := NewFrustumFromMatrix(NewMatrix4().MakeOrthographic(-1, 1, 1, -1, -5, 5))
+	persp := NewFrustumFromMatrix(NewMatrix4().MakePerspective(90, 1, 1, 10))
+	cases := []struct {
+		name    string
+		frustum *Frustum
+		tri     *Triangle
+		result  bool
+	}{
+		{"inside", ortho, NewTriangle(NewVector3(0, 0, 0), NewVector3(0.5, 0, 0), NewVector3(0, 0.5, 0)), true},
+		{"outside right", ortho, NewTriangle(NewVector3(2, 0, 0), NewVector3(3, 0, 0), NewVector3(2, 1, 0)), false},
+		{"crossing", ortho, NewTriangle(NewVector3(-3, -3, 0), NewVector3(3, -3, 0), NewVector3(0, 3, 0)), true},
+		{"containing", ortho, NewTriangle(NewVector3(-9, -9, 1), NewVector3(9, -9, 1), NewVector3(0, 9, 1)), true},
+		// Outside the corner but not separated by one of the planes of the frustum
+		{"beyond corner", ortho, NewTriangle(NewVector3(1.5, 0.8, 0), NewVector3(0.8, 1.5, 0), NewVector3(1.6, 1.6, 0)), false},
+		{"perspective inside", persp, NewTriangle(NewVector3(-1, -1, -5), NewVector3(1, -1, -5), NewVector3(0, 1, -5)), true},
+		{"perspective behind", persp, NewTriangle(NewVector3(-1, -1, 1), NewVector3(1, -1, 1), NewVector3(0, 1, 1)), false},
+		// Beside the edge between the left and top planes, near the far plane
+		{"perspective beyond edge", persp, NewTriangle(NewVector3(-9.6, 8.6, -9), NewVector3(-8.6, 9.6, -9), NewVector3(-10, 10, -9.2)), false},
+	}
+	for _, c := range cases {
+		if c.frustum.IntersectsTriangle(c.tri) != c.result {
+			t.Error("IntersectsTriangle failed:", c.name)
+		}
+	}
+}
+
+// Test the projected radius of spheres with perspective and orthographic projections
+func TestFrustumProjectSphere(t *testing.T) {
+
+	// Field of view of 90 degrees: the screen half height is at the tangent 1
+	proj := NewMatrix4().MakePerspective(90, 1, 1, 100)
+	frustum := NewFrustumFromMatrix(proj)
+	cases := []struct {
+		sphere  Sphere
+		radius  float32
+		visible bool
+	}{
+		{Sphere{Center: Vector3{Z: -10}, Radius: 1}, 1 / Sqrt(99) / 2, true},
+		{Sphere{Center: Vector3{X: 3, Z: -50}, Radius: 2}, 2 / Sqrt(50*50+3*3-2*2) / 2, true},
+		{Sphere{Center: Vector3{Z: 10}, Radius: 1}, 1 / Sqrt(99) / 2, false},
+		{Sphere{Center: Vector3{Z: -1}, Radius: 2}, Infinity, true},
+	}
+	for _, c := range cases {
+		radius, visible := frustum.ProjectSphere(&c.sphere, proj)
+		if radius != c.radius && Abs(radius-c.radius) > 1e-6 || visible != c.visible {
+			t.Error("ProjectSphere of", c.sphere, "returned", radius, visible, "instead of", c.radius, c.visible)
+		}
+	}
+
+	// Far enough, the radius is the projection of a point at the top of the sphere
+	sphere := Sphere{Center: Vector3{Z: -80}, Radius: 0.5}
+	radius, _ := frustum.ProjectSphere(&sphere, proj)
+	top := Vector3{Y: 0.5, Z: -80}
+	top.ApplyProjection(proj)
+	if Abs(radius-top.Y/2) > 1e-3*radius {
+		t.Error("ProjectSphere radius", radius, "instead of about", top.Y/2)
+	}
+
+	// The orthographic radius does not depend on the distance
+	ortho := NewMatrix4().MakeOrthographic(-1, 1, 1, -1, 0, 100)
+	frustum = NewFrustumFromMatrix(ortho)
+	for _, z := range []float32{-1, -50} {
+		sphere := Sphere{Center: Vector3{Z: z}, Radius: 0.5}
+		if radius, visible := frustum.ProjectSphere(&sphere, ortho); radius != 0.25 || !visible {
+			t.Error("orthographic ProjectSphere returned", radius, visible, "instead of 0.25")
+		}
+	}
+}
+
+// frustumSink keeps the results of the benchmarks.
+var frustumSink float32
+
+// Benchmark the projected radius of a sphere
+func BenchmarkFrustumProjectSphere(b *testing.B) {
+
+	proj := NewMatrix4().MakePerspective(60, 1.5, 0.1, 1000)
+	frustum := NewFrustumFromMatrix(proj)
+	sphere := Sphere{Center: Vector3{X: 3, Y: -2, Z: -40}, Radius: 2}
+	var sum float32
+	for i := 0; i < b.N; i++ {
+		r, _ := frustum.ProjectSphere(&sphere, proj)
+		sum += r
+	}
+	frustumSink = sum
+}
+
+// Benchmark the projected radius of a sphere estimated from the NDC transform of the extremes of its axes
+// and of the corners of its bounding box, to compare with BenchmarkFrustumProjectSphere
+func BenchmarkFrustumProjectSpherePoints(b *testing.B) {
+
+	proj := NewMatrix4().MakePerspective(60, 1.5, 0.1, 1000)
+	frustum := NewFrustumFromMatrix(proj)
+	sphere := Sphere{Center: Vector3{X: 3, Y: -2, Z: -40}, Radius: 2}
+	offsets := []Vector3{{X: 1}, {X: -1}, {Y: 1}, {Y: -1}, {Z: 1}, {Z: -1}}
+	for i := 0; i < 8; i++ {
+		offsets = append(offsets, Vector3{X: float32(i&1*2 - 1), Y: float32(i>>1&1*2 - 1), Z: float32(i>>2&1*2 - 1)})
+	}
+	var sum float32
+	for i := 0; i < b.N; i++ {
+		frustum.IntersectsSphere(&sphere)
+		minY, maxY := Infinity, -Infinity
+		for k := range offsets {
+			p := offsets[k]
+			p.MultiplyScalar(sphere.Radius).Add(&sphere.Center).ApplyProjection(proj)
+			minY = Min(minY, p.Y)
+			maxY = Max(maxY, p.Y)
+		}
+		sum += (maxY - minY) / 4
+	}
+	frustumSink = sum
+}
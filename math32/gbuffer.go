@@ -0,0 +1,84 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+// GBuffer is the layout of the per pixel material data written to the render targets
+// of a deferred renderer. Each field corresponds to an RGBA render target.
+type GBuffer struct {
+	AlbedoMetallic  [4]float32 // albedo color (RGB) and metallic factor (A)
+	NormalRoughness [4]float32 // octahedral encoded normal (RG), roughness (B) and unused (A)
+	EmissiveAO      [4]float32 // emissive color (RGB) and ambient occlusion (A)
+}
+
+// SetNormal sets the octahedral encoded normal of this G-buffer texel from the specified unit normal.
+// Returns pointer to this updated G-buffer texel.
+func (gb *GBuffer) SetNormal(n *Vector3) *GBuffer {
+
+	e := EncodeNormal(n)
+	gb.NormalRoughness[0] = e[0]
+	gb.NormalRoughness[1] = e[1]
+	return gb
+}
+
+// Normal returns the unit normal decoded from this G-buffer texel.
+func (gb *GBuffer) Normal() Vector3 {
+
+	return DecodeNormal([2]float32{gb.NormalRoughness[0], gb.NormalRoughness[1]})
+}
+
+// signNotZero returns 1 for non negative values and -1 for negative values.
+func signNotZero(v float32) float32 {
+
+	if v < 0 {
+		return -1
+	}
+	return 1
+}
+
+// EncodeNormal returns the octahedral encoding in [-1,1]x[-1,1] of the specified unit normal.
+// The normal is projected on the octahedron |x|+|y|+|z| = 1 whose lower half is folded over the upper half.
+func EncodeNormal(n *Vector3) [2]float32 {
+
+	l1 := Abs(n.X) + Abs(n.Y) + Abs(n.Z)
+	if l1 == 0 {
+		return [2]float32{0, 0}
+	}
+	x := n.X / l1
+	y := n.Y / l1
+	if n.Z < 0 {
+		x, y = (1-Abs(y))*signNotZero(x), (1-Abs(x))*signNotZero(y)
+	}
+	return [2]float32{x, y}
+}
+
+// DecodeNormal returns the unit normal from the specified octahedral encoding.
+func DecodeNormal(encoded [2]float32) Vector3 {
+
+	x := encoded[0]
+	y := encoded[1]
+	z := 1 - Abs(x) - Abs(y)
+	if z < 0 {
+		x, y = (1-Abs(y))*signNotZero(x), (1-Abs(x))*signNotZero(y)
+	}
+	n := Vector3{X: x, Y: y, Z: z}
+	n.Normalize()
+	return n
+}
+
+// PackMetallicRoughness returns the specified metallic and roughness factors clamped
+// to [0,1] and quantized as 16 bit unsigned normalized values.
+func PackMetallicRoughness(metallic, roughness float32) [2]uint16 {
+
+	return [2]uint16{
+		uint16(Clamp(metallic, 0, 1)*65535 + 0.5),
+		uint16(Clamp(roughness, 0, 1)*65535 + 0.5),
+	}
+}
+
+// UnpackMetallicRoughness returns the metallic and roughness factors from the specified packed values.
+func UnpackMetallicRoughness(packed [2]uint16) (metallic, roughness float32) {
+
+	return float32(packed[0]) / 65535, float32(packed[1]) / 65535
+}
@@ -0,0 +1,51 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Test that the octahedral encoding and decoding of random and axis normals has an angular error below 0.001
+func TestGBufferNormalEncoding(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(1))
+	normals := []Vector3{{X: 1}, {X: -1}, {Y: 1}, {Y: -1}, {Z: 1}, {Z: -1}}
+	for len(normals) < 100000 {
+		n := Vector3{X: rng.Float32()*2 - 1, Y: rng.Float32()*2 - 1, Z: rng.Float32()*2 - 1}
+		if l := n.Length(); l > 0.1 && l <= 1 {
+			normals = append(normals, *n.Normalize())
+		}
+	}
+	var gb GBuffer
+	for _, n := range normals {
+		e := EncodeNormal(&n)
+		if Abs(e[0]) > 1 || Abs(e[1]) > 1 {
+			t.Fatal("encoding", e, "of", n, "outside [-1,1]")
+		}
+		d := gb.SetNormal(&n).Normal()
+		var cross Vector3
+		cross.CrossVectors(&n, &d)
+		if angle := Atan2(cross.Length(), n.Dot(&d)); angle > 0.001 || Abs(d.Length()-1) > 1e-5 {
+			t.Fatal("normal", n, "decoded as", d, "with the angle", angle)
+		}
+	}
+}
+
+// Test the quantization and clamping of the metallic and roughness factors
+func TestGBufferMetallicRoughness(t *testing.T) {
+
+	packed := PackMetallicRoughness(0.3, 1.2)
+	if packed != [2]uint16{19661, 65535} {
+		t.Error("packed factors", packed, "instead of 19661 and 65535")
+	}
+	if m, r := UnpackMetallicRoughness(packed); Abs(m-0.3) > 1.0/65535 || r != 1 {
+		t.Error("unpacked factors", m, r, "instead of 0.3 and 1")
+	}
+	if m, r := UnpackMetallicRoughness(PackMetallicRoughness(-1, 0)); m != 0 || r != 0 {
+		t.Error("unpacked factors", m, r, "instead of 0 and 0")
+	}
+}
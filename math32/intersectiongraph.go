@@ -0,0 +1,704 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+// intersectionBruteForce is the number of segments below which the intersections are found by testing all pairs.
+const intersectionBruteForce = 100
+
+// SegmentIntersection is a point shared by two segments of an IntersectionGraph.
+type SegmentIntersection struct {
+	A     int     // index of the first segment
+	B     int     // index of the second segment, greater than A
+	Point Vector3 // intersection point with the Z coordinate of the first segment
+}
+
+// IntersectionGraph contains all the intersections among a set of line segments in the XY plane.
+// The Z coordinates of the segments are ignored to find the intersections.
+// Collinear overlapping segments are reported once, at the first point of their overlap.
+// The intersections are found with the Bentley-Ottmann sweep line algorithm in O((n+k) log n) time,
+// or by testing all pairs of segments for small inputs and when points nearly concurrent within
+// rounding errors make the order of the segments on the sweep line ambiguous.
+type IntersectionGraph struct {
+	segments      []Line3
+	intersections []SegmentIntersection
+}
+
+// NewIntersectionGraph creates and returns a pointer to a new IntersectionGraph with copies
+// of the specified segments, computing all their intersections.
+func NewIntersectionGraph(segments []Line3) *IntersectionGraph {
+
+	ig := new(IntersectionGraph)
+	ig.segments = make([]Line3, len(segments))
+	copy(ig.segments, segments)
+	segs := newSweepSegments(ig.segments)
+	ok := false
+	if len(segments) >= intersectionBruteForce {
+		ig.intersections, ok = sweepIntersections(segs)
+	}
+	if !ok {
+		ig.intersections = bruteForceIntersections(segs)
+	}
+	for i := range ig.intersections {
+		ig.intersections[i].Point.Z = segs[ig.intersections[i].A].zAt(&ig.intersections[i].Point)
+	}
+	sort.Slice(ig.intersections, func(i, j int) bool {
+		a, b := &ig.intersections[i], &ig.intersections[j]
+		return a.A < b.A || (a.A == b.A && a.B < b.B)
+	})
+	return ig
+}
+
+// Segments returns the segments of this graph.
+func (ig *IntersectionGraph) Segments() []Line3 {
+
+	return ig.segments
+}
+
+// Intersections returns all the intersections among the segments ordered by segment indices.
+func (ig *IntersectionGraph) Intersections() []SegmentIntersection {
+
+	return ig.intersections
+}
+
+// sweepPoint is a point in the XY plane in double precision.
+type sweepPoint struct {
+	x, y float64
+}
+
+// before returns if this point is processed before the specified point by the sweep line,
+// which moves along +X and then along +Y.
+func (p sweepPoint) before(q sweepPoint) bool {
+
+	return p.x < q.x || (p.x == q.x && p.y < q.y)
+}
+
+// sweepSegment is a segment with its end points ordered as processed by the sweep line.
+type sweepSegment struct {
+	p0, p1   sweepPoint // first and last end points
+	z0, z1   float32    // Z coordinates of the end points
+	eps      float64    // distance tolerance
+	vertical bool
+}
+
+// newSweepSegments returns the sweep segments of the specified segments.
+func newSweepSegments(segments []Line3) []sweepSegment {
+
+	segs := make([]sweepSegment, len(segments))
+	var extent float64
+	for i := range segments {
+		s := &segs[i]
+		l := &segments[i]
+		s.p0 = sweepPoint{float64(l.start.X), float64(l.start.Y)}
+		s.p1 = sweepPoint{float64(l.end.X), float64(l.end.Y)}
+		s.z0, s.z1 = l.start.Z, l.end.Z
+		if s.p1.before(s.p0) {
+			s.p0, s.p1 = s.p1, s.p0
+			s.z0, s.z1 = s.z1, s.z0
+		}
+		s.vertical = s.p0.x == s.p1.x
+		extent = math.Max(extent, math.Max(math.Abs(s.p0.x), math.Abs(s.p0.y)))
+		extent = math.Max(extent, math.Max(math.Abs(s.p1.x), math.Abs(s.p1.y)))
+	}
+	// Tolerance relative to the single precision of the input
+	eps := math.Max(extent, 1) * 1e-6
+	for i := range segs {
+		segs[i].eps = eps
+	}
+	return segs
+}
+
+// yAt returns the Y coordinate of the segment at the specified X coordinate,
+// or the specified Y coordinate clamped to the segment if it is vertical.
+func (s *sweepSegment) yAt(x, y float64) float64 {
+
+	if s.vertical {
+		return math.Min(math.Max(y, s.p0.y), s.p1.y)
+	}
+	return s.p0.y + (x-s.p0.x)*(s.p1.y-s.p0.y)/(s.p1.x-s.p0.x)
+}
+
+// slope returns the slope of the segment, which is infinite if it is vertical.
+func (s *sweepSegment) slope() float64 {
+
+	if s.vertical {
+		return math.Inf(1)
+	}
+	return (s.p1.y - s.p0.y) / (s.p1.x - s.p0.x)
+}
+
+// contains returns if the specified point is within the tolerance along both X and Y
+// of a point of the segment.
+func (s *sweepSegment) contains(p sweepPoint) bool {
+
+	if s.vertical {
+		return math.Abs(p.x-s.p0.x) <= s.eps && p.y >= s.p0.y-s.eps && p.y <= s.p1.y+s.eps
+	}
+	if p.x < s.p0.x-s.eps || p.x > s.p1.x+s.eps {
+		return false
+	}
+	// Y range of the segment within the tolerance of the X coordinate
+	x0 := math.Max(p.x-s.eps, s.p0.x)
+	x1 := math.Min(p.x+s.eps, s.p1.x)
+	y0, y1 := s.yAt(x0, p.y), s.yAt(x1, p.y)
+	return p.y >= math.Min(y0, y1)-s.eps && p.y <= math.Max(y0, y1)+s.eps
+}
+
+// zAt returns the Z coordinate of the segment at the specified point projected on it.
+func (s *sweepSegment) zAt(p *Vector3) float32 {
+
+	dx, dy := s.p1.x-s.p0.x, s.p1.y-s.p0.y
+	lsq := dx*dx + dy*dy
+	if lsq == 0 {
+		return s.z0
+	}
+	t := math.Min(math.Max(((float64(p.X)-s.p0.x)*dx+(float64(p.Y)-s.p0.y)*dy)/lsq, 0), 1)
+	return s.z0 + float32(t)*(s.z1-s.z0)
+}
+
+// sweepIntersect returns the intersection point of the two specified segments, which for collinear
+// overlapping segments is the first point of the overlap processed by the sweep line.
+func sweepIntersect(a, b *sweepSegment) (sweepPoint, bool) {
+
+	rx, ry := a.p1.x-a.p0.x, a.p1.y-a.p0.y
+	sx, sy := b.p1.x-b.p0.x, b.p1.y-b.p0.y
+	qx, qy := b.p0.x-a.p0.x, b.p0.y-a.p0.y
+	rxs := rx*sy - ry*sx
+	rr := rx*rx + ry*ry
+	ss := sx*sx + sy*sy
+	if rxs*rxs > 1e-18*rr*ss && rr > 0 && ss > 0 {
+		t := (qx*sy - qy*sx) / rxs
+		u := (qx*ry - qy*rx) / rxs
+		if t < 0 || t > 1 || u < 0 || u > 1 {
+			return sweepPoint{}, false
+		}
+		// The point is kept exactly on vertical and horizontal segments,
+		// so that it is processed in order with their other events
+		ip := sweepPoint{a.p0.x + t*rx, a.p0.y + t*ry}
+		if a.vertical || b.vertical {
+			ip.x = b.p0.x
+			if a.vertical {
+				ip.x = a.p0.x
+			}
+		}
+		if a.p0.y == a.p1.y {
+			ip.y = a.p0.y
+		} else if b.p0.y == b.p1.y {
+			ip.y = b.p0.y
+		}
+		return ip, true
+	}
+	// Parallel and degenerate segments intersect if an end point of one is on the other
+	var first sweepPoint
+	found := false
+	for _, c := range [4]struct {
+		p   sweepPoint
+		seg *sweepSegment
+	}{{a.p0, b}, {a.p1, b}, {b.p0, a}, {b.p1, a}} {
+		if c.seg.contains(c.p) && (!found || c.p.before(first)) {
+			first = c.p
+			found = true
+		}
+	}
+	return first, found
+}
+
+// bruteForceIntersections returns the intersections of the specified segments by testing all pairs.
+func bruteForceIntersections(segs []sweepSegment) []SegmentIntersection {
+
+	var result []SegmentIntersection
+	for i := range segs {
+		for j := i + 1; j < len(segs); j++ {
+			if p, ok := sweepIntersect(&segs[i], &segs[j]); ok {
+				result = append(result, SegmentIntersection{A: i, B: j, Point: Vector3{X: float32(p.x), Y: float32(p.y)}})
+			}
+		}
+	}
+	return result
+}
+
+// sweepEvent is a point processed by the sweep line with the segments starting and ending at it.
+type sweepEvent struct {
+	p      sweepPoint
+	starts []int
+	ends   []int
+	pairs  [][2]int // pairs of segments intersecting at the point
+	index  int      // position in the queue
+	done   bool     // the event was processed
+}
+
+// eventQueue is the priority queue of the events ordered as processed by the sweep line.
+// The events are also indexed by cells of twice the size of the tolerance,
+// to find the pending events near a point.
+type eventQueue struct {
+	events []*sweepEvent
+	eps    float64
+	cells  map[[2]int64][]*sweepEvent
+}
+
+func (q *eventQueue) Len() int           { return len(q.events) }
+func (q *eventQueue) Less(i, j int) bool { return q.events[i].p.before(q.events[j].p) }
+func (q *eventQueue) Swap(i, j int) {
+	q.events[i], q.events[j] = q.events[j], q.events[i]
+	q.events[i].index = i
+	q.events[j].index = j
+}
+func (q *eventQueue) Push(x interface{}) {
+	e := x.(*sweepEvent)
+	e.index = len(q.events)
+	q.events = append(q.events, e)
+}
+func (q *eventQueue) Pop() interface{} {
+	e := q.events[len(q.events)-1]
+	q.events = q.events[:len(q.events)-1]
+	e.done = true
+	return e
+}
+
+// cell returns the cell containing the specified point.
+func (q *eventQueue) cell(p sweepPoint) [2]int64 {
+
+	return [2]int64{int64(math.Floor(p.x / (2 * q.eps))), int64(math.Floor(p.y / (2 * q.eps)))}
+}
+
+// near returns a pending event other than the specified one within the tolerance
+// of the specified point along both axes, or nil.
+func (q *eventQueue) near(p sweepPoint, other *sweepEvent) *sweepEvent {
+
+	c0 := q.cell(sweepPoint{p.x - q.eps, p.y - q.eps})
+	c1 := q.cell(sweepPoint{p.x + q.eps, p.y + q.eps})
+	for cx := c0[0]; cx <= c1[0]; cx++ {
+		for cy := c0[1]; cy <= c1[1]; cy++ {
+			for _, e := range q.cells[[2]int64{cx, cy}] {
+				if e != other && !e.done && math.Abs(e.p.x-p.x) <= q.eps && math.Abs(e.p.y-p.y) <= q.eps {
+					return e
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// newEventQueue returns the queue of the events at the end points of the specified segments.
+// Returns false if distinct end points are within the tolerance.
+func newEventQueue(segs []sweepSegment) (*eventQueue, bool) {
+
+	type endPoint struct {
+		p     sweepPoint
+		seg   int
+		start bool
+	}
+	points := make([]endPoint, 0, 2*len(segs))
+	for i := range segs {
+		points = append(points, endPoint{segs[i].p0, i, true}, endPoint{segs[i].p1, i, false})
+	}
+	sort.Slice(points, func(i, j int) bool {
+		a, b := &points[i], &points[j]
+		return a.p.before(b.p) || (a.p == b.p && a.seg < b.seg)
+	})
+
+	// The events are created in order, so they are already a heap
+	q := &eventQueue{eps: segs[0].eps, cells: make(map[[2]int64][]*sweepEvent)}
+	var e *sweepEvent
+	for _, ep := range points {
+		if e == nil || ep.p != e.p {
+			if q.near(ep.p, nil) != nil {
+				return nil, false
+			}
+			e = &sweepEvent{p: ep.p, index: len(q.events)}
+			q.events = append(q.events, e)
+			c := q.cell(e.p)
+			q.cells[c] = append(q.cells[c], e)
+		}
+		if ep.start {
+			e.starts = append(e.starts, ep.seg)
+		} else {
+			e.ends = append(e.ends, ep.seg)
+		}
+	}
+	return q, true
+}
+
+// addNear adds the specified pair of segments intersecting at the specified point to a pending event
+// within the tolerance of the point, so that nearly concurrent intersections are processed together,
+// or to a new event at the point if there is none. An event without end points is moved to the point
+// if it is processed first and no other pending event is near it.
+func (q *eventQueue) addNear(p sweepPoint, pair [2]int) {
+
+	e := q.near(p, nil)
+	if e == nil {
+		e = q.push(p)
+	} else if p.before(e.p) && len(e.starts) == 0 && len(e.ends) == 0 && q.near(p, e) == nil {
+		q.move(e, p)
+	}
+	e.pairs = append(e.pairs, pair)
+}
+
+// move moves the specified pending event to the specified point.
+func (q *eventQueue) move(e *sweepEvent, p sweepPoint) {
+
+	c := q.cell(e.p)
+	cell := q.cells[c]
+	for i := range cell {
+		if cell[i] == e {
+			cell[i] = cell[len(cell)-1]
+			q.cells[c] = cell[:len(cell)-1]
+			break
+		}
+	}
+	e.p = p
+	heap.Fix(q, e.index)
+	c = q.cell(p)
+	q.cells[c] = append(q.cells[c], e)
+}
+
+// push adds a new event at the specified point.
+func (q *eventQueue) push(p sweepPoint) *sweepEvent {
+
+	e := &sweepEvent{p: p}
+	heap.Push(q, e)
+	c := q.cell(p)
+	q.cells[c] = append(q.cells[c], e)
+	return e
+}
+
+// sweepNode is a node of the treap of the segments crossing the sweep line,
+// ordered by position in the tree and heap ordered by priority.
+type sweepNode struct {
+	seg                 int
+	priority            uint32
+	left, right, parent *sweepNode
+}
+
+// next returns the node after this one in the order of the sweep line, or nil.
+func (n *sweepNode) next() *sweepNode {
+
+	if n.right != nil {
+		n = n.right
+		for n.left != nil {
+			n = n.left
+		}
+		return n
+	}
+	for n.parent != nil && n.parent.right == n {
+		n = n.parent
+	}
+	return n.parent
+}
+
+// prev returns the node before this one in the order of the sweep line, or nil.
+func (n *sweepNode) prev() *sweepNode {
+
+	if n.left != nil {
+		n = n.left
+		for n.right != nil {
+			n = n.right
+		}
+		return n
+	}
+	for n.parent != nil && n.parent.left == n {
+		n = n.parent
+	}
+	return n.parent
+}
+
+// sweepLine contains the segments crossing the sweep line ordered by their Y coordinate
+// and the intersections found.
+type sweepLine struct {
+	segs     []sweepSegment
+	root     *sweepNode   // treap of the segments ordered from bottom to top
+	nodes    []*sweepNode // node of each segment crossing the sweep line or nil
+	seed     uint32       // state of the generator of the node priorities
+	ending   []int        // number of the last event at which each segment ends
+	reported map[[2]int]bool
+	result   []SegmentIntersection
+}
+
+// sweepIntersections returns the intersections of the specified segments
+// using the Bentley-Ottmann algorithm with the degenerate cases handling of de Berg et al.
+// Returns false if points nearly concurrent within the tolerance make the order of the segments
+// on the sweep line ambiguous, in which case the segments must be tested by pairs.
+func sweepIntersections(segs []sweepSegment) ([]SegmentIntersection, bool) {
+
+	if len(segs) == 0 {
+		return nil, true
+	}
+	// Distinct end points within the tolerance may miss the intersections of their segments.
+	// The intersections are added to the pending events near them, so no other events are that near.
+	q, ok := newEventQueue(segs)
+	if !ok {
+		return nil, false
+	}
+	sl := &sweepLine{
+		segs:     segs,
+		nodes:    make([]*sweepNode, len(segs)),
+		seed:     1,
+		ending:   make([]int, len(segs)),
+		reported: make(map[[2]int]bool),
+	}
+	var involved, inserted []int
+	for event := 1; q.Len() > 0; event++ {
+		e := heap.Pop(q).(*sweepEvent)
+		p := e.p
+
+		// Active segments containing the point, between the nodes below and above them,
+		// which must include the active segments ending at the point
+		below, above, ok := sl.containing(p)
+		if !ok {
+			return nil, false
+		}
+		ends := 0
+		for _, si := range e.ends {
+			sl.ending[si] = event
+			if sl.nodes[si] != nil {
+				ends++
+			}
+		}
+		involved = involved[:0]
+		inserted = inserted[:0]
+		for n := sl.after(below); n != above; n = n.next() {
+			involved = append(involved, n.seg)
+			if sl.ending[n.seg] == event {
+				ends--
+			} else {
+				inserted = append(inserted, n.seg)
+			}
+		}
+		if ends != 0 {
+			return nil, false
+		}
+		involved = append(involved, e.starts...)
+		for i := 0; i < len(involved); i++ {
+			for j := i + 1; j < len(involved); j++ {
+				sl.report(involved[i], involved[j])
+			}
+		}
+		// The segments of the intersections snapped to the event must contain its point
+		for _, pair := range e.pairs {
+			if !sl.reported[pair] {
+				return nil, false
+			}
+		}
+
+		// Removes the segments ending at the point and reinserts the segments continuing after it
+		// ordered by slope, which reverses the order of the segments crossing at the point.
+		// Parallel segments within the tolerance of the point are kept ordered by their Y coordinate.
+		for _, si := range involved[:len(involved)-len(e.starts)] {
+			sl.remove(sl.nodes[si])
+			sl.nodes[si] = nil
+		}
+		for _, si := range e.starts {
+			if sl.ending[si] != event {
+				inserted = append(inserted, si)
+			}
+		}
+		sort.SliceStable(inserted, func(i, j int) bool {
+			a, b := &segs[inserted[i]], &segs[inserted[j]]
+			sa, sb := a.slope(), b.slope()
+			return sa < sb || (sa == sb && a.yAt(p.x, p.y) < b.yAt(p.x, p.y))
+		})
+		last := below
+		for _, si := range inserted {
+			last = sl.insertAfter(last, si)
+			sl.nodes[si] = last
+		}
+
+		// Checks the new neighbors for intersections after the point
+		if len(inserted) > 0 {
+			ok = sl.checkEvent(q, below, sl.nodes[inserted[0]], p) && sl.checkEvent(q, last, above, p)
+		} else {
+			ok = sl.checkEvent(q, below, above, p)
+		}
+		if !ok {
+			return nil, false
+		}
+	}
+	return sl.result, true
+}
+
+// report adds the intersection of the specified segments if they intersect and it was not already reported.
+func (sl *sweepLine) report(a, b int) {
+
+	if a > b {
+		a, b = b, a
+	}
+	if sl.reported[[2]int{a, b}] {
+		return
+	}
+	// Segments containing the same point within the tolerance may not intersect
+	ip, ok := sweepIntersect(&sl.segs[a], &sl.segs[b])
+	if !ok {
+		return
+	}
+	sl.reported[[2]int{a, b}] = true
+	sl.result = append(sl.result, SegmentIntersection{A: a, B: b, Point: Vector3{X: float32(ip.x), Y: float32(ip.y)}})
+}
+
+// containing returns the nodes below and above the active segments which contain the specified point,
+// or nil at the bottom and top of the sweep line. Returns false if the segments containing the point
+// are not contiguous.
+func (sl *sweepLine) containing(p sweepPoint) (*sweepNode, *sweepNode, bool) {
+
+	// First active segment above the point, from which the range is extended in both directions
+	var above, below *sweepNode
+	for n := sl.root; n != nil; {
+		if sl.segs[n.seg].yAt(p.x, p.y) > p.y {
+			above = n
+			n = n.left
+		} else {
+			below = n
+			n = n.right
+		}
+	}
+	for below != nil && sl.segs[below.seg].contains(p) {
+		below = below.prev()
+	}
+	for above != nil && sl.segs[above.seg].contains(p) {
+		above = above.next()
+	}
+	if below != nil {
+		if n := below.prev(); n != nil && sl.segs[n.seg].contains(p) {
+			return nil, nil, false
+		}
+	}
+	if above != nil {
+		if n := above.next(); n != nil && sl.segs[n.seg].contains(p) {
+			return nil, nil, false
+		}
+	}
+	return below, above, true
+}
+
+// checkEvent adds an event for the intersection of the specified neighbor segments, if the intersection
+// is after the specified point and was not already reported. Returns false if an intersection not
+// reported is not after the point, which was missed by rounding errors.
+func (sl *sweepLine) checkEvent(q *eventQueue, a, b *sweepNode, p sweepPoint) bool {
+
+	if a == nil || b == nil {
+		return true
+	}
+	key := [2]int{a.seg, b.seg}
+	if key[0] > key[1] {
+		key[0], key[1] = key[1], key[0]
+	}
+	ip, ok := sweepIntersect(&sl.segs[a.seg], &sl.segs[b.seg])
+	if !ok || sl.reported[key] {
+		return true
+	}
+	if !p.before(ip) {
+		return false
+	}
+	q.addNear(ip, key)
+	return true
+}
+
+// after returns the node after the specified one, or the first node if nil.
+func (sl *sweepLine) after(n *sweepNode) *sweepNode {
+
+	if n != nil {
+		return n.next()
+	}
+	n = sl.root
+	for n != nil && n.left != nil {
+		n = n.left
+	}
+	return n
+}
+
+// insertAfter inserts the specified segment after the specified node, or first if nil, and returns its node.
+func (sl *sweepLine) insertAfter(prev *sweepNode, seg int) *sweepNode {
+
+	// Priorities from a xorshift generator
+	sl.seed ^= sl.seed << 13
+	sl.seed ^= sl.seed >> 17
+	sl.seed ^= sl.seed << 5
+	n := &sweepNode{seg: seg, priority: sl.seed}
+	switch {
+	case sl.root == nil:
+		sl.root = n
+		return n
+	case prev == nil:
+		parent := sl.root
+		for parent.left != nil {
+			parent = parent.left
+		}
+		parent.left = n
+		n.parent = parent
+	case prev.right == nil:
+		prev.right = n
+		n.parent = prev
+	default:
+		parent := prev.right
+		for parent.left != nil {
+			parent = parent.left
+		}
+		parent.left = n
+		n.parent = parent
+	}
+	for n.parent != nil && n.priority < n.parent.priority {
+		sl.rotateUp(n)
+	}
+	return n
+}
+
+// remove removes the specified node, rotating it down to a leaf.
+func (sl *sweepLine) remove(n *sweepNode) {
+
+	for n.left != nil && n.right != nil {
+		if n.left.priority < n.right.priority {
+			sl.rotateUp(n.left)
+		} else {
+			sl.rotateUp(n.right)
+		}
+	}
+	child := n.left
+	if child == nil {
+		child = n.right
+	}
+	if child != nil {
+		child.parent = n.parent
+	}
+	sl.replaceChild(n.parent, n, child)
+}
+
+// rotateUp rotates the specified node above its parent, keeping the order of the nodes.
+func (sl *sweepLine) rotateUp(n *sweepNode) {
+
+	parent := n.parent
+	if parent.left == n {
+		parent.left = n.right
+		if n.right != nil {
+			n.right.parent = parent
+		}
+		n.right = parent
+	} else {
+		parent.right = n.left
+		if n.left != nil {
+			n.left.parent = parent
+		}
+		n.left = parent
+	}
+	n.parent = parent.parent
+	parent.parent = n
+	sl.replaceChild(n.parent, parent, n)
+}
+
+// replaceChild replaces the specified child of the specified parent, or the root if nil.
+func (sl *sweepLine) replaceChild(parent, child, n *sweepNode) {
+
+	switch {
+	case parent == nil:
+		sl.root = n
+	case parent.left == child:
+		parent.left = n
+	default:
+		parent.right = n
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// checkSweepIntersections checks that the sweep line finds the same intersections as the brute force.
+func checkSweepIntersections(t *testing.T, segments []Line3) {
+
+	t.Helper()
+	segs := newSweepSegments(segments)
+	expected := make(map[[2]int]Vector3)
+	for _, x := range bruteForceIntersections(segs) {
+		expected[[2]int{x.A, x.B}] = x.Point
+	}
+	found, ok := sweepIntersections(segs)
+	if !ok {
+		t.Error("sweep line found nearly concurrent points in", len(segments), "segments")
+		return
+	}
+	if len(found) != len(expected) {
+		t.Error("sweep line found", len(found), "intersections instead of", len(expected))
+	}
+	for _, x := range found {
+		p, ok := expected[[2]int{x.A, x.B}]
+		if !ok {
+			t.Error("intersection", x, "not found by the brute force")
+		} else if p.DistanceTo(&x.Point) > 1e-3 {
+			t.Error("intersection of", x.A, x.B, "at", x.Point, "instead of", p)
+		}
+	}
+}
+
+// Test the sweep line against the brute force with random segments
+func TestIntersectionGraphRandom(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(3))
+	for i := 0; i < 20; i++ {
+		segments := make([]Line3, 50+rng.Intn(300))
+		for j := range segments {
+			a := Vector3{X: rng.Float32() * 100, Y: rng.Float32() * 100, Z: rng.Float32()}
+			b := Vector3{X: a.X + (rng.Float32()-0.5)*40, Y: a.Y + (rng.Float32()-0.5)*40, Z: rng.Float32()}
+			segments[j] = *NewLine3(&a, &b)
+		}
+		checkSweepIntersections(t, segments)
+	}
+}
+
+// Test the sweep line against the brute force with segments on a grid with shared endpoints,
+// vertical, horizontal and collinear segments
+func TestIntersectionGraphGrid(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(5))
+	for i := 0; i < 20; i++ {
+		segments := make([]Line3, 50+rng.Intn(200))
+		for j := range segments {
+			a := Vector3{X: float32(rng.Intn(10)), Y: float32(rng.Intn(10))}
+			b := Vector3{X: float32(rng.Intn(10)), Y: float32(rng.Intn(10))}
+			if rng.Intn(4) == 0 {
+				b.X = a.X
+			}
+			if rng.Intn(4) == 0 {
+				b.Y = a.Y
+			}
+			segments[j] = *NewLine3(&a, &b)
+		}
+		checkSweepIntersections(t, segments)
+	}
+}
+
+// Test the sweep line against the brute force with many long parallel segments crossing the sweep line
+// at the same time and segments crossing them
+func TestIntersectionGraphParallel(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(7))
+	segments := make([]Line3, 2000)
+	for j := range segments {
+		if j%50 == 0 {
+			a := Vector3{X: rng.Float32() * 500, Y: 0}
+			b := Vector3{X: a.X + (rng.Float32()-0.5)*100, Y: 1000}
+			segments[j] = *NewLine3(&a, &b)
+			continue
+		}
+		a := Vector3{X: rng.Float32() * 500, Y: rng.Float32() * 1000}
+		b := Vector3{X: a.X + 500, Y: a.Y}
+		segments[j] = *NewLine3(&a, &b)
+	}
+	checkSweepIntersections(t, segments)
+}
+
+// Test that segments through nearly the same point have the same intersections as with the brute force
+func TestIntersectionGraphConcurrent(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(9))
+	for i := 0; i < 20; i++ {
+		segments := make([]Line3, 200)
+		for j := range segments {
+			a := rng.Float64() * math.Pi
+			l := 10 + rng.Float64()*30
+			c := Vector3{X: 50 + float32(rng.Float64()-0.5)*1e-4, Y: 50 + float32(rng.Float64()-0.5)*1e-4}
+			d := Vector3{X: float32(l * math.Cos(a)), Y: float32(l * math.Sin(a))}
+			segments[j] = *NewLine3(c.Clone().Add(&d), c.Clone().Sub(&d))
+		}
+		expected := make(map[[2]int]bool)
+		for _, x := range bruteForceIntersections(newSweepSegments(segments)) {
+			expected[[2]int{x.A, x.B}] = true
+		}
+		found := NewIntersectionGraph(segments).Intersections()
+		if len(found) != len(expected) {
+			t.Error("found", len(found), "intersections instead of", len(expected))
+		}
+		for _, x := range found {
+			if !expected[[2]int{x.A, x.B}] {
+				t.Error("intersection", x, "not found by the brute force")
+			}
+		}
+	}
+}
+
+// Test the intersections of a few segments
+func TestIntersectionGraphIntersections(t *testing.T) {
+
+	segments := []Line3{
+		*NewLine3(&Vector3{X: 0, Y: 0, Z: 0}, &Vector3{X: 2, Y: 2, Z: 2}),
+		*NewLine3(&Vector3{X: 0, Y: 2, Z: 0}, &Vector3{X: 2, Y: 0, Z: 0}),
+		*NewLine3(&Vector3{X: 3, Y: 0}, &Vector3{X: 3, Y: 5}),
+		*NewLine3(&Vector3{X: 0, Y: 1}, &Vector3{X: 4, Y: 1}),
+	}
+	found := NewIntersectionGraph(segments).Intersections()
+	expected := []SegmentIntersection{
+		{A: 0, B: 1, Point: Vector3{X: 1, Y: 1, Z: 1}},
+		{A: 0, B: 3, Point: Vector3{X: 1, Y: 1, Z: 1}},
+		{A: 1, B: 3, Point: Vector3{X: 1, Y: 1}},
+		{A: 2, B: 3, Point: Vector3{X: 3, Y: 1}},
+	}
+	if len(found) != len(expected) {
+		t.Fatal("intersections", found, "instead of", expected)
+	}
+	for i := range expected {
+		if found[i].A != expected[i].A || found[i].B != expected[i].B || found[i].Point.DistanceTo(&expected[i].Point) > 1e-5 {
+			t.Error("intersection", found[i], "instead of", expected[i])
+		}
+	}
+}
+
+// intersectionsSink keeps the results of the benchmarks.
+var intersectionsSink []SegmentIntersection
+
+// Benchmark the sweep line with many long parallel segments crossing the sweep line at the same time
+func BenchmarkIntersectionGraphParallel(b *testing.B) {
+
+	rng := rand.New(rand.NewSource(1))
+	segments := make([]Line3, 20000)
+	for j := range segments {
+		a := Vector3{X: rng.Float32() * 500, Y: rng.Float32() * 1000}
+		c := Vector3{X: a.X + 500, Y: a.Y}
+		if j%1000 == 0 {
+			a.Y, c.X, c.Y = 0, a.X+(rng.Float32()-0.5)*100, 1000
+		}
+		segments[j] = *NewLine3(&a, &c)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		intersectionsSink = NewIntersectionGraph(segments).Intersections()
+	}
+}
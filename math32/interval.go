@@ -0,0 +1,47 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+// Interval is a closed range of values [Min, Max].
+type Interval struct {
+	Min float32
+	Max float32
+}
+
+// NewInterval creates and returns a pointer to a new interval with the specified bounds.
+func NewInterval(min, max float32) *Interval {
+
+	return &Interval{Min: min, Max: max}
+}
+
+// IsEmpty returns if this interval contains no value: its maximum is less than its minimum.
+func (iv *Interval) IsEmpty() bool {
+
+	return iv.Max < iv.Min
+}
+
+// IsZero returns if both bounds of this interval are zero.
+func (iv *Interval) IsZero() bool {
+
+	return iv.Min == 0 && iv.Max == 0
+}
+
+// Contains returns if the specified value is inside this interval.
+func (iv *Interval) Contains(v float32) bool {
+
+	return v >= iv.Min && v <= iv.Max
+}
+
+// Clamp returns the specified value clamped to this interval.
+func (iv *Interval) Clamp(v float32) float32 {
+
+	return Clamp(v, iv.Min, iv.Max)
+}
+
+// Length returns the length of this interval, zero if it is empty.
+func (iv *Interval) Length() float32 {
+
+	return Max(iv.Max-iv.Min, 0)
+}
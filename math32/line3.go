@@ -4,6 +4,10 @@
 
 package math32
 
+import (
+	"math/rand"
+)
+
 // Line3 represents a 3D line segment defined by a start and an end point.
 type Line3 struct {
 	start Vector3
@@ -134,3 +138,167 @@ func (l *Line3) Clone() *Line3 {
 
 	return NewLine3(&l.start, &l.end)
 }
+
+// IntersectTriangle returns a pointer to a new vector with the intersection of this line segment with the
+// specified triangle, using the Möller–Trumbore algorithm, and if they intersect. The edges are included.
+// A segment parallel to the plane of the triangle, including a coplanar segment, does not intersect it.
+// If backfaceCulling is true it ignores the intersection with the back face of the triangle, whose
+// vertices are in clockwise order as seen from the start of the segment.
+func (l *Line3) IntersectTriangle(tri *Triangle, backfaceCulling bool) (point *Vector3, hit bool) {
+
+	var dir, edge1, edge2, normal, p, q, s Vector3
+	l.Delta(&dir)
+	edge1.SubVectors(&tri.b, &tri.a)
+	edge2.SubVectors(&tri.c, &tri.a)
+
+	// The determinant is the opposite of the dot product of the direction with the normal of the triangle
+	p.CrossVectors(&dir, &edge2)
+	det := edge1.Dot(&p)
+	normal.CrossVectors(&edge1, &edge2)
+	if Abs(det) <= 1e-6*dir.Length()*normal.Length() {
+		return nil, false
+	}
+	if backfaceCulling && det < 0 {
+		return nil, false
+	}
+	invDet := 1 / det
+
+	// Barycentric coordinates of the intersection with the plane of the triangle
+	s.SubVectors(&l.start, &tri.a)
+	u := s.Dot(&p) * invDet
+	if u < 0 || u > 1 {
+		return nil, false
+	}
+	q.CrossVectors(&s, &edge1)
+	v := dir.Dot(&q) * invDet
+	if v < 0 || u+v > 1 {
+		return nil, false
+	}
+
+	// Parameter of the intersection along the segment
+	t := edge2.Dot(&q) * invDet
+	if t < 0 || t > 1 {
+		return nil, false
+	}
+	return NewVector3(0, 0, 0).Copy(&l.start).Add(dir.MultiplyScalar(t)), true
+}
+
+// UniformSample appends to optionalTarget, which may be nil, n points distributed uniformly along
+// this line segment, using the specified random number generator, and returns the extended slice.
+// If rng is nil a generator with a fixed seed is used.
+func (l *Line3) UniformSample(n int, rng *rand.Rand, optionalTarget []Vector3) []Vector3 {
+
+	if rng == nil {
+		rng = rand.New(rand.NewSource(1))
+	}
+	for i := 0; i < n; i++ {
+		optionalTarget = append(optionalTarget, l.start)
+		optionalTarget[len(optionalTarget)-1].Lerp(&l.end, rng.Float32())
+	}
+	return optionalTarget
+}
+
+// StratifiedSample appends to optionalTarget, which may be nil, n points along this line segment, one
+// at a random position in each of n equal parts of the segment in order, using the specified random
+// number generator, and returns the extended slice. The points are spread more evenly than by UniformSample.
+// If rng is nil a generator with a fixed seed is used.
+func (l *Line3) StratifiedSample(n int, rng *rand.Rand, optionalTarget []Vector3) []Vector3 {
+
+	if rng == nil {
+		rng = rand.New(rand.NewSource(1))
+	}
+	for i := 0; i < n; i++ {
+		optionalTarget = append(optionalTarget, l.start)
+		optionalTarget[len(optionalTarget)-1].Lerp(&l.end, (float32(i)+rng.Float32())/float32(n))
+	}
+	return optionalTarget
+}
+
+// OffsetParallel creates and returns a pointer to a new line segment parallel to this one,
+// translated by the specified distance along the normalized specified normal.
+func (l *Line3) OffsetParallel(distance float32, normal *Vector3) *Line3 {
+
+	var offset Vector3
+	offset.Copy(normal).Normalize().MultiplyScalar(distance)
+	res := l.Clone()
+	res.start.Add(&offset)
+	res.end.Add(&offset)
+	return res
+}
+
+// OffsetParallelXZ creates and returns a pointer to a new line segment parallel to this one, translated
+// by the specified distance in the XZ plane perpendicularly to its direction: along the cross product
+// of the Y axis with the direction, which is on the left of the direction seen from above.
+// A segment parallel to the Y axis is not translated.
+func (l *Line3) OffsetParallelXZ(distance float32) *Line3 {
+
+	var dir Vector3
+	l.Delta(&dir)
+	normal := Vector3{X: dir.Z, Y: 0, Z: -dir.X}
+	if normal.LengthSq() == 0 {
+		return l.Clone()
+	}
+	return l.OffsetParallel(distance, &normal)
+}
+
+// ToQuaternionFrame creates and returns a pointer to a new quaternion with the rotation of the +Z axis to the
+// direction of this line segment, rotating the +Y axis as close as possible to the specified up vector.
+// If the direction is nearly parallel to the up vector, the axis least aligned with the direction is used as up.
+// Returns the identity quaternion for a segment of zero length.
+func (l *Line3) ToQuaternionFrame(up *Vector3) *Quaternion {
+
+	var dir Vector3
+	l.Delta(&dir)
+	return NewQuaternion(0, 0, 0, 1).LookRotation(&dir, up)
+}
+
+// BoundingBox creates and returns a pointer to a new Box3 with the smallest box containing this line segment.
+func (l *Line3) BoundingBox() *Box3 {
+
+	b := NewBox3(&l.start, &l.start)
+	b.Min.Min(&l.end)
+	b.Max.Max(&l.end)
+	return b
+}
+
+// ExpandedBoundingBox creates and returns a pointer to a new Box3 with the bounding box
+// of this line segment expanded by the specified margin on all sides.
+func (l *Line3) ExpandedBoundingBox(margin float32) *Box3 {
+
+	return l.BoundingBox().ExpandByScalar(margin)
+}
+
+// Extrude creates and returns pointers to two new triangles forming the quadrilateral swept by this line segment
+// translated by the specified direction times amount. The triangles are wound counterclockwise around the cross
+// product of the segment direction and the extrusion, and share the diagonal of the quadrilateral which makes
+// their smallest angle the largest.
+func (l *Line3) Extrude(direction *Vector3, amount float32) (t0, t1 *Triangle) {
+
+	var offset, start2, end2 Vector3
+	offset.Copy(direction).MultiplyScalar(amount)
+	start2.AddVectors(&l.start, &offset)
+	end2.AddVectors(&l.end, &offset)
+	// Diagonal from the start to the translated end, or from the end to the translated start
+	q0 := Min(triangleMinAngle(&l.start, &l.end, &end2), triangleMinAngle(&l.start, &end2, &start2))
+	q1 := Min(triangleMinAngle(&l.start, &l.end, &start2), triangleMinAngle(&l.end, &end2, &start2))
+	if q0 >= q1 {
+		return NewTriangle(&l.start, &l.end, &end2), NewTriangle(&l.start, &end2, &start2)
+	}
+	return NewTriangle(&l.start, &l.end, &start2), NewTriangle(&l.end, &end2, &start2)
+}
+
+// triangleMinAngle returns the smallest angle of the triangle with the specified vertices,
+// or 0 if it is degenerate.
+func triangleMinAngle(a, b, c *Vector3) float32 {
+
+	var ab, ac, bc Vector3
+	ab.SubVectors(b, a)
+	ac.SubVectors(c, a)
+	bc.SubVectors(c, b)
+	if ab.LengthSq() == 0 || ac.LengthSq() == 0 || bc.LengthSq() == 0 {
+		return 0
+	}
+	angleA := ab.AngleTo(&ac)
+	angleB := bc.AngleTo(ab.Negate())
+	return Min(Min(angleA, angleB), Pi-angleA-angleB)
+}
@@ -0,0 +1,97 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"testing"
+)
+
+// Test the intersections of segments with the front face, the back face, the edges and the plane of a triangle
+func TestLine3IntersectTriangle(t *testing.T) {
+
+	// Counter clockwise as seen from +Z
+	tri := NewTriangle(NewVector3(0, 0, 0), NewVector3(1, 0, 0), NewVector3(0, 1, 0))
+	cases := []struct {
+		name            string
+		start, end      Vector3
+		backfaceCulling bool
+		hit             bool
+		point           Vector3
+	}{
+		{"front face", Vector3{X: 0.2, Y: 0.2, Z: 1}, Vector3{X: 0.2, Y: 0.2, Z: -1}, true, true, Vector3{X: 0.2, Y: 0.2}},
+		{"culled back face", Vector3{X: 0.2, Y: 0.2, Z: -1}, Vector3{X: 0.2, Y: 0.2, Z: 1}, true, false, Vector3{}},
+		{"back face", Vector3{X: 0.2, Y: 0.2, Z: -1}, Vector3{X: 0.2, Y: 0.2, Z: 3}, false, true, Vector3{X: 0.2, Y: 0.2}},
+		{"oblique", Vector3{X: 0, Y: 0, Z: 2}, Vector3{X: 0.5, Y: 0.5, Z: -2}, false, true, Vector3{X: 0.25, Y: 0.25}},
+		{"segment ending before", Vector3{X: 0.2, Y: 0.2, Z: 1}, Vector3{X: 0.2, Y: 0.2, Z: 0.5}, false, false, Vector3{}},
+		{"segment starting after", Vector3{X: 0.2, Y: 0.2, Z: -0.5}, Vector3{X: 0.2, Y: 0.2, Z: -1}, false, false, Vector3{}},
+		{"segment ending on the face", Vector3{X: 0.2, Y: 0.2, Z: 1}, Vector3{X: 0.2, Y: 0.2, Z: 0}, true, true, Vector3{X: 0.2, Y: 0.2}},
+		{"grazing edge", Vector3{X: 0.5, Y: 0, Z: 1}, Vector3{X: 0.5, Y: 0, Z: -1}, true, true, Vector3{X: 0.5}},
+		{"grazing hypotenuse", Vector3{X: 0.5, Y: 0.5, Z: 1}, Vector3{X: 0.5, Y: 0.5, Z: -1}, true, true, Vector3{X: 0.5, Y: 0.5}},
+		{"vertex", Vector3{X: 1, Y: 0, Z: 1}, Vector3{X: 1, Y: 0, Z: -1}, true, true, Vector3{X: 1}},
+		{"outside", Vector3{X: 0.6, Y: 0.6, Z: 1}, Vector3{X: 0.6, Y: 0.6, Z: -1}, true, false, Vector3{}},
+		{"coplanar", Vector3{X: -1, Y: 0.2, Z: 0}, Vector3{X: 1, Y: 0.2, Z: 0}, false, false, Vector3{}},
+		{"parallel", Vector3{X: -1, Y: 0.2, Z: 1}, Vector3{X: 1, Y: 0.2, Z: 1}, false, false, Vector3{}},
+	}
+	for _, c := range cases {
+		point, hit := NewLine3(&c.start, &c.end).IntersectTriangle(tri, c.backfaceCulling)
+		if hit != c.hit || (point == nil) == hit {
+			t.Error(c.name, "intersection", point, hit, "instead of", c.hit)
+		} else if hit && point.DistanceTo(&c.point) > 1e-6 {
+			t.Error(c.name, "intersection at", *point, "instead of", c.point)
+		}
+	}
+}
+
+// Test that the offset segments have the same length and are translated by the distance perpendicularly in XZ
+func TestLine3OffsetParallel(t *testing.T) {
+
+	l := NewLine3(NewVector3(0, 1, 0), NewVector3(3, 2, 4))
+	o := l.OffsetParallel(1, NewVector3(0, 5, 0))
+	if *o.Start() != (Vector3{X: 0, Y: 2, Z: 0}) || *o.End() != (Vector3{X: 3, Y: 3, Z: 4}) {
+		t.Error("segment offset along Y from", *o.Start(), "to", *o.End())
+	}
+	if Abs(o.Distance()-l.Distance()) > 1e-6 {
+		t.Error("offset segment length", o.Distance(), "instead of", l.Distance())
+	}
+
+	// Direction (3,1,4) with the perpendicular (4,0,-3)/5 in XZ
+	o = l.OffsetParallelXZ(2)
+	expected := Vector3{X: 1.6, Y: 1, Z: -1.2}
+	if o.Start().DistanceTo(&expected) > 1e-6 || Abs(o.Distance()-l.Distance()) > 1e-6 {
+		t.Error("segment offset in XZ from", *o.Start(), "with the length", o.Distance(), "instead of", expected, l.Distance())
+	}
+	if start := *l.Start(); start != (Vector3{Y: 1}) {
+		t.Error("original segment moved to", start)
+	}
+
+	// Along +X the offset is along -Z, and a vertical segment is not translated
+	if o := NewLine3(NewVector3(0, 0, 0), NewVector3(1, 0, 0)).OffsetParallelXZ(1); *o.Start() != (Vector3{Z: -1}) {
+		t.Error("segment along X offset to", *o.Start())
+	}
+	if o := NewLine3(NewVector3(1, 0, 1), NewVector3(1, 3, 1)).OffsetParallelXZ(1); *o.Start() != (Vector3{X: 1, Z: 1}) {
+		t.Error("vertical segment offset to", *o.Start())
+	}
+}
+
+// Test the bounding boxes of a diagonal segment, which contain its endpoints
+func TestLine3BoundingBox(t *testing.T) {
+
+	l := NewLine3(NewVector3(1, -2, 3), NewVector3(-1, 4, 0))
+	box := l.BoundingBox()
+	if !box.ContainsPoint(l.Start()) || !box.ContainsPoint(l.End()) {
+		t.Error("bounding box", *box, "does not contain the endpoints")
+	}
+	if box.Min != (Vector3{X: -1, Y: -2, Z: 0}) || box.Max != (Vector3{X: 1, Y: 4, Z: 3}) {
+		t.Error("bounding box", *box, "instead of (-1,-2,0) to (1,4,3)")
+	}
+	if *l.Start() != (Vector3{X: 1, Y: -2, Z: 3}) || *l.End() != (Vector3{X: -1, Y: 4, Z: 0}) {
+		t.Error("segment changed to", *l.Start(), *l.End())
+	}
+
+	box = l.ExpandedBoundingBox(0.5)
+	if box.Min != (Vector3{X: -1.5, Y: -2.5, Z: -0.5}) || box.Max != (Vector3{X: 1.5, Y: 4.5, Z: 3.5}) {
+		t.Error("expanded bounding box", *box, "instead of (-1.5,-2.5,-0.5) to (1.5,4.5,3.5)")
+	}
+}
@@ -82,6 +82,10 @@ func Cos(v float32) float32 {
 	return float32(math.Cos(float64(v)))
 }
 
+func Exp(v float32) float32 {
+	return float32(math.Exp(float64(v)))
+}
+
 func Floor(v float32) float32 {
 	return float32(math.Floor(float64(v)))
 }
@@ -129,3 +133,93 @@ func Pow(a, b float32) float32 {
 func Tan(v float32) float32 {
 	return float32(math.Tan(float64(v)))
 }
+
+// SmoothDamp gradually moves current towards target like a critically damped spring
+// which reaches the target in approximately smoothTime and returns the new value.
+// The velocity is updated and must be kept between calls.
+func SmoothDamp(current, target float32, velocity *float32, smoothTime, dt float32) float32 {
+
+	smoothTime = Max(smoothTime, 1e-4)
+	omega := 2 / smoothTime
+	x := omega * dt
+	exp := 1 / (1 + x + 0.48*x*x + 0.235*x*x*x)
+	change := current - target
+	temp := (*velocity + omega*change) * dt
+	*velocity = (*velocity - omega*temp) * exp
+	return target + (change+temp)*exp
+}
+
+// SmoothStep returns 0 if x is less than edge0, 1 if x is greater than edge1, and the smooth Hermite interpolation
+// 3t²-2t³ of t=(x-edge0)/(edge1-edge0) in between, as the GLSL smoothstep function.
+// If the edges are equal it returns 0 below them and 1 otherwise.
+func SmoothStep(edge0, edge1, x float32) float32 {
+
+	if edge0 == edge1 {
+		if x < edge0 {
+			return 0
+		}
+		return 1
+	}
+	return SmoothStepScalar((x - edge0) / (edge1 - edge0))
+}
+
+// SmoothStepScalar returns the smooth Hermite interpolation 3x²-2x³ of x clamped to [0,1].
+func SmoothStepScalar(x float32) float32 {
+
+	x = Clamp(x, 0, 1)
+	return x * x * (3 - 2*x)
+}
+
+// Repeat returns t wrapped into the range [0,length), which is also positive for negative t,
+// or 0 if length is not positive. The remainder is computed without conversion to integers,
+// so that t may be much larger than length.
+func Repeat(t, length float32) float32 {
+
+	if length <= 0 {
+		return 0
+	}
+	r := math.Mod(float64(t), float64(length))
+	if r < 0 {
+		r += float64(length)
+	}
+	// Negative zero, or rounding of a tiny negative remainder
+	if r == 0 || float32(r) >= length {
+		return 0
+	}
+	return float32(r)
+}
+
+// PingPong returns t moving back and forth in the range [0,length]: the triangle wave
+// of period 2*length which is 0 at t=0 and length at t=length, or 0 if length is not positive.
+func PingPong(t, length float32) float32 {
+
+	if length <= 0 {
+		return 0
+	}
+	return length - Abs(Repeat(t, 2*length)-length)
+}
+
+// Hermite returns the cubic Hermite interpolation at t in [0,1] between the value p0 with tangent m0 at t=0
+// and the value p1 with tangent m1 at t=1.
+func Hermite(t, p0, p1, m0, m1 float32) float32 {
+
+	t2 := t * t
+	t3 := t2 * t
+	h00 := 2*t3 - 3*t2 + 1
+	h10 := t3 - 2*t2 + t
+	h01 := -2*t3 + 3*t2
+	h11 := t3 - t2
+	return h00*p0 + h10*m0 + h01*p1 + h11*m1
+}
+
+// HermiteDerivative returns the derivative with respect to t of the cubic Hermite interpolation
+// returned by Hermite with the same parameters.
+func HermiteDerivative(t, p0, p1, m0, m1 float32) float32 {
+
+	t2 := t * t
+	d00 := 6*t2 - 6*t
+	d10 := 3*t2 - 4*t + 1
+	d01 := -6*t2 + 6*t
+	d11 := 3*t2 - 2*t
+	return d00*p0 + d10*m0 + d01*p1 + d11*m1
+}
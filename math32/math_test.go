@@ -0,0 +1,87 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"math"
+	"testing"
+)
+
+// Test the values of Repeat at the boundaries, for negative and large parameters and its periodicity
+func TestRepeat(t *testing.T) {
+
+	cases := [][3]float32{
+		// t, length, expected
+		{0, 2, 0}, {2, 2, 0}, {0.5, 2, 0.5}, {-0.5, 2, 1.5}, {-2, 2, 0}, {-1e-9, 2, 0},
+		{3e9, 7, 4}, {1, 0, 0}, {1, -3, 0},
+	}
+	for _, c := range cases {
+		if r := Repeat(c[0], c[1]); r != c[2] {
+			t.Error("Repeat", c[0], c[1], "is", r, "instead of", c[2])
+		}
+	}
+	// Beyond the range of the integers
+	x := float32(1e20)
+	if r, expected := Repeat(x, 3), float32(math.Mod(float64(x), 3)); r != expected {
+		t.Error("Repeat", x, 3, "is", r, "instead of", expected)
+	}
+	for i := -100; i < 100; i++ {
+		x := float32(i) * 0.37
+		r := Repeat(x, 1.5)
+		if r < 0 || r >= 1.5 {
+			t.Error("Repeat", x, 1.5, "is", r, "outside [0,1.5)")
+		}
+		// Equal after a period, but for rounding near a multiple of the period
+		if d := Abs(Repeat(x+1.5, 1.5) - r); d > 1e-4 && Abs(d-1.5) > 1e-4 {
+			t.Error("Repeat", x+1.5, 1.5, "differs from", r)
+		}
+	}
+}
+
+// Test the values of PingPong at the boundaries, for negative and large parameters and its periodicity
+func TestPingPong(t *testing.T) {
+
+	cases := [][3]float32{
+		// t, length, expected
+		{0, 2, 0}, {2, 2, 2}, {3, 2, 1}, {4, 2, 0}, {-1, 2, 1}, {-3, 2, 1},
+		{3e9, 7, 4}, {1e9, 7, 6}, {5, 0, 0}, {5, -1, 0},
+	}
+	for _, c := range cases {
+		if p := PingPong(c[0], c[1]); p != c[2] {
+			t.Error("PingPong", c[0], c[1], "is", p, "instead of", c[2])
+		}
+	}
+	for i := -100; i < 100; i++ {
+		x := float32(i) * 0.37
+		p := PingPong(x, 1.5)
+		if p < 0 || p > 1.5 || Abs(PingPong(x+3, 1.5)-p) > 1e-4 || Abs(PingPong(-x, 1.5)-p) > 1e-4 {
+			t.Error("PingPong", x, 1.5, "is", p, "not in [0,1.5], periodic and even")
+		}
+	}
+}
+
+// Test the values and tangents of the Hermite interpolation at its ends and its numerical derivative
+func TestHermite(t *testing.T) {
+
+	const p0, p1, m0, m1 = 2, 5, 1, -3
+	if Hermite(0, p0, p1, m0, m1) != p0 || Hermite(1, p0, p1, m0, m1) != p1 {
+		t.Error("Hermite at the ends", Hermite(0, p0, p1, m0, m1), Hermite(1, p0, p1, m0, m1), "instead of", p0, p1)
+	}
+	if HermiteDerivative(0, p0, p1, m0, m1) != m0 || HermiteDerivative(1, p0, p1, m0, m1) != m1 {
+		t.Error("HermiteDerivative at the ends", HermiteDerivative(0, p0, p1, m0, m1), HermiteDerivative(1, p0, p1, m0, m1), "instead of", m0, m1)
+	}
+	const h = 1e-3
+	for i := 0; i <= 10; i++ {
+		x := float32(i) / 10
+		numerical := (Hermite(x+h, p0, p1, m0, m1) - Hermite(x-h, p0, p1, m0, m1)) / (2 * h)
+		if d := HermiteDerivative(x, p0, p1, m0, m1); Abs(d-numerical) > 1e-2 {
+			t.Error("HermiteDerivative at", x, "is", d, "instead of", numerical)
+		}
+	}
+	// The tangents of a line reproduce it
+	if v := Hermite(0.3, 1, 3, 2, 2); Abs(v-1.6) > 1e-6 {
+		t.Error("Hermite of a line at 0.3 is", v, "instead of 1.6")
+	}
+}
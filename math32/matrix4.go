@@ -4,7 +4,10 @@
 
 package math32
 
-import "errors"
+import (
+	"errors"
+	"math"
+)
 
 // Matrix4 is 4x4 matrix organized internally as column matrix.
 type Matrix4 [16]float32
@@ -520,6 +523,20 @@ func (m *Matrix4) Scale(v *Vector3) *Matrix4 {
 	return m
 }
 
+// ScaleNonUniform post-multiplies this matrix by the scale matrix of the specified x, y and z values.
+// Returns pointer to this updated matrix.
+func (m *Matrix4) ScaleNonUniform(x, y, z float32) *Matrix4 {
+
+	return m.Scale(&Vector3{x, y, z})
+}
+
+// ScaleUniform post-multiplies this matrix by the scale matrix of the specified value on all axes.
+// Returns pointer to this updated matrix.
+func (m *Matrix4) ScaleUniform(s float32) *Matrix4 {
+
+	return m.Scale(&Vector3{s, s, s})
+}
+
 // GetMaxScaleOnAxis returns the maximum scale value of the 3 axes.
 func (m *Matrix4) GetMaxScaleOnAxis() float32 {
 
@@ -609,6 +626,18 @@ func (m *Matrix4) MakeRotationAxis(axis *Vector3, angle float32) *Matrix4 {
 	return m
 }
 
+// RotateAroundAxis post-multiplies this matrix by the rotation of the specified angle
+// around the specified axis, which does not need to be normalized.
+// Returns pointer to this updated matrix.
+func (m *Matrix4) RotateAroundAxis(axis *Vector3, angle float32) *Matrix4 {
+
+	var rot Matrix4
+	a := *axis
+	a.Normalize()
+	rot.MakeRotationAxis(&a, angle)
+	return m.Multiply(&rot)
+}
+
 // MakeScale sets this matrix to a scale transformation matrix using the specified x, y and z values.
 // Returns pointer to this updated matrix.
 func (m *Matrix4) MakeScale(x, y, z float32) *Matrix4 {
@@ -770,3 +799,132 @@ func (m *Matrix4) Clone() *Matrix4 {
 	cloned = *m
 	return &cloned
 }
+
+// Lerp sets each of the 16 elements of this matrix to the linear interpolation between
+// itself and the corresponding element of the other matrix, where t=0 keeps this matrix
+// and t=1 gives the other matrix. This is a per element interpolation and not a polar
+// decomposition: intermediate rotations are not orthonormal and shrink the geometry,
+// so Quaternion.Slerp should be used when rotations must be preserved.
+// Returns pointer to this updated matrix.
+func (m *Matrix4) Lerp(other *Matrix4, t float32) *Matrix4 {
+
+	for i := range m {
+		m[i] += (other[i] - m[i]) * t
+	}
+	return m
+}
+
+// SVD returns the singular value decomposition of the upper left 3x3 part of this matrix
+// computed by one sided Jacobi rotations, such that it is equal to U * diag(S) * transpose(V),
+// where U and V are orthonormal and the singular values in S are non negative and in descending order.
+// For matrices with positive determinant U * transpose(V) is the rotation of their polar decomposition.
+func (m *Matrix4) SVD() (U *Matrix3, S *Vector3, V *Matrix3) {
+
+	// Columns of the working matrix and the accumulated right rotations in double precision
+	var a, v [3][3]float64
+	for c := 0; c < 3; c++ {
+		for r := 0; r < 3; r++ {
+			a[c][r] = float64(m[c*4+r])
+		}
+		v[c][c] = 1
+	}
+	dot := func(x, y *[3]float64) float64 { return x[0]*y[0] + x[1]*y[1] + x[2]*y[2] }
+
+	// Rotates pairs of columns until all columns are orthogonal
+	for sweep := 0; sweep < 30; sweep++ {
+		rotated := false
+		for i := 0; i < 2; i++ {
+			for j := i + 1; j < 3; j++ {
+				alpha := dot(&a[i], &a[i])
+				beta := dot(&a[j], &a[j])
+				gamma := dot(&a[i], &a[j])
+				if gamma == 0 || math.Abs(gamma) <= 1e-15*math.Sqrt(alpha*beta) {
+					continue
+				}
+				rotated = true
+				zeta := (beta - alpha) / (2 * gamma)
+				t := math.Copysign(1, zeta) / (math.Abs(zeta) + math.Sqrt(1+zeta*zeta))
+				c := 1 / math.Sqrt(1+t*t)
+				s := c * t
+				for k := 0; k < 3; k++ {
+					ai, aj := a[i][k], a[j][k]
+					a[i][k] = c*ai - s*aj
+					a[j][k] = s*ai + c*aj
+					vi, vj := v[i][k], v[j][k]
+					v[i][k] = c*vi - s*vj
+					v[j][k] = s*vi + c*vj
+				}
+			}
+		}
+		if !rotated {
+			break
+		}
+	}
+
+	// The singular values are the norms of the columns, sorted in descending order
+	var sigma [3]float64
+	order := [3]int{0, 1, 2}
+	for i := range sigma {
+		sigma[i] = math.Sqrt(dot(&a[i], &a[i]))
+	}
+	for i := 1; i < 3; i++ {
+		for j := i; j > 0 && sigma[order[j]] > sigma[order[j-1]]; j-- {
+			order[j], order[j-1] = order[j-1], order[j]
+		}
+	}
+
+	// The left singular vectors are the normalized columns. The vectors of null singular values
+	// are completed to an orthonormal basis.
+	var u [3][3]float64
+	var sv [3]float64
+	var rv [3][3]float64
+	rank := 0
+	for k, i := range order {
+		sv[k] = sigma[i]
+		rv[k] = v[i]
+		if sigma[i] > 1e-7*sigma[order[0]] {
+			for r := 0; r < 3; r++ {
+				u[k][r] = a[i][r] / sigma[i]
+			}
+			rank++
+		}
+	}
+	cross := func(x, y *[3]float64) [3]float64 {
+		return [3]float64{x[1]*y[2] - x[2]*y[1], x[2]*y[0] - x[0]*y[2], x[0]*y[1] - x[1]*y[0]}
+	}
+	if rank == 0 {
+		u[0] = [3]float64{1, 0, 0}
+		rank = 1
+	}
+	if rank == 1 {
+		// Any vector orthogonal to the first one, from its smallest component axis
+		low := 0
+		for r := 1; r < 3; r++ {
+			if math.Abs(u[0][r]) < math.Abs(u[0][low]) {
+				low = r
+			}
+		}
+		var axis [3]float64
+		axis[low] = 1
+		u[1] = cross(&u[0], &axis)
+		l := math.Sqrt(dot(&u[1], &u[1]))
+		for r := 0; r < 3; r++ {
+			u[1][r] /= l
+		}
+		rank = 2
+	}
+	if rank == 2 {
+		u[2] = cross(&u[0], &u[1])
+	}
+
+	U = new(Matrix3)
+	V = new(Matrix3)
+	for c := 0; c < 3; c++ {
+		for r := 0; r < 3; r++ {
+			U[c*3+r] = float32(u[c][r])
+			V[c*3+r] = float32(rv[c][r])
+		}
+	}
+	S = &Vector3{float32(sv[0]), float32(sv[1]), float32(sv[2])}
+	return U, S, V
+}
@@ -0,0 +1,177 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// checkSVD tests the decomposition of the specified matrix and returns the largest absolute errors
+// of the reconstruction and of the orthonormality of U and V.
+func checkSVD(t *testing.T, m *Matrix4) (reconstruction, orthonormality float32) {
+
+	t.Helper()
+	U, S, V := m.SVD()
+	if S.Z < 0 || S.Y < S.Z || S.X < S.Y {
+		t.Error("singular values not non negative and descending:", S, "for", m)
+	}
+
+	// U * diag(S) * transpose(V)
+	us := *U
+	us.ScaleColumns(S)
+	vt := *V
+	vt.Transpose()
+	var r, m3 Matrix3
+	r.MultiplyMatrices(&us, &vt)
+	m3.SetFromMatrix4(m)
+	for i := range r {
+		reconstruction = Max(reconstruction, Abs(r[i]-m3[i]))
+	}
+
+	// transpose(Q) * Q = I
+	for _, q := range []*Matrix3{U, V} {
+		qt := *q
+		qt.Transpose()
+		var p Matrix3
+		p.MultiplyMatrices(&qt, q)
+		for i := range p {
+			var id float32
+			if i%4 == 0 {
+				id = 1
+			}
+			orthonormality = Max(orthonormality, Abs(p[i]-id))
+		}
+	}
+	return reconstruction, orthonormality
+}
+
+// Test the singular value decomposition of full rank, rank deficient and special matrices
+func TestMatrix4SVD(t *testing.T) {
+
+	const tolerance = 1e-5
+	rng := rand.New(rand.NewSource(1))
+	random := func() *Matrix4 {
+		m := NewMatrix4()
+		for i := 0; i < 12; i++ {
+			m[i] = rng.Float32()*2 - 1
+		}
+		return m
+	}
+	for k := 0; k < 2000; k++ {
+		m := random()
+		rank := 3
+		switch k % 5 {
+		case 1:
+			// Third column is a combination of the others
+			for r := 0; r < 3; r++ {
+				m[8+r] = m[r] + 2*m[4+r]
+			}
+			rank = 2
+		case 2:
+			// All columns are multiples of the first one
+			for r := 0; r < 3; r++ {
+				m[4+r] = 3 * m[r]
+				m[8+r] = -m[r]
+			}
+			rank = 1
+		case 3:
+			m.MakeRotationAxis(NewVector3(m[0], m[1], m[2]).Normalize(), m[3]*3)
+		case 4:
+			m.Identity().Scale(NewVector3(1, 1, 1e-3))
+		}
+		e, o := checkSVD(t, m)
+		if e > tolerance || o > tolerance {
+			t.Fatal("SVD errors", e, o, "for", m)
+		}
+		_, S, _ := m.SVD()
+		if rank < 3 && S.Z > tolerance || rank < 2 && S.Y > tolerance {
+			t.Fatal("SVD singular values", S, "for a matrix of rank", rank, m)
+		}
+	}
+
+	// Known singular values of a scaled rotation
+	m := NewMatrix4().MakeRotationAxis(NewVector3(0, 0, 1), 0.5)
+	m.Scale(NewVector3(2, -3, 0.5))
+	_, S, _ := m.SVD()
+	if Abs(S.X-3) > tolerance || Abs(S.Y-2) > tolerance || Abs(S.Z-0.5) > tolerance {
+		t.Error("SVD singular values", S, "instead of 3, 2, 0.5")
+	}
+
+	// The null matrix
+	var z Matrix4
+	e, o := checkSVD(t, &z)
+	if e != 0 || o > tolerance {
+		t.Error("SVD errors", e, o, "for the null matrix")
+	}
+}
+
+// Test the rotations of a full turn and of a quarter turn post-multiplied into a translation
+func TestMatrix4RotateAroundAxis(t *testing.T) {
+
+	m := NewMatrix4().RotateAroundAxis(&Vector3{X: 1, Y: 2, Z: 3}, 2*Pi)
+	identity := NewMatrix4()
+	for i := range m {
+		if Abs(m[i]-identity[i]) > 1e-6 {
+			t.Fatal("full turn", m, "instead of the identity")
+		}
+	}
+
+	// The axis is normalized and the rotation is applied before the translation
+	m.MakeTranslation(5, 0, 0).RotateAroundAxis(&Vector3{Z: 2}, Pi/2)
+	v := Vector3{X: 1}
+	v.ApplyMatrix4(m)
+	if v.DistanceTo(&Vector3{X: 5, Y: 1}) > 1e-6 {
+		t.Error("quarter turn around Z of (1,0,0) is", v, "instead of (0,1,0) translated to (5,1,0)")
+	}
+}
+
+// Test that a uniform scale of 2 doubles the axis scales and multiplies the determinant by 8,
+// and the non uniform scale of a vector
+func TestMatrix4ScaleNonUniform(t *testing.T) {
+
+	m := NewMatrix4().MakeRotationAxis(&Vector3{Z: 1}, 0.3)
+	m.SetPosition(&Vector3{X: 1, Y: 2, Z: 3})
+	det := m.Determinant()
+	var position Vector3
+	var rotation Quaternion
+	var scale Vector3
+	m.ScaleNonUniform(2, 2, 2).Decompose(&position, &rotation, &scale)
+	if scale.DistanceTo(&Vector3{X: 2, Y: 2, Z: 2}) > 1e-6 || Abs(m.Determinant()-8*det) > 1e-5 {
+		t.Error("scales", scale, "and determinant", m.Determinant(), "instead of (2,2,2) and", 8*det)
+	}
+	if *NewMatrix4().ScaleUniform(3) != *NewMatrix4().ScaleNonUniform(3, 3, 3) {
+		t.Error("ScaleUniform(3) differs from ScaleNonUniform(3,3,3)")
+	}
+
+	// The scale is applied before the translation
+	m.MakeTranslation(1, 0, 0).ScaleNonUniform(1, 2, 3)
+	v := Vector3{X: 1, Y: 1, Z: 1}
+	v.ApplyMatrix4(m)
+	if v != (Vector3{X: 2, Y: 2, Z: 3}) {
+		t.Error("scaled vector", v, "instead of (2,2,3)")
+	}
+}
+
+// Test the per element interpolation with the identity
+func TestMatrix4Lerp(t *testing.T) {
+
+	var m Matrix4
+	m.Compose(&Vector3{X: 1, Y: -2, Z: 3}, NewQuaternion(0, 0, 0, 1).SetFromAxisAngle(&Vector3{Y: 1}, 0.7), &Vector3{X: 2, Y: 1, Z: 0.5})
+	identity := NewMatrix4()
+	if r := m; *r.Lerp(identity, 0) != m {
+		t.Error("Lerp with t=0 is", r, "instead of", m)
+	}
+	if r := m; *r.Lerp(identity, 1) != *identity {
+		t.Error("Lerp with t=1 is", r, "instead of the identity")
+	}
+	r := m
+	r.Lerp(identity, 0.25)
+	for i := range r {
+		if expected := 0.75*m[i] + 0.25*identity[i]; Abs(r[i]-expected) > 1e-6 {
+			t.Error("Lerp with t=0.25 element", i, "is", r[i], "instead of", expected)
+		}
+	}
+}
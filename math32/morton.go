@@ -0,0 +1,78 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"sort"
+)
+
+// mortonMax is the maximum coordinate value of a 3D Morton code (21 bits).
+const mortonMax = 1<<21 - 1
+
+// mortonSpread returns the specified 21 bit value with two zero bits inserted between each of its bits.
+func mortonSpread(v uint32) uint64 {
+
+	x := uint64(v) & mortonMax
+	x = (x | x<<32) & 0x1f00000000ffff
+	x = (x | x<<16) & 0x1f0000ff0000ff
+	x = (x | x<<8) & 0x100f00f00f00f00f
+	x = (x | x<<4) & 0x10c30c30c30c30c3
+	x = (x | x<<2) & 0x1249249249249249
+	return x
+}
+
+// mortonCompact returns the value formed by every third bit of the specified value.
+func mortonCompact(v uint64) uint32 {
+
+	x := v & 0x1249249249249249
+	x = (x | x>>2) & 0x10c30c30c30c30c3
+	x = (x | x>>4) & 0x100f00f00f00f00f
+	x = (x | x>>8) & 0x1f0000ff0000ff
+	x = (x | x>>16) & 0x1f00000000ffff
+	x = (x | x>>32) & mortonMax
+	return uint32(x)
+}
+
+// MortonEncode3D returns the 63 bit Morton code (Z-order curve index) of the specified coordinates
+// by interleaving their bits, with the bits of x in the lowest positions.
+// Only the lowest 21 bits of the coordinates are used.
+func MortonEncode3D(x, y, z uint32) uint64 {
+
+	return mortonSpread(x) | mortonSpread(y)<<1 | mortonSpread(z)<<2
+}
+
+// MortonDecode3D returns the coordinates of the specified 3D Morton code.
+func MortonDecode3D(code uint64) (x, y, z uint32) {
+
+	return mortonCompact(code), mortonCompact(code >> 1), mortonCompact(code >> 2)
+}
+
+// MortonSort returns the permutation of the indices of the specified points which orders them by the
+// Morton codes of their positions quantized to 21 bits inside the specified bounds.
+// Points outside the bounds are clamped to them.
+func MortonSort(points []Vector3, bounds *Box3) []int {
+
+	var size Vector3
+	size.SubVectors(&bounds.Max, &bounds.Min)
+	quantize := func(v, min, size float32) uint32 {
+		if size <= 0 {
+			return 0
+		}
+		return uint32(Clamp((v-min)/size, 0, 1) * mortonMax)
+	}
+	codes := make([]uint64, len(points))
+	perm := make([]int, len(points))
+	for i := range points {
+		p := &points[i]
+		codes[i] = MortonEncode3D(
+			quantize(p.X, bounds.Min.X, size.X),
+			quantize(p.Y, bounds.Min.Y, size.Y),
+			quantize(p.Z, bounds.Min.Z, size.Z),
+		)
+		perm[i] = i
+	}
+	sort.SliceStable(perm, func(i, j int) bool { return codes[perm[i]] < codes[perm[j]] })
+	return perm
+}
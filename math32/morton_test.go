@@ -0,0 +1,73 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Test the round trip of the Morton codes over the 21 bit range of the coordinates
+func TestMortonRoundTrip(t *testing.T) {
+
+	// Every coordinate value on each axis, the others at the limits of the range
+	for v := uint32(0); v <= mortonMax; v++ {
+		for _, other := range []uint32{0, mortonMax} {
+			code := MortonEncode3D(v, other, mortonMax-other)
+			x, y, z := MortonDecode3D(code)
+			if x != v || y != other || z != mortonMax-other {
+				t.Fatal("MortonDecode3D(MortonEncode3D) failed for", v, other)
+			}
+			if MortonEncode3D(x, y, z) != code {
+				t.Fatal("MortonEncode3D(MortonDecode3D) failed for", code)
+			}
+		}
+	}
+
+	// Codes at the limits of the 63 bits and random codes
+	codes := []uint64{0, 1, 2, 4, 1<<63 - 1, 1 << 62, 1<<62 - 1}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100000; i++ {
+		codes = append(codes, rng.Uint64()>>1)
+	}
+	for _, code := range codes {
+		if MortonEncode3D(MortonDecode3D(code)) != code {
+			t.Fatal("MortonEncode3D(MortonDecode3D) failed for", code)
+		}
+	}
+
+	// Interleaving of the bits, with the bits above 21 ignored
+	if MortonEncode3D(1, 0, 0) != 1 || MortonEncode3D(0, 1, 0) != 2 || MortonEncode3D(0, 0, 1) != 4 {
+		t.Error("MortonEncode3D bit order failed")
+	}
+	if MortonEncode3D(mortonMax, mortonMax, mortonMax) != 1<<63-1 || MortonEncode3D(1<<21, 1<<22, 1<<31) != 0 {
+		t.Error("MortonEncode3D 21 bit range failed")
+	}
+}
+
+// Test MortonSort on the corners of the unit cube
+func TestMortonSort(t *testing.T) {
+
+	var points []Vector3
+	for i := 7; i >= 0; i-- {
+		points = append(points, Vector3{X: float32(i & 1), Y: float32(i >> 1 & 1), Z: float32(i >> 2 & 1)})
+	}
+	points = append(points, Vector3{X: -5, Y: -5, Z: -5}) // clamped to the first corner
+	order := MortonSort(points, NewBox3(&Vector3{}, &Vector3{X: 1, Y: 1, Z: 1}))
+	if len(order) != len(points) {
+		t.Fatal("MortonSort returned", len(order), "indices")
+	}
+	for i := 1; i < len(order); i++ {
+		a, b := &points[order[i-1]], &points[order[i]]
+		codeA := MortonEncode3D(uint32(Max(a.X, 0)), uint32(Max(a.Y, 0)), uint32(Max(a.Z, 0)))
+		codeB := MortonEncode3D(uint32(Max(b.X, 0)), uint32(Max(b.Y, 0)), uint32(Max(b.Z, 0)))
+		if codeA > codeB {
+			t.Error("MortonSort order failed at", i)
+		}
+	}
+	if order[len(order)-1] != 0 {
+		t.Error("MortonSort should end with the corner (1,1,1)")
+	}
+}
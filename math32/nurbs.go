@@ -0,0 +1,163 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"sort"
+)
+
+// NURBSCurve is a 3D non-uniform rational B-spline curve, which can represent
+// conic sections such as circles and ellipses exactly.
+// The curve is defined on the parameter interval [KnotVector[Degree], KnotVector[len(ControlPoints)]].
+type NURBSCurve struct {
+	ControlPoints []Vector4 // position (XYZ) and weight (W) of each control point
+	KnotVector    []float32 // non decreasing knots, as many as the control points plus the degree plus one
+	Degree        int
+}
+
+// NewNURBSCurve creates and returns a pointer to a new NURBSCurve with copies of the
+// specified control points and knot vector and the specified degree.
+func NewNURBSCurve(controlPoints []Vector4, knots []float32, degree int) *NURBSCurve {
+
+	if degree < 1 || len(controlPoints) <= degree {
+		panic("NewNURBSCurve: more control points than the degree are required")
+	}
+	if len(knots) != len(controlPoints)+degree+1 {
+		panic("NewNURBSCurve: invalid number of knots")
+	}
+	c := new(NURBSCurve)
+	c.ControlPoints = make([]Vector4, len(controlPoints))
+	copy(c.ControlPoints, controlPoints)
+	c.KnotVector = make([]float32, len(knots))
+	copy(c.KnotVector, knots)
+	c.Degree = degree
+	return c
+}
+
+// Domain returns the parameter interval of this curve.
+func (c *NURBSCurve) Domain() (float32, float32) {
+
+	return c.KnotVector[c.Degree], c.KnotVector[len(c.ControlPoints)]
+}
+
+// PointAt returns the point of this curve at the specified parameter, which is clamped to the
+// curve domain, using the Cox-de Boor basis functions with rational weighting.
+func (c *NURBSCurve) PointAt(t float32) Vector3 {
+
+	n := len(c.ControlPoints)
+	p := c.Degree
+	t = Clamp(t, c.KnotVector[p], c.KnotVector[n])
+	span := bsplineSpan(c.KnotVector, n, p, t)
+	basis := make([]float32, p+1)
+	derivs := make([]float32, p+1)
+	bsplineBasis(c.KnotVector, span, p, t, basis, derivs)
+	var sum Vector4
+	for i := 0; i <= p; i++ {
+		cp := &c.ControlPoints[span-p+i]
+		bw := basis[i] * cp.W
+		sum.X += cp.X * bw
+		sum.Y += cp.Y * bw
+		sum.Z += cp.Z * bw
+		sum.W += bw
+	}
+	return Vector3{sum.X / sum.W, sum.Y / sum.W, sum.Z / sum.W}
+}
+
+// InsertKnot inserts the knot t using Boehm's algorithm, adding a control point without
+// changing the shape of the curve. Knots outside the open curve domain or whose multiplicity
+// would exceed the degree are ignored.
+func (c *NURBSCurve) InsertKnot(t float32) {
+
+	n := len(c.ControlPoints)
+	if t <= c.KnotVector[c.Degree] || t >= c.KnotVector[n] || c.multiplicity(t) >= c.Degree {
+		return
+	}
+	c.insertKnot(t)
+}
+
+// multiplicity returns the number of knots equal to t.
+func (c *NURBSCurve) multiplicity(t float32) int {
+
+	m := 0
+	for _, k := range c.KnotVector {
+		if k == t {
+			m++
+		}
+	}
+	return m
+}
+
+// insertKnot inserts the knot t in homogeneous coordinates (The NURBS Book, A5.1 with one insertion).
+func (c *NURBSCurve) insertKnot(t float32) {
+
+	p := c.Degree
+	knots := c.KnotVector
+	old := c.ControlPoints
+	// Last knot not greater than t, or the last knot less than t at the end of the domain
+	k := sort.Search(len(knots), func(i int) bool { return knots[i] > t }) - 1
+	if k >= len(old) {
+		k = sort.Search(len(knots), func(i int) bool { return knots[i] >= t }) - 1
+	}
+	cps := make([]Vector4, len(old)+1)
+	for i := range cps {
+		switch {
+		case i <= k-p:
+			cps[i] = old[i]
+		case i > k:
+			cps[i] = old[i-1]
+		default:
+			// Knots equal to t give alpha 0, keeping the previous control point
+			alpha := (t - knots[i]) / (knots[i+p] - knots[i])
+			a, b := &old[i-1], &old[i]
+			wa := a.W * (1 - alpha)
+			wb := b.W * alpha
+			w := wa + wb
+			cps[i] = Vector4{(a.X*wa + b.X*wb) / w, (a.Y*wa + b.Y*wb) / w, (a.Z*wa + b.Z*wb) / w, w}
+		}
+	}
+	newKnots := make([]float32, 0, len(knots)+1)
+	newKnots = append(newKnots, knots[:k+1]...)
+	newKnots = append(newKnots, t)
+	newKnots = append(newKnots, knots[k+1:]...)
+	c.ControlPoints = cps
+	c.KnotVector = newKnots
+}
+
+// ToBezierSegments returns the rational Bezier curves of the spans of this curve, in order.
+// The knots in the curve domain are inserted in a copy of the curve up to a multiplicity equal
+// to the degree, so that the control points of each span are the control points of a Bezier curve.
+func (c *NURBSCurve) ToBezierSegments() []*BezierCurve3 {
+
+	d := NewNURBSCurve(c.ControlPoints, c.KnotVector, c.Degree)
+	p := d.Degree
+	lo, hi := d.Domain()
+	var values []float32
+	for i, k := range c.KnotVector {
+		if k >= lo && k <= hi && (i == 0 || k != c.KnotVector[i-1]) {
+			values = append(values, k)
+		}
+	}
+	for _, t := range values {
+		for d.multiplicity(t) < p {
+			d.insertKnot(t)
+		}
+	}
+
+	var segments []*BezierCurve3
+	n := len(d.ControlPoints)
+	for k := p; k < n; k++ {
+		if d.KnotVector[k] == d.KnotVector[k+1] {
+			continue
+		}
+		seg := &BezierCurve3{ControlPoints: make([]Vector3, p+1), Weights: make([]float32, p+1)}
+		for i := 0; i <= p; i++ {
+			cp := &d.ControlPoints[k-p+i]
+			seg.ControlPoints[i] = Vector3{cp.X, cp.Y, cp.Z}
+			seg.Weights[i] = cp.W
+		}
+		segments = append(segments, seg)
+	}
+	return segments
+}
@@ -0,0 +1,99 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"testing"
+)
+
+// newTestNURBSCircle returns the quadratic NURBS of the unit circle with the control polygon
+// of an equilateral triangle, whose corners have weight 0.5.
+func newTestNURBSCircle() *NURBSCurve {
+
+	controlPoints := make([]Vector4, 7)
+	for i := range controlPoints {
+		angle := float32(i) * Pi / 3
+		r, w := float32(1), float32(1)
+		if i%2 == 1 {
+			r, w = 2, 0.5
+		}
+		controlPoints[i] = Vector4{X: r * Cos(angle), Y: r * Sin(angle), W: w}
+	}
+	knots := []float32{0, 0, 0, 1.0 / 3, 1.0 / 3, 2.0 / 3, 2.0 / 3, 1, 1, 1}
+	return NewNURBSCurve(controlPoints, knots, 2)
+}
+
+// Test that the points of the circle are exactly at distance 1 from its center
+func TestNURBSCurveCircle(t *testing.T) {
+
+	c := newTestNURBSCircle()
+	for i := 0; i <= 1000; i++ {
+		p := c.PointAt(float32(i) / 1000)
+		if Abs(p.Length()-1) > 1e-6 || p.Z != 0 {
+			t.Fatal("point", p, "at", float32(i)/1000, "not on the unit circle")
+		}
+	}
+	// The curve passes through the control points with weight 1 at the knots
+	for i := 0; i < 3; i++ {
+		p := c.PointAt(float32(i) / 3)
+		expected := Vector3{X: c.ControlPoints[2*i].X, Y: c.ControlPoints[2*i].Y}
+		if p.DistanceTo(&expected) > 1e-6 {
+			t.Error("point", p, "at the knot", i, "instead of", expected)
+		}
+	}
+}
+
+// Test that the insertion of knots does not change the curve
+func TestNURBSCurveInsertKnot(t *testing.T) {
+
+	c := newTestNURBSCircle()
+	reference := make([]Vector3, 101)
+	for i := range reference {
+		reference[i] = c.PointAt(float32(i) / 100)
+	}
+	c.InsertKnot(0.1)
+	c.InsertKnot(0.5)
+	c.InsertKnot(0.5)
+	if len(c.ControlPoints) != 10 || len(c.KnotVector) != 13 {
+		t.Fatal(len(c.ControlPoints), "control points and", len(c.KnotVector), "knots instead of 10 and 13")
+	}
+	for i := range reference {
+		if p := c.PointAt(float32(i) / 100); p.DistanceTo(&reference[i]) > 1e-6 {
+			t.Error("point", p, "at", float32(i)/100, "instead of", reference[i])
+		}
+	}
+}
+
+// Test that the Bezier segments of clamped and unclamped curves reproduce their spans
+func TestNURBSCurveToBezierSegments(t *testing.T) {
+
+	circle := newTestNURBSCircle()
+	var controlPoints []Vector4
+	for i := 0; i < 6; i++ {
+		controlPoints = append(controlPoints, Vector4{X: float32(i), Y: float32(i * i % 5), Z: float32(i % 2), W: 1 + float32(i%3)})
+	}
+	unclamped := NewNURBSCurve(controlPoints, []float32{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, 3)
+
+	for _, c := range []*NURBSCurve{circle, unclamped} {
+		segments := c.ToBezierSegments()
+		lo, hi := c.Domain()
+		if len(segments) != 3 {
+			t.Fatal(len(segments), "Bezier segments instead of 3")
+		}
+		span := (hi - lo) / 3
+		for i, s := range segments {
+			if s.Degree() != c.Degree {
+				t.Error("segment of degree", s.Degree(), "instead of", c.Degree)
+			}
+			for j := 0; j <= 10; j++ {
+				p := s.PointAt(float32(j) / 10)
+				expected := c.PointAt(lo + (float32(i)+float32(j)/10)*span)
+				if p.DistanceTo(&expected) > 1e-5 {
+					t.Error("segment", i, "point", p, "instead of", expected)
+				}
+			}
+		}
+	}
+}
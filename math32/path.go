@@ -0,0 +1,276 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+// PathCurve is the interface for 3D curves parameterized by arc length,
+// which can be followed at a constant speed.
+type PathCurve interface {
+	Length() float32
+	PointAtLength(s float32) Vector3
+	TangentAtLength(s float32) Vector3
+}
+
+// catmullRomSamples is the number of samples per span used to approximate the
+// arc length parameterization of a CatmullRomSpline3.
+const catmullRomSamples = 16
+
+// PolyLine3 is a 3D curve formed by straight segments between consecutive points.
+type PolyLine3 struct {
+	points  []Vector3
+	lengths []float32 // cumulative length at each point
+}
+
+// NewPolyLine3 creates and returns a pointer to a new PolyLine3 with a copy of the specified points.
+func NewPolyLine3(points []Vector3) *PolyLine3 {
+
+	pl := new(PolyLine3)
+	pl.SetPoints(points)
+	return pl
+}
+
+// SetPoints sets the points of this polyline to a copy of the specified points.
+// Returns pointer to this updated polyline.
+func (pl *PolyLine3) SetPoints(points []Vector3) *PolyLine3 {
+
+	pl.points = make([]Vector3, len(points))
+	copy(pl.points, points)
+	pl.lengths = make([]float32, len(points))
+	for i := 1; i < len(points); i++ {
+		pl.lengths[i] = pl.lengths[i-1] + points[i].DistanceTo(&points[i-1])
+	}
+	return pl
+}
+
+// Points returns the points of this polyline.
+func (pl *PolyLine3) Points() []Vector3 {
+
+	return pl.points
+}
+
+// Length returns the total length of this polyline.
+func (pl *PolyLine3) Length() float32 {
+
+	if len(pl.lengths) == 0 {
+		return 0
+	}
+	return pl.lengths[len(pl.lengths)-1]
+}
+
+// segmentAt returns the index of the segment containing the specified
+// arc length, clamped to the polyline, and the fraction along the segment.
+func (pl *PolyLine3) segmentAt(s float32) (int, float32) {
+
+	n := len(pl.points)
+	s = Clamp(s, 0, pl.Length())
+	// Binary search of the last point with cumulative length not greater than s
+	low, high := 0, n-2
+	for low < high {
+		mid := (low + high + 1) / 2
+		if pl.lengths[mid] <= s {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	segLen := pl.lengths[low+1] - pl.lengths[low]
+	if segLen == 0 {
+		return low, 0
+	}
+	return low, (s - pl.lengths[low]) / segLen
+}
+
+// PointAtLength returns the point of this polyline at the specified arc length from its start.
+func (pl *PolyLine3) PointAtLength(s float32) Vector3 {
+
+	switch len(pl.points) {
+	case 0:
+		return Vector3{}
+	case 1:
+		return pl.points[0]
+	}
+	i, t := pl.segmentAt(s)
+	p := pl.points[i]
+	p.Lerp(&pl.points[i+1], t)
+	return p
+}
+
+// TangentAtLength returns the unit tangent of this polyline at the specified arc length from its start.
+func (pl *PolyLine3) TangentAtLength(s float32) Vector3 {
+
+	if len(pl.points) < 2 {
+		return Vector3{}
+	}
+	i, _ := pl.segmentAt(s)
+	var t Vector3
+	t.SubVectors(&pl.points[i+1], &pl.points[i])
+	if t.LengthSq() > 0 {
+		t.Normalize()
+	}
+	return t
+}
+
+// CatmullRomSpline3 is a uniform Catmull-Rom spline passing through all of its control points.
+// The first and last control points are repeated to define the end spans.
+type CatmullRomSpline3 struct {
+	points  []Vector3
+	lengths []float32 // cumulative arc length at each sample
+}
+
+// NewCatmullRomSpline3 creates and returns a pointer to a new CatmullRomSpline3
+// with a copy of the specified control points.
+func NewCatmullRomSpline3(points []Vector3) *CatmullRomSpline3 {
+
+	cr := new(CatmullRomSpline3)
+	cr.SetPoints(points)
+	return cr
+}
+
+// SetPoints sets the control points of this spline to a copy of the specified points.
+// Returns pointer to this updated spline.
+func (cr *CatmullRomSpline3) SetPoints(points []Vector3) *CatmullRomSpline3 {
+
+	cr.points = make([]Vector3, len(points))
+	copy(cr.points, points)
+	cr.lengths = nil
+	if len(points) < 2 {
+		return cr
+	}
+	// Arc length table sampling each span uniformly in the spline parameter
+	samples := (len(points) - 1) * catmullRomSamples
+	cr.lengths = make([]float32, samples+1)
+	prev := cr.PointAt(0)
+	for i := 1; i <= samples; i++ {
+		p := cr.PointAt(float32(i) / float32(samples))
+		cr.lengths[i] = cr.lengths[i-1] + p.DistanceTo(&prev)
+		prev = p
+	}
+	return cr
+}
+
+// Points returns the control points of this spline.
+func (cr *CatmullRomSpline3) Points() []Vector3 {
+
+	return cr.points
+}
+
+// span returns the index of the span of the spline at the specified
+// parameter in [0,1] and the local parameter inside the span.
+func (cr *CatmullRomSpline3) span(t float32) (int, float32) {
+
+	spans := len(cr.points) - 1
+	f := Clamp(t, 0, 1) * float32(spans)
+	i := int(f)
+	if i >= spans {
+		i = spans - 1
+	}
+	return i, f - float32(i)
+}
+
+// controlPoints returns the four control points of the specified span.
+func (cr *CatmullRomSpline3) controlPoints(i int) (p0, p1, p2, p3 *Vector3) {
+
+	last := len(cr.points) - 1
+	p0 = &cr.points[ClampInt(i-1, 0, last)]
+	p1 = &cr.points[i]
+	p2 = &cr.points[ClampInt(i+1, 0, last)]
+	p3 = &cr.points[ClampInt(i+2, 0, last)]
+	return
+}
+
+// PointAt returns the point of this spline at the specified parameter in [0,1].
+// The parameter is uniform per span, so it is not proportional to the arc length.
+func (cr *CatmullRomSpline3) PointAt(t float32) Vector3 {
+
+	switch len(cr.points) {
+	case 0:
+		return Vector3{}
+	case 1:
+		return cr.points[0]
+	}
+	i, u := cr.span(t)
+	p0, p1, p2, p3 := cr.controlPoints(i)
+	u2 := u * u
+	u3 := u2 * u
+	// Catmull-Rom basis with tension 0.5
+	b0 := 0.5 * (-u3 + 2*u2 - u)
+	b1 := 0.5 * (3*u3 - 5*u2 + 2)
+	b2 := 0.5 * (-3*u3 + 4*u2 + u)
+	b3 := 0.5 * (u3 - u2)
+	return Vector3{
+		X: b0*p0.X + b1*p1.X + b2*p2.X + b3*p3.X,
+		Y: b0*p0.Y + b1*p1.Y + b2*p2.Y + b3*p3.Y,
+		Z: b0*p0.Z + b1*p1.Z + b2*p2.Z + b3*p3.Z,
+	}
+}
+
+// TangentAt returns the unit tangent of this spline at the specified parameter in [0,1].
+func (cr *CatmullRomSpline3) TangentAt(t float32) Vector3 {
+
+	if len(cr.points) < 2 {
+		return Vector3{}
+	}
+	i, u := cr.span(t)
+	p0, p1, p2, p3 := cr.controlPoints(i)
+	u2 := u * u
+	d0 := 0.5 * (-3*u2 + 4*u - 1)
+	d1 := 0.5 * (9*u2 - 10*u)
+	d2 := 0.5 * (-9*u2 + 8*u + 1)
+	d3 := 0.5 * (3*u2 - 2*u)
+	tan := Vector3{
+		X: d0*p0.X + d1*p1.X + d2*p2.X + d3*p3.X,
+		Y: d0*p0.Y + d1*p1.Y + d2*p2.Y + d3*p3.Y,
+		Z: d0*p0.Z + d1*p1.Z + d2*p2.Z + d3*p3.Z,
+	}
+	if tan.LengthSq() > 0 {
+		tan.Normalize()
+	}
+	return tan
+}
+
+// Length returns the approximate arc length of this spline.
+func (cr *CatmullRomSpline3) Length() float32 {
+
+	if len(cr.lengths) == 0 {
+		return 0
+	}
+	return cr.lengths[len(cr.lengths)-1]
+}
+
+// paramAtLength returns the spline parameter at the specified arc length
+// by interpolating the arc length table.
+func (cr *CatmullRomSpline3) paramAtLength(s float32) float32 {
+
+	n := len(cr.lengths)
+	if n == 0 {
+		return 0
+	}
+	s = Clamp(s, 0, cr.Length())
+	low, high := 0, n-2
+	for low < high {
+		mid := (low + high + 1) / 2
+		if cr.lengths[mid] <= s {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	f := float32(0)
+	if d := cr.lengths[low+1] - cr.lengths[low]; d > 0 {
+		f = (s - cr.lengths[low]) / d
+	}
+	return (float32(low) + f) / float32(n-1)
+}
+
+// PointAtLength returns the point of this spline at the specified arc length from its start.
+func (cr *CatmullRomSpline3) PointAtLength(s float32) Vector3 {
+
+	return cr.PointAt(cr.paramAtLength(s))
+}
+
+// TangentAtLength returns the unit tangent of this spline at the specified arc length from its start.
+func (cr *CatmullRomSpline3) TangentAtLength(s float32) Vector3 {
+
+	return cr.TangentAt(cr.paramAtLength(s))
+}
@@ -0,0 +1,126 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"math/rand"
+)
+
+// Perlin is a gradient noise generator implementing Ken Perlin's improved noise.
+// Its permutation table is generated from a seed, so generators with the same seed
+// produce the same noise. It is safe for concurrent use.
+type Perlin struct {
+	perm [512]uint8
+}
+
+// NewPerlin creates and returns a pointer to a new Perlin noise generator
+// with a permutation table generated from the specified seed.
+func NewPerlin(seed int64) *Perlin {
+
+	p := new(Perlin)
+	r := rand.New(rand.NewSource(seed))
+	for i, v := range r.Perm(256) {
+		p.perm[i] = uint8(v)
+		p.perm[i+256] = uint8(v)
+	}
+	return p
+}
+
+// perlinFade is the quintic interpolation curve 6t^5-15t^4+10t^3.
+func perlinFade(t float32) float32 {
+
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+// perlinGrad returns the dot product of the gradient selected by
+// the hash with the specified offset vector.
+func perlinGrad(hash uint8, x, y, z float32) float32 {
+
+	h := hash & 15
+	u := y
+	if h < 8 {
+		u = x
+	}
+	v := z
+	if h < 4 {
+		v = y
+	} else if h == 12 || h == 14 {
+		v = x
+	}
+	if h&1 != 0 {
+		u = -u
+	}
+	if h&2 != 0 {
+		v = -v
+	}
+	return u + v
+}
+
+// Noise3D returns the noise value at the specified point, approximately in the range [-1,1].
+// The noise is zero at all integer coordinates.
+func (p *Perlin) Noise3D(x, y, z float32) float32 {
+
+	fx := Floor(x)
+	fy := Floor(y)
+	fz := Floor(z)
+	xi := int(fx) & 255
+	yi := int(fy) & 255
+	zi := int(fz) & 255
+	x -= fx
+	y -= fy
+	z -= fz
+	u := perlinFade(x)
+	v := perlinFade(y)
+	w := perlinFade(z)
+
+	perm := &p.perm
+	a := int(perm[xi]) + yi
+	aa := int(perm[a]) + zi
+	ab := int(perm[a+1]) + zi
+	b := int(perm[xi+1]) + yi
+	ba := int(perm[b]) + zi
+	bb := int(perm[b+1]) + zi
+
+	lerp := func(t, a, b float32) float32 { return a + t*(b-a) }
+	return lerp(w,
+		lerp(v,
+			lerp(u, perlinGrad(perm[aa], x, y, z), perlinGrad(perm[ba], x-1, y, z)),
+			lerp(u, perlinGrad(perm[ab], x, y-1, z), perlinGrad(perm[bb], x-1, y-1, z))),
+		lerp(v,
+			lerp(u, perlinGrad(perm[aa+1], x, y, z-1), perlinGrad(perm[ba+1], x-1, y, z-1)),
+			lerp(u, perlinGrad(perm[ab+1], x, y-1, z-1), perlinGrad(perm[bb+1], x-1, y-1, z-1))))
+}
+
+// Offsets of the samples of the three components of the warp of DomainWarpedNoise,
+// far apart so that the components are not correlated.
+var perlinWarpOffsets = [3]Vector3{{X: 0, Y: 0, Z: 0}, {X: 5.2, Y: 1.3, Z: 2.8}, {X: 1.7, Y: 9.2, Z: 4.1}}
+
+// PerlinOctaveNoise returns the fractal Brownian motion of the specified Perlin noise at the specified point:
+// the sum of the specified number of octaves of noise, the first with the specified frequency and amplitude and
+// each next one with the frequency multiplied by lacunarity and the amplitude multiplied by persistence.
+func PerlinOctaveNoise(perlin *Perlin, x, y, z, frequency, amplitude float32, octaves int, lacunarity, persistence float32) float32 {
+
+	var sum float32
+	for o := 0; o < octaves; o++ {
+		sum += amplitude * perlin.Noise3D(x*frequency, y*frequency, z*frequency)
+		frequency *= lacunarity
+		amplitude *= persistence
+	}
+	return sum
+}
+
+// DomainWarpedNoise returns the domain warped fractal noise of the specified Perlin noise at the specified point,
+// as described by Inigo Quilez: the octave noise at the point offset by a vector of octave noises times
+// warpStrength, which twists the features of the noise into more organic shapes.
+// The octaves have frequencies doubling from 1 and amplitudes halving from 1.
+func DomainWarpedNoise(perlin *Perlin, x, y, z float32, warpStrength float32, octaves int) float32 {
+
+	var warp [3]float32
+	for i := range warp {
+		o := &perlinWarpOffsets[i]
+		warp[i] = PerlinOctaveNoise(perlin, x+o.X, y+o.Y, z+o.Z, 1, 1, octaves, 2, 0.5)
+	}
+	return PerlinOctaveNoise(perlin, x+warpStrength*warp[0], y+warpStrength*warp[1], z+warpStrength*warp[2], 1, 1, octaves, 2, 0.5)
+}
@@ -0,0 +1,186 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"math/rand"
+)
+
+// poissonCandidates is the number of candidates generated around each active sample
+// before it is removed from the active list (k in Bridson's algorithm).
+const poissonCandidates = 30
+
+// PoissonDiskSampler3D generates points inside a box with no two points closer than a minimum
+// distance, using Bridson's fast Poisson disk sampling with a background grid of cells
+// small enough to contain at most one point each.
+// The generated points are kept, so that successive calls extend the same distribution.
+type PoissonDiskSampler3D struct {
+	bounds   Box3       // sampled volume
+	minDist  float32    // minimum distance between points
+	cellSize float32    // size of the grid cells
+	dims     [3]int     // number of grid cells along each axis
+	grid     []int32    // index of the point in each cell or -1
+	points   []Vector3  // generated points
+	active   []int32    // indices of the points around which new points may be generated
+	rng      *rand.Rand // random number generator
+}
+
+// NewPoissonDiskSampler3D creates and returns a pointer to a new PoissonDiskSampler3D for the specified
+// bounds and minimum distance, using the specified random number generator.
+// If rng is nil a generator with a fixed seed is used.
+func NewPoissonDiskSampler3D(bounds *Box3, minDist float32, rng *rand.Rand) *PoissonDiskSampler3D {
+
+	ps := new(PoissonDiskSampler3D)
+	ps.bounds = *bounds
+	ps.minDist = minDist
+	ps.rng = rng
+	if ps.rng == nil {
+		ps.rng = rand.New(rand.NewSource(1))
+	}
+	// A cell of diagonal minDist contains at most one point
+	ps.cellSize = minDist / Sqrt(3)
+	var size Vector3
+	size.SubVectors(&bounds.Max, &bounds.Min)
+	for i, s := range [3]float32{size.X, size.Y, size.Z} {
+		ps.dims[i] = int(Ceil(s / ps.cellSize))
+		if ps.dims[i] < 1 {
+			ps.dims[i] = 1
+		}
+	}
+	ps.grid = make([]int32, ps.dims[0]*ps.dims[1]*ps.dims[2])
+	ps.Reset()
+	return ps
+}
+
+// Reset removes all the generated points.
+func (ps *PoissonDiskSampler3D) Reset() {
+
+	for i := range ps.grid {
+		ps.grid[i] = -1
+	}
+	ps.points = ps.points[:0]
+	ps.active = ps.active[:0]
+}
+
+// Points returns all the points generated since the last reset.
+func (ps *PoissonDiskSampler3D) Points() []Vector3 {
+
+	return ps.points
+}
+
+// MinDistance returns the minimum distance between points.
+func (ps *PoissonDiskSampler3D) MinDistance() float32 {
+
+	return ps.minDist
+}
+
+// cell returns the grid coordinates of the cell containing the specified point.
+func (ps *PoissonDiskSampler3D) cell(p *Vector3) (int, int, int) {
+
+	x := ClampInt(int((p.X-ps.bounds.Min.X)/ps.cellSize), 0, ps.dims[0]-1)
+	y := ClampInt(int((p.Y-ps.bounds.Min.Y)/ps.cellSize), 0, ps.dims[1]-1)
+	z := ClampInt(int((p.Z-ps.bounds.Min.Z)/ps.cellSize), 0, ps.dims[2]-1)
+	return x, y, z
+}
+
+// valid returns if the specified point is inside the bounds and
+// farther than the minimum distance from all the generated points.
+func (ps *PoissonDiskSampler3D) valid(p *Vector3) bool {
+
+	if !ps.bounds.ContainsPoint(p) {
+		return false
+	}
+	cx, cy, cz := ps.cell(p)
+	// Points closer than minDist can only be within two cells
+	minDistSq := ps.minDist * ps.minDist
+	for z := ClampInt(cz-2, 0, ps.dims[2]-1); z <= ClampInt(cz+2, 0, ps.dims[2]-1); z++ {
+		for y := ClampInt(cy-2, 0, ps.dims[1]-1); y <= ClampInt(cy+2, 0, ps.dims[1]-1); y++ {
+			for x := ClampInt(cx-2, 0, ps.dims[0]-1); x <= ClampInt(cx+2, 0, ps.dims[0]-1); x++ {
+				idx := ps.grid[x+ps.dims[0]*(y+ps.dims[1]*z)]
+				if idx >= 0 && ps.points[idx].DistanceToSquared(p) < minDistSq {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// add adds the specified point to the generated and active points.
+func (ps *PoissonDiskSampler3D) add(p *Vector3) {
+
+	x, y, z := ps.cell(p)
+	idx := int32(len(ps.points))
+	ps.grid[x+ps.dims[0]*(y+ps.dims[1]*z)] = idx
+	ps.points = append(ps.points, *p)
+	ps.active = append(ps.active, idx)
+}
+
+// candidate returns a random point uniformly distributed in the spherical
+// shell of radii minDist and 2*minDist around the specified center.
+func (ps *PoissonDiskSampler3D) candidate(center *Vector3) Vector3 {
+
+	// Uniform direction
+	z := 2*ps.rng.Float32() - 1
+	phi := 2 * Pi * ps.rng.Float32()
+	s := Sqrt(1 - z*z)
+	// Radius with uniform distribution by volume: r^3 uniform in [minDist^3, 8*minDist^3]
+	r := ps.minDist * Pow(1+7*ps.rng.Float32(), 1.0/3)
+	return Vector3{X: center.X + r*s*Cos(phi), Y: center.Y + r*s*Sin(phi), Z: center.Z + r*z}
+}
+
+// Generate generates points filling the bounds as densely as possible, until there is no space for
+// new points or the total number of points reaches maxPoints, and returns all the generated points.
+// If no points were generated yet, the first point is chosen randomly inside the bounds.
+func (ps *PoissonDiskSampler3D) Generate(maxPoints int) []Vector3 {
+
+	if len(ps.points) == 0 && maxPoints > 0 {
+		var size Vector3
+		size.SubVectors(&ps.bounds.Max, &ps.bounds.Min)
+		p := Vector3{
+			X: ps.bounds.Min.X + ps.rng.Float32()*size.X,
+			Y: ps.bounds.Min.Y + ps.rng.Float32()*size.Y,
+			Z: ps.bounds.Min.Z + ps.rng.Float32()*size.Z,
+		}
+		ps.add(&p)
+	}
+	for len(ps.active) > 0 && len(ps.points) < maxPoints {
+		// Random active point
+		ai := ps.rng.Intn(len(ps.active))
+		center := ps.points[ps.active[ai]]
+		found := false
+		for k := 0; k < poissonCandidates; k++ {
+			p := ps.candidate(&center)
+			if ps.valid(&p) {
+				ps.add(&p)
+				found = true
+				break
+			}
+		}
+		if !found {
+			// Removes the point from the active list
+			last := len(ps.active) - 1
+			ps.active[ai] = ps.active[last]
+			ps.active = ps.active[:last]
+		}
+	}
+	return ps.points
+}
+
+// GenerateAround generates up to n new points in the spherical shell of radii minDist and 2*minDist
+// around the specified seed, respecting the minimum distance from each other and from the
+// previously generated points, and returns the new points.
+// Fewer points are returned if there is no space for n points in the shell.
+func (ps *PoissonDiskSampler3D) GenerateAround(seed *Vector3, n int) []Vector3 {
+
+	start := len(ps.points)
+	for tries := 0; tries < n*poissonCandidates && len(ps.points)-start < n; tries++ {
+		p := ps.candidate(seed)
+		if ps.valid(&p) {
+			ps.add(&p)
+		}
+	}
+	return ps.points[start:]
+}
@@ -0,0 +1,185 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+// Polygon3 is a planar polygon embedded in 3D space.
+// Its plane is fitted to the vertices by Newell's method, so the vertices
+// may be slightly out of plane, and its normal follows the counter clockwise order of the vertices.
+type Polygon3 struct {
+	vertices []Vector3
+	plane    Plane
+	u, v     Vector3 // orthonormal axes of the local 2D frame of the plane
+	origin   Vector3 // origin of the local 2D frame
+}
+
+// NewPolygon3 creates and returns a pointer to a new Polygon3 with a copy of the specified vertices.
+func NewPolygon3(vertices []Vector3) *Polygon3 {
+
+	pg := new(Polygon3)
+	pg.vertices = make([]Vector3, len(vertices))
+	copy(pg.vertices, vertices)
+
+	// Newell's method: the normal is the sum of the cross products of the edges
+	var normal Vector3
+	for i := range vertices {
+		a, b := &vertices[i], &vertices[(i+1)%len(vertices)]
+		normal.X += (a.Y - b.Y) * (a.Z + b.Z)
+		normal.Y += (a.Z - b.Z) * (a.X + b.X)
+		normal.Z += (a.X - b.X) * (a.Y + b.Y)
+		pg.origin.Add(a)
+	}
+	if len(vertices) > 0 {
+		pg.origin.DivideScalar(float32(len(vertices)))
+	}
+	if normal.LengthSq() > 0 {
+		normal.Normalize()
+		// First axis orthogonal to the normal from the axis of its smallest component
+		axis := Vector3{X: 1}
+		if Abs(normal.Y) < Abs(normal.X) && Abs(normal.Y) <= Abs(normal.Z) {
+			axis = Vector3{Y: 1}
+		} else if Abs(normal.Z) < Abs(normal.X) && Abs(normal.Z) < Abs(normal.Y) {
+			axis = Vector3{Z: 1}
+		}
+		pg.u.CrossVectors(&axis, &normal).Normalize()
+		pg.v.CrossVectors(&normal, &pg.u)
+	}
+	pg.plane.SetFromNormalAndCoplanarPoint(&normal, &pg.origin)
+	return pg
+}
+
+// Vertices returns the vertices of this polygon.
+func (pg *Polygon3) Vertices() []Vector3 {
+
+	return pg.vertices
+}
+
+// Plane returns the plane of this polygon.
+func (pg *Polygon3) Plane() Plane {
+
+	return pg.plane
+}
+
+// project returns the coordinates of the specified point projected on the local 2D frame of the plane.
+func (pg *Polygon3) project(p *Vector3) Vector2 {
+
+	var d Vector3
+	d.SubVectors(p, &pg.origin)
+	return Vector2{d.Dot(&pg.u), d.Dot(&pg.v)}
+}
+
+// projected returns the vertices of this polygon projected on the local 2D frame of the plane,
+// where they are in counter clockwise order.
+func (pg *Polygon3) projected() []Vector2 {
+
+	points := make([]Vector2, len(pg.vertices))
+	for i := range pg.vertices {
+		points[i] = pg.project(&pg.vertices[i])
+	}
+	return points
+}
+
+// IsConvex returns if this polygon is convex, testing its projection on the plane.
+// Collinear consecutive vertices are allowed.
+func (pg *Polygon3) IsConvex() bool {
+
+	points := pg.projected()
+	n := len(points)
+	if n < 3 {
+		return false
+	}
+	// All turns must be to the left and add to a single revolution
+	var turning float32
+	for i := range points {
+		a, b, c := &points[i], &points[(i+1)%n], &points[(i+2)%n]
+		e1 := Vector2{b.X - a.X, b.Y - a.Y}
+		e2 := Vector2{c.X - b.X, c.Y - b.Y}
+		cross := e1.X*e2.Y - e1.Y*e2.X
+		if cross < 0 {
+			return false
+		}
+		turning += Atan2(cross, e1.X*e2.X+e1.Y*e2.Y)
+	}
+	return Abs(turning-2*Pi) < 1e-3
+}
+
+// Area returns the area of this polygon, which is half of the length
+// of the sum of the cross products of its consecutive vertices.
+func (pg *Polygon3) Area() float32 {
+
+	var sum, cross Vector3
+	for i := range pg.vertices {
+		cross.CrossVectors(&pg.vertices[i], &pg.vertices[(i+1)%len(pg.vertices)])
+		sum.Add(&cross)
+	}
+	return sum.Length() / 2
+}
+
+// Centroid returns a pointer to a new vector with the center of mass of the area of this polygon.
+// The average of the vertices is returned for degenerate polygons.
+func (pg *Polygon3) Centroid() *Vector3 {
+
+	var centroid, cross, e1, e2 Vector3
+	var area float32
+	normal := pg.plane.Normal()
+	for i := 1; i+1 < len(pg.vertices); i++ {
+		// Signed area of each triangle of the fan from the first vertex
+		first, b, c := &pg.vertices[0], &pg.vertices[i], &pg.vertices[i+1]
+		e1.SubVectors(b, first)
+		e2.SubVectors(c, first)
+		a := cross.CrossVectors(&e1, &e2).Dot(&normal) / 2
+		centroid.X += a * (first.X + b.X + c.X) / 3
+		centroid.Y += a * (first.Y + b.Y + c.Y) / 3
+		centroid.Z += a * (first.Z + b.Z + c.Z) / 3
+		area += a
+	}
+	if area == 0 {
+		c := pg.origin
+		return &c
+	}
+	return centroid.DivideScalar(area)
+}
+
+// Triangulate returns the triangles of this polygon computed by ear clipping its projection on the plane.
+// Returns an error if the projected polygon is degenerate or self intersecting.
+func (pg *Polygon3) Triangulate() ([]Triangle, error) {
+
+	indices, err := TriangulatePolygon(pg.projected())
+	if err != nil {
+		return nil, err
+	}
+	triangles := make([]Triangle, 0, len(indices)/3)
+	for i := 0; i+2 < len(indices); i += 3 {
+		triangles = append(triangles, Triangle{
+			a: pg.vertices[indices[i]],
+			b: pg.vertices[indices[i+1]],
+			c: pg.vertices[indices[i+2]],
+		})
+	}
+	return triangles, nil
+}
+
+// ContainsPoint returns if the specified point is at most epsilon away from the plane of this
+// polygon and its projection on the plane is inside the polygon by the winding number rule.
+func (pg *Polygon3) ContainsPoint(p *Vector3, epsilon float32) bool {
+
+	if Abs(pg.plane.DistanceToPoint(p)) > epsilon {
+		return false
+	}
+	q := pg.project(p)
+	points := pg.projected()
+	winding := 0
+	for i := range points {
+		a, b := &points[i], &points[(i+1)%len(points)]
+		side := (b.X-a.X)*(q.Y-a.Y) - (q.X-a.X)*(b.Y-a.Y)
+		if a.Y <= q.Y {
+			if b.Y > q.Y && side > 0 {
+				winding++
+			}
+		} else if b.Y <= q.Y && side < 0 {
+			winding--
+		}
+	}
+	return winding != 0
+}
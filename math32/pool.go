@@ -0,0 +1,48 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"sync"
+)
+
+// MemoryPool is a pool of reusable values of type T, backed by a sync.Pool, which reduces
+// the allocations of the temporary values of inner loops. It is safe for concurrent use.
+type MemoryPool[T any] struct {
+	pool  sync.Pool
+	reset func(*T)
+}
+
+// NewMemoryPool creates and returns a pointer to a new MemoryPool of values of type T.
+// The optional reset function is called with the released values before they are reused.
+func NewMemoryPool[T any](reset func(*T)) *MemoryPool[T] {
+
+	mp := new(MemoryPool[T])
+	mp.pool.New = func() any { return new(T) }
+	mp.reset = reset
+	return mp
+}
+
+// Acquire returns a value from the pool, allocating a zero value if the pool is empty.
+func (mp *MemoryPool[T]) Acquire() *T {
+
+	return mp.pool.Get().(*T)
+}
+
+// Release returns the specified value to the pool. It must not be used after.
+func (mp *MemoryPool[T]) Release(v *T) {
+
+	if mp.reset != nil {
+		mp.reset(v)
+	}
+	mp.pool.Put(v)
+}
+
+// Pools of the frequently allocated types, which zero the released values.
+var (
+	Line3Pool   = NewMemoryPool(func(l *Line3) { *l = Line3{} })
+	Vector3Pool = NewMemoryPool(func(v *Vector3) { *v = Vector3{} })
+	RayPool     = NewMemoryPool(func(r *Ray) { *r = Ray{} })
+)
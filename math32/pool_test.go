@@ -0,0 +1,91 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// Test that the acquired values are zero after the release of modified values, also concurrently
+func TestMemoryPoolReset(t *testing.T) {
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				v := Vector3Pool.Acquire()
+				r := RayPool.Acquire()
+				l := Line3Pool.Acquire()
+				if *v != (Vector3{}) || *r != (Ray{}) || *l != (Line3{}) {
+					t.Error("acquired values", *v, *r, *l, "not zero")
+					return
+				}
+				v.Set(1, 2, 3)
+				r.Set(v, &Vector3{Z: 1})
+				l.Set(v, v)
+				Vector3Pool.Release(v)
+				RayPool.Release(r)
+				Line3Pool.Release(l)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// poolSink keeps the results of the benchmarks.
+var poolSink float32
+
+// benchmarkRayCast runs a ray casting inner loop against spheres getting its temporary ray
+// and hit point from the specified functions, and reports the GC pause time per operation.
+func benchmarkRayCast(b *testing.B, acquire func() (*Ray, *Vector3), release func(*Ray, *Vector3)) {
+
+	spheres := make([]Sphere, 64)
+	for i := range spheres {
+		spheres[i] = Sphere{Center: Vector3{X: float32(i%8) - 4, Y: float32(i/8) - 4, Z: -10}, Radius: 0.4}
+	}
+	var stats runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&stats)
+	pause := stats.PauseTotalNs
+	b.ResetTimer()
+	var sum float32
+	for i := 0; i < b.N; i++ {
+		dir := Vector3{X: float32(i%17)/17 - 0.5, Y: float32(i%13)/13 - 0.5, Z: -1}
+		dir.Normalize()
+		for k := range spheres {
+			ray, hit := acquire()
+			ray.Set(&Vector3{}, &dir)
+			if ray.IntersectSphere(&spheres[k], hit) != nil {
+				sum += hit.Z
+			}
+			release(ray, hit)
+		}
+	}
+	poolSink = sum
+	b.StopTimer()
+	runtime.ReadMemStats(&stats)
+	b.ReportMetric(float64(stats.PauseTotalNs-pause)/float64(b.N), "gc-pause-ns/op")
+}
+
+// Benchmark a ray casting inner loop allocating its temporary values
+func BenchmarkRayCastAllocated(b *testing.B) {
+
+	benchmarkRayCast(b, func() (*Ray, *Vector3) { return NewRay(nil, nil), NewVector3(0, 0, 0) },
+		func(*Ray, *Vector3) {})
+}
+
+// Benchmark a ray casting inner loop getting its temporary values from the pools
+func BenchmarkRayCastPooled(b *testing.B) {
+
+	benchmarkRayCast(b, func() (*Ray, *Vector3) { return RayPool.Acquire(), Vector3Pool.Acquire() },
+		func(ray *Ray, hit *Vector3) {
+			RayPool.Release(ray)
+			Vector3Pool.Release(hit)
+		})
+}
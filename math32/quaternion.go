@@ -171,6 +171,43 @@ func (q *Quaternion) SetFromRotationMatrix(m *Matrix4) *Quaternion {
 	return q
 }
 
+// LookRotation sets this quaternion to the rotation of the +Z axis to the specified forward direction,
+// rotating the +Y axis as close as possible to the specified up vector: the right vector is the cross product
+// of up and forward, and the up vector is orthogonalized against the forward direction.
+// If the forward direction is nearly parallel to the up vector, the axis least aligned with it is used as up.
+// Sets the identity if the forward direction is zero.
+// Returns pointer to this updated quaternion.
+func (q *Quaternion) LookRotation(forward, up *Vector3) *Quaternion {
+
+	var x, y, z Vector3
+	z.Copy(forward)
+	if z.LengthSq() == 0 {
+		return q.SetIdentity()
+	}
+	z.Normalize()
+	y.Copy(up).Normalize()
+	if y.LengthSq() == 0 || Abs(y.Dot(&z)) > 0.9999 {
+		switch {
+		case Abs(z.X) <= Abs(z.Y) && Abs(z.X) <= Abs(z.Z):
+			y.Set(1, 0, 0)
+		case Abs(z.Y) <= Abs(z.Z):
+			y.Set(0, 1, 0)
+		default:
+			y.Set(0, 0, 1)
+		}
+	}
+	x.CrossVectors(&y, &z).Normalize()
+	y.CrossVectors(&z, &x)
+	var m Matrix4
+	m.Set(
+		x.X, y.X, z.X, 0,
+		x.Y, y.Y, z.Y, 0,
+		x.Z, y.Z, z.Z, 0,
+		0, 0, 0, 1,
+	)
+	return q.SetFromRotationMatrix(&m)
+}
+
 // SetFromUnitVectors sets this quaternion to the rotation from vector vFrom to vTo.
 // The vectors must be normalized.
 // Returns pointer to this updated quaternion.
@@ -369,6 +406,27 @@ func (q *Quaternion) Slerp(other *Quaternion, t float32) *Quaternion {
 	return q
 }
 
+// RotateVector calculates the specified vector rotated by this unit quaternion, without building a rotation matrix:
+// 2*(u·v)*u + (2*w²-1)*v + 2*w*(u×v), where u is the vector part of the quaternion.
+// Stores the result into optionalTarget, if not nil, and also returns it. The target may be the vector itself.
+func (q *Quaternion) RotateVector(v *Vector3, optionalTarget *Vector3) *Vector3 {
+
+	var result *Vector3
+	if optionalTarget == nil {
+		result = NewVector3(0, 0, 0)
+	} else {
+		result = optionalTarget
+	}
+	x, y, z := v.X, v.Y, v.Z
+	dot2 := 2 * (q.X*x + q.Y*y + q.Z*z)
+	s := 2*q.W*q.W - 1
+	w2 := 2 * q.W
+	result.X = dot2*q.X + s*x + w2*(q.Y*z-q.Z*y)
+	result.Y = dot2*q.Y + s*y + w2*(q.Z*x-q.X*z)
+	result.Z = dot2*q.Z + s*z + w2*(q.X*y-q.Y*x)
+	return result
+}
+
 // Equals returns if this quaternion is equal to other.
 func (q *Quaternion) Equals(other *Quaternion) bool {
 
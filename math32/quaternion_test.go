@@ -0,0 +1,75 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Test that RotateVector is equal to the rotation by the matrix of the quaternion, also in place
+func TestQuaternionRotateVector(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(1))
+	var m Matrix4
+	for i := 0; i < 1000; i++ {
+		axis := Vector3{X: rng.Float32() - 0.5, Y: rng.Float32() - 0.5, Z: rng.Float32() - 0.5}
+		q := NewQuaternion(0, 0, 0, 1).SetFromAxisAngle(axis.Normalize(), rng.Float32()*2*Pi)
+		v := Vector3{X: rng.Float32()*10 - 5, Y: rng.Float32()*10 - 5, Z: rng.Float32()*10 - 5}
+		expected := v
+		expected.ApplyMatrix4(m.MakeRotationFromQuaternion(q))
+		if r := q.RotateVector(&v, nil); !r.AlmostEquals(&expected, 1e-5*v.Length()) {
+			t.Fatal("RotateVector of", v, "by", *q, "is", *r, "instead of", expected)
+		}
+		if q.RotateVector(&v, &v); !v.AlmostEquals(&expected, 1e-5*v.Length()) {
+			t.Fatal("RotateVector in place is", v, "instead of", expected)
+		}
+	}
+	q := NewQuaternion(0, 0, 0, 1).SetFromAxisAngle(&Vector3{Z: 1}, Pi/2)
+	if r := q.RotateVector(&Vector3{X: 1}, nil); !r.AlmostEquals(&Vector3{Y: 1}, 1e-6) {
+		t.Error("quarter turn around Z of (1,0,0) is", *r, "instead of (0,1,0)")
+	}
+}
+
+// benchmarkVectors returns 1M random vectors and a rotation.
+func benchmarkVectors() ([]Vector3, *Quaternion) {
+
+	rng := rand.New(rand.NewSource(1))
+	vectors := make([]Vector3, 1<<20)
+	for i := range vectors {
+		vectors[i] = Vector3{X: rng.Float32(), Y: rng.Float32(), Z: rng.Float32()}
+	}
+	return vectors, NewQuaternion(0, 0, 0, 1).SetFromAxisAngle(NewVector3(1, 2, 3).Normalize(), 1.2)
+}
+
+// Benchmark the rotation of 1M vectors with RotateVector
+func BenchmarkQuaternionRotateVector(b *testing.B) {
+
+	vectors, q := benchmarkVectors()
+	out := make([]Vector3, len(vectors))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for k := range vectors {
+			q.RotateVector(&vectors[k], &out[k])
+		}
+	}
+}
+
+// Benchmark the rotation of 1M vectors with the rotation matrix of the quaternion built for each vector,
+// to compare with BenchmarkQuaternionRotateVector
+func BenchmarkQuaternionRotateMatrix(b *testing.B) {
+
+	vectors, q := benchmarkVectors()
+	out := make([]Vector3, len(vectors))
+	var m Matrix4
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for k := range vectors {
+			m.MakeRotationFromQuaternion(q)
+			out[k] = vectors[k]
+			out[k].ApplyMatrix4(&m)
+		}
+	}
+}
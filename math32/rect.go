@@ -0,0 +1,313 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"encoding/json"
+)
+
+// Rect represents a 2D axis-aligned rectangle defined by two points:
+// the point with minimum coordinates and the point with maximum coordinates.
+// It is used for UI layout, viewport and scissor rectangles and 2D broad phase tests.
+type Rect struct {
+	Min Vector2
+	Max Vector2
+}
+
+// NewRect creates and returns a pointer to a new Rect defined
+// by its minimum and maximum coordinates.
+func NewRect(min, max *Vector2) *Rect {
+
+	r := new(Rect)
+	r.Set(min, max)
+	return r
+}
+
+// Set sets this rectangle minimum and maximum coordinates.
+// Returns pointer to this updated rectangle.
+func (r *Rect) Set(min, max *Vector2) *Rect {
+
+	if min != nil {
+		r.Min = *min
+	} else {
+		r.Min.Set(Infinity, Infinity)
+	}
+	if max != nil {
+		r.Max = *max
+	} else {
+		r.Max.Set(-Infinity, -Infinity)
+	}
+	return r
+}
+
+// Copy copy other to this rectangle.
+// Returns pointer to this updated rectangle.
+func (r *Rect) Copy(other *Rect) *Rect {
+
+	*r = *other
+	return r
+}
+
+// MakeEmpty set this rectangle to empty.
+// Returns pointer to this updated rectangle.
+func (r *Rect) MakeEmpty() *Rect {
+
+	r.Min.Set(Infinity, Infinity)
+	r.Max.Set(-Infinity, -Infinity)
+	return r
+}
+
+// Empty returns if this rectangle is empty.
+func (r *Rect) Empty() bool {
+
+	return (r.Max.X < r.Min.X) || (r.Max.Y < r.Min.Y)
+}
+
+// Width returns the width of this rectangle.
+func (r *Rect) Width() float32 {
+
+	return r.Max.X - r.Min.X
+}
+
+// Height returns the height of this rectangle.
+func (r *Rect) Height() float32 {
+
+	return r.Max.Y - r.Min.Y
+}
+
+// Area returns the area of this rectangle or zero if it is empty.
+func (r *Rect) Area() float32 {
+
+	if r.Empty() {
+		return 0
+	}
+	return r.Width() * r.Height()
+}
+
+// Center calculates the center point of this rectangle and
+// stores its pointer to optionalTarget, if not nil, and also returns it.
+func (r *Rect) Center(optionalTarget *Vector2) *Vector2 {
+
+	var result *Vector2
+	if optionalTarget == nil {
+		result = NewVector2(0, 0)
+	} else {
+		result = optionalTarget
+	}
+	return result.AddVectors(&r.Min, &r.Max).MultiplyScalar(0.5)
+}
+
+// Size calculates the size of this rectangle: the vector from
+// its minimum point to its maximum point.
+// Store pointer to the calculated size into optionalTarget, if not nil,
+// and also returns it.
+func (r *Rect) Size(optionalTarget *Vector2) *Vector2 {
+
+	var result *Vector2
+	if optionalTarget == nil {
+		result = NewVector2(0, 0)
+	} else {
+		result = optionalTarget
+	}
+	return result.SubVectors(&r.Max, &r.Min)
+}
+
+// ContainsPoint returns if this rectangle contains the specified point.
+// Points on the border are considered to be inside the rectangle.
+func (r *Rect) ContainsPoint(point *Vector2) bool {
+
+	if point.X < r.Min.X || point.X > r.Max.X ||
+		point.Y < r.Min.Y || point.Y > r.Max.Y {
+		return false
+	}
+	return true
+}
+
+// ContainsRect returns if this rectangle entirely contains other rectangle.
+func (r *Rect) ContainsRect(other *Rect) bool {
+
+	if (r.Min.X <= other.Min.X) && (other.Max.X <= r.Max.X) &&
+		(r.Min.Y <= other.Min.Y) && (other.Max.Y <= r.Max.Y) {
+		return true
+	}
+	return false
+}
+
+// Intersects returns if other rectangle intersects this one.
+// Rectangles which only share a border are considered to intersect.
+func (r *Rect) Intersects(other *Rect) bool {
+
+	if other.Max.X < r.Min.X || other.Min.X > r.Max.X ||
+		other.Max.Y < r.Min.Y || other.Min.Y > r.Max.Y {
+		return false
+	}
+	return true
+}
+
+// IntersectionRect calculates the intersection of this rectangle with other.
+// If the rectangles do not intersect the result is an empty rectangle.
+// Stores the pointer to the result into optionalTarget, if not nil, and also returns it.
+func (r *Rect) IntersectionRect(other *Rect, optionalTarget *Rect) *Rect {
+
+	var result *Rect
+	if optionalTarget == nil {
+		result = new(Rect)
+	} else {
+		result = optionalTarget
+	}
+	if !r.Intersects(other) {
+		return result.MakeEmpty()
+	}
+	min := r.Min
+	max := r.Max
+	min.Max(&other.Min)
+	max.Min(&other.Max)
+	result.Min = min
+	result.Max = max
+	return result
+}
+
+// Union sets this rectangle to the union with other rectangle.
+// Returns pointer to this updated rectangle.
+func (r *Rect) Union(other *Rect) *Rect {
+
+	r.Min.Min(&other.Min)
+	r.Max.Max(&other.Max)
+	return r
+}
+
+// Expand expands this rectangle by the specified amount on all sides.
+// A negative amount shrinks the rectangle.
+// Returns pointer to this updated rectangle.
+func (r *Rect) Expand(amount float32) *Rect {
+
+	r.Min.AddScalar(-amount)
+	r.Max.AddScalar(amount)
+	return r
+}
+
+// ExpandByPoint may expand this rectangle to include the specified point.
+// Returns pointer to this updated rectangle.
+func (r *Rect) ExpandByPoint(point *Vector2) *Rect {
+
+	r.Min.Min(point)
+	r.Max.Max(point)
+	return r
+}
+
+// ApplyMatrix3 transforms this rectangle by the specified 2D affine transform
+// matrix and sets it to the axis-aligned rectangle containing the four transformed corners.
+// Returns pointer to this updated rectangle.
+func (r *Rect) ApplyMatrix3(m *Matrix3) *Rect {
+
+	if r.Empty() {
+		return r
+	}
+	corners := [4]Vector2{
+		{r.Min.X, r.Min.Y},
+		{r.Min.X, r.Max.Y},
+		{r.Max.X, r.Min.Y},
+		{r.Max.X, r.Max.Y},
+	}
+	r.MakeEmpty()
+	for i := 0; i < len(corners); i++ {
+		x := corners[i].X
+		y := corners[i].Y
+		corners[i].X = m[0]*x + m[3]*y + m[6]
+		corners[i].Y = m[1]*x + m[4]*y + m[7]
+		r.ExpandByPoint(&corners[i])
+	}
+	return r
+}
+
+// Clamp clamps the specified point to the inside of this rectangle.
+// Returns the pointer to the updated point.
+func (r *Rect) Clamp(p *Vector2) *Vector2 {
+
+	return p.Clamp(&r.Min, &r.Max)
+}
+
+// Split subdivides this rectangle in two along the specified axis (0 for X, 1 for Y)
+// at the fractional position t in [0,1] and returns the two resulting rectangles.
+// The first rectangle contains the minimum point and the second the maximum point.
+func (r *Rect) Split(t float32, axis int) (Rect, Rect) {
+
+	t = Clamp(t, 0, 1)
+	first := *r
+	second := *r
+	switch axis {
+	case 0:
+		x := r.Min.X + t*r.Width()
+		first.Max.X = x
+		second.Min.X = x
+	case 1:
+		y := r.Min.Y + t*r.Height()
+		first.Max.Y = y
+		second.Min.Y = y
+	default:
+		panic("index is out of range")
+	}
+	return first, second
+}
+
+// Translate translates the position of this rectangle by offset.
+// Returns pointer to this updated rectangle.
+func (r *Rect) Translate(offset *Vector2) *Rect {
+
+	r.Min.Add(offset)
+	r.Max.Add(offset)
+	return r
+}
+
+// Equals returns if this rectangle is equal to other.
+func (r *Rect) Equals(other *Rect) bool {
+
+	return other.Min.Equals(&r.Min) && other.Max.Equals(&r.Max)
+}
+
+// Clone creates and returns a pointer to a copy of this rectangle.
+func (r *Rect) Clone() *Rect {
+
+	return NewRect(&r.Min, &r.Max)
+}
+
+// rectJSON is the JSON representation of a Rect.
+type rectJSON struct {
+	Min   [2]float32 `json:"min"`
+	Max   [2]float32 `json:"max"`
+	Empty bool       `json:"empty,omitempty"`
+}
+
+// MarshalJSON satisfies the json.Marshaler interface.
+// The rectangle is encoded as {"min":[x,y],"max":[x,y]}, or as {"empty":true}
+// if it is empty, as its infinite coordinates cannot be encoded.
+func (r Rect) MarshalJSON() ([]byte, error) {
+
+	if r.Empty() {
+		return []byte(`{"empty":true}`), nil
+	}
+	return json.Marshal(rectJSON{
+		Min: [2]float32{r.Min.X, r.Min.Y},
+		Max: [2]float32{r.Max.X, r.Max.Y},
+	})
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface.
+// An empty rectangle is decoded as the rectangle set by MakeEmpty.
+func (r *Rect) UnmarshalJSON(data []byte) error {
+
+	var rj rectJSON
+	err := json.Unmarshal(data, &rj)
+	if err != nil {
+		return err
+	}
+	if rj.Empty {
+		r.MakeEmpty()
+		return nil
+	}
+	r.Min.Set(rj.Min[0], rj.Min[1])
+	r.Max.Set(rj.Max[0], rj.Max[1])
+	return nil
+}
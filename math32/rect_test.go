@@ -0,0 +1,43 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// Test the JSON encoding and decoding of a rectangle and of the empty rectangle
+func TestRectJSON(t *testing.T) {
+
+	r := Rect{Min: Vector2{X: -1, Y: 0.5}, Max: Vector2{X: 2, Y: 3}}
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"min":[-1,0.5],"max":[2,3]}` {
+		t.Error("rectangle encoded as", string(data))
+	}
+	var decoded Rect
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded != r {
+		t.Error("rectangle decoded as", decoded, err, "instead of", r)
+	}
+
+	// The empty rectangle inside another value
+	value := struct{ Bounds Rect }{}
+	value.Bounds.MakeEmpty()
+	data, err = json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"Bounds":{"empty":true}}` {
+		t.Error("empty rectangle encoded as", string(data))
+	}
+	value.Bounds = r
+	if err := json.Unmarshal(data, &value); err != nil || !value.Bounds.Empty() ||
+		value.Bounds.Min.X != Infinity || value.Bounds.Max.X != -Infinity {
+		t.Error("empty rectangle decoded as", value.Bounds, err)
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+// SDF3 represents a 3D signed distance field: a function returning for each point
+// its distance to a surface, negative inside and positive outside.
+// The field is defined by an expression of primitives and combinators which is evaluated
+// lazily and may be baked into a dense volume of samples over its bounds.
+// Combinators return new fields and do not modify the fields they combine.
+type SDF3 struct {
+	bounds     Box3                     // bounds of the dense volume
+	resolution [3]int                   // number of samples of the dense volume along each axis
+	eval       func(p *Vector3) float32 // distance function
+	data       []float32                // baked samples
+}
+
+// sdfMaxSteps is the maximum number of sphere tracing steps of SDF3.RayCast.
+const sdfMaxSteps = 256
+
+// NewSDF3 creates and returns a pointer to a new empty signed distance field
+// with a dense volume of the specified resolution over the specified bounds.
+// The distance of an empty field is positive infinity everywhere.
+func NewSDF3(bounds *Box3, resolution [3]int) *SDF3 {
+
+	s := new(SDF3)
+	s.bounds = *bounds
+	for i := range resolution {
+		if resolution[i] < 2 {
+			resolution[i] = 2
+		}
+	}
+	s.resolution = resolution
+	s.eval = func(p *Vector3) float32 { return Infinity }
+	return s
+}
+
+// derive returns a new field with the same volume as this one and the specified distance function.
+func (s *SDF3) derive(eval func(p *Vector3) float32) *SDF3 {
+
+	d := new(SDF3)
+	d.bounds = s.bounds
+	d.resolution = s.resolution
+	d.eval = eval
+	return d
+}
+
+// Bounds returns the bounds of the dense volume of this field.
+func (s *SDF3) Bounds() Box3 {
+
+	return s.bounds
+}
+
+// Resolution returns the number of samples of the dense volume of this field along each axis.
+func (s *SDF3) Resolution() [3]int {
+
+	return s.resolution
+}
+
+// Distance evaluates and returns the signed distance of the specified point to the surface.
+func (s *SDF3) Distance(p *Vector3) float32 {
+
+	return s.eval(p)
+}
+
+// Sphere returns a new field with the union of this field and
+// the sphere with the specified center and radius.
+func (s *SDF3) Sphere(center *Vector3, radius float32) *SDF3 {
+
+	c := *center
+	prev := s.eval
+	return s.derive(func(p *Vector3) float32 {
+		dx := p.X - c.X
+		dy := p.Y - c.Y
+		dz := p.Z - c.Z
+		return Min(prev(p), Sqrt(dx*dx+dy*dy+dz*dz)-radius)
+	})
+}
+
+// Box returns a new field with the union of this field and the specified axis-aligned box.
+func (s *SDF3) Box(box *Box3) *SDF3 {
+
+	var center, half Vector3
+	box.Center(&center)
+	half.SubVectors(&box.Max, &box.Min).MultiplyScalar(0.5)
+	prev := s.eval
+	return s.derive(func(p *Vector3) float32 {
+		qx := Abs(p.X-center.X) - half.X
+		qy := Abs(p.Y-center.Y) - half.Y
+		qz := Abs(p.Z-center.Z) - half.Z
+		ox := Max(qx, 0)
+		oy := Max(qy, 0)
+		oz := Max(qz, 0)
+		outside := Sqrt(ox*ox + oy*oy + oz*oz)
+		inside := Min(Max(qx, Max(qy, qz)), 0)
+		return Min(prev(p), outside+inside)
+	})
+}
+
+// Union returns a new field with the union of this field and other.
+func (s *SDF3) Union(other *SDF3) *SDF3 {
+
+	a := s.eval
+	b := other.eval
+	return s.derive(func(p *Vector3) float32 {
+		return Min(a(p), b(p))
+	})
+}
+
+// UnionSmooth returns a new field with the union of this field and other
+// blended with the polynomial smooth minimum of radius k.
+func (s *SDF3) UnionSmooth(other *SDF3, k float32) *SDF3 {
+
+	if k <= 0 {
+		return s.Union(other)
+	}
+	a := s.eval
+	b := other.eval
+	return s.derive(func(p *Vector3) float32 {
+		da := a(p)
+		db := b(p)
+		h := Clamp(0.5+0.5*(db-da)/k, 0, 1)
+		return db + (da-db)*h - k*h*(1-h)
+	})
+}
+
+// Intersection returns a new field with the intersection of this field and other.
+func (s *SDF3) Intersection(other *SDF3) *SDF3 {
+
+	a := s.eval
+	b := other.eval
+	return s.derive(func(p *Vector3) float32 {
+		return Max(a(p), b(p))
+	})
+}
+
+// Subtract returns a new field with this field minus other.
+func (s *SDF3) Subtract(other *SDF3) *SDF3 {
+
+	a := s.eval
+	b := other.eval
+	return s.derive(func(p *Vector3) float32 {
+		return Max(a(p), -b(p))
+	})
+}
+
+// SamplePosition returns the position of the sample with the specified indices of the dense volume.
+func (s *SDF3) SamplePosition(i, j, k int) Vector3 {
+
+	var size Vector3
+	size.SubVectors(&s.bounds.Max, &s.bounds.Min)
+	return Vector3{
+		X: s.bounds.Min.X + size.X*float32(i)/float32(s.resolution[0]-1),
+		Y: s.bounds.Min.Y + size.Y*float32(j)/float32(s.resolution[1]-1),
+		Z: s.bounds.Min.Z + size.Z*float32(k)/float32(s.resolution[2]-1),
+	}
+}
+
+// Bake evaluates the field expression at each sample of the dense volume and returns the samples
+// in X major order (index = i + j*resX + k*resX*resY). Samples span the bounds including their faces.
+func (s *SDF3) Bake() []float32 {
+
+	nx, ny, nz := s.resolution[0], s.resolution[1], s.resolution[2]
+	if len(s.data) != nx*ny*nz {
+		s.data = make([]float32, nx*ny*nz)
+	}
+	for k := 0; k < nz; k++ {
+		for j := 0; j < ny; j++ {
+			for i := 0; i < nx; i++ {
+				p := s.SamplePosition(i, j, k)
+				s.data[i+j*nx+k*nx*ny] = s.eval(&p)
+			}
+		}
+	}
+	return s.data
+}
+
+// epsilon returns the tolerance used for differences and surface hits, half the smallest sample spacing.
+func (s *SDF3) epsilon() float32 {
+
+	var size Vector3
+	size.SubVectors(&s.bounds.Max, &s.bounds.Min)
+	eps := size.X / float32(s.resolution[0]-1)
+	eps = Min(eps, size.Y/float32(s.resolution[1]-1))
+	eps = Min(eps, size.Z/float32(s.resolution[2]-1))
+	if eps <= 0 || eps == Infinity || IsNaN(eps) {
+		return 1e-3
+	}
+	return eps * 0.5
+}
+
+// RayCast returns the distance along the specified ray of its first intersection
+// with the surface of this field inside the field bounds, found by sphere tracing.
+// Returns false if there is no intersection.
+func (s *SDF3) RayCast(ray *Ray) (t float32, hit bool) {
+
+	origin := ray.Origin()
+	dir := ray.Direction()
+	length := dir.Length()
+	if length == 0 {
+		return 0, false
+	}
+	dir.MultiplyScalar(1 / length)
+
+	// Starts marching where the ray enters the bounds
+	t = 0
+	if !s.bounds.ContainsPoint(&origin) {
+		var entry Vector3
+		if ray.IntersectBox(&s.bounds, &entry) == nil {
+			return 0, false
+		}
+		t = entry.DistanceTo(&origin)
+	}
+	var center Vector3
+	s.bounds.Center(&center)
+	tmax := origin.DistanceTo(&center) + s.bounds.Max.DistanceTo(&center)
+
+	eps := s.epsilon() * 0.01
+	var p Vector3
+	for step := 0; step < sdfMaxSteps && t <= tmax; step++ {
+		p.Set(origin.X+dir.X*t, origin.Y+dir.Y*t, origin.Z+dir.Z*t)
+		d := s.eval(&p)
+		if d < eps {
+			return t / length, true
+		}
+		t += d
+	}
+	return 0, false
+}
+
+// GradientAt returns the gradient of this field at the specified point computed by
+// central differences, which for points near the surface approximates its normal.
+func (s *SDF3) GradientAt(p *Vector3) Vector3 {
+
+	h := s.epsilon()
+	q := *p
+	var g Vector3
+	q.X = p.X + h
+	g.X = s.eval(&q)
+	q.X = p.X - h
+	g.X -= s.eval(&q)
+	q.X = p.X
+	q.Y = p.Y + h
+	g.Y = s.eval(&q)
+	q.Y = p.Y - h
+	g.Y -= s.eval(&q)
+	q.Y = p.Y
+	q.Z = p.Z + h
+	g.Z = s.eval(&q)
+	q.Z = p.Z - h
+	g.Z -= s.eval(&q)
+	g.MultiplyScalar(1 / (2 * h))
+	return g
+}
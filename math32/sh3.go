@@ -0,0 +1,152 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// SH3 represents the projection of a spherical color function, such as the
+// environment lighting at a point, on the first three bands (L0 to L2) of the
+// real spherical harmonics basis. Each coefficient stores the red, green and blue
+// components in its X, Y and Z fields and the coefficients are ordered
+// (l,m) = (0,0), (1,-1), (1,0), (1,1), (2,-2), (2,-1), (2,0), (2,1), (2,2).
+type SH3 struct {
+	Coeffs [9]Vector3
+}
+
+// Cosine lobe convolution factors of the first three bands
+// divided by Pi (Ramamoorthi and Hanrahan, 2001).
+const (
+	sh3Band0 = 1.0
+	sh3Band1 = 2.0 / 3.0
+	sh3Band2 = 1.0 / 4.0
+)
+
+// NewSH3 creates and returns a pointer to a new SH3 with all coefficients zero.
+func NewSH3() *SH3 {
+
+	return new(SH3)
+}
+
+// sh3Basis stores in basis the nine real spherical harmonics
+// basis functions evaluated at the specified unit direction.
+func sh3Basis(d *Vector3, basis *[9]float32) {
+
+	basis[0] = 0.282095
+	basis[1] = 0.488603 * d.Y
+	basis[2] = 0.488603 * d.Z
+	basis[3] = 0.488603 * d.X
+	basis[4] = 1.092548 * d.X * d.Y
+	basis[5] = 1.092548 * d.Y * d.Z
+	basis[6] = 0.315392 * (3*d.Z*d.Z - 1)
+	basis[7] = 1.092548 * d.X * d.Z
+	basis[8] = 0.546274 * (d.X*d.X - d.Y*d.Y)
+}
+
+// Zero sets all the coefficients of this SH3 to zero.
+// Returns pointer to this updated SH3.
+func (sh *SH3) Zero() *SH3 {
+
+	*sh = SH3{}
+	return sh
+}
+
+// Copy copies other to this SH3.
+// Returns pointer to this updated SH3.
+func (sh *SH3) Copy(other *SH3) *SH3 {
+
+	*sh = *other
+	return sh
+}
+
+// sh3Rand is the seeded generator of the directions sampled by ProjectEnvironment,
+// guarded by sh3RandMutex.
+var sh3Rand = rand.New(rand.NewSource(1))
+var sh3RandMutex sync.Mutex
+
+// ProjectEnvironment sets this SH3 to the projection of the specified spherical function,
+// which returns the incoming radiance from a unit direction, estimated by Monte Carlo
+// integration over numSamples directions uniformly distributed on the sphere.
+// The directions are generated by a package generator with a fixed seed,
+// and concurrent projections are serialized.
+// Returns pointer to this updated SH3.
+func (sh *SH3) ProjectEnvironment(sampler func(dir Vector3) Color, numSamples int) *SH3 {
+
+	sh3RandMutex.Lock()
+	defer sh3RandMutex.Unlock()
+	return sh.ProjectEnvironmentRand(sampler, numSamples, sh3Rand)
+}
+
+// ProjectEnvironmentRand sets this SH3 to the projection of the specified spherical function
+// as ProjectEnvironment, with the directions generated by the specified generator.
+// Returns pointer to this updated SH3.
+func (sh *SH3) ProjectEnvironmentRand(sampler func(dir Vector3) Color, numSamples int, rng *rand.Rand) *SH3 {
+
+	sh.Zero()
+	if numSamples <= 0 {
+		return sh
+	}
+	random := rng.Float32
+	// Sums in double precision, as each sample is small relative to the sum
+	var basis [9]float32
+	var sums [9][3]float64
+	for n := 0; n < numSamples; n++ {
+		// Uniform direction on the unit sphere
+		z := 2*random() - 1
+		phi := 2 * Pi * random()
+		r := Sqrt(Max(0, 1-z*z))
+		dir := Vector3{X: r * Cos(phi), Y: r * Sin(phi), Z: z}
+		c := sampler(dir)
+		sh3Basis(&dir, &basis)
+		for i := range sums {
+			sums[i][0] += float64(c.R * basis[i])
+			sums[i][1] += float64(c.G * basis[i])
+			sums[i][2] += float64(c.B * basis[i])
+		}
+	}
+	// Each sample represents a solid angle of 4*Pi/numSamples
+	weight := 4 * math.Pi / float64(numSamples)
+	for i := range sh.Coeffs {
+		sh.Coeffs[i].Set(float32(sums[i][0]*weight), float32(sums[i][1]*weight), float32(sums[i][2]*weight))
+	}
+	return sh
+}
+
+// Evaluate returns the irradiance at a surface with the specified unit normal,
+// obtained by convolving this SH3 with the clamped cosine lobe, divided by Pi so that
+// a constant environment of color c evaluates to c.
+func (sh *SH3) Evaluate(normal *Vector3) Color {
+
+	var basis [9]float32
+	sh3Basis(normal, &basis)
+	var sum Vector3
+	for i := range sh.Coeffs {
+		factor := float32(sh3Band2)
+		if i == 0 {
+			factor = sh3Band0
+		} else if i < 4 {
+			factor = sh3Band1
+		}
+		w := factor * basis[i]
+		sum.X += sh.Coeffs[i].X * w
+		sum.Y += sh.Coeffs[i].Y * w
+		sum.Z += sh.Coeffs[i].Z * w
+	}
+	return Color{Max(sum.X, 0), Max(sum.Y, 0), Max(sum.Z, 0)}
+}
+
+// Lerp sets each coefficient of this SH3 to the linear interpolation between
+// its current value and the corresponding coefficient of other by t.
+// Returns pointer to this updated SH3.
+func (sh *SH3) Lerp(other *SH3, t float32) *SH3 {
+
+	for i := range sh.Coeffs {
+		sh.Coeffs[i].Lerp(&other.Coeffs[i], t)
+	}
+	return sh
+}
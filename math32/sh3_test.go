@@ -0,0 +1,76 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Test that the projection of a constant environment has only the DC component and evaluates to the constant
+func TestSH3ProjectConstant(t *testing.T) {
+
+	color := Color{R: 0.5, G: 1, B: 2}
+	sh := NewSH3().ProjectEnvironmentRand(func(dir Vector3) Color { return color }, 100000, rand.New(rand.NewSource(1)))
+
+	// The integral of the constant basis function over the sphere is 4*Pi*0.282095
+	dc := float32(4 * Pi * 0.282095)
+	expected := Vector3{X: color.R * dc, Y: color.G * dc, Z: color.B * dc}
+	if sh.Coeffs[0].DistanceTo(&expected) > 1e-4 {
+		t.Error("DC component", sh.Coeffs[0], "instead of", expected)
+	}
+	// Monte Carlo noise of the other bands
+	for i := 1; i < 9; i++ {
+		if sh.Coeffs[i].Length() > 0.1 {
+			t.Error("coefficient", i, "is", sh.Coeffs[i], "instead of near zero")
+		}
+	}
+	for _, n := range []Vector3{{X: 1}, {Y: -1}, {Z: 1}, *NewVector3(1, 1, 1).Normalize()} {
+		c := sh.Evaluate(&n)
+		if Abs(c.R-color.R) > 0.05 || Abs(c.G-color.G) > 0.05 || Abs(c.B-color.B) > 0.05 {
+			t.Error("irradiance", c, "instead of", color, "at", n)
+		}
+	}
+}
+
+// Test the irradiance of an environment lit from above and the blending of probes
+func TestSH3Evaluate(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(1))
+	sky := NewSH3().ProjectEnvironmentRand(func(dir Vector3) Color {
+		if dir.Z > 0 {
+			return Color{R: 1, G: 1, B: 1}
+		}
+		return Color{}
+	}, 20000, rng)
+	// The irradiance of a hemisphere over the cosine lobe is 1, 0 and 1/2, approximated by three bands
+	up := sky.Evaluate(&Vector3{Z: 1})
+	down := sky.Evaluate(&Vector3{Z: -1})
+	side := sky.Evaluate(&Vector3{X: 1})
+	if up.R < 0.9 || down.R > 0.1 || Abs(side.R-0.5) > 0.05 {
+		t.Error("irradiance up", up, "down", down, "side", side)
+	}
+
+	// Halfway between the sky and the null environment
+	blend := NewSH3().Lerp(sky, 0.5)
+	if c := blend.Evaluate(&Vector3{Z: 1}); Abs(c.R-up.R/2) > 1e-5 {
+		t.Error("blended irradiance", c, "instead of", up.R/2)
+	}
+
+	// The same generator seed gives the same projection
+	sampler := func(dir Vector3) Color { return Color{R: dir.X, G: dir.Y, B: dir.Z} }
+	a := NewSH3().ProjectEnvironmentRand(sampler, 100, rand.New(rand.NewSource(7)))
+	b := NewSH3().ProjectEnvironmentRand(sampler, 100, rand.New(rand.NewSource(7)))
+	if *a != *b {
+		t.Error("projections with the same seed differ")
+	}
+	white := NewSH3().ProjectEnvironment(func(dir Vector3) Color { return Color{R: 1, G: 1, B: 1} }, 100)
+	if dc := float32(4 * Pi * 0.282095); Abs(white.Coeffs[0].X-dc) > 1e-4 {
+		t.Error("DC component", white.Coeffs[0], "with the package generator instead of", dc)
+	}
+	if *NewSH3().ProjectEnvironment(sampler, 0) != (SH3{}) {
+		t.Error("projection without samples not zero")
+	}
+}
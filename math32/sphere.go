@@ -114,6 +114,25 @@ func (s *Sphere) ClampPoint(point *Vector3, optionalTarget *Vector3) *Vector3 {
 	return result
 }
 
+// SupportPoint calculates the point of the surface of this sphere furthest in the specified direction.
+// If the direction is zero the center is returned.
+// The point is stored in optionalTarget, if not nil, and returned.
+func (s *Sphere) SupportPoint(dir *Vector3, optionalTarget *Vector3) *Vector3 {
+
+	var result *Vector3
+	if optionalTarget != nil {
+		result = optionalTarget
+	} else {
+		result = NewVector3(0, 0, 0)
+	}
+	length := dir.Length()
+	if length == 0 {
+		return result.Copy(&s.Center)
+	}
+	result.Copy(dir).MultiplyScalar(s.Radius / length)
+	return result.Add(&s.Center)
+}
+
 // GetBoundingBox calculates a Box3 which bounds this sphere.
 // Update optionalTarget with the calculated Box3, if not nil, and also returns it.
 func (s *Sphere) GetBoundingBox(optionalTarget *Box3) *Box3 {
@@ -0,0 +1,127 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+// SplineEditor edits the control points of a CatmullRomSpline3 keeping
+// a limited history of its states for undo and redo.
+type SplineEditor struct {
+	spline *CatmullRomSpline3
+	depth  int         // maximum number of states kept for undo
+	undo   [][]Vector3 // previous states, the most recent last
+	redo   [][]Vector3 // undone states, the most recent last
+}
+
+// NewSplineEditor creates and returns a pointer to a new SplineEditor for the specified spline
+// keeping up to the specified number of states for undo.
+func NewSplineEditor(spline *CatmullRomSpline3, historyDepth int) *SplineEditor {
+
+	if historyDepth < 1 {
+		panic("NewSplineEditor: history depth must be positive")
+	}
+	return &SplineEditor{spline: spline, depth: historyDepth}
+}
+
+// Spline returns the spline edited by this editor.
+func (se *SplineEditor) Spline() *CatmullRomSpline3 {
+
+	return se.spline
+}
+
+// record saves the current state of the spline for undo and discards the states for redo.
+func (se *SplineEditor) record() {
+
+	if len(se.undo) == se.depth {
+		copy(se.undo, se.undo[1:])
+		se.undo = se.undo[:len(se.undo)-1]
+	}
+	se.undo = append(se.undo, se.snapshot())
+	se.redo = se.redo[:0]
+}
+
+// snapshot returns a copy of the current control points of the spline.
+func (se *SplineEditor) snapshot() []Vector3 {
+
+	points := make([]Vector3, len(se.spline.points))
+	copy(points, se.spline.points)
+	return points
+}
+
+// MoveControlPoint moves the control point with the specified index to the specified position.
+func (se *SplineEditor) MoveControlPoint(index int, newPos *Vector3) {
+
+	points := se.snapshot()
+	points[index] = *newPos
+	se.record()
+	se.spline.SetPoints(points)
+}
+
+// SnapControlPoint moves the control point with the specified index to the nearest
+// point of a grid with the specified spacing. Does nothing if the spacing is not positive.
+func (se *SplineEditor) SnapControlPoint(index int, grid float32) {
+
+	if grid <= 0 {
+		return
+	}
+	p := se.spline.points[index]
+	p.Set(Round(p.X/grid)*grid, Round(p.Y/grid)*grid, Round(p.Z/grid)*grid)
+	se.MoveControlPoint(index, &p)
+}
+
+// SplitSegment inserts a new control point in the segment with the specified index, which goes
+// from the control point with the same index to the next one, at the specified parameter in [0,1].
+func (se *SplineEditor) SplitSegment(segmentIndex int, t float32) {
+
+	spans := len(se.spline.points) - 1
+	if segmentIndex < 0 || segmentIndex >= spans {
+		panic("SplineEditor.SplitSegment: invalid segment index")
+	}
+	p := se.spline.PointAt((float32(segmentIndex) + Clamp(t, 0, 1)) / float32(spans))
+	se.record()
+	points := make([]Vector3, 0, spans+2)
+	points = append(points, se.spline.points[:segmentIndex+1]...)
+	points = append(points, p)
+	points = append(points, se.spline.points[segmentIndex+1:]...)
+	se.spline.SetPoints(points)
+}
+
+// CanUndo returns if there is a state to undo.
+func (se *SplineEditor) CanUndo() bool {
+
+	return len(se.undo) > 0
+}
+
+// CanRedo returns if there is an undone state to redo.
+func (se *SplineEditor) CanRedo() bool {
+
+	return len(se.redo) > 0
+}
+
+// Undo restores the spline to the state before the last edit.
+// Returns false if there is nothing to undo.
+func (se *SplineEditor) Undo() bool {
+
+	if len(se.undo) == 0 {
+		return false
+	}
+	se.redo = append(se.redo, se.snapshot())
+	last := len(se.undo) - 1
+	se.spline.SetPoints(se.undo[last])
+	se.undo = se.undo[:last]
+	return true
+}
+
+// Redo restores the spline to the state before the last undo.
+// Returns false if there is nothing to redo.
+func (se *SplineEditor) Redo() bool {
+
+	if len(se.redo) == 0 {
+		return false
+	}
+	se.undo = append(se.undo, se.snapshot())
+	last := len(se.redo) - 1
+	se.spline.SetPoints(se.redo[last])
+	se.redo = se.redo[:last]
+	return true
+}
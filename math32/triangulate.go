@@ -0,0 +1,411 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"errors"
+	"sort"
+)
+
+// TriangulatePolygon returns the indices of the triangles of the specified simple polygon
+// computed by ear clipping, with three indices per triangle in counter clockwise order.
+// The polygon may be in clockwise or counter clockwise order and must not be self intersecting.
+func TriangulatePolygon(vertices []Vector2) ([]int, error) {
+
+	if err := validatePolygon(vertices); err != nil {
+		return nil, err
+	}
+	ring := make([]int, len(vertices))
+	for i := range ring {
+		ring[i] = i
+	}
+	if polygonArea(vertices) < 0 {
+		reverseInts(ring)
+	}
+	return earClip(vertices, ring)
+}
+
+// TriangulateWithHoles returns the indices of the triangles of the specified simple polygon
+// with holes, with three indices per triangle in counter clockwise order.
+// The indices refer to the outer polygon vertices followed by the vertices of each hole.
+// The holes are connected to the outer polygon by bridges, which are triangulated by ear clipping.
+// The holes must be inside the outer polygon and the polygons must not intersect.
+func TriangulateWithHoles(outer []Vector2, holes [][]Vector2) ([]int, error) {
+
+	if err := validatePolygon(outer); err != nil {
+		return nil, err
+	}
+	vertices := append([]Vector2(nil), outer...)
+	rings := [][]int{makeRing(0, len(outer))}
+	for _, hole := range holes {
+		if err := validatePolygon(hole); err != nil {
+			return nil, err
+		}
+		if !pointInPolygon(&hole[0], outer) {
+			return nil, errors.New("hole outside of polygon")
+		}
+		rings = append(rings, makeRing(len(vertices), len(hole)))
+		vertices = append(vertices, hole...)
+	}
+	for i := range rings {
+		for j := i + 1; j < len(rings); j++ {
+			if ringsIntersect(vertices, rings[i], rings[j]) {
+				return nil, errors.New("polygon and holes intersect")
+			}
+		}
+	}
+
+	// The outer polygon is counter clockwise and the holes are clockwise
+	if polygonArea(outer) < 0 {
+		reverseInts(rings[0])
+	}
+	for _, r := range rings[1:] {
+		if ringArea(vertices, r) > 0 {
+			reverseInts(r)
+		}
+	}
+
+	// Holes are bridged in decreasing order of their rightmost vertex
+	hs := rings[1:]
+	rightmost := func(r []int) int {
+		m := 0
+		for i := range r {
+			if vertices[r[i]].X > vertices[r[m]].X {
+				m = i
+			}
+		}
+		return m
+	}
+	sort.SliceStable(hs, func(i, j int) bool {
+		return vertices[hs[i][rightmost(hs[i])]].X > vertices[hs[j][rightmost(hs[j])]].X
+	})
+	ring := rings[0]
+	for _, h := range hs {
+		m := rightmost(h)
+		p := bridgeVertex(vertices, ring, h[m])
+		if p < 0 {
+			return nil, errors.New("cannot connect hole to polygon")
+		}
+		// Splices the hole starting and ending at its rightmost vertex after the bridge vertex
+		merged := make([]int, 0, len(ring)+len(h)+2)
+		merged = append(merged, ring[:p+1]...)
+		merged = append(merged, h[m:]...)
+		merged = append(merged, h[:m+1]...)
+		merged = append(merged, ring[p:]...)
+		ring = merged
+	}
+	return earClip(vertices, ring)
+}
+
+// validatePolygon returns an error if the specified polygon has less than three vertices,
+// degenerate edges, no area or self intersections.
+func validatePolygon(vertices []Vector2) error {
+
+	n := len(vertices)
+	if n < 3 {
+		return errors.New("polygon has less than three vertices")
+	}
+	for i := range vertices {
+		if vertices[i].Equals(&vertices[(i+1)%n]) {
+			return errors.New("polygon has degenerate edge")
+		}
+	}
+	if polygonArea(vertices) == 0 {
+		return errors.New("polygon has no area")
+	}
+	for i := 0; i < n; i++ {
+		a, b := &vertices[i], &vertices[(i+1)%n]
+		// Adjacent edges folding back over each other
+		c := &vertices[(i+2)%n]
+		if cross2(a, b, c) == 0 && (a.X-b.X)*(c.X-b.X)+(a.Y-b.Y)*(c.Y-b.Y) > 0 {
+			return errors.New("polygon is self intersecting")
+		}
+		for j := i + 2; j < n; j++ {
+			if i == 0 && j == n-1 {
+				continue
+			}
+			if segmentsIntersect2(a, b, &vertices[j], &vertices[(j+1)%n]) {
+				return errors.New("polygon is self intersecting")
+			}
+		}
+	}
+	return nil
+}
+
+// ringsIntersect returns if any edge of the first ring intersects any edge of the second ring.
+func ringsIntersect(vertices []Vector2, r1, r2 []int) bool {
+
+	for i := range r1 {
+		a, b := &vertices[r1[i]], &vertices[r1[(i+1)%len(r1)]]
+		for j := range r2 {
+			if segmentsIntersect2(a, b, &vertices[r2[j]], &vertices[r2[(j+1)%len(r2)]]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// bridgeVertex returns the position in the ring of the vertex visible from the specified hole vertex
+// to which the hole is connected (D. Eberly, Triangulation by Ear Clipping), or -1 if not found.
+func bridgeVertex(vertices []Vector2, ring []int, hole int) int {
+
+	m := &vertices[hole]
+	// Nearest intersection of the ray from the hole vertex along +X with the ring edges
+	best := -1
+	var bestX float32
+	for i := range ring {
+		a, b := &vertices[ring[i]], &vertices[ring[(i+1)%len(ring)]]
+		if (a.Y > m.Y) == (b.Y > m.Y) && a.Y != m.Y && b.Y != m.Y {
+			continue
+		}
+		var x float32
+		switch {
+		case a.Y == m.Y && b.Y == m.Y:
+			x = Min(a.X, b.X)
+		case a.Y == m.Y:
+			x = a.X
+		case b.Y == m.Y:
+			x = b.X
+		default:
+			x = a.X + (m.Y-a.Y)*(b.X-a.X)/(b.Y-a.Y)
+		}
+		if x < m.X || (best >= 0 && x >= bestX) {
+			continue
+		}
+		// The candidate is the edge end point with the larger X, or the end point on the ray
+		best, bestX = i, x
+		switch {
+		case a.Y == m.Y && a.X == x:
+		case b.Y == m.Y && b.X == x:
+			best = (i + 1) % len(ring)
+		case b.X > a.X:
+			best = (i + 1) % len(ring)
+		}
+	}
+	if best < 0 {
+		return -1
+	}
+	p := &vertices[ring[best]]
+	if p.Y == m.Y {
+		return bridgeOccurrence(vertices, ring, best, m)
+	}
+
+	// Reflex vertices inside the triangle formed by the hole vertex, the intersection and the
+	// candidate may hide it: the one with the smallest angle to the ray is chosen instead
+	in := Vector2{bestX, m.Y}
+	t0, t1, t2 := m, &in, p
+	if cross2(t0, t1, t2) < 0 {
+		t1, t2 = t2, t1
+	}
+	result := best
+	minTan := float32(-1)
+	minDist := float32(0)
+	for i := range ring {
+		v := &vertices[ring[i]]
+		if i == best || v.X < m.X {
+			continue
+		}
+		prev := &vertices[ring[(i+len(ring)-1)%len(ring)]]
+		next := &vertices[ring[(i+1)%len(ring)]]
+		if cross2(prev, v, next) > 0 {
+			continue
+		}
+		if !pointInTriangle2(v, t0, t1, t2) {
+			continue
+		}
+		dx := v.X - m.X
+		tan := Abs(v.Y-m.Y) / Max(dx, 1e-30)
+		dist := dx*dx + (v.Y-m.Y)*(v.Y-m.Y)
+		if minTan < 0 || tan < minTan || (tan == minTan && dist < minDist) {
+			result, minTan, minDist = i, tan, dist
+		}
+	}
+	return bridgeOccurrence(vertices, ring, result, m)
+}
+
+// bridgeOccurrence returns the position in the ring of the occurrence of the vertex at the specified
+// position whose interior angle contains the specified point. Vertices occur more than once in the
+// ring after holes are bridged to them.
+func bridgeOccurrence(vertices []Vector2, ring []int, pos int, m *Vector2) int {
+
+	for i := range ring {
+		if ring[i] != ring[pos] {
+			continue
+		}
+		v := &vertices[ring[i]]
+		prev := &vertices[ring[(i+len(ring)-1)%len(ring)]]
+		next := &vertices[ring[(i+1)%len(ring)]]
+		if cross2(v, next, prev) > 0 {
+			if cross2(v, next, m) > 0 && cross2(v, m, prev) > 0 {
+				return i
+			}
+		} else if !(cross2(v, prev, m) >= 0 && cross2(v, m, next) >= 0) {
+			return i
+		}
+	}
+	return pos
+}
+
+// earClip returns the triangles of the specified counter clockwise ring of vertex indices by ear clipping.
+func earClip(vertices []Vector2, ring []int) ([]int, error) {
+
+	n := len(ring)
+	prev := make([]int, n)
+	next := make([]int, n)
+	for i := range ring {
+		prev[i] = (i + n - 1) % n
+		next[i] = (i + 1) % n
+	}
+	indices := make([]int, 0, 3*(n-2))
+	cur := 0
+	// After a full loop without ears, convex vertices with vertices on their boundary are accepted
+	strict := true
+	stall := 0
+	for n > 3 {
+		a, b, c := ring[prev[cur]], ring[cur], ring[next[cur]]
+		cr := cross2(&vertices[a], &vertices[b], &vertices[c])
+		clip := false
+		if cr == 0 {
+			// Collinear vertices are removed without triangles
+			clip = true
+		} else if cr > 0 {
+			clip = isEar(vertices, ring, prev, next, cur, strict)
+		}
+		if clip {
+			if cr != 0 {
+				indices = append(indices, a, b, c)
+			}
+			next[prev[cur]] = next[cur]
+			prev[next[cur]] = prev[cur]
+			cur = next[cur]
+			n--
+			stall = 0
+			strict = true
+			continue
+		}
+		cur = next[cur]
+		stall++
+		if stall > n {
+			if !strict {
+				return nil, errors.New("cannot triangulate polygon")
+			}
+			strict = false
+			stall = 0
+		}
+	}
+	a, b, c := ring[prev[cur]], ring[cur], ring[next[cur]]
+	if cross2(&vertices[a], &vertices[b], &vertices[c]) != 0 {
+		indices = append(indices, a, b, c)
+	}
+	return indices, nil
+}
+
+// isEar returns if the triangle of the specified convex ring vertex and its neighbors contains
+// no other vertex of the ring. Vertices equal to the triangle vertices, from bridges, are ignored.
+// When not strict, vertices on the triangle boundary are also ignored.
+func isEar(vertices []Vector2, ring, prev, next []int, cur int, strict bool) bool {
+
+	p := prev[cur]
+	a, b, c := &vertices[ring[p]], &vertices[ring[cur]], &vertices[ring[next[cur]]]
+	for i := next[next[cur]]; i != p; i = next[i] {
+		v := &vertices[ring[i]]
+		if v.Equals(a) || v.Equals(b) || v.Equals(c) {
+			continue
+		}
+		if strict {
+			if pointInTriangle2(v, a, b, c) {
+				return false
+			}
+		} else if cross2(a, b, v) > 0 && cross2(b, c, v) > 0 && cross2(c, a, v) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// makeRing returns the ring of n consecutive indices starting at the specified index.
+func makeRing(start, n int) []int {
+
+	r := make([]int, n)
+	for i := range r {
+		r[i] = start + i
+	}
+	return r
+}
+
+// reverseInts reverses the specified slice in place.
+func reverseInts(s []int) {
+
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+// polygonArea returns the signed area of the specified polygon, positive if counter clockwise.
+func polygonArea(vertices []Vector2) float32 {
+
+	var area float32
+	for i := range vertices {
+		a, b := &vertices[i], &vertices[(i+1)%len(vertices)]
+		area += a.X*b.Y - b.X*a.Y
+	}
+	return area / 2
+}
+
+// ringArea returns the signed area of the specified ring of vertex indices.
+func ringArea(vertices []Vector2, ring []int) float32 {
+
+	var area float32
+	for i := range ring {
+		a, b := &vertices[ring[i]], &vertices[ring[(i+1)%len(ring)]]
+		area += a.X*b.Y - b.X*a.Y
+	}
+	return area / 2
+}
+
+// pointInPolygon returns if the specified point is inside the specified polygon by the even-odd rule.
+func pointInPolygon(p *Vector2, vertices []Vector2) bool {
+
+	inside := false
+	for i := range vertices {
+		a, b := &vertices[i], &vertices[(i+1)%len(vertices)]
+		if (a.Y > p.Y) != (b.Y > p.Y) && p.X < a.X+(p.Y-a.Y)*(b.X-a.X)/(b.Y-a.Y) {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// cross2 returns the Z component of the cross product of (a - o) and (b - o),
+// positive if o, a and b are in counter clockwise order.
+func cross2(o, a, b *Vector2) float32 {
+
+	return (a.X-o.X)*(b.Y-o.Y) - (a.Y-o.Y)*(b.X-o.X)
+}
+
+// pointInTriangle2 returns if the specified point is inside or on the boundary
+// of the specified counter clockwise triangle.
+func pointInTriangle2(p, a, b, c *Vector2) bool {
+
+	return cross2(a, b, p) >= 0 && cross2(b, c, p) >= 0 && cross2(c, a, p) >= 0
+}
+
+// segmentsIntersect2 returns if the segment from a to b intersects or touches the segment from c to d.
+func segmentsIntersect2(a, b, c, d *Vector2) bool {
+
+	d1 := cross2(c, d, a)
+	d2 := cross2(c, d, b)
+	d3 := cross2(a, b, c)
+	d4 := cross2(a, b, d)
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+	onSegment := func(p, q, r *Vector2) bool {
+		return Min(p.X, q.X) <= r.X && r.X <= Max(p.X, q.X) && Min(p.Y, q.Y) <= r.Y && r.Y <= Max(p.Y, q.Y)
+	}
+	return (d1 == 0 && onSegment(c, d, a)) || (d2 == 0 && onSegment(c, d, b)) ||
+		(d3 == 0 && onSegment(a, b, c)) || (d4 == 0 && onSegment(a, b, d))
+}
@@ -0,0 +1,158 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// checkTriangulation checks that the specified triangles are counter clockwise and cover the specified area.
+func checkTriangulation(t *testing.T, vertices []Vector2, indices []int, area float32, name string) {
+
+	t.Helper()
+	if len(indices)%3 != 0 {
+		t.Fatal(name, "has", len(indices), "indices")
+	}
+	var sum float32
+	for i := 0; i < len(indices); i += 3 {
+		a := cross2(&vertices[indices[i]], &vertices[indices[i+1]], &vertices[indices[i+2]]) / 2
+		if a < 0 {
+			t.Error(name, "triangle", indices[i:i+3], "is clockwise")
+		}
+		sum += a
+	}
+	if Abs(sum-area) > 1e-4*Max(area, 1) {
+		t.Error(name, "triangles cover the area", sum, "instead of", area)
+	}
+}
+
+// Test the triangulation of simple polygons in both orders and the validation of invalid polygons
+func TestTriangulatePolygon(t *testing.T) {
+
+	var star []Vector2
+	for i := 0; i < 10; i++ {
+		r := float32(1 - 0.6*float32(i%2))
+		angle := float32(i) * Pi / 5
+		star = append(star, Vector2{X: r * Cos(angle), Y: r * Sin(angle)})
+	}
+	polygons := map[string][]Vector2{
+		"square":           {{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}},
+		"clockwise square": {{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 0}},
+		"collinear":        {{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 2}, {X: 0, Y: 2}},
+		"star":             star,
+		"concave L":        {{X: 0, Y: 0}, {X: 3, Y: 0}, {X: 3, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 3}, {X: 0, Y: 3}},
+	}
+	for name, polygon := range polygons {
+		indices, err := TriangulatePolygon(polygon)
+		if err != nil {
+			t.Error(name, err)
+			continue
+		}
+		if len(indices) != 3*(len(polygon)-2) {
+			t.Error(name, "has", len(indices)/3, "triangles instead of", len(polygon)-2)
+		}
+		checkTriangulation(t, polygon, indices, Abs(polygonArea(polygon)), name)
+	}
+
+	invalid := map[string][]Vector2{
+		"bowtie":          {{X: 0, Y: 0}, {X: 2, Y: 2}, {X: 2, Y: 0}, {X: 0, Y: 1}},
+		"degenerate edge": {{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}},
+		"flat":            {{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}},
+		"two vertices":    {{X: 0, Y: 0}, {X: 1, Y: 0}},
+	}
+	for name, polygon := range invalid {
+		if _, err := TriangulatePolygon(polygon); err == nil {
+			t.Error(name, "polygon not rejected")
+		}
+	}
+}
+
+// Test the triangulation of random star shaped polygons
+func TestTriangulatePolygonRandom(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		n := 3 + rng.Intn(60)
+		polygon := make([]Vector2, n)
+		for j := range polygon {
+			angle := (float32(j) + rng.Float32()*0.9) * 2 * Pi / float32(n)
+			r := 0.2 + rng.Float32()
+			polygon[j] = Vector2{X: r * Cos(angle), Y: r * Sin(angle)}
+		}
+		indices, err := TriangulatePolygon(polygon)
+		if err != nil {
+			t.Fatal("polygon", i, err)
+		}
+		checkTriangulation(t, polygon, indices, polygonArea(polygon), "random polygon")
+	}
+}
+
+// Test the triangulation of a square with holes, of which a bridge passes by a vertex of the outer polygon
+func TestTriangulateWithHoles(t *testing.T) {
+
+	outer := []Vector2{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 0, Y: 10}}
+	holes := [][]Vector2{
+		{{X: 2, Y: 2}, {X: 4, Y: 2}, {X: 4, Y: 4}, {X: 2, Y: 4}},
+		{{X: 6, Y: 6}, {X: 6, Y: 8}, {X: 8, Y: 8}, {X: 8, Y: 6}},
+		{{X: 6, Y: 2}, {X: 8, Y: 2}, {X: 7, Y: 4}},
+	}
+	vertices := append([]Vector2(nil), outer...)
+	for _, hole := range holes {
+		vertices = append(vertices, hole...)
+	}
+	indices, err := TriangulateWithHoles(outer, holes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkTriangulation(t, vertices, indices, 90, "square with holes")
+
+	notched := []Vector2{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 5}, {X: 12, Y: 5}, {X: 12, Y: 10}, {X: 0, Y: 10}}
+	diamond := []Vector2{{X: 4, Y: 5}, {X: 5, Y: 4}, {X: 6, Y: 5}, {X: 5, Y: 6}}
+	indices, err = TriangulateWithHoles(notched, [][]Vector2{diamond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkTriangulation(t, append(append([]Vector2(nil), notched...), diamond...), indices, 108, "notched polygon with hole")
+
+	if _, err := TriangulateWithHoles(outer, [][]Vector2{{{X: 20, Y: 20}, {X: 21, Y: 20}, {X: 21, Y: 21}}}); err == nil {
+		t.Error("hole outside of the polygon not rejected")
+	}
+	if _, err := TriangulateWithHoles(outer, [][]Vector2{{{X: 5, Y: 5}, {X: 15, Y: 5}, {X: 5, Y: 7}}}); err == nil {
+		t.Error("hole intersecting the polygon not rejected")
+	}
+}
+
+// Test the triangulation of a square with random holes in a grid of cells
+func TestTriangulateWithHolesRandom(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(2))
+	outer := []Vector2{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 0, Y: 10}}
+	for i := 0; i < 200; i++ {
+		var holes [][]Vector2
+		vertices := append([]Vector2(nil), outer...)
+		area := float32(100)
+		for cell := 0; cell < 16; cell++ {
+			if rng.Intn(2) == 0 {
+				continue
+			}
+			cx, cy := 1.25+float32(cell%4)*2.5, 1.25+float32(cell/4)*2.5
+			hole := make([]Vector2, 3+rng.Intn(6))
+			for j := range hole {
+				angle := -float32(j) * 2 * Pi / float32(len(hole))
+				r := 0.3 + rng.Float32()*0.8
+				hole[j] = Vector2{X: cx + r*Cos(angle), Y: cy + r*Sin(angle)}
+			}
+			holes = append(holes, hole)
+			vertices = append(vertices, hole...)
+			area -= Abs(polygonArea(hole))
+		}
+		indices, err := TriangulateWithHoles(outer, holes)
+		if err != nil {
+			t.Fatal("polygon", i, err)
+		}
+		checkTriangulation(t, vertices, indices, area, "random holes")
+	}
+}
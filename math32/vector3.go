@@ -289,6 +289,17 @@ func (v *Vector3) Clamp(min, max *Vector3) *Vector3 {
 	return v
 }
 
+// SmoothStep sets each component of this vector to the SmoothStep of the component
+// between the corresponding components of edge0 and edge1.
+// Returns the pointer to this updated vector.
+func (v *Vector3) SmoothStep(edge0, edge1 *Vector3) *Vector3 {
+
+	v.X = SmoothStep(edge0.X, edge1.X, v.X)
+	v.Y = SmoothStep(edge0.Y, edge1.Y, v.Y)
+	v.Z = SmoothStep(edge0.Z, edge1.Z, v.Z)
+	return v
+}
+
 // ClampScalar sets this vector components to be no less than minVal and not greater than maxVal.
 // Returns the pointer to this updated vector.
 func (v *Vector3) ClampScalar(minVal, maxVal float32) *Vector3 {
@@ -678,3 +689,15 @@ func (v *Vector3) AlmostEquals(other *Vector3, tolerance float32) bool {
 	}
 	return false
 }
+
+// SmoothDampVector3 gradually moves the current vector towards target like a critically
+// damped spring which reaches the target in approximately smoothTime.
+// The velocity is updated and must be kept between calls.
+// Returns pointer to the updated current vector.
+func SmoothDampVector3(current, target, velocity *Vector3, smoothTime, dt float32) *Vector3 {
+
+	current.X = SmoothDamp(current.X, target.X, &velocity.X, smoothTime, dt)
+	current.Y = SmoothDamp(current.Y, target.Y, &velocity.Y, smoothTime, dt)
+	current.Z = SmoothDamp(current.Z, target.Z, &velocity.Z, smoothTime, dt)
+	return current
+}
@@ -0,0 +1,43 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+// DotProduct4 returns the dot products of the four pairs of vectors of a and b.
+// On amd64 the four products are computed together with SSE instructions,
+// transposing the vectors to lanes of X, Y and Z components.
+func DotProduct4(a, b *[4]Vector3) [4]float32 {
+
+	var out [4]float32
+	dotProduct4(a, b, &out)
+	return out
+}
+
+// CrossProduct4 sets out to the cross products of the four pairs of vectors of a and b.
+// out may be a or b. On amd64 the four products are computed together with SSE instructions.
+func CrossProduct4(a, b *[4]Vector3, out *[4]Vector3) {
+
+	crossProduct4(a, b, out)
+}
+
+// dotProduct4Go is the portable implementation of DotProduct4.
+func dotProduct4Go(a, b *[4]Vector3, out *[4]float32) {
+
+	out[0] = a[0].X*b[0].X + a[0].Y*b[0].Y + a[0].Z*b[0].Z
+	out[1] = a[1].X*b[1].X + a[1].Y*b[1].Y + a[1].Z*b[1].Z
+	out[2] = a[2].X*b[2].X + a[2].Y*b[2].Y + a[2].Z*b[2].Z
+	out[3] = a[3].X*b[3].X + a[3].Y*b[3].Y + a[3].Z*b[3].Z
+}
+
+// crossProduct4Go is the portable implementation of CrossProduct4.
+func crossProduct4Go(a, b *[4]Vector3, out *[4]Vector3) {
+
+	// Reads all the values before writing, as out may alias a or b
+	a0, a1, a2, a3 := a[0], a[1], a[2], a[3]
+	b0, b1, b2, b3 := b[0], b[1], b[2], b[3]
+	out[0] = Vector3{X: a0.Y*b0.Z - a0.Z*b0.Y, Y: a0.Z*b0.X - a0.X*b0.Z, Z: a0.X*b0.Y - a0.Y*b0.X}
+	out[1] = Vector3{X: a1.Y*b1.Z - a1.Z*b1.Y, Y: a1.Z*b1.X - a1.X*b1.Z, Z: a1.X*b1.Y - a1.Y*b1.X}
+	out[2] = Vector3{X: a2.Y*b2.Z - a2.Z*b2.Y, Y: a2.Z*b2.X - a2.X*b2.Z, Z: a2.X*b2.Y - a2.Y*b2.X}
+	out[3] = Vector3{X: a3.Y*b3.Z - a3.Z*b3.Y, Y: a3.Z*b3.X - a3.X*b3.Z, Z: a3.X*b3.Y - a3.Y*b3.X}
+}
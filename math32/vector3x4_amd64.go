@@ -0,0 +1,15 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+// dotProduct4 is implemented in vector3x4_amd64.s.
+//
+//go:noescape
+func dotProduct4(a, b *[4]Vector3, out *[4]float32)
+
+// crossProduct4 is implemented in vector3x4_amd64.s.
+//
+//go:noescape
+func crossProduct4(a, b *[4]Vector3, out *[4]Vector3)
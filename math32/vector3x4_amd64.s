@@ -0,0 +1,92 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+#include "textflag.h"
+
+// The three registers of four packed vectors m0=(x0 y0 z0 x1), m1=(y1 z1 x2 y2) and m2=(z2 x3 y3 z3)
+// are transposed to the lanes x=(x0 x1 x2 x3), y=(y0 y1 y2 y3) and z=(z0 z1 z2 z3), using t0 and t1.
+#define TRANSPOSE_SOA(m0, m1, m2, x, y, z, t0, t1) \
+	MOVAPS m1, t0 \
+	SHUFPS $0x9E, m2, t0 \
+	MOVAPS m0, t1 \
+	SHUFPS $0x49, m1, t1 \
+	MOVAPS m0, x \
+	SHUFPS $0x8C, t0, x \
+	MOVAPS t1, y \
+	SHUFPS $0xD8, t0, y \
+	MOVAPS t1, z \
+	SHUFPS $0xCD, m2, z
+
+// The lanes x, y and z are transposed back to the packed vectors m0, m1 and m2, using t0, t1 and t2.
+#define TRANSPOSE_AOS(x, y, z, m0, m1, m2, t0, t1, t2) \
+	MOVAPS x, t0 \
+	SHUFPS $0x88, y, t0 \
+	MOVAPS y, t1 \
+	SHUFPS $0xDD, z, t1 \
+	MOVAPS z, t2 \
+	SHUFPS $0xD8, x, t2 \
+	MOVAPS t0, m0 \
+	SHUFPS $0x88, t2, m0 \
+	MOVAPS t1, m1 \
+	SHUFPS $0xD8, t0, m1 \
+	MOVAPS t2, m2 \
+	SHUFPS $0xDD, t1, m2
+
+// func dotProduct4(a, b *[4]Vector3, out *[4]float32)
+TEXT ·dotProduct4(SB), NOSPLIT, $0-24
+	MOVQ a+0(FP), AX
+	MOVQ b+8(FP), BX
+	MOVQ out+16(FP), CX
+	MOVUPS 0(AX), X0
+	MOVUPS 16(AX), X1
+	MOVUPS 32(AX), X2
+	MOVUPS 0(BX), X3
+	MOVUPS 16(BX), X4
+	MOVUPS 32(BX), X5
+	// Products of the components, which are then summed by vector
+	MULPS X3, X0
+	MULPS X4, X1
+	MULPS X5, X2
+	TRANSPOSE_SOA(X0, X1, X2, X3, X4, X5, X6, X7)
+	ADDPS X4, X3
+	ADDPS X5, X3
+	MOVUPS X3, 0(CX)
+	RET
+
+// func crossProduct4(a, b *[4]Vector3, out *[4]Vector3)
+TEXT ·crossProduct4(SB), NOSPLIT, $0-24
+	MOVQ a+0(FP), AX
+	MOVQ b+8(FP), BX
+	MOVQ out+16(FP), CX
+	MOVUPS 0(AX), X0
+	MOVUPS 16(AX), X1
+	MOVUPS 32(AX), X2
+	TRANSPOSE_SOA(X0, X1, X2, X3, X4, X5, X6, X7)
+	MOVUPS 0(BX), X0
+	MOVUPS 16(BX), X1
+	MOVUPS 32(BX), X2
+	TRANSPOSE_SOA(X0, X1, X2, X8, X9, X10, X6, X7)
+	// x = ay*bz - az*by
+	MOVAPS X4, X11
+	MULPS X10, X11
+	MOVAPS X5, X0
+	MULPS X9, X0
+	SUBPS X0, X11
+	// y = az*bx - ax*bz
+	MOVAPS X5, X12
+	MULPS X8, X12
+	MOVAPS X3, X0
+	MULPS X10, X0
+	SUBPS X0, X12
+	// z = ax*by - ay*bx
+	MOVAPS X3, X13
+	MULPS X9, X13
+	MOVAPS X4, X0
+	MULPS X8, X0
+	SUBPS X0, X13
+	TRANSPOSE_AOS(X11, X12, X13, X0, X1, X2, X5, X6, X7)
+	MOVUPS X0, 0(CX)
+	MOVUPS X1, 16(CX)
+	MOVUPS X2, 32(CX)
+	RET
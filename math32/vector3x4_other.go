@@ -0,0 +1,17 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !amd64
+
+package math32
+
+func dotProduct4(a, b *[4]Vector3, out *[4]float32) {
+
+	dotProduct4Go(a, b, out)
+}
+
+func crossProduct4(a, b *[4]Vector3, out *[4]Vector3) {
+
+	crossProduct4Go(a, b, out)
+}
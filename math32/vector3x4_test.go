@@ -0,0 +1,91 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// randomVector3x4 returns four vectors with random components in [-1,1).
+func randomVector3x4(rng *rand.Rand) [4]Vector3 {
+
+	var v [4]Vector3
+	for i := range v {
+		v[i] = Vector3{X: 2*rng.Float32() - 1, Y: 2*rng.Float32() - 1, Z: 2*rng.Float32() - 1}
+	}
+	return v
+}
+
+// Test DotProduct4 and CrossProduct4 against the scalar products
+func TestVector3x4(t *testing.T) {
+
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 100; n++ {
+		a := randomVector3x4(rng)
+		b := randomVector3x4(rng)
+		dots := DotProduct4(&a, &b)
+		var cross [4]Vector3
+		CrossProduct4(&a, &b, &cross)
+		for i := 0; i < 4; i++ {
+			if Abs(dots[i]-a[i].Dot(&b[i])) > 1e-6 {
+				t.Fatal("DotProduct4", i, dots[i], a[i].Dot(&b[i]))
+			}
+			var c Vector3
+			c.CrossVectors(&a[i], &b[i])
+			if !cross[i].AlmostEquals(&c, 1e-6) {
+				t.Fatal("CrossProduct4", i, cross[i], c)
+			}
+		}
+		// The output may alias an input
+		CrossProduct4(&a, &b, &a)
+		if a != cross {
+			t.Fatal("CrossProduct4 aliasing a", a, cross)
+		}
+	}
+}
+
+var benchDots [4]float32
+var benchCross [4]Vector3
+
+func BenchmarkDotProduct4(b *testing.B) {
+
+	rng := rand.New(rand.NewSource(1))
+	va, vb := randomVector3x4(rng), randomVector3x4(rng)
+	for i := 0; i < b.N; i++ {
+		benchDots = DotProduct4(&va, &vb)
+	}
+}
+
+func BenchmarkDotProduct4Scalar(b *testing.B) {
+
+	rng := rand.New(rand.NewSource(1))
+	va, vb := randomVector3x4(rng), randomVector3x4(rng)
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < 4; j++ {
+			benchDots[j] = va[j].Dot(&vb[j])
+		}
+	}
+}
+
+func BenchmarkCrossProduct4(b *testing.B) {
+
+	rng := rand.New(rand.NewSource(1))
+	va, vb := randomVector3x4(rng), randomVector3x4(rng)
+	for i := 0; i < b.N; i++ {
+		CrossProduct4(&va, &vb, &benchCross)
+	}
+}
+
+func BenchmarkCrossProduct4Scalar(b *testing.B) {
+
+	rng := rand.New(rand.NewSource(1))
+	va, vb := randomVector3x4(rng), randomVector3x4(rng)
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < 4; j++ {
+			benchCross[j].CrossVectors(&va[j], &vb[j])
+		}
+	}
+}
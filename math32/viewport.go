@@ -0,0 +1,68 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+// Viewport is a rectangle of the screen in pixels, with the origin at the top left corner
+// and the Y axis pointing down, used to convert between screen pixels, normalized device
+// coordinates (NDC) and world space.
+type Viewport struct {
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+// NewViewport creates and returns a pointer to a new Viewport
+// with the specified position and size in pixels.
+func NewViewport(x, y, width, height int) *Viewport {
+
+	return &Viewport{X: x, Y: y, Width: width, Height: height}
+}
+
+// NDCToScreen converts the specified NDC coordinates in [-1,1] to pixel coordinates.
+// 0,0 is the center of the viewport and 1,1 its top right corner.
+// Returns pointer to a new vector with the pixel coordinates.
+func (vp *Viewport) NDCToScreen(ndc *Vector2) *Vector2 {
+
+	return &Vector2{
+		float32(vp.X) + (ndc.X+1)*0.5*float32(vp.Width),
+		float32(vp.Y) + (1-ndc.Y)*0.5*float32(vp.Height),
+	}
+}
+
+// ScreenToNDC converts the specified pixel coordinates to NDC coordinates.
+// It is the inverse of NDCToScreen.
+// Returns pointer to a new vector with the NDC coordinates.
+func (vp *Viewport) ScreenToNDC(screen *Vector2) *Vector2 {
+
+	return &Vector2{
+		(screen.X-float32(vp.X))/float32(vp.Width)*2 - 1,
+		1 - (screen.Y-float32(vp.Y))/float32(vp.Height)*2,
+	}
+}
+
+// WorldToScreen projects the specified world position with the specified view projection matrix.
+// Returns pointer to a new vector with the pixel coordinates and the window depth,
+// which is 0 at the near plane and 1 at the far plane.
+func (vp *Viewport) WorldToScreen(worldPos *Vector3, viewProj *Matrix4) (*Vector2, float32) {
+
+	ndc := *worldPos
+	ndc.ApplyProjection(viewProj)
+	return vp.NDCToScreen(&Vector2{ndc.X, ndc.Y}), (ndc.Z + 1) * 0.5
+}
+
+// ScreenToRay returns a pointer to a new world space ray from the near plane through the
+// specified pixel, using the inverse of the view projection matrix.
+// The ray direction is normalized.
+func (vp *Viewport) ScreenToRay(screenPos *Vector2, invViewProj *Matrix4) *Ray {
+
+	ndc := vp.ScreenToNDC(screenPos)
+	near := Vector3{ndc.X, ndc.Y, -1}
+	far := Vector3{ndc.X, ndc.Y, 1}
+	near.ApplyProjection(invViewProj)
+	far.ApplyProjection(invViewProj)
+	far.Sub(&near).Normalize()
+	return NewRay(&near, &far)
+}
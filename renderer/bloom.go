@@ -0,0 +1,242 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"fmt"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/texture"
+)
+
+// bloomDownFragmentSource is the fragment shader of the dual Kawase downsampling of the bloom,
+// which extracts the bright pixels for the first level when PREFILTER is defined.
+const bloomDownFragmentSource = `
+in vec2 vTexcoord;
+out vec4 FragColor;
+
+uniform sampler2D uInput;
+uniform float uThreshold;
+uniform float uKnee;
+
+vec3 tap(vec2 uv) {
+
+    vec3 c = texture(uInput, uv).rgb;
+#ifdef PREFILTER
+    // Soft threshold: quadratic between threshold-knee and threshold+knee, linear above
+    float lum = dot(c, vec3(0.2126, 0.7152, 0.0722));
+    float soft = clamp(lum - uThreshold + uKnee, 0.0, 2.0 * uKnee);
+    soft = soft * soft / (4.0 * uKnee + 1e-4);
+    c *= max(soft, lum - uThreshold) / max(lum, 1e-4);
+#endif
+    return c;
+}
+
+void main() {
+
+    vec2 hp = 0.5 / vec2(textureSize(uInput, 0));
+    vec3 sum = tap(vTexcoord) * 4.0;
+    sum += tap(vTexcoord - hp);
+    sum += tap(vTexcoord + hp);
+    sum += tap(vTexcoord + vec2(hp.x, -hp.y));
+    sum += tap(vTexcoord - vec2(hp.x, -hp.y));
+    FragColor = vec4(sum / 8.0, 1.0);
+}
+`
+
+// bloomUpFragmentSource is the fragment shader of the dual Kawase upsampling of the bloom,
+// which adds the upsampled smaller level to the level of the same size.
+const bloomUpFragmentSource = `
+in vec2 vTexcoord;
+out vec4 FragColor;
+
+uniform sampler2D uInput;
+uniform sampler2D uBase;
+uniform float uInputWeight;
+uniform float uScale;
+
+void main() {
+
+    vec2 hp = 0.5 / vec2(textureSize(uInput, 0));
+    vec3 sum = texture(uInput, vTexcoord + vec2(-2.0 * hp.x, 0.0)).rgb;
+    sum += texture(uInput, vTexcoord + vec2(-hp.x, hp.y)).rgb * 2.0;
+    sum += texture(uInput, vTexcoord + vec2(0.0, 2.0 * hp.y)).rgb;
+    sum += texture(uInput, vTexcoord + vec2(hp.x, hp.y)).rgb * 2.0;
+    sum += texture(uInput, vTexcoord + vec2(2.0 * hp.x, 0.0)).rgb;
+    sum += texture(uInput, vTexcoord + vec2(hp.x, -hp.y)).rgb * 2.0;
+    sum += texture(uInput, vTexcoord + vec2(0.0, -2.0 * hp.y)).rgb;
+    sum += texture(uInput, vTexcoord + vec2(-hp.x, -hp.y)).rgb * 2.0;
+    FragColor = vec4((texture(uBase, vTexcoord).rgb + sum / 12.0 * uInputWeight) * uScale, 1.0);
+}
+`
+
+// BloomSampler is the name of the shader sampler of the bloom computed by a BloomPass.
+const BloomSampler = "uBloomSampler"
+
+// BloomPass computes the bloom of a HDR image: the glow around its bright pixels. The pixels
+// brighter than the threshold are extracted into the first of a chain of render targets of
+// halved sizes, blurred while downsampling them along the chain and upsampled back adding the
+// levels, so that the bloom combines blurs of increasing radius.
+// The parameters can be changed at any time without reallocating the render targets.
+type BloomPass struct {
+	gs        *gls.GLS
+	Threshold float32                 // luminance above which the pixels bloom
+	Knee      float32                 // width of the soft transition around the threshold
+	Strength  float32                 // factor of the bloom texture
+	down      []*texture.RenderTarget // downsampled levels, from half the size of the image
+	up        []*texture.RenderTarget // accumulated levels
+	prefilter *fullscreenPass
+	downPass  *fullscreenPass
+	upPass    *fullscreenPass
+}
+
+// NewBloomPass creates and returns a pointer to a new BloomPass for images of the specified size,
+// with the specified number of levels, luminance threshold and strength. The knee is half the threshold.
+// The number of levels is reduced if the smallest level would be less than 2 pixels wide or high.
+// Returns an error if the number of levels is not positive or the shaders or render targets cannot be created.
+func NewBloomPass(gs *gls.GLS, width, height int, numMips int, threshold, strength float32) (*BloomPass, error) {
+
+	if numMips < 1 {
+		return nil, fmt.Errorf("invalid number of bloom levels:%d", numMips)
+	}
+	b := new(BloomPass)
+	b.gs = gs
+	b.Threshold = threshold
+	b.Knee = threshold / 2
+	b.Strength = strength
+
+	var err error
+	b.prefilter, err = newFullscreenPass(gs, "#define PREFILTER\n", bloomDownFragmentSource)
+	if err == nil {
+		b.downPass, err = newFullscreenPass(gs, "", bloomDownFragmentSource)
+	}
+	if err == nil {
+		b.upPass, err = newFullscreenPass(gs, "", bloomUpFragmentSource)
+	}
+	if err != nil {
+		b.Dispose()
+		return nil, err
+	}
+	err = b.Resize(width, height, numMips)
+	if err != nil {
+		b.Dispose()
+		return nil, err
+	}
+	return b, nil
+}
+
+// Resize reallocates the render targets of the levels for images of the specified size.
+func (b *BloomPass) Resize(width, height int, numMips int) error {
+
+	b.disposeTargets()
+	opts := texture.RenderTargetOpts{ColorFormat: gls.RGBA16F}
+	w, h := width/2, height/2
+	for i := 0; i < numMips && w >= 2 && h >= 2; i++ {
+		down, err := texture.NewRenderTarget(b.gs, w, h, opts)
+		if err != nil {
+			return err
+		}
+		b.down = append(b.down, down)
+		up, err := texture.NewRenderTarget(b.gs, w, h, opts)
+		if err != nil {
+			return err
+		}
+		b.up = append(b.up, up)
+		w, h = w/2, h/2
+	}
+	if len(b.down) == 0 {
+		return fmt.Errorf("image too small for bloom:%dx%d", width, height)
+	}
+	b.up[0].ColorTexture().SetUniformNames(BloomSampler, "uBloomTexParams")
+	return nil
+}
+
+// Levels returns the number of levels of the chain.
+func (b *BloomPass) Levels() int {
+
+	return len(b.down)
+}
+
+// Apply computes the bloom of the color texture of the specified HDR render target and
+// returns the texture with the bloom, at half the size of the image, to add to the image.
+func (b *BloomPass) Apply(hdrTarget *texture.RenderTarget) *texture.Texture2D {
+
+	// Bright pixels into the first level, then downsampled along the chain
+	src := hdrTarget.ColorTexture()
+	for i, dst := range b.down {
+		p := b.downPass
+		if i == 0 {
+			p = b.prefilter
+		}
+		dst.Bind()
+		p.use()
+		p.bindTexture("uInput", src, 0)
+		if i == 0 {
+			b.gs.Uniform1f(p.uniform("uThreshold"), b.Threshold)
+			b.gs.Uniform1f(p.uniform("uKnee"), b.Knee)
+		}
+		p.draw()
+		dst.Unbind()
+		src = dst.ColorTexture()
+	}
+
+	// Upsampled back adding the levels, from the smallest
+	n := len(b.down)
+	p := b.upPass
+	for i := n - 1; i >= 0; i-- {
+		input := src
+		weight := float32(1)
+		if i == n-1 {
+			// The smallest level has no smaller level to add
+			input = b.down[i].ColorTexture()
+			weight = 0
+		}
+		scale := float32(1)
+		if i == 0 {
+			scale = b.Strength
+		}
+		b.up[i].Bind()
+		p.use()
+		p.bindTexture("uInput", input, 0)
+		p.bindTexture("uBase", b.down[i].ColorTexture(), 1)
+		b.gs.Uniform1f(p.uniform("uInputWeight"), weight)
+		b.gs.Uniform1f(p.uniform("uScale"), scale)
+		p.draw()
+		b.up[i].Unbind()
+		src = b.up[i].ColorTexture()
+	}
+	return src
+}
+
+// Texture returns the texture with the bloom computed by the last Apply,
+// bound to the shader sampler BloomSampler.
+func (b *BloomPass) Texture() *texture.Texture2D {
+
+	return b.up[0].ColorTexture()
+}
+
+// disposeTargets releases the render targets of the levels.
+func (b *BloomPass) disposeTargets() {
+
+	for i := range b.down {
+		b.down[i].Dispose()
+	}
+	for i := range b.up {
+		b.up[i].Dispose()
+	}
+	b.down = nil
+	b.up = nil
+}
+
+// Dispose releases the OpenGL resources of this BloomPass.
+func (b *BloomPass) Dispose() {
+
+	b.disposeTargets()
+	for _, p := range []*fullscreenPass{b.prefilter, b.downPass, b.upPass} {
+		if p != nil {
+			p.dispose()
+		}
+	}
+}
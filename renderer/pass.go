@@ -0,0 +1,87 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"fmt"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/texture"
+)
+
+// fullscreenVertexSource is the vertex shader of the fullscreen passes: a triangle covering
+// the viewport computed from the vertex index, without vertex buffers.
+const fullscreenVertexSource = `
+out vec2 vTexcoord;
+
+void main() {
+
+    vec2 pos = vec2(float((gl_VertexID << 1) & 2), float(gl_VertexID & 2));
+    vTexcoord = pos;
+    gl_Position = vec4(pos * 2.0 - 1.0, 0.0, 1.0);
+}
+`
+
+// fullscreenPass is a shader program run for each pixel of the current render target,
+// for the post-processing passes.
+type fullscreenPass struct {
+	gs   *gls.GLS
+	prog *gls.Program
+	vao  uint32 // empty vertex array required to draw
+}
+
+// newFullscreenPass builds a fullscreen pass with the specified fragment shader source,
+// preceded by the GLSL version and the specified defines.
+func newFullscreenPass(gs *gls.GLS, defines, fragSource string) (*fullscreenPass, error) {
+
+	prefix := fmt.Sprintf("#version %s\n%s", GLSL_VERSION, defines)
+	p := new(fullscreenPass)
+	p.gs = gs
+	p.prog = gs.NewProgram()
+	p.prog.AddShader(gls.VERTEX_SHADER, prefix+fullscreenVertexSource)
+	p.prog.AddShader(gls.FRAGMENT_SHADER, prefix+fragSource)
+	err := p.prog.Build()
+	if err != nil {
+		return nil, err
+	}
+	p.vao = gs.GenVertexArray()
+	return p, nil
+}
+
+// use sets the program of the pass as the current program.
+func (p *fullscreenPass) use() {
+
+	p.gs.UseProgram(p.prog)
+}
+
+// bindTexture binds the specified texture to the specified texture unit and sets the specified sampler uniform.
+func (p *fullscreenPass) bindTexture(sampler string, tex *texture.Texture2D, unit int) {
+
+	tex.Bind(p.gs, unit)
+	p.gs.Uniform1i(p.prog.GetUniformLocation(sampler), int32(unit))
+}
+
+// uniform returns the location of the specified uniform of the program.
+func (p *fullscreenPass) uniform(name string) int32 {
+
+	return p.prog.GetUniformLocation(name)
+}
+
+// draw renders the pass into the current render target, without depth test and blending.
+func (p *fullscreenPass) draw() {
+
+	p.gs.Disable(gls.DEPTH_TEST)
+	p.gs.Disable(gls.BLEND)
+	p.gs.Disable(gls.CULL_FACE)
+	p.gs.BindVertexArray(p.vao)
+	p.gs.DrawArrays(gls.TRIANGLES, 0, 3)
+}
+
+// dispose releases the OpenGL resources of the pass.
+func (p *fullscreenPass) dispose() {
+
+	p.gs.DeleteVertexArrays(p.vao)
+	p.gs.DeleteProgram(p.prog.Handle())
+}
@@ -0,0 +1,181 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"math"
+
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+)
+
+// Bits of the sort keys of the draw calls, from the most significant
+const (
+	keyTransparentShift = 63 // transparent calls after the opaque ones
+	keyOrderShift       = 48 // user supplied render order, 15 bits
+	keyHighShift        = 24 // material of opaque calls or depth of transparent calls, 24 bits
+	keyMask24           = 1<<24 - 1
+	keyOrderMask        = 1<<15 - 1
+)
+
+// transparencyChecker is the interface of the materials which are transparent
+// depending on their parameters, in addition to their transparent flag.
+type transparencyChecker interface {
+	IsTransparent() bool
+}
+
+// DrawCall is a graphic material to render with its sort key.
+type DrawCall struct {
+	Key             uint64
+	GraphicMaterial *graphic.GraphicMaterial
+}
+
+// RenderQueue accumulates the draw calls of a frame and sorts them for rendering:
+// the opaque calls first, grouped by material and front to back inside each material
+// to reduce the state changes and the overdraw, then the transparent calls back to front
+// so that they are blended correctly. The user supplied render order of the graphics
+// takes precedence over the material and the depth.
+type RenderQueue struct {
+	calls     []DrawCall
+	tmp       []DrawCall                    // buffer of the radix sort
+	materials map[material.IMaterial]uint32 // identifiers of the materials, in order of first submission
+	sorting   bool                          // whether the calls are sorted by material and depth
+}
+
+// NewRenderQueue creates and returns a pointer to a new empty RenderQueue.
+func NewRenderQueue() *RenderQueue {
+
+	rq := new(RenderQueue)
+	rq.materials = make(map[material.IMaterial]uint32)
+	rq.sorting = true
+	return rq
+}
+
+// SetSorting sets whether the calls are sorted by material and depth.
+// When not sorted, the transparent calls are still rendered after the opaque calls,
+// each in the order of submission.
+func (rq *RenderQueue) SetSorting(sorting bool) {
+
+	rq.sorting = sorting
+}
+
+// Submit adds a draw call of the specified graphic material at the specified
+// depth: its distance in front of the camera.
+func (rq *RenderQueue) Submit(grmat *graphic.GraphicMaterial, depth float32) {
+
+	imat := grmat.IMaterial()
+	transparent := imat.GetMaterial().Transparent()
+	if tm, ok := imat.(transparencyChecker); ok && tm.IsTransparent() {
+		transparent = true
+	}
+	var key uint64
+	if transparent {
+		key = 1 << keyTransparentShift
+	}
+	if rq.sorting {
+		// Biased so that the negative orders come first
+		order := int64(grmat.IGraphic().GetGraphic().RenderOrder()) + 1<<14
+		if order < 0 {
+			order = 0
+		} else if order > keyOrderMask {
+			order = keyOrderMask
+		}
+		key |= uint64(order) << keyOrderShift
+
+		id, ok := rq.materials[imat]
+		if !ok {
+			id = uint32(len(rq.materials)) & keyMask24
+			rq.materials[imat] = id
+		}
+		d := depthKey(depth)
+		if transparent {
+			key |= uint64(keyMask24-d)<<keyHighShift | uint64(id)
+		} else {
+			key |= uint64(id)<<keyHighShift | uint64(d)
+		}
+	}
+	rq.calls = append(rq.calls, DrawCall{Key: key, GraphicMaterial: grmat})
+}
+
+// depthKey returns the 24 most significant bits of the specified depth
+// converted to an unsigned integer with the same order.
+func depthKey(depth float32) uint32 {
+
+	bits := math.Float32bits(depth)
+	if bits&(1<<31) != 0 {
+		bits = ^bits
+	} else {
+		bits |= 1 << 31
+	}
+	return bits >> 8
+}
+
+// Sort sorts the draw calls by their keys with a radix sort,
+// keeping the order of submission of the calls with the same key.
+func (rq *RenderQueue) Sort() {
+
+	n := len(rq.calls)
+	if n < 2 {
+		return
+	}
+	if cap(rq.tmp) < n {
+		rq.tmp = make([]DrawCall, n)
+	}
+	src, dst := rq.calls, rq.tmp[:n]
+	var counts [256]int
+	for shift := uint(0); shift < 64; shift += 8 {
+		for i := range counts {
+			counts[i] = 0
+		}
+		for i := range src {
+			counts[byte(src[i].Key>>shift)]++
+		}
+		// Skips the bytes equal in all the keys
+		if counts[byte(src[0].Key>>shift)] == n {
+			continue
+		}
+		pos := 0
+		for i, c := range counts {
+			counts[i] = pos
+			pos += c
+		}
+		for i := range src {
+			b := byte(src[i].Key >> shift)
+			dst[counts[b]] = src[i]
+			counts[b]++
+		}
+		src, dst = dst, src
+	}
+	if &src[0] != &rq.calls[0] {
+		copy(rq.calls, src)
+	}
+}
+
+// Calls returns the draw calls in the current order.
+func (rq *RenderQueue) Calls() []DrawCall {
+
+	return rq.calls
+}
+
+// Len returns the number of draw calls.
+func (rq *RenderQueue) Len() int {
+
+	return len(rq.calls)
+}
+
+// Clear removes all the draw calls, keeping the allocated memory for the next frame.
+func (rq *RenderQueue) Clear() {
+
+	for i := range rq.calls {
+		rq.calls[i] = DrawCall{}
+	}
+	rq.calls = rq.calls[:0]
+	for i := range rq.tmp {
+		rq.tmp[i] = DrawCall{}
+	}
+	for imat := range rq.materials {
+		delete(rq.materials, imat)
+	}
+}
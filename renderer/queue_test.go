@@ -0,0 +1,137 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// queueCall is a draw call submitted to a render queue by the tests.
+type queueCall struct {
+	grmat *graphic.GraphicMaterial
+	depth float32
+}
+
+// newQueueCall returns a call of a new mesh with the specified material, render order and depth.
+func newQueueCall(mat material.IMaterial, order int, depth float32) queueCall {
+
+	mesh := graphic.NewMesh(geometry.NewGeometry(), mat)
+	mesh.SetRenderOrder(order)
+	return queueCall{&mesh.Materials()[0], depth}
+}
+
+// newQueueMaterial returns a new material with the specified transparency.
+func newQueueMaterial(transparent bool) material.IMaterial {
+
+	mat := material.NewStandard(&math32.Color{R: 1})
+	mat.SetTransparent(transparent)
+	return mat
+}
+
+// sortedCalls submits the specified calls to a new render queue and returns the indices of the calls in sorted order.
+func sortedCalls(calls []queueCall, sorting bool) []int {
+
+	rq := NewRenderQueue()
+	rq.SetSorting(sorting)
+	index := make(map[*graphic.GraphicMaterial]int)
+	for i, c := range calls {
+		rq.Submit(c.grmat, c.depth)
+		index[c.grmat] = i
+	}
+	rq.Sort()
+	order := make([]int, rq.Len())
+	for i, dc := range rq.Calls() {
+		order[i] = index[dc.GraphicMaterial]
+	}
+	return order
+}
+
+// checkOrder tests that the specified order is the expected one.
+func checkOrder(t *testing.T, name string, order, expected []int) {
+
+	t.Helper()
+	if len(order) != len(expected) {
+		t.Fatal(name, "order", order, "instead of", expected)
+	}
+	for i := range order {
+		if order[i] != expected[i] {
+			t.Error(name, "order", order, "instead of", expected)
+			return
+		}
+	}
+}
+
+// Test that the transparent calls are sorted after the opaque calls and back to front
+func TestRenderQueueTransparent(t *testing.T) {
+
+	opaque := newQueueMaterial(false)
+	transparent := newQueueMaterial(true)
+	calls := []queueCall{
+		newQueueCall(transparent, 0, 1),
+		newQueueCall(opaque, 0, 5),
+		newQueueCall(transparent, 0, 5),
+		newQueueCall(opaque, 0, 1),
+		newQueueCall(transparent, 0, 3),
+		newQueueCall(opaque, 0, 3),
+	}
+	// Opaque front to back, then transparent back to front
+	checkOrder(t, "sorted", sortedCalls(calls, true), []int{3, 5, 1, 2, 4, 0})
+	// Submission order, transparent after opaque
+	checkOrder(t, "unsorted", sortedCalls(calls, false), []int{1, 3, 5, 0, 2, 4})
+}
+
+// Test that the opaque calls are grouped by material and that the render order takes precedence
+func TestRenderQueueMaterialOrder(t *testing.T) {
+
+	mat1 := newQueueMaterial(false)
+	mat2 := newQueueMaterial(false)
+	calls := []queueCall{
+		newQueueCall(mat1, 0, 4),
+		newQueueCall(mat2, 0, 1),
+		newQueueCall(mat1, 0, 2),
+		newQueueCall(mat2, 0, 3),
+		newQueueCall(mat2, -1, 10),
+		newQueueCall(mat1, 1, 0),
+	}
+	checkOrder(t, "material", sortedCalls(calls, true), []int{4, 2, 0, 1, 3, 5})
+}
+
+// Test that the calls with equal keys keep their order of submission
+func TestRenderQueueStable(t *testing.T) {
+
+	opaque := newQueueMaterial(false)
+	transparent := newQueueMaterial(true)
+	var calls []queueCall
+	for i := 0; i < 300; i++ {
+		mat := opaque
+		if i%2 == 1 {
+			mat = transparent
+		}
+		calls = append(calls, newQueueCall(mat, 0, float32(i%3)))
+	}
+	order := sortedCalls(calls, true)
+	for i := 1; i < len(order); i++ {
+		a, b := calls[order[i-1]], calls[order[i]]
+		if a.grmat.IMaterial() == b.grmat.IMaterial() && a.depth == b.depth && order[i-1] > order[i] {
+			t.Fatal("calls", order[i-1], "and", order[i], "with equal keys not in submission order")
+		}
+	}
+}
+
+// Test the order of the depth keys, including negative depths
+func TestDepthKey(t *testing.T) {
+
+	depths := []float32{math32.Inf(-1), -1e10, -2, -0.5, 0, 0.5, 1, 2, 1e10, math32.Inf(1)}
+	for i := 1; i < len(depths); i++ {
+		if depthKey(depths[i-1]) >= depthKey(depths[i]) {
+			t.Error("depthKey of", depths[i-1], "not less than of", depths[i])
+		}
+	}
+}
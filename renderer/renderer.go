@@ -12,35 +12,33 @@ import (
 	"github.com/g3n/engine/gui"
 	"github.com/g3n/engine/light"
 	"github.com/g3n/engine/math32"
-	"sort"
 )
 
 // Renderer renders a 3D scene and/or a 2D GUI on the current window.
 type Renderer struct {
 	gs           *gls.GLS
-	shaman       Shaman                     // Internal shader manager
-	stats        Stats                      // Renderer statistics
-	prevStats    Stats                      // Renderer statistics for previous frame
-	scene        core.INode                 // Node containing 3D scene to render
-	panelGui     gui.IPanel                 // Panel containing GUI to render
-	panel3D      gui.IPanel                 // Panel which contains the 3D scene
-	ambLights    []*light.Ambient           // Array of ambient lights for last scene
-	dirLights    []*light.Directional       // Array of directional lights for last scene
-	pointLights  []*light.Point             // Array of point
-	spotLights   []*light.Spot              // Array of spot lights for the scene
-	others       []core.INode               // Other nodes (audio, players, etc)
-	rgraphics    []*graphic.Graphic         // Array of rendered graphics
-	cgraphics    []*graphic.Graphic         // Array of rendered graphics
-	grmatsOpaque []*graphic.GraphicMaterial // Array of rendered opaque graphic materials for scene
-	grmatsTransp []*graphic.GraphicMaterial // Array of rendered transparent graphic materials for scene
-	rinfo        core.RenderInfo            // Preallocated Render info
-	specs        ShaderSpecs                // Preallocated Shader specs
-	sortObjects  bool                       // Flag indicating whether objects should be sorted before rendering
-	redrawGui    bool                       // Flag indicating the gui must be redrawn completely
-	rendered     bool                       // Flag indicating if anything was rendered
-	panList      []gui.IPanel               // list of panels to render
-	frameBuffers int                        // Number of frame buffers
-	frameCount   int                        // Current number of frame buffers to write
+	shaman       Shaman               // Internal shader manager
+	stats        Stats                // Renderer statistics
+	prevStats    Stats                // Renderer statistics for previous frame
+	scene        core.INode           // Node containing 3D scene to render
+	panelGui     gui.IPanel           // Panel containing GUI to render
+	panel3D      gui.IPanel           // Panel which contains the 3D scene
+	ambLights    []*light.Ambient     // Array of ambient lights for last scene
+	dirLights    []*light.Directional // Array of directional lights for last scene
+	pointLights  []*light.Point       // Array of point
+	spotLights   []*light.Spot        // Array of spot lights for the scene
+	others       []core.INode         // Other nodes (audio, players, etc)
+	rgraphics    []*graphic.Graphic   // Array of rendered graphics
+	cgraphics    []*graphic.Graphic   // Array of rendered graphics
+	queue        *RenderQueue         // Queue of the graphic materials to render for scene
+	rinfo        core.RenderInfo      // Preallocated Render info
+	specs        ShaderSpecs          // Preallocated Shader specs
+	sortObjects  bool                 // Flag indicating whether objects should be sorted before rendering
+	redrawGui    bool                 // Flag indicating the gui must be redrawn completely
+	rendered     bool                 // Flag indicating if anything was rendered
+	panList      []gui.IPanel         // list of panels to render
+	frameBuffers int                  // Number of frame buffers
+	frameCount   int                  // Current number of frame buffers to write
 }
 
 // Stats describes how many object types were rendered.
@@ -66,8 +64,7 @@ func NewRenderer(gs *gls.GLS) *Renderer {
 	r.others = make([]core.INode, 0)
 	r.rgraphics = make([]*graphic.Graphic, 0)
 	r.cgraphics = make([]*graphic.Graphic, 0)
-	r.grmatsOpaque = make([]*graphic.GraphicMaterial, 0)
-	r.grmatsTransp = make([]*graphic.GraphicMaterial, 0)
+	r.queue = NewRenderQueue()
 	r.panList = make([]gui.IPanel, 0)
 	r.frameBuffers = 2
 	r.sortObjects = true
@@ -193,8 +190,7 @@ func (r *Renderer) renderScene(iscene core.INode, icam camera.ICamera) error {
 	r.others = r.others[0:0]
 	r.rgraphics = r.rgraphics[0:0]
 	r.cgraphics = r.cgraphics[0:0]
-	r.grmatsOpaque = r.grmatsOpaque[0:0]
-	r.grmatsTransp = r.grmatsTransp[0:0]
+	r.queue.Clear()
 
 	// Prepare for frustum culling
 	var proj math32.Matrix4
@@ -276,58 +272,24 @@ func (r *Renderer) renderScene(iscene core.INode, icam camera.ICamera) error {
 	r.specs.PointLightsMax = len(r.pointLights)
 	r.specs.SpotLightsMax = len(r.spotLights)
 
-	// Pre-calculate MV and MVP matrices and compile lists of opaque and transparent graphic materials
+	// Pre-calculate MV and MVP matrices and submit the graphic materials to the render queue,
+	// which sorts the opaque graphics front to back and the transparent graphics back to front
+	r.queue.SetSorting(r.sortObjects)
 	for _, gr := range r.rgraphics {
 		// Calculate MV and MVP matrices for all graphics to be rendered
 		gr.CalculateMatrices(r.gs, &r.rinfo)
 
-		// Append all graphic materials of this graphic to list of graphic materials to be rendered
+		// Depth of the graphic in front of the camera
+		pos := gr.Position()
+		pos.ApplyMatrix4(gr.ModelViewMatrix())
+
+		// Submit all graphic materials of this graphic
 		materials := gr.Materials()
 		for i := 0; i < len(materials); i++ {
-			if materials[i].IMaterial().GetMaterial().Transparent() {
-				r.grmatsTransp = append(r.grmatsTransp, &materials[i])
-			} else {
-				r.grmatsOpaque = append(r.grmatsOpaque, &materials[i])
-			}
+			r.queue.Submit(&materials[i], -pos.Z)
 		}
 	}
-
-	// TODO: If both GraphicMaterials belong to same Graphic we might want to keep their relative order...
-
-	// Z-sort graphic materials (opaque front-to-back and transparent back-to-front)
-	if r.sortObjects {
-		// Internal function to render a list of graphic materials
-		var zSortGraphicMaterials func(grmats []*graphic.GraphicMaterial, backToFront bool)
-		zSortGraphicMaterials = func(grmats []*graphic.GraphicMaterial, backToFront bool) {
-			sort.Slice(grmats, func(i, j int) bool {
-				gr1 := grmats[i].IGraphic().GetGraphic()
-				gr2 := grmats[j].IGraphic().GetGraphic()
-
-				// Check for user-supplied render order
-				rO1 := gr1.RenderOrder()
-				rO2 := gr2.RenderOrder()
-				if rO1 != rO2 {
-					return  rO1 < rO2
-				}
-
-				mvm1 := gr1.ModelViewMatrix()
-				mvm2 := gr2.ModelViewMatrix()
-				g1pos := gr1.Position()
-				g2pos := gr2.Position()
-				g1pos.ApplyMatrix4(mvm1)
-				g2pos.ApplyMatrix4(mvm2)
-
-				if backToFront {
-					return g1pos.Z < g2pos.Z
-				}
-
-				return g1pos.Z > g2pos.Z
-			})
-		}
-
-		zSortGraphicMaterials(r.grmatsOpaque, false) // Sort opaque graphics front to back
-		zSortGraphicMaterials(r.grmatsTransp, true)  // Sort transparent graphics back to front
-	}
+	r.queue.Sort()
 
 	// Render other nodes (audio players, etc)
 	for i := 0; i < len(r.others); i++ {
@@ -341,7 +303,7 @@ func (r *Renderer) renderScene(iscene core.INode, icam camera.ICamera) error {
 
 	// If there is graphic material to render or there was in the previous frame
 	// it is necessary to clear the screen.
-	if len(r.grmatsOpaque) > 0 || len(r.grmatsTransp) > 0 || r.prevStats.Graphics > 0 {
+	if r.queue.Len() > 0 || r.prevStats.Graphics > 0 {
 		// If the 3D scene to draw is to be confined to user specified panel
 		// sets scissor to avoid erasing gui elements outside of this panel
 		if r.panel3D != nil {
@@ -373,11 +335,11 @@ func (r *Renderer) renderScene(iscene core.INode, icam camera.ICamera) error {
 
 	err := error(nil)
 
-	// Internal function to render a list of graphic materials
-	var renderGraphicMaterials func(grmats []*graphic.GraphicMaterial)
-	renderGraphicMaterials = func(grmats []*graphic.GraphicMaterial) {
-		// For each *GraphicMaterial
-		for _, grmat := range grmats {
+	// Internal function to render the draw calls
+	var renderGraphicMaterials func(calls []DrawCall)
+	renderGraphicMaterials = func(calls []DrawCall) {
+		for _, call := range calls {
+			grmat := call.GraphicMaterial
 			mat := grmat.IMaterial().GetMaterial()
 			geom := grmat.IGraphic().GetGeometry()
 			gr := grmat.IGraphic().GetGraphic()
@@ -424,11 +386,7 @@ func (r *Renderer) renderScene(iscene core.INode, icam camera.ICamera) error {
 		}
 	}
 
-	renderGraphicMaterials(r.grmatsOpaque) // Render opaque objects (front to back)
-	if err != nil {
-		return err
-	}
-	renderGraphicMaterials(r.grmatsTransp) // Render transparent objects (back to front)
+	renderGraphicMaterials(r.queue.Calls()) // Render opaque objects then transparent objects
 
 	return err
 }
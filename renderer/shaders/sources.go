@@ -629,7 +629,6 @@ uniform sampler2D uEmissiveSampler;
 #endif
 #ifdef HAS_OCCLUSIONMAP
 uniform sampler2D uOcclusionSampler;
-uniform float uOcclusionStrength;
 #endif
 
 // Material parameters uniform array
@@ -639,6 +638,8 @@ uniform vec4 Material[3];
 #define uEmissiveColor      Material[1]
 #define uMetallicFactor     Material[2].x
 #define uRoughnessFactor    Material[2].y
+#define uOcclusionStrength  Material[2].z
+#define uAlphaCutoff        Material[2].w
 
 #include <lights>
 
@@ -857,6 +858,11 @@ void main() {
     vec4 baseColor = uBaseColor;
 #endif
 
+    // Alpha mask
+    if (uAlphaCutoff > 0.0 && baseColor.a < uAlphaCutoff) {
+        discard;
+    }
+
     vec3 f0 = vec3(0.04);
     vec3 diffuseColor = baseColor.rgb * (vec3(1.0) - f0);
     diffuseColor *= 1.0 - metallic;
@@ -956,7 +962,7 @@ void main() {
     // Apply optional PBR terms for additional (optional) shading
 #ifdef HAS_OCCLUSIONMAP
     float ao = texture(uOcclusionSampler, FragTexcoord).r;
-    color = mix(color, color * ao, 1.0);//, uOcclusionStrength);
+    color = mix(color, color * ao, uOcclusionStrength);
 #endif
 
 #ifdef HAS_EMISSIVEMAP
@@ -0,0 +1,296 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// skyRadianceSource declares the uniforms of the Preetham sky and the function skyRadiance returning
+// the color of the sky in a direction: the luminance and chromaticity of the zenith scaled by the
+// Perez distribution. It is shared by the shaders rendering or reflecting a ProceduralSky.
+const skyRadianceSource = `
+uniform vec3 uSunDir;
+uniform vec3 uZenith;   // Y, x, y of the zenith
+uniform vec3 uPerez[5]; // A, B, C, D, E coefficients of Y, x and y
+uniform vec3 uPerezSun; // distribution in the direction of the sun at the zenith
+uniform float uSkyScale;
+
+vec3 perez(float cosTheta, float gamma, float cosGamma) {
+
+    return (1.0 + uPerez[0] * exp(uPerez[1] / cosTheta)) *
+        (1.0 + uPerez[2] * exp(uPerez[3] * gamma) + uPerez[4] * cosGamma * cosGamma);
+}
+
+vec3 skyRadiance(vec3 dir) {
+
+    float cosTheta = max(dir.y, 0.01);
+    float cosGamma = clamp(dot(dir, uSunDir), -1.0, 1.0);
+    vec3 Yxy = uZenith * perez(cosTheta, acos(cosGamma), cosGamma) / uPerezSun;
+
+    // Yxy to XYZ to linear sRGB
+    vec3 xyz = vec3(Yxy.y / Yxy.z * Yxy.x, Yxy.x, (1.0 - Yxy.y - Yxy.z) / Yxy.z * Yxy.x);
+    mat3 toRGB = mat3(3.2406, -0.9689, 0.0557, -1.5372, 1.8758, -0.2040, -0.4986, 0.0415, 1.0570);
+    return max(toRGB * xyz, 0.0) * uSkyScale;
+}
+`
+
+// skyFragmentSource is the fragment shader of the sky, with the radiance in the direction of each pixel.
+const skyFragmentSource = skyRadianceSource + `
+in vec2 vTexcoord;
+out vec4 FragColor;
+
+uniform mat4 uInvViewProj;
+
+void main() {
+
+    vec4 p = uInvViewProj * vec4(vTexcoord * 2.0 - 1.0, 1.0, 1.0);
+    FragColor = vec4(skyRadiance(normalize(p.xyz / p.w)), 1.0);
+}
+`
+
+// skyAmbientSamples is the number of elevations and half the number of azimuths
+// of the directions of the sky integrated by AmbientColor.
+const skyAmbientSamples = 16
+
+// ProceduralSky renders the sky with the analytic model of Preetham, Shirley and Smits,
+// from the direction of the sun and the turbidity of the atmosphere, and computes the colors
+// of the sun and of the ambient light of the sky with the same model.
+// The colors are linear, with the luminance of the sky in kcd/m² multiplied by Scale.
+type ProceduralSky struct {
+	gs        *gls.GLS
+	Scale     float32 // factor of the luminance of the sky
+	sunDir    math32.Vector3
+	turbidity float32
+	zenith    math32.Vector3    // Y, x, y of the zenith
+	perez     [5]math32.Vector3 // A, B, C, D, E coefficients of Y, x and y
+	ambient   math32.Color      // ambient color without the Scale factor
+	ambientOk bool              // ambient is up to date
+	pass      *fullscreenPass
+}
+
+// NewProceduralSky creates and returns a pointer to a new ProceduralSky with the specified
+// direction towards the sun and turbidity, from 2 for a very clear sky to about 10 for a hazy sky.
+// Returns an error if the shader cannot be built.
+func NewProceduralSky(gs *gls.GLS, sunDir *math32.Vector3, turbidity float32) (*ProceduralSky, error) {
+
+	s := new(ProceduralSky)
+	s.gs = gs
+	s.Scale = 1
+	var err error
+	s.pass, err = newFullscreenPass(gs, "", skyFragmentSource)
+	if err != nil {
+		return nil, err
+	}
+	s.sunDir = *sunDir
+	s.sunDir.Normalize()
+	s.SetTurbidity(turbidity)
+	return s, nil
+}
+
+// SetTurbidity sets the turbidity of the atmosphere, which may change at each frame.
+func (s *ProceduralSky) SetTurbidity(turbidity float32) {
+
+	s.turbidity = math32.Max(turbidity, 1)
+	s.update()
+}
+
+// Turbidity returns the turbidity of the atmosphere.
+func (s *ProceduralSky) Turbidity() float32 {
+
+	return s.turbidity
+}
+
+// SetSunDirection sets the direction towards the sun, which may change at each frame.
+func (s *ProceduralSky) SetSunDirection(sunDir *math32.Vector3) {
+
+	s.sunDir = *sunDir
+	s.sunDir.Normalize()
+	s.update()
+}
+
+// SunDirection returns the normalized direction towards the sun.
+func (s *ProceduralSky) SunDirection() math32.Vector3 {
+
+	return s.sunDir
+}
+
+// update computes the Perez coefficients and the zenith values of the turbidity and the sun elevation.
+func (s *ProceduralSky) update() {
+
+	t := s.turbidity
+	s.perez[0] = math32.Vector3{X: 0.1787*t - 1.4630, Y: -0.0193*t - 0.2592, Z: -0.0167*t - 0.2608}
+	s.perez[1] = math32.Vector3{X: -0.3554*t + 0.4275, Y: -0.0665*t + 0.0008, Z: -0.0950*t + 0.0092}
+	s.perez[2] = math32.Vector3{X: -0.0227*t + 5.3251, Y: -0.0004*t + 0.2125, Z: -0.0079*t + 0.2102}
+	s.perez[3] = math32.Vector3{X: 0.1206*t - 2.5771, Y: -0.0641*t - 0.8989, Z: -0.0441*t - 1.6537}
+	s.perez[4] = math32.Vector3{X: -0.0670*t + 0.3703, Y: -0.0033*t + 0.0452, Z: -0.0109*t + 0.0529}
+
+	// The model is valid for the sun above the horizon
+	thetaS := math32.Acos(math32.Clamp(s.sunDir.Y, 0, 1))
+	chi := (4.0/9 - t/120) * (math32.Pi - 2*thetaS)
+	s.zenith.X = math32.Max((4.0453*t-4.9710)*math32.Tan(chi)-0.2155*t+2.4192, 0)
+	t2, th2 := t*t, thetaS*thetaS
+	th3 := th2 * thetaS
+	s.zenith.Y = t2*(0.00166*th3-0.00375*th2+0.00209*thetaS) +
+		t*(-0.02903*th3+0.06377*th2-0.03202*thetaS+0.00394) +
+		(0.11693*th3 - 0.21196*th2 + 0.06052*thetaS + 0.25886)
+	s.zenith.Z = t2*(0.00275*th3-0.00610*th2+0.00317*thetaS) +
+		t*(-0.04214*th3+0.08970*th2-0.04153*thetaS+0.00516) +
+		(0.15346*th3 - 0.26756*th2 + 0.06670*thetaS + 0.26688)
+	s.ambientOk = false
+}
+
+// perezAt returns the Perez distribution of Y, x and y for the specified cosine of the angle
+// from the zenith and angle from the sun.
+func (s *ProceduralSky) perezAt(cosTheta, gamma float32) math32.Vector3 {
+
+	cosGamma := math32.Cos(gamma)
+	f := func(a, b, c, d, e float32) float32 {
+		return (1 + a*math32.Exp(b/cosTheta)) * (1 + c*math32.Exp(d*gamma) + e*cosGamma*cosGamma)
+	}
+	p := &s.perez
+	return math32.Vector3{
+		X: f(p[0].X, p[1].X, p[2].X, p[3].X, p[4].X),
+		Y: f(p[0].Y, p[1].Y, p[2].Y, p[3].Y, p[4].Y),
+		Z: f(p[0].Z, p[1].Z, p[2].Z, p[3].Z, p[4].Z),
+	}
+}
+
+// perezSun returns the Perez distribution at the zenith, which normalizes the distribution.
+func (s *ProceduralSky) perezSun() math32.Vector3 {
+
+	return s.perezAt(1, math32.Acos(math32.Clamp(s.sunDir.Y, -1, 1)))
+}
+
+// Radiance returns the linear color of the sky in the specified normalized direction.
+func (s *ProceduralSky) Radiance(dir *math32.Vector3) math32.Color {
+
+	c := s.radiance(dir, s.perezSun())
+	return *c.MultiplyScalar(s.Scale)
+}
+
+// radiance returns the color of the sky in the specified direction with the specified Perez normalization,
+// without the Scale factor.
+func (s *ProceduralSky) radiance(dir *math32.Vector3, norm math32.Vector3) math32.Color {
+
+	cosTheta := math32.Max(dir.Y, 0.01)
+	gamma := math32.Acos(math32.Clamp(dir.Dot(&s.sunDir), -1, 1))
+	f := s.perezAt(cosTheta, gamma)
+	lum := s.zenith.X * f.X / norm.X
+	x := s.zenith.Y * f.Y / norm.Y
+	y := s.zenith.Z * f.Z / norm.Z
+	return xyYToRGB(x, y, lum)
+}
+
+// xyYToRGB returns the linear sRGB color of the specified chromaticity and luminance.
+func xyYToRGB(x, y, lum float32) math32.Color {
+
+	if y <= 0 {
+		return math32.Color{}
+	}
+	cx := x / y * lum
+	cz := (1 - x - y) / y * lum
+	return math32.Color{
+		R: math32.Max(3.2406*cx-1.5372*lum-0.4986*cz, 0),
+		G: math32.Max(-0.9689*cx+1.8758*lum+0.0415*cz, 0),
+		B: math32.Max(0.0557*cx-0.2040*lum+1.0570*cz, 0),
+	}
+}
+
+// SunColor returns the color of the sun light: the fraction of each of the red, green and blue
+// wavelengths (680, 550 and 440 nm) of the sun light outside the atmosphere which is transmitted
+// through the Rayleigh and aerosol scattering of the model along the path to the sun.
+// Returns black when the sun is below the horizon.
+func (s *ProceduralSky) SunColor() math32.Color {
+
+	if s.sunDir.Y <= 0 {
+		return math32.Color{}
+	}
+	thetaS := math32.Acos(s.sunDir.Y)
+	// Relative optical mass of the atmosphere along the path, and the Angstrom turbidity
+	mass := 1 / (s.sunDir.Y + 0.15*math32.Pow(93.885-math32.RadToDeg(thetaS), -1.253))
+	beta := 0.04608*s.turbidity - 0.04586
+	trans := func(lambda float32) float32 {
+		rayleigh := math32.Exp(-0.008735 * math32.Pow(lambda, -4.08) * mass)
+		aerosol := math32.Exp(-beta * math32.Pow(lambda, -1.3) * mass)
+		return rayleigh * aerosol
+	}
+	return math32.Color{R: trans(0.680), G: trans(0.550), B: trans(0.440)}
+}
+
+// AmbientColor returns the color of the ambient light of the sky: its irradiance on an
+// horizontal surface divided by Pi, the color of a white diffuse surface lit by the sky.
+func (s *ProceduralSky) AmbientColor() math32.Color {
+
+	if s.ambientOk {
+		c := s.ambient
+		return *c.MultiplyScalar(s.Scale)
+	}
+	norm := s.perezSun()
+	var sum math32.Color
+	var weights float32
+	for i := 0; i < skyAmbientSamples; i++ {
+		// Cosine weighted elevations: uniform in sin^2 of the angle from the zenith
+		sin2 := (float32(i) + 0.5) / skyAmbientSamples
+		cosTheta := math32.Sqrt(1 - sin2)
+		sinTheta := math32.Sqrt(sin2)
+		for j := 0; j < 2*skyAmbientSamples; j++ {
+			phi := 2 * math32.Pi * (float32(j) + 0.5) / (2 * skyAmbientSamples)
+			dir := math32.Vector3{X: sinTheta * math32.Cos(phi), Y: cosTheta, Z: sinTheta * math32.Sin(phi)}
+			c := s.radiance(&dir, norm)
+			sum.Add(&c)
+			weights++
+		}
+	}
+	s.ambient = *sum.MultiplyScalar(1 / weights)
+	s.ambientOk = true
+	return *sum.MultiplyScalar(s.Scale)
+}
+
+// setRadianceUniforms transfers the parameters of the sky to the uniforms
+// declared by skyRadianceSource of the specified current program.
+func (s *ProceduralSky) setRadianceUniforms(prog *gls.Program) {
+
+	norm := s.perezSun()
+	s.gs.Uniform3f(prog.GetUniformLocation("uSunDir"), s.sunDir.X, s.sunDir.Y, s.sunDir.Z)
+	s.gs.Uniform3f(prog.GetUniformLocation("uZenith"), s.zenith.X, s.zenith.Y, s.zenith.Z)
+	s.gs.Uniform3fv(prog.GetUniformLocation("uPerez"), 5, &s.perez[0].X)
+	s.gs.Uniform3f(prog.GetUniformLocation("uPerezSun"), norm.X, norm.Y, norm.Z)
+	s.gs.Uniform1f(prog.GetUniformLocation("uSkyScale"), s.Scale)
+}
+
+// Draw renders the sky seen from the camera of the specified rig into the specified render target,
+// or into the current framebuffer if nil, without depth test: it is the background of the scene.
+func (s *ProceduralSky) Draw(rig *camera.CameraRig, rt *texture.RenderTarget) {
+
+	var view, proj, vp, inv math32.Matrix4
+	rig.Camera().ViewMatrix(&view)
+	rig.Camera().ProjMatrix(&proj)
+	// Directions only: no translation of the camera
+	view[12], view[13], view[14] = 0, 0, 0
+	vp.MultiplyMatrices(&proj, &view)
+	inv.GetInverse(&vp)
+
+	if rt != nil {
+		rt.Bind()
+	}
+	p := s.pass
+	p.use()
+	s.gs.UniformMatrix4fv(p.uniform("uInvViewProj"), 1, false, &inv[0])
+	s.setRadianceUniforms(p.prog)
+	p.draw()
+	if rt != nil {
+		rt.Unbind()
+	}
+}
+
+// Dispose releases the OpenGL resources of this ProceduralSky.
+func (s *ProceduralSky) Dispose() {
+
+	s.pass.dispose()
+}
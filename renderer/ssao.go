@@ -0,0 +1,273 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// SSAOSampler is the name of the shader sampler of the ambient occlusion computed by an SSAO.
+const SSAOSampler = "uSSAOSampler"
+
+// ssaoNoiseSize is the width and height of the tiled texture of random rotations of the kernel.
+const ssaoNoiseSize = 4
+
+// ssaoFragmentSource is the fragment shader computing the ambient occlusion from the depth
+// and the octahedral encoded view space normals, comparing the depth of the samples of
+// a hemisphere oriented by the normal with the depth of the scene at their positions.
+const ssaoFragmentSource = `
+in vec2 vTexcoord;
+out vec4 FragColor;
+
+uniform sampler2D uDepth;
+uniform sampler2D uNormal;
+uniform sampler2D uNoise;
+uniform vec3 uKernel[KERNEL_SIZE];
+uniform mat4 uProjection;
+uniform mat4 uInvProjection;
+uniform vec2 uNoiseScale;
+uniform float uRadius;
+uniform float uBias;
+
+vec3 viewPosition(vec2 uv) {
+
+    float depth = texture(uDepth, uv).r;
+    vec4 pos = uInvProjection * vec4(vec3(uv, depth) * 2.0 - 1.0, 1.0);
+    return pos.xyz / pos.w;
+}
+
+vec3 decodeNormal(vec2 e) {
+
+    vec3 n = vec3(e, 1.0 - abs(e.x) - abs(e.y));
+    if (n.z < 0.0) {
+        n.xy = (1.0 - abs(n.yx)) * vec2(n.x >= 0.0 ? 1.0 : -1.0, n.y >= 0.0 ? 1.0 : -1.0);
+    }
+    return normalize(n);
+}
+
+void main() {
+
+    if (texture(uDepth, vTexcoord).r >= 1.0) {
+        FragColor = vec4(1.0);
+        return;
+    }
+    vec3 pos = viewPosition(vTexcoord);
+    vec3 normal = decodeNormal(texture(uNormal, vTexcoord).rg);
+    vec3 rnd = texture(uNoise, vTexcoord * uNoiseScale).xyz;
+    vec3 tangent = normalize(rnd - normal * dot(rnd, normal));
+    mat3 tbn = mat3(tangent, cross(normal, tangent), normal);
+
+    float occlusion = 0.0;
+    for (int i = 0; i < KERNEL_SIZE; i++) {
+        vec3 s = pos + tbn * uKernel[i] * uRadius;
+        vec4 offset = uProjection * vec4(s, 1.0);
+        vec2 uv = offset.xy / offset.w * 0.5 + 0.5;
+        float sceneZ = viewPosition(uv).z;
+        float rangeCheck = smoothstep(0.0, 1.0, uRadius / abs(pos.z - sceneZ));
+        occlusion += (sceneZ >= s.z + uBias ? 1.0 : 0.0) * rangeCheck;
+    }
+    FragColor = vec4(vec3(1.0 - occlusion / float(KERNEL_SIZE)), 1.0);
+}
+`
+
+// ssaoBlurFragmentSource is the fragment shader of the bilateral blur of the ambient occlusion
+// over the size of the noise texture, weighting the texels by the difference of their depth.
+const ssaoBlurFragmentSource = `
+in vec2 vTexcoord;
+out vec4 FragColor;
+
+uniform sampler2D uInput;
+uniform sampler2D uDepth;
+uniform mat4 uInvProjection;
+uniform float uRadius;
+
+float viewDepth(vec2 uv) {
+
+    vec4 pos = uInvProjection * vec4(vec3(uv, texture(uDepth, uv).r) * 2.0 - 1.0, 1.0);
+    return pos.z / pos.w;
+}
+
+void main() {
+
+    vec2 texel = 1.0 / vec2(textureSize(uInput, 0));
+    float z = viewDepth(vTexcoord);
+    float sum = 0.0;
+    float weights = 0.0;
+    for (int y = -NOISE_SIZE / 2; y < NOISE_SIZE / 2; y++) {
+        for (int x = -NOISE_SIZE / 2; x < NOISE_SIZE / 2; x++) {
+            vec2 uv = vTexcoord + (vec2(x, y) + 0.5) * texel;
+            float w = max(0.0, 1.0 - abs(viewDepth(uv) - z) / uRadius);
+            sum += texture(uInput, uv).r * w;
+            weights += w;
+        }
+    }
+    FragColor = vec4(vec3(weights > 0.0 ? sum / weights : 1.0), 1.0);
+}
+`
+
+// SSAO is a screen space ambient occlusion pass: it computes the occlusion of the ambient light
+// of each pixel from the depth and the view space normals of the scene, into a texture to
+// multiply by the ambient lighting.
+type SSAO struct {
+	gs       *gls.GLS
+	Radius   float32 // radius of the sampled hemisphere in view space units
+	Bias     float32 // depth difference ignored to avoid the self occlusion of flat surfaces
+	kernel   []math32.Vector3
+	noise    *texture.Texture2D
+	raw      *texture.RenderTarget // occlusion computed by Compute
+	blurred  *texture.RenderTarget // occlusion blurred by Blur
+	depth    *texture.Texture2D    // depth of the last Compute
+	invProj  math32.Matrix4        // inverse projection of the last Compute
+	ssaoPass *fullscreenPass
+	blurPass *fullscreenPass
+}
+
+// NewSSAO creates and returns a pointer to a new SSAO with the specified size of its textures,
+// number of samples, radius and bias. The sample kernel and the noise are generated
+// with the specified random number generator.
+// Returns an error if the kernel size is not positive or the shaders or render targets cannot be created.
+func NewSSAO(gs *gls.GLS, width, height, kernelSize int, radius, bias float32, rng *rand.Rand) (*SSAO, error) {
+
+	if kernelSize < 1 {
+		return nil, fmt.Errorf("invalid SSAO kernel size:%d", kernelSize)
+	}
+	s := new(SSAO)
+	s.gs = gs
+	s.Radius = radius
+	s.Bias = bias
+
+	// Samples in the hemisphere around +Z, more of them near the center
+	s.kernel = make([]math32.Vector3, kernelSize)
+	for i := range s.kernel {
+		v := math32.Vector3{X: rng.Float32()*2 - 1, Y: rng.Float32()*2 - 1, Z: rng.Float32()}
+		v.Normalize()
+		t := float32(i) / float32(kernelSize)
+		v.MultiplyScalar(rng.Float32() * (0.1 + 0.9*t*t))
+		s.kernel[i] = v
+	}
+
+	// Random rotations around the normal, tiled over the screen
+	noise := make([]float32, 3*ssaoNoiseSize*ssaoNoiseSize)
+	for i := 0; i < len(noise); i += 3 {
+		noise[i] = rng.Float32()*2 - 1
+		noise[i+1] = rng.Float32()*2 - 1
+	}
+	s.noise = texture.NewTexture2DFromData(ssaoNoiseSize, ssaoNoiseSize, gls.RGB, gls.FLOAT, gls.RGB16F, noise)
+	s.noise.SetGenerateMipmap(false)
+	s.noise.SetFilter(texture.FilterNearest, texture.FilterNearest)
+	s.noise.SetWrap(texture.WrapRepeat, texture.WrapRepeat)
+
+	var err error
+	s.ssaoPass, err = newFullscreenPass(gs, fmt.Sprintf("#define KERNEL_SIZE %d\n", kernelSize), ssaoFragmentSource)
+	if err != nil {
+		s.Dispose()
+		return nil, err
+	}
+	s.blurPass, err = newFullscreenPass(gs, fmt.Sprintf("#define NOISE_SIZE %d\n", ssaoNoiseSize), ssaoBlurFragmentSource)
+	if err != nil {
+		s.Dispose()
+		return nil, err
+	}
+	s.raw, err = texture.NewRenderTarget(gs, width, height, texture.RenderTargetOpts{ColorFormat: gls.RGB8})
+	if err != nil {
+		s.Dispose()
+		return nil, err
+	}
+	s.blurred, err = texture.NewRenderTarget(gs, width, height, texture.RenderTargetOpts{ColorFormat: gls.RGB8})
+	if err != nil {
+		s.Dispose()
+		return nil, err
+	}
+	s.blurred.ColorTexture().SetUniformNames(SSAOSampler, "uSSAOTexParams")
+	return s, nil
+}
+
+// Kernel returns the sample offsets in the unit hemisphere around +Z.
+func (s *SSAO) Kernel() []math32.Vector3 {
+
+	return s.kernel
+}
+
+// Compute renders the ambient occlusion of the scene with the specified depth texture,
+// view space normals octahedral encoded in the RG components of the normal texture as in
+// math32.GBuffer, and projection matrix. Returns the texture with the occlusion factor,
+// 1 for no occlusion, noisy until blurred by Blur.
+func (s *SSAO) Compute(depth, normal *texture.Texture2D, proj *math32.Matrix4) *texture.Texture2D {
+
+	s.depth = depth
+	s.invProj.GetInverse(proj)
+	p := s.ssaoPass
+	s.raw.Bind()
+	p.use()
+	p.bindTexture("uDepth", depth, 0)
+	p.bindTexture("uNormal", normal, 1)
+	p.bindTexture("uNoise", s.noise, 2)
+	s.gs.Uniform3fv(p.uniform("uKernel"), int32(len(s.kernel)), &s.kernel[0].X)
+	s.gs.UniformMatrix4fv(p.uniform("uProjection"), 1, false, &proj[0])
+	s.gs.UniformMatrix4fv(p.uniform("uInvProjection"), 1, false, &s.invProj[0])
+	s.gs.Uniform2f(p.uniform("uNoiseScale"), float32(s.raw.Width())/ssaoNoiseSize, float32(s.raw.Height())/ssaoNoiseSize)
+	s.gs.Uniform1f(p.uniform("uRadius"), s.Radius)
+	s.gs.Uniform1f(p.uniform("uBias"), s.Bias)
+	p.draw()
+	s.raw.Unbind()
+	return s.raw.ColorTexture()
+}
+
+// Blur applies a bilateral blur, preserving the edges between different depths, to the specified
+// occlusion texture computed by Compute and returns the texture with the result, which is the
+// texture returned by Texture.
+func (s *SSAO) Blur(raw *texture.Texture2D) *texture.Texture2D {
+
+	p := s.blurPass
+	s.blurred.Bind()
+	p.use()
+	p.bindTexture("uInput", raw, 0)
+	p.bindTexture("uDepth", s.depth, 1)
+	s.gs.UniformMatrix4fv(p.uniform("uInvProjection"), 1, false, &s.invProj[0])
+	s.gs.Uniform1f(p.uniform("uRadius"), s.Radius)
+	p.draw()
+	s.blurred.Unbind()
+	return s.blurred.ColorTexture()
+}
+
+// Texture returns the texture with the blurred ambient occlusion, bound to the shader
+// sampler SSAOSampler, to add to the materials of the lighting pass.
+func (s *SSAO) Texture() *texture.Texture2D {
+
+	return s.blurred.ColorTexture()
+}
+
+// Resize reallocates the textures of the occlusion with the specified size.
+func (s *SSAO) Resize(width, height int) error {
+
+	err := s.raw.Resize(width, height)
+	if err != nil {
+		return err
+	}
+	return s.blurred.Resize(width, height)
+}
+
+// Dispose releases the OpenGL resources of this SSAO.
+func (s *SSAO) Dispose() {
+
+	if s.ssaoPass != nil {
+		s.ssaoPass.dispose()
+	}
+	if s.blurPass != nil {
+		s.blurPass.dispose()
+	}
+	if s.raw != nil {
+		s.raw.Dispose()
+	}
+	if s.blurred != nil {
+		s.blurred.Dispose()
+	}
+	s.noise.Dispose()
+}
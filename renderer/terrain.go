@@ -0,0 +1,452 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/light"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// TerrainMaxLayers is the maximum number of texture layers of a TerrainRenderer.
+const TerrainMaxLayers = 8
+
+// terrainChunkCells is the number of cells along X and Z of the chunks of the terrain.
+const terrainChunkCells = 32
+
+// terrainLODLevels is the number of levels of detail of the chunks,
+// each using one sample every two samples of the previous level.
+const terrainLODLevels = 4
+
+// terrainVertexSource is the vertex shader of the terrain, whose vertex positions are in world coordinates.
+const terrainVertexSource = `
+in vec3 VertexPosition;
+in vec3 VertexNormal;
+
+uniform mat4 uViewProj;
+uniform mat4 uView;
+
+out vec3 vPosition;
+out vec3 vNormal;
+out float vViewDepth;
+
+void main() {
+
+    vPosition = VertexPosition;
+    vNormal = VertexNormal;
+    vViewDepth = -(uView * vec4(VertexPosition, 1.0)).z;
+    gl_Position = uViewProj * vec4(VertexPosition, 1.0);
+}
+`
+
+// terrainFragmentSource is the fragment shader of the terrain blending the layers of the texture
+// arrays weighted by the height and slope of the fragment, lit by a directional light with
+// cascaded shadows when CASCADES is not zero. SHADOW_DEPTH is replaced by the sampling of the
+// depth map of each cascade, since the sampler arrays require constant indices.
+const terrainFragmentSource = `
+in vec3 vPosition;
+in vec3 vNormal;
+in float vViewDepth;
+out vec4 FragColor;
+
+uniform sampler2DArray uDiffuse;
+#ifdef NORMAL_MAPS
+uniform sampler2DArray uNormals;
+#endif
+uniform int uLayerCount;
+uniform vec4 uLayerRanges[MAX_LAYERS]; // min and max height, min and max slope
+uniform float uTiling[MAX_LAYERS];
+uniform vec2 uBlend;                   // width of the height and slope transitions
+uniform vec3 uLightDir;
+uniform vec3 uLightColor;
+uniform vec3 uAmbient;
+
+#if CASCADES > 0
+uniform sampler2D uShadowMap[CASCADES];
+uniform mat4 ShadowMatrix[CASCADES];
+uniform float ShadowSplits[CASCADES];
+uniform float uShadowBias;
+
+float shadowDepth(int c, vec2 uv) {
+
+SHADOW_DEPTH
+    return 1.0;
+}
+
+float shadow() {
+
+    if (vViewDepth > ShadowSplits[CASCADES - 1]) {
+        return 1.0;
+    }
+    int c = CASCADES - 1;
+    for (int i = CASCADES - 1; i >= 0; i--) {
+        if (vViewDepth <= ShadowSplits[i]) {
+            c = i;
+        }
+    }
+    vec4 p = ShadowMatrix[c] * vec4(vPosition, 1.0);
+    vec3 sc = p.xyz / p.w;
+    vec2 texel = 1.0 / vec2(textureSize(uShadowMap[0], 0));
+    float lit = 0.0;
+    for (int y = -1; y <= 1; y++) {
+        for (int x = -1; x <= 1; x++) {
+            lit += sc.z - uShadowBias <= shadowDepth(c, sc.xy + vec2(x, y) * texel) ? 1.0 : 0.0;
+        }
+    }
+    return lit / 9.0;
+}
+#endif
+
+float band(float v, vec2 range, float blend) {
+
+    return smoothstep(range.x - blend, range.x, v) * (1.0 - smoothstep(range.y, range.y + blend, v));
+}
+
+void main() {
+
+    vec3 n = normalize(vNormal);
+    float slope = degrees(acos(clamp(n.y, 0.0, 1.0)));
+    vec3 color = vec3(0.0);
+    vec3 tn = vec3(0.0);
+    float weights = 0.0;
+    for (int i = 0; i < uLayerCount; i++) {
+        vec4 r = uLayerRanges[i];
+        float w = r.xy == vec2(0.0) ? 1.0 : band(vPosition.y, r.xy, uBlend.x);
+        w *= band(slope, r.zw, uBlend.y);
+        vec3 uv = vec3(vPosition.xz * uTiling[i], float(i));
+        color += texture(uDiffuse, uv).rgb * w;
+#ifdef NORMAL_MAPS
+        tn += (texture(uNormals, uv).xyz * 2.0 - 1.0) * w;
+#endif
+        weights += w;
+    }
+    // The first layer where no layer applies
+    if (weights <= 0.0) {
+        vec3 uv = vec3(vPosition.xz * uTiling[0], 0.0);
+        color = texture(uDiffuse, uv).rgb;
+#ifdef NORMAL_MAPS
+        tn = texture(uNormals, uv).xyz * 2.0 - 1.0;
+#endif
+        weights = 1.0;
+    }
+    color /= weights;
+#ifdef NORMAL_MAPS
+    // Tangent space of the projection of the textures on the XZ plane
+    vec3 t = normalize(vec3(1.0, 0.0, 0.0) - n * n.x);
+    n = normalize(mat3(t, cross(t, n), n) * normalize(tn));
+#endif
+
+    float lit = 1.0;
+#if CASCADES > 0
+    lit = shadow();
+#endif
+    float ndotl = max(dot(n, uLightDir), 0.0);
+    FragColor = vec4(color * (uAmbient + uLightColor * ndotl * lit), 1.0);
+}
+`
+
+// TerrainLayer is a texture layer of a terrain, applied where the height and the slope
+// of the terrain are inside its ranges.
+type TerrainLayer struct {
+	Texture     *texture.Texture2D
+	NormalMap   *texture.Texture2D // tangent space normal map, for all layers or none
+	TilingScale float32            // repetitions of the textures per world unit
+	HeightRange math32.Interval    // heights of the layer, all heights if zero
+	SlopeRange  math32.Interval    // slopes of the layer in degrees, from 0 for horizontal to 90
+}
+
+// terrainChunk is a square part of the terrain, culled and with its level of detail selected separately.
+type terrainChunk struct {
+	box   math32.Box3
+	lod   *graphic.LODGroup
+	grass *graphic.InstancedRenderer // nil without grass
+}
+
+// TerrainRenderer renders a height map with textures blended by height and slope, lit by a
+// directional light with optional cascaded shadows. The terrain is split into chunks drawn only
+// when inside the view frustum, with a level of detail selected by their size on the screen.
+// Grass may be drawn with instancing on the visible chunks at the most detailed level.
+type TerrainRenderer struct {
+	gs           *gls.GLS
+	LightDir     math32.Vector3 // normalized direction towards the light
+	LightColor   math32.Color
+	AmbientColor math32.Color
+	HeightBlend  float32 // width of the transitions between the height ranges of the layers
+	SlopeBlend   float32 // width in degrees of the transitions between the slope ranges of the layers
+	ShadowBias   float32 // depth offset of the shadow tests avoiding self shadowing
+	hm           *geometry.HeightMap
+	layers       []TerrainLayer
+	diffuse      *texture.Texture2DArray
+	normals      *texture.Texture2DArray // nil without normal maps
+	chunks       []terrainChunk
+	shaders      map[int]*gls.Shader // shader for each number of shadow cascades
+	grassShader  *gls.Shader
+}
+
+// NewTerrainRenderer creates and returns a pointer to a new TerrainRenderer of the specified height map
+// with the specified texture layers. The meshes of the levels of detail of the chunks and the texture
+// arrays of the layers are created immediately.
+// Returns an error if the number of layers is invalid, if only some layers have normal maps,
+// if the textures cannot be combined into texture arrays or if the shader cannot be built.
+func NewTerrainRenderer(gs *gls.GLS, hm *geometry.HeightMap, layers []TerrainLayer) (*TerrainRenderer, error) {
+
+	if len(layers) == 0 || len(layers) > TerrainMaxLayers {
+		return nil, fmt.Errorf("invalid number of terrain layers:%d", len(layers))
+	}
+	tr := new(TerrainRenderer)
+	tr.gs = gs
+	tr.hm = hm
+	tr.layers = layers
+	tr.LightDir = math32.Vector3{X: 0, Y: 1, Z: 0}
+	tr.LightColor = math32.Color{R: 1, G: 1, B: 1}
+	tr.AmbientColor = math32.Color{R: 0.2, G: 0.2, B: 0.2}
+	tr.HeightBlend = 1
+	tr.SlopeBlend = 5
+	tr.ShadowBias = 0.002
+	tr.shaders = make(map[int]*gls.Shader)
+
+	diffuse := make([]*texture.Texture2D, len(layers))
+	var normals []*texture.Texture2D
+	for i := range layers {
+		diffuse[i] = layers[i].Texture
+		if layers[i].NormalMap != nil {
+			normals = append(normals, layers[i].NormalMap)
+		}
+	}
+	if len(normals) != 0 && len(normals) != len(layers) {
+		return nil, fmt.Errorf("terrain layers without normal maps:%d", len(layers)-len(normals))
+	}
+	var err error
+	tr.diffuse, err = texture.NewTexture2DArray(diffuse)
+	if err != nil {
+		return nil, err
+	}
+	if len(normals) != 0 {
+		tr.normals, err = texture.NewTexture2DArray(normals)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, err = tr.shader(0); err != nil {
+		return nil, err
+	}
+
+	// Chunks with their levels of detail, the coverage threshold halving with the resolution
+	width, height := hm.Dimensions()
+	for j := 0; j < height-1; j += terrainChunkCells {
+		for i := 0; i < width-1; i += terrainChunkCells {
+			var c terrainChunk
+			for level := 0; level < terrainLODLevels; level++ {
+				step := 1 << uint(level)
+				if level > 0 && step > terrainChunkCells {
+					break
+				}
+				skirt := float32(step) * hm.CellSize()
+				geom := hm.RegionMesh(i, j, terrainChunkCells, terrainChunkCells, step, skirt)
+				if level == 0 {
+					// Geometry.BoundingBox includes the origin
+					first := true
+					geom.ReadVertices(func(v math32.Vector3) bool {
+						if first {
+							c.box.Set(&v, &v)
+							first = false
+						} else {
+							c.box.ExpandByPoint(&v)
+						}
+						return false
+					})
+					var center, size math32.Vector3
+					c.box.Center(&center)
+					c.box.Size(&size)
+					c.lod = graphic.NewLODGroup(&center)
+					c.lod.Radius = size.Length() / 2
+				}
+				coverage := 1 / float32(step)
+				if level == terrainLODLevels-1 {
+					coverage = 0
+				}
+				c.lod.AddLevel(graphic.NewMesh(geom, nil), coverage, 0)
+			}
+			tr.chunks = append(tr.chunks, c)
+		}
+	}
+	return tr, nil
+}
+
+// shader returns the terrain shader for the specified number of shadow cascades, building it the first time.
+func (tr *TerrainRenderer) shader(cascades int) (*gls.Shader, error) {
+
+	if s, ok := tr.shaders[cascades]; ok {
+		return s, nil
+	}
+	prefix := fmt.Sprintf("#version %s\n#define MAX_LAYERS %d\n#define CASCADES %d\n", GLSL_VERSION, TerrainMaxLayers, cascades)
+	if tr.normals != nil {
+		prefix += "#define NORMAL_MAPS\n"
+	}
+	var depth strings.Builder
+	for c := 0; c < cascades; c++ {
+		fmt.Fprintf(&depth, "    if (c == %d) return textureLod(uShadowMap[%d], uv, 0.0).r;\n", c, c)
+	}
+	frag := strings.Replace(terrainFragmentSource, "SHADOW_DEPTH\n", depth.String(), 1)
+	s, err := tr.gs.NewShader(prefix+terrainVertexSource, prefix+frag)
+	if err != nil {
+		return nil, err
+	}
+	tr.shaders[cascades] = s
+	return s, nil
+}
+
+// SetGrass distributes the specified number of instances of the specified blade geometry randomly
+// over each chunk of the terrain where the slope in degrees is inside the specified range, with
+// random rotations around the vertical and scales from 0.8 to 1.2. The grass is drawn on the visible
+// chunks at the most detailed level with the specified shader, which must declare the instance
+// attributes of graphic.InstancedRenderer and a ViewProjMatrix uniform.
+func (tr *TerrainRenderer) SetGrass(blade *geometry.Geometry, shader *gls.Shader, perChunk int, slopeRange math32.Interval, rng *rand.Rand) {
+
+	tr.grassShader = shader
+	chunkSize := terrainChunkCells * tr.hm.CellSize()
+	for i := range tr.chunks {
+		c := &tr.chunks[i]
+		if c.grass != nil {
+			c.grass.Dispose()
+		}
+		var transforms []math32.Matrix4
+		for k := 0; k < perChunk; k++ {
+			x := c.box.Min.X + rng.Float32()*chunkSize
+			z := c.box.Min.Z + rng.Float32()*chunkSize
+			if !tr.hm.Contains(x, z) {
+				continue
+			}
+			n := tr.hm.NormalAt(x, z)
+			if !slopeRange.Contains(math32.RadToDeg(math32.Acos(math32.Clamp(n.Y, 0, 1)))) {
+				continue
+			}
+			var m, rot math32.Matrix4
+			scale := 0.8 + 0.4*rng.Float32()
+			m.MakeScale(scale, scale, scale)
+			rot.MakeRotationY(rng.Float32() * 2 * math32.Pi)
+			m.MultiplyMatrices(&rot, &m)
+			m[12], m[13], m[14] = x, tr.hm.HeightAt(x, z), z
+			transforms = append(transforms, m)
+		}
+		c.grass = graphic.NewInstancedRenderer(blade, len(transforms))
+		c.grass.SetInstances(transforms, nil)
+	}
+}
+
+// Draw renders the chunks of the terrain inside the view frustum of the camera of the specified rig
+// into the current framebuffer, with the shadows of the specified shadow map if not nil,
+// whose cascades must have been updated for that camera.
+func (tr *TerrainRenderer) Draw(rig *camera.CameraRig, shadow *light.ShadowMap) {
+
+	cascades := 0
+	if shadow != nil {
+		cascades = shadow.Cascades()
+	}
+	s, err := tr.shader(cascades)
+	if err != nil {
+		log.Error("terrain shader: %v", err)
+		return
+	}
+	gs := tr.gs
+	cam := rig.Camera()
+	var view, proj, vp math32.Matrix4
+	cam.ViewMatrix(&view)
+	cam.ProjMatrix(&proj)
+	vp.MultiplyMatrices(&proj, &view)
+	var frustum math32.Frustum
+	frustum.SetFromMatrix(&vp)
+
+	s.Use()
+	prog := s.Program()
+	s.SetUniformMat4("uViewProj", &vp)
+	s.SetUniformMat4("uView", &view)
+	s.SetUniformVec3("uLightDir", &tr.LightDir)
+	gs.Uniform3f(prog.GetUniformLocation("uLightColor"), tr.LightColor.R, tr.LightColor.G, tr.LightColor.B)
+	gs.Uniform3f(prog.GetUniformLocation("uAmbient"), tr.AmbientColor.R, tr.AmbientColor.G, tr.AmbientColor.B)
+	gs.Uniform2f(prog.GetUniformLocation("uBlend"), tr.HeightBlend, tr.SlopeBlend)
+	s.SetUniformInt("uLayerCount", int32(len(tr.layers)))
+	ranges := make([]float32, 0, 4*len(tr.layers))
+	tiling := make([]float32, len(tr.layers))
+	for i := range tr.layers {
+		l := &tr.layers[i]
+		ranges = append(ranges, l.HeightRange.Min, l.HeightRange.Max, l.SlopeRange.Min, l.SlopeRange.Max)
+		tiling[i] = l.TilingScale
+	}
+	gs.Uniform4fv(prog.GetUniformLocation("uLayerRanges"), int32(len(tr.layers)), ranges)
+	gs.Uniform1fv(prog.GetUniformLocation("uTiling"), int32(len(tr.layers)), tiling)
+	tr.diffuse.Bind(gs, 0)
+	gs.Uniform1i(prog.GetUniformLocation("uDiffuse"), 0)
+	if tr.normals != nil {
+		tr.normals.Bind(gs, 1)
+		gs.Uniform1i(prog.GetUniformLocation("uNormals"), 1)
+	}
+	if shadow != nil {
+		for c := 0; c < cascades; c++ {
+			shadow.DepthTexture(c).Bind(gs, 2+c)
+			gs.Uniform1i(prog.GetUniformLocation(fmt.Sprintf("%s[%d]", light.ShadowSampler, c)), int32(2+c))
+		}
+		shadow.RenderSetup(gs)
+		s.SetUniformFloat("uShadowBias", tr.ShadowBias)
+	}
+
+	gs.Enable(gls.DEPTH_TEST)
+	gs.DepthMask(true)
+	gs.Disable(gls.BLEND)
+	gs.Disable(gls.CULL_FACE)
+	var grass []*graphic.InstancedRenderer
+	for i := range tr.chunks {
+		c := &tr.chunks[i]
+		if !frustum.IntersectsBox(&c.box) {
+			continue
+		}
+		mesh := c.lod.Select(cam)
+		if mesh == nil {
+			continue
+		}
+		geom := mesh.GetGeometry()
+		geom.RenderSetup(gs)
+		gs.DrawElements(gls.TRIANGLES, int32(len(geom.Indices())), gls.UNSIGNED_INT, 0)
+		if c.grass != nil && c.lod.Level() == 0 {
+			grass = append(grass, c.grass)
+		}
+	}
+
+	if len(grass) > 0 {
+		tr.grassShader.SetUniformMat4("ViewProjMatrix", &vp)
+		for _, ir := range grass {
+			ir.Draw(tr.grassShader)
+		}
+	}
+}
+
+// Dispose releases the OpenGL resources of this TerrainRenderer. The textures of the layers,
+// the blade geometry and the grass shader are not disposed.
+func (tr *TerrainRenderer) Dispose() {
+
+	for _, s := range tr.shaders {
+		tr.gs.DeleteProgram(s.Program().Handle())
+	}
+	tr.diffuse.Dispose()
+	if tr.normals != nil {
+		tr.normals.Dispose()
+	}
+	for i := range tr.chunks {
+		for _, level := range tr.chunks[i].lod.Levels {
+			level.Mesh.GetGeometry().Dispose()
+		}
+		if tr.chunks[i].grass != nil {
+			tr.chunks[i].grass.Dispose()
+		}
+	}
+}
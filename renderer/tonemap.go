@@ -0,0 +1,190 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"math"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/texture"
+)
+
+// ToneMapOperator is the curve used by a ToneMapper to compress the HDR colors.
+type ToneMapOperator int
+
+// The tone mapping operators, with the same values in the shader.
+const (
+	ToneMapReinhard         = ToneMapOperator(iota) // c / (1 + c)
+	ToneMapReinhardExtended                         // Reinhard on the luminance mapping the maximum luminance to white
+	ToneMapUncharted2                               // filmic curve of John Hable for Uncharted 2
+	ToneMapACES                                     // fit of the ACES reference rendering and output transforms
+)
+
+// toneMapFragmentSource is the fragment shader converting the exposed HDR colors to display colors
+// with the operator selected by uOperator, gamma correction and dithering.
+const toneMapFragmentSource = `
+in vec2 vTexcoord;
+out vec4 FragColor;
+
+uniform sampler2D uInput;
+uniform int uOperator;
+uniform float uExposure;
+uniform float uParam;
+uniform float uInvGamma;
+uniform int uDither;
+
+const vec3 lumCoef = vec3(0.2126, 0.7152, 0.0722);
+
+vec3 uncharted2(vec3 x) {
+
+    const float A = 0.15, B = 0.50, C = 0.10, D = 0.20, E = 0.02, F = 0.30;
+    return ((x * (A * x + C * B) + D * E) / (x * (A * x + B) + D * F)) - E / F;
+}
+
+vec3 aces(vec3 c) {
+
+    // sRGB to the RRT input space, and the output space to sRGB (Stephen Hill)
+    const mat3 inMat = mat3(0.59719, 0.07600, 0.02840, 0.35458, 0.90834, 0.13383, 0.04823, 0.01566, 0.83777);
+    const mat3 outMat = mat3(1.60475, -0.10208, -0.00327, -0.53108, 1.10813, -0.07276, -0.07367, -0.00605, 1.07602);
+    c = inMat * c;
+    vec3 a = c * (c + 0.0245786) - 0.000090537;
+    vec3 b = c * (0.983729 * c + 0.4329510) + 0.238081;
+    return outMat * (a / b);
+}
+
+void main() {
+
+    vec3 c = texture(uInput, vTexcoord).rgb * uExposure;
+    if (uOperator == 0) {
+        c = c / (1.0 + c);
+    } else if (uOperator == 1) {
+        float l = dot(c, lumCoef);
+        float ln = l * (1.0 + l / (uParam * uParam)) / (1.0 + l);
+        c *= ln / max(l, 1e-6);
+    } else if (uOperator == 2) {
+        const float W = 11.2;
+        c = uncharted2(c * uParam) / uncharted2(vec3(W));
+    } else {
+        c = aces(c);
+    }
+    c = pow(clamp(c, 0.0, 1.0), vec3(uInvGamma));
+    if (uDither != 0) {
+        // Triangular noise of one 8 bit step, different for each pixel
+        vec2 p = gl_FragCoord.xy;
+        float r1 = fract(sin(dot(p, vec2(12.9898, 78.233))) * 43758.5453);
+        float r2 = fract(sin(dot(p, vec2(39.3468, 11.1351))) * 24634.6345);
+        c += (r1 + r2 - 1.0) / 255.0;
+    }
+    FragColor = vec4(c, 1.0);
+}
+`
+
+// ToneMapper is the final pass of a HDR rendering: it maps the exposed linear HDR colors of an image
+// to display colors in [0,1] with a tone mapping operator, and applies the gamma correction.
+type ToneMapper struct {
+	gs            *gls.GLS
+	ExposureValue float32 // EV100 exposure value: the exposure of the colors is 1/(1.2*2^EV)
+	Gamma         float32 // gamma of the display, 2.2 by default
+	Dither        bool    // adds a noise of one 8 bit step to reduce the banding of the gradients
+	operator      ToneMapOperator
+	param         float32 // maximum luminance or exposure bias of the operator
+	pass          *fullscreenPass
+}
+
+// NewToneMapper creates and returns a pointer to a new ToneMapper with the ACES operator,
+// an exposure value of 0 and a gamma of 2.2.
+// Returns an error if the shader cannot be built.
+func NewToneMapper(gs *gls.GLS) (*ToneMapper, error) {
+
+	tm := new(ToneMapper)
+	tm.gs = gs
+	tm.Gamma = 2.2
+	tm.operator = ToneMapACES
+	var err error
+	tm.pass, err = newFullscreenPass(gs, "", toneMapFragmentSource)
+	if err != nil {
+		return nil, err
+	}
+	return tm, nil
+}
+
+// Reinhard selects the Reinhard operator, c/(1+c) for each component.
+func (tm *ToneMapper) Reinhard() {
+
+	tm.operator = ToneMapReinhard
+}
+
+// ReinhardExtended selects the extended Reinhard operator applied to the luminance,
+// which maps the specified luminance to white.
+func (tm *ToneMapper) ReinhardExtended(maxLuminance float32) {
+
+	tm.operator = ToneMapReinhardExtended
+	tm.param = maxLuminance
+}
+
+// Uncharted2 selects the filmic operator of Uncharted 2, applied to the colors
+// multiplied by the specified exposure bias, usually 2.
+func (tm *ToneMapper) Uncharted2(exposureBias float32) {
+
+	tm.operator = ToneMapUncharted2
+	tm.param = exposureBias
+}
+
+// ACES selects the operator fitted to the reference rendering and output device transforms
+// of the Academy Color Encoding System.
+func (tm *ToneMapper) ACES() {
+
+	tm.operator = ToneMapACES
+}
+
+// Operator returns the current tone mapping operator.
+func (tm *ToneMapper) Operator() ToneMapOperator {
+
+	return tm.operator
+}
+
+// AutoExposure sets and returns the EV100 exposure value of a scene with the specified
+// average luminance, with the exposure formula of the reflected light meters
+// EV100 = log2(L * S / K), with a sensitivity S of 100 and a calibration constant K of 12.5.
+func (tm *ToneMapper) AutoExposure(averageLuminance float32) float32 {
+
+	const minLuminance = 1e-5
+	if averageLuminance < minLuminance {
+		averageLuminance = minLuminance
+	}
+	tm.ExposureValue = float32(math.Log2(float64(averageLuminance) * 100 / 12.5))
+	return tm.ExposureValue
+}
+
+// Exposure returns the factor of the HDR colors for the current exposure value:
+// the inverse of the maximum luminance 1.2*2^EV100 of a camera with the standard output sensitivity.
+func (tm *ToneMapper) Exposure() float32 {
+
+	return float32(1 / (1.2 * math.Exp2(float64(tm.ExposureValue))))
+}
+
+// Apply renders the tone mapped colors of the specified HDR texture into the current framebuffer.
+func (tm *ToneMapper) Apply(hdr *texture.Texture2D) {
+
+	p := tm.pass
+	p.use()
+	p.bindTexture("uInput", hdr, 0)
+	tm.gs.Uniform1i(p.uniform("uOperator"), int32(tm.operator))
+	tm.gs.Uniform1f(p.uniform("uExposure"), tm.Exposure())
+	tm.gs.Uniform1f(p.uniform("uParam"), tm.param)
+	tm.gs.Uniform1f(p.uniform("uInvGamma"), 1/tm.Gamma)
+	var dither int32
+	if tm.Dither {
+		dither = 1
+	}
+	tm.gs.Uniform1i(p.uniform("uDither"), dither)
+	p.draw()
+}
+
+// Dispose releases the OpenGL resources of this ToneMapper.
+func (tm *ToneMapper) Dispose() {
+
+	tm.pass.dispose()
+}
@@ -0,0 +1,383 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"math/rand"
+
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// waterGravity is the gravitational acceleration of the dispersion relation of the waves.
+const waterGravity = 9.81
+
+// waterVertexSource is the vertex shader of the water surface displacing the vertices
+// of a horizontal grid by the displacement map.
+const waterVertexSource = `
+in vec3 VertexPosition;
+
+uniform mat4 uViewProj;
+uniform vec3 uOrigin;
+uniform float uPatchSize;
+uniform float uChoppiness;
+uniform sampler2D uDisplacement;
+
+out vec3 vPosition;
+out vec2 vTexcoord;
+out float vHeight;
+
+void main() {
+
+    vTexcoord = VertexPosition.xz / uPatchSize;
+    vec3 d = textureLod(uDisplacement, vTexcoord, 0.0).xyz;
+    vPosition = uOrigin + VertexPosition + vec3(d.x * uChoppiness, d.y, d.z * uChoppiness);
+    vHeight = d.y;
+    gl_Position = uViewProj * vec4(vPosition, 1.0);
+}
+`
+
+// waterFragmentSource is the fragment shader of the water surface: the reflection of the sky weighted
+// by the Fresnel factor over the water color with the light scattered under the crests facing the sun,
+// the highlight of the sun and the foam.
+const waterFragmentSource = skyRadianceSource + `
+in vec3 vPosition;
+in vec2 vTexcoord;
+in float vHeight;
+out vec4 FragColor;
+
+uniform sampler2D uNormalFoam;
+uniform vec3 uCamPos;
+uniform vec3 uWaterColor;
+uniform vec3 uScatterColor;
+uniform vec3 uSunColor;
+uniform vec3 uAmbient;
+uniform float uAmplitude;
+
+void main() {
+
+    vec4 nf = texture(uNormalFoam, vTexcoord);
+    vec3 n = normalize(nf.xyz);
+    vec3 v = normalize(uCamPos - vPosition);
+    float ndotv = max(dot(n, v), 0.0);
+
+    // Schlick approximation of the Fresnel reflectance of water
+    float fresnel = 0.02 + 0.98 * pow(1.0 - ndotv, 5.0);
+    vec3 r = reflect(-v, n);
+    r.y = abs(r.y);
+    vec3 reflection = skyRadiance(r);
+
+    // Light scattered through the crests of the waves seen against the sun
+    float crest = clamp(0.5 + 0.5 * vHeight / max(uAmplitude, 1e-4), 0.0, 1.0);
+    float sss = pow(max(dot(v, -uSunDir), 0.0), 4.0) * crest;
+    vec3 water = uWaterColor * uAmbient + uScatterColor * uSunColor * sss;
+
+    vec3 h = normalize(v + uSunDir);
+    vec3 spec = uSunColor * pow(max(dot(n, h), 0.0), 512.0) * 8.0;
+    vec3 color = mix(water, reflection, fresnel) + spec;
+    float foam = clamp(nf.w, 0.0, 1.0);
+    color = mix(color, uAmbient + uSunColor * max(dot(n, uSunDir), 0.0), foam);
+    FragColor = vec4(color, 1.0);
+}
+`
+
+// WaterSurface simulates ocean waves with the statistical model of Tessendorf: the waves of a square
+// patch, which tiles seamlessly, are a sum of sinusoids with random amplitudes following the Phillips
+// spectrum of the wind, each moving at the speed of the dispersion relation of deep water.
+// Update computes their sum with inverse FFTs into the heights, the choppy horizontal displacements,
+// the normals and the foam where the displacements fold the surface, transferred to the textures
+// used by Draw to render the patch.
+type WaterSurface struct {
+	gs            *gls.GLS
+	Origin        math32.Vector3 // position of the corner of the patch
+	Choppiness    float32        // factor of the horizontal displacements sharpening the crests
+	FoamThreshold float32        // folding of the surface below which there is foam, 1 for none
+	WaterColor    math32.Color   // color of the deep water
+	ScatterColor  math32.Color   // color of the light scattered through the crests
+	n             int            // number of samples along each side of the patch
+	patchSize     float32
+	amplitude     float32         // standard deviation of the heights after the first Update
+	h0            []complex128    // initial amplitude of each wave vector
+	h0Conj        []complex128    // conjugate of the initial amplitude of the opposite wave vector
+	omega         []float64       // angular frequency of each wave vector
+	bufs          [4][]complex128 // spectra of two real fields each
+	heights       []float32
+	displacement  []float32 // x, y, z displacements of each sample
+	normalFoam    []float32 // normal and foam of each sample
+	dispTex       *texture.Texture2D
+	normalTex     *texture.Texture2D
+	geom          *geometry.Geometry
+	prog          *gls.Program
+}
+
+// NewWaterSurface creates and returns a pointer to a new WaterSurface with the specified number of samples
+// along each side of the square patch, size of the patch, wind velocity in the XZ plane in m/s and
+// amplitude of the spectrum. The random amplitudes of the waves are generated with the specified generator.
+// Returns an error if the number of samples is not a power of two or the shader cannot be built.
+func NewWaterSurface(gs *gls.GLS, gridSize int, patchSize float32, wind *math32.Vector2, waveAmplitude float32, rng *rand.Rand) (*WaterSurface, error) {
+
+	if gridSize < 2 || gridSize&(gridSize-1) != 0 {
+		return nil, fmt.Errorf("invalid water grid size:%d", gridSize)
+	}
+	w := new(WaterSurface)
+	w.gs = gs
+	w.Choppiness = 1
+	w.FoamThreshold = 0.3
+	w.WaterColor = math32.Color{R: 0.004, G: 0.016, B: 0.047}
+	w.ScatterColor = math32.Color{R: 0, G: 0.09, B: 0.08}
+	w.n = gridSize
+	w.patchSize = patchSize
+	n := gridSize
+	count := n * n
+	w.h0 = make([]complex128, count)
+	w.h0Conj = make([]complex128, count)
+	w.omega = make([]float64, count)
+	for i := range w.bufs {
+		w.bufs[i] = make([]complex128, count)
+	}
+	w.heights = make([]float32, count)
+	w.displacement = make([]float32, 3*count)
+	w.normalFoam = make([]float32, 4*count)
+
+	// Phillips spectrum: largest waves of the wind speed, aligned with the wind,
+	// and suppression of the waves much smaller than them
+	speed := float64(wind.Length())
+	windDir := math32.Vector2{X: 1, Y: 0}
+	if speed > 0 {
+		windDir = *wind
+		windDir.Normalize()
+	}
+	largest := speed * speed / waterGravity
+	small := largest / 1000
+	for m := 0; m < n; m++ {
+		for l := 0; l < n; l++ {
+			kx, kz := w.waveVector(l, m)
+			k2 := kx*kx + kz*kz
+			i := l + m*n
+			w.omega[i] = math.Sqrt(waterGravity * math.Sqrt(k2))
+			// No wave at the zero frequency, nor at the Nyquist frequency without an opposite wave vector
+			if k2 == 0 || largest == 0 || l == 0 || m == 0 {
+				continue
+			}
+			kdotw := (kx*float64(windDir.X) + kz*float64(windDir.Y)) / math.Sqrt(k2)
+			p := float64(waveAmplitude) * math.Exp(-1/(k2*largest*largest)) / (k2 * k2) * kdotw * kdotw * math.Exp(-k2*small*small)
+			amp := math.Sqrt(p / 2)
+			w.h0[i] = complex(rng.NormFloat64()*amp, rng.NormFloat64()*amp)
+		}
+	}
+	for m := 0; m < n; m++ {
+		for l := 0; l < n; l++ {
+			opposite := (n-l)%n + ((n-m)%n)*n
+			w.h0Conj[l+m*n] = cmplx.Conj(w.h0[opposite])
+		}
+	}
+
+	w.dispTex = texture.NewTexture2DFromData(n, n, gls.RGB, gls.FLOAT, gls.RGB32F, w.displacement)
+	w.dispTex.SetGenerateMipmap(false)
+	w.dispTex.SetFilter(texture.FilterLinear, texture.FilterLinear)
+	w.dispTex.SetWrap(texture.WrapRepeat, texture.WrapRepeat)
+	w.normalTex = texture.NewTexture2DFromData(n, n, gls.RGBA, gls.FLOAT, gls.RGBA16F, w.normalFoam)
+	w.normalTex.SetWrap(texture.WrapRepeat, texture.WrapRepeat)
+
+	// Grid of the patch, with the last row and column on the first ones of the next patch
+	positions := math32.NewArrayF32(0, (n+1)*(n+1)*3)
+	indices := math32.NewArrayU32(0, n*n*6)
+	cell := patchSize / float32(n)
+	for m := 0; m <= n; m++ {
+		for l := 0; l <= n; l++ {
+			positions.Append(float32(l)*cell, 0, float32(m)*cell)
+		}
+	}
+	for m := 0; m < n; m++ {
+		for l := 0; l < n; l++ {
+			a := uint32(l + m*(n+1))
+			b := a + uint32(n+1)
+			indices.Append(a, b, a+1, b, b+1, a+1)
+		}
+	}
+	w.geom = geometry.NewGeometry()
+	w.geom.SetIndices(indices)
+	w.geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+
+	prefix := fmt.Sprintf("#version %s\n", GLSL_VERSION)
+	w.prog = gs.NewProgram()
+	w.prog.AddShader(gls.VERTEX_SHADER, prefix+waterVertexSource)
+	w.prog.AddShader(gls.FRAGMENT_SHADER, prefix+waterFragmentSource)
+	if err := w.prog.Build(); err != nil {
+		w.Dispose()
+		return nil, err
+	}
+	w.Update(0)
+	return w, nil
+}
+
+// waveVector returns the wave vector of the specified indices of the spectrum.
+func (w *WaterSurface) waveVector(l, m int) (kx, kz float64) {
+
+	scale := 2 * math.Pi / float64(w.patchSize)
+	return float64(l-w.n/2) * scale, float64(m-w.n/2) * scale
+}
+
+// GridSize returns the number of samples along each side of the patch.
+func (w *WaterSurface) GridSize() int {
+
+	return w.n
+}
+
+// PatchSize returns the size of the side of the patch.
+func (w *WaterSurface) PatchSize() float32 {
+
+	return w.patchSize
+}
+
+// Heights returns the heights of the samples of the last Update, in row major order along X.
+func (w *WaterSurface) Heights() []float32 {
+
+	return w.heights
+}
+
+// DisplacementTexture returns the texture with the x, y and z displacements of the samples.
+func (w *WaterSurface) DisplacementTexture() *texture.Texture2D {
+
+	return w.dispTex
+}
+
+// NormalTexture returns the texture with the normals of the samples and the foam in the alpha component.
+func (w *WaterSurface) NormalTexture() *texture.Texture2D {
+
+	return w.normalTex
+}
+
+// Update computes the surface at the specified time in seconds, and updates the textures.
+func (w *WaterSurface) Update(time float32) {
+
+	n := w.n
+	t := float64(time)
+	hk, slope, disp, jac := w.bufs[0], w.bufs[1], w.bufs[2], w.bufs[3]
+	for m := 0; m < n; m++ {
+		for l := 0; l < n; l++ {
+			i := l + m*n
+			kx, kz := w.waveVector(l, m)
+			k := math.Sqrt(kx*kx + kz*kz)
+			e := cmplx.Rect(1, w.omega[i]*t)
+			h := w.h0[i]*e + w.h0Conj[i]*cmplx.Conj(e)
+			// Pairs of spectra of real fields f and g as f + i*g: height and Jacobian term xz,
+			// slopes along x and z, displacements along x and z, Jacobian terms xx and zz
+			var jxz, dx, dz, jxx, jzz complex128
+			if k > 0 {
+				jxz = h * complex(kx*kz/k, 0)
+				dx = h * complex(0, -kx/k)
+				dz = h * complex(0, -kz/k)
+				jxx = h * complex(kx*kx/k, 0)
+				jzz = h * complex(kz*kz/k, 0)
+			}
+			hk[i] = h + 1i*jxz
+			slope[i] = h*complex(0, kx) + 1i*h*complex(0, kz)
+			disp[i] = dx + 1i*dz
+			jac[i] = jxx + 1i*jzz
+		}
+	}
+	for _, b := range w.bufs {
+		math32.FFT2D(b, n, n, true)
+	}
+
+	// The spectrum is centered on the zero frequency: the signs of the samples alternate
+	scale := float64(n * n)
+	var sumSq float64
+	for m := 0; m < n; m++ {
+		for l := 0; l < n; l++ {
+			i := l + m*n
+			sign := scale
+			if (l+m)%2 != 0 {
+				sign = -scale
+			}
+			h := real(hk[i]) * sign
+			jxz := imag(hk[i]) * sign
+			sx, sz := real(slope[i])*sign, imag(slope[i])*sign
+			dx, dz := real(disp[i])*sign, imag(disp[i])*sign
+			jxx, jzz := real(jac[i])*sign, imag(jac[i])*sign
+			sumSq += h * h
+
+			w.heights[i] = float32(h)
+			w.displacement[3*i] = float32(dx)
+			w.displacement[3*i+1] = float32(h)
+			w.displacement[3*i+2] = float32(dz)
+			nv := math32.Vector3{X: float32(-sx), Y: 1, Z: float32(-sz)}
+			nv.Normalize()
+			// Jacobian of the horizontal displacement: negative where the surface folds
+			c := float64(w.Choppiness)
+			j := (1+c*jxx)*(1+c*jzz) - c*c*jxz*jxz
+			var foam float32
+			if w.FoamThreshold > 0 {
+				foam = math32.Clamp((w.FoamThreshold-float32(j))/w.FoamThreshold, 0, 1)
+			}
+			w.normalFoam[4*i] = nv.X
+			w.normalFoam[4*i+1] = nv.Y
+			w.normalFoam[4*i+2] = nv.Z
+			w.normalFoam[4*i+3] = foam
+		}
+	}
+	w.amplitude = float32(math.Sqrt(sumSq / scale))
+	w.dispTex.SetData(n, n, gls.RGB, gls.FLOAT, gls.RGB32F, w.displacement)
+	w.normalTex.SetData(n, n, gls.RGBA, gls.FLOAT, gls.RGBA16F, w.normalFoam)
+}
+
+// Draw renders the patch seen from the camera of the specified rig into the current framebuffer,
+// reflecting the specified sky and lit by its sun and ambient colors.
+func (w *WaterSurface) Draw(rig *camera.CameraRig, sky *ProceduralSky) {
+
+	gs := w.gs
+	cam := rig.Camera()
+	var view, proj, vp math32.Matrix4
+	cam.ViewMatrix(&view)
+	cam.ProjMatrix(&proj)
+	vp.MultiplyMatrices(&proj, &view)
+	var camPos math32.Vector3
+	cam.GetCamera().WorldPosition(&camPos)
+	sun := sky.SunColor()
+	ambient := sky.AmbientColor()
+
+	prog := w.prog
+	gs.UseProgram(prog)
+	gs.UniformMatrix4fv(prog.GetUniformLocation("uViewProj"), 1, false, &vp[0])
+	gs.Uniform3f(prog.GetUniformLocation("uOrigin"), w.Origin.X, w.Origin.Y, w.Origin.Z)
+	gs.Uniform1f(prog.GetUniformLocation("uPatchSize"), w.patchSize)
+	gs.Uniform1f(prog.GetUniformLocation("uChoppiness"), w.Choppiness)
+	gs.Uniform1f(prog.GetUniformLocation("uAmplitude"), w.amplitude)
+	gs.Uniform3f(prog.GetUniformLocation("uCamPos"), camPos.X, camPos.Y, camPos.Z)
+	gs.Uniform3f(prog.GetUniformLocation("uWaterColor"), w.WaterColor.R, w.WaterColor.G, w.WaterColor.B)
+	gs.Uniform3f(prog.GetUniformLocation("uScatterColor"), w.ScatterColor.R, w.ScatterColor.G, w.ScatterColor.B)
+	gs.Uniform3f(prog.GetUniformLocation("uSunColor"), sun.R, sun.G, sun.B)
+	gs.Uniform3f(prog.GetUniformLocation("uAmbient"), ambient.R, ambient.G, ambient.B)
+	sky.setRadianceUniforms(prog)
+	w.dispTex.Bind(gs, 0)
+	gs.Uniform1i(prog.GetUniformLocation("uDisplacement"), 0)
+	w.normalTex.Bind(gs, 1)
+	gs.Uniform1i(prog.GetUniformLocation("uNormalFoam"), 1)
+
+	gs.Enable(gls.DEPTH_TEST)
+	gs.DepthMask(true)
+	gs.Disable(gls.BLEND)
+	gs.Disable(gls.CULL_FACE)
+	w.geom.RenderSetup(gs)
+	gs.DrawElements(gls.TRIANGLES, int32(len(w.geom.Indices())), gls.UNSIGNED_INT, 0)
+}
+
+// Dispose releases the OpenGL resources of this WaterSurface.
+func (w *WaterSurface) Dispose() {
+
+	w.dispTex.Dispose()
+	w.normalTex.Dispose()
+	w.geom.Dispose()
+	if w.prog.Handle() != 0 {
+		w.gs.DeleteProgram(w.prog.Handle())
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/math32"
+)
+
+// Align specifies the horizontal alignment of the lines of a TextLayout.
+type Align int
+
+// Horizontal alignments
+const (
+	AlignLeft    = Align(iota) // lines start at the left of the box
+	AlignCenter                // lines are centered in the box
+	AlignRight                 // lines end at the right of the box
+	AlignJustify               // spaces are stretched so the lines fill the box, except the last line of each paragraph
+)
+
+// TextLayout lays out multi-line text of an SDFFont inside a bounding box, wrapping the
+// lines at the spaces between words to fit the box width.
+// The box is in pixels of the font size with the Y axis up, and the first line is placed at its top.
+type TextLayout struct {
+	Font   *SDFFont
+	Bounds math32.Rect
+	Align  Align
+	Text   string
+}
+
+// layoutLine is a wrapped line of text.
+type layoutLine struct {
+	text  string
+	width float32 // width in pixels
+	last  bool    // last line of a paragraph
+}
+
+// NewTextLayout creates and returns a pointer to a new TextLayout of the specified text.
+func NewTextLayout(font *SDFFont, bounds *math32.Rect, align Align, text string) *TextLayout {
+
+	tl := new(TextLayout)
+	tl.Font = font
+	tl.Bounds = *bounds
+	tl.Align = align
+	tl.Text = text
+	return tl
+}
+
+// Wrap returns the lines of the text of this layout which fit the width of its box.
+// Lines are broken at the line feeds and at the spaces between words.
+// A word wider than the box is broken between its characters.
+func (tl *TextLayout) Wrap() []string {
+
+	lines := tl.wrap(tl.Text)
+	texts := make([]string, len(lines))
+	for i := range lines {
+		texts[i] = lines[i].text
+	}
+	return texts
+}
+
+// MeasureText returns the width and height of the specified text laid out in the box
+// of this layout, with one pixel of the font size scaled by the specified scale.
+func (tl *TextLayout) MeasureText(text string, scale float32) math32.Vector2 {
+
+	lines := tl.wrap(text)
+	var width float32
+	for i := range lines {
+		width = math32.Max(width, lines[i].width)
+	}
+	return math32.Vector2{X: width * scale, Y: float32(len(lines)) * tl.Font.lineHeight * scale}
+}
+
+// GenerateMesh returns a geometry with the textured glyph quads of the text of this layout in the XY plane
+// facing +Z, with its lines wrapped, aligned and kerned. One pixel of the font size has the specified scale.
+// Characters without a glyph return an error.
+func (tl *TextLayout) GenerateMesh(scale float32) (*geometry.Geometry, error) {
+
+	f := tl.Font
+	boxWidth := tl.Bounds.Max.X - tl.Bounds.Min.X
+	var qb glyphQuads
+	penY := tl.Bounds.Max.Y - f.ascent
+	for _, line := range tl.wrap(tl.Text) {
+		penX := tl.Bounds.Min.X
+		var spacing float32 // extra advance of each space when justified
+		switch tl.Align {
+		case AlignCenter:
+			penX += (boxWidth - line.width) / 2
+		case AlignRight:
+			penX += boxWidth - line.width
+		case AlignJustify:
+			if spaces := strings.Count(line.text, " "); !line.last && spaces > 0 {
+				spacing = (boxWidth - line.width) / float32(spaces)
+			}
+		}
+		prev := rune(-1)
+		for _, r := range line.text {
+			g, ok := f.glyphs[r]
+			if !ok {
+				return nil, fmt.Errorf("font has no glyph for character %q", r)
+			}
+			if prev >= 0 {
+				penX += f.Kern(prev, r)
+			}
+			qb.add(g, penX, penY, scale)
+			penX += g.advance
+			if r == ' ' {
+				penX += spacing
+			}
+			prev = r
+		}
+		penY -= f.lineHeight
+	}
+	return qb.geometry(), nil
+}
+
+// wrap breaks the specified text into lines which fit the width of the box of this layout.
+func (tl *TextLayout) wrap(text string) []layoutLine {
+
+	maxWidth := tl.Bounds.Max.X - tl.Bounds.Min.X
+	fits := func(s string) bool { return maxWidth <= 0 || tl.width(s) <= maxWidth }
+	var lines []layoutLine
+	for _, para := range strings.Split(text, "\n") {
+		start := len(lines)
+		cur := ""
+		for _, word := range strings.Split(para, " ") {
+			if cur != "" && fits(cur+" "+word) {
+				cur += " " + word
+				continue
+			}
+			if cur != "" {
+				lines = append(lines, layoutLine{text: cur})
+			}
+			// Breaks the words wider than the box between characters
+			cur = ""
+			for _, r := range word {
+				if cur != "" && !fits(cur+string(r)) {
+					lines = append(lines, layoutLine{text: cur})
+					cur = ""
+				}
+				cur += string(r)
+			}
+		}
+		if cur != "" || len(lines) == start {
+			lines = append(lines, layoutLine{text: cur})
+		}
+		lines[len(lines)-1].last = true
+	}
+	for i := range lines {
+		lines[i].width = tl.width(lines[i].text)
+	}
+	return lines
+}
+
+// width returns the width in pixels of the specified single line text, including the kerning.
+func (tl *TextLayout) width(s string) float32 {
+
+	f := tl.Font
+	var w float32
+	prev := rune(-1)
+	for _, r := range s {
+		g, ok := f.glyphs[r]
+		if !ok {
+			continue
+		}
+		if prev >= 0 {
+			w += f.Kern(prev, r)
+		}
+		w += g.advance
+		prev = r
+	}
+	return w
+}
@@ -0,0 +1,374 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io/ioutil"
+	"strings"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// Range of the characters of an SDFFont atlas
+const (
+	sdfFirstChar = ' '
+	sdfLastChar  = '~'
+)
+
+// sdfGlyph contains the layout of a glyph of an SDFFont in pixels at the font size.
+type sdfGlyph struct {
+	index   sfnt.GlyphIndex
+	uvRect  math32.Rect // UV rectangle in the atlas from its top left corner, empty for blank glyphs
+	advance float32     // horizontal distance to the origin of the next glyph
+	bearing float32     // horizontal distance from the origin to the left of the glyph bitmap
+	top     float32     // vertical distance from the baseline up to the top of the glyph bitmap
+	width   float32     // glyph bitmap width, including the padding
+	height  float32     // glyph bitmap height, including the padding
+}
+
+// SDFFont is a TrueType font whose ASCII glyphs are stored in a texture atlas as signed
+// distance fields: each texel holds the distance to the nearest glyph edge, 0.5 at the edge,
+// increasing inside the glyph. Thresholding the interpolated distance in the fragment shader
+// renders sharp text at any scale.
+type SDFFont struct {
+	sf         *sfnt.Font
+	buf        sfnt.Buffer
+	ppem       fixed.Int26_6
+	size       float32 // font size in pixels
+	spread     int     // padding around the glyphs and maximum distance in pixels
+	lineHeight float32
+	ascent     float32
+	atlas      *texture.SpriteAtlas
+	tex        *texture.Texture2D
+	glyphs     map[rune]*sdfGlyph
+}
+
+// NewSDFFontFromFile creates and returns a pointer to a new SDFFont from the specified
+// TrueType font file, rasterized with the specified size in pixels.
+func NewSDFFontFromFile(path string, fontSize float32) (*SDFFont, error) {
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sf, err := sfnt.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if fontSize <= 0 {
+		return nil, fmt.Errorf("invalid font size:%v", fontSize)
+	}
+
+	f := new(SDFFont)
+	f.sf = sf
+	f.size = fontSize
+	f.spread = int(math32.Max(2, math32.Ceil(fontSize/8)))
+	f.glyphs = make(map[rune]*sdfGlyph)
+	buf := &f.buf
+	ppem := fixed.Int26_6(fontSize * 64)
+	f.ppem = ppem
+	metrics, err := sf.Metrics(buf, ppem, font.HintingNone)
+	if err != nil {
+		return nil, err
+	}
+	f.lineHeight = float32(metrics.Height) / 64
+	f.ascent = float32(metrics.Ascent) / 64
+
+	// Computes the distance fields of the glyphs
+	type bitmap struct {
+		r   rune
+		img *image.RGBA
+	}
+	bitmaps := make([]bitmap, 0, sdfLastChar-sdfFirstChar+1)
+	var area int
+	for r := rune(sdfFirstChar); r <= sdfLastChar; r++ {
+		idx, err := sf.GlyphIndex(buf, r)
+		if err != nil || idx == 0 {
+			continue
+		}
+		bounds, advance, err := sf.GlyphBounds(buf, idx, ppem, font.HintingNone)
+		if err != nil {
+			return nil, err
+		}
+		g := &sdfGlyph{index: idx, advance: float32(advance) / 64}
+		f.glyphs[r] = g
+		minX, minY := bounds.Min.X.Floor(), bounds.Min.Y.Floor()
+		w, h := bounds.Max.X.Ceil()-minX, bounds.Max.Y.Ceil()-minY
+		if w <= 0 || h <= 0 {
+			continue
+		}
+		segments, err := sf.LoadGlyph(buf, idx, ppem, nil)
+		if err != nil {
+			return nil, err
+		}
+		// Glyph coordinates relative to the top left of the padded bitmap
+		offX := float32(f.spread - minX)
+		offY := float32(f.spread - minY)
+		mask := rasterizeGlyph(segments, w+2*f.spread, h+2*f.spread, offX, offY)
+		img := signedDistanceField(mask, f.spread)
+		g.bearing = float32(minX - f.spread)
+		g.top = float32(f.spread - minY)
+		g.width = float32(img.Rect.Dx())
+		g.height = float32(img.Rect.Dy())
+		bitmaps = append(bitmaps, bitmap{r, img})
+		area += img.Rect.Dx() * img.Rect.Dy()
+	}
+
+	// Packs the glyphs in the smallest power of two atlas they fit
+	size := 64
+	for size*size < area {
+		size *= 2
+	}
+	for {
+		f.atlas = texture.NewSpriteAtlas(size)
+		packed := true
+		for _, b := range bitmaps {
+			uv, err := f.atlas.Add(string(b.r), b.img)
+			if err != nil {
+				packed = false
+				break
+			}
+			f.glyphs[b.r].uvRect = uv
+		}
+		if packed {
+			break
+		}
+		size *= 2
+	}
+	if len(bitmaps) > 0 {
+		f.tex, err = f.atlas.Finalize()
+		if err != nil {
+			return nil, err
+		}
+		f.tex.SetFilter(texture.FilterLinear, texture.FilterLinear)
+		f.tex.SetGenerateMipmap(false)
+	}
+	return f, nil
+}
+
+// rasterizeGlyph returns the coverage mask with the specified size of the specified glyph
+// outline translated by the specified offset.
+func rasterizeGlyph(segments sfnt.Segments, width, height int, offX, offY float32) *image.Alpha {
+
+	z := vector.NewRasterizer(width, height)
+	z.DrawOp = draw.Src
+	pt := func(p fixed.Point26_6) (float32, float32) {
+		return float32(p.X)/64 + offX, float32(p.Y)/64 + offY
+	}
+	for _, seg := range segments {
+		ax, ay := pt(seg.Args[0])
+		bx, by := pt(seg.Args[1])
+		cx, cy := pt(seg.Args[2])
+		switch seg.Op {
+		case sfnt.SegmentOpMoveTo:
+			z.MoveTo(ax, ay)
+		case sfnt.SegmentOpLineTo:
+			z.LineTo(ax, ay)
+		case sfnt.SegmentOpQuadTo:
+			z.QuadTo(ax, ay, bx, by)
+		case sfnt.SegmentOpCubeTo:
+			z.CubeTo(ax, ay, bx, by, cx, cy)
+		}
+	}
+	mask := image.NewAlpha(z.Bounds())
+	z.Draw(mask, mask.Bounds(), image.Opaque, image.Point{})
+	return mask
+}
+
+// signedDistanceField returns the signed distance field of the specified coverage mask, computed
+// by brute force: the distance of each pixel to the nearest pixel on the other side of the glyph edge,
+// searched up to the specified spread. Distances in [-spread,spread] are mapped to [0,1], 0.5 at the edge.
+func signedDistanceField(mask *image.Alpha, spread int) *image.RGBA {
+
+	w, h := mask.Rect.Dx(), mask.Rect.Dy()
+	inside := func(x, y int) bool {
+		if x < 0 || y < 0 || x >= w || y >= h {
+			return false
+		}
+		return mask.Pix[y*mask.Stride+x] >= 0x80
+	}
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	maxDist := float32(spread)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			in := inside(x, y)
+			best := maxDist * maxDist
+			for dy := -spread; dy <= spread; dy++ {
+				for dx := -spread; dx <= spread; dx++ {
+					if d := float32(dx*dx + dy*dy); d < best && inside(x+dx, y+dy) != in {
+						best = d
+					}
+				}
+			}
+			// The edge is half way between the pixel centers on each side
+			dist := math32.Sqrt(best) - 0.5
+			if !in {
+				dist = -dist
+			}
+			v := uint8(math32.Clamp(0.5+dist/(2*maxDist), 0, 1) * 0xFF)
+			img.SetRGBA(x, y, color.RGBA{R: v, G: v, B: v, A: v})
+		}
+	}
+	return img
+}
+
+// Size returns the size in pixels at which the glyphs of this font were rasterized.
+func (f *SDFFont) Size() float32 {
+
+	return f.size
+}
+
+// Spread returns the maximum distance in pixels stored in the distance fields.
+func (f *SDFFont) Spread() int {
+
+	return f.spread
+}
+
+// LineHeight returns the recommended vertical distance between two lines of text in pixels.
+func (f *SDFFont) LineHeight() float32 {
+
+	return f.lineHeight
+}
+
+// Ascent returns the distance in pixels from the top of a line to its baseline.
+func (f *SDFFont) Ascent() float32 {
+
+	return f.ascent
+}
+
+// Kern returns the horizontal adjustment in pixels of the advance between the specified
+// consecutive characters, from the kerning tables of the font. Returns 0 if there is none.
+func (f *SDFFont) Kern(r0, r1 rune) float32 {
+
+	g0, ok0 := f.glyphs[r0]
+	g1, ok1 := f.glyphs[r1]
+	if !ok0 || !ok1 {
+		return 0
+	}
+	k, err := f.sf.Kern(&f.buf, g0.index, g1.index, f.ppem, font.HintingNone)
+	if err != nil {
+		return 0
+	}
+	return float32(k) / 64
+}
+
+// Atlas returns the sprite atlas with the distance fields of the glyphs.
+func (f *SDFFont) Atlas() *texture.SpriteAtlas {
+
+	return f.atlas
+}
+
+// Texture returns the texture with the distance fields of the glyphs,
+// or nil if the font has no visible glyphs.
+func (f *SDFFont) Texture() *texture.Texture2D {
+
+	return f.tex
+}
+
+// GlyphInfo returns the UV rectangle in the atlas from its top left corner, the advance and
+// the left side bearing in pixels of the glyph of the specified character and true,
+// or false if the font has no glyph for it. Blank glyphs have an empty rectangle.
+func (f *SDFFont) GlyphInfo(r rune) (uvRect math32.Rect, advance, bearing float32, ok bool) {
+
+	g, ok := f.glyphs[r]
+	if !ok {
+		return uvRect, 0, 0, false
+	}
+	return g.uvRect, g.advance, g.bearing, true
+}
+
+// GlyphQuad returns the rectangle of the quad of the glyph of the specified character in pixels,
+// relative to its origin on the baseline with the Y axis up, its UV rectangle in the atlas
+// from its top left corner, its advance and true, or false if the font has no glyph for it.
+// Blank glyphs have zero size rectangles.
+func (f *SDFFont) GlyphQuad(r rune) (quad, uvRect math32.Rect, advance float32, ok bool) {
+
+	g, ok := f.glyphs[r]
+	if !ok {
+		return quad, uvRect, 0, false
+	}
+	quad.Min.Set(g.bearing, g.top-g.height)
+	quad.Max.Set(g.bearing+g.width, g.top)
+	return quad, g.uvRect, g.advance, true
+}
+
+// MeshForString returns a geometry with a textured quad for each glyph of the specified text
+// in the XY plane facing +Z, so it faces the camera when its node is a billboard.
+// The first line starts at the origin on its baseline and one pixel of the font size has the
+// specified scale. Characters without a glyph return an error.
+func (f *SDFFont) MeshForString(text string, scale float32) (*geometry.Geometry, error) {
+
+	var qb glyphQuads
+	var penY float32
+	for _, line := range strings.Split(text, "\n") {
+		penX := float32(0)
+		prev := rune(-1)
+		for _, r := range line {
+			g, ok := f.glyphs[r]
+			if !ok {
+				return nil, fmt.Errorf("font has no glyph for character %q", r)
+			}
+			if prev >= 0 {
+				penX += f.Kern(prev, r)
+			}
+			qb.add(g, penX, penY, scale)
+			penX += g.advance
+			prev = r
+		}
+		penY -= f.lineHeight
+	}
+	return qb.geometry(), nil
+}
+
+// glyphQuads accumulates the vertices of the glyph quads of a text mesh.
+type glyphQuads struct {
+	positions math32.ArrayF32
+	normals   math32.ArrayF32
+	uvs       math32.ArrayF32
+	indices   math32.ArrayU32
+}
+
+// add adds the quad of the specified glyph with its origin at the specified position in pixels, scaled by the specified scale.
+// Blank glyphs add nothing.
+func (qb *glyphQuads) add(g *sdfGlyph, penX, penY, scale float32) {
+
+	if g.width == 0 {
+		return
+	}
+	x0 := (penX + g.bearing) * scale
+	x1 := x0 + g.width*scale
+	y1 := (penY + g.top) * scale
+	y0 := y1 - g.height*scale
+	base := uint32(qb.positions.Size() / 3)
+	qb.positions.Append(x0, y0, 0, x1, y0, 0, x1, y1, 0, x0, y1, 0)
+	qb.normals.Append(0, 0, 1, 0, 0, 1, 0, 0, 1, 0, 0, 1)
+	// Flips the atlas rectangle, whose origin is at the top, to the texture coordinates
+	u0, u1 := g.uvRect.Min.X, g.uvRect.Max.X
+	v0, v1 := 1-g.uvRect.Max.Y, 1-g.uvRect.Min.Y
+	qb.uvs.Append(u0, v0, u1, v0, u1, v1, u0, v1)
+	qb.indices.Append(base, base+1, base+2, base, base+2, base+3)
+}
+
+// geometry returns a new geometry with the accumulated quads.
+func (qb *glyphQuads) geometry() *geometry.Geometry {
+
+	geom := geometry.NewGeometry()
+	geom.SetIndices(qb.indices)
+	geom.AddVBO(gls.NewVBO(qb.positions).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(qb.normals).AddAttrib(gls.VertexNormal))
+	geom.AddVBO(gls.NewVBO(qb.uvs).AddAttrib(gls.VertexTexcoord))
+	return geom
+}
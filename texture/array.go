@@ -0,0 +1,101 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package texture
+
+import (
+	"fmt"
+
+	"github.com/g3n/engine/gls"
+)
+
+// Texture2DArray is an array of two-dimensional textures of the same size and format,
+// sampled by a single sampler2DArray with the layer as third texture coordinate.
+type Texture2DArray struct {
+	gs         *gls.GLS      // Pointer to OpenGL state
+	texname    uint32        // Texture handle
+	width      int32         // width of the layers in pixels
+	height     int32         // height of the layers in pixels
+	iformat    int32         // internal format
+	format     uint32        // format of the pixel data
+	formatType uint32        // type of the pixel data
+	data       []interface{} // data of each layer to transfer, nil after the transfer
+}
+
+// NewTexture2DArray creates and returns a pointer to a new Texture2DArray with the data of the
+// specified textures as layers, with mipmaps and repeat wrapping.
+// Returns an error if there are no textures, or if they do not have data or have different sizes or formats.
+func NewTexture2DArray(layers []*Texture2D) (*Texture2DArray, error) {
+
+	if len(layers) == 0 {
+		return nil, fmt.Errorf("texture array without layers")
+	}
+	first := layers[0]
+	ta := new(Texture2DArray)
+	ta.width = first.width
+	ta.height = first.height
+	ta.iformat = first.iformat
+	ta.format = first.format
+	ta.formatType = first.formatType
+	for i, t := range layers {
+		if t.data == nil {
+			return nil, fmt.Errorf("texture array layer without data:%d", i)
+		}
+		if t.width != ta.width || t.height != ta.height || t.iformat != ta.iformat ||
+			t.format != ta.format || t.formatType != ta.formatType {
+			return nil, fmt.Errorf("invalid texture array layer size or format:%d", i)
+		}
+		ta.data = append(ta.data, t.data)
+	}
+	return ta, nil
+}
+
+// Layers returns the number of layers of this texture array.
+func (ta *Texture2DArray) Layers() int {
+
+	return len(ta.data)
+}
+
+// Dimensions returns the width and height in pixels of the layers.
+func (ta *Texture2DArray) Dimensions() (width, height int) {
+
+	return int(ta.width), int(ta.height)
+}
+
+// Bind binds this texture array to the specified texture unit, transferring
+// its layers and generating its mipmaps the first time.
+func (ta *Texture2DArray) Bind(gs *gls.GLS, slotIdx int) {
+
+	gs.ActiveTexture(uint32(gls.TEXTURE0 + slotIdx))
+	if ta.gs != nil {
+		gs.BindTexture(gls.TEXTURE_2D_ARRAY, ta.texname)
+		return
+	}
+	ta.gs = gs
+	ta.texname = gs.GenTexture()
+	gs.BindTexture(gls.TEXTURE_2D_ARRAY, ta.texname)
+	layers := int32(len(ta.data))
+	gs.TexImage3D(gls.TEXTURE_2D_ARRAY, 0, ta.iformat, ta.width, ta.height, layers, 0, ta.format, ta.formatType, nil)
+	for i, data := range ta.data {
+		gs.TexSubImage3D(gls.TEXTURE_2D_ARRAY, 0, 0, 0, int32(i), ta.width, ta.height, 1, ta.format, ta.formatType, data)
+	}
+	gs.GenerateMipmap(gls.TEXTURE_2D_ARRAY)
+	gs.TexParameteri(gls.TEXTURE_2D_ARRAY, gls.TEXTURE_MAG_FILTER, gls.LINEAR)
+	gs.TexParameteri(gls.TEXTURE_2D_ARRAY, gls.TEXTURE_MIN_FILTER, gls.LINEAR_MIPMAP_LINEAR)
+	gs.TexParameteri(gls.TEXTURE_2D_ARRAY, gls.TEXTURE_WRAP_S, gls.REPEAT)
+	gs.TexParameteri(gls.TEXTURE_2D_ARRAY, gls.TEXTURE_WRAP_T, gls.REPEAT)
+	// The data is kept by OpenGL
+	for i := range ta.data {
+		ta.data[i] = nil
+	}
+}
+
+// Dispose releases the OpenGL texture of this texture array.
+func (ta *Texture2DArray) Dispose() {
+
+	if ta.gs != nil {
+		ta.gs.DeleteTextures(ta.texname)
+		ta.gs = nil
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package texture
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+
+	"github.com/g3n/engine/math32"
+)
+
+// SpriteAtlas packs many small images into a single square texture, so sprites,
+// icons and particles can be drawn without binding a texture for each one.
+// The images are packed with the guillotine algorithm: each image is placed at the corner
+// of the free rectangle it fits best and the rest of that rectangle is split in two.
+type SpriteAtlas struct {
+	size    int
+	rgba    *image.RGBA
+	free    []image.Rectangle      // free rectangles of the atlas image
+	sprites map[string]math32.Rect // UV rectangles by name
+}
+
+// NewSpriteAtlas creates and returns a pointer to a new empty SpriteAtlas
+// whose texture has the specified width and height in pixels.
+func NewSpriteAtlas(maxSize int) *SpriteAtlas {
+
+	if maxSize <= 0 {
+		panic("NewSpriteAtlas: size must be positive")
+	}
+	sa := new(SpriteAtlas)
+	sa.size = maxSize
+	sa.rgba = image.NewRGBA(image.Rect(0, 0, maxSize, maxSize))
+	sa.free = []image.Rectangle{sa.rgba.Bounds()}
+	sa.sprites = make(map[string]math32.Rect)
+	return sa
+}
+
+// Add packs the specified image into the atlas with the specified name.
+// Returns the UV rectangle of the image in the atlas with coordinates in [0,1]
+// from the top left corner, or an error if the name was already added or the image does not fit.
+func (sa *SpriteAtlas) Add(name string, img image.Image) (uvRect math32.Rect, err error) {
+
+	if _, ok := sa.sprites[name]; ok {
+		return uvRect, fmt.Errorf("sprite %s already added", name)
+	}
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+
+	// Best short side fit: the free rectangle leaving the smallest leftover along one side
+	best := -1
+	bestFit := 0
+	for i, f := range sa.free {
+		dw, dh := f.Dx()-w, f.Dy()-h
+		if dw < 0 || dh < 0 {
+			continue
+		}
+		fit := dw
+		if dh < fit {
+			fit = dh
+		}
+		if best < 0 || fit < bestFit {
+			best, bestFit = i, fit
+		}
+	}
+	if best < 0 {
+		return uvRect, fmt.Errorf("sprite %s of %dx%d does not fit in the atlas", name, w, h)
+	}
+
+	// Places the image at the corner of the free rectangle and splits the rest of it
+	// along the shorter leftover axis, which keeps the larger free rectangle as large as possible
+	f := sa.free[best]
+	sa.free = append(sa.free[:best], sa.free[best+1:]...)
+	placed := image.Rect(f.Min.X, f.Min.Y, f.Min.X+w, f.Min.Y+h)
+	var right, below image.Rectangle
+	if f.Dx()-w < f.Dy()-h {
+		right = image.Rect(placed.Max.X, f.Min.Y, f.Max.X, placed.Max.Y)
+		below = image.Rect(f.Min.X, placed.Max.Y, f.Max.X, f.Max.Y)
+	} else {
+		right = image.Rect(placed.Max.X, f.Min.Y, f.Max.X, f.Max.Y)
+		below = image.Rect(f.Min.X, placed.Max.Y, placed.Max.X, f.Max.Y)
+	}
+	for _, r := range []image.Rectangle{right, below} {
+		if !r.Empty() {
+			sa.free = append(sa.free, r)
+		}
+	}
+
+	draw.Draw(sa.rgba, placed, img, img.Bounds().Min, draw.Src)
+	size := float32(sa.size)
+	uvRect.Min.Set(float32(placed.Min.X)/size, float32(placed.Min.Y)/size)
+	uvRect.Max.Set(float32(placed.Max.X)/size, float32(placed.Max.Y)/size)
+	sa.sprites[name] = uvRect
+	return uvRect, nil
+}
+
+// UVRect returns the UV rectangle of the image with the specified name
+// and true, or false if not found.
+func (sa *SpriteAtlas) UVRect(name string) (math32.Rect, bool) {
+
+	r, ok := sa.sprites[name]
+	return r, ok
+}
+
+// Image returns the packed atlas image.
+func (sa *SpriteAtlas) Image() *image.RGBA {
+
+	return sa.rgba
+}
+
+// Finalize returns a pointer to a new Texture2D with the packed atlas image,
+// which is sent to OpenGL when first rendered.
+// Returns an error if no image was added.
+func (sa *SpriteAtlas) Finalize() (*Texture2D, error) {
+
+	if len(sa.sprites) == 0 {
+		return nil, errors.New("sprite atlas is empty")
+	}
+	return NewTexture2DFromRGBA(sa.rgba), nil
+}
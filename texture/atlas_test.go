@@ -0,0 +1,68 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package texture
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+// Test that three packed sprites have non overlapping UV rectangles covering their area fractions of the atlas
+// and that their pixels are copied
+func TestSpriteAtlasAdd(t *testing.T) {
+
+	sa := NewSpriteAtlas(256)
+	if _, err := sa.Finalize(); err == nil {
+		t.Error("empty atlas finalized")
+	}
+	names := []string{"wide", "square", "strip"}
+	sizes := []image.Point{{X: 128, Y: 64}, {X: 64, Y: 64}, {X: 100, Y: 30}}
+	colors := []color.RGBA{{R: 255, A: 255}, {G: 255, A: 255}, {B: 255, A: 255}}
+	var rects []math32.Rect
+	for i, name := range names {
+		img := image.NewRGBA(image.Rectangle{Max: sizes[i]})
+		draw.Draw(img, img.Bounds(), &image.Uniform{C: colors[i]}, image.Point{}, draw.Src)
+		uv, err := sa.Add(name, img)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fraction := float32(sizes[i].X*sizes[i].Y) / (256 * 256); uv.Area() != fraction {
+			t.Error("sprite", name, "covers", uv.Area(), "of the atlas instead of", fraction)
+		}
+		if r, ok := sa.UVRect(name); !ok || r != uv {
+			t.Error("UVRect of", name, "is", r, ok, "instead of", uv)
+		}
+		// The center pixel of the sprite in the atlas has its color
+		center := uv.Center(nil)
+		if c := sa.Image().RGBAAt(int(center.X*256), int(center.Y*256)); c != colors[i] {
+			t.Error("sprite", name, "pixel", c, "instead of", colors[i])
+		}
+		rects = append(rects, uv)
+	}
+	for i := range rects {
+		for j := i + 1; j < len(rects); j++ {
+			if rects[i].IntersectionRect(&rects[j], nil).Area() > 0 {
+				t.Error("sprites", names[i], "and", names[j], "overlap:", rects[i], rects[j])
+			}
+		}
+	}
+
+	if _, err := sa.Add("square", image.NewRGBA(image.Rect(0, 0, 1, 1))); err == nil {
+		t.Error("sprite added twice")
+	}
+	if _, err := sa.Add("large", image.NewRGBA(image.Rect(0, 0, 257, 1))); err == nil {
+		t.Error("sprite larger than the atlas added")
+	}
+	if _, ok := sa.UVRect("large"); ok {
+		t.Error("UVRect found a sprite not added")
+	}
+	if tex, err := sa.Finalize(); err != nil || tex == nil {
+		t.Error("Finalize returned", tex, err)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package texture
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+// HDRImage is a high dynamic range image with linear RGB components.
+type HDRImage struct {
+	Width  int
+	Height int
+	Pix    []float32 // RGB components of the pixels, row by row from the top
+}
+
+// DecodeHDR reads and decodes the specified Radiance RGBE (.hdr) image file.
+func DecodeHDR(hdrfile string) (*HDRImage, error) {
+
+	file, err := os.Open(hdrfile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return decodeHDR(bufio.NewReader(file))
+}
+
+// decodeHDR decodes a Radiance image with the standard orientation, flat or run-length encoded.
+func decodeHDR(r *bufio.Reader) (*HDRImage, error) {
+
+	// Header lines up to an empty line
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "#?") {
+		return nil, fmt.Errorf("not a Radiance HDR file")
+	}
+	for {
+		line, err = r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if strings.HasPrefix(line, "FORMAT=") && line != "FORMAT=32-bit_rle_rgbe" {
+			return nil, fmt.Errorf("unsupported HDR format:%s", line[len("FORMAT="):])
+		}
+	}
+
+	// Resolution line
+	line, err = r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	var width, height int
+	_, err = fmt.Sscanf(line, "-Y %d +X %d", &height, &width)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported HDR resolution:%s", strings.TrimSpace(line))
+	}
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid HDR size:%dx%d", width, height)
+	}
+
+	img := &HDRImage{Width: width, Height: height, Pix: make([]float32, 3*width*height)}
+	scanline := make([]byte, 4*width)
+	for y := 0; y < height; y++ {
+		err = readHDRScanline(r, scanline, width)
+		if err != nil {
+			return nil, err
+		}
+		row := img.Pix[3*width*y:]
+		for x := 0; x < width; x++ {
+			e := scanline[4*x+3]
+			if e == 0 {
+				continue
+			}
+			f := float32(math.Ldexp(1, int(e)-(128+8)))
+			row[3*x] = (float32(scanline[4*x]) + 0.5) * f
+			row[3*x+1] = (float32(scanline[4*x+1]) + 0.5) * f
+			row[3*x+2] = (float32(scanline[4*x+2]) + 0.5) * f
+		}
+	}
+	return img, nil
+}
+
+// readHDRScanline reads a scanline of RGBE pixels into the specified buffer.
+func readHDRScanline(r *bufio.Reader, scanline []byte, width int) error {
+
+	head := scanline[:4]
+	_, err := io.ReadFull(r, head)
+	if err != nil {
+		return err
+	}
+	// Flat scanline: the encoded scanlines start with 2, 2 and the width
+	if width < 8 || width > 0x7fff || head[0] != 2 || head[1] != 2 || head[2]&0x80 != 0 {
+		_, err = io.ReadFull(r, scanline[4:])
+		return err
+	}
+	if int(head[2])<<8|int(head[3]) != width {
+		return fmt.Errorf("invalid HDR scanline width")
+	}
+	// Each component is run-length encoded separately
+	for c := 0; c < 4; c++ {
+		for x := 0; x < width; {
+			count, err := r.ReadByte()
+			if err != nil {
+				return err
+			}
+			if count > 128 {
+				// Run of a value
+				n := int(count - 128)
+				if x+n > width {
+					return fmt.Errorf("invalid HDR run length")
+				}
+				value, err := r.ReadByte()
+				if err != nil {
+					return err
+				}
+				for ; n > 0; n-- {
+					scanline[4*x+c] = value
+					x++
+				}
+			} else {
+				// Literal values
+				n := int(count)
+				if n == 0 || x+n > width {
+					return fmt.Errorf("invalid HDR run length")
+				}
+				for ; n > 0; n-- {
+					value, err := r.ReadByte()
+					if err != nil {
+						return err
+					}
+					scanline[4*x+c] = value
+					x++
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package texture
+
+import (
+	"math"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// Shader sampler names of the textures of an IBL.
+// The pre-filtered textures are the elements of a sampler array.
+const (
+	IBLIrradianceSampler  = "uIrradianceSampler"
+	IBLPrefilteredSampler = "uPrefilteredSampler"
+	IBLBRDFSampler        = "uBRDFSampler"
+)
+
+// Sizes of the textures of an IBL
+const (
+	iblIrradianceWidth  = 32  // width of the irradiance map
+	iblPrefilteredWidth = 128 // width of the least rough pre-filtered map
+	iblPrefilteredCount = 5   // number of pre-filtered maps
+	iblBRDFSize         = 64  // width and height of the BRDF table
+	iblSamples          = 256 // number of samples of each texel
+)
+
+// IBL contains the textures of image based lighting computed from an environment map:
+// the ambient light of the physically based materials. The maps are equirectangular, as the
+// environment map: u is the longitude from -Z through +X and v the colatitude from +Y at v=0.
+type IBL struct {
+	Irradiance  *Texture2D   // diffuse irradiance divided by Pi, to multiply by the diffuse color
+	Prefiltered []*Texture2D // specular radiance pre-filtered for roughness increasing from 0 to 1
+	BRDF        *Texture2D   // scale (R) and bias (G) of the specular color indexed by NdotV (u) and roughness (v)
+}
+
+// NewIBL creates and returns a pointer to a new IBL computed from the
+// specified equirectangular Radiance (.hdr) environment map.
+func NewIBL(hdrfile string) (*IBL, error) {
+
+	img, err := DecodeHDR(hdrfile)
+	if err != nil {
+		return nil, err
+	}
+	return NewIBLFromHDR(img), nil
+}
+
+// NewIBLFromHDR creates and returns a pointer to a new IBL computed from the
+// specified equirectangular environment map. The maps are computed in the CPU:
+// the diffuse irradiance by Monte Carlo integration over the hemisphere with cosine weighted
+// samples, and the pre-filtered radiance and the BRDF table of the split sum approximation
+// by importance sampling of the GGX distribution.
+func NewIBLFromHDR(env *HDRImage) *IBL {
+
+	levels := newEnvLevels(env)
+	ibl := new(IBL)
+
+	pix := computeIrradiance(levels, iblIrradianceWidth, iblIrradianceWidth/2)
+	ibl.Irradiance = newIBLMap(iblIrradianceWidth, iblIrradianceWidth/2, pix)
+	ibl.Irradiance.SetUniformNames(IBLIrradianceSampler, "uIrradianceTexParams")
+
+	for i := 0; i < iblPrefilteredCount; i++ {
+		w := iblPrefilteredWidth >> uint(i)
+		roughness := float32(i) / float32(iblPrefilteredCount-1)
+		pix = computePrefiltered(levels, w, w/2, roughness)
+		tex := newIBLMap(w, w/2, pix)
+		tex.SetUniformNames(IBLPrefilteredSampler, "uPrefilteredTexParams")
+		ibl.Prefiltered = append(ibl.Prefiltered, tex)
+	}
+
+	ibl.BRDF = NewTexture2DFromData(iblBRDFSize, iblBRDFSize, gls.RG, gls.FLOAT, gls.RG16F, computeBRDF(iblBRDFSize))
+	ibl.BRDF.SetGenerateMipmap(false)
+	ibl.BRDF.SetFilter(FilterLinear, FilterLinear)
+	ibl.BRDF.SetFlipY(false)
+	ibl.BRDF.SetUniformNames(IBLBRDFSampler, "uBRDFTexParams")
+	return ibl
+}
+
+// Textures returns the textures of the IBL, to add to the materials using them.
+func (ibl *IBL) Textures() []*Texture2D {
+
+	textures := []*Texture2D{ibl.Irradiance}
+	textures = append(textures, ibl.Prefiltered...)
+	return append(textures, ibl.BRDF)
+}
+
+// Dispose releases the textures of the IBL.
+func (ibl *IBL) Dispose() {
+
+	for _, tex := range ibl.Textures() {
+		tex.Dispose()
+	}
+}
+
+// newIBLMap creates an equirectangular float texture without mipmaps.
+func newIBLMap(width, height int, pix []float32) *Texture2D {
+
+	tex := NewTexture2DFromData(width, height, gls.RGB, gls.FLOAT, gls.RGB16F, pix)
+	tex.SetGenerateMipmap(false)
+	tex.SetFilter(FilterLinear, FilterLinear)
+	tex.SetWrap(WrapRepeat, WrapClampToEdge)
+	tex.SetFlipY(false)
+	return tex
+}
+
+// envLevel is a level of the mipmaps of an equirectangular map.
+type envLevel struct {
+	width  int
+	height int
+	pix    []float32
+}
+
+// newEnvLevels returns the mipmaps of the specified equirectangular map, down to a width of 8.
+func newEnvLevels(env *HDRImage) []envLevel {
+
+	levels := []envLevel{{width: env.Width, height: env.Height, pix: env.Pix}}
+	for {
+		src := levels[len(levels)-1]
+		if src.width <= 8 || src.height <= 1 {
+			return levels
+		}
+		dst := envLevel{width: src.width / 2, height: src.height / 2}
+		dst.pix = make([]float32, 3*dst.width*dst.height)
+		for y := 0; y < dst.height; y++ {
+			for x := 0; x < dst.width; x++ {
+				for c := 0; c < 3; c++ {
+					i0 := 3*(2*y*src.width+2*x) + c
+					i1 := i0 + 3*src.width
+					dst.pix[3*(y*dst.width+x)+c] = (src.pix[i0] + src.pix[i0+3] + src.pix[i1] + src.pix[i1+3]) / 4
+				}
+			}
+		}
+		levels = append(levels, dst)
+	}
+}
+
+// texelSolidAngle returns the mean solid angle of a texel of the level.
+func (l *envLevel) texelSolidAngle() float32 {
+
+	return 4 * math.Pi / float32(l.width*l.height)
+}
+
+// sample returns the bilinear interpolation of the level in the specified unit direction.
+func (l *envLevel) sample(d *math32.Vector3) math32.Vector3 {
+
+	u := math32.Atan2(d.X, -d.Z)/(2*math.Pi) + 0.5
+	v := math32.Acos(math32.Clamp(d.Y, -1, 1)) / math.Pi
+	fx := u*float32(l.width) - 0.5
+	fy := math32.Clamp(v*float32(l.height)-0.5, 0, float32(l.height-1))
+	x0 := int(math32.Floor(fx))
+	y0 := int(fy)
+	tx := fx - float32(x0)
+	ty := fy - float32(y0)
+	y1 := y0 + 1
+	if y1 >= l.height {
+		y1 = l.height - 1
+	}
+	x0 = (x0%l.width + l.width) % l.width
+	x1 := (x0 + 1) % l.width
+	texel := func(x, y int) math32.Vector3 {
+		i := 3 * (y*l.width + x)
+		return math32.Vector3{X: l.pix[i], Y: l.pix[i+1], Z: l.pix[i+2]}
+	}
+	c00, c10, c01, c11 := texel(x0, y0), texel(x1, y0), texel(x0, y1), texel(x1, y1)
+	c00.Lerp(&c10, tx)
+	c01.Lerp(&c11, tx)
+	return *c00.Lerp(&c01, ty)
+}
+
+// sampleLevels returns the interpolation of the mipmaps in the specified unit direction
+// at the specified fractional level.
+func sampleLevels(levels []envLevel, d *math32.Vector3, lod float32) math32.Vector3 {
+
+	lod = math32.Clamp(lod, 0, float32(len(levels)-1))
+	l0 := int(lod)
+	c := levels[l0].sample(d)
+	if t := lod - float32(l0); t > 0 && l0+1 < len(levels) {
+		c1 := levels[l0+1].sample(d)
+		c.Lerp(&c1, t)
+	}
+	return c
+}
+
+// sampleLod returns the level of detail to sample for a sample covering the specified solid angle.
+func sampleLod(levels []envLevel, solidAngle float32) float32 {
+
+	return 0.5 * float32(math.Log2(float64(solidAngle/levels[0].texelSolidAngle())))
+}
+
+// texelDirection returns the unit direction of the center of the specified texel of an equirectangular map.
+func texelDirection(x, y, width, height int) math32.Vector3 {
+
+	phi := (float32(x)+0.5)/float32(width)*2*math.Pi - math.Pi
+	theta := (float32(y) + 0.5) / float32(height) * math.Pi
+	sinTheta := math32.Sin(theta)
+	return math32.Vector3{X: sinTheta * math32.Sin(phi), Y: math32.Cos(theta), Z: -sinTheta * math32.Cos(phi)}
+}
+
+// tangentFrame returns two unit vectors orthogonal to the specified unit normal and to each other.
+func tangentFrame(n *math32.Vector3) (math32.Vector3, math32.Vector3) {
+
+	up := math32.Vector3{X: 0, Y: 1, Z: 0}
+	if math32.Abs(n.Y) > 0.999 {
+		up = math32.Vector3{X: 1, Y: 0, Z: 0}
+	}
+	var t, b math32.Vector3
+	t.CrossVectors(&up, n).Normalize()
+	b.CrossVectors(n, &t)
+	return t, b
+}
+
+// hammersley returns the specified point of a Hammersley sequence of the specified number of points in [0,1)².
+func hammersley(i, n int) (float32, float32) {
+
+	bits := uint32(i)
+	bits = (bits << 16) | (bits >> 16)
+	bits = ((bits & 0x55555555) << 1) | ((bits & 0xAAAAAAAA) >> 1)
+	bits = ((bits & 0x33333333) << 2) | ((bits & 0xCCCCCCCC) >> 2)
+	bits = ((bits & 0x0F0F0F0F) << 4) | ((bits & 0xF0F0F0F0) >> 4)
+	bits = ((bits & 0x00FF00FF) << 8) | ((bits & 0xFF00FF00) >> 8)
+	return float32(i) / float32(n), float32(bits) * 2.3283064365386963e-10
+}
+
+// importanceSampleGGX returns the halfway vector in tangent space (Z up) of the specified sample
+// of the GGX distribution with the specified alpha, and the cosine of its angle with the normal.
+func importanceSampleGGX(xi1, xi2, alpha float32) (math32.Vector3, float32) {
+
+	phi := 2 * math.Pi * xi1
+	cosTheta := math32.Sqrt((1 - xi2) / (1 + (alpha*alpha-1)*xi2))
+	sinTheta := math32.Sqrt(1 - cosTheta*cosTheta)
+	return math32.Vector3{X: sinTheta * math32.Cos(phi), Y: sinTheta * math32.Sin(phi), Z: cosTheta}, cosTheta
+}
+
+// computeIrradiance returns the equirectangular map of the irradiance divided by Pi:
+// the mean of the radiance over the hemisphere of each normal weighted by the cosine.
+func computeIrradiance(levels []envLevel, width, height int) []float32 {
+
+	pix := make([]float32, 3*width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			n := texelDirection(x, y, width, height)
+			t, b := tangentFrame(&n)
+			var sum math32.Vector3
+			for i := 0; i < iblSamples; i++ {
+				// Cosine weighted sample: pdf = cos/Pi
+				xi1, xi2 := hammersley(i, iblSamples)
+				phi := 2 * math.Pi * xi1
+				cosTheta := math32.Sqrt(1 - xi2)
+				sinTheta := math32.Sqrt(xi2)
+				d := math32.Vector3{
+					X: sinTheta*math32.Cos(phi)*t.X + sinTheta*math32.Sin(phi)*b.X + cosTheta*n.X,
+					Y: sinTheta*math32.Cos(phi)*t.Y + sinTheta*math32.Sin(phi)*b.Y + cosTheta*n.Y,
+					Z: sinTheta*math32.Cos(phi)*t.Z + sinTheta*math32.Sin(phi)*b.Z + cosTheta*n.Z,
+				}
+				pdf := math32.Max(cosTheta, 1e-4) / math.Pi
+				c := sampleLevels(levels, &d, sampleLod(levels, 1/(iblSamples*pdf))+1)
+				sum.Add(&c)
+			}
+			sum.MultiplyScalar(1 / float32(iblSamples))
+			i := 3 * (y*width + x)
+			pix[i], pix[i+1], pix[i+2] = sum.X, sum.Y, sum.Z
+		}
+	}
+	return pix
+}
+
+// computePrefiltered returns the equirectangular map of the radiance convolved with the GGX
+// distribution of the specified roughness, assuming that the view and normal directions are
+// the reflection direction.
+func computePrefiltered(levels []envLevel, width, height int, roughness float32) []float32 {
+
+	pix := make([]float32, 3*width*height)
+	alpha := roughness * roughness
+	texelLod := sampleLod(levels, 4*math.Pi/float32(width*height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			n := texelDirection(x, y, width, height)
+			var c math32.Vector3
+			if roughness == 0 {
+				c = sampleLevels(levels, &n, texelLod)
+			} else {
+				t, b := tangentFrame(&n)
+				var sum math32.Vector3
+				var weight float32
+				for i := 0; i < iblSamples; i++ {
+					xi1, xi2 := hammersley(i, iblSamples)
+					ht, nDotH := importanceSampleGGX(xi1, xi2, alpha)
+					h := math32.Vector3{
+						X: ht.X*t.X + ht.Y*b.X + ht.Z*n.X,
+						Y: ht.X*t.Y + ht.Y*b.Y + ht.Z*n.Y,
+						Z: ht.X*t.Z + ht.Y*b.Z + ht.Z*n.Z,
+					}
+					// Reflection of the view direction (the normal) around the halfway vector
+					l := h
+					l.MultiplyScalar(2 * nDotH).Sub(&n)
+					nDotL := l.Dot(&n)
+					if nDotL <= 0 {
+						continue
+					}
+					// pdf = D * NdotH / (4 * VdotH) = D / 4 with V = N
+					a2 := alpha * alpha
+					denom := nDotH*nDotH*(a2-1) + 1
+					pdf := a2 / (math.Pi * denom * denom) / 4
+					s := sampleLevels(levels, &l, math32.Max(sampleLod(levels, 1/(iblSamples*pdf))+1, texelLod))
+					sum.Add(s.MultiplyScalar(nDotL))
+					weight += nDotL
+				}
+				if weight > 0 {
+					sum.MultiplyScalar(1 / weight)
+				}
+				c = sum
+			}
+			i := 3 * (y*width + x)
+			pix[i], pix[i+1], pix[i+2] = c.X, c.Y, c.Z
+		}
+	}
+	return pix
+}
+
+// computeBRDF returns the table of the scale and bias of the specular color of the split sum
+// approximation, with NdotV increasing along the rows and the roughness from row to row.
+func computeBRDF(size int) []float32 {
+
+	pix := make([]float32, 2*size*size)
+	for y := 0; y < size; y++ {
+		roughness := (float32(y) + 0.5) / float32(size)
+		alpha := roughness * roughness
+		k := alpha / 2
+		for x := 0; x < size; x++ {
+			nDotV := (float32(x) + 0.5) / float32(size)
+			v := math32.Vector3{X: math32.Sqrt(1 - nDotV*nDotV), Y: 0, Z: nDotV}
+			var scale, bias float32
+			for i := 0; i < iblSamples; i++ {
+				xi1, xi2 := hammersley(i, iblSamples)
+				h, nDotH := importanceSampleGGX(xi1, xi2, alpha)
+				vDotH := v.Dot(&h)
+				nDotL := 2*vDotH*h.Z - v.Z
+				if nDotL <= 0 {
+					continue
+				}
+				g := (nDotV / (nDotV*(1-k) + k)) * (nDotL / (nDotL*(1-k) + k))
+				gVis := g * vDotH / (nDotH * nDotV)
+				fc := math32.Pow(1-vDotH, 5)
+				scale += (1 - fc) * gVis
+				bias += fc * gVis
+			}
+			i := 2 * (y*size + x)
+			pix[i] = scale / iblSamples
+			pix[i+1] = bias / iblSamples
+		}
+	}
+	return pix
+}
@@ -0,0 +1,244 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package texture
+
+import (
+	"fmt"
+
+	"github.com/g3n/engine/gls"
+)
+
+// RenderTargetOpts describes the attachments of a RenderTarget.
+type RenderTargetOpts struct {
+	ColorFormat uint32 // internal format of the color attachment such as gls.RGBA8 or gls.RGBA16F
+	DepthFormat uint32 // internal format of the depth attachment such as gls.DEPTH_COMPONENT24, or 0 for none
+	Samples     int    // number of samples per pixel for multisample anti-aliasing, or 0 for none
+}
+
+// RenderTarget is a framebuffer object for rendering off-screen into textures,
+// which can then be used as inputs of other shaders for post-processing.
+// A multisampled render target renders into multisample renderbuffers which are
+// resolved into its textures when unbound.
+type RenderTarget struct {
+	gs           *gls.GLS
+	opts         RenderTargetOpts
+	width        int32
+	height       int32
+	fbo          uint32     // framebuffer with the textures
+	msfbo        uint32     // multisample framebuffer with the renderbuffers
+	colorRb      uint32     // multisample color renderbuffer
+	depthRb      uint32     // multisample depth renderbuffer
+	colorTex     *Texture2D // color attachment
+	depthTex     *Texture2D // depth attachment, nil if none
+	prevViewport [4]int32   // viewport before Bind
+}
+
+// NewRenderTarget creates and returns a pointer to a new RenderTarget with the specified size and attachments.
+// A zero ColorFormat defaults to gls.RGBA8.
+func NewRenderTarget(gs *gls.GLS, width, height int, opts RenderTargetOpts) (*RenderTarget, error) {
+
+	if opts.ColorFormat == 0 {
+		opts.ColorFormat = gls.RGBA8
+	}
+	rt := new(RenderTarget)
+	rt.gs = gs
+	rt.opts = opts
+	rt.fbo = gs.GenFramebuffer()
+	rt.colorTex = newAttachmentTexture(gs)
+	if opts.DepthFormat != 0 {
+		rt.depthTex = newAttachmentTexture(gs)
+	}
+	if opts.Samples > 0 {
+		rt.msfbo = gs.GenFramebuffer()
+		rt.colorRb = gs.GenRenderbuffer()
+		if opts.DepthFormat != 0 {
+			rt.depthRb = gs.GenRenderbuffer()
+		}
+	}
+	if err := rt.Resize(width, height); err != nil {
+		rt.Dispose()
+		return nil, err
+	}
+	return rt, nil
+}
+
+// newAttachmentTexture returns a new texture without data or mipmaps to be attached to a framebuffer.
+func newAttachmentTexture(gs *gls.GLS) *Texture2D {
+
+	t := newTexture2D()
+	t.gs = gs
+	t.texname = gs.GenTexture()
+	t.minFilter = gls.LINEAR
+	t.genMipmap = false
+	t.SetFlipY(false)
+	return t
+}
+
+// ColorTexture returns the texture with the color attachment of this render target.
+func (rt *RenderTarget) ColorTexture() *Texture2D {
+
+	return rt.colorTex
+}
+
+// DepthTexture returns the texture with the depth attachment of this render target,
+// or nil if it has no depth attachment.
+func (rt *RenderTarget) DepthTexture() *Texture2D {
+
+	return rt.depthTex
+}
+
+// Width returns the width in pixels of this render target.
+func (rt *RenderTarget) Width() int {
+
+	return int(rt.width)
+}
+
+// Height returns the height in pixels of this render target.
+func (rt *RenderTarget) Height() int {
+
+	return int(rt.height)
+}
+
+// Resize reallocates the attachments of this render target with the specified size keeping their formats.
+func (rt *RenderTarget) Resize(width, height int) error {
+
+	gs := rt.gs
+	rt.width = int32(width)
+	rt.height = int32(height)
+
+	// Texture attachments
+	gs.BindFramebuffer(gls.FRAMEBUFFER, rt.fbo)
+	format, ftype := pixelFormat(rt.opts.ColorFormat)
+	rt.allocTexture(rt.colorTex, rt.opts.ColorFormat, format, ftype)
+	gs.FramebufferTexture2D(gls.FRAMEBUFFER, gls.COLOR_ATTACHMENT0, gls.TEXTURE_2D, rt.colorTex.texname, 0)
+	if rt.depthTex != nil {
+		format, ftype = pixelFormat(rt.opts.DepthFormat)
+		rt.allocTexture(rt.depthTex, rt.opts.DepthFormat, format, ftype)
+		gs.FramebufferTexture2D(gls.FRAMEBUFFER, depthAttachment(rt.opts.DepthFormat), gls.TEXTURE_2D, rt.depthTex.texname, 0)
+	}
+	err := checkFramebuffer(gs)
+
+	// Multisample renderbuffer attachments
+	if err == nil && rt.opts.Samples > 0 {
+		gs.BindFramebuffer(gls.FRAMEBUFFER, rt.msfbo)
+		gs.BindRenderbuffer(gls.RENDERBUFFER, rt.colorRb)
+		gs.RenderbufferStorageMultisample(gls.RENDERBUFFER, int32(rt.opts.Samples), rt.opts.ColorFormat, rt.width, rt.height)
+		gs.FramebufferRenderbuffer(gls.FRAMEBUFFER, gls.COLOR_ATTACHMENT0, gls.RENDERBUFFER, rt.colorRb)
+		if rt.depthRb != 0 {
+			gs.BindRenderbuffer(gls.RENDERBUFFER, rt.depthRb)
+			gs.RenderbufferStorageMultisample(gls.RENDERBUFFER, int32(rt.opts.Samples), rt.opts.DepthFormat, rt.width, rt.height)
+			gs.FramebufferRenderbuffer(gls.FRAMEBUFFER, depthAttachment(rt.opts.DepthFormat), gls.RENDERBUFFER, rt.depthRb)
+		}
+		gs.BindRenderbuffer(gls.RENDERBUFFER, 0)
+		err = checkFramebuffer(gs)
+	}
+	gs.BindFramebuffer(gls.FRAMEBUFFER, 0)
+	return err
+}
+
+// allocTexture allocates the storage of the specified attachment texture with the size of this render target.
+func (rt *RenderTarget) allocTexture(t *Texture2D, iformat, format, ftype uint32) {
+
+	t.width = rt.width
+	t.height = rt.height
+	t.iformat = int32(iformat)
+	t.format = format
+	t.formatType = ftype
+	t.updateData = false
+	t.updateParams = true
+	rt.gs.BindTexture(gls.TEXTURE_2D, t.texname)
+	rt.gs.TexImage2D(gls.TEXTURE_2D, 0, t.iformat, t.width, t.height, 0, format, ftype, nil)
+	rt.gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_MIN_FILTER, int32(t.minFilter))
+	rt.gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_MAG_FILTER, int32(t.magFilter))
+	rt.gs.BindTexture(gls.TEXTURE_2D, 0)
+}
+
+// Bind sets this render target as the current framebuffer and its size as the viewport.
+func (rt *RenderTarget) Bind() {
+
+	x, y, w, h := rt.gs.GetViewport()
+	rt.prevViewport = [4]int32{x, y, w, h}
+	if rt.opts.Samples > 0 {
+		rt.gs.BindFramebuffer(gls.FRAMEBUFFER, rt.msfbo)
+	} else {
+		rt.gs.BindFramebuffer(gls.FRAMEBUFFER, rt.fbo)
+	}
+	rt.gs.Viewport(0, 0, rt.width, rt.height)
+}
+
+// Unbind restores the default framebuffer and the viewport set before Bind.
+// A multisampled render target is first resolved into its textures.
+func (rt *RenderTarget) Unbind() {
+
+	if rt.opts.Samples > 0 {
+		rt.gs.BindFramebuffer(gls.READ_FRAMEBUFFER, rt.msfbo)
+		rt.gs.BindFramebuffer(gls.DRAW_FRAMEBUFFER, rt.fbo)
+		mask := uint32(gls.COLOR_BUFFER_BIT)
+		if rt.depthRb != 0 {
+			mask |= gls.DEPTH_BUFFER_BIT
+		}
+		rt.gs.BlitFramebuffer(0, 0, rt.width, rt.height, 0, 0, rt.width, rt.height, mask, gls.NEAREST)
+	}
+	rt.gs.BindFramebuffer(gls.FRAMEBUFFER, 0)
+	v := rt.prevViewport
+	rt.gs.Viewport(v[0], v[1], v[2], v[3])
+}
+
+// Dispose releases the OpenGL resources of this render target including its textures.
+func (rt *RenderTarget) Dispose() {
+
+	rt.gs.DeleteFramebuffers(rt.fbo)
+	if rt.msfbo != 0 {
+		rt.gs.DeleteFramebuffers(rt.msfbo)
+		rt.gs.DeleteRenderbuffers(rt.colorRb)
+		if rt.depthRb != 0 {
+			rt.gs.DeleteRenderbuffers(rt.depthRb)
+		}
+	}
+	rt.colorTex.Release()
+	if rt.depthTex != nil {
+		rt.depthTex.Release()
+	}
+}
+
+// pixelFormat returns the format and type of the pixel data compatible with the specified internal format.
+func pixelFormat(iformat uint32) (format, ftype uint32) {
+
+	switch iformat {
+	case gls.DEPTH_COMPONENT16, gls.DEPTH_COMPONENT24, gls.DEPTH_COMPONENT32:
+		return gls.DEPTH_COMPONENT, gls.UNSIGNED_INT
+	case gls.DEPTH_COMPONENT32F:
+		return gls.DEPTH_COMPONENT, gls.FLOAT
+	case gls.DEPTH24_STENCIL8:
+		return gls.DEPTH_STENCIL, gls.UNSIGNED_INT_24_8
+	case gls.RGB8:
+		return gls.RGB, gls.UNSIGNED_BYTE
+	case gls.RGB16F:
+		return gls.RGB, gls.FLOAT
+	case gls.RGBA16F, gls.RGBA32F:
+		return gls.RGBA, gls.FLOAT
+	default:
+		return gls.RGBA, gls.UNSIGNED_BYTE
+	}
+}
+
+// depthAttachment returns the framebuffer attachment point of the specified depth internal format.
+func depthAttachment(iformat uint32) uint32 {
+
+	if iformat == gls.DEPTH24_STENCIL8 {
+		return gls.DEPTH_STENCIL_ATTACHMENT
+	}
+	return gls.DEPTH_ATTACHMENT
+}
+
+// checkFramebuffer returns an error if the currently bound framebuffer is not complete.
+func checkFramebuffer(gs *gls.GLS) error {
+
+	status := gs.CheckFramebufferStatus(gls.FRAMEBUFFER)
+	if status != gls.FRAMEBUFFER_COMPLETE {
+		return fmt.Errorf("incomplete framebuffer status:0x%X", status)
+	}
+	return nil
+}
@@ -12,9 +12,35 @@ import (
 	_ "image/jpeg"
 	_ "image/png"
 	"os"
+	"path/filepath"
+	"strings"
 	"unsafe"
 
 	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// WrapMode is the wrapping mode of a texture coordinate outside of [0,1].
+type WrapMode uint32
+
+// Wrapping modes
+const (
+	WrapClampToEdge    = WrapMode(gls.CLAMP_TO_EDGE)
+	WrapRepeat         = WrapMode(gls.REPEAT)
+	WrapMirroredRepeat = WrapMode(gls.MIRRORED_REPEAT)
+)
+
+// FilterMode is the filter used to sample a texture.
+type FilterMode uint32
+
+// Filter modes. The mipmap modes only apply to the minification filter.
+const (
+	FilterNearest              = FilterMode(gls.NEAREST)
+	FilterLinear               = FilterMode(gls.LINEAR)
+	FilterNearestMipmapNearest = FilterMode(gls.NEAREST_MIPMAP_NEAREST)
+	FilterLinearMipmapNearest  = FilterMode(gls.LINEAR_MIPMAP_NEAREST)
+	FilterNearestMipmapLinear  = FilterMode(gls.NEAREST_MIPMAP_LINEAR)
+	FilterLinearMipmapLinear   = FilterMode(gls.LINEAR_MIPMAP_LINEAR)
 )
 
 // Texture2D represents a texture
@@ -87,6 +113,33 @@ func NewTexture2DFromImage(imgfile string) (*Texture2D, error) {
 	return t, nil
 }
 
+// NewTexture2DFromFile creates and returns a pointer to a new Texture2D
+// using the specified image file as data, with mipmaps generated when uploaded.
+// Supported image formats are: PNG, JPEG, GIF and TGA.
+func NewTexture2DFromFile(path string) (*Texture2D, error) {
+
+	return NewTexture2DFromImage(path)
+}
+
+// NewTexture2DFromColor creates and returns a pointer to a new Texture2D
+// with the specified size filled with the specified color.
+func NewTexture2DFromColor(color *math32.Color, width, height int) *Texture2D {
+
+	r := uint8(math32.Clamp(color.R, 0, 1) * 0xFF)
+	g := uint8(math32.Clamp(color.G, 0, 1) * 0xFF)
+	b := uint8(math32.Clamp(color.B, 0, 1) * 0xFF)
+	rgba := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := 0; i < len(rgba.Pix); i += 4 {
+		rgba.Pix[i] = r
+		rgba.Pix[i+1] = g
+		rgba.Pix[i+2] = b
+		rgba.Pix[i+3] = 0xFF
+	}
+	t := newTexture2D()
+	t.SetFromRGBA(rgba)
+	return t
+}
+
 // NewTexture2DFromRGBA creates a new texture from a pointer to an RGBA image object.
 func NewTexture2DFromRGBA(rgba *image.RGBA) *Texture2D {
 
@@ -128,6 +181,19 @@ func (t *Texture2D) Dispose() {
 	}
 }
 
+// Release releases the OpenGL texture object of this texture regardless of its
+// reference count. The texture is uploaded again if rendered afterwards.
+func (t *Texture2D) Release() {
+
+	if t.gs != nil {
+		t.gs.DeleteTextures(t.texname)
+		t.gs = nil
+		t.texname = 0
+	}
+	t.updateData = t.data != nil
+	t.updateParams = true
+}
+
 // SetUniformNames sets the names of the uniforms in the shader for sampler and texture info.
 func (t *Texture2D) SetUniformNames(sampler, info string) {
 
@@ -178,6 +244,13 @@ func (t *Texture2D) SetData(width, height int, format int, formatType, iformat i
 	t.updateData = true
 }
 
+// Data returns the format, type and internal format of the texture data set by SetData, and the data,
+// which is nil if the texture has no data in memory.
+func (t *Texture2D) Data() (format, formatType, iformat int, data interface{}) {
+
+	return int(t.format), int(t.formatType), int(t.iformat), t.data
+}
+
 // SetVisible sets the visibility state of the texture
 func (t *Texture2D) SetVisible(state bool) {
 
@@ -230,6 +303,47 @@ func (t *Texture2D) SetWrapT(wrapT uint32) {
 	t.updateParams = true
 }
 
+// SetWrap sets the wrapping modes of the texture S and T coordinates.
+func (t *Texture2D) SetWrap(wrapS, wrapT WrapMode) {
+
+	t.wrapS = uint32(wrapS)
+	t.wrapT = uint32(wrapT)
+	t.updateParams = true
+}
+
+// Wrap returns the wrapping modes of the texture S and T coordinates.
+func (t *Texture2D) Wrap() (wrapS, wrapT WrapMode) {
+
+	return WrapMode(t.wrapS), WrapMode(t.wrapT)
+}
+
+// SetFilter sets the minification and magnification filters.
+func (t *Texture2D) SetFilter(minFilter, magFilter FilterMode) {
+
+	t.minFilter = uint32(minFilter)
+	t.magFilter = uint32(magFilter)
+	t.updateParams = true
+}
+
+// Filter returns the minification and magnification filters.
+func (t *Texture2D) Filter() (minFilter, magFilter FilterMode) {
+
+	return FilterMode(t.minFilter), FilterMode(t.magFilter)
+}
+
+// SetGenerateMipmap sets whether mipmaps are generated when the texture data is uploaded.
+// Without mipmaps the minification filter should not be a mipmap filter.
+func (t *Texture2D) SetGenerateMipmap(state bool) {
+
+	t.genMipmap = state
+}
+
+// GenerateMipmap returns whether mipmaps are generated when the texture data is uploaded.
+func (t *Texture2D) GenerateMipmap() bool {
+
+	return t.genMipmap
+}
+
 // SetRepeat set the repeat factor
 func (t *Texture2D) SetRepeat(x, y float32) {
 
@@ -266,6 +380,12 @@ func (t *Texture2D) SetFlipY(state bool) {
 	}
 }
 
+// FlipY returns whether the texture is flipped vertically.
+func (t *Texture2D) FlipY() bool {
+
+	return t.udata.flipY != 0
+}
+
 // Width returns the texture width in pixels
 func (t *Texture2D) Width() int {
 
@@ -278,8 +398,14 @@ func (t *Texture2D) Height() int {
 	return int(t.height)
 }
 
+// Dimensions returns the texture width and height in pixels
+func (t *Texture2D) Dimensions() (width, height int) {
+
+	return int(t.width), int(t.height)
+}
+
 // DecodeImage reads and decodes the specified image file into RGBA8.
-// The supported image files are PNG, JPEG and GIF.
+// The supported image files are PNG, JPEG, GIF and TGA.
 func DecodeImage(imgfile string) (*image.RGBA, error) {
 
 	// Open image file
@@ -290,7 +416,12 @@ func DecodeImage(imgfile string) (*image.RGBA, error) {
 	defer file.Close()
 
 	// Decodes image
-	img, _, err := image.Decode(file)
+	var img image.Image
+	if strings.ToLower(filepath.Ext(imgfile)) == ".tga" {
+		img, err = decodeTGA(file)
+	} else {
+		img, _, err = image.Decode(file)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -307,6 +438,27 @@ func DecodeImage(imgfile string) (*image.RGBA, error) {
 // RenderSetup is called by the material render setup
 func (t *Texture2D) RenderSetup(gs *gls.GLS, slotIdx, uniIdx int) { // Could have as input - TEXTURE0 (slot) and uni location
 
+	t.Bind(gs, slotIdx)
+
+	// Transfer texture unit uniform
+	var location int32
+	if uniIdx == 0 {
+		location = t.uniUnit.Location(gs)
+	} else {
+		location = t.uniUnit.LocationIdx(gs, int32(uniIdx))
+	}
+	gs.Uniform1i(location, int32(slotIdx))
+
+	// Transfer texture info combined uniform
+	const vec2count = 3
+	location = t.uniInfo.LocationIdx(gs, vec2count*int32(uniIdx))
+	gs.Uniform2fvUP(location, vec2count, unsafe.Pointer(&t.udata))
+}
+
+// Bind binds this texture to the specified texture unit, transferring its data
+// and parameters to OpenGL if necessary, without setting any uniform.
+func (t *Texture2D) Bind(gs *gls.GLS, slotIdx int) {
+
 	// One time initialization
 	if t.gs == nil {
 		t.texname = gs.GenTexture()
@@ -346,18 +498,4 @@ func (t *Texture2D) RenderSetup(gs *gls.GLS, slotIdx, uniIdx int) { // Could hav
 		gs.TexParameteri(gls.TEXTURE_2D, gls.TEXTURE_WRAP_T, int32(t.wrapT))
 		t.updateParams = false
 	}
-
-	// Transfer texture unit uniform
-	var location int32
-	if uniIdx == 0 {
-		location = t.uniUnit.Location(gs)
-	} else {
-		location = t.uniUnit.LocationIdx(gs, int32(uniIdx))
-	}
-	gs.Uniform1i(location, int32(slotIdx))
-
-	// Transfer texture info combined uniform
-	const vec2count = 3
-	location = t.uniInfo.LocationIdx(gs, vec2count*int32(uniIdx))
-	gs.Uniform2fvUP(location, vec2count, unsafe.Pointer(&t.udata))
 }
@@ -0,0 +1,116 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package texture
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+// TGA image types
+const (
+	tgaTrueColor    = 2
+	tgaGray         = 3
+	tgaTrueColorRLE = 10
+	tgaGrayRLE      = 11
+)
+
+// decodeTGA decodes an uncompressed or run length encoded true color or grayscale TGA image.
+// The standard library has no TGA decoder and the format has no magic number to register it
+// with image.RegisterFormat, so it is selected by the file extension.
+func decodeTGA(r io.Reader) (image.Image, error) {
+
+	br := bufio.NewReader(r)
+	var header [18]byte
+	if _, err := io.ReadFull(br, header[:]); err != nil {
+		return nil, err
+	}
+	idLength := int(header[0])
+	colorMapType := header[1]
+	imageType := header[2]
+	width := int(header[12]) | int(header[13])<<8
+	height := int(header[14]) | int(header[15])<<8
+	bpp := int(header[16])
+	topToBottom := header[17]&0x20 != 0
+
+	if colorMapType != 0 {
+		return nil, errors.New("unsupported color mapped TGA image")
+	}
+	gray := imageType == tgaGray || imageType == tgaGrayRLE
+	rle := imageType == tgaTrueColorRLE || imageType == tgaGrayRLE
+	switch {
+	case imageType != tgaTrueColor && imageType != tgaTrueColorRLE && !gray:
+		return nil, fmt.Errorf("unsupported TGA image type:%d", imageType)
+	case gray && bpp != 8:
+		return nil, fmt.Errorf("unsupported TGA grayscale depth:%d", bpp)
+	case !gray && bpp != 24 && bpp != 32:
+		return nil, fmt.Errorf("unsupported TGA color depth:%d", bpp)
+	}
+	if _, err := br.Discard(idLength); err != nil {
+		return nil, err
+	}
+
+	// Reads the pixels as stored, each with bpp/8 bytes in BGR(A) order
+	size := bpp / 8
+	pixels := make([]byte, width*height*size)
+	if rle {
+		for n := 0; n < len(pixels); {
+			count, err := br.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			run := (int(count&0x7F) + 1) * size
+			if n+run > len(pixels) {
+				return nil, errors.New("invalid TGA run length")
+			}
+			if count&0x80 == 0 {
+				// Raw packet
+				if _, err := io.ReadFull(br, pixels[n:n+run]); err != nil {
+					return nil, err
+				}
+			} else {
+				// Run length packet repeating one pixel
+				if _, err := io.ReadFull(br, pixels[n:n+size]); err != nil {
+					return nil, err
+				}
+				for i := n + size; i < n+run; i++ {
+					pixels[i] = pixels[i-size]
+				}
+			}
+			n += run
+		}
+	} else if _, err := io.ReadFull(br, pixels); err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		// Rows are stored from the bottom unless the descriptor says otherwise
+		row := y
+		if !topToBottom {
+			row = height - 1 - y
+		}
+		for x := 0; x < width; x++ {
+			p := pixels[(row*width+x)*size:]
+			var c color.RGBA
+			switch size {
+			case 1:
+				c = color.RGBA{R: p[0], G: p[0], B: p[0], A: 0xFF}
+			case 3:
+				c = color.RGBA{R: p[2], G: p[1], B: p[0], A: 0xFF}
+			default:
+				// Converts to alpha premultiplied as image.RGBA
+				a := uint16(p[3])
+				c = color.RGBA{R: uint8(uint16(p[2]) * a / 0xFF), G: uint8(uint16(p[1]) * a / 0xFF), B: uint8(uint16(p[0]) * a / 0xFF), A: p[3]}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img, nil
+}
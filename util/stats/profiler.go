@@ -0,0 +1,166 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stats
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Profiler measures the time spent each frame in named sections of code, which may be nested.
+// The sections are begun and ended in the goroutine of the main loop, and EndFrame is called at the end
+// of each frame to report and reset the times. The sections with the same name and parent are accumulated.
+type Profiler struct {
+	root       section
+	current    *section  // innermost section begun
+	frameStart time.Time // start of the current frame
+}
+
+// section accumulates the time of a section of the profiler during a frame.
+// Its nodes are kept across the frames to avoid allocations.
+type section struct {
+	name     string
+	parent   *section
+	children []*section
+	start    time.Time     // start of the current call, if begun
+	total    time.Duration // time of the calls ended in the current frame
+	calls    int
+}
+
+// ProfileReport contains the times of the sections of a frame.
+type ProfileReport struct {
+	Frame    time.Duration // duration of the frame
+	Sections []*Section    // top level sections
+}
+
+// Section contains the times of a section of a frame in a ProfileReport.
+type Section struct {
+	Name          string
+	Duration      time.Duration // total time of the calls of the section
+	ChildDuration time.Duration // time of the nested sections
+	Percent       float32       // percentage of the frame duration
+	Calls         int
+	Children      []*Section
+}
+
+// NewProfiler creates and returns a pointer to a new Profiler whose first frame starts now.
+func NewProfiler() *Profiler {
+
+	p := new(Profiler)
+	p.current = &p.root
+	p.frameStart = time.Now()
+	return p
+}
+
+// Begin begins the section with the specified name, nested in the current section.
+func (p *Profiler) Begin(name string) {
+
+	parent := p.current
+	var s *section
+	for _, child := range parent.children {
+		if child.name == name {
+			s = child
+			break
+		}
+	}
+	if s == nil {
+		s = &section{name: name, parent: parent}
+		parent.children = append(parent.children, s)
+	}
+	p.current = s
+	s.start = time.Now()
+}
+
+// End ends the current section, accumulating its time.
+func (p *Profiler) End() {
+
+	s := p.current
+	if s == &p.root {
+		panic("Profiler.End: no section begun")
+	}
+	s.total += time.Since(s.start)
+	s.calls++
+	p.current = s.parent
+}
+
+// EndFrame ends the current frame, returning the times of its sections, and starts the next one.
+// The sections not ended yet are measured until now and continue in the next frame.
+func (p *Profiler) EndFrame() *ProfileReport {
+
+	now := time.Now()
+	for s := p.current; s != &p.root; s = s.parent {
+		s.total += now.Sub(s.start)
+		s.start = now
+	}
+	r := &ProfileReport{Frame: now.Sub(p.frameStart)}
+	r.Sections = p.report(&p.root, r.Frame)
+	p.frameStart = now
+	return r
+}
+
+// report returns the times of the children of the specified section and resets them.
+// The children not called in the frame are omitted.
+func (p *Profiler) report(parent *section, frame time.Duration) []*Section {
+
+	var sections []*Section
+	for _, s := range parent.children {
+		children := p.report(s, frame)
+		if s.calls == 0 && s.total == 0 {
+			continue
+		}
+		rs := &Section{Name: s.name, Duration: s.total, Calls: s.calls, Children: children}
+		for _, c := range children {
+			rs.ChildDuration += c.Duration
+		}
+		if frame > 0 {
+			rs.Percent = float32(100 * float64(s.total) / float64(frame))
+		}
+		sections = append(sections, rs)
+		s.total = 0
+		s.calls = 0
+	}
+	return sections
+}
+
+// Format writes to the specified writer the tree of the sections of the report whose percentage
+// of the frame duration is at least minPercent, one section per line indented by its depth,
+// with its total and self times, its percentage and number of calls.
+func (r *ProfileReport) Format(w io.Writer, minPercent float32) error {
+
+	_, err := fmt.Fprintf(w, "frame %s\n", formatDuration(r.Frame))
+	if err != nil {
+		return err
+	}
+	return formatSections(w, r.Sections, 1, minPercent)
+}
+
+// formatSections writes the lines of the specified sections and of their children.
+func formatSections(w io.Writer, sections []*Section, depth int, minPercent float32) error {
+
+	for _, s := range sections {
+		if s.Percent < minPercent {
+			continue
+		}
+		name := strings.Repeat("  ", depth) + s.Name
+		_, err := fmt.Fprintf(w, "%-32s %10s %6.1f%%  self %10s  calls %d\n",
+			name, formatDuration(s.Duration), s.Percent, formatDuration(s.Duration-s.ChildDuration), s.Calls)
+		if err != nil {
+			return err
+		}
+		err = formatSections(w, s.Children, depth+1, minPercent)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatDuration formats a duration in milliseconds.
+func formatDuration(d time.Duration) string {
+
+	return fmt.Sprintf("%.3fms", float64(d)/float64(time.Millisecond))
+}
@@ -463,3 +463,13 @@ func (w *glfwWindow) SetCursorPos(xpos, ypos float64) {
 
 	w.win.SetCursorPos(xpos, ypos)
 }
+
+// joystickAxes returns the values of the axes of the specified joystick, from 0, or nil if not present.
+func joystickAxes(joy int) []float32 {
+
+	j := glfw.Joystick1 + glfw.Joystick(joy)
+	if joy < 0 || j > glfw.JoystickLast || !glfw.JoystickPresent(j) {
+		return nil
+	}
+	return glfw.GetJoystickAxes(j)
+}
@@ -0,0 +1,208 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package window
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+)
+
+// AxisBinding binds a game action to an axis of a gamepad.
+type AxisBinding struct {
+	Joystick    int     `json:"joystick"`    // index of the joystick, from 0
+	Axis        int     `json:"axis"`        // index of the axis of the joystick, from 0
+	DeadZone    float32 `json:"deadZone"`    // absolute values below the dead zone are considered 0
+	Sensitivity float32 `json:"sensitivity"` // factor of the value outside of the dead zone, 1 if 0
+}
+
+// Binding specifies the physical keys and gamepad axes which trigger a game action.
+// Keys give the value 1 to the axis of the action and NegativeKeys the value -1.
+type Binding struct {
+	Keys         []Key         `json:"keys,omitempty"`
+	NegativeKeys []Key         `json:"negativeKeys,omitempty"`
+	Axes         []AxisBinding `json:"axes,omitempty"`
+}
+
+// InputManager maps the physical inputs to named game actions, so the game logic queries actions
+// instead of key codes and the user can rebind them.
+// The state of the actions is computed by Update, which should be called once per frame.
+type InputManager struct {
+	bindings map[string]Binding
+	keys     map[Key]bool       // keys currently down
+	axes     map[string]float32 // values of the actions computed by the last update
+	held     map[string]bool    // actions held at the last update
+	prevHeld map[string]bool    // actions held at the update before the last one
+	joyAxes  func(joy int) []float32
+}
+
+// NewInputManager creates and returns a pointer to a new InputManager without bindings
+// which receives the key events of the specified window, if not nil.
+func NewInputManager(win IWindow) *InputManager {
+
+	im := new(InputManager)
+	im.bindings = make(map[string]Binding)
+	im.keys = make(map[Key]bool)
+	im.axes = make(map[string]float32)
+	im.held = make(map[string]bool)
+	im.prevHeld = make(map[string]bool)
+	im.joyAxes = joystickAxes
+	if win != nil {
+		win.Subscribe(OnKeyDown, im.onKey)
+		win.Subscribe(OnKeyUp, im.onKey)
+	}
+	return im
+}
+
+// onKey updates the state of the keys from the window key events.
+func (im *InputManager) onKey(evname string, ev interface{}) {
+
+	kev := ev.(*KeyEvent)
+	im.SetKey(kev.Keycode, evname == OnKeyDown)
+}
+
+// SetKey sets if the specified key is down.
+// It is called by the window key events and can be used to simulate them.
+func (im *InputManager) SetKey(key Key, down bool) {
+
+	if down {
+		im.keys[key] = true
+	} else {
+		delete(im.keys, key)
+	}
+}
+
+// SetBinding sets the binding of the specified action, replacing its previous binding.
+func (im *InputManager) SetBinding(action string, binding Binding) {
+
+	im.bindings[action] = binding
+}
+
+// Binding returns the binding of the specified action and if it exists.
+func (im *InputManager) Binding(action string) (Binding, bool) {
+
+	b, ok := im.bindings[action]
+	return b, ok
+}
+
+// RemoveBinding removes the binding of the specified action.
+func (im *InputManager) RemoveBinding(action string) {
+
+	delete(im.bindings, action)
+	delete(im.axes, action)
+	delete(im.held, action)
+	delete(im.prevHeld, action)
+}
+
+// Update computes the state of all the actions from the current keys and gamepad axes.
+func (im *InputManager) Update() {
+
+	im.prevHeld, im.held = im.held, im.prevHeld
+	for action := range im.held {
+		delete(im.held, action)
+	}
+	// Axes of each joystick used by the bindings, read once
+	joys := make(map[int][]float32)
+	for action, b := range im.bindings {
+		var value float32
+		for _, k := range b.Keys {
+			if im.keys[k] {
+				value++
+				break
+			}
+		}
+		for _, k := range b.NegativeKeys {
+			if im.keys[k] {
+				value--
+				break
+			}
+		}
+		for i := range b.Axes {
+			ab := &b.Axes[i]
+			axes, ok := joys[ab.Joystick]
+			if !ok {
+				axes = im.joyAxes(ab.Joystick)
+				joys[ab.Joystick] = axes
+			}
+			if ab.Axis >= 0 && ab.Axis < len(axes) {
+				value += ab.apply(axes[ab.Axis])
+			}
+		}
+		value = float32(math.Max(-1, math.Min(float64(value), 1)))
+		im.axes[action] = value
+		if value != 0 {
+			im.held[action] = true
+		}
+	}
+}
+
+// apply returns the specified raw axis value with the dead zone and sensitivity of this binding.
+// The values outside of the dead zone are rescaled to start from 0 at its edge.
+func (ab *AxisBinding) apply(raw float32) float32 {
+
+	abs := float32(math.Abs(float64(raw)))
+	if abs <= ab.DeadZone || ab.DeadZone >= 1 {
+		return 0
+	}
+	value := (abs - ab.DeadZone) / (1 - ab.DeadZone)
+	if ab.Sensitivity != 0 {
+		value *= ab.Sensitivity
+	}
+	if raw < 0 {
+		return -value
+	}
+	return value
+}
+
+// Held returns if the specified action was active at the last update.
+func (im *InputManager) Held(action string) bool {
+
+	return im.held[action]
+}
+
+// Pressed returns if the specified action became active at the last update.
+func (im *InputManager) Pressed(action string) bool {
+
+	return im.held[action] && !im.prevHeld[action]
+}
+
+// Released returns if the specified action stopped being active at the last update.
+func (im *InputManager) Released(action string) bool {
+
+	return !im.held[action] && im.prevHeld[action]
+}
+
+// Axis returns the value in [-1,1] of the specified action at the last update:
+// the sum of its key and gamepad axis values.
+func (im *InputManager) Axis(action string) float32 {
+
+	return im.axes[action]
+}
+
+// SaveConfig writes the bindings of all the actions as JSON to the specified writer.
+func (im *InputManager) SaveConfig(w io.Writer) error {
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(im.bindings)
+}
+
+// LoadConfig reads bindings written by SaveConfig from the specified reader
+// and replaces all the bindings with them.
+func (im *InputManager) LoadConfig(r io.Reader) error {
+
+	bindings := make(map[string]Binding)
+	err := json.NewDecoder(r).Decode(&bindings)
+	if err != nil {
+		return err
+	}
+	for action := range im.bindings {
+		if _, ok := bindings[action]; !ok {
+			im.RemoveBinding(action)
+		}
+	}
+	im.bindings = bindings
+	return nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package window
+
+import (
+	"time"
+
+	"github.com/g3n/engine/math32"
+)
+
+// SwipeMaxTime is the longest duration in seconds of a touch recognized as a swipe.
+const SwipeMaxTime = 0.3
+
+// Touch is the state of a finger on a touch screen.
+type Touch struct {
+	ID        int
+	Start     math32.Vector2 // position where the touch began
+	Pos       math32.Vector2 // current or last position
+	StartTime time.Time
+	EndTime   time.Time // time the touch ended, zero while active
+	multi     bool      // other touches were active during this touch
+}
+
+// Duration returns the duration in seconds of this touch, until now if it is active.
+func (t *Touch) Duration(now time.Time) float32 {
+
+	end := t.EndTime
+	if end.IsZero() {
+		end = now
+	}
+	return float32(end.Sub(t.StartTime).Seconds())
+}
+
+// TouchInput keeps the state of the touches of a multi-touch screen, fed by the platform
+// touch events, and recognizes pinch, swipe and tap gestures from it.
+// Swipes and taps are recognized from the last ended touch until the next touch begins.
+type TouchInput struct {
+	touches   map[int]*Touch
+	order     []int   // IDs of the active touches in the order they began
+	pinchDist float32 // distance between the two primary touches when they began
+	released  *Touch  // last ended touch, cleared when a touch begins
+	now       func() time.Time
+}
+
+// NewTouchInput creates and returns a pointer to a new TouchInput without touches.
+func NewTouchInput() *TouchInput {
+
+	ti := new(TouchInput)
+	ti.touches = make(map[int]*Touch)
+	ti.now = time.Now
+	return ti
+}
+
+// Touches returns the active touches keyed by ID.
+func (ti *TouchInput) Touches() map[int]*Touch {
+
+	return ti.touches
+}
+
+// BeginTouch starts the touch with the specified ID at the specified position.
+func (ti *TouchInput) BeginTouch(id int, pos *math32.Vector2) {
+
+	if _, ok := ti.touches[id]; ok {
+		ti.EndTouch(id)
+	}
+	t := &Touch{ID: id, Start: *pos, Pos: *pos, StartTime: ti.now()}
+	if len(ti.order) > 0 {
+		t.multi = true
+		for _, other := range ti.touches {
+			other.multi = true
+		}
+	}
+	ti.touches[id] = t
+	ti.order = append(ti.order, id)
+	ti.released = nil
+	if len(ti.order) == 2 {
+		ti.resetPinch()
+	}
+}
+
+// MoveTouch moves the touch with the specified ID to the specified position.
+func (ti *TouchInput) MoveTouch(id int, pos *math32.Vector2) {
+
+	if t, ok := ti.touches[id]; ok {
+		t.Pos = *pos
+	}
+}
+
+// EndTouch ends the touch with the specified ID.
+func (ti *TouchInput) EndTouch(id int) {
+
+	t, ok := ti.touches[id]
+	if !ok {
+		return
+	}
+	t.EndTime = ti.now()
+	delete(ti.touches, id)
+	for i, oid := range ti.order {
+		if oid == id {
+			primary := i < 2
+			ti.order = append(ti.order[:i], ti.order[i+1:]...)
+			// The pinch continues from the new primary touches
+			if primary && len(ti.order) >= 2 {
+				ti.resetPinch()
+			}
+			break
+		}
+	}
+	ti.released = t
+}
+
+// resetPinch starts a pinch from the current positions of the two primary touches.
+func (ti *TouchInput) resetPinch() {
+
+	ti.pinchDist = ti.primaryDistance()
+}
+
+// primaryDistance returns the current distance between the two primary touches.
+func (ti *TouchInput) primaryDistance() float32 {
+
+	t0, t1 := ti.touches[ti.order[0]], ti.touches[ti.order[1]]
+	return t0.Pos.DistanceTo(&t1.Pos)
+}
+
+// PinchGesture returns the ratio of the current distance between the two primary touches,
+// the first two active touches, to their distance when the pinch began,
+// and false if there are less than two touches.
+func (ti *TouchInput) PinchGesture() (scale float32, ok bool) {
+
+	if len(ti.order) < 2 || ti.pinchDist == 0 {
+		return 0, false
+	}
+	return ti.primaryDistance() / ti.pinchDist, true
+}
+
+// SwipeGesture returns the normalized direction of the last ended touch and true if it was the only
+// touch during its life, lasted less than SwipeMaxTime seconds and traveled more than the specified distance.
+func (ti *TouchInput) SwipeGesture(minDist float32) (dir *math32.Vector2, ok bool) {
+
+	t := ti.released
+	if t == nil || t.multi || t.Duration(t.EndTime) >= SwipeMaxTime {
+		return nil, false
+	}
+	dir = math32.NewVec2().SubVectors(&t.Pos, &t.Start)
+	if dir.Length() <= minDist {
+		return nil, false
+	}
+	return dir.Normalize(), true
+}
+
+// TapGesture returns true if the last ended touch was the only touch during its life,
+// lasted at most the specified time in seconds and ended at most at the specified distance from its start.
+func (ti *TouchInput) TapGesture(maxDist float32, maxTime float32) bool {
+
+	t := ti.released
+	if t == nil || t.multi || t.Duration(t.EndTime) > maxTime {
+		return false
+	}
+	return t.Pos.DistanceTo(&t.Start) <= maxDist
+}